@@ -0,0 +1,151 @@
+// Package bidi provides the last steps of the Unicode Bidirectional Algorithm (UAX #9),
+// which operate on the resolved embedding levels of a line : splitting it into
+// runs to be shaped, and reordering these runs for display.
+package bidi
+
+import "github.com/boxesandglue/textlayout/harfbuzz"
+
+// LevelRun is a run of text with the same bidi embedding level,
+// which should be shaped as a whole, in the direction given by its level.
+type LevelRun struct {
+	Offset, Length int // position in the text, in runes
+	Level          uint8
+}
+
+// Direction returns the direction used to shape the run :
+// right to left for odd levels, left to right otherwise.
+func (r LevelRun) Direction() harfbuzz.Direction {
+	if r.Level%2 == 1 {
+		return harfbuzz.RightToLeft
+	}
+	return harfbuzz.LeftToRight
+}
+
+// SplitByLevel splits a line into runs of the same level. `levels` are the
+// resolved embedding levels of the runes of the line, after rule L1
+// (which resets the trailing whitespace to the paragraph level) has been applied.
+func SplitByLevel(levels []uint8) []LevelRun {
+	var runs []LevelRun
+	for start := 0; start < len(levels); {
+		end := start + 1
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		runs = append(runs, LevelRun{Offset: start, Length: end - start, Level: levels[start]})
+		start = end
+	}
+	return runs
+}
+
+// VisualOrder returns the indices of the runs of a line in visual order,
+// given their levels in logical order, following rule L2 of the Unicode
+// Bidirectional Algorithm : from the highest level to the lowest odd level,
+// any sequence of runs at that level or higher is reversed.
+func VisualOrder(levels []uint8) []int {
+	order := make([]int, len(levels))
+	var highest, lowestOdd uint8 = 0, 0xFF
+	for i, level := range levels {
+		order[i] = i
+		if level > highest {
+			highest = level
+		}
+		if level%2 == 1 && level < lowestOdd {
+			lowestOdd = level
+		}
+	}
+
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for start := 0; start < len(order); {
+			if levels[order[start]] < level {
+				start++
+				continue
+			}
+			end := start + 1
+			for end < len(order) && levels[order[end]] >= level {
+				end++
+			}
+			for i, j := start, end-1; i < j; i, j = i+1, j-1 {
+				order[i], order[j] = order[j], order[i]
+			}
+			start = end
+		}
+	}
+	return order
+}
+
+// ReorderRuns returns the shaped `runs` of a line, given in logical order with
+// their embedding `levels`, in visual order.
+// Each run must have been shaped in the direction given by its level (see `LevelRun.Direction`) :
+// since harfbuzz outputs the glyphs of right to left runs in visual order,
+// the glyphs of the runs are then ready to be drawn one after the other.
+func ReorderRuns(runs []*harfbuzz.Buffer, levels []uint8) []*harfbuzz.Buffer {
+	out := make([]*harfbuzz.Buffer, len(runs))
+	for i, index := range VisualOrder(levels) {
+		out[i] = runs[index]
+	}
+	return out
+}
+
+// LogicalToVisual returns the visual position of each rune of a line,
+// given their resolved embedding `levels` (in logical order).
+// It is the inverse of the permutation returned by VisualOrder(levels).
+func LogicalToVisual(levels []uint8) []int {
+	out := make([]int, len(levels))
+	for visual, logical := range VisualOrder(levels) {
+		out[logical] = visual
+	}
+	return out
+}
+
+// VisualCarets returns the visual caret positions (from 0 to len(levels), from
+// left to right) matching the logical caret position `index`, that is the
+// position before the rune at `index` (or the end of the line for len(levels)).
+// `leading` is the position next to the rune at `index`, and `trailing` is
+// the position next to the rune before it. They differ at the boundaries
+// between runs of different directions, where editors usually
+// display two carets. At the start (resp. end) of the line, only the leading
+// (resp. trailing) position is defined, and both are equal.
+func VisualCarets(levels []uint8, index int) (leading, trailing int) {
+	visual := LogicalToVisual(levels)
+	if index < len(levels) { // the edge before the rune at index
+		leading = visual[index]
+		if levels[index]%2 == 1 {
+			leading++
+		}
+	}
+	if index > 0 { // the edge after the rune at index-1
+		trailing = visual[index-1]
+		if levels[index-1]%2 == 0 {
+			trailing++
+		}
+	}
+	if index == 0 {
+		trailing = leading
+	} else if index >= len(levels) {
+		leading = trailing
+	}
+	return leading, trailing
+}
+
+// LogicalCaret returns the logical caret position matching the visual caret
+// position `visual` (from 0 to len(levels)), for instance to process a click.
+// The position is resolved using the rune on the right of the caret, or the
+// last rune for the right edge of the line.
+func LogicalCaret(levels []uint8, visual int) int {
+	if len(levels) == 0 {
+		return 0
+	}
+	order := VisualOrder(levels)
+	if visual >= len(levels) {
+		logical := order[len(order)-1]
+		if levels[logical]%2 == 1 { // the right edge of a RTL rune is its start
+			return logical
+		}
+		return logical + 1
+	}
+	logical := order[visual]
+	if levels[logical]%2 == 1 { // the left edge of a RTL rune is its end
+		return logical + 1
+	}
+	return logical
+}
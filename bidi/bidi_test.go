@@ -0,0 +1,144 @@
+package bidi
+
+import (
+	"testing"
+
+	"github.com/boxesandglue/textlayout/harfbuzz"
+)
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSplitByLevel(t *testing.T) {
+	runs := SplitByLevel([]uint8{0, 0, 1, 1, 1, 2, 0})
+	expected := []LevelRun{{0, 2, 0}, {2, 3, 1}, {5, 1, 2}, {6, 1, 0}}
+	if len(runs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, runs)
+	}
+	for i, run := range runs {
+		if run != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], run)
+		}
+	}
+	if runs[1].Direction() != harfbuzz.RightToLeft || runs[2].Direction() != harfbuzz.LeftToRight {
+		t.Error("invalid directions")
+	}
+	if SplitByLevel(nil) != nil {
+		t.Error("expected no runs")
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	for _, test := range []struct {
+		levels   []uint8
+		expected []int
+	}{
+		{nil, []int{}},
+		{[]uint8{0}, []int{0}},
+		{[]uint8{1}, []int{0}},
+		{[]uint8{0, 1, 0}, []int{0, 1, 2}},
+		{[]uint8{1, 2, 1}, []int{2, 1, 0}},
+		{[]uint8{0, 1, 2, 1, 0}, []int{0, 3, 2, 1, 4}},
+		{[]uint8{1, 0, 1}, []int{0, 1, 2}},
+		{[]uint8{2, 1, 2}, []int{2, 1, 0}},
+		{[]uint8{0, 2, 0}, []int{0, 1, 2}},
+		{[]uint8{0, 2, 3, 0}, []int{0, 1, 2, 3}}, // the lowest odd level is 3
+	} {
+		if got := VisualOrder(test.levels); !equalInts(got, test.expected) {
+			t.Errorf("for levels %v, expected %v, got %v", test.levels, test.expected, got)
+		}
+	}
+
+	a, b, c := harfbuzz.NewBuffer(), harfbuzz.NewBuffer(), harfbuzz.NewBuffer()
+	runs := ReorderRuns([]*harfbuzz.Buffer{a, b, c}, []uint8{1, 2, 1})
+	if runs[0] != c || runs[1] != b || runs[2] != a {
+		t.Errorf("unexpected order")
+	}
+}
+
+func TestCarets(t *testing.T) {
+	// "ab" followed by two RTL letters "CD", displayed as "abDC"
+	levels := []uint8{0, 0, 1, 1}
+	if got := LogicalToVisual(levels); !equalInts(got, []int{0, 1, 3, 2}) {
+		t.Errorf("unexpected visual positions %v", got)
+	}
+
+	for _, test := range []struct {
+		index             int
+		leading, trailing int
+	}{
+		{0, 0, 0},
+		{1, 1, 1},
+		{2, 4, 2}, // boundary : after 'b' or at the right of 'C'
+		{3, 3, 3},
+		{4, 2, 2}, // end of line : at the left of 'D'
+	} {
+		leading, trailing := VisualCarets(levels, test.index)
+		if leading != test.leading || trailing != test.trailing {
+			t.Errorf("for index %d, expected (%d, %d), got (%d, %d)", test.index, test.leading, test.trailing, leading, trailing)
+		}
+	}
+
+	for visual, exp := range []int{0, 1, 4, 3, 2} {
+		if got := LogicalCaret(levels, visual); got != exp {
+			t.Errorf("for visual position %d, expected %d, got %d", visual, exp, got)
+		}
+	}
+
+	// the round trip is consistent in a single direction
+	levels = []uint8{1, 1, 1}
+	for index := 0; index <= len(levels); index++ {
+		leading, _ := VisualCarets(levels, index)
+		if got := LogicalCaret(levels, leading); got != index {
+			t.Errorf("round trip for %d: got %d", index, got)
+		}
+	}
+}
+
+func TestCaretsMixedLevels(t *testing.T) {
+	// "a" "BC" "de" "F" "g", where "de" is embedded in the RTL run,
+	// displayed as "a" "F" "de" "CB" "g"
+	levels := []uint8{0, 1, 1, 2, 2, 1, 0}
+	if got := VisualOrder(levels); !equalInts(got, []int{0, 5, 3, 4, 2, 1, 6}) {
+		t.Errorf("unexpected visual order %v", got)
+	}
+	if got := LogicalToVisual(levels); !equalInts(got, []int{0, 5, 4, 2, 3, 1, 6}) {
+		t.Errorf("unexpected visual positions %v", got)
+	}
+
+	for index, exp := range [][2]int{
+		{0, 0},
+		{6, 1}, // at the right of 'B' or after 'a'
+		{5, 5},
+		{2, 4}, // at the left of 'd' or at the left of 'C'
+		{3, 3},
+		{2, 4}, // at the right of 'F' or after 'e'
+		{6, 1}, // at the left of 'g' or at the left of 'F'
+		{7, 7},
+	} {
+		leading, trailing := VisualCarets(levels, index)
+		if leading != exp[0] || trailing != exp[1] {
+			t.Errorf("for index %d, expected %v, got (%d, %d)", index, exp, leading, trailing)
+		}
+	}
+
+	for visual, exp := range []int{0, 6, 3, 4, 3, 2, 6, 7} {
+		got := LogicalCaret(levels, visual)
+		if got != exp {
+			t.Errorf("for visual position %d, expected %d, got %d", visual, exp, got)
+		}
+		// the visual position is one of the carets of the logical position
+		if leading, trailing := VisualCarets(levels, got); leading != visual && trailing != visual {
+			t.Errorf("for visual position %d, inconsistent carets (%d, %d)", visual, leading, trailing)
+		}
+	}
+}
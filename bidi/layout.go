@@ -0,0 +1,34 @@
+package bidi
+
+import (
+	"github.com/boxesandglue/textlayout/harfbuzz"
+	"github.com/boxesandglue/textlayout/language"
+)
+
+// Layout shapes a line of `text`, given the resolved embedding `levels` of its
+// runes (see `SplitByLevel`), and returns the shaped runs in visual order.
+// The line is split into runs of the same level and script, each one being
+// shaped with the font returned by `fonts` for its script, and with the whole
+// line as context. The clusters of the glyphs are indices into `text`.
+func Layout(text []rune, levels []uint8, fonts func(script language.Script) *harfbuzz.Font,
+	features []harfbuzz.Feature,
+) []*harfbuzz.Buffer {
+	var (
+		runs      []*harfbuzz.Buffer
+		runLevels []uint8
+	)
+	for _, levelRun := range SplitByLevel(levels) {
+		for _, scriptRun := range language.SplitByScript(text[levelRun.Offset : levelRun.Offset+levelRun.Length]) {
+			buf := harfbuzz.NewBuffer()
+			buf.AddRunes(text, levelRun.Offset+scriptRun.Offset, scriptRun.Length)
+			buf.Props.Direction = levelRun.Direction()
+			buf.Props.Script = scriptRun.Script
+			buf.GuessSegmentProperties()
+			buf.Shape(fonts(scriptRun.Script), features)
+
+			runs = append(runs, buf)
+			runLevels = append(runLevels, levelRun.Level)
+		}
+	}
+	return ReorderRuns(runs, runLevels)
+}
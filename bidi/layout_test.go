@@ -0,0 +1,91 @@
+package bidi
+
+import (
+	"bytes"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+	"github.com/boxesandglue/textlayout/harfbuzz"
+	"github.com/boxesandglue/textlayout/language"
+)
+
+func loadFont(t *testing.T, filename string) *harfbuzz.Font {
+	file, err := testdata.Files.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := tt.Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return harfbuzz.NewFont(face)
+}
+
+// clusters returns the clusters of the glyphs of `runs`, in visual order
+func clusters(runs []*harfbuzz.Buffer) []int {
+	var out []int
+	for _, run := range runs {
+		for _, info := range run.Info {
+			if len(out) == 0 || out[len(out)-1] != info.Cluster {
+				out = append(out, info.Cluster)
+			}
+		}
+	}
+	return out
+}
+
+func TestLayout(t *testing.T) {
+	latin := loadFont(t, "perf_reference/fonts/Roboto-Regular.ttf")
+	arabic := loadFont(t, "perf_reference/fonts/Amiri-Regular.ttf")
+	var scripts []language.Script
+	fonts := func(script language.Script) *harfbuzz.Font {
+		scripts = append(scripts, script)
+		if script == language.Arabic {
+			return arabic
+		}
+		return latin
+	}
+
+	// a left to right paragraph with an Arabic word
+	text := []rune("ab جزر cd")
+	runs := Layout(text, []uint8{0, 0, 0, 1, 1, 1, 0, 0, 0}, fonts, nil)
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	if runs[1].Props.Direction != harfbuzz.RightToLeft || runs[1].Props.Script != language.Arabic {
+		t.Fatalf("unexpected properties %v", runs[1].Props)
+	}
+	if exp := []language.Script{language.Latin, language.Arabic, language.Latin}; !equalScripts(scripts, exp) {
+		t.Fatalf("expected scripts %v, got %v", exp, scripts)
+	}
+	if exp, got := []int{0, 1, 2, 5, 4, 3, 6, 7, 8}, clusters(runs); !equalInts(got, exp) {
+		t.Fatalf("expected clusters %v, got %v", exp, got)
+	}
+
+	// a right to left paragraph with a Latin word
+	scripts = nil
+	text = []rune("جزر ab جزر")
+	runs = Layout(text, []uint8{1, 1, 1, 1, 2, 2, 1, 1, 1, 1}, fonts, nil)
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	if exp, got := []int{9, 8, 7, 6, 4, 5, 3, 2, 1, 0}, clusters(runs); !equalInts(got, exp) {
+		t.Fatalf("expected clusters %v, got %v", exp, got)
+	}
+	if runs[1].Props.Direction != harfbuzz.LeftToRight || runs[1].Props.Script != language.Latin {
+		t.Fatalf("unexpected properties %v", runs[1].Props)
+	}
+}
+
+func equalScripts(a, b []language.Script) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -6,6 +6,7 @@
 package fonts
 
 import (
+	"bytes"
 	"io"
 	"math"
 	"sort"
@@ -20,6 +21,31 @@ type Resource interface {
 	Seek(int64, int) (int64, error)
 }
 
+// BytesResource is a Resource whose content is directly
+// accessible in memory, such as a memory mapped file.
+// Parsers may use sub-slices of the content instead of copying it,
+// so that only the parts of a (large) font file actually used
+// are loaded (for a memory mapped file, paged in).
+// The content must not be modified while the fonts parsed from it are in use.
+type BytesResource interface {
+	Resource
+	// Bytes returns the whole content of the resource.
+	Bytes() []byte
+}
+
+type bytesResource struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (br bytesResource) Bytes() []byte { return br.data }
+
+// NewBytesResource returns a BytesResource reading from `data`,
+// which may be, for instance, a memory mapped file.
+func NewBytesResource(data []byte) BytesResource {
+	return bytesResource{Reader: bytes.NewReader(data), data: data}
+}
+
 // PSInfo exposes global properties of a postscript font.
 type PSInfo struct {
 	FontName    string // Postscript font name.
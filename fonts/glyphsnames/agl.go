@@ -0,0 +1,70 @@
+package glyphsnames
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GlyphToRunes maps a glyph name to a sequence of runes, following
+// the Adobe Glyph List Specification (https://github.com/adobe-type-tools/agl-specification).
+// Contrary to `GlyphToRune`, ligatures whose name is made of several
+// components separated by underscores (like "f_f_i" or "T_h") are mapped to the
+// runes of their components.
+// It returns nil if the name can't be mapped.
+func GlyphToRunes(glyph string) []rune {
+	// drop the suffix
+	if i := strings.IndexByte(glyph, '.'); i != -1 {
+		glyph = glyph[:i]
+	}
+	if glyph == "" {
+		return nil
+	}
+	var out []rune
+	for _, component := range strings.Split(glyph, "_") {
+		out = append(out, componentToRunes(component)...)
+	}
+	return out
+}
+
+func componentToRunes(component string) []rune {
+	if r, ok := glyphlistGlyphToRuneMap[component]; ok {
+		return []rune{r}
+	}
+	if alias, ok := glyphAliases[component]; ok {
+		if r, ok := glyphlistGlyphToRuneMap[alias]; ok {
+			return []rune{r}
+		}
+	}
+	// uniXXXX[YYYY...]
+	if hexa := strings.TrimPrefix(component, "uni"); len(hexa) != len(component) && len(hexa) != 0 && len(hexa)%4 == 0 {
+		var out []rune
+		for ; len(hexa) != 0; hexa = hexa[4:] {
+			r, ok := parseUpperHex(hexa[:4])
+			if !ok || (0xD800 <= r && r <= 0xDFFF) {
+				return nil
+			}
+			out = append(out, r)
+		}
+		return out
+	}
+	// uXXXX to uXXXXXX
+	if hexa := strings.TrimPrefix(component, "u"); len(hexa) != len(component) && 4 <= len(hexa) && len(hexa) <= 6 {
+		r, ok := parseUpperHex(hexa)
+		if !ok || (0xD800 <= r && r <= 0xDFFF) || r > 0x10FFFF {
+			return nil
+		}
+		return []rune{r}
+	}
+	return nil
+}
+
+// parseUpperHex only accepts digits and uppercase letters.
+func parseUpperHex(s string) (rune, bool) {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'A' <= c && c <= 'F') {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	return rune(n), err == nil
+}
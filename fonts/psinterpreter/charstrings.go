@@ -52,13 +52,64 @@ func (p Point) toSP() fonts.SegmentPoint {
 	return fonts.SegmentPoint{X: float32(p.X), Y: float32(p.Y)}
 }
 
+// Matrix is an affine transform [a b c d e f], as the PostScript
+// FontMatrix, which maps (x, y) to (a*x + c*y + e, b*x + d*y + f).
+type Matrix [6]float32
+
+// IdentityMatrix leaves the points unchanged.
+var IdentityMatrix = Matrix{1, 0, 0, 1, 0, 0}
+
+// Multiply returns the transform applying `m`, then `n`,
+// so that FontMatrix.Multiply(userTransform) maps font units to user space.
+func (m Matrix) Multiply(n Matrix) Matrix {
+	return Matrix{
+		n[0]*m[0] + n[2]*m[1],
+		n[1]*m[0] + n[3]*m[1],
+		n[0]*m[2] + n[2]*m[3],
+		n[1]*m[2] + n[3]*m[3],
+		n[0]*m[4] + n[2]*m[5] + n[4],
+		n[1]*m[4] + n[3]*m[5] + n[5],
+	}
+}
+
+// Apply returns the transformed point.
+func (m Matrix) Apply(pt fonts.SegmentPoint) fonts.SegmentPoint {
+	x, y := float64(pt.X), float64(pt.Y)
+	return fonts.SegmentPoint{
+		X: float32(float64(m[0])*x + float64(m[2])*y + float64(m[4])),
+		Y: float32(float64(m[1])*x + float64(m[3])*y + float64(m[5])),
+	}
+}
+
+// PathConsumer receives the outlines of a glyph as the charstring is
+// interpreted, so that rasterizers may build their own path representation
+// without an intermediate slice of segments.
+// The points are in absolute font units, and the contours are closed
+// with a line to their first point, if needed.
+type PathConsumer interface {
+	MoveTo(pt fonts.SegmentPoint)
+	LineTo(pt fonts.SegmentPoint)
+	CurveTo(pt1, pt2, pt3 fonts.SegmentPoint)
+}
+
 // CharstringReader provides implementation
 // of the operators found in a font charstring.
 type CharstringReader struct {
-	// Acumulated segments for the glyph outlines
+	// Acumulated segments for the glyph outlines,
+	// unless Consumer is not nil
 	Segments []fonts.Segment
+	// Consumer, if not nil, receives the glyph outlines,
+	// which are then not stored in Segments
+	Consumer PathConsumer
+	// Transform, if not nil, is applied to the points of the outlines
+	// as they are emitted. The Bounds are not transformed.
+	Transform *Matrix
 	// Acumulated bounds for the glyph outlines
 	Bounds PathBounds
+	// WidthOnly enables a fast mode, where the interpretation
+	// stops as soon as the width is known (see WidthFound),
+	// without building the outlines.
+	WidthOnly bool
 
 	vstemCount   int32
 	hstemCount   int32
@@ -76,6 +127,17 @@ type CharstringReader struct {
 	seenPoint bool
 }
 
+// WidthFound must be called by the operator handlers once the width
+// of the glyph is known (for instance after 'hsbw' or the first
+// stack-clearing operator of a Type 2 charstring).
+// It returns ErrInterrupt in WidthOnly mode, nil otherwise.
+func (out *CharstringReader) WidthFound() error {
+	if out.WidthOnly {
+		return ErrInterrupt
+	}
+	return nil
+}
+
 // enlarges the current bounds to include the Point (x,y).
 func (out *CharstringReader) updateBounds(pt Point) {
 	if !out.seenPoint {
@@ -102,9 +164,16 @@ func (out *CharstringReader) determineHintmaskSize(state *Machine) {
 	}
 }
 
-func (out *CharstringReader) Hintmask(state *Machine) {
+// Hintmask skips the mask bytes following a 'hintmask' or 'cntrmask'
+// operator, and returns them (or nil if the charstring is truncated).
+func (out *CharstringReader) Hintmask(state *Machine) []byte {
 	out.determineHintmaskSize(state)
+	var mask []byte
+	if int(out.hintmaskSize) < len(state.instructions) {
+		mask = state.instructions[:out.hintmaskSize:out.hintmaskSize]
+	}
 	state.SkipBytes(out.hintmaskSize)
+	return mask
 }
 
 func (out *CharstringReader) move(pt Point) {
@@ -113,9 +182,33 @@ func (out *CharstringReader) move(pt Point) {
 	out.CurrentPoint.Move(pt.X, pt.Y)
 	out.isPathOpen = false
 	out.firstPoint = out.CurrentPoint
+	if out.Consumer != nil {
+		out.Consumer.MoveTo(out.emit(out.CurrentPoint))
+		return
+	}
 	out.Segments = append(out.Segments, fonts.Segment{
 		Op:   fonts.SegmentOpMoveTo,
-		Args: [3]fonts.SegmentPoint{out.CurrentPoint.toSP()},
+		Args: [3]fonts.SegmentPoint{out.emit(out.CurrentPoint)},
+	})
+}
+
+// emit returns the point sent to the outlines, transformed if needed
+func (out *CharstringReader) emit(pt Point) fonts.SegmentPoint {
+	if out.Transform != nil {
+		return out.Transform.Apply(pt.toSP())
+	}
+	return pt.toSP()
+}
+
+// lineTo records a line to `pt`, without updating the current point
+func (out *CharstringReader) lineTo(pt Point) {
+	if out.Consumer != nil {
+		out.Consumer.LineTo(out.emit(pt))
+		return
+	}
+	out.Segments = append(out.Segments, fonts.Segment{
+		Op:   fonts.SegmentOpLineTo,
+		Args: [3]fonts.SegmentPoint{out.emit(pt)},
 	})
 }
 
@@ -127,10 +220,7 @@ func (out *CharstringReader) line(pt Point) {
 	}
 	out.CurrentPoint = pt
 	out.updateBounds(pt)
-	out.Segments = append(out.Segments, fonts.Segment{
-		Op:   fonts.SegmentOpLineTo,
-		Args: [3]fonts.SegmentPoint{pt.toSP()},
-	})
+	out.lineTo(pt)
 }
 
 func (out *CharstringReader) curve(pt1, pt2, pt3 Point) {
@@ -143,9 +233,13 @@ func (out *CharstringReader) curve(pt1, pt2, pt3 Point) {
 	out.updateBounds(pt2)
 	out.CurrentPoint = pt3
 	out.updateBounds(pt3)
+	if out.Consumer != nil {
+		out.Consumer.CurveTo(out.emit(pt1), out.emit(pt2), out.emit(pt3))
+		return
+	}
 	out.Segments = append(out.Segments, fonts.Segment{
 		Op:   fonts.SegmentOpCubeTo,
-		Args: [3]fonts.SegmentPoint{pt1.toSP(), pt2.toSP(), pt3.toSP()},
+		Args: [3]fonts.SegmentPoint{out.emit(pt1), out.emit(pt2), out.emit(pt3)},
 	})
 }
 
@@ -155,11 +249,9 @@ func (out *CharstringReader) doubleCurve(pt1, pt2, pt3, pt4, pt5, pt6 Point) {
 }
 
 func (out *CharstringReader) ensureClosePath() {
-	if out.firstPoint != out.CurrentPoint {
-		out.Segments = append(out.Segments, fonts.Segment{
-			Op:   fonts.SegmentOpLineTo,
-			Args: [3]fonts.SegmentPoint{out.firstPoint.toSP()},
-		})
+	// nothing to close if the path has not been drawn
+	if out.isPathOpen && out.firstPoint != out.CurrentPoint {
+		out.lineTo(out.firstPoint)
 	}
 }
 
@@ -190,6 +282,10 @@ func GlobalSubr(state *Machine) error {
 	return state.CallSubroutine(index, false)
 }
 
+// IsEmpty returns true if no outline has been drawn,
+// in which case the bounds are not meaningful.
+func (out *CharstringReader) IsEmpty() bool { return !out.seenPoint }
+
 // ClosePath closes the current contour, adding
 // a segment to the first point if needed.
 func (out *CharstringReader) ClosePath() {
@@ -20,6 +20,10 @@ var (
 	// ErrInterrupt signals the interpreter to stop early, without erroring.
 	ErrInterrupt = errors.New("interruption")
 
+	// ErrLimitExceeded is returned (wrapped) when the instructions
+	// exceed one of the limits of the interpreter (see Limits).
+	ErrLimitExceeded = errors.New("interpreter limit exceeded")
+
 	errInvalidCFFTable               = errors.New("invalid ps instructions")
 	errUnsupportedCFFVersion         = errors.New("unsupported CFF version")
 	errUnsupportedRealNumberEncoding = errors.New("unsupported real number encoding")
@@ -33,11 +37,19 @@ const (
 	// preceded by up to a maximum of 48 operands". 5177.Type2.pdf Appendix B
 	// "Type 2 Charstring Implementation Limits" says that "Argument stack 48".
 	// T1_SPEC.pdf 6.1 Encoding as a limitation of 24.
-	psArgStackSize = 48
+	// However, CFF2 charstrings may use up to 513 operands (see the maxstack operator),
+	// which are required by the blend operator.
+	psArgStackSize = 513
 
 	// Similarly, Appendix B says "Subr nesting, stack limit 10".
 	psCallStackSize = 10
 
+	// DefaultMaxOperators is the default number of operators
+	// a Run call may execute, including the ones in subroutines.
+	// It is far above the needs of real glyphs, but bounds the
+	// work done on (maliciously) recursive subroutines.
+	DefaultMaxOperators = 1 << 16
+
 	maxRealNumberStrLen = 64 // Maximum length in bytes of the "-123.456E-7" representation.
 )
 
@@ -92,19 +104,53 @@ func (a *ArgStack) PopN(numPop int32) error {
 	return nil
 }
 
+// Limits bounds the resources used by a Machine, so that invalid or
+// malicious fonts fail fast, instead of looping or exhausting memory.
+// A zero field selects the default limit.
+type Limits struct {
+	// ArgStackSize is the maximum number of operands on the argument
+	// stack. It defaults to (and can't exceed) 513, the limit of CFF2 charstrings.
+	ArgStackSize int
+	// CallDepth is the maximum nesting of subroutine calls, 10 by default.
+	CallDepth int
+	// MaxOperators is the maximum number of operators executed by
+	// a Run call, including the ones in subroutines.
+	// It defaults to DefaultMaxOperators.
+	MaxOperators int
+}
+
+// resolve returns the limits to use, with default values
+// for the zero fields.
+func (l Limits) resolve() Limits {
+	if l.ArgStackSize <= 0 || l.ArgStackSize > psArgStackSize {
+		l.ArgStackSize = psArgStackSize
+	}
+	if l.CallDepth <= 0 {
+		l.CallDepth = psCallStackSize
+	}
+	if l.MaxOperators <= 0 {
+		l.MaxOperators = DefaultMaxOperators
+	}
+	return l
+}
+
 // Machine is a PostScript interpreter.
 // A same interpreter may be re-used using multiples `Run` calls.
 type Machine struct {
+	// Limits are the budgets enforced by Run,
+	// which may be changed between two calls.
+	Limits Limits
+
 	localSubrs  [][]byte
 	globalSubrs [][]byte
 
 	instructions []byte
 
-	callStack struct {
-		vals [psCallStackSize][]byte // parent instructions
-		top  int32                   // effective size currently in use
-	}
-	ArgStack ArgStack
+	callStack [][]byte // parent instructions
+	ArgStack  ArgStack
+
+	limits    Limits // resolved at the start of Run
+	operators int    // number of operators executed
 
 	parseNumberBuf [maxRealNumberStrLen]byte
 	ctx            PsContext
@@ -125,8 +171,8 @@ func (p *Machine) hasMoreInstructions() bool {
 	if len(p.instructions) != 0 {
 		return true
 	}
-	for i := int32(0); i < p.callStack.top; i++ {
-		if len(p.callStack.vals[i]) != 0 {
+	for _, instructions := range p.callStack {
+		if len(instructions) != 0 {
 			return true
 		}
 	}
@@ -146,9 +192,22 @@ func (p *Machine) Run(instructions []byte, localSubrs, globalSubrs [][]byte, han
 	p.localSubrs = localSubrs
 	p.globalSubrs = globalSubrs
 	p.ArgStack.Top = 0
-	p.callStack.top = 0
+	p.callStack = p.callStack[:0]
+	p.limits = p.Limits.resolve()
+	p.operators = 0
+
+	for {
+		if len(p.instructions) == 0 {
+			if len(p.callStack) == 0 {
+				break
+			}
+			// CFF2 subroutines end without return operator
+			if err := p.Return(); err != nil {
+				return err
+			}
+			continue
+		}
 
-	for len(p.instructions) > 0 {
 		// Push a numeric operand on the stack, if applicable.
 		if hasResult, err := p.parseNumber(); hasResult {
 			if err != nil {
@@ -158,6 +217,10 @@ func (p *Machine) Run(instructions []byte, localSubrs, globalSubrs [][]byte, han
 		}
 
 		// Otherwise, execute an operator.
+		if p.operators == p.limits.MaxOperators {
+			return fmt.Errorf("%w: more than %d operators", ErrLimitExceeded, p.limits.MaxOperators)
+		}
+		p.operators++
 		b := p.instructions[0]
 		p.instructions = p.instructions[1:]
 
@@ -267,8 +330,8 @@ func (p *Machine) parseNumber() (hasResult bool, err error) {
 	}
 
 	if hasResult {
-		if p.ArgStack.Top == psArgStackSize {
-			return true, errInvalidCFFTable
+		if int(p.ArgStack.Top) == p.limits.ArgStackSize {
+			return true, fmt.Errorf("%w: more than %d operands", ErrLimitExceeded, p.limits.ArgStackSize)
 		}
 		p.ArgStack.Vals[p.ArgStack.Top] = number
 		p.ArgStack.Top++
@@ -328,12 +391,11 @@ func (p *Machine) CallSubroutine(index int32, isLocal bool) error {
 	if index < 0 || int(index) >= len(subrs) {
 		return fmt.Errorf("invalid subroutine index %d (for length %d)", index, len(subrs))
 	}
-	if p.callStack.top == psCallStackSize {
-		return errors.New("maximum call stack size reached")
+	if len(p.callStack) == p.limits.CallDepth {
+		return fmt.Errorf("%w: more than %d nested subroutine calls", ErrLimitExceeded, p.limits.CallDepth)
 	}
 	// save the current instructions
-	p.callStack.vals[p.callStack.top] = p.instructions
-	p.callStack.top++
+	p.callStack = append(p.callStack, p.instructions)
 
 	// activate the subroutine
 	p.instructions = subrs[index]
@@ -342,12 +404,12 @@ func (p *Machine) CallSubroutine(index int32, isLocal bool) error {
 
 // Return returns from a subroutine call.
 func (p *Machine) Return() error {
-	if p.callStack.top <= 0 {
+	if len(p.callStack) == 0 {
 		return errors.New("no subroutine has been called")
 	}
-	p.callStack.top--
 	// restore the previous instructions
-	p.instructions = p.callStack.vals[p.callStack.top]
+	p.instructions = p.callStack[len(p.callStack)-1]
+	p.callStack = p.callStack[:len(p.callStack)-1]
 	return nil
 }
 
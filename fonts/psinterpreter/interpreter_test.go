@@ -0,0 +1,173 @@
+package psinterpreter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// charstringHandler interprets the path and subroutine
+// operators of Type 2 charstrings.
+type charstringHandler struct {
+	cs CharstringReader
+}
+
+func (charstringHandler) Context() PsContext { return Type2Charstring }
+
+func (h *charstringHandler) Apply(op PsOperator, state *Machine) error {
+	var err error
+	if !op.IsEscaped {
+		switch op.Operator {
+		case 11: // return
+			return state.Return()
+		case 10: // callsubr
+			return LocalSubr(state)
+		case 29: // callgsubr
+			return GlobalSubr(state)
+		case 14: // endchar
+			h.cs.ClosePath()
+			return ErrInterrupt
+		case 1, 18: // hstem, hstemhm
+			h.cs.Hstem(state)
+		case 3, 23: // vstem, vstemhm
+			h.cs.Vstem(state)
+		case 19, 20: // hintmask, cntrmask
+			h.cs.Hintmask(state)
+			return nil
+		case 21: // rmoveto
+			err = h.cs.Rmoveto(state)
+		case 22: // hmoveto
+			err = h.cs.Hmoveto(state)
+		case 4: // vmoveto
+			err = h.cs.Vmoveto(state)
+		case 5: // rlineto
+			h.cs.Rlineto(state)
+		case 6: // hlineto
+			h.cs.Hlineto(state)
+		case 7: // vlineto
+			h.cs.Vlineto(state)
+		case 8: // rrcurveto
+			h.cs.Rrcurveto(state)
+		case 24: // rcurveline
+			err = h.cs.Rcurveline(state)
+		case 25: // rlinecurve
+			err = h.cs.Rlinecurve(state)
+		case 26: // vvcurveto
+			h.cs.Vvcurveto(state)
+		case 27: // hhcurveto
+			h.cs.Hhcurveto(state)
+		case 30: // vhcurveto
+			h.cs.Vhcurveto(state)
+		case 31: // hvcurveto
+			h.cs.Hvcurveto(state)
+		default:
+			err = fmt.Errorf("invalid operator %s in charstring", op)
+		}
+	} else {
+		switch op.Operator {
+		case 34: // hflex
+			err = h.cs.Hflex(state)
+		case 35: // flex
+			err = h.cs.Flex(state)
+		case 36: // hflex1
+			err = h.cs.Hflex1(state)
+		case 37: // flex1
+			err = h.cs.Flex1(state)
+		default:
+			err = fmt.Errorf("invalid operator %s in charstring", op)
+		}
+	}
+	state.ArgStack.Clear()
+	return err
+}
+
+// callSubr returns the instructions calling the local subroutine `index`,
+// with less than 1240 subroutines
+func callSubr(index int) []byte { return []byte{byte(index - 107 + 139), 10} }
+
+func TestLimits(t *testing.T) {
+	// 48 operands, the maximum for Type 2 charstrings
+	curves := make([]byte, 0, 49)
+	for i := 0; i < 48; i++ {
+		curves = append(curves, 139+10)
+	}
+	curves = append(curves, 8) // rrcurveto
+
+	// each subroutine calls the next one twice
+	var nested [][]byte
+	for i := 0; i < 9; i++ {
+		nested = append(nested, append(append(append([]byte{139, 139, 21}, callSubr(i+1)...), callSubr(i+1)...), 11))
+	}
+	nested = append(nested, []byte{139 + 1, 139 + 1, 5, 11}) // rlineto
+
+	for _, test := range []struct {
+		charstring []byte
+		subrs      [][]byte
+		limits     Limits
+		exceeded   bool
+	}{
+		{curves, nil, Limits{}, false},
+		{curves, nil, Limits{ArgStackSize: 47}, true},
+		{callSubr(0), [][]byte{callSubr(0)}, Limits{}, true}, // infinite recursion
+		{callSubr(0), [][]byte{callSubr(0)}, Limits{CallDepth: 100}, true},
+		{callSubr(0), nested, Limits{}, false},
+		{callSubr(0), nested, Limits{CallDepth: 9}, true},
+		{callSubr(0), nested, Limits{MaxOperators: 1000}, true},
+	} {
+		var (
+			psi     = Machine{Limits: test.limits}
+			handler charstringHandler
+		)
+		err := psi.Run(test.charstring, test.subrs, nil, &handler)
+		if got := errors.Is(err, ErrLimitExceeded); got != test.exceeded {
+			t.Fatalf("for limits %v, expected exceeded limit: %v, got %v", test.limits, test.exceeded, err)
+		}
+	}
+}
+
+// FuzzRun checks that the interpreter does not panic nor exceed
+// its limits on arbitrary charstrings and subroutines.
+func FuzzRun(f *testing.F) {
+	f.Add([]byte{139, 139, 21, 149, 149, 5, 14}, []byte{11})
+	f.Add(callSubr(0), callSubr(1))
+	f.Add(append(callSubr(0), 14), []byte{139, 29, 11})
+	f.Add([]byte{139, 139, 139, 139, 18, 19, 0xff, 139, 12, 35}, []byte{28, 0, 1, 10})
+
+	f.Fuzz(func(t *testing.T, charstring, subr []byte) {
+		var (
+			psi     Machine
+			handler charstringHandler
+		)
+		// the charstring may call itself, as well as `subr`
+		subrs := [][]byte{subr, charstring}
+		_ = psi.Run(charstring, subrs, subrs, &handler)
+		if psi.operators > DefaultMaxOperators {
+			t.Fatalf("too many operators executed: %d", psi.operators)
+		}
+		if len(psi.callStack) > psCallStackSize {
+			t.Fatalf("too many nested calls: %d", len(psi.callStack))
+		}
+	})
+}
+
+func TestMatrix(t *testing.T) {
+	scale := Matrix{0.001, 0, 0, 0.001, 0, 0}
+	slant := Matrix{12, 0, 3, 12, 5, -2}
+	pt := fonts.SegmentPoint{X: 500, Y: 1000}
+
+	if got := IdentityMatrix.Apply(pt); got != pt {
+		t.Fatalf("expected %v, got %v", pt, got)
+	}
+	if exp, got := (fonts.SegmentPoint{X: 6 + 3*1 + 5, Y: 12*1 - 2}), scale.Multiply(slant).Apply(pt); got != exp {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	// the first matrix is applied first
+	if exp, got := slant.Apply(scale.Apply(pt)), scale.Multiply(slant).Apply(pt); got != exp {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if got := slant.Multiply(IdentityMatrix); got != slant {
+		t.Fatalf("expected %v, got %v", slant, got)
+	}
+}
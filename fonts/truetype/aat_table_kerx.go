@@ -306,8 +306,8 @@ func parseKernxSubtable2(data []byte, headerLength int, extended bool, numGlyphs
 		arrayOffset = uint32(binary.BigEndian.Uint16(data[headerLength+6:]))
 	}
 
-	if len(data) < int(arrayOffset) {
-		return out, errors.New("invalid kerx subtable format 2 (EOF)")
+	if len(data) < int(arrayOffset) || len(data) < int(leftOffset) || len(data) < int(rightOffset) {
+		return out, errors.New("invalid kern/x subtable format 2 (EOF)")
 	}
 
 	if extended {
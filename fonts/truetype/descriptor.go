@@ -15,7 +15,10 @@ func (font *Font) PostscriptInfo() (fonts.PSInfo, bool) {
 	return fonts.PSInfo{}, false
 }
 
-func (font *Font) Cmap() (fonts.Cmap, fonts.CmapEncoding) { return font.cmap, font.cmapEncoding }
+func (font *Font) Cmap() (fonts.Cmap, fonts.CmapEncoding) {
+	cmap, encoding, _ := font.lazy.cmaps()
+	return cmap, encoding
+}
 
 // PostscriptName returns the optional PostscriptName of the font
 func (font *Font) PostscriptName() string {
@@ -118,6 +121,12 @@ func (pr *FontParser) loadSummary(font *Font) error {
 
 func (font *Font) LoadSummary() (fonts.FontSummary, error) {
 	isItalic, isBold, familyName, styleName := font.fontSummary.getStyle()
+	// the legacy names of variable fonts usually only describe the default instance
+	if len(font.fvar.Axis) != 0 {
+		if style := font.STATStyleName(nil); style != "" {
+			styleName = style
+		}
+	}
 	return fonts.FontSummary{
 		IsItalic: isItalic,
 		IsBold:   isBold,
@@ -131,6 +140,57 @@ func (font *Font) LoadSummary() (fonts.FontSummary, error) {
 	}, nil
 }
 
+// statCoords returns the design coordinates `coords`, completed
+// by the default values of the 'fvar' axis.
+func (font *Font) statCoords(coords []Variation) map[Tag]float32 {
+	out := make(map[Tag]float32, len(font.fvar.Axis)+len(coords))
+	for _, axis := range font.fvar.Axis {
+		out[axis.Tag] = axis.Default
+	}
+	for _, v := range coords {
+		out[v.Tag] = v.Value
+	}
+	return out
+}
+
+// AxisValueNames returns the names of the 'STAT' axis values describing
+// the style at the given design coordinates, at most one for each axis,
+// sorted by axis ordering.
+// The axes of a variable font which are not in `coords` use their default value,
+// and the other axes match any value, so that `coords` is usually empty for non-variable fonts.
+func (font *Font) AxisValueNames(coords []Variation) []string {
+	values := font.stat.selectValues(font.statCoords(coords))
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = font.Names.getName(value.Name)
+	}
+	return out
+}
+
+// STATStyleName returns the style name (such as "Condensed Bold") at the
+// given design coordinates (see AxisValueNames), built from the 'STAT' table:
+// the elidable names (such as "Regular") are omitted, and the elided fallback
+// name is used if all the names are elided.
+// It returns an empty string if the font has no 'STAT' table.
+func (font *Font) STATStyleName(coords []Variation) string {
+	if len(font.stat.Axes) == 0 {
+		return ""
+	}
+	var names []string
+	for _, value := range font.stat.selectValues(font.statCoords(coords)) {
+		if value.Flags&ElidableAxisValueName != 0 {
+			continue
+		}
+		if name := font.Names.getName(value.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return font.Names.getName(font.stat.ElidedFallbackName)
+	}
+	return strings.Join(names, " ")
+}
+
 // getStyle sum up the style of the font
 func (summary fontSummary) getStyle() (isItalic, isBold bool, familyName, styleName string) {
 	// Bit 8 of the `fsSelection' field in the `OS/2' table denotes
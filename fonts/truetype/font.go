@@ -45,18 +45,26 @@ type gid = uint16
 // exist. In particular, there's a big different between TrueType glyphs (usually .ttf)
 // and CFF/PostScript Type 2 glyphs (usually .otf)
 type Font struct {
-	cmap         Cmap
-	cmapVar      unicodeVariations
-	cmapEncoding fonts.CmapEncoding
-	knowTables   map[Tag]bool
+	knowTables map[Tag]bool
+
+	// glyf, cmap and layout tables, decoded on demand
+	lazy *lazyTables
 
 	Names TableName
 
 	hhea, vhea *TableHVhea
 	vorg       *tableVorg // optional
 	cff        *type1c.Font
+	cff2       *type1c.CFF2
 	post       TablePost // optional
 	svg        tableSVG  // optional
+	colr       tableCOLR // optional
+	cpal       tableCPAL // optional
+	stat       TableSTAT // optional
+	gasp       TableGasp // optional
+	meta       TableMeta // optional
+
+	glyphNames *glyphNameIndex // used by GlyphByName, may be nil
 
 	// Optional, only present in variable fonts
 
@@ -64,11 +72,9 @@ type Font struct {
 	hvar, vvar *tableHVvar // optional
 	avar       tableAvar
 	mvar       TableMvar
-	gvar       tableGvar
 	fvar       TableFvar
 	Maxp       TableMaxp
 
-	Glyf       TableGlyf
 	vmtx, Hmtx TableHVmtx
 	bitmap     bitmapTable // CBDT or EBLC or BLOC
 	sbix       tableSbix
@@ -78,9 +84,6 @@ type Font struct {
 	// graphite font, optional
 	Graphite *GraphiteTables
 
-	// Advanced layout tables.
-	layoutTables LayoutTables
-
 	fontSummary fontSummary
 
 	Head TableHead
@@ -95,6 +98,8 @@ type Font struct {
 
 	upem uint16 // cached value
 
+	lineMetrics LineMetricsSource // used by FontHExtents
+
 	// HasHint is true if the font has a prep table.
 	HasHint bool
 
@@ -112,6 +117,9 @@ type Font struct {
 
 	// The cvt table
 	cvt []byte
+
+	// The fpgm table
+	fpgm []byte
 }
 
 // LayoutTables exposes advanced layout tables.
@@ -126,9 +134,5 @@ type LayoutTables struct {
 	Kerx TableKernx
 	GSUB TableGSUB // An absent table has a nil slice of lookups
 	GPOS TableGPOS // An absent table has a nil slice of lookups
+	Math TableMATH // An absent table has nil coverages
 }
-
-// LayoutTables returns the valid advanced layout tables.
-// When parsing yields an error, it is ignored and an empty table is returned.
-// See the individual methods for more control over error handling.
-func (font *Font) LayoutTables() LayoutTables { return font.layoutTables }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+	"github.com/boxesandglue/textlayout/fonts"
 )
 
 func loadFont(t *testing.T, filename string) *Font {
@@ -199,3 +200,34 @@ func TestScanDescription(t *testing.T) {
 		}
 	}
 }
+
+func TestLineMetricsSource(t *testing.T) {
+	for _, test := range []struct {
+		filename string
+		source   LineMetricsSource
+		expected fonts.FontExtents
+	}{
+		{"DejaVuSerif.ttf", LineMetricsAuto, fonts.FontExtents{Ascender: 1901, Descender: -483, LineGap: 0}},
+		{"DejaVuSerif.ttf", LineMetricsHhea, fonts.FontExtents{Ascender: 1901, Descender: -483, LineGap: 0}},
+		{"DejaVuSerif.ttf", LineMetricsTypo, fonts.FontExtents{Ascender: 1556, Descender: -492, LineGap: 410}},
+		{"Castoro-Regular.ttf", LineMetricsWin, fonts.FontExtents{Ascender: 1091, Descender: -430, LineGap: 0}},
+		// USE_TYPO_METRICS is set
+		{"Commissioner-VF.ttf", LineMetricsAuto, fonts.FontExtents{Ascender: 2034, Descender: -412, LineGap: 0}},
+		{"Commissioner-VF.ttf", LineMetricsWin, fonts.FontExtents{Ascender: 2424, Descender: -586, LineGap: 0}},
+	} {
+		font := loadFont(t, test.filename)
+		font.SetLineMetricsSource(test.source)
+		got, ok := font.FontHExtents()
+		if !ok || got != test.expected {
+			t.Fatalf("%s (source %d): expected %v, got %v", test.filename, test.source, test.expected, got)
+		}
+	}
+
+	// missing OS/2 table: fallback to 'hhea'
+	font := loadFont(t, "DejaVuSerif.ttf")
+	font.OS2 = nil
+	font.SetLineMetricsSource(LineMetricsWin)
+	if got, _ := font.FontHExtents(); got != (fonts.FontExtents{Ascender: 1901, Descender: -483}) {
+		t.Fatalf("unexpected extents %v", got)
+	}
+}
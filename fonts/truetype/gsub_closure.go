@@ -0,0 +1,291 @@
+package truetype
+
+import "sort"
+
+// maxClosureNesting bounds the recursion through contextual lookups,
+// which may reference each other.
+const maxClosureNesting = 64
+
+// Closure adds to `glyphs` all the glyphs which may be produced
+// by the lookups of the given `features`, until no more glyphs are found.
+// If `features` is nil, the lookups of all the features are used.
+// The scripts, languages and feature variations are not taken into account.
+//
+// The nested lookups of contextual substitutions are only applied
+// when the context may be matched by glyphs of the set, to the
+// glyphs which may be found at the position they apply to.
+// Since the order of the glyphs is unknown, the result may still contain
+// glyphs which are never produced when shaping a text made of `glyphs`.
+func (t TableGSUB) Closure(glyphs map[GID]bool, features []Tag) {
+	lookups := t.featuresLookups(features)
+	for {
+		size := len(glyphs)
+		for _, index := range lookups {
+			t.lookupClosure(index, glyphs, glyphs, 0)
+		}
+		if len(glyphs) == size {
+			return
+		}
+	}
+}
+
+// featuresLookups returns the sorted indices of the lookups
+// used by `features`, or by all the features if `features` is nil.
+func (t TableGSUB) featuresLookups(features []Tag) []uint16 {
+	enabled := make(map[Tag]bool, len(features))
+	for _, feature := range features {
+		enabled[feature] = true
+	}
+	indices := make(map[uint16]bool)
+	for _, feature := range t.Features {
+		if features != nil && !enabled[feature.Tag] {
+			continue
+		}
+		for _, index := range feature.LookupIndices {
+			if int(index) < len(t.Lookups) {
+				indices[index] = true
+			}
+		}
+	}
+	out := make([]uint16, 0, len(indices))
+	for index := range indices {
+		out = append(out, index)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// lookupClosure applies the lookup at `index` to the glyphs in `input`,
+// adding the substitutes to `glyphs`. `input` is either `glyphs` itself,
+// or the glyphs which may be found at one position of a context.
+func (t TableGSUB) lookupClosure(index uint16, input, glyphs map[GID]bool, depth int) {
+	if depth > maxClosureNesting || int(index) >= len(t.Lookups) {
+		return
+	}
+	for _, subtable := range t.Lookups[index].Subtables {
+		t.subtableClosure(subtable, input, glyphs, depth)
+	}
+}
+
+func (t TableGSUB) subtableClosure(st GSUBSubtable, input, glyphs map[GID]bool, depth int) {
+	if st.Coverage == nil {
+		return
+	}
+	// the coverage is resolved first, since `glyphs` is modified
+	type covered struct {
+		glyph GID
+		index int
+	}
+	var inputs []covered
+	for g := range input {
+		if index, ok := st.Coverage.Index(g); ok {
+			inputs = append(inputs, covered{g, index})
+		}
+	}
+	if len(inputs) == 0 {
+		return
+	}
+
+	switch data := st.Data.(type) {
+	case GSUBContext3:
+		if intersectsCoverages(data.Coverages, glyphs) {
+			t.nestedClosure(data.SequenceLookups, func(i int) map[GID]bool {
+				return coveredGlyphs(data.Coverages[i], input, glyphs, i)
+			}, glyphs, depth)
+		}
+		return
+	case GSUBChainedContext3:
+		if intersectsCoverages(data.Input, glyphs) && intersectsCoverages(data.Backtrack, glyphs) &&
+			intersectsCoverages(data.Lookahead, glyphs) {
+			t.nestedClosure(data.SequenceLookups, func(i int) map[GID]bool {
+				return coveredGlyphs(data.Input[i], input, glyphs, i)
+			}, glyphs, depth)
+		}
+		return
+	case GSUBReverseChainedContext1:
+		if !intersectsCoverages(data.Backtrack, glyphs) || !intersectsCoverages(data.Lookahead, glyphs) {
+			return
+		}
+	}
+
+	for _, input := range inputs {
+		switch data := st.Data.(type) {
+		case GSUBSingle1:
+			glyphs[GID(uint16(int(input.glyph)+int(data)))] = true
+		case GSUBSingle2:
+			if input.index < len(data) {
+				glyphs[data[input.index]] = true
+			}
+		case GSUBMultiple1:
+			if input.index < len(data) {
+				for _, g := range data[input.index] {
+					glyphs[g] = true
+				}
+			}
+		case GSUBAlternate1:
+			if input.index < len(data) {
+				for _, g := range data[input.index] {
+					glyphs[g] = true
+				}
+			}
+		case GSUBLigature1:
+			if input.index >= len(data) {
+				continue
+			}
+			for _, lig := range data[input.index] {
+				if containsGlyphs(lig.Components, glyphs) {
+					glyphs[lig.Glyph] = true
+				}
+			}
+		case GSUBContext1:
+			if input.index >= len(data) {
+				continue
+			}
+			for _, rule := range data[input.index] {
+				if containsGlyphs(rule.Input, glyphs) {
+					t.nestedClosure(rule.Lookups, glyphsSequence(input.glyph, rule.Input), glyphs, depth)
+				}
+			}
+		case GSUBChainedContext1:
+			if input.index >= len(data) {
+				continue
+			}
+			for _, rule := range data[input.index] {
+				if containsGlyphs(rule.Input, glyphs) && containsGlyphs(rule.Backtrack, glyphs) &&
+					containsGlyphs(rule.Lookahead, glyphs) {
+					t.nestedClosure(rule.Lookups, glyphsSequence(input.glyph, rule.Input), glyphs, depth)
+				}
+			}
+		case GSUBContext2:
+			class := classOf(data.Class, input.glyph)
+			if int(class) >= len(data.SequenceSets) {
+				continue
+			}
+			for _, rule := range data.SequenceSets[class] {
+				if intersectsClasses(data.Class, rule.Input, glyphs) {
+					t.nestedClosure(rule.Lookups, classesSequence(input.glyph, data.Class, rule.Input, glyphs), glyphs, depth)
+				}
+			}
+		case GSUBChainedContext2:
+			class := classOf(data.InputClass, input.glyph)
+			if int(class) >= len(data.SequenceSets) {
+				continue
+			}
+			for _, rule := range data.SequenceSets[class] {
+				if intersectsClasses(data.InputClass, rule.Input, glyphs) && intersectsClasses(data.BacktrackClass, rule.Backtrack, glyphs) &&
+					intersectsClasses(data.LookaheadClass, rule.Lookahead, glyphs) {
+					t.nestedClosure(rule.Lookups, classesSequence(input.glyph, data.InputClass, rule.Input, glyphs), glyphs, depth)
+				}
+			}
+		case GSUBReverseChainedContext1:
+			if input.index < len(data.Substitutes) {
+				glyphs[data.Substitutes[input.index]] = true
+			}
+		}
+	}
+}
+
+// nestedClosure applies the nested lookups of a matched context, where
+// `position` returns the glyphs which may be found at an input index.
+func (t TableGSUB) nestedClosure(lookups []SequenceLookup, position func(int) map[GID]bool, glyphs map[GID]bool, depth int) {
+	for _, lookup := range lookups {
+		t.lookupClosure(lookup.LookupIndex, position(int(lookup.InputIndex)), glyphs, depth+1)
+	}
+}
+
+// glyphsSequence returns the glyphs of a format 1 context,
+// starting with `first`.
+func glyphsSequence(first GID, rest []uint16) func(int) map[GID]bool {
+	return func(i int) map[GID]bool {
+		if i == 0 {
+			return map[GID]bool{first: true}
+		}
+		return map[GID]bool{GID(rest[i-1]): true}
+	}
+}
+
+// classesSequence returns the glyphs of a format 2 context,
+// starting with `first`.
+func classesSequence(first GID, class Class, rest []uint16, glyphs map[GID]bool) func(int) map[GID]bool {
+	return func(i int) map[GID]bool {
+		if i == 0 {
+			return map[GID]bool{first: true}
+		}
+		out := make(map[GID]bool)
+		for g := range glyphs {
+			if classOf(class, g) == rest[i-1] {
+				out[g] = true
+			}
+		}
+		return out
+	}
+}
+
+// coveredGlyphs returns the glyphs covered by `cov`, among `input`
+// for the first position, and among `glyphs` for the others.
+func coveredGlyphs(cov Coverage, input, glyphs map[GID]bool, position int) map[GID]bool {
+	if position == 0 {
+		glyphs = input
+	}
+	out := make(map[GID]bool)
+	for g := range glyphs {
+		if _, ok := cov.Index(g); ok {
+			out[g] = true
+		}
+	}
+	return out
+}
+
+// classOf returns the class of `glyph`, which is 0 for the glyphs not in `class`.
+func classOf(class Class, glyph GID) uint16 {
+	if class == nil {
+		return 0
+	}
+	id, _ := class.ClassID(glyph)
+	return uint16(id)
+}
+
+func containsGlyphs(gids []uint16, glyphs map[GID]bool) bool {
+	for _, g := range gids {
+		if !glyphs[GID(g)] {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectsClasses returns true if each class in `classes`
+// is used by a glyph of the set.
+func intersectsClasses(class Class, classes []uint16, glyphs map[GID]bool) bool {
+	for _, id := range classes {
+		found := false
+		for g := range glyphs {
+			if classOf(class, g) == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectsCoverages returns true if each coverage
+// covers a glyph of the set.
+func intersectsCoverages(covs []Coverage, glyphs map[GID]bool) bool {
+	for _, cov := range covs {
+		found := false
+		for g := range glyphs {
+			if _, ok := cov.Index(g); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package truetype
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGSUBClosure(t *testing.T) {
+	gsub := TableGSUB{Lookups: []LookupGSUB{
+		{Type: GSUBLigature, Subtables: []GSUBSubtable{
+			{Coverage: CoverageList{1}, Data: GSUBLigature1{{{Components: []uint16{2}, Glyph: 10}, {Components: []uint16{3}, Glyph: 11}}}},
+		}},
+		{Type: GSUBSingle, Subtables: []GSUBSubtable{
+			{Coverage: CoverageList{10}, Data: GSUBSingle1(5)},
+			{Coverage: CoverageList{1, 2}, Data: GSUBSingle2{20, 21}},
+		}},
+		{Type: GSUBMultiple, Subtables: []GSUBSubtable{
+			{Coverage: CoverageList{15}, Data: GSUBMultiple1{{30, 31}}},
+		}},
+	}}
+	gsub.Features = []FeatureRecord{
+		{Tag: MustNewTag("liga"), Feature: Feature{LookupIndices: []uint16{0}}},
+		{Tag: MustNewTag("smcp"), Feature: Feature{LookupIndices: []uint16{1, 2}}},
+	}
+
+	for _, test := range []struct {
+		features []Tag
+		expected []GID
+	}{
+		{nil, []GID{1, 2, 10, 15, 20, 21, 30, 31}},
+		{[]Tag{MustNewTag("liga")}, []GID{1, 2, 10}},
+		{[]Tag{MustNewTag("smcp")}, []GID{1, 2, 20, 21}},
+		{[]Tag{MustNewTag("liga"), MustNewTag("smcp")}, []GID{1, 2, 10, 15, 20, 21, 30, 31}},
+		{[]Tag{}, []GID{1, 2}},
+	} {
+		glyphs := map[GID]bool{1: true, 2: true}
+		gsub.Closure(glyphs, test.features)
+		expected := make(map[GID]bool)
+		for _, g := range test.expected {
+			expected[g] = true
+		}
+		if !reflect.DeepEqual(glyphs, expected) {
+			t.Fatalf("features %v: expected %v, got %v", test.features, expected, glyphs)
+		}
+	}
+}
+
+func TestGSUBClosureContext(t *testing.T) {
+	// lookups 0 and 1 are only referenced by the contextual lookups
+	lookups := []LookupGSUB{
+		{Type: GSUBSingle, Subtables: []GSUBSubtable{
+			{Coverage: CoverageList{1, 2, 3}, Data: GSUBSingle2{101, 102, 103}},
+		}},
+		{Type: GSUBSingle, Subtables: []GSUBSubtable{
+			{Coverage: CoverageList{1, 2, 3}, Data: GSUBSingle2{201, 202, 203}},
+		}},
+	}
+	class := classFormat1{startGlyph: 1, classIDs: []uint32{1, 2, 2}}
+
+	for _, test := range []struct {
+		subtable GSUBSubtable
+		input    []GID
+		expected []GID
+	}{
+		// 1 2 -> apply lookup 0 on 2
+		{
+			GSUBSubtable{Coverage: CoverageList{1}, Data: GSUBContext1{{
+				{Input: []uint16{2}, Lookups: []SequenceLookup{{InputIndex: 1, LookupIndex: 0}}},
+			}}},
+			[]GID{1, 2}, []GID{1, 2, 102},
+		},
+		// context not matched
+		{
+			GSUBSubtable{Coverage: CoverageList{1}, Data: GSUBContext1{{
+				{Input: []uint16{2}, Lookups: []SequenceLookup{{InputIndex: 1, LookupIndex: 0}}},
+			}}},
+			[]GID{1, 3}, []GID{1, 3},
+		},
+		// class 1, class 2 -> apply lookup 1 on class 2
+		{
+			GSUBSubtable{Coverage: CoverageList{1}, Data: GSUBContext2{Class: class, SequenceSets: [][]SequenceRule{
+				nil,
+				{{Input: []uint16{2}, Lookups: []SequenceLookup{{InputIndex: 1, LookupIndex: 1}}}},
+			}}},
+			[]GID{1, 2, 3}, []GID{1, 2, 3, 202, 203},
+		},
+		// backtrack 3, input 1 -> apply lookup 0 on 1
+		{
+			GSUBSubtable{Coverage: CoverageList{1}, Data: GSUBChainedContext3{
+				Backtrack:       []Coverage{CoverageList{3}},
+				Input:           []Coverage{CoverageList{1}},
+				SequenceLookups: []SequenceLookup{{InputIndex: 0, LookupIndex: 0}},
+			}},
+			[]GID{1, 3}, []GID{1, 3, 101},
+		},
+		{
+			GSUBSubtable{Coverage: CoverageList{1}, Data: GSUBChainedContext3{
+				Backtrack:       []Coverage{CoverageList{3}},
+				Input:           []Coverage{CoverageList{1}},
+				SequenceLookups: []SequenceLookup{{InputIndex: 0, LookupIndex: 0}},
+			}},
+			[]GID{1, 2}, []GID{1, 2},
+		},
+	} {
+		gsub := TableGSUB{Lookups: append(lookups[:2:2], LookupGSUB{Subtables: []GSUBSubtable{test.subtable}})}
+		gsub.Features = []FeatureRecord{{Tag: MustNewTag("calt"), Feature: Feature{LookupIndices: []uint16{2}}}}
+
+		glyphs := make(map[GID]bool)
+		for _, g := range test.input {
+			glyphs[g] = true
+		}
+		gsub.Closure(glyphs, nil)
+		expected := make(map[GID]bool)
+		for _, g := range test.expected {
+			expected[g] = true
+		}
+		if !reflect.DeepEqual(glyphs, expected) {
+			t.Fatalf("expected %v, got %v", expected, glyphs)
+		}
+	}
+}
+
+func TestGSUBClosureFont(t *testing.T) {
+	font := loadFont(t, "Roboto-BoldItalic.ttf")
+	gsub := font.LayoutTables().GSUB
+	f, _ := font.NominalGlyph('f')
+	i, _ := font.NominalGlyph('i')
+
+	all := map[GID]bool{f: true, i: true}
+	gsub.Closure(all, nil)
+	liga := map[GID]bool{f: true, i: true}
+	gsub.Closure(liga, []Tag{MustNewTag("liga")})
+	none := map[GID]bool{f: true, i: true}
+	gsub.Closure(none, []Tag{MustNewTag("xxxx")})
+
+	if len(liga) <= 2 { // at least the fi ligature
+		t.Fatalf("unexpected closure %v", liga)
+	}
+	if len(all) < len(liga) || len(none) != 2 {
+		t.Fatal("unexpected closure")
+	}
+	for g := range liga {
+		if !all[g] {
+			t.Fatalf("missing glyph %d", g)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package truetype
+
+import "encoding/binary"
+
+// The following methods expose the data required by a TrueType
+// bytecode interpreter. See also the Maxp table for the limits
+// (stack size, number of functions, storage, twilight points) it should allocate.
+
+// FontProgram returns the content of the 'fpgm' table, which
+// is executed once, when the font is loaded. It is nil if the table is absent.
+func (f *Font) FontProgram() []byte { return f.fpgm }
+
+// ControlValueProgram returns the content of the 'prep' table, which is
+// executed each time the size or the transformation changes.
+// It is nil if the table is absent.
+func (f *Font) ControlValueProgram() []byte { return f.prep }
+
+// ControlValues returns the values of the 'cvt ' table, in font units.
+// The variations of the 'cvar' table are not applied.
+func (f *Font) ControlValues() []int16 {
+	out := make([]int16, len(f.cvt)/2)
+	for i := range out {
+		out[i] = int16(binary.BigEndian.Uint16(f.cvt[2*i:]))
+	}
+	return out
+}
+
+// GlyphInstructions returns the instructions of the given glyph,
+// or nil if the glyph has none or is not defined in a 'glyf' table.
+func (f *Font) GlyphInstructions(gid GID) []byte {
+	glyf := f.Glyf()
+	if int(gid) >= len(glyf) {
+		return nil
+	}
+	switch data := glyf[gid].data.(type) {
+	case simpleGlyphData:
+		return data.instructions
+	case compositeGlyphData:
+		return data.instructions
+	}
+	return nil
+}
+
+// GaspBehavior returns the rendering to use at the given size, in pixels per em,
+// as specified by the 'gasp' table.
+// If the font has no 'gasp' table or the table does not cover `ppem`,
+// the default returned behavior enables both hinting (for fonts with a 'prep' table)
+// and anti-aliasing.
+func (f *Font) GaspBehavior(ppem uint16) GaspBehavior {
+	if behavior, ok := f.gasp.Behavior(ppem); ok {
+		return behavior
+	}
+	if f.HasHint {
+		return GaspGridfit | GaspDoGray
+	}
+	return GaspDoGray
+}
@@ -0,0 +1,108 @@
+package truetype
+
+import (
+	"bytes"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+)
+
+func TestHintingTables(t *testing.T) {
+	file, err := testdata.Files.ReadFile("SelawikVar.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := NewFontParser(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		tag Tag
+		got []byte
+	}{
+		{tagFpgm, font.FontProgram()},
+		{tagPrep, font.ControlValueProgram()},
+	} {
+		exp, err := pr.GetRawTable(test.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(exp, test.got) {
+			t.Fatalf("invalid %s table", test.tag)
+		}
+	}
+
+	cvt := font.ControlValues()
+	if len(cvt) != 214 {
+		t.Fatalf("unexpected cvt length %d", len(cvt))
+	}
+
+	if len(font.GlyphInstructions(3)) == 0 {
+		t.Fatal("missing glyph instructions")
+	}
+	if font.GlyphInstructions(GID(font.NumGlyphs)) != nil {
+		t.Fatal("unexpected instructions for out of range glyph")
+	}
+
+	if b := font.GaspBehavior(7); b != GaspDoGray|GaspSymmetricSmoothing {
+		t.Fatalf("unexpected behavior %d", b)
+	}
+	if b := font.GaspBehavior(8); b != GaspGridfit|GaspDoGray|GaspSymmetricGridfit|GaspSymmetricSmoothing {
+		t.Fatalf("unexpected behavior %d", b)
+	}
+}
+
+func TestGasp(t *testing.T) {
+	font := loadFont(t, "FreeSerif.ttf")
+	for _, test := range []struct {
+		ppem     uint16
+		expected GaspBehavior
+	}{
+		{6, GaspDoGray},
+		{9, GaspDoGray},
+		{10, GaspGridfit},
+		{21, GaspGridfit},
+		{22, GaspGridfit | GaspDoGray},
+		{500, GaspGridfit | GaspDoGray},
+	} {
+		if got := font.GaspBehavior(test.ppem); got != test.expected {
+			t.Fatalf("ppem %d: expected %d, got %d", test.ppem, test.expected, got)
+		}
+	}
+
+	// no table
+	font = loadFont(t, "Roboto-BoldItalic.ttf")
+	if font.gasp != nil {
+		t.Fatal("unexpected gasp table")
+	}
+	if got := font.GaspBehavior(12); got != GaspDoGray {
+		t.Fatalf("unexpected default behavior %d", got)
+	}
+	// empty table, with hinting
+	font = loadFont(t, "04B_30.ttf")
+	if got := font.GaspBehavior(12); got != GaspGridfit|GaspDoGray {
+		t.Fatalf("unexpected default behavior %d", got)
+	}
+
+	// version 0 ignores the symmetric flags
+	gasp, err := parseTableGasp([]byte{0, 0, 0, 1, 0xFF, 0xFF, 0, 0x0F})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := gasp.Behavior(0xFFFF); !ok || b != GaspGridfit|GaspDoGray {
+		t.Fatalf("unexpected behavior %d", b)
+	}
+
+	for _, data := range [][]byte{
+		{0, 1, 0, 2, 0xFF, 0xFF, 0, 0x0F},
+		{0, 1, 0, 2, 0, 10, 0, 1, 0, 8, 0, 2}, // unsorted
+	} {
+		if _, err := parseTableGasp(data); err == nil {
+			t.Fatalf("expected error for %v", data)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package truetype
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MVAR tags not used when querying the metrics of a variable font,
+// but needed to instantiate the global metrics
+var (
+	tagHorizontalClippingAscent  = MustNewTag("hcla")
+	tagHorizontalClippingDescent = MustNewTag("hcld")
+	tagHorizontalCaretRise       = MustNewTag("hcrs")
+	tagHorizontalCaretRun        = MustNewTag("hcrn")
+	tagHorizontalCaretOffset     = MustNewTag("hcof")
+	tagVerticalCaretRise         = MustNewTag("vcrs")
+	tagVerticalCaretRun          = MustNewTag("vcrn")
+	tagVerticalCaretOffset       = MustNewTag("vcof")
+	tagSubscriptXSize            = MustNewTag("sbxs")
+)
+
+// Instance returns a static font for the variation instance given by `design`,
+// which must contain one coordinate for each axis of the font, in design units
+// (see Variations).
+//
+// The 'gvar' deltas are applied to the 'glyf' outlines, 'HVAR' and 'VVAR'
+// (or the phantom points) to the glyph metrics and 'MVAR' to the global metrics.
+// The returned font has no variation tables left, so that it may be used
+// for shaping and embedded as a regular TrueType font (see Subset and WriteSubset).
+//
+// The variations of the 'cvt ' table and of the layout tables (GDEF, GPOS and
+// feature variations) are not instantiated: their default values are used.
+// Fonts with 'CFF2' outlines are not supported: see StaticCFF to instance their outlines.
+func (f *Font) Instance(design []float32) (*Font, error) {
+	if len(f.fvar.Axis) == 0 {
+		return nil, errors.New("font is not variable")
+	}
+	if len(design) != len(f.fvar.Axis) {
+		return nil, fmt.Errorf("invalid number of coordinates: expected %d, got %d", len(f.fvar.Axis), len(design))
+	}
+	if f.cff2 != nil {
+		return nil, errors.New("instancing CFF2 outlines is not supported")
+	}
+
+	varFont := *f
+	varFont.varCoords = f.NormalizeVariations(design)
+
+	out := *f
+	out.varCoords = nil
+	out.fvar = TableFvar{}
+	out.avar = tableAvar{}
+	out.lazy = f.lazy.decodedCopy() // the glyphs are replaced in instanceGlyphs
+	out.hvar, out.vvar = nil, nil
+	out.mvar = TableMvar{}
+	out.knowTables = make(map[Tag]bool, len(f.knowTables))
+	for tag := range f.knowTables {
+		switch tag {
+		case tagFvar, tagAvar, tagGvar, tagHvar, tagVvar, tagMvar:
+		default:
+			out.knowTables[tag] = true
+		}
+	}
+	// the tables modified in place are copied
+	if f.hhea != nil {
+		hhea := *f.hhea
+		out.hhea = &hhea
+	}
+	if f.vhea != nil {
+		vhea := *f.vhea
+		out.vhea = &vhea
+	}
+	if f.OS2 != nil {
+		os2 := *f.OS2
+		out.OS2 = &os2
+	}
+
+	varFont.instanceGlobalMetrics(&out)
+	varFont.instanceGlyphs(&out)
+
+	return &out, nil
+}
+
+func roundInt16(v float32) int16 { return int16(math.Round(float64(v))) }
+
+// instanceGlyphs applies the variations to the glyph outlines and
+// metrics, storing the result in `out`
+func (f *Font) instanceGlyphs(out *Font) {
+	glyf := make(TableGlyf, len(f.Glyf()))
+	phantoms := make([][phantomCount]contourPoint, len(glyf))
+	for i := range glyf {
+		gid := GID(i)
+		glyf[i] = f.instanceGlyph(gid)
+
+		var allPoints []contourPoint
+		f.getPointsForGlyph(gid, 1, &allPoints) // depth 1 skips the left side bearing shift
+		if len(allPoints) >= phantomCount {
+			copy(phantoms[i][:], allPoints[len(allPoints)-phantomCount:])
+		}
+	}
+
+	out.lazy.setGlyphs(glyf, tableGvar{})
+
+	// now that all the simple glyphs are resolved, compute the bounding boxes
+	var (
+		fontBounds   [4]int16 // xMin, yMin, xMax, yMax
+		hasGlyphData bool
+	)
+	for i := range glyf {
+		g := &glyf[i]
+		if g.data == nil {
+			continue
+		}
+		var allPoints []contourPoint
+		out.getPointsForGlyph(GID(i), 1, &allPoints)
+		if len(allPoints) > phantomCount {
+			ext := extentsFromPoints(allPoints)
+			g.Xmin = int16(math.Floor(float64(ext.XBearing)))
+			g.Xmax = int16(math.Ceil(float64(ext.XBearing + ext.Width)))
+			g.Ymin = int16(math.Floor(float64(ext.YBearing + ext.Height)))
+			g.Ymax = int16(math.Ceil(float64(ext.YBearing)))
+		} else {
+			g.Xmin, g.Ymin, g.Xmax, g.Ymax = 0, 0, 0, 0
+		}
+		g.rawdata = g.encode()
+
+		if !hasGlyphData {
+			fontBounds = [4]int16{g.Xmin, g.Ymin, g.Xmax, g.Ymax}
+			hasGlyphData = true
+		} else {
+			fontBounds[0], fontBounds[1] = min16(fontBounds[0], g.Xmin), min16(fontBounds[1], g.Ymin)
+			fontBounds[2], fontBounds[3] = max16(fontBounds[2], g.Xmax), max16(fontBounds[3], g.Ymax)
+		}
+	}
+	if hasGlyphData {
+		out.Head.XMin, out.Head.YMin, out.Head.XMax, out.Head.YMax = fontBounds[0], fontBounds[1], fontBounds[2], fontBounds[3]
+	}
+
+	if len(f.Hmtx) != 0 {
+		out.Hmtx = make(TableHVmtx, len(f.Hmtx))
+		for i := range out.Hmtx {
+			gid := GID(i)
+			out.Hmtx[i].Advance = roundInt16(f.HorizontalAdvance(gid))
+			if i < len(glyf) {
+				out.Hmtx[i].SideBearing = glyf[i].Xmin - roundInt16(phantoms[i][phantomLeft].X)
+			} else {
+				out.Hmtx[i].SideBearing = f.getHorizontalSideBearing(gid)
+			}
+		}
+		if out.hhea != nil {
+			out.hhea.updateMetrics(out.Hmtx, glyf, false)
+		}
+	}
+
+	if len(f.vmtx) != 0 {
+		out.vmtx = make(TableHVmtx, len(f.vmtx))
+		for i := range out.vmtx {
+			gid := GID(i)
+			out.vmtx[i].Advance = roundInt16(-f.VerticalAdvance(gid))
+			if i < len(glyf) {
+				out.vmtx[i].SideBearing = roundInt16(phantoms[i][phantomTop].Y) - glyf[i].Ymax
+			} else {
+				out.vmtx[i].SideBearing = f.getVerticalSideBearing(gid)
+			}
+		}
+		if out.vhea != nil {
+			out.vhea.updateMetrics(out.vmtx, glyf, true)
+		}
+	}
+}
+
+// instanceGlyph returns the glyph data with variations applied.
+// The bounding box is not updated.
+func (f *Font) instanceGlyph(gid GID) GlyphData {
+	g := f.Glyf()[gid]
+	points := f.getOwnPoints(gid)
+	switch data := g.data.(type) {
+	case simpleGlyphData:
+		instance := simpleGlyphData{
+			endPtsOfContours: data.endPtsOfContours,
+			instructions:     data.instructions,
+			points:           make([]glyphContourPoint, len(data.points)),
+		}
+		for i, p := range data.points {
+			instance.points[i] = glyphContourPoint{flag: p.flag, x: roundInt16(points[i].X), y: roundInt16(points[i].Y)}
+		}
+		g.data = instance
+	case compositeGlyphData:
+		instance := compositeGlyphData{
+			instructions: data.instructions,
+			glyphs:       append([]compositeGlyphPart(nil), data.glyphs...),
+		}
+		for i := range instance.glyphs {
+			part := &instance.glyphs[i]
+			if part.isAnchored() { // the position is defined by the (varied) points
+				continue
+			}
+			// the deltas are stored as pseudo points: add them to the offsets
+			tx, ty := part.argsAsTranslation()
+			part.arg1 = uint16(tx + roundInt16(points[i].X))
+			part.arg2 = uint16(ty + roundInt16(points[i].Y))
+			part.flags |= arg1And2AreWords
+		}
+		g.data = instance
+	}
+	g.rawdata = nil // updated once the bounding box is known
+	return g
+}
+
+// updateMetrics updates the summary values of the header table
+// from the given glyph metrics.
+func (t *TableHVhea) updateMetrics(metrics TableHVmtx, glyphs TableGlyf, isVertical bool) {
+	t.AdvanceMax = 0
+	first := true
+	for i, m := range metrics {
+		if uint16(m.Advance) > t.AdvanceMax {
+			t.AdvanceMax = uint16(m.Advance)
+		}
+		if i >= len(glyphs) || glyphs[i].data == nil {
+			continue // empty glyphs are ignored
+		}
+		g := glyphs[i]
+		length := g.Xmax - g.Xmin
+		if isVertical {
+			length = g.Ymax - g.Ymin
+		}
+		secondSideBearing := m.Advance - m.SideBearing - length
+		extent := m.SideBearing + length
+		if first {
+			t.MinFirstSideBearing, t.MinSecondSideBearing, t.MaxExtent = m.SideBearing, secondSideBearing, extent
+			first = false
+			continue
+		}
+		t.MinFirstSideBearing = min16(t.MinFirstSideBearing, m.SideBearing)
+		t.MinSecondSideBearing = min16(t.MinSecondSideBearing, secondSideBearing)
+		t.MaxExtent = max16(t.MaxExtent, extent)
+	}
+}
+
+// instanceGlobalMetrics applies the 'MVAR' deltas to the
+// 'OS/2', 'hhea', 'vhea' and 'post' tables of `out`
+func (f *Font) instanceGlobalMetrics(out *Font) {
+	delta := func(tag Tag) int16 { return roundInt16(f.mvar.getVar(tag, f.varCoords)) }
+	deltaU := func(v uint16, tag Tag) uint16 { return uint16(int32(v) + int32(delta(tag))) }
+
+	if os2 := out.OS2; os2 != nil {
+		os2.STypoAscender += delta(metricsTagHorizontalAscender)
+		os2.STypoDescender += delta(metricsTagHorizontalDescender)
+		os2.STypoLineGap += delta(metricsTagHorizontalLineGap)
+		os2.UsWinAscent = deltaU(os2.UsWinAscent, tagHorizontalClippingAscent)
+		os2.UsWinDescent = deltaU(os2.UsWinDescent, tagHorizontalClippingDescent)
+		os2.YSubscriptXSize += delta(tagSubscriptXSize)
+		os2.YSubscriptYSize += delta(tagSubscriptYSize)
+		os2.YSubscriptXOffset += delta(tagSubscriptXOffset)
+		os2.YSubscriptYOffset += delta(tagSubscriptYOffset)
+		os2.YSuperscriptXSize += delta(tagSuperscriptXSize)
+		os2.YSuperscriptYSize += delta(tagSuperscriptYSize)
+		os2.YSuperscriptXOffset += delta(tagSuperscriptXOffset)
+		os2.YSuperscriptYOffset += delta(tagSuperscriptYOffset)
+		os2.YStrikeoutSize += delta(tagStrikeoutSize)
+		os2.YStrikeoutPosition += delta(tagStrikeoutOffset)
+		os2.SxHeigh += delta(tagXHeight)
+		os2.SCapHeight += delta(tagCapHeight)
+	}
+	// as in getPositionCommon, the ascender, descender and line gap
+	// deltas also apply to the 'hhea' values
+	if hhea := out.hhea; hhea != nil {
+		hhea.Ascent += delta(metricsTagHorizontalAscender)
+		hhea.Descent += delta(metricsTagHorizontalDescender)
+		hhea.LineGap += delta(metricsTagHorizontalLineGap)
+		hhea.CaretSlopeRise += delta(tagHorizontalCaretRise)
+		hhea.CaretSlopeRun += delta(tagHorizontalCaretRun)
+		hhea.CaretOffset += delta(tagHorizontalCaretOffset)
+	}
+	if vhea := out.vhea; vhea != nil {
+		vhea.Ascent += delta(metricsTagVerticalAscender)
+		vhea.Descent += delta(metricsTagVerticalDescender)
+		vhea.LineGap += delta(metricsTagVerticalLineGap)
+		vhea.CaretSlopeRise += delta(tagVerticalCaretRise)
+		vhea.CaretSlopeRun += delta(tagVerticalCaretRun)
+		vhea.CaretOffset += delta(tagVerticalCaretOffset)
+	}
+	out.post.UnderlinePosition += delta(tagUnderlineOffset)
+	out.post.UnderlineThickness += delta(tagUnderlineSize)
+}
@@ -0,0 +1,125 @@
+package truetype
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+func absF(v float32) float32 { return float32(math.Abs(float64(v))) }
+
+func TestInstance(t *testing.T) {
+	for _, filename := range []string{
+		"SelawikVar.ttf",
+		"Commissioner-VF.ttf",
+		"SourceSansVariable-Roman-nohvar-41,C1.ttf", // phantom points
+		"SourceSansVariable-Roman.modcomp.ttf",      // composites
+	} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fvar := font.Variations()
+		// use the maximum of each axis
+		design := make([]float32, len(fvar.Axis))
+		for i, axis := range fvar.Axis {
+			design[i] = axis.Maximum
+		}
+		instance, err := font.Instance(design)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(instance.Variations().Axis) != 0 || instance.isVar() {
+			t.Fatalf("%s: instance should not be variable", filename)
+		}
+
+		font.SetVarCoordinates(font.NormalizeVariations(design))
+
+		for gid := range font.Glyf() {
+			gid := GID(gid)
+			if exp, got := roundInt16(font.HorizontalAdvance(gid)), instance.HorizontalAdvance(gid); float32(exp) != got {
+				t.Errorf("%s: glyph %d: expected advance %d, got %g", filename, gid, exp, got)
+			}
+
+			if font.Glyf()[gid].data == nil {
+				continue
+			}
+
+			expExt, _ := font.GlyphExtents(gid, 0, 0)
+			gotExt, _ := instance.GlyphExtents(gid, 0, 0)
+			if absF(expExt.XBearing-gotExt.XBearing) > 1 || absF(expExt.YBearing-gotExt.YBearing) > 1 ||
+				absF(expExt.Width-gotExt.Width) > 2 || absF(expExt.Height-gotExt.Height) > 2 {
+				t.Errorf("%s: glyph %d: expected extents %v, got %v", filename, gid, expExt, gotExt)
+			}
+
+			expOutline := font.GlyphData(gid, 0, 0).(fonts.GlyphOutline)
+			gotOutline := instance.GlyphData(gid, 0, 0).(fonts.GlyphOutline)
+			if len(expOutline.Segments) != len(gotOutline.Segments) {
+				t.Fatalf("%s: glyph %d: expected %d segments, got %d", filename, gid, len(expOutline.Segments), len(gotOutline.Segments))
+			}
+			for i, seg := range expOutline.Segments {
+				gotSeg := gotOutline.Segments[i]
+				for j, p := range seg.ArgsSlice() {
+					q := gotSeg.Args[j]
+					if absF(p.X-q.X) > 1.5 || absF(p.Y-q.Y) > 1.5 {
+						t.Errorf("%s: glyph %d: expected point %v, got %v", filename, gid, p, q)
+					}
+				}
+			}
+		}
+
+		expExt, ok1 := font.FontHExtents()
+		gotExt, ok2 := instance.FontHExtents()
+		if ok1 != ok2 || absF(expExt.Ascender-gotExt.Ascender) > 0.5 ||
+			absF(expExt.Descender-gotExt.Descender) > 0.5 || absF(expExt.LineGap-gotExt.LineGap) > 0.5 {
+			t.Errorf("%s: expected font extents %v, got %v", filename, expExt, gotExt)
+		}
+		for _, metric := range []fonts.LineMetric{fonts.UnderlinePosition, fonts.StrikethroughThickness, fonts.XHeight, fonts.CapHeight} {
+			exp, _ := font.LineMetric(metric)
+			got, _ := instance.LineMetric(metric)
+			if absF(exp-got) > 0.5 {
+				t.Errorf("%s: metric %d: expected %g, got %g", filename, metric, exp, got)
+			}
+		}
+
+		// the original font is not modified
+		font.SetVarCoordinates(nil)
+		if font.HorizontalAdvance(1) != float32(font.Hmtx[1].Advance) {
+			t.Errorf("%s: original font modified", filename)
+		}
+	}
+}
+
+func TestInstanceInvalid(t *testing.T) {
+	file, err := testdata.Files.ReadFile("Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = font.Instance(nil); err == nil {
+		t.Error("expected error for a static font")
+	}
+
+	file, err = testdata.Files.ReadFile("SelawikVar.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = font.Instance([]float32{400, 400, 400, 400, 400}); err == nil {
+		t.Error("expected error for invalid coordinates")
+	}
+}
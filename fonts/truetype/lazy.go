@@ -0,0 +1,367 @@
+package truetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+var (
+	glyfTags   = []Tag{tagLoca, tagGlyf, tagGvar}
+	cmapTags   = []Tag{tagCmap}
+	layoutTags = []Tag{TagGdef, TagGsub, TagGpos, tagMath, tagMorx, tagKern, tagKerx, tagAnkr, tagTrak, tagFeat}
+)
+
+// lazyTables stores the tables which are expensive to decode
+// (glyf, cmap and the layout tables), and which are only decoded on first use,
+// so that clients only requiring the metrics do not pay for them.
+// Each group of tables is read from an in-memory copy of the raw tables,
+// so that the font file is not needed after loading; this copy is released
+// once the tables are decoded. Fonts read from a fonts.BytesResource
+// directly use the font content instead.
+// The groups may be shared by the faces of a collection (see sharedTables).
+// A nil *lazyTables has no tables.
+type lazyTables struct {
+	glyf   *lazyGlyphs
+	cmap   *lazyCmaps
+	layout *lazyLayout
+
+	// required to decode the tables
+	numGlyphs  int
+	locaFormat int16
+	fvar       TableFvar
+}
+
+type lazyGlyphs struct {
+	src  *FontParser
+	once sync.Once
+	glyf TableGlyf
+	gvar tableGvar
+
+	// raw 'loca' offsets and 'glyf' table, used to read
+	// the glyph headers without decoding the whole table;
+	// loca is nil if the table is missing or invalid
+	rawOnce sync.Once
+	loca    []uint32
+	rawGlyf []byte
+}
+
+type lazyCmaps struct {
+	src      *FontParser
+	once     sync.Once
+	cmap     Cmap
+	encoding fonts.CmapEncoding
+	cmapVar  unicodeVariations
+
+	// reverse mapping, built on first use
+	reverseOnce sync.Once
+	reverse     map[GID][]rune
+}
+
+type lazyLayout struct {
+	src    *FontParser
+	once   sync.Once
+	layout LayoutTables
+}
+
+// sharedTables stores the groups of lazy tables of the faces
+// of a collection, indexed by the location of their raw tables,
+// so that the tables used by several faces are only decoded once.
+type sharedTables struct {
+	glyphs  map[string]*lazyGlyphs
+	cmaps   map[string]*lazyCmaps
+	layouts map[string]*lazyLayout
+}
+
+func newSharedTables() *sharedTables {
+	return &sharedTables{
+		glyphs:  make(map[string]*lazyGlyphs),
+		cmaps:   make(map[string]*lazyCmaps),
+		layouts: make(map[string]*lazyLayout),
+	}
+}
+
+// inMemory returns a parser for the tables among `tags`
+// present in the font, copying their content, unless
+// the font content is already in memory.
+func (pr *FontParser) inMemory(tags []Tag) (*FontParser, error) {
+	var buf []byte
+	out := &FontParser{tables: make(map[Tag]tableSection), Type: pr.Type, isBinary: pr.isBinary}
+	if pr.data != nil { // simply share the content
+		out.file, out.data = pr.file, pr.data
+		for _, tag := range tags {
+			if section, has := pr.tables[tag]; has {
+				out.tables[tag] = section
+			}
+		}
+		return out, nil
+	}
+	for _, tag := range tags {
+		if !pr.HasTable(tag) {
+			continue
+		}
+		table, err := pr.GetRawTable(tag)
+		if err != nil {
+			return nil, err
+		}
+		length := uint32(len(table))
+		out.tables[tag] = tableSection{offset: uint32(len(buf)), length: length, zLength: length}
+		buf = append(buf, table...)
+	}
+	out.file = bytes.NewReader(buf)
+	return out, nil
+}
+
+// sectionsKey identifies the location of the tables among `tags`
+// in the font file, and the decoding parameters `params`.
+func (pr *FontParser) sectionsKey(tags []Tag, params ...interface{}) string {
+	var key strings.Builder
+	for _, tag := range tags {
+		if s, ok := pr.tables[tag]; ok {
+			fmt.Fprintf(&key, "%s:%d:%d:%d;", tag, s.offset, s.length, s.zLength)
+		}
+	}
+	fmt.Fprint(&key, params...)
+	return key.String()
+}
+
+// newLazyTables copies the raw tables needed for the lazy decoding.
+// If `shared` is not nil, the groups of tables already used by another face
+// with the same raw tables are reused.
+func (pr *FontParser) newLazyTables(numGlyphs int, locaFormat int16, fvar TableFvar, shared *sharedTables) (*lazyTables, error) {
+	out := lazyTables{numGlyphs: numGlyphs, locaFormat: locaFormat, fvar: fvar}
+
+	// fvar is included since the variation tables depend on it
+	glyfKey := pr.sectionsKey(append(glyfTags, tagFvar), numGlyphs, locaFormat)
+	cmapKey := pr.sectionsKey(cmapTags)
+	layoutKey := pr.sectionsKey(append(layoutTags, tagFvar), numGlyphs)
+	if shared != nil {
+		out.glyf, out.cmap, out.layout = shared.glyphs[glyfKey], shared.cmaps[cmapKey], shared.layouts[layoutKey]
+	}
+
+	if out.glyf == nil {
+		src, err := pr.inMemory(glyfTags)
+		if err != nil {
+			return nil, err
+		}
+		out.glyf = &lazyGlyphs{src: src}
+	}
+	if out.cmap == nil {
+		src, err := pr.inMemory(cmapTags)
+		if err != nil {
+			return nil, err
+		}
+		out.cmap = &lazyCmaps{src: src}
+	}
+	if out.layout == nil {
+		src, err := pr.inMemory(layoutTags)
+		if err != nil {
+			return nil, err
+		}
+		out.layout = &lazyLayout{src: src}
+	}
+
+	if shared != nil {
+		shared.glyphs[glyfKey], shared.cmaps[cmapKey], shared.layouts[layoutKey] = out.glyf, out.cmap, out.layout
+	}
+	return &out, nil
+}
+
+// loadRaw reads the 'loca' and 'glyf' tables, without decoding them.
+// It is always called before g.src is released, so that g.src
+// is never accessed concurrently with its release.
+func (g *lazyGlyphs) loadRaw(numGlyphs int, locaFormat int16) {
+	g.rawOnce.Do(func() {
+		if g.src == nil {
+			return
+		}
+		buf, err := g.src.GetRawTable(tagLoca)
+		if err != nil {
+			return
+		}
+		loca, err := parseTableLoca(buf, numGlyphs, locaFormat == 1)
+		if err != nil {
+			return
+		}
+		g.rawGlyf, err = g.src.GetRawTable(tagGlyf)
+		if err != nil {
+			return
+		}
+		g.loca = loca
+	})
+}
+
+func (lt *lazyTables) glyphs() (TableGlyf, tableGvar) {
+	if lt == nil || lt.glyf == nil {
+		return nil, tableGvar{}
+	}
+	g := lt.glyf
+	g.once.Do(func() {
+		if g.src == nil {
+			return
+		}
+		g.loadRaw(lt.numGlyphs, lt.locaFormat)
+		// errors are ignored, as for the eagerly loaded optional tables
+		if g.loca != nil {
+			g.glyf, _ = parseTableGlyf(g.rawGlyf, g.loca)
+		}
+		if len(lt.fvar.Axis) != 0 {
+			g.gvar, _ = g.src.gvarTable(g.glyf, lt.fvar)
+		}
+		g.src = nil
+	})
+	return g.glyf, g.gvar
+}
+
+// glyphHeader returns the glyph `gid` with only its bounding box,
+// read from the glyph header without decoding the whole 'glyf' table
+// when possible.
+// It returns false if the font has no glyph `gid`.
+func (lt *lazyTables) glyphHeader(gid GID) (GlyphData, bool) {
+	if lt == nil || lt.glyf == nil {
+		return GlyphData{}, false
+	}
+	g := lt.glyf
+	g.loadRaw(lt.numGlyphs, lt.locaFormat)
+	if g.loca == nil { // fall back to the decoded table
+		glyf, _ := lt.glyphs()
+		if int(gid) >= len(glyf) {
+			return GlyphData{}, false
+		}
+		return glyf[gid], true
+	}
+
+	if int(gid) >= len(g.loca)-1 {
+		return GlyphData{}, false
+	}
+	start, end := g.loca[gid], g.loca[gid+1]
+	if start == end { // no outline
+		return GlyphData{}, true
+	}
+	if end < start || int(end) > len(g.rawGlyf) || end-start < 10 {
+		return GlyphData{}, false
+	}
+	header := g.rawGlyf[start:]
+	return GlyphData{
+		Xmin: int16(binary.BigEndian.Uint16(header[2:])),
+		Ymin: int16(binary.BigEndian.Uint16(header[4:])),
+		Xmax: int16(binary.BigEndian.Uint16(header[6:])),
+		Ymax: int16(binary.BigEndian.Uint16(header[8:])),
+	}, true
+}
+
+func (lt *lazyTables) cmaps() (Cmap, fonts.CmapEncoding, unicodeVariations) {
+	if lt == nil || lt.cmap == nil {
+		return nil, fonts.EncOther, nil
+	}
+	c := lt.cmap
+	c.once.Do(func() {
+		if c.src == nil {
+			return
+		}
+		if cmaps, err := c.src.CmapTable(); err == nil {
+			c.cmap, c.encoding = cmaps.BestEncoding()
+			c.cmapVar = cmaps.unicodeVariation
+		}
+		c.src = nil
+	})
+	return c.cmap, c.encoding, c.cmapVar
+}
+
+// reverseCmap returns the runes mapped to each glyph by the cmap,
+// sorted in increasing order.
+func (lt *lazyTables) reverseCmap() map[GID][]rune {
+	cmap, _, _ := lt.cmaps()
+	if cmap == nil {
+		return nil
+	}
+	c := lt.cmap
+	c.reverseOnce.Do(func() {
+		c.reverse = make(map[GID][]rune)
+		for iter := cmap.Iter(); iter.Next(); {
+			r, gid := iter.Char()
+			c.reverse[gid] = append(c.reverse[gid], r)
+		}
+		for _, runes := range c.reverse {
+			sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+		}
+	})
+	return c.reverse
+}
+
+func (lt *lazyTables) layoutTables() *LayoutTables {
+	if lt == nil {
+		return new(LayoutTables)
+	}
+	if lt.layout == nil {
+		lt.layout = new(lazyLayout)
+	}
+	l := lt.layout
+	l.once.Do(func() {
+		if l.src == nil {
+			return
+		}
+		l.layout = l.src.loadLayoutTables(lt.numGlyphs, lt.fvar)
+		l.src = nil
+	})
+	return &l.layout
+}
+
+// decodedCopy decodes all the tables and returns a copy,
+// which may be modified without affecting `lt`.
+func (lt *lazyTables) decodedCopy() *lazyTables {
+	var out lazyTables
+	out.setGlyphs(lt.glyphs())
+	out.setCmaps(lt.cmaps())
+	out.setLayoutTables(*lt.layoutTables())
+	out.numGlyphs, out.locaFormat, out.fvar = lt.numGlyphs, lt.locaFormat, lt.fvar
+	return &out
+}
+
+// the following setters disable the lazy decoding,
+// and must not be called concurrently with the accessors;
+// since they replace the groups of tables, the faces sharing them are not affected
+
+func (lt *lazyTables) setGlyphs(glyf TableGlyf, gvar tableGvar) {
+	lt.glyf = &lazyGlyphs{glyf: glyf, gvar: gvar}
+	lt.glyf.once.Do(func() {})
+	lt.glyf.rawOnce.Do(func() {}) // use the decoded glyphs for the headers
+}
+
+func (lt *lazyTables) setCmaps(cmap Cmap, encoding fonts.CmapEncoding, cmapVar unicodeVariations) {
+	lt.cmap = &lazyCmaps{cmap: cmap, encoding: encoding, cmapVar: cmapVar}
+	lt.cmap.once.Do(func() {})
+}
+
+func (lt *lazyTables) setLayoutTables(layout LayoutTables) {
+	lt.layout = &lazyLayout{layout: layout}
+	lt.layout.once.Do(func() {})
+}
+
+// tables returns the lazy tables of the font, which
+// are created if needed, so that they may be set.
+func (f *Font) tables() *lazyTables {
+	if f.lazy == nil {
+		f.lazy = new(lazyTables)
+	}
+	return f.lazy
+}
+
+// Glyf returns the 'glyf' table, decoding it on first use.
+// It is nil for fonts without TrueType outlines.
+// The returned table should not be modified.
+func (f *Font) Glyf() TableGlyf {
+	glyf, _ := f.lazy.glyphs()
+	return glyf
+}
+
+// LayoutTables returns the valid advanced layout tables, decoding
+// them on first use.
+// When parsing yields an error, it is ignored and an empty table is returned.
+// See the individual methods of FontParser for more control over error handling.
+func (f *Font) LayoutTables() LayoutTables { return *f.lazy.layoutTables() }
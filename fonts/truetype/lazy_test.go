@@ -0,0 +1,235 @@
+package truetype
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+func TestLazyTables(t *testing.T) {
+	file, err := testdata.Files.ReadFile("Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := NewFontParser(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	glyf, err := pr.GlyfTable(font.NumGlyphs, font.Head.indexToLocFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpos, err := pr.GPOSTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tables may be decoded concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			font.GlyphExtents(1, 0, 0)
+			font.Glyf()
+			font.NominalGlyph('a')
+			font.LayoutTables()
+		}()
+	}
+	wg.Wait()
+
+	if !reflect.DeepEqual(font.Glyf(), glyf) {
+		t.Fatal("invalid lazy glyf table")
+	}
+	if !reflect.DeepEqual(font.LayoutTables().GPOS, gpos) {
+		t.Fatal("invalid lazy GPOS table")
+	}
+	if gid, ok := font.NominalGlyph('a'); !ok || gid == 0 {
+		t.Fatal("invalid lazy cmap table")
+	}
+	if font.lazy.glyf.src != nil || font.lazy.cmap.src != nil || font.lazy.layout.src != nil {
+		t.Fatal("raw tables should be released once decoded")
+	}
+}
+
+func TestLoadCollection(t *testing.T) {
+	file, err := testdata.Files.ReadFile("Bangla Sangam MN.ttc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	faces, err := Load(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 2 {
+		t.Fatalf("expected 2 faces, got %d", len(faces))
+	}
+	regular, bold := faces[0].(*Font), faces[1].(*Font)
+	// the faces use the same 'cmap' table but different outlines
+	if regular.lazy.cmap != bold.lazy.cmap || regular.lazy.glyf == bold.lazy.glyf {
+		t.Fatal("unexpected shared tables")
+	}
+
+	for i, face := range faces {
+		font, err := ParseIndex(bytes.NewReader(file), i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if font.Names.getName(NameFull) != face.(*Font).Names.getName(NameFull) {
+			t.Fatalf("unexpected face %d", i)
+		}
+		if !reflect.DeepEqual(font.Glyf(), face.(*Font).Glyf()) {
+			t.Fatalf("invalid glyf table for face %d", i)
+		}
+	}
+	if _, err := ParseIndex(bytes.NewReader(file), 2); err == nil {
+		t.Fatal("expected error for invalid index")
+	}
+
+	// modifying a face does not affect the others
+	gid, ok := bold.NominalGlyph(0x0995)
+	if !ok {
+		t.Fatal("missing glyph")
+	}
+	regular.tables().setCmaps(nil, 0, nil)
+	if g, _ := bold.NominalGlyph(0x0995); g != gid {
+		t.Fatal("shared cmap should not be modified")
+	}
+
+	file, err = testdata.Files.ReadFile("Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseIndex(bytes.NewReader(file), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseIndex(bytes.NewReader(file), 1); err == nil {
+		t.Fatal("expected error for invalid index")
+	}
+}
+
+func TestBytesResource(t *testing.T) {
+	for _, filename := range []string{
+		"Roboto-BoldItalic.ttf",
+		"Raleway-v4020-Regular.otf",
+		"open-sans-v15-latin-regular.woff",
+		"Bangla Sangam MN.ttc",
+	} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := Load(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		faces, err := Load(fonts.NewBytesResource(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(faces) != len(expected) {
+			t.Fatalf("%s: unexpected number of faces %d", filename, len(faces))
+		}
+		for i, face := range faces {
+			font, exp := face.(*Font), expected[i].(*Font)
+			if !reflect.DeepEqual(font.Glyf(), exp.Glyf()) || !reflect.DeepEqual(font.LayoutTables(), exp.LayoutTables()) {
+				t.Fatalf("%s: different tables", filename)
+			}
+			expGID, _ := exp.NominalGlyph('a')
+			if gid, _ := font.NominalGlyph('a'); gid != expGID {
+				t.Fatalf("%s: unexpected glyph %d", filename, gid)
+			}
+		}
+	}
+
+	// uncompressed tables are not copied
+	file, err := testdata.Files.ReadFile("Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := NewFontParser(fonts.NewBytesResource(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := pr.GetRawTable(tagHead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &head[0] != &file[pr.tables[tagHead].offset] || cap(head) != len(head) {
+		t.Fatal("table should point into the font content")
+	}
+	src, err := pr.inMemory(glyfTags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if glyf, _ := src.GetRawTable(tagGlyf); &glyf[0] != &file[pr.tables[tagGlyf].offset] {
+		t.Fatal("lazy tables should share the font content")
+	}
+
+	// truncated content
+	pr, err = NewFontParser(fonts.NewBytesResource(file[:pr.tables[tagHead].offset+10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pr.GetRawTable(tagHead); err == nil {
+		t.Fatal("expected error for truncated content")
+	}
+}
+
+func TestGlyphHeader(t *testing.T) {
+	for _, filename := range []string{
+		"Roboto-BoldItalic.ttf",
+		"FreeSerif.ttf",
+		"04B_30.ttf",
+	} {
+		font := loadFont(t, filename)
+
+		// the extents do not require decoding the 'glyf' table
+		extents, ok := font.GlyphExtents(GID(font.NumGlyphs-1), 0, 0)
+		if !ok {
+			t.Fatalf("%s: missing extents", filename)
+		}
+		if font.lazy.glyf.src == nil {
+			t.Fatalf("%s: 'glyf' table should not be decoded", filename)
+		}
+
+		glyf := font.Glyf()
+		for gid, glyph := range glyf {
+			header, ok := font.lazy.glyphHeader(GID(gid))
+			if !ok {
+				t.Fatalf("%s: missing header for glyph %d", filename, gid)
+			}
+			if header.Xmin != glyph.Xmin || header.Ymin != glyph.Ymin || header.Xmax != glyph.Xmax || header.Ymax != glyph.Ymax {
+				t.Fatalf("%s: invalid header for glyph %d", filename, gid)
+			}
+		}
+		if exp := glyf[len(glyf)-1].getExtents(font.Hmtx, GID(len(glyf)-1)); extents != exp {
+			t.Fatalf("%s: expected %v, got %v", filename, exp, extents)
+		}
+		if _, ok := font.lazy.glyphHeader(GID(len(glyf))); ok {
+			t.Fatalf("%s: expected no header for out of range glyph", filename)
+		}
+
+		// the headers of modified glyphs are used
+		glyf = append(TableGlyf(nil), glyf...)
+		glyf[1].Xmax++
+		font.tables().setGlyphs(glyf, tableGvar{})
+		if header, _ := font.lazy.glyphHeader(1); header.Xmax != glyf[1].Xmax {
+			t.Fatalf("%s: header should use the modified glyphs", filename)
+		}
+	}
+
+	// fonts without 'glyf' table
+	font := loadFont(t, "CFFTest.otf")
+	if _, ok := font.lazy.glyphHeader(1); ok {
+		t.Fatal("expected no glyph header for a CFF font")
+	}
+}
@@ -1,9 +1,14 @@
 package truetype
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/boxesandglue/textlayout/fonts"
+	"github.com/boxesandglue/textlayout/fonts/glyphsnames"
 )
 
 var _ fonts.FaceMetrics = (*Font)(nil)
@@ -19,7 +24,21 @@ func (f *Font) GetGlyphContourPoint(glyph fonts.GID, pointIndex uint16) (x, y in
 	return 0, 0, false
 }
 
+// GlyphName returns the name of `glyph`, read from the 'post' table,
+// or the charset of the 'CFF ' table.
+// For fonts without names, "gidN" is returned, where N is the glyph index.
+// An empty string is returned for glyphs out of range.
 func (f *Font) GlyphName(glyph GID) string {
+	if name := f.storedGlyphName(glyph); name != "" {
+		return name
+	}
+	if int(glyph) < f.NumGlyphs {
+		return fmt.Sprintf("gid%d", glyph)
+	}
+	return ""
+}
+
+func (f *Font) storedGlyphName(glyph GID) string {
 	if postNames := f.post.Names; postNames != nil {
 		if name := postNames.GlyphName(glyph); name != "" {
 			return name
@@ -31,6 +50,54 @@ func (f *Font) GlyphName(glyph GID) string {
 	return ""
 }
 
+// glyphNameIndex is the reverse mapping of the glyph names,
+// built on first use.
+type glyphNameIndex struct {
+	once   sync.Once
+	glyphs map[string]GID
+}
+
+func (f *Font) buildGlyphNameIndex() map[string]GID {
+	out := make(map[string]GID)
+	for gid := 0; gid < f.NumGlyphs; gid++ {
+		name := f.storedGlyphName(GID(gid))
+		if _, has := out[name]; name != "" && !has { // keep the first glyph
+			out[name] = GID(gid)
+		}
+	}
+	return out
+}
+
+// GlyphByName returns the glyph named `name`, as returned by GlyphName.
+// If the font has no such name, Adobe Glyph List names without suffix
+// (such as "uni00E9" or "eacute") are resolved using the 'cmap' table.
+func (f *Font) GlyphByName(name string) (GID, bool) {
+	var glyphs map[string]GID
+	if f.glyphNames != nil {
+		f.glyphNames.once.Do(func() { f.glyphNames.glyphs = f.buildGlyphNameIndex() })
+		glyphs = f.glyphNames.glyphs
+	} else {
+		glyphs = f.buildGlyphNameIndex()
+	}
+	if gid, ok := glyphs[name]; ok {
+		return gid, true
+	}
+
+	// synthesized names
+	if index := strings.TrimPrefix(name, "gid"); len(index) != len(name) {
+		if gid, err := strconv.ParseUint(index, 10, 32); err == nil && int(gid) < f.NumGlyphs {
+			return GID(gid), true
+		}
+	}
+	// names with a suffix (like "a.sc") usually denote unencoded variants
+	if !strings.Contains(name, ".") {
+		if r, ok := glyphsnames.GlyphToRune(name); ok {
+			return f.NominalGlyph(r)
+		}
+	}
+	return 0, false
+}
+
 func (f *Font) Upem() uint16 { return f.upem }
 
 var (
@@ -52,27 +119,91 @@ func fixAscenderDescender(value float32, metricsTag Tag) float32 {
 	return value
 }
 
+// LineMetricsSource selects the table providing the horizontal
+// ascender, descender and line gap of a font (see Font.SetLineMetricsSource).
+type LineMetricsSource uint8
+
+const (
+	// LineMetricsAuto uses the 'OS/2' typographic metrics if the USE_TYPO_METRICS
+	// flag of the fsSelection field is set, and the 'hhea' metrics otherwise.
+	// This is the behavior of HarfBuzz, and the default.
+	LineMetricsAuto LineMetricsSource = iota
+	// LineMetricsHhea uses the 'hhea' metrics.
+	LineMetricsHhea
+	// LineMetricsTypo uses the sTypoAscender, sTypoDescender and sTypoLineGap
+	// fields of the 'OS/2' table.
+	LineMetricsTypo
+	// LineMetricsWin uses the usWinAscent and usWinDescent fields of the 'OS/2'
+	// table, with a zero line gap, since the Windows metrics already include
+	// the space between lines.
+	LineMetricsWin
+)
+
+var (
+	metricsTagHorizontalClippingAscent  = MustNewTag("hcla")
+	metricsTagHorizontalClippingDescent = MustNewTag("hcld")
+)
+
+// SetLineMetricsSource selects the table used by FontHExtents.
+// When the requested table is missing, the LineMetricsAuto behavior is used.
+func (f *Font) SetLineMetricsSource(source LineMetricsSource) { f.lineMetrics = source }
+
+// lineMetricsSource resolves f.lineMetrics according to the available tables.
+func (f *Font) lineMetricsSource() LineMetricsSource {
+	switch f.lineMetrics {
+	case LineMetricsHhea:
+		if f.hhea != nil {
+			return LineMetricsHhea
+		}
+	case LineMetricsTypo, LineMetricsWin:
+		if f.OS2.hasData() {
+			return f.lineMetrics
+		}
+	}
+	if f.OS2.useTypoMetrics() && f.OS2.hasData() {
+		return LineMetricsTypo
+	}
+	return LineMetricsHhea
+}
+
 func (f *Font) getPositionCommon(metricTag Tag) (float32, bool) {
 	deltaVar := f.mvar.getVar(metricTag, f.varCoords)
+	source := f.lineMetricsSource()
 	switch metricTag {
 	case metricsTagHorizontalAscender:
-		if f.OS2.useTypoMetrics() && f.OS2.hasData() {
+		switch source {
+		case LineMetricsTypo:
 			return fixAscenderDescender(float32(f.OS2.STypoAscender)+deltaVar, metricTag), true
-		} else if f.hhea != nil {
-			return fixAscenderDescender(float32(f.hhea.Ascent)+deltaVar, metricTag), true
+		case LineMetricsWin:
+			deltaVar = f.mvar.getVar(metricsTagHorizontalClippingAscent, f.varCoords)
+			return fixAscenderDescender(float32(f.OS2.UsWinAscent)+deltaVar, metricTag), true
+		default:
+			if f.hhea != nil {
+				return fixAscenderDescender(float32(f.hhea.Ascent)+deltaVar, metricTag), true
+			}
 		}
-
 	case metricsTagHorizontalDescender:
-		if f.OS2.useTypoMetrics() && f.OS2.hasData() {
+		switch source {
+		case LineMetricsTypo:
 			return fixAscenderDescender(float32(f.OS2.STypoDescender)+deltaVar, metricTag), true
-		} else if f.hhea != nil {
-			return fixAscenderDescender(float32(f.hhea.Descent)+deltaVar, metricTag), true
+		case LineMetricsWin: // usWinDescent is positive below the baseline
+			deltaVar = f.mvar.getVar(metricsTagHorizontalClippingDescent, f.varCoords)
+			return fixAscenderDescender(float32(f.OS2.UsWinDescent)+deltaVar, metricTag), true
+		default:
+			if f.hhea != nil {
+				return fixAscenderDescender(float32(f.hhea.Descent)+deltaVar, metricTag), true
+			}
 		}
 	case metricsTagHorizontalLineGap:
-		if f.OS2.useTypoMetrics() && f.OS2.hasData() {
+		switch source {
+		case LineMetricsTypo:
 			return fixAscenderDescender(float32(f.OS2.STypoLineGap)+deltaVar, metricTag), true
-		} else if f.hhea != nil {
-			return fixAscenderDescender(float32(f.hhea.LineGap)+deltaVar, metricTag), true
+		case LineMetricsWin:
+			return 0, true
+		default:
+			if f.hhea != nil {
+				return fixAscenderDescender(float32(f.hhea.LineGap)+deltaVar, metricTag), true
+			}
 		}
 	case metricsTagVerticalAscender:
 		if f.vhea != nil {
@@ -157,21 +288,44 @@ func (f *Font) LineMetric(metric fonts.LineMetric) (float32, bool) {
 }
 
 func (f *Font) NominalGlyph(ch rune) (GID, bool) {
-	return f.cmap.Lookup(ch)
+	cmap, _, _ := f.lazy.cmaps()
+	if cmap == nil {
+		return 0, false
+	}
+	return cmap.Lookup(ch)
 }
 
-func (f *Font) VariationGlyph(ch, varSelector rune) (GID, bool) {
-	gid, kind := f.cmapVar.getGlyphVariant(ch, varSelector)
+// GlyphRunes returns the runes mapped to `gid` by the cmap, in increasing order,
+// or nil if there are none. It is the reverse of NominalGlyph, and is
+// typically used to build a ToUnicode CMap in PDF files.
+// The reverse mapping is built on first use.
+// The returned slice should not be modified.
+func (f *Font) GlyphRunes(gid GID) []rune { return f.lazy.reverseCmap()[gid] }
+
+// GlyphVariationIndex returns the glyph to use for the variation sequence
+// made of `r` followed by `selector`, such as U+FE0E and U+FE0F for the
+// text and emoji presentations, or the ideographic variation selectors.
+// The sequences are defined by the 'cmap' format 14 subtable, and may
+// use the nominal glyph of `r`.
+// It returns false if the sequence is not supported by the font.
+func (f *Font) GlyphVariationIndex(r, selector rune) (GID, bool) {
+	_, _, cmapVar := f.lazy.cmaps()
+	gid, kind := cmapVar.getGlyphVariant(r, selector)
 	switch kind {
 	case variantNotFound:
 		return 0, false
 	case variantFound:
 		return gid, true
 	default: // variantUseDefault
-		return f.NominalGlyph(ch)
+		return f.NominalGlyph(r)
 	}
 }
 
+// VariationGlyph is the same as GlyphVariationIndex.
+func (f *Font) VariationGlyph(ch, varSelector rune) (GID, bool) {
+	return f.GlyphVariationIndex(ch, varSelector)
+}
+
 // do not take into account variations
 func (f *Font) getBaseAdvance(gid GID, table TableHVmtx) int16 {
 	if int(gid) >= len(table) {
@@ -196,43 +350,29 @@ const (
 
 // use the `glyf` table to fetch the contour points,
 // applying variation if needed.
-// for composite, recursively calls itself; allPoints includes phantom points and will be at least of length 4
+// for composite, recursively calls itself; allPoints includes phantom points and will be at least of length 4,
+// unless the glyph is invalid (too deeply nested): in this case, allPoints is left unchanged.
 func (f *Font) getPointsForGlyph(gid GID, currentDepth int, allPoints *[]contourPoint /* OUT */) {
 	// adapted from harfbuzz/src/hb-ot-glyf-table.hh
 
-	if currentDepth > maxCompositeNesting || int(gid) >= len(f.Glyf) {
+	glyf := f.Glyf()
+	if currentDepth > maxCompositeNesting {
 		return
 	}
-	g := f.Glyf[gid]
-
-	var points []contourPoint
-	if data, ok := g.data.(simpleGlyphData); ok {
-		points = data.getContourPoints() // fetch the "real" points
-	} else { // zeros values are enough
-		points = make([]contourPoint, g.pointNumbersCount())
+	if int(gid) >= len(glyf) { // empty glyph, with zero metrics
+		*allPoints = append(*allPoints, make([]contourPoint, phantomCount)...)
+		return
 	}
+	g := glyf[gid]
 
-	// init phantom point
-	points = append(points, make([]contourPoint, phantomCount)...)
+	points := f.getOwnPoints(gid)
 	phantoms := points[len(points)-phantomCount:]
 
-	hDelta := float32(g.Xmin - f.Hmtx.getSideBearing(gid))
-	vOrig := float32(g.Ymax + f.vmtx.getSideBearing(gid))
-	hAdv := float32(f.getBaseAdvance(gid, f.Hmtx))
-	vAdv := float32(f.getBaseAdvance(gid, f.vmtx))
-	phantoms[phantomLeft].X = hDelta
-	phantoms[phantomRight].X = hAdv + hDelta
-	phantoms[phantomTop].Y = vOrig
-	phantoms[phantomBottom].Y = vOrig - vAdv
-
-	if f.isVar() {
-		f.gvar.applyDeltasToPoints(gid, f.varCoords, points)
-	}
-
 	switch data := g.data.(type) {
 	case simpleGlyphData:
 		*allPoints = append(*allPoints, points...)
 	case compositeGlyphData:
+		start := len(*allPoints)
 		for compIndex, item := range data.glyphs {
 			// recurse on component
 			var compPoints []contourPoint
@@ -241,6 +381,7 @@ func (f *Font) getPointsForGlyph(gid GID, currentDepth int, allPoints *[]contour
 
 			LC := len(compPoints)
 			if LC < phantomCount { // in case of max depth reached
+				*allPoints = (*allPoints)[:start]
 				return
 			}
 
@@ -258,8 +399,11 @@ func (f *Font) getPointsForGlyph(gid GID, currentDepth int, allPoints *[]contour
 				compPoints[i].translate(tx, ty)
 			}
 
+			// point matching: the point p2 of the component is moved to
+			// the point p1 of the glyph (made of the previous components)
 			if item.isAnchored() {
 				p1, p2 := item.argsAsIndices()
+				p1 += start
 				if p1 < len(*allPoints) && p2 < LC {
 					tx, ty := (*allPoints)[p1].X-compPoints[p2].X, (*allPoints)[p1].Y-compPoints[p2].Y
 					for i := range compPoints {
@@ -287,6 +431,40 @@ func (f *Font) getPointsForGlyph(gid GID, currentDepth int, allPoints *[]contour
 	}
 }
 
+// getOwnPoints returns the points defined by the glyph itself, that is
+// the contour points for simple glyphs and one pseudo point per component
+// for composite glyphs, followed by the phantom points.
+// Variations are applied if needed.
+func (f *Font) getOwnPoints(gid GID) []contourPoint {
+	glyf, gvar := f.lazy.glyphs()
+	g := glyf[gid]
+
+	var points []contourPoint
+	if data, ok := g.data.(simpleGlyphData); ok {
+		points = data.getContourPoints() // fetch the "real" points
+	} else { // zeros values are enough
+		points = make([]contourPoint, g.pointNumbersCount())
+	}
+
+	// init phantom point
+	points = append(points, make([]contourPoint, phantomCount)...)
+	phantoms := points[len(points)-phantomCount:]
+
+	hDelta := float32(g.Xmin - f.Hmtx.getSideBearing(gid))
+	vOrig := float32(g.Ymax + f.vmtx.getSideBearing(gid))
+	hAdv := float32(f.getBaseAdvance(gid, f.Hmtx))
+	vAdv := float32(f.getBaseAdvance(gid, f.vmtx))
+	phantoms[phantomLeft].X = hDelta
+	phantoms[phantomRight].X = hAdv + hDelta
+	phantoms[phantomTop].Y = vOrig
+	phantoms[phantomBottom].Y = vOrig - vAdv
+
+	if f.isVar() {
+		gvar.applyDeltasToPoints(gid, f.varCoords, points)
+	}
+	return points
+}
+
 func extentsFromPoints(allPoints []contourPoint) (ext fonts.GlyphExtents) {
 	truePoints := allPoints[:len(allPoints)-phantomCount]
 	if len(truePoints) == 0 {
@@ -311,11 +489,14 @@ func extentsFromPoints(allPoints []contourPoint) (ext fonts.GlyphExtents) {
 // walk through the contour points of the given glyph to compute its extends and its phantom points
 // As an optimization, if `computeExtents` is false, the extents computation is skipped (a zero value is returned).
 func (f *Font) getGlyfPoints(gid GID, computeExtents bool) (ext fonts.GlyphExtents, ph [phantomCount]contourPoint) {
-	if int(gid) >= len(f.Glyf) {
+	if int(gid) >= len(f.Glyf()) {
 		return
 	}
 	var allPoints []contourPoint
 	f.getPointsForGlyph(gid, 0, &allPoints)
+	if len(allPoints) < phantomCount { // invalid composite glyph
+		return
+	}
 
 	copy(ph[:], allPoints[len(allPoints)-phantomCount:])
 
@@ -361,6 +542,9 @@ func (f *Font) isVar() bool {
 	return len(f.varCoords) != 0 && len(f.varCoords) == len(f.fvar.Axis)
 }
 
+// VerticalAdvance returns the vertical advance of `gid`, from the
+// 'vmtx' table (or the units per em if it is missing), with the variations applied.
+// The advance is negative, since the Y axis is upward.
 func (f *Font) VerticalAdvance(gid GID) float32 {
 	// return the opposite of the advance from the font
 	advance := f.getBaseAdvance(gid, f.vmtx)
@@ -412,12 +596,19 @@ func (f *Font) GlyphHOrigin(GID) (x, y int32, found bool) {
 	return 0, 0, true
 }
 
+// GlyphVOrigin returns the origin of `glyph` used in vertical layout,
+// relative to its horizontal origin, expressed in font units.
+// The 'VORG' table is used if present, then the 'vmtx' table for TrueType outlines,
+// and finally the font ascender.
 func (f *Font) GlyphVOrigin(glyph GID) (x, y int32, found bool) {
 	x = int32(f.HorizontalAdvance(glyph) / 2)
 
 	if f.vorg != nil {
-		y = int32(f.vorg.getYOrigin(glyph))
-		return x, y, true
+		origin := float32(f.vorg.getYOrigin(glyph))
+		if f.vvar != nil && f.isVar() {
+			origin += f.vvar.getOriginVar(glyph, f.varCoords)
+		}
+		return x, int32(roundInt16(origin)), true
 	}
 
 	if extents, ok := f.getExtentsFromGlyf(glyph); ok {
@@ -433,14 +624,18 @@ func (f *Font) GlyphVOrigin(glyph GID) (x, y int32, found bool) {
 }
 
 func (f *Font) getExtentsFromGlyf(glyph GID) (fonts.GlyphExtents, bool) {
-	if int(glyph) >= len(f.Glyf) {
-		return fonts.GlyphExtents{}, false
-	}
-	g := f.Glyf[glyph]
 	if f.isVar() { // we have to compute the outline points and apply variations
+		if int(glyph) >= len(f.Glyf()) {
+			return fonts.GlyphExtents{}, false
+		}
 		extents, _ := f.getGlyfPoints(glyph, true)
 		return extents, true
 	}
+	// fast path: the bounding box is read from the glyph header
+	g, ok := f.lazy.glyphHeader(glyph)
+	if !ok {
+		return fonts.GlyphExtents{}, false
+	}
 	return g.getExtents(f.Hmtx, glyph), true
 }
 
@@ -500,8 +695,16 @@ func (f *Font) getExtentsFromCff1(glyph GID) (fonts.GlyphExtents, bool) {
 	return bounds.ToExtents(), true
 }
 
-// func (f *fontMetrics) getExtentsFromCff2(glyph , coords []float32) (fonts.GlyphExtents, bool) {
-// }
+func (f *Font) getExtentsFromCff2(glyph GID) (fonts.GlyphExtents, bool) {
+	if f.cff2 == nil {
+		return fonts.GlyphExtents{}, false
+	}
+	_, bounds, err := f.cff2.LoadGlyph(glyph, f.varCoords)
+	if err != nil {
+		return fonts.GlyphExtents{}, false
+	}
+	return bounds.ToExtents(), true
+}
 
 func (f *Font) GlyphExtents(glyph GID, xPpem, yPpem uint16) (fonts.GlyphExtents, bool) {
 	out, ok := f.getExtentsFromSbix(glyph, xPpem, yPpem)
@@ -516,6 +719,10 @@ func (f *Font) GlyphExtents(glyph GID, xPpem, yPpem uint16) (fonts.GlyphExtents,
 	if ok {
 		return out, ok
 	}
+	out, ok = f.getExtentsFromCff2(glyph)
+	if ok {
+		return out, ok
+	}
 	out, ok = f.getExtentsFromCBDT(glyph, xPpem, yPpem)
 	return out, ok
 }
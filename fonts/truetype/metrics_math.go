@@ -0,0 +1,115 @@
+package truetype
+
+// This file implements the accessors of the 'MATH' table.
+// The returned values are expressed in font units, and the
+// variations are applied for variable fonts.
+
+// mathValue returns the value of `record`, applying the
+// variations stored in the 'GDEF' table, if any.
+// Hinting device tables are ignored, since they depend on the ppem.
+func (f *Font) mathValue(record MathValueRecord) float32 {
+	value := float32(record.Value)
+	if device, ok := record.Device.(DeviceVariation); ok && len(f.varCoords) != 0 {
+		value += f.lazy.layoutTables().GDEF.VariationStore.GetDelta(VariationStoreIndex(device), f.varCoords)
+	}
+	return value
+}
+
+// MathConstant returns the value of the given constant.
+// For the percentage constants (MathScriptPercentScaleDown,
+// MathScriptScriptPercentScaleDown and MathRadicalDegreeBottomRaisePercent),
+// the returned value is a percentage.
+// Zero is returned for invalid constants.
+func (f *Font) MathConstant(constant MathConstant) float32 {
+	if constant >= mathConstantsCount {
+		return 0
+	}
+	return f.mathValue(f.lazy.layoutTables().Math.Constants[constant])
+}
+
+// MathItalicsCorrection returns the italics correction of `glyph`,
+// or 0 if it is not defined.
+func (f *Font) MathItalicsCorrection(glyph GID) float32 {
+	record, _ := f.lazy.layoutTables().Math.GlyphInfo.ItalicsCorrection.value(glyph)
+	return f.mathValue(record)
+}
+
+// MathTopAccentAttachment returns the horizontal position where
+// accents should be attached to `glyph`.
+// If it is not defined, `false` is returned and the caller
+// should use the middle of the glyph advance.
+func (f *Font) MathTopAccentAttachment(glyph GID) (float32, bool) {
+	record, ok := f.lazy.layoutTables().Math.GlyphInfo.TopAccentAttachment.value(glyph)
+	if !ok {
+		return 0, false
+	}
+	return f.mathValue(record), true
+}
+
+// IsMathExtendedShape returns true if `glyph` is an extended shape,
+// such as a tall parenthesis.
+func (f *Font) IsMathExtendedShape(glyph GID) bool {
+	cov := f.lazy.layoutTables().Math.GlyphInfo.ExtendedShapes
+	if cov == nil {
+		return false
+	}
+	_, ok := cov.Index(glyph)
+	return ok
+}
+
+// MathKerning returns the kerning to apply at `corner` of `glyph`,
+// for the given correction height, or 0 if it is not defined.
+func (f *Font) MathKerning(glyph GID, corner MathKernCorner, correctionHeight float32) float32 {
+	info := f.lazy.layoutTables().Math.GlyphInfo.Kerns
+	if info.Coverage == nil || corner > MathKernBottomLeft {
+		return 0
+	}
+	index, ok := info.Coverage.Index(glyph)
+	if !ok {
+		return 0
+	}
+	kern := info.Kerns[index][corner]
+	if kern == nil {
+		return 0
+	}
+	// select the first interval whose upper bound is not below the height
+	i := 0
+	for i < len(kern.CorrectionHeights) && f.mathValue(kern.CorrectionHeights[i]) < correctionHeight {
+		i++
+	}
+	return f.mathValue(kern.KernValues[i])
+}
+
+// MathGlyphVariants returns the size variants of `glyph`, in the
+// vertical or horizontal direction, sorted by increasing size.
+// The first variant is usually `glyph` itself.
+func (f *Font) MathGlyphVariants(glyph GID, vertical bool) []MathGlyphVariant {
+	constructions := f.lazy.layoutTables().Math.Variants.Horizontal
+	if vertical {
+		constructions = f.lazy.layoutTables().Math.Variants.Vertical
+	}
+	construction, _ := constructions.construction(glyph)
+	return construction.Variants
+}
+
+// MathGlyphAssembly returns the parts used to build `glyph`
+// with an arbitrary size, in the vertical or horizontal direction,
+// and the italics correction of the assembly.
+// It returns `false` if `glyph` has no assembly.
+func (f *Font) MathGlyphAssembly(glyph GID, vertical bool) ([]MathGlyphPart, float32, bool) {
+	constructions := f.lazy.layoutTables().Math.Variants.Horizontal
+	if vertical {
+		constructions = f.lazy.layoutTables().Math.Variants.Vertical
+	}
+	construction, _ := constructions.construction(glyph)
+	if construction.Assembly.Parts == nil {
+		return nil, 0, false
+	}
+	return construction.Assembly.Parts, f.mathValue(construction.Assembly.ItalicsCorrection), true
+}
+
+// MathMinConnectorOverlap returns the minimum overlap of connecting
+// glyphs during glyph construction.
+func (f *Font) MathMinConnectorOverlap() float32 {
+	return float32(f.lazy.layoutTables().Math.Variants.MinConnectorOverlap)
+}
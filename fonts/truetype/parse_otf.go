@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/boxesandglue/textlayout/fonts"
 )
@@ -73,7 +74,8 @@ func readDirectoryEntry(r io.Reader, entry *directoryEntry) error {
 // the beginning of the resource in the file (non zero for collections)
 // `relativeOffset` is true when the table offset are expressed relatively ot
 // the resource (that is, `offset`) rather than to the file
-func parseOTF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontParser, error) {
+// In salvage mode, truncated directories and invalid table ranges are fixed (see salvageTables).
+func parseOTF(file fonts.Resource, offset uint32, relativeOffset bool, opts ParseOptions) (*FontParser, error) {
 	_, err := file.Seek(int64(offset), io.SeekStart)
 	if err != nil {
 		return nil, fmt.Errorf("invalid offset: %s", err)
@@ -88,15 +90,24 @@ func parseOTF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontPar
 		file:   file,
 		tables: make(map[Tag]tableSection, header.NumTables),
 		Type:   header.ScalerType,
+		data:   resourceBytes(file),
 	}
 
 	for i := 0; i < int(header.NumTables); i++ {
 		var entry directoryEntry
 		if err := readDirectoryEntry(file, &entry); err != nil {
-			return nil, err
+			if !opts.Salvage {
+				return nil, err
+			}
+			// keep the entries already read
+			fontParser.warnings = append(fontParser.warnings, ValidationWarning{
+				Issue:   InvalidTableRecord,
+				Message: fmt.Sprintf("truncated table directory: %d entries read, %d expected", i, header.NumTables),
+			})
+			break
 		}
 
-		// TODO Check the checksum.
+		// the checksum is only verified by FontParser.Validate
 
 		if _, found := fontParser.tables[entry.Tag]; found {
 			// ignore duplicate tables – the first one wins
@@ -104,8 +115,10 @@ func parseOTF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontPar
 		}
 
 		sec := tableSection{
-			offset: entry.Offset,
-			length: entry.Length,
+			offset:      entry.Offset,
+			length:      entry.Length,
+			checksum:    entry.CheckSum,
+			hasChecksum: true,
 		}
 		// adapt the relative offsets
 		if relativeOffset {
@@ -117,5 +130,79 @@ func parseOTF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontPar
 		fontParser.tables[entry.Tag] = sec
 	}
 
+	if opts.Salvage {
+		size, err := file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		fontParser.warnings = append(fontParser.warnings, fontParser.salvageTables(size)...)
+	}
+	if opts.Log != nil {
+		for _, w := range fontParser.warnings {
+			opts.Log(w)
+		}
+	}
+
 	return fontParser, nil
 }
+
+// salvageTables fixes the table ranges which are not included in the file
+// of size `fileSize`, or which overlap the next table, and returns the
+// problems found.
+// The tables are processed in increasing offset order : a table starting outside
+// the file is dropped, and a table overlapping the next one (or the end of the file)
+// is clamped to its start.
+// Tables with the same offset are supposed to share their content and are left untouched.
+func (pr *FontParser) salvageTables(fileSize int64) []ValidationWarning {
+	tags := make([]Tag, 0, len(pr.tables))
+	for tag := range pr.tables {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		si, sj := pr.tables[tags[i]], pr.tables[tags[j]]
+		if si.offset != sj.offset {
+			return si.offset < sj.offset
+		}
+		return tags[i] < tags[j]
+	})
+
+	var out []ValidationWarning
+	for i, tag := range tags {
+		sec := pr.tables[tag]
+		if sec.offset%4 != 0 {
+			out = append(out, ValidationWarning{
+				Table: tag, Issue: InvalidTableRecord,
+				Message: fmt.Sprintf("unaligned offset %d", sec.offset),
+			})
+		}
+		if int64(sec.offset) >= fileSize && sec.length != 0 {
+			out = append(out, ValidationWarning{
+				Table: tag, Issue: InvalidTableRecord,
+				Message: fmt.Sprintf("offset %d exceeds the file size %d: table dropped", sec.offset, fileSize),
+			})
+			delete(pr.tables, tag)
+			continue
+		}
+
+		// find the limit of the table
+		limit, limitName := fileSize, "the end of the file"
+		for _, next := range tags[i+1:] {
+			if nextOffset := pr.tables[next].offset; nextOffset > sec.offset {
+				if int64(nextOffset) < limit {
+					limit, limitName = int64(nextOffset), fmt.Sprintf("table %s", next)
+				}
+				break
+			}
+		}
+		if end := int64(sec.offset) + int64(sec.length); end > limit {
+			out = append(out, ValidationWarning{
+				Table: tag, Issue: InvalidTableRecord,
+				Message: fmt.Sprintf("range [%d, %d) overlaps %s: length clamped to %d", sec.offset, end, limitName, limit-int64(sec.offset)),
+			})
+			sec.length = uint32(limit - int64(sec.offset))
+			sec.hasChecksum = false // the checksum is meaningless for a clamped table
+			pr.tables[tag] = sec
+		}
+	}
+	return out
+}
@@ -94,6 +94,7 @@ func parseWOFF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontPa
 		file:   file,
 		tables: make(map[Tag]tableSection, header.NumTables),
 		Type:   header.Flavor,
+		data:   resourceBytes(file),
 	}
 	for i := 0; i < int(header.NumTables); i++ {
 		entry, err := readWOFFEntry(file)
@@ -101,7 +102,7 @@ func parseWOFF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontPa
 			return nil, err
 		}
 
-		// TODO Check the checksum.
+		// the checksum is only verified by FontParser.Validate
 
 		if _, found := fontParser.tables[entry.Tag]; found {
 			// ignore duplicate tables – the first one wins
@@ -109,9 +110,11 @@ func parseWOFF(file fonts.Resource, offset uint32, relativeOffset bool) (*FontPa
 		}
 
 		sec := tableSection{
-			offset:  entry.Offset,
-			length:  entry.CompLength,
-			zLength: entry.OrigLength,
+			offset:      entry.Offset,
+			length:      entry.CompLength,
+			zLength:     entry.OrigLength,
+			checksum:    entry.OrigChecksum,
+			hasChecksum: true,
 		}
 		// adapt the relative offsets
 		if relativeOffset {
@@ -0,0 +1,626 @@
+package truetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// WOFF2 support, see https://www.w3.org/TR/WOFF2/
+// The tables are decompressed and reconstructed in memory
+// when the font is opened.
+
+type woff2Header struct {
+	Signature           Tag
+	Flavor              Tag
+	Length              uint32
+	NumTables           uint16
+	TotalSfntSize       uint32
+	TotalCompressedSize uint32
+}
+
+type woff2Entry struct {
+	Tag              Tag
+	OrigLength       uint32
+	TransformLength  uint32 // length of the (compressed) data, possibly transformed
+	TransformVersion uint8
+}
+
+// isTransformed returns true if the table data needs to be reconstructed.
+// The null transform is 3 for 'glyf' and 'loca', and 0 for the other tables.
+func (e woff2Entry) isTransformed() bool {
+	if e.Tag == tagGlyf || e.Tag == tagLoca {
+		return e.TransformVersion != 3
+	}
+	return e.TransformVersion != 0
+}
+
+const (
+	woff2HeaderSize = 48
+
+	// protect against malicious fonts
+	maxWOFF2Size = 1 << 30
+)
+
+// woff2KnownTags is used to encode the most common tags
+// with one byte.
+var woff2KnownTags = [63]Tag{
+	tagCmap, tagHead, tagHhea, tagHmtx, tagMaxp, tagName, tagOS2, tagPost,
+	tagCvt, tagFpgm, tagGlyf, tagLoca, tagPrep, tagCFF, tagVorg, tagEBDT,
+	tagEBLC, MustNewTag("gasp"), MustNewTag("hdmx"), tagKern, MustNewTag("LTSH"), MustNewTag("PCLT"), MustNewTag("VDMX"), tagVhea,
+	tagVmtx, MustNewTag("BASE"), MustNewTag("GDEF"), MustNewTag("GPOS"), MustNewTag("GSUB"), MustNewTag("EBSC"), MustNewTag("JSTF"), tagMath,
+	tagCBDT, tagCBLC, tagCOLR, tagCPAL, MustNewTag("SVG "), tagSbix, MustNewTag("acnt"), tagAvar,
+	tagBdat, tagBloc, MustNewTag("bsln"), MustNewTag("cvar"), MustNewTag("fdsc"), tagFeat, MustNewTag("fmtx"), tagFvar,
+	tagGvar, MustNewTag("hsty"), MustNewTag("just"), MustNewTag("lcar"), tagMort, tagMorx, MustNewTag("opbd"), MustNewTag("prop"),
+	tagTrak, MustNewTag("Zapf"), tagSilf, tagGlat, tagGloc, tagGraphiteFeat, tagSill,
+}
+
+func parseWOFF2Header(data []byte) (woff2Header, error) {
+	var header woff2Header
+	if len(data) < woff2HeaderSize {
+		return header, errors.New("invalid WOFF2 header (EOF)")
+	}
+	header.Signature = newTag(data[0:4])
+	header.Flavor = newTag(data[4:8])
+	header.Length = binary.BigEndian.Uint32(data[8:12])
+	header.NumTables = binary.BigEndian.Uint16(data[12:14])
+	// reserved uint16
+	header.TotalSfntSize = binary.BigEndian.Uint32(data[16:20])
+	header.TotalCompressedSize = binary.BigEndian.Uint32(data[20:24])
+	// version and metadata are ignored
+	return header, nil
+}
+
+// readUintBase128 reads a variable-length integer,
+// returning the remaining data.
+func readUintBase128(data []byte) (uint32, []byte, error) {
+	var accum uint32
+	for i := 0; i < 5; i++ {
+		if len(data) == 0 {
+			return 0, nil, errors.New("invalid UIntBase128 (EOF)")
+		}
+		b := data[0]
+		data = data[1:]
+		if i == 0 && b == 0x80 { // leading zeros
+			return 0, nil, errors.New("invalid UIntBase128 (leading zeros)")
+		}
+		if accum&0xFE000000 != 0 { // overflow
+			return 0, nil, errors.New("invalid UIntBase128 (overflow)")
+		}
+		accum = accum<<7 | uint32(b&0x7F)
+		if b&0x80 == 0 {
+			return accum, data, nil
+		}
+	}
+	return 0, nil, errors.New("invalid UIntBase128 (too long)")
+}
+
+// read255Uint16 reads a variable-length integer,
+// returning the remaining data.
+func read255Uint16(data []byte) (uint16, []byte, error) {
+	const (
+		oneMoreByteCode2 = 254
+		oneMoreByteCode1 = 255
+		wordCode         = 253
+		lowestUCode      = 253
+	)
+	if len(data) == 0 {
+		return 0, nil, errors.New("invalid 255UInt16 (EOF)")
+	}
+	code := data[0]
+	data = data[1:]
+	switch code {
+	case wordCode:
+		if len(data) < 2 {
+			return 0, nil, errors.New("invalid 255UInt16 (EOF)")
+		}
+		return binary.BigEndian.Uint16(data), data[2:], nil
+	case oneMoreByteCode1:
+		if len(data) < 1 {
+			return 0, nil, errors.New("invalid 255UInt16 (EOF)")
+		}
+		return uint16(data[0]) + lowestUCode, data[1:], nil
+	case oneMoreByteCode2:
+		if len(data) < 1 {
+			return 0, nil, errors.New("invalid 255UInt16 (EOF)")
+		}
+		return uint16(data[0]) + lowestUCode*2, data[1:], nil
+	default:
+		return uint16(code), data, nil
+	}
+}
+
+// parseWOFF2Entries parses the table directory, returning the remaining data
+func parseWOFF2Entries(data []byte, numTables int) ([]woff2Entry, []byte, error) {
+	out := make([]woff2Entry, numTables)
+	var err error
+	for i := range out {
+		if len(data) < 1 {
+			return nil, nil, errors.New("invalid WOFF2 table directory (EOF)")
+		}
+		flags := data[0]
+		data = data[1:]
+		if tagIndex := flags & 0x3F; tagIndex == 0x3F { // arbitrary tag
+			if len(data) < 4 {
+				return nil, nil, errors.New("invalid WOFF2 table directory (EOF)")
+			}
+			out[i].Tag = newTag(data)
+			data = data[4:]
+		} else {
+			out[i].Tag = woff2KnownTags[tagIndex]
+		}
+		out[i].TransformVersion = flags >> 6
+
+		out[i].OrigLength, data, err = readUintBase128(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		out[i].TransformLength = out[i].OrigLength
+		if out[i].isTransformed() {
+			switch tag, version := out[i].Tag, out[i].TransformVersion; {
+			case (tag == tagGlyf || tag == tagLoca) && version == 0, tag == tagHmtx && version == 1:
+			default:
+				return nil, nil, fmt.Errorf("unsupported WOFF2 transformation %d for table %s", version, tag)
+			}
+			out[i].TransformLength, data, err = readUintBase128(data)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return out, data, nil
+}
+
+// parseWOFF2 decompresses and reconstructs the tables in memory.
+// The returned parser does not use `file` anymore.
+// Font collections are not supported.
+func parseWOFF2(file fonts.Resource, offset uint32) (*FontParser, error) {
+	var buf [woff2HeaderSize]byte
+	if _, err := file.ReadAt(buf[:], int64(offset)); err != nil {
+		return nil, fmt.Errorf("invalid WOFF2 header: %s", err)
+	}
+	header, err := parseWOFF2Header(buf[:])
+	if err != nil {
+		return nil, err
+	}
+	if header.Flavor == ttcTag {
+		return nil, errors.New("unsupported WOFF2 font collection")
+	}
+	if header.Length < woff2HeaderSize || header.Length > maxWOFF2Size {
+		return nil, errors.New("invalid WOFF2 header length")
+	}
+	data := make([]byte, header.Length)
+	if _, err := file.ReadAt(data, int64(offset)); err != nil {
+		return nil, fmt.Errorf("invalid WOFF2 file: %s", err)
+	}
+
+	entries, data, err := parseWOFF2Entries(data[woff2HeaderSize:], int(header.NumTables))
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(data)) < header.TotalCompressedSize {
+		return nil, errors.New("invalid WOFF2 compressed data (EOF)")
+	}
+
+	var totalSize uint64
+	for _, entry := range entries {
+		totalSize += uint64(entry.TransformLength)
+	}
+	if totalSize > maxWOFF2Size {
+		return nil, errors.New("invalid WOFF2 table lengths")
+	}
+	decompressed := make([]byte, totalSize)
+	r := brotli.NewReader(bytes.NewReader(data[:header.TotalCompressedSize]))
+	if _, err := io.ReadFull(r, decompressed); err != nil {
+		return nil, fmt.Errorf("invalid WOFF2 compressed data: %s", err)
+	}
+
+	// split the tables
+	tables := make(map[Tag][]byte, len(entries))
+	var isGlyfTransformed bool
+	for _, entry := range entries {
+		if _, found := tables[entry.Tag]; found {
+			return nil, fmt.Errorf("duplicate WOFF2 table %s", entry.Tag)
+		}
+		tables[entry.Tag] = decompressed[:entry.TransformLength]
+		decompressed = decompressed[entry.TransformLength:]
+		if entry.Tag == tagGlyf && entry.isTransformed() {
+			isGlyfTransformed = true
+		}
+	}
+
+	var glyphs []GlyphData // needed to reconstruct the hmtx table
+	if isGlyfTransformed {
+		var glyf, loca []byte
+		glyf, loca, glyphs, err = reconstructGlyfLoca(tables[tagGlyf])
+		if err != nil {
+			return nil, err
+		}
+		tables[tagGlyf], tables[tagLoca] = glyf, loca
+	}
+	for _, entry := range entries {
+		if entry.Tag == tagHmtx && entry.isTransformed() {
+			tables[tagHmtx], err = reconstructHmtx(tables[tagHmtx], tables[tagHhea], glyphs)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// store the tables in one buffer
+	var (
+		content  []byte
+		sections = make(map[Tag]tableSection, len(tables))
+	)
+	for _, entry := range entries {
+		table := tables[entry.Tag]
+		sections[entry.Tag] = tableSection{offset: uint32(len(content)), length: uint32(len(table)), zLength: uint32(len(table))}
+		content = append(content, table...)
+	}
+
+	return &FontParser{
+		file:   bytes.NewReader(content),
+		tables: sections,
+		Type:   header.Flavor,
+		data:   content,
+	}, nil
+}
+
+// woff2GlyfStreams stores the streams of the transformed 'glyf' table.
+// They are consumed while decoding the glyphs.
+type woff2GlyfStreams struct {
+	nContour, nPoints, flag, glyph, composite, bbox, instruction []byte
+}
+
+// reconstructGlyfLoca decodes the transformed 'glyf' table,
+// returning the regular 'glyf' and 'loca' tables, and the parsed glyphs.
+func reconstructGlyfLoca(data []byte) (glyf, loca []byte, glyphs []GlyphData, err error) {
+	const headerSize = 36
+	if len(data) < headerSize {
+		return nil, nil, nil, errors.New("invalid WOFF2 transformed glyf table (EOF)")
+	}
+	optionFlags := binary.BigEndian.Uint16(data[2:])
+	numGlyphs := int(binary.BigEndian.Uint16(data[4:]))
+	indexFormat := binary.BigEndian.Uint16(data[6:])
+
+	var (
+		streams woff2GlyfStreams
+		offset  = uint64(headerSize)
+	)
+	for i, stream := range [...]*[]byte{
+		&streams.nContour, &streams.nPoints, &streams.flag, &streams.glyph,
+		&streams.composite, &streams.bbox, &streams.instruction,
+	} {
+		size := uint64(binary.BigEndian.Uint32(data[8+4*i:]))
+		if offset+size > uint64(len(data)) {
+			return nil, nil, nil, errors.New("invalid WOFF2 transformed glyf table (EOF)")
+		}
+		*stream = data[offset : offset+size]
+		offset += size
+	}
+
+	bitmapSize := ((numGlyphs + 31) >> 5) << 2
+	if len(streams.bbox) < bitmapSize {
+		return nil, nil, nil, errors.New("invalid WOFF2 bbox bitmap (EOF)")
+	}
+	bboxBitmap := streams.bbox[:bitmapSize]
+	streams.bbox = streams.bbox[bitmapSize:]
+
+	var overlapBitmap []byte
+	if optionFlags&1 != 0 {
+		overlapBitmap = data[offset:]
+		if len(overlapBitmap) < (numGlyphs+7)>>3 {
+			return nil, nil, nil, errors.New("invalid WOFF2 overlap bitmap (EOF)")
+		}
+	}
+
+	if len(streams.nContour) < 2*numGlyphs {
+		return nil, nil, nil, errors.New("invalid WOFF2 contour stream (EOF)")
+	}
+
+	glyphs = make([]GlyphData, numGlyphs)
+	locaOffsets := make([]uint32, numGlyphs+1)
+	for i := range glyphs {
+		nContours := int16(binary.BigEndian.Uint16(streams.nContour[2*i:]))
+		hasBBox := bboxBitmap[i>>3]&(0x80>>(i&7)) != 0
+
+		var rawdata []byte
+		switch {
+		case nContours == 0: // empty glyph
+			if hasBBox {
+				return nil, nil, nil, fmt.Errorf("invalid WOFF2 bbox for empty glyph %d", i)
+			}
+		case nContours < 0: // composite glyph
+			if !hasBBox {
+				return nil, nil, nil, fmt.Errorf("missing WOFF2 bbox for composite glyph %d", i)
+			}
+			rawdata, err = streams.decodeComposite()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		default: // simple glyph
+			simple, err := streams.decodeSimple(int(nContours))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if overlapBitmap != nil && overlapBitmap[i>>3]&(0x80>>(i&7)) != 0 {
+				simple.points[0].flag |= overlapSimple
+			}
+			glyphs[i].data = simple
+			if !hasBBox {
+				glyphs[i].Xmin, glyphs[i].Ymin, glyphs[i].Xmax, glyphs[i].Ymax = simple.bounds()
+			}
+		}
+
+		if hasBBox {
+			if len(streams.bbox) < 8 {
+				return nil, nil, nil, errors.New("invalid WOFF2 bbox stream (EOF)")
+			}
+			glyphs[i].Xmin = int16(binary.BigEndian.Uint16(streams.bbox))
+			glyphs[i].Ymin = int16(binary.BigEndian.Uint16(streams.bbox[2:]))
+			glyphs[i].Xmax = int16(binary.BigEndian.Uint16(streams.bbox[4:]))
+			glyphs[i].Ymax = int16(binary.BigEndian.Uint16(streams.bbox[6:]))
+			streams.bbox = streams.bbox[8:]
+		}
+
+		if nContours < 0 {
+			// the component data are copied as it is: only update the header
+			binary.BigEndian.PutUint16(rawdata[2:], uint16(glyphs[i].Xmin))
+			binary.BigEndian.PutUint16(rawdata[4:], uint16(glyphs[i].Ymin))
+			binary.BigEndian.PutUint16(rawdata[6:], uint16(glyphs[i].Xmax))
+			binary.BigEndian.PutUint16(rawdata[8:], uint16(glyphs[i].Ymax))
+			glyphs[i], err = parseGlyphData(rawdata, 0)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		} else {
+			rawdata = glyphs[i].encode()
+		}
+		glyphs[i].rawdata = rawdata
+
+		glyf = append(glyf, rawdata...)
+		locaOffsets[i+1] = uint32(len(glyf))
+	}
+
+	if indexFormat == 0 {
+		loca = make([]byte, 2*len(locaOffsets))
+		for i, o := range locaOffsets {
+			binary.BigEndian.PutUint16(loca[2*i:], uint16(o/2))
+		}
+	} else {
+		loca = make([]byte, 4*len(locaOffsets))
+		for i, o := range locaOffsets {
+			binary.BigEndian.PutUint32(loca[4*i:], o)
+		}
+	}
+
+	return glyf, loca, glyphs, nil
+}
+
+// bounds returns the bounding box of the points, or zeros for an empty glyph
+func (sg simpleGlyphData) bounds() (xMin, yMin, xMax, yMax int16) {
+	for i, p := range sg.points {
+		if i == 0 {
+			xMin, yMin, xMax, yMax = p.x, p.y, p.x, p.y
+			continue
+		}
+		xMin, yMin = min16(xMin, p.x), min16(yMin, p.y)
+		xMax, yMax = max16(xMax, p.x), max16(yMax, p.y)
+	}
+	return
+}
+
+// readInstructions reads the instructions length from the glyph
+// stream and the instructions from the instruction stream
+func (s *woff2GlyfStreams) readInstructions() ([]byte, error) {
+	var (
+		length uint16
+		err    error
+	)
+	length, s.glyph, err = read255Uint16(s.glyph)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.instruction) < int(length) {
+		return nil, errors.New("invalid WOFF2 instruction stream (EOF)")
+	}
+	out := s.instruction[:length]
+	s.instruction = s.instruction[length:]
+	return out, nil
+}
+
+// decodeSimple reads one simple glyph with `nContours` > 0 contours.
+func (s *woff2GlyfStreams) decodeSimple(nContours int) (out simpleGlyphData, err error) {
+	out.endPtsOfContours = make([]uint16, nContours)
+	var totalPoints int
+	for i := range out.endPtsOfContours {
+		var nPoints uint16
+		nPoints, s.nPoints, err = read255Uint16(s.nPoints)
+		if err != nil {
+			return out, err
+		}
+		totalPoints += int(nPoints)
+		if totalPoints == 0 || totalPoints > 0xFFFF {
+			return out, errors.New("invalid WOFF2 number of points")
+		}
+		out.endPtsOfContours[i] = uint16(totalPoints - 1)
+	}
+
+	if len(s.flag) < totalPoints {
+		return out, errors.New("invalid WOFF2 flag stream (EOF)")
+	}
+	out.points = make([]glyphContourPoint, totalPoints)
+	var x, y int16 // coordinates are relative to the previous
+	for i, flag := range s.flag[:totalPoints] {
+		dx, dy, n, err := decodeTriplet(flag&0x7F, s.glyph)
+		if err != nil {
+			return out, err
+		}
+		s.glyph = s.glyph[n:]
+		x += dx
+		y += dy
+		out.points[i] = glyphContourPoint{x: x, y: y}
+		if flag&0x80 == 0 {
+			out.points[i].flag = flagOnCurve
+		}
+	}
+	s.flag = s.flag[totalPoints:]
+
+	out.instructions, err = s.readInstructions()
+	return out, err
+}
+
+// decodeTriplet decodes one point, returning the relative coordinates and
+// the number of bytes read from `data`.
+func decodeTriplet(flag byte, data []byte) (dx, dy int16, n int, err error) {
+	withSign := func(flag byte, v int) int16 {
+		if flag&1 != 0 {
+			return int16(v)
+		}
+		return int16(-v)
+	}
+
+	switch {
+	case flag < 84:
+		n = 1
+	case flag < 120:
+		n = 2
+	case flag < 124:
+		n = 3
+	default:
+		n = 4
+	}
+	if len(data) < n {
+		return 0, 0, 0, errors.New("invalid WOFF2 glyph stream (EOF)")
+	}
+
+	switch {
+	case flag < 10:
+		dy = withSign(flag, int(flag&14)<<7+int(data[0]))
+	case flag < 20:
+		dx = withSign(flag, int((flag-10)&14)<<7+int(data[0]))
+	case flag < 84:
+		b0, b1 := int(flag-20), int(data[0])
+		dx = withSign(flag, 1+(b0&0x30)+(b1>>4))
+		dy = withSign(flag>>1, 1+(b0&0x0C)<<2+(b1&0x0F))
+	case flag < 120:
+		b0 := int(flag - 84)
+		dx = withSign(flag, 1+(b0/12)<<8+int(data[0]))
+		dy = withSign(flag>>1, 1+((b0%12)>>2)<<8+int(data[1]))
+	case flag < 124:
+		b2 := int(data[1])
+		dx = withSign(flag, int(data[0])<<4+b2>>4)
+		dy = withSign(flag>>1, (b2&0x0F)<<8+int(data[2]))
+	default:
+		dx = withSign(flag, int(data[0])<<8+int(data[1]))
+		dy = withSign(flag>>1, int(data[2])<<8+int(data[3]))
+	}
+	return dx, dy, n, nil
+}
+
+// decodeComposite reads one composite glyph, returning its
+// data, with a zero bounding box.
+func (s *woff2GlyfStreams) decodeComposite() ([]byte, error) {
+	// find the length of the components
+	var (
+		size            int
+		flags           uint16
+		hasInstructions bool
+	)
+	for do := true; do; do = flags&moreComponents != 0 {
+		if len(s.composite) < size+4 {
+			return nil, errors.New("invalid WOFF2 composite stream (EOF)")
+		}
+		flags = binary.BigEndian.Uint16(s.composite[size:])
+		hasInstructions = hasInstructions || flags&weHaveInstructions != 0
+		size += 4
+		if flags&arg1And2AreWords != 0 {
+			size += 4
+		} else {
+			size += 2
+		}
+		if flags&weHaveAScale != 0 {
+			size += 2
+		} else if flags&weHaveAnXAndYScale != 0 {
+			size += 4
+		} else if flags&weHaveATwoByTwo != 0 {
+			size += 8
+		}
+	}
+	if len(s.composite) < size {
+		return nil, errors.New("invalid WOFF2 composite stream (EOF)")
+	}
+
+	out := make([]byte, 10, 10+size)
+	binary.BigEndian.PutUint16(out, 0xFFFF) // numberOfContours = -1
+	out = append(out, s.composite[:size]...)
+	s.composite = s.composite[size:]
+
+	if hasInstructions {
+		instructions, err := s.readInstructions()
+		if err != nil {
+			return nil, err
+		}
+		out = appendUint16(out, uint16(len(instructions)))
+		out = append(out, instructions...)
+	}
+	if len(out)%2 != 0 {
+		out = append(out, 0)
+	}
+	return out, nil
+}
+
+// reconstructHmtx decodes the transformed 'hmtx' table, using
+// the glyphs bounding boxes for the missing left side bearings
+func reconstructHmtx(data, hhea []byte, glyphs []GlyphData) ([]byte, error) {
+	if len(hhea) < 36 {
+		return nil, errors.New("invalid 'hhea' table (EOF)")
+	}
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	numGlyphs := len(glyphs)
+	if numberOfHMetrics > numGlyphs || numberOfHMetrics == 0 {
+		return nil, errors.New("invalid WOFF2 transformed hmtx table")
+	}
+	if len(data) < 1 {
+		return nil, errors.New("invalid WOFF2 transformed hmtx table (EOF)")
+	}
+	flags := data[0]
+	data = data[1:]
+	hasProportionalLsbs := flags&1 == 0
+	hasMonospaceLsbs := flags&2 == 0
+
+	expectedSize := 2 * numberOfHMetrics
+	if hasProportionalLsbs {
+		expectedSize += 2 * numberOfHMetrics
+	}
+	if hasMonospaceLsbs {
+		expectedSize += 2 * (numGlyphs - numberOfHMetrics)
+	}
+	if len(data) < expectedSize {
+		return nil, errors.New("invalid WOFF2 transformed hmtx table (EOF)")
+	}
+
+	out := make([]byte, 4*numberOfHMetrics+2*(numGlyphs-numberOfHMetrics))
+	for i := 0; i < numberOfHMetrics; i++ { // advances
+		copy(out[4*i:], data[2*i:2*i+2])
+	}
+	data = data[2*numberOfHMetrics:]
+	for i := range glyphs {
+		lsb := uint16(glyphs[i].Xmin)
+		if i < numberOfHMetrics && hasProportionalLsbs || i >= numberOfHMetrics && hasMonospaceLsbs {
+			lsb = binary.BigEndian.Uint16(data)
+			data = data[2:]
+		}
+		if i < numberOfHMetrics {
+			binary.BigEndian.PutUint16(out[4*i+2:], lsb)
+		} else {
+			binary.BigEndian.PutUint16(out[4*numberOfHMetrics+2*(i-numberOfHMetrics):], lsb)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,156 @@
+package truetype
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+)
+
+// SourceSansVariable-Roman.modcomp.ttf converted to WOFF2,
+// with transformed 'glyf', 'loca' and 'hmtx' tables
+var woff2ModComp = deHexStr(
+	"774f463200010000000005e00015000000000cb400000570000100000000000000000000000000000000000000000000" +
+		"1a311b810c1c363f485641523a3f4d5641523c06603f53544154814c27260081082f600a822882123082220136022443" +
+		"1c190b120004200588600720172418121b1f0b289e07b67b94869594b4184e64fe3045f07c9ddfd7b9dd93d72f1e3923" +
+		"e79b18acbebcb376143260fcaf357d29a164e0831f5cc8ec6d0148982a53252b234c7515112cef130b230c32e83c209f" +
+		"e1f25fddf98ffb95feb7155a966fdb52600517643da2ef6e3caaa9bdf4fcec9abaa9824e3871c17c81e56c3391b3a5a1" +
+		"a40ffb48c539394236649fef4c840cfee9fd04aa0283846043bd1008fe12286b5b5fbee9e6938b483fe44831a41fa128" +
+		"80f4630d2d902e872e8138e63025108340c8f5221442422169d9144c35559200a0bb55854c92c92521c9055c48a06c65" +
+		"ab2e442e2417d20b5d6a5d800106f2e99422b26322245f554846fc972a6051ce5c8f060487924e541b248b7fbca20fe9" +
+		"a2b427d86628b2b1bffa1845aa6459966729e52801c046d33f037839aae9af6e89ec93e8db1237427c95ae414b268442" +
+		"c20005c64b671ce1d5ae5e8e3a83b90aa169bc356d6b97f6e80609a2ec15bc82254810d5988e25c4fe2021453dae3059" +
+		"d1d18168a63dcba48b9c5cbff404ca642de40664f3e03594c95063e90981f8c81e1008107a9ef10ce89d503abcdf58ca" +
+		"dbf8ab261e5ef5b1ea131bea057264186f94ef2a5c4098293baf086d140a092f4729aa5daeaedd75562c5fb148505615" +
+		"675595195e3e65892869c988ea11e4024d4918a0a45cbdb356db375788de289bb1a8a4db4f276a51672b6f449332737d" +
+		"a35f2d15a27f0c60dc650c04056822c73ad80b4cc5084074756321c4e8bc681ef4fcd14731fb63dda872f1875326dff3" +
+		"51f9a20f3e70e1871f3e168d8f3798f2c407fb0f5a29db5e4e598adf63e0e262fae7e331fbb1d5f788dff5f6475df8c1" +
+		"16959dce5e1c783f72fbe2d3b135aaee596bb79ab7d51edc6a42cdb66ca2fdf98003da5e8fd96ebc69ae6cac617ca31e" +
+		"035ffcfab4335690f8e22927854e3a24e2a44342a79c84b1b7d746114b060ddefc6ed26e99599b7cef3faa68b312a489" +
+		"82668f9ed859dc4ee0f589f589024a6bd7d68435bb2ba772ba69203adc6ccfe7da8d0b213051012683a956b5be2d2d21" +
+		"678dcb50d81af41b36a4d4d1b006d66dadb47667617dc4c6a21fd7fd80e563d6cf6e2cfa65e6d42186cc9e3dbcf36767" +
+		"d192450b44f4ff5fd75d7ca1d28d1f2d19b1eb7e07b11eb67c4e2c408672e6c254cfcd991b0b4638e45166d646ad4373" +
+		"c7bcfe4e73d7af39d91763d7fe6176ac3ddebfce60a487dea9ce4e9df68e373d0bfeab8f1d5bff6fc1eca897432d27a1" +
+		"f1d5276fbfa6caefbdf1d188511326bef5f2b98d1faae5b0b0d349a9945bf1e777b3238d10883ea3973ebff1f63bc9eb" +
+		"ce17d92ff0f1a29dfa039fbcbfc3c9ffe77727577b50284920c83e59f137e9c46543649eb4ba20e1527a219e56ee85c5" +
+		"81b2ade950adcb860814460bb324ef3bcc1d07455d26f25ebc0403c3642f6152f7176676f633320f621f87c6f6b29276" +
+		"3cd2287a94e33b60a11a7f402f7511960d483956ac9a866055396d8b3553d3ee5857f71736cc4c176253333d807db5d3" +
+		"53d84f91fec2fe26a7053840391b75be81a66693ddad65656d2b696b99d160cdcfb2119b6422b97671c4a111ce916b2b" +
+		"8245528224c5a0115baec98ea46941ed6699e58cbbacda414c5f75feb65c8d29a66b39838fe6956b67c441947d883727" +
+		"89c2e4da9e8123b9ad217621ed512c5469669ab685c3862438b07b46f149b989a129e51e7b98fb3775666a5b59db3a36" +
+		"32a8df8c0ea2ebe8f30857414ee2111be18fcde8376419511c3e92903ba7fe9124cdf243fbcd37b3fedc5ad661e60228" +
+		"aaea460e11ac0cab6556b7da3dbd6b597d49d7f828c66806c6c7b282f8787869430e698a61d2e97f968b37a5aae41031" +
+		"8628ded76ffb4a0b08e76392185bfa4e8d467f3ab4311692315802cd158f85538bddacec6fa3293a2baa64bfd8fe9f95" +
+		"9f00025b45f64b74627fb351b13dbb03")
+
+func TestParseWOFF2(t *testing.T) {
+	ttf, err := testdata.Files.ReadFile("SourceSansVariable-Roman.modcomp.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := NewFontParser(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewFontParser(bytes.NewReader(woff2ModComp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != exp.Type || len(got.tables) != len(exp.tables) {
+		t.Fatalf("expected %d tables, got %d", len(exp.tables), len(got.tables))
+	}
+	// the tables which are not transformed are stored as is
+	for tag := range exp.tables {
+		if tag == tagGlyf || tag == tagLoca {
+			continue
+		}
+		expTable, err := exp.GetRawTable(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotTable, err := got.GetRawTable(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(expTable, gotTable) {
+			t.Errorf("invalid table %s", tag)
+		}
+	}
+
+	expFont, err := exp.loadTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotFont, err := got.loadTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expFont.Glyf()) != len(gotFont.Glyf()) {
+		t.Fatalf("expected %d glyphs, got %d", len(expFont.Glyf()), len(gotFont.Glyf()))
+	}
+	for i, g := range expFont.Glyf() {
+		assertGlyphHeaderEqual(t, g, gotFont.Glyf()[i])
+		if !reflect.DeepEqual(g.data, gotFont.Glyf()[i].data) {
+			t.Errorf("glyph %d: expected %v, got %v", i, g.data, gotFont.Glyf()[i].data)
+		}
+	}
+	if !reflect.DeepEqual(expFont.Hmtx, gotFont.Hmtx) {
+		t.Errorf("expected %v, got %v", expFont.Hmtx, gotFont.Hmtx)
+	}
+}
+
+func TestParseWOFF2Invalid(t *testing.T) {
+	for _, l := range []int{10, 60, 200, len(woff2ModComp) - 10} {
+		if _, err := Parse(bytes.NewReader(woff2ModComp[:l])); err == nil {
+			t.Errorf("expected error for truncated file (%d bytes)", l)
+		}
+	}
+}
+
+func TestWOFF2Integers(t *testing.T) {
+	for _, test := range []struct {
+		data     []byte
+		expected uint16
+	}{
+		{[]byte{63}, 63},
+		{[]byte{255, 253}, 506},
+		{[]byte{254, 0}, 506},
+		{[]byte{253, 1, 250}, 506},
+	} {
+		got, _, err := read255Uint16(test.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("expected %d, got %d", test.expected, got)
+		}
+	}
+
+	for _, test := range []struct {
+		data     []byte
+		expected uint32
+	}{
+		{[]byte{63}, 63},
+		{[]byte{0x81, 0x00}, 128},
+		{[]byte{0x8F, 0xFF, 0xFF, 0xFF, 0x7F}, 0xFFFFFFFF},
+	} {
+		got, _, err := readUintBase128(test.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("expected %d, got %d", test.expected, got)
+		}
+	}
+	for _, data := range [][]byte{
+		{0x80, 0x01},                   // leading zeros
+		{0x90, 0x80, 0x80, 0x80, 0x00}, // overflow
+		{0x81, 0x81, 0x81, 0x81, 0x81}, // too long
+		{0x81},                         // EOF
+	} {
+		if _, _, err := readUintBase128(data); err == nil {
+			t.Errorf("expected error for %v", data)
+		}
+	}
+}
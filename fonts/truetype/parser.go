@@ -18,71 +18,104 @@ type FontParser struct {
 	file   fonts.Resource       // source, needed to parse each table
 	tables map[Tag]tableSection // header only, contents is processed on demand
 
+	// content of `file`, if it is available in memory (see fonts.BytesResource),
+	// in which case the uncompressed tables are not copied
+	data []byte
+
 	Type Tag
 
 	// True for fonts which include a 'bhed' table instead
 	// of a 'head' table. Apple uses it as a flag that a font doesn't have
 	// any glyph outlines but only embedded bitmaps
 	isBinary bool
+
+	// problems fixed in salvage mode, reported by Validate
+	warnings []ValidationWarning
+}
+
+// ParseOptions controls how damaged font files are handled.
+type ParseOptions struct {
+	// Salvage enables a recovery mode for fonts with a damaged table directory,
+	// as found in the wild: instead of failing when the tables are read,
+	// the table records are sorted by offset, and their ranges are clamped
+	// to the next table and to the end of the file. Truncated directories
+	// are also accepted.
+	// It only applies to OpenType and TrueType files (not to WOFF files).
+	Salvage bool
+
+	// Log, if not nil, is called with each problem fixed in salvage mode.
+	// These problems are also returned by FontParser.Validate.
+	Log func(ValidationWarning)
 }
 
 // NewFontParser reads the `file` header and returns
 // a parser.
 // `file` will be used to parse tables, and should not be close.
 func NewFontParser(file fonts.Resource) (*FontParser, error) {
-	return parseOneFont(file, 0, false)
+	return parseOneFont(file, 0, false, ParseOptions{})
+}
+
+// NewFontParserWithOptions is the same as `NewFontParser`, but allows to
+// customize the parsing with `opts`.
+func NewFontParserWithOptions(file fonts.Resource, opts ParseOptions) (*FontParser, error) {
+	return parseOneFont(file, 0, false, opts)
 }
 
 // NewFontParsers is the same as `NewFontParser`, but supports collections.
 func NewFontParsers(file fonts.Resource) ([]*FontParser, error) {
-	_, err := file.Seek(0, io.SeekStart) // file might have been used before
+	offsets, relativeOffset, err := collectionOffsets(file)
 	if err != nil {
 		return nil, err
 	}
 
+	out := make([]*FontParser, len(offsets))
+	for i, o := range offsets {
+		out[i], err = parseOneFont(file, o, relativeOffset, ParseOptions{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// resourceBytes returns the content of `file` if it
+// is available in memory, or nil.
+func resourceBytes(file fonts.Resource) []byte {
+	if br, ok := file.(fonts.BytesResource); ok {
+		return br.Bytes()
+	}
+	return nil
+}
+
+// collectionOffsets returns the offsets of the faces in `file`,
+// which is a single offset (0) for fonts which are not collections.
+func collectionOffsets(file fonts.Resource) (offsets []uint32, relativeOffset bool, err error) {
+	_, err = file.Seek(0, io.SeekStart) // file might have been used before
+	if err != nil {
+		return nil, false, err
+	}
+
 	var bytes [4]byte
 	_, err = file.Read(bytes[:])
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	magic := newTag(bytes[:])
 
 	file.Seek(0, io.SeekStart)
 
-	var (
-		pr             *FontParser
-		offsets        []uint32
-		relativeOffset bool
-	)
 	switch magic {
-	case SignatureWOFF, TypeTrueType, TypeOpenType, TypePostScript1, TypeAppleTrueType:
-		pr, err = parseOneFont(file, 0, false)
+	case SignatureWOFF, SignatureWOFF2, TypeTrueType, TypeOpenType, TypePostScript1, TypeAppleTrueType:
+		return []uint32{0}, false, nil
 	case ttcTag:
 		offsets, err = parseTTCHeader(file)
 	case dfontResourceDataOffset:
 		offsets, err = parseDfont(file)
 		relativeOffset = true
 	default:
-		return nil, fmt.Errorf("unsupported font format %v", bytes)
+		return nil, false, fmt.Errorf("unsupported font format %v", bytes)
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	// only one font
-	if pr != nil {
-		return []*FontParser{pr}, nil
-	}
-
-	// collection
-	out := make([]*FontParser, len(offsets))
-	for i, o := range offsets {
-		out[i], err = parseOneFont(file, o, relativeOffset)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return out, nil
+	return offsets, relativeOffset, err
 }
 
 // tableSection represents a table within the font file.
@@ -90,6 +123,9 @@ type tableSection struct {
 	offset  uint32 // Offset into the file this table starts.
 	length  uint32 // Length of this table within the file.
 	zLength uint32 // Uncompressed length of this table.
+
+	checksum    uint32 // checksum of the uncompressed table, as stored in the table directory
+	hasChecksum bool   // false for WOFF2 files
 }
 
 func (pr *FontParser) findTableBuffer(s tableSection) ([]byte, error) {
@@ -107,7 +143,14 @@ func (pr *FontParser) findTableBuffer(s tableSection) ([]byte, error) {
 		if _, err := io.ReadFull(r, buf); err != nil {
 			return nil, err
 		}
-	} else {
+	} else if s.length != 0 && pr.data != nil {
+		end := uint64(s.offset) + uint64(s.length)
+		if end > uint64(len(pr.data)) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		// limit the capacity so that appending to the table does not overwrite the file
+		buf = pr.data[s.offset:end:end]
+	} else if s.length != 0 { // ReadAt may fail at the end of the file, even for an empty table
 		buf = make([]byte, s.length)
 		if _, err := pr.file.ReadAt(buf, int64(s.offset)); err != nil {
 			return nil, err
@@ -126,6 +169,8 @@ func (pr *FontParser) HasTable(tag Tag) bool {
 // or an error if not found.
 // Note that many tables are already interpreted by this package,
 // see the various XXXTable().
+// If the font was read from a fonts.BytesResource, the returned slice
+// points into the font content, and must not be modified.
 func (pr *FontParser) GetRawTable(tag Tag) ([]byte, error) {
 	s, found := pr.tables[tag]
 	if !found {
@@ -213,6 +258,23 @@ func (pr *FontParser) cffTable(numGlyphs int) (*type1c.Font, error) {
 	return out, nil
 }
 
+func (pr *FontParser) cff2Table(numGlyphs int) (*type1c.CFF2, error) {
+	buf, err := pr.GetRawTable(tagCFF2)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := type1c.ParseCFF2(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if N := out.NumGlyphs(); N != numGlyphs {
+		return nil, fmt.Errorf("invalid number of glyphs in CFF2 table (%d != %d)", N, numGlyphs)
+	}
+	return out, nil
+}
+
 func (pr *FontParser) sbixTable(numGlyphs int) (tableSbix, error) {
 	buf, err := pr.GetRawTable(tagSbix)
 	if err != nil {
@@ -326,6 +388,36 @@ func (pr *FontParser) GDEFTable(nbAxis int) (TableGDEF, error) {
 	return parseTableGdef(buf, nbAxis)
 }
 
+// MATHTable returns the Mathematical Typesetting table identified with the 'MATH' tag.
+func (pr *FontParser) MATHTable() (TableMATH, error) {
+	buf, err := pr.GetRawTable(tagMath)
+	if err != nil {
+		return TableMATH{}, err
+	}
+
+	return parseTableMATH(buf)
+}
+
+// STATTable returns the Style Attributes table identified with the 'STAT' tag.
+func (pr *FontParser) STATTable() (TableSTAT, error) {
+	buf, err := pr.GetRawTable(tagStat)
+	if err != nil {
+		return TableSTAT{}, err
+	}
+
+	return parseTableSTAT(buf)
+}
+
+// MetaTable returns the Metadata table identified with the 'meta' tag.
+func (pr *FontParser) MetaTable() (TableMeta, error) {
+	buf, err := pr.GetRawTable(tagMeta)
+	if err != nil {
+		return TableMeta{}, err
+	}
+
+	return parseTableMeta(buf)
+}
+
 // TableMaxp table maxp
 type TableMaxp struct {
 	Version               uint32
@@ -398,6 +490,14 @@ func (pr *FontParser) prepTable() ([]byte, error) {
 	return nil, nil
 }
 
+func (pr *FontParser) fpgmTable() ([]byte, error) {
+	s, found := pr.tables[tagFpgm]
+	if found {
+		return pr.findTableBuffer(s)
+	}
+	return nil, nil
+}
+
 func (pr *FontParser) cvtTable() ([]byte, error) {
 	s, found := pr.tables[tagCvt]
 	if found {
@@ -406,6 +506,16 @@ func (pr *FontParser) cvtTable() ([]byte, error) {
 	return nil, nil
 }
 
+// GaspTable returns the Grid-fitting And Scan-conversion Procedure table.
+func (pr *FontParser) GaspTable() (TableGasp, error) {
+	buf, err := pr.GetRawTable(tagGasp)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTableGasp(buf)
+}
+
 func (pr *FontParser) maxpTable() (TableMaxp, error) {
 	s, found := pr.tables[tagMaxp]
 	if !found {
@@ -454,6 +564,24 @@ func (pr *FontParser) svgTable() (tableSVG, error) {
 	return parseTableSVG(buf)
 }
 
+// colrTable returns the Color table
+func (pr *FontParser) colrTable(fvar TableFvar) (tableCOLR, error) {
+	buf, err := pr.GetRawTable(tagCOLR)
+	if err != nil {
+		return tableCOLR{}, err
+	}
+	return parseTableCOLR(buf, len(fvar.Axis))
+}
+
+// cpalTable returns the Color Palette table
+func (pr *FontParser) cpalTable() (tableCPAL, error) {
+	buf, err := pr.GetRawTable(tagCPAL)
+	if err != nil {
+		return tableCPAL{}, err
+	}
+	return parseTableCPAL(buf)
+}
+
 // HmtxTable returns the glyphs horizontal metrics (array of size numGlyphs),
 // expressed in fonts units.
 func (pr *FontParser) HmtxTable(numGlyphs int) (TableHVmtx, error) {
@@ -565,12 +693,12 @@ func (pr *FontParser) tryAndLoadFvarTable(names TableName) (TableFvar, error) {
 func (pr *FontParser) tryAndLoadAvarTable(fvar TableFvar) (tableAvar, error) {
 	s, found := pr.tables[tagAvar]
 	if !found {
-		return nil, nil
+		return tableAvar{}, nil
 	}
 
 	buf, err := pr.findTableBuffer(s)
 	if err != nil {
-		return nil, err
+		return tableAvar{}, err
 	}
 
 	return parseTableAvar(buf, len(fvar.Axis))
@@ -591,7 +719,7 @@ func (pr *FontParser) hvarTable(fvar TableFvar) (tableHVvar, error) {
 		return tableHVvar{}, err
 	}
 
-	return parseTableHVvar(buf, len(fvar.Axis))
+	return parseTableHVvar(buf, len(fvar.Axis), false)
 }
 
 func (pr *FontParser) vvarTable(fvar TableFvar) (tableHVvar, error) {
@@ -600,7 +728,7 @@ func (pr *FontParser) vvarTable(fvar TableFvar) (tableHVvar, error) {
 		return tableHVvar{}, err
 	}
 
-	return parseTableHVvar(buf, len(fvar.Axis))
+	return parseTableHVvar(buf, len(fvar.Axis), true)
 }
 
 func (pr *FontParser) mvarTable(fvar TableFvar) (TableMvar, error) {
@@ -632,6 +760,9 @@ func (pr *FontParser) loadLayoutTables(numGlyphs int, fvar TableFvar) (out Layou
 	if tb, err := pr.GPOSTable(); err == nil {
 		out.GPOS = tb
 	}
+	if tb, err := pr.MATHTable(); err == nil {
+		out.Math = tb
+	}
 
 	if tb, err := pr.MorxTable(numGlyphs); err == nil {
 		out.Morx = tb
@@ -702,7 +833,7 @@ func (pr *FontParser) LoadGraphiteTables() (gr GraphiteTables, err error) {
 	return gr, nil
 }
 
-func parseOneFont(file fonts.Resource, offset uint32, relativeOffset bool) (parser *FontParser, err error) {
+func parseOneFont(file fonts.Resource, offset uint32, relativeOffset bool, opts ParseOptions) (parser *FontParser, err error) {
 	_, err = file.Seek(int64(offset), io.SeekStart)
 	if err != nil {
 		return nil, fmt.Errorf("invalid offset: %s", err)
@@ -718,8 +849,10 @@ func parseOneFont(file fonts.Resource, offset uint32, relativeOffset bool) (pars
 	switch magic {
 	case SignatureWOFF:
 		parser, err = parseWOFF(file, offset, relativeOffset)
+	case SignatureWOFF2:
+		parser, err = parseWOFF2(file, offset)
 	case TypeTrueType, TypeOpenType, TypePostScript1, TypeAppleTrueType:
-		parser, err = parseOTF(file, offset, relativeOffset)
+		parser, err = parseOTF(file, offset, relativeOffset, opts)
 	default:
 		// no more collections allowed here
 		return nil, errUnsupportedFormat
@@ -735,7 +868,11 @@ func parseOneFont(file fonts.Resource, offset uint32, relativeOffset bool) (pars
 // loadTables calls all the functions loading the
 // various font tables,
 // and return the loaded font
-func (pr *FontParser) loadTables() (*Font, error) {
+func (pr *FontParser) loadTables() (*Font, error) { return pr.loadTablesShared(nil) }
+
+// loadTablesShared is the same as loadTables, but reuses the lazy tables
+// found in `shared`, if not nil.
+func (pr *FontParser) loadTablesShared(shared *sharedTables) (*Font, error) {
 	var (
 		out Font
 		err error
@@ -763,12 +900,16 @@ func (pr *FontParser) loadTables() (*Font, error) {
 		return nil, err
 	}
 
-	out.NumGlyphs = int(out.Maxp.NumGlyphs)
-
-	cmaps, err := pr.CmapTable()
+	out.fpgm, err = pr.fpgmTable()
 	if err != nil {
 		return nil, err
 	}
+
+	out.NumGlyphs = int(out.Maxp.NumGlyphs)
+
+	if !pr.HasTable(tagCmap) {
+		return nil, errors.New("missing required 'cmap' table")
+	}
 	out.Head, err = pr.loadHeadTable()
 	if err != nil {
 		return nil, err
@@ -790,14 +931,16 @@ func (pr *FontParser) loadTables() (*Font, error) {
 
 	out.OS2, _ = pr.OS2Table()
 
-	out.Glyf, _ = pr.GlyfTable(out.NumGlyphs, out.Head.indexToLocFormat)
-
 	out.bitmap = pr.selectBitmapTable()
 
 	out.sbix, _ = pr.sbixTable(out.NumGlyphs)
 	out.cff, _ = pr.cffTable(out.NumGlyphs)
+	out.cff2, _ = pr.cff2Table(out.NumGlyphs)
 	out.post, _ = pr.PostTable(out.NumGlyphs)
+	out.glyphNames = new(glyphNameIndex)
 	out.svg, _ = pr.svgTable()
+	out.colr, _ = pr.colrTable(out.fvar)
+	out.cpal, _ = pr.cpalTable()
 
 	out.hhea, _ = pr.HheaTable()
 	out.vhea, _ = pr.VheaTable()
@@ -806,7 +949,6 @@ func (pr *FontParser) loadTables() (*Font, error) {
 
 	if len(out.fvar.Axis) != 0 {
 		out.mvar, _ = pr.mvarTable(out.fvar)
-		out.gvar, _ = pr.gvarTable(out.Glyf, out.fvar)
 		if v, err := pr.hvarTable(out.fvar); err == nil {
 			out.hvar = &v
 		}
@@ -815,14 +957,19 @@ func (pr *FontParser) loadTables() (*Font, error) {
 		}
 	}
 
-	out.cmap, out.cmapEncoding = cmaps.BestEncoding()
-	out.cmapVar = cmaps.unicodeVariation
-
 	if vorg, err := pr.vorgTable(); err == nil {
 		out.vorg = &vorg
 	}
 
-	out.layoutTables = pr.loadLayoutTables(out.NumGlyphs, out.fvar)
+	out.stat, _ = pr.STATTable()
+	out.gasp, _ = pr.GaspTable()
+	out.meta, _ = pr.MetaTable()
+
+	// glyf, cmap and layout tables are only decoded on demand
+	out.lazy, err = pr.newLazyTables(out.NumGlyphs, out.Head.indexToLocFormat, out.fvar, shared)
+	if err != nil {
+		return nil, err
+	}
 
 	if pr.HasTable(TagSilf) {
 		var gr GraphiteTables
@@ -857,16 +1004,51 @@ func Parse(file fonts.Resource) (*Font, error) {
 	return pr.loadTables()
 }
 
+// ParseWithOptions is the same as Parse, but allows to
+// customize the parsing with `opts`.
+func ParseWithOptions(file fonts.Resource, opts ParseOptions) (*Font, error) {
+	pr, err := NewFontParserWithOptions(file, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.loadTables()
+}
+
+// ParseIndex is the same as Parse, but supports collections,
+// returning the face at `index`. For font files which are not collections,
+// the only valid index is 0.
+func ParseIndex(file fonts.Resource, index int) (*Font, error) {
+	offsets, relativeOffset, err := collectionOffsets(file)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(offsets) {
+		return nil, fmt.Errorf("invalid face index %d (for %d faces)", index, len(offsets))
+	}
+	pr, err := parseOneFont(file, offsets[index], relativeOffset, ParseOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.loadTables()
+}
+
 // Load implements fonts.FontLoader. For collection font files (.ttc, .otc),
 // multiple fonts may be returned.
+// The tables used by several faces of a collection are only decoded once.
 func Load(file fonts.Resource) (fonts.Faces, error) {
 	prs, err := NewFontParsers(file)
 	if err != nil {
 		return nil, err
 	}
+	var shared *sharedTables
+	if len(prs) > 1 {
+		shared = newSharedTables()
+	}
 	out := make(fonts.Faces, len(prs))
 	for i, pr := range prs {
-		out[i], err = pr.loadTables()
+		out[i], err = pr.loadTablesShared(shared)
 		if err != nil {
 			return nil, err
 		}
@@ -60,13 +60,18 @@ func (colorBitmap bitmapTable) glyphData(gid GID, xPpem, yPpem uint16) (fonts.Gl
 	return out, nil
 }
 
-// look for data in 'glyf' and 'cff' tables
+// look for data in 'glyf', 'CFF ' and 'CFF2' tables
 func (f *Font) outlineGlyphData(gid GID) (fonts.GlyphOutline, bool) {
 	out, err := f.glyphDataFromCFF1(gid)
 	if err == nil {
 		return out, true
 	}
 
+	out, err = f.glyphDataFromCFF2(gid)
+	if err == nil {
+		return out, true
+	}
+
 	out, err = f.glyphDataFromGlyf(gid)
 	if err == nil {
 		return out, true
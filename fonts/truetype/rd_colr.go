@@ -0,0 +1,464 @@
+package truetype
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// this file walks the COLR paint graph, forwarding the drawing
+// operations to a ColorPainter
+
+// ColorPainter is implemented by renderers of color glyphs.
+// It receives the drawing operations resolved by Font.PaintGlyph,
+// as a sequence of nested Push/Pop calls : every PushTransform, PushClipGlyph,
+// PushClipBox and PushLayer call is matched by a PopTransform, PopClip, PopClip
+// and PopLayer call.
+// All the coordinates are expressed in font units.
+type ColorPainter interface {
+	// PushTransform applies `transform` to the following operations,
+	// until the matching PopTransform, on top of the current transformation.
+	PushTransform(transform Affine2x3)
+	PopTransform()
+
+	// PushClipGlyph restricts the following operations to the outline of `glyph`,
+	// until the matching PopClip.
+	PushClipGlyph(glyph GID)
+	// PushClipBox restricts the following operations to `box`,
+	// until the matching PopClip.
+	PushClipBox(box ClipBox)
+	PopClip()
+
+	// Fill paints the current clip region with `brush`.
+	Fill(brush Brush)
+
+	// PushLayer starts an offscreen layer, which is composed
+	// with the content below it using `mode`, at the matching PopLayer.
+	PushLayer(mode CompositeMode)
+	PopLayer()
+}
+
+// Brush is the content used to fill a region.
+// It is one of SolidBrush, LinearGradientBrush, RadialGradientBrush
+// or SweepGradientBrush.
+type Brush interface {
+	isBrush()
+}
+
+func (SolidBrush) isBrush()          {}
+func (LinearGradientBrush) isBrush() {}
+func (RadialGradientBrush) isBrush() {}
+func (SweepGradientBrush) isBrush()  {}
+
+// SolidBrush fills with a uniform color.
+type SolidBrush struct {
+	Color color.NRGBA
+}
+
+// ColorStop is a color at a given position of a gradient,
+// where 0 is the start and 1 is the end of the gradient.
+type ColorStop struct {
+	Offset float32
+	Color  color.NRGBA
+}
+
+// LinearGradientBrush is a gradient along the line from P0 to P1,
+// rotated so that the color is constant along the lines
+// parallel to P0P2.
+type LinearGradientBrush struct {
+	Stops      []ColorStop // sorted by offset
+	P0, P1, P2 [2]float32
+	Extend     ColorExtend
+}
+
+// RadialGradientBrush is a gradient between the circles (C0, R0) and (C1, R1).
+type RadialGradientBrush struct {
+	Stops  []ColorStop // sorted by offset
+	C0, C1 [2]float32
+	R0, R1 float32
+	Extend ColorExtend
+}
+
+// SweepGradientBrush is a gradient around Center, from StartAngle to EndAngle,
+// expressed in counter-clockwise degrees from the x axis.
+type SweepGradientBrush struct {
+	Stops                []ColorStop // sorted by offset
+	Center               [2]float32
+	StartAngle, EndAngle float32
+	Extend               ColorExtend
+}
+
+// Palettes returns the color palettes defined in the 'CPAL' table, if any.
+// All the palettes have the same number of colors.
+func (f *Font) Palettes() [][]color.NRGBA { return f.cpal.palettes }
+
+// PaletteFlags returns the usage flags of the palette at index `palette`,
+// or 0 if the font does not provide them.
+func (f *Font) PaletteFlags(palette int) PaletteFlags {
+	if palette < 0 || palette >= len(f.cpal.flags) {
+		return 0
+	}
+	return f.cpal.flags[palette]
+}
+
+// PaletteName returns the 'name' table entry labelling the palette at index `palette`.
+// It returns false if the palette has no label.
+func (f *Font) PaletteName(palette int) (NameID, bool) {
+	if palette < 0 || palette >= len(f.cpal.labels) || f.cpal.labels[palette] == noPaletteLabel {
+		return 0, false
+	}
+	return f.cpal.labels[palette], true
+}
+
+// PaletteEntryName returns the 'name' table entry labelling the palette entry
+// at index `entry` (which is common to all the palettes).
+// It returns false if the entry has no label.
+func (f *Font) PaletteEntryName(entry uint16) (NameID, bool) {
+	if int(entry) >= len(f.cpal.entryLabels) || f.cpal.entryLabels[entry] == noPaletteLabel {
+		return 0, false
+	}
+	return f.cpal.entryLabels[entry], true
+}
+
+// SelectPalette returns the index of the first palette
+// with all the given `flags`, or 0 (the default palette)
+// if there is none.
+func (f *Font) SelectPalette(flags PaletteFlags) int {
+	for i, pf := range f.cpal.flags {
+		if pf&flags == flags {
+			return i
+		}
+	}
+	return 0
+}
+
+// Palette returns the colors of the palette at index `palette`,
+// where the entries of `overrides` (indexed by palette entry)
+// replace the colors defined by the font.
+// An invalid `palette` index selects the default palette (index 0).
+// The returned slice is a copy and may be modified by the caller.
+func (f *Font) Palette(palette int, overrides map[uint16]color.NRGBA) []color.NRGBA {
+	if palette < 0 || palette >= len(f.cpal.palettes) {
+		palette = 0
+	}
+	var out []color.NRGBA
+	if palette < len(f.cpal.palettes) {
+		out = append(out, f.cpal.palettes[palette]...)
+	}
+	for entry, c := range overrides {
+		if int(entry) < len(out) {
+			out[entry] = c
+		}
+	}
+	return out
+}
+
+// ResolveColor returns the color of the palette entry `entry`, as
+// referenced by 'COLR' layers and paints. The special entry 0xFFFF
+// is resolved to `foreground`.
+// It returns false if `entry` is out of range.
+func ResolveColor(palette []color.NRGBA, entry uint16, foreground color.NRGBA) (color.NRGBA, bool) {
+	if entry == 0xFFFF {
+		return foreground, true
+	}
+	if int(entry) < len(palette) {
+		return palette[entry], true
+	}
+	return color.NRGBA{}, false
+}
+
+// IsColorGlyph returns true if `glyph` has a color description in the 'COLR' table.
+func (f *Font) IsColorGlyph(glyph GID) bool {
+	if _, ok := f.colr.baseGlyphPaint(glyph); ok {
+		return true
+	}
+	_, ok := f.colr.baseGlyph(glyph)
+	return ok
+}
+
+// PaintGlyph walks the 'COLR' description of `glyph`, calling
+// the methods of `painter`. The colors are selected from the palette
+// at index `palette` (see Palettes); `foreground` is used for the text color.
+// The version 1 description is used when available, and the variations
+// are applied for variable fonts.
+// An error is returned if `glyph` has no color description, or if its
+// description is invalid (for instance, if it references itself).
+func (f *Font) PaintGlyph(glyph GID, palette int, foreground color.NRGBA, painter ColorPainter) error {
+	var colors []color.NRGBA
+	if palette >= 0 && palette < len(f.cpal.palettes) {
+		colors = f.cpal.palettes[palette]
+	}
+	return f.PaintGlyphWithColors(glyph, colors, foreground, painter)
+}
+
+// PaintGlyphWithColors is the same as PaintGlyph, but uses
+// the given `colors` instead of a font palette, typically obtained
+// with Palette to apply user overrides.
+func (f *Font) PaintGlyphWithColors(glyph GID, colors []color.NRGBA, foreground color.NRGBA, painter ColorPainter) error {
+	cp := colrPainter{
+		colr:       &f.colr,
+		coords:     f.varCoords,
+		colors:     colors,
+		foreground: foreground,
+		painter:    painter,
+		glyphs:     make(map[GID]bool),
+		layers:     make(map[paintColrLayers]bool),
+	}
+
+	if _, ok := f.colr.baseGlyphPaint(glyph); ok {
+		return cp.paintColrGlyph(glyph, 0)
+	}
+
+	base, ok := f.colr.baseGlyph(glyph)
+	if !ok {
+		return fmt.Errorf("no color description for glyph %d", glyph)
+	}
+	for _, layer := range f.colr.layers[base.firstLayer : base.firstLayer+base.numLayers] {
+		c, err := cp.resolveColor(layer.paletteIndex, 1)
+		if err != nil {
+			return err
+		}
+		painter.PushClipGlyph(GID(layer.glyph))
+		painter.Fill(SolidBrush{Color: c})
+		painter.PopClip()
+	}
+	return nil
+}
+
+// colrPainter stores the state needed to walk the paint graph
+type colrPainter struct {
+	colr    *tableCOLR
+	painter ColorPainter
+	// glyphs and layers on the current path, to detect cycles
+	glyphs     map[GID]bool
+	layers     map[paintColrLayers]bool
+	coords     []float32
+	colors     []color.NRGBA
+	foreground color.NRGBA
+}
+
+// deltas returns the `n` variation deltas starting at `varIndexBase`.
+func (cp *colrPainter) deltas(varIndexBase uint32, n int) [6]float32 {
+	var out [6]float32
+	if varIndexBase == noVariationIndex || len(cp.coords) == 0 {
+		return out
+	}
+	for i := 0; i < n; i++ {
+		varIndex := varIndexBase + uint32(i)
+		var index VariationStoreIndex
+		if len(cp.colr.varIndexMap) != 0 {
+			index = cp.colr.varIndexMap.getIndex(GID(varIndex))
+		} else { // implicit mapping
+			index = VariationStoreIndex{DeltaSetOuter: uint16(varIndex >> 16), DeltaSetInner: uint16(varIndex)}
+		}
+		out[i] = cp.colr.store.GetDelta(index, cp.coords)
+	}
+	return out
+}
+
+func (cp *colrPainter) resolveColor(paletteIndex uint16, alpha float32) (color.NRGBA, error) {
+	c, ok := ResolveColor(cp.colors, paletteIndex, cp.foreground)
+	if !ok {
+		return c, fmt.Errorf("invalid palette index %d", paletteIndex)
+	}
+	alpha = float32(math.Max(0, math.Min(1, float64(alpha))))
+	c.A = uint8(math.Round(float64(c.A) * float64(alpha)))
+	return c, nil
+}
+
+// resolveColorLine applies the variations to the stops, and sort them
+func (cp *colrPainter) resolveColorLine(line colorLine) ([]ColorStop, error) {
+	out := make([]ColorStop, len(line.stops))
+	for i, stop := range line.stops {
+		d := cp.deltas(stop.varIndexBase, 2)
+		c, err := cp.resolveColor(stop.paletteIndex, stop.alpha+d[1]/(1<<14))
+		if err != nil {
+			return nil, err
+		}
+		out[i] = ColorStop{Offset: stop.stopOffset + d[0]/(1<<14), Color: c}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Offset < out[j].Offset })
+	return out, nil
+}
+
+// paintColrGlyph paints the base glyph `glyph` inside its clip box
+func (cp *colrPainter) paintColrGlyph(glyph GID, depth int) error {
+	p, ok := cp.colr.baseGlyphPaint(glyph)
+	if !ok {
+		return fmt.Errorf("no color description for glyph %d", glyph)
+	}
+	if cp.glyphs[glyph] {
+		return errors.New("invalid 'COLR' table (cycle in glyphs)")
+	}
+	cp.glyphs[glyph] = true
+	defer delete(cp.glyphs, glyph)
+
+	box, hasClip := cp.colr.clipBox(glyph)
+	if hasClip {
+		d := cp.deltas(box.varIndexBase, 4)
+		box.XMin += d[0]
+		box.YMin += d[1]
+		box.XMax += d[2]
+		box.YMax += d[3]
+		cp.painter.PushClipBox(box)
+	}
+	err := cp.paint(p, depth)
+	if hasClip {
+		cp.painter.PopClip()
+	}
+	return err
+}
+
+// transformAround returns the transform `m` applied around (centerX, centerY)
+func transformAround(m Affine2x3, centerX, centerY float32) Affine2x3 {
+	m.DX = centerX - (m.XX*centerX + m.XY*centerY)
+	m.DY = centerY - (m.YX*centerX + m.YY*centerY)
+	return m
+}
+
+func (cp *colrPainter) paintTransformed(transform Affine2x3, child paint, depth int) error {
+	cp.painter.PushTransform(transform)
+	err := cp.paint(child, depth+1)
+	cp.painter.PopTransform()
+	return err
+}
+
+func (cp *colrPainter) paint(p paint, depth int) error {
+	if depth > maxPaintDepth {
+		return errors.New("invalid 'COLR' table (paint graph too deep)")
+	}
+
+	switch p := p.(type) {
+	case paintColrLayers:
+		end := int(p.firstLayerIndex) + int(p.numLayers)
+		if end > len(cp.colr.layerList) {
+			return fmt.Errorf("invalid 'COLR' layer index %d", end)
+		}
+		if cp.layers[p] {
+			return errors.New("invalid 'COLR' table (cycle in layers)")
+		}
+		cp.layers[p] = true
+		defer delete(cp.layers, p)
+		// each layer is drawn over the previous ones
+		for _, layer := range cp.colr.layerList[p.firstLayerIndex:end] {
+			if err := cp.paint(layer, depth+1); err != nil {
+				return err
+			}
+		}
+	case paintSolid:
+		d := cp.deltas(p.varIndexBase, 1)
+		c, err := cp.resolveColor(p.paletteIndex, p.alpha+d[0]/(1<<14))
+		if err != nil {
+			return err
+		}
+		cp.painter.Fill(SolidBrush{Color: c})
+	case paintLinearGradient:
+		stops, err := cp.resolveColorLine(p.colorLine)
+		if err != nil {
+			return err
+		}
+		d := cp.deltas(p.varIndexBase, 6)
+		cp.painter.Fill(LinearGradientBrush{
+			Stops:  stops,
+			P0:     [2]float32{p.x0 + d[0], p.y0 + d[1]},
+			P1:     [2]float32{p.x1 + d[2], p.y1 + d[3]},
+			P2:     [2]float32{p.x2 + d[4], p.y2 + d[5]},
+			Extend: p.colorLine.extend,
+		})
+	case paintRadialGradient:
+		stops, err := cp.resolveColorLine(p.colorLine)
+		if err != nil {
+			return err
+		}
+		d := cp.deltas(p.varIndexBase, 6)
+		cp.painter.Fill(RadialGradientBrush{
+			Stops:  stops,
+			C0:     [2]float32{p.x0 + d[0], p.y0 + d[1]},
+			R0:     p.r0 + d[2],
+			C1:     [2]float32{p.x1 + d[3], p.y1 + d[4]},
+			R1:     p.r1 + d[5],
+			Extend: p.colorLine.extend,
+		})
+	case paintSweepGradient:
+		stops, err := cp.resolveColorLine(p.colorLine)
+		if err != nil {
+			return err
+		}
+		d := cp.deltas(p.varIndexBase, 4)
+		cp.painter.Fill(SweepGradientBrush{
+			Stops:      stops,
+			Center:     [2]float32{p.centerX + d[0], p.centerY + d[1]},
+			StartAngle: 180 * (p.startAngle + d[2]/(1<<14)),
+			EndAngle:   180 * (p.endAngle + d[3]/(1<<14)),
+			Extend:     p.colorLine.extend,
+		})
+	case paintGlyph:
+		cp.painter.PushClipGlyph(GID(p.glyph))
+		err := cp.paint(p.paint, depth+1)
+		cp.painter.PopClip()
+		return err
+	case paintColrGlyph:
+		return cp.paintColrGlyph(GID(p.glyph), depth+1)
+	case paintTransform:
+		d := cp.deltas(p.varIndexBase, 6)
+		m := p.transform
+		m.XX += d[0] / (1 << 16)
+		m.YX += d[1] / (1 << 16)
+		m.XY += d[2] / (1 << 16)
+		m.YY += d[3] / (1 << 16)
+		m.DX += d[4] / (1 << 16)
+		m.DY += d[5] / (1 << 16)
+		return cp.paintTransformed(m, p.paint, depth)
+	case paintTranslate:
+		d := cp.deltas(p.varIndexBase, 2)
+		return cp.paintTransformed(Affine2x3{XX: 1, YY: 1, DX: p.dx + d[0], DY: p.dy + d[1]}, p.paint, depth)
+	case paintScale:
+		var scaleX, scaleY, centerX, centerY float32
+		if p.uniform {
+			d := cp.deltas(p.varIndexBase, 3)
+			scaleX = p.scaleX + d[0]/(1<<14)
+			scaleY = scaleX
+			centerX, centerY = p.centerX+d[1], p.centerY+d[2]
+		} else {
+			d := cp.deltas(p.varIndexBase, 4)
+			scaleX, scaleY = p.scaleX+d[0]/(1<<14), p.scaleY+d[1]/(1<<14)
+			centerX, centerY = p.centerX+d[2], p.centerY+d[3]
+		}
+		m := Affine2x3{XX: scaleX, YY: scaleY}
+		if p.aroundCenter {
+			m = transformAround(m, centerX, centerY)
+		}
+		return cp.paintTransformed(m, p.paint, depth)
+	case paintRotate:
+		d := cp.deltas(p.varIndexBase, 3)
+		angle := float64(p.angle+d[0]/(1<<14)) * math.Pi
+		sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+		m := Affine2x3{XX: cos, YX: sin, XY: -sin, YY: cos}
+		if p.aroundCenter {
+			m = transformAround(m, p.centerX+d[1], p.centerY+d[2])
+		}
+		return cp.paintTransformed(m, p.paint, depth)
+	case paintSkew:
+		d := cp.deltas(p.varIndexBase, 4)
+		xAngle := float64(p.xSkewAngle+d[0]/(1<<14)) * math.Pi
+		yAngle := float64(p.ySkewAngle+d[1]/(1<<14)) * math.Pi
+		m := Affine2x3{XX: 1, YX: float32(math.Tan(yAngle)), XY: float32(-math.Tan(xAngle)), YY: 1}
+		if p.aroundCenter {
+			m = transformAround(m, p.centerX+d[2], p.centerY+d[3])
+		}
+		return cp.paintTransformed(m, p.paint, depth)
+	case paintComposite:
+		cp.painter.PushLayer(CompositeSrcOver)
+		err := cp.paint(p.backdrop, depth+1)
+		if err == nil {
+			cp.painter.PushLayer(p.mode)
+			err = cp.paint(p.source, depth+1)
+			cp.painter.PopLayer()
+		}
+		cp.painter.PopLayer()
+		return err
+	}
+	return nil
+}
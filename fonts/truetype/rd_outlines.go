@@ -3,8 +3,11 @@ package truetype
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+	type1c "github.com/boxesandglue/textlayout/fonts/type1C"
 )
 
 // this file converts from font format for glyph outlines to
@@ -132,11 +135,14 @@ func buildSegments(points []contourPoint) []fonts.Segment {
 
 // apply variation when needed
 func (f *Font) glyphDataFromGlyf(glyph GID) (fonts.GlyphOutline, error) {
-	if int(glyph) >= len(f.Glyf) {
+	if int(glyph) >= len(f.Glyf()) {
 		return fonts.GlyphOutline{}, fmt.Errorf("out of range glyph %d", glyph)
 	}
 	var points []contourPoint
 	f.getPointsForGlyph(glyph, 0, &points)
+	if len(points) < phantomCount {
+		return fonts.GlyphOutline{}, fmt.Errorf("invalid composite glyph %d", glyph)
+	}
 	segments := buildSegments(points[:len(points)-phantomCount])
 	return fonts.GlyphOutline{Segments: segments}, nil
 }
@@ -151,3 +157,96 @@ func (f *Font) glyphDataFromCFF1(glyph GID) (fonts.GlyphOutline, error) {
 	}
 	return fonts.GlyphOutline{Segments: segments}, nil
 }
+
+// apply variation when needed
+func (f *Font) glyphDataFromCFF2(glyph GID) (fonts.GlyphOutline, error) {
+	if f.cff2 == nil {
+		return fonts.GlyphOutline{}, errors.New("no CFF2 table")
+	}
+	segments, _, err := f.cff2.LoadGlyph(glyph, f.varCoords)
+	if err != nil {
+		return fonts.GlyphOutline{}, err
+	}
+	return fonts.GlyphOutline{Segments: segments}, nil
+}
+
+// GlyphHintsCFF returns the hints of `glyph`, as defined in its
+// Type 2 charstring, in the 'CFF ' or 'CFF2' table.
+// For variable fonts, the stems are blended using the current variations.
+// An error is returned for fonts without CFF outlines.
+func (f *Font) GlyphHintsCFF(glyph GID) (*type1c.Hints, error) {
+	var (
+		hints *type1c.Hints
+		err   error
+	)
+	switch {
+	case f.cff != nil:
+		_, _, hints, err = f.cff.LoadGlyphWithHints(glyph)
+	case f.cff2 != nil:
+		_, _, hints, err = f.cff2.LoadGlyphWithHints(glyph, f.varCoords)
+	default:
+		return nil, errors.New("no CFF outlines")
+	}
+	return hints, err
+}
+
+// DrawGlyphCFF sends the outlines of `glyph`, from the 'CFF ' or 'CFF2' table,
+// to `consumer` as its charstring is interpreted, without storing them.
+// For variable fonts, the current variations are applied.
+// If `transform` is not nil, it is applied to the points as they are emitted,
+// so that scaled or slanted outlines are produced in one pass (see also
+// type1c.Font.FontMatrix).
+// An error is returned for fonts without CFF outlines.
+func (f *Font) DrawGlyphCFF(glyph GID, transform *ps.Matrix, consumer ps.PathConsumer) error {
+	var err error
+	switch {
+	case f.cff != nil:
+		_, err = f.cff.DrawGlyph(glyph, transform, consumer)
+	case f.cff2 != nil:
+		_, err = f.cff2.DrawGlyph(glyph, f.varCoords, transform, consumer)
+	default:
+		return errors.New("no CFF outlines")
+	}
+	return err
+}
+
+// GlyphComponentsCFF returns the components of `glyph` if it is an accented
+// glyph in the 'CFF ' table (see type1c.Font.GlyphComponents), or nil otherwise.
+// An error is returned for fonts without a 'CFF ' table.
+func (f *Font) GlyphComponentsCFF(glyph GID) (*type1c.Seac, error) {
+	if f.cff == nil {
+		return nil, errors.New("no CFF table")
+	}
+	return f.cff.GlyphComponents(glyph)
+}
+
+// StaticCFF returns a static CFF font with the 'CFF2' outlines of `f` instanced at
+// the current variation coordinates, so that it may be embedded in a PDF file
+// (see type1c.CFF.WriteCFFData). The glyph names are read from the 'post' table
+// and the advances from the horizontal metrics, with the variations applied.
+// An error is returned for fonts without a 'CFF2' table.
+func (f *Font) StaticCFF() (*type1c.CFF, error) {
+	if f.cff2 == nil {
+		return nil, errors.New("no CFF2 table")
+	}
+	numGlyphs := f.cff2.NumGlyphs()
+	data := type1c.FontData{
+		FontName:           f.PostscriptName(),
+		FamilyName:         f.Names.getName(NameFontFamily),
+		FontBBox:           [4]int{int(f.Head.XMin), int(f.Head.YMin), int(f.Head.XMax), int(f.Head.YMax)},
+		UnderlinePosition:  float64(f.post.UnderlinePosition),
+		UnderlineThickness: float64(f.post.UnderlineThickness),
+		GlyphNames:         make([]string, numGlyphs),
+	}
+	if upem := f.Upem(); upem != 1000 {
+		data.FontMatrix = []float64{1 / float64(upem), 0, 0, 1 / float64(upem), 0, 0}
+	}
+	advances := make([]int32, numGlyphs)
+	for i := range advances {
+		gid := GID(i)
+		data.GlyphNames[i] = f.GlyphName(gid)
+		advances[i] = int32(math.Round(float64(f.HorizontalAdvance(gid))))
+	}
+	data.GlyphNames[0] = ".notdef"
+	return f.cff2.ToCFF(f.varCoords, data, advances)
+}
@@ -1,11 +1,16 @@
 package truetype
 
 import (
+	"bytes"
+	"math"
 	"reflect"
 	"testing"
 
+	hbtestdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
 	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
 	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+	type1c "github.com/boxesandglue/textlayout/fonts/type1C"
 	"golang.org/x/image/font/sfnt"
 	fx "golang.org/x/image/math/fixed"
 )
@@ -195,8 +200,8 @@ func TestGlyfSegments1(t *testing.T) {
 		transform_(22381, 8192, 5996, 14188, 237, 258, lineTo(205, 0)),
 	}}
 
-	if len(f.Glyf) != len(expecteds) {
-		t.Fatalf("number of glyphs: expected %d, got %d", len(expecteds), len(f.Glyf))
+	if len(f.Glyf()) != len(expecteds) {
+		t.Fatalf("number of glyphs: expected %d, got %d", len(expecteds), len(f.Glyf()))
 	}
 
 	for i, expected := range expecteds {
@@ -480,3 +485,354 @@ func TestCFFSegments(t *testing.T) {
 		}
 	}
 }
+
+func TestCFF2Segments(t *testing.T) {
+	font := loadFont(t, "TestCFF2VF.otf")
+	if font.cff2 == nil {
+		t.Fatal("missing CFF2 table")
+	}
+
+	// the 'A' glyph, at the end of the weight axis
+	font.SetVarCoordinates([]float32{1})
+	expected := []fonts.Segment{
+		// - contour #0
+		moveTo(0, 0),
+		lineTo(176, 0),
+		lineTo(249, 316),
+		cubeTo(263, 378, 280, 456, 294, 522),
+		lineTo(298, 522),
+		cubeTo(312, 456, 331, 378, 345, 316),
+		lineTo(418, 0),
+		lineTo(600, 0),
+		lineTo(404, 650),
+		lineTo(196, 650),
+		lineTo(0, 0),
+		// - contour #1
+		moveTo(141, 138),
+		lineTo(457, 138),
+		lineTo(457, 271),
+		lineTo(141, 271),
+		lineTo(141, 138),
+	}
+	if got := font.GlyphData(1, 0, 0).(fonts.GlyphOutline).Segments; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	font.SetVarCoordinates([]float32{-1})
+	exp := fonts.GlyphExtents{XBearing: 50, YBearing: 660, Width: 500, Height: -660}
+	if extents, _ := font.GlyphExtents(1, 0, 0); extents != exp {
+		t.Fatalf("expected %v, got %v", exp, extents)
+	}
+
+	// the default outlines are consistent with the side bearings
+	file, err := hbtestdata.Files.ReadFile("harfbuzz_reference/text-rendering-tests/fonts/AdobeVFPrototype-Subset.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prototype, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for filename, font := range map[string]*Font{"TestCFF2VF.otf": loadFont(t, "TestCFF2VF.otf"), "AdobeVFPrototype-Subset.otf": prototype} {
+		for gid := 0; gid < font.NumGlyphs; gid++ {
+			extents, ok := font.GlyphExtents(GID(gid), 0, 0)
+			if !ok {
+				t.Fatalf("%s: missing extents for glyph %d", filename, gid)
+			}
+			if extents.Width != 0 && extents.XBearing != float32(font.Hmtx[gid].SideBearing) {
+				t.Fatalf("%s: glyph %d: expected side bearing %d, got %g", filename, gid, font.Hmtx[gid].SideBearing, extents.XBearing)
+			}
+		}
+	}
+
+	if _, err := prototype.Instance([]float32{500, 0}); err == nil {
+		t.Fatal("expected error for CFF2 instance")
+	}
+}
+
+func TestGlyphHintsCFF(t *testing.T) {
+	font := loadFont(t, "Raleway-v4020-Regular.otf")
+	gid, _ := font.NominalGlyph('H')
+	hints, err := font.GlyphHintsCFF(gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []type1c.Stem{{Edge: 21, Width: -21}, {Edge: 332, Width: 62}, {Edge: 710, Width: -20}}; !reflect.DeepEqual(hints.HStems, exp) {
+		t.Fatalf("unexpected horizontal stems %v", hints.HStems)
+	}
+	if exp := []type1c.Stem{{Edge: 89, Width: 70}, {Edge: 580, Width: 69}}; !reflect.DeepEqual(hints.VStems, exp) {
+		t.Fatalf("unexpected vertical stems %v", hints.VStems)
+	}
+
+	var masks int
+	for gid := 0; gid < font.NumGlyphs; gid++ {
+		hints, err := font.GlyphHintsCFF(GID(gid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments, _, _ := font.cff.LoadGlyph(GID(gid))
+		for _, mask := range hints.Masks {
+			if exp := (len(hints.HStems) + len(hints.VStems) + 7) / 8; len(mask.Mask) != exp {
+				t.Fatalf("glyph %d: expected mask of length %d, got %v", gid, exp, mask.Mask)
+			}
+			if mask.Segment > len(segments) {
+				t.Fatalf("glyph %d: invalid mask segment %d", gid, mask.Segment)
+			}
+		}
+		masks += len(hints.Masks)
+	}
+	if masks == 0 {
+		t.Fatal("expected hint masks")
+	}
+
+	if _, err = loadFont(t, "Roboto-BoldItalic.ttf").GlyphHintsCFF(1); err == nil {
+		t.Fatal("expected error for TrueType outlines")
+	}
+}
+
+func TestCFFFontDicts(t *testing.T) {
+	file, err := hbtestdata.Files.ReadFile("harfbuzz_reference/text-rendering-tests/fonts/FDArrayTest257.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cid, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := cid.cff.NumFontDicts(); n != 256 {
+		t.Fatalf("unexpected number of font dicts %d", n)
+	}
+	fd, err := cid.cff.FontDictIndex(GID(cid.NumGlyphs - 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fd != 255 {
+		t.Fatalf("unexpected font dict %d", fd)
+	}
+	private, err := cid.cff.PrivateDict(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if private.DefaultWidthX != 1000 || private.NominalWidthX != 0 || !reflect.DeepEqual(private.StemSnapV, []int{30, 80}) {
+		t.Fatalf("unexpected private dict %v", private)
+	}
+	if _, err = cid.cff.PrivateDict(256); err == nil {
+		t.Fatal("expected error for invalid font dict")
+	}
+
+	font := loadFont(t, "Raleway-v4020-Regular.otf")
+	if n := font.cff.NumFontDicts(); n != 1 {
+		t.Fatalf("unexpected number of font dicts %d", n)
+	}
+	private, err = font.cff.PrivateDict(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []int{-10, 0, 521, 531, 710, 720, 730, 740}; !reflect.DeepEqual(private.BlueValues, exp) || private.NominalWidthX != 649 {
+		t.Fatalf("unexpected private dict %v", private)
+	}
+
+	// the widths are decoded with the private dict of each glyph
+	for _, font := range []*Font{cid, font, loadFont(t, "STIX-BoldItalic.otf")} {
+		for gid := 0; gid < font.NumGlyphs; gid++ {
+			advance, err := font.cff.GlyphAdvance(GID(gid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp := font.Hmtx[gid].Advance; advance != int32(exp) {
+				t.Fatalf("glyph %d: expected advance %d, got %d", gid, exp, advance)
+			}
+		}
+	}
+}
+
+func TestCFFConversion(t *testing.T) {
+	file, err := hbtestdata.Files.ReadFile("harfbuzz_reference/text-rendering-tests/fonts/AdobeVFPrototype-Subset.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prototype, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// de-blend variable fonts at several instances
+	for _, font := range []*Font{loadFont(t, "TestCFF2VF.otf"), prototype} {
+		for _, coord := range []float32{0, 1, -0.6, 0.3} {
+			coords := make([]float32, len(font.fvar.Axis))
+			for i := range coords {
+				coords[i] = coord
+			}
+			font.SetVarCoordinates(coords)
+
+			static, err := font.StaticCFF()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err = static.WriteCFFData(&buf); err != nil {
+				t.Fatal(err)
+			}
+			cff, err := type1c.Parse(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the variable table is preserved when written
+			buf.Reset()
+			if err = font.cff2.WriteCFF2Data(&buf); err != nil {
+				t.Fatal(err)
+			}
+			cff2, err := type1c.ParseCFF2(buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for gid := 0; gid < font.NumGlyphs; gid++ {
+				glyph := GID(gid)
+				exp, _, expHints, err := font.cff2.LoadGlyphWithHints(glyph, coords)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, _, hints, err := cff.LoadGlyphWithHints(glyph)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(exp, got) || !reflect.DeepEqual(expHints, hints) {
+					t.Fatalf("glyph %d at %v: expected\n%v %v, got\n%v %v", gid, coords, exp, expHints, got, hints)
+				}
+				expAdvance := int32(math.Round(float64(font.HorizontalAdvance(glyph))))
+				if advance, _ := cff.GlyphAdvance(glyph); advance != expAdvance {
+					t.Fatalf("glyph %d at %v: expected advance %d, got %d", gid, coords, expAdvance, advance)
+				}
+
+				got, _, err = cff2.LoadGlyph(glyph, coords)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(exp, got) {
+					t.Fatalf("glyph %d at %v: expected\n%v, got\n%v", gid, coords, exp, got)
+				}
+			}
+		}
+	}
+
+	// wrap static outlines
+	for _, font := range []*Font{loadFont(t, "Raleway-v4020-Regular.otf"), loadFont(t, "STIX-BoldItalic.otf")} {
+		cff2, err := font.cff.ToCFF2()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err = cff2.WriteCFF2Data(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if cff2, err = type1c.ParseCFF2(buf.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		for gid := 0; gid < font.NumGlyphs; gid++ {
+			glyph := GID(gid)
+			exp, _, expHints, err := font.cff.LoadGlyphWithHints(glyph)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, hints, err := cff2.LoadGlyphWithHints(glyph, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(exp, got) {
+				t.Fatalf("glyph %d: expected\n%v, got\n%v", gid, exp, got)
+			}
+			// stems with fractional values are clamped
+			hasFixedStems := false
+			for _, stem := range append(expHints.HStems, expHints.VStems...) {
+				hasFixedStems = hasFixedStems || stem.Edge > math.MaxInt16 || stem.Width > math.MaxInt16
+			}
+			if components, _ := font.cff.GlyphComponents(glyph); components == nil && !hasFixedStems && !reflect.DeepEqual(expHints, hints) {
+				t.Fatalf("glyph %d: expected hints %v, got %v", gid, expHints, hints)
+			}
+		}
+	}
+
+	if _, err = loadFont(t, "Raleway-v4020-Regular.otf").StaticCFF(); err == nil {
+		t.Fatal("expected error for CFF outlines")
+	}
+}
+
+// segmentsRecorder is a ps.PathConsumer storing the segments
+type segmentsRecorder []fonts.Segment
+
+func (r *segmentsRecorder) MoveTo(pt fonts.SegmentPoint) {
+	*r = append(*r, fonts.Segment{Op: fonts.SegmentOpMoveTo, Args: [3]fonts.SegmentPoint{pt}})
+}
+
+func (r *segmentsRecorder) LineTo(pt fonts.SegmentPoint) {
+	*r = append(*r, fonts.Segment{Op: fonts.SegmentOpLineTo, Args: [3]fonts.SegmentPoint{pt}})
+}
+
+func (r *segmentsRecorder) CurveTo(pt1, pt2, pt3 fonts.SegmentPoint) {
+	*r = append(*r, fonts.Segment{Op: fonts.SegmentOpCubeTo, Args: [3]fonts.SegmentPoint{pt1, pt2, pt3}})
+}
+
+func TestDrawGlyphCFF(t *testing.T) {
+	variable := loadFont(t, "TestCFF2VF.otf")
+	variable.SetVarCoordinates([]float32{0.4})
+	for _, font := range []*Font{loadFont(t, "Raleway-v4020-Regular.otf"), loadFont(t, "STIX-BoldItalic.otf"), variable} {
+		for gid := 0; gid < font.NumGlyphs; gid++ {
+			var got segmentsRecorder
+			if err := font.DrawGlyphCFF(GID(gid), nil, &got); err != nil {
+				t.Fatal(err)
+			}
+			exp := font.GlyphData(GID(gid), 0, 0).(fonts.GlyphOutline).Segments
+			if len(exp) != len(got) || (len(exp) != 0 && !reflect.DeepEqual(exp, []fonts.Segment(got))) {
+				t.Fatalf("glyph %d: expected\n%v, got\n%v", gid, exp, got)
+			}
+
+			// scaled and slanted outlines
+			transform := ps.Matrix{0.012, 0, 0.003, 0.012, 5, -2}
+			got = got[:0]
+			if err := font.DrawGlyphCFF(GID(gid), &transform, &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(exp) != len(got) {
+				t.Fatalf("glyph %d: expected %d segments, got %d", gid, len(exp), len(got))
+			}
+			for i, seg := range exp {
+				for j, pt := range seg.ArgsSlice() {
+					if tr := transform.Apply(pt); got[i].Args[j] != tr {
+						t.Fatalf("glyph %d, segment %d: expected %v, got %v", gid, i, tr, got[i].Args[j])
+					}
+				}
+			}
+		}
+	}
+
+	if err := loadFont(t, "Roboto-BoldItalic.ttf").DrawGlyphCFF(1, nil, new(segmentsRecorder)); err == nil {
+		t.Fatal("expected error for TrueType outlines")
+	}
+}
+
+func TestFontMatrixCFF(t *testing.T) {
+	file, err := hbtestdata.Files.ReadFile("harfbuzz_reference/text-rendering-tests/fonts/FDArrayTest257.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cid, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, font := range []*Font{loadFont(t, "Raleway-v4020-Regular.otf"), loadFont(t, "STIX-BoldItalic.otf"), cid} {
+		for _, gid := range []GID{0, 1, GID(font.NumGlyphs - 1)} {
+			m, err := font.cff.FontMatrix(gid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp := (ps.Matrix{0.001, 0, 0, 0.001, 0, 0}); m != exp {
+				t.Fatalf("glyph %d: expected %v, got %v", gid, exp, m)
+			}
+		}
+	}
+	if _, err := cid.cff.FontMatrix(GID(cid.NumGlyphs)); err == nil {
+		t.Fatal("expected error for invalid glyph")
+	}
+}
@@ -22,65 +22,175 @@ func binarywrite(w io.Writer, data interface{}) error {
 	return nil
 }
 
-func (fnt *Font) getAdditionalCodepoints(codepoint GID) []GID {
-	var additionalCodepoints []GID
-	cp := fnt.Glyf[codepoint]
-	switch t := cp.data.(type) {
-	case simpleGlyphData:
-		additionalCodepoints = append(additionalCodepoints, codepoint)
-	case *compositeGlyphData:
-		for _, g := range t.glyphs {
-			additionalCodepoints = append(additionalCodepoints, fnt.getAdditionalCodepoints(g.glyphIndex)...)
+// addComponents adds to `glyphs` the components of the glyph `gid`,
+// if it is a composite glyph, recursively.
+func (fnt *Font) addComponents(gid GID, glyphs map[GID]bool) {
+	glyf := fnt.Glyf()
+	if int(gid) >= len(glyf) {
+		return
+	}
+	composite, ok := glyf[gid].data.(compositeGlyphData)
+	if !ok {
+		return
+	}
+	for _, part := range composite.glyphs {
+		if !glyphs[part.glyphIndex] { // also protects against cycles
+			glyphs[part.glyphIndex] = true
+			fnt.addComponents(part.glyphIndex, glyphs)
 		}
 	}
-	return additionalCodepoints
 }
 
 // subsetTrueType removes all data from the font file that is not necessary to
-// render the given code points.
-func (fnt *Font) subsetTrueType(codepoints []GID) error {
-	var additionalCodepoints []GID
-	for _, gid := range codepoints {
-		cp := fnt.Glyf[gid]
-		switch t := cp.data.(type) {
-		case compositeGlyphData:
-			for _, g := range t.glyphs {
-				additionalCodepoints = append(additionalCodepoints, fnt.getAdditionalCodepoints(g.glyphIndex)...)
+// render the glyphs in `keep`. The glyph indices are preserved: the
+// unused glyphs are replaced by empty ones, and the font is truncated after
+// the last glyph kept.
+func (fnt *Font) subsetTrueType(keep map[GID]bool) {
+	var numGlyphs int
+	for gid := range keep {
+		if int(gid) >= numGlyphs {
+			numGlyphs = int(gid) + 1
+		}
+	}
+
+	glyphs := make(TableGlyf, numGlyphs)
+	hmtx := make(TableHVmtx, numGlyphs)
+	var vmtx TableHVmtx
+	if len(fnt.vmtx) != 0 {
+		vmtx = make(TableHVmtx, numGlyphs)
+	}
+	glyf, gvar := fnt.lazy.glyphs()
+	for gid := range keep {
+		if int(gid) < len(glyf) {
+			glyphs[gid] = glyf[gid]
+		}
+		if int(gid) < len(fnt.Hmtx) {
+			hmtx[gid] = fnt.Hmtx[gid]
+		}
+		if int(gid) < len(fnt.vmtx) {
+			vmtx[gid] = fnt.vmtx[gid]
+		}
+	}
+	fnt.tables().setGlyphs(glyphs, gvar)
+	fnt.Hmtx, fnt.vmtx = hmtx, vmtx
+
+	// the tables may be shared with other fonts (see Instance)
+	if fnt.hhea != nil {
+		hhea := *fnt.hhea
+		hhea.NumberOfHMetrics = uint16(numGlyphs)
+		fnt.hhea = &hhea
+	}
+	if fnt.vhea != nil {
+		vhea := *fnt.vhea
+		vhea.NumberOfHMetrics = uint16(numGlyphs)
+		fnt.vhea = &vhea
+	}
+	fnt.NumGlyphs = numGlyphs
+	fnt.Maxp.NumGlyphs = uint16(numGlyphs)
+}
+
+// compileCmap12 returns the mappings of `cmap` to the glyphs accepted by `filter`,
+// sorted by rune and grouped by ranges of consecutive glyphs.
+func compileCmap12(cmap Cmap, filter func(GID) bool) cmap12 {
+	type mapping struct {
+		r rune
+		g GID
+	}
+	var mappings []mapping
+	for iter := cmap.Iter(); iter.Next(); {
+		r, g := iter.Char()
+		if g != 0 && filter(g) {
+			mappings = append(mappings, mapping{r, g})
+		}
+	}
+	sort.SliceStable(mappings, func(i, j int) bool { return mappings[i].r < mappings[j].r })
+
+	var out cmap12
+	for _, m := range mappings {
+		if L := len(out); L != 0 {
+			last := &out[L-1]
+			if uint32(m.r) <= last.end { // duplicated rune
+				continue
+			}
+			if uint32(m.r) == last.end+1 && uint32(m.g) == last.value+last.end-last.start+1 {
+				last.end++
+				continue
 			}
 		}
+		out = append(out, cmapEntry32{start: uint32(m.r), end: uint32(m.r), value: uint32(m.g)})
 	}
-	codepoints = append(codepoints, additionalCodepoints...)
-	fonts.RemoveDuplicates(codepoints)
+	return out
+}
 
-	maxCP := codepoints[len(codepoints)-1] + 1
-	// the codepoints not used in the subset (or used from one of these glyphs)
-	// are replaced by an empty glyph.
-	glyphs := make([]GlyphData, maxCP)
+// subsetCmap restricts the cmap to the glyphs in `keep`.
+func (fnt *Font) subsetCmap(keep map[GID]bool) {
+	cmap, encoding, cmapVar := fnt.lazy.cmaps()
+	if cmap == nil {
+		return
+	}
+	subset := compileCmap12(cmap, func(g GID) bool { return keep[g] })
+
+	variations := make(unicodeVariations, len(cmapVar))
+	for i, vs := range cmapVar {
+		variations[i] = variationSelector{varSelector: vs.varSelector, defaultUVS: vs.defaultUVS}
+		for _, m := range vs.nonDefaultUVS {
+			if keep[GID(m.glyphID)] {
+				variations[i].nonDefaultUVS = append(variations[i].nonDefaultUVS, m)
+			}
+		}
+	}
+	fnt.tables().setCmaps(subset, encoding, variations)
+}
 
-	emptyGlyph := GlyphData{}
-	for i, c := GID(0), 0; i < maxCP; i++ {
-		if i == codepoints[c] {
-			glyphs[i] = fnt.Glyf[i]
-			c++
-		} else {
-			fnt.Hmtx[i].Advance = 0
-			fnt.Hmtx[i].SideBearing = 0
-			glyphs[i] = emptyGlyph
+// coversAny returns true if one of the glyphs is covered.
+// A nil Coverage is considered as covering every glyph.
+func coversAny(cov Coverage, glyphs map[GID]bool) bool {
+	if cov == nil {
+		return true
+	}
+	for g := range glyphs {
+		if _, ok := cov.Index(g); ok {
+			return true
 		}
 	}
-	fnt.Glyf = glyphs
-	fnt.Hmtx = fnt.Hmtx[:maxCP]
-	fnt.NumGlyphs = int(maxCP)
-	fnt.Head.indexToLocFormat = 1
-	fnt.hhea.NumberOfHMetrics = uint16(maxCP)
-	fnt.subsetCodepoints = codepoints
-	return nil
+	return false
+}
+
+// subsetLayoutTables removes the GSUB and GPOS subtables
+// which do not apply to any of the glyphs in `keep`.
+// The lookups themselves are kept, since they are referenced by index.
+func (fnt *Font) subsetLayoutTables(keep map[GID]bool) {
+	layout := fnt.tables().layoutTables()
+	gsub := layout.GSUB.Lookups
+	layout.GSUB.Lookups = make([]LookupGSUB, len(gsub))
+	for i, lookup := range gsub {
+		var subtables []GSUBSubtable
+		for _, subtable := range lookup.Subtables {
+			if coversAny(subtable.Coverage, keep) {
+				subtables = append(subtables, subtable)
+			}
+		}
+		lookup.Subtables = subtables
+		layout.GSUB.Lookups[i] = lookup
+	}
+
+	gpos := layout.GPOS.Lookups
+	layout.GPOS.Lookups = make([]LookupGPOS, len(gpos))
+	for i, lookup := range gpos {
+		var subtables []GPOSSubtable
+		for _, subtable := range lookup.Subtables {
+			if coversAny(subtable.Coverage, keep) {
+				subtables = append(subtables, subtable)
+			}
+		}
+		lookup.Subtables = subtables
+		layout.GPOS.Lookups[i] = lookup
+	}
 }
 
 func (fnt *Font) subsetCFF(codepoints []GID) error {
 	fnt.subsetCodepoints = codepoints
-	fnt.cff.Subset(codepoints)
-	return nil
+	return fnt.cff.Subset(codepoints)
 }
 
 // WidthsPDF returns a width entry suitable for embedding in a PDF file.
@@ -199,22 +309,62 @@ func (fnt *Font) XHeightPDF() int {
 }
 
 // Subset removes all data from the font except the one needed for the given
-// code points.
+// glyphs. The .notdef glyph and the components of composite glyphs are always kept.
+// Contrary to Type 1 fonts, the glyph indices are preserved, so that they may
+// be used as CIDs in PDF files: the unused glyphs are replaced by empty ones,
+// and the glyphs after the last one used are removed.
+// The 'cmap' table is restricted to the glyphs kept, and the GSUB and GPOS
+// subtables which do not apply to any of them are removed.
+// See SubsetRunes to select the glyphs from characters.
+// Subset must only be called once.
 func (fnt *Font) Subset(codepoints []GID) error {
+	keep := map[GID]bool{0: true} // .notdef
+	for _, gid := range codepoints {
+		if int(gid) >= fnt.NumGlyphs {
+			return fmt.Errorf("invalid glyph index %d", gid)
+		}
+		keep[gid] = true
+	}
+	codepoints = fonts.RemoveDuplicates(append([]GID(nil), codepoints...))
+
 	fnt.SubsetID = getCharTag(codepoints)
-	if fnt.cff == nil {
-		err := fnt.subsetTrueType(codepoints)
-		return err
+	if fnt.cff != nil {
+		return fnt.subsetCFF(codepoints)
 	}
-	return fnt.subsetCFF(codepoints)
+
+	// the decoded tables may be shared with other fonts
+	fnt.lazy = fnt.lazy.decodedCopy()
+
+	for _, gid := range codepoints {
+		fnt.addComponents(gid, keep)
+	}
+	fnt.subsetCmap(keep)
+	fnt.subsetLayoutTables(keep)
+	fnt.subsetTrueType(keep)
+	fnt.subsetCodepoints = codepoints
+	return nil
 }
 
-type tableOffsetLength struct {
-	offset    uint32
-	length    uint32
-	tag       Tag
-	checksum  uint32
-	tabledata []byte
+// SubsetRunes is the same as Subset, but the glyphs are selected from the
+// characters in `runes`, using the 'cmap' table.
+// The glyphs which may be produced by the GSUB substitutions are also kept,
+// so that the subset font may still be used to shape a text made of `runes`.
+func (fnt *Font) SubsetRunes(runes []rune) error {
+	glyphs := make(map[GID]bool)
+	for _, r := range runes {
+		if g, ok := fnt.NominalGlyph(r); ok {
+			glyphs[g] = true
+		}
+	}
+	fnt.lazy.layoutTables().GSUB.Closure(glyphs, nil)
+
+	codepoints := make([]GID, 0, len(glyphs))
+	for g := range glyphs {
+		if int(g) < fnt.NumGlyphs { // ignore invalid substitutions
+			codepoints = append(codepoints, g)
+		}
+	}
+	return fnt.Subset(codepoints)
 }
 
 func (fnt *Font) writeHead(w io.Writer) error {
@@ -262,22 +412,31 @@ func (fnt *Font) writeHead(w io.Writer) error {
 	return err
 }
 
+// writeGlyf also chooses the 'loca' format, so that it must
+// be called before writeHead and writeLoca.
 func (fnt *Font) writeGlyf(w io.Writer) error {
 	glyphOffsets := []uint32{}
 	c := uint32(0)
+	isShort := true // the short format requires even offsets
+	glyf := fnt.Glyf()
 	for i := 0; i < fnt.NumGlyphs; i++ {
-		g := fnt.Glyf[i]
+		g := glyf[i]
 		glyphOffsets = append(glyphOffsets, c)
 		w.Write(g.rawdata)
 		c += uint32(len(g.rawdata))
+		isShort = isShort && c%2 == 0
 	}
 	glyphOffsets = append(glyphOffsets, c)
 	fnt.glyphOffsets = glyphOffsets
+	fnt.Head.indexToLocFormat = 1
+	if isShort && c <= 2*0xFFFF {
+		fnt.Head.indexToLocFormat = 0
+	}
 	return nil
 }
 
-func (fnt *Font) writeHHea(w io.Writer) error {
-	tbl := fnt.hhea
+// writeHVhea writes the 'hhea' or 'vhea' table.
+func writeHVhea(w io.Writer, tbl *TableHVhea) error {
 	binarywrite(w, uint16(1))
 	binarywrite(w, uint16(0))
 
@@ -315,6 +474,204 @@ func (fnt *Font) writePrep(w io.Writer) error {
 	return err
 }
 
+func (fnt *Font) writeFpgm(w io.Writer) error {
+	_, err := w.Write(fnt.fpgm)
+	return err
+}
+
+// writeCmap writes a format 4 subtable for the BMP characters,
+// a format 12 subtable if needed, and a format 14 subtable
+// for the variation sequences.
+func (fnt *Font) writeCmap(w io.Writer) error {
+	var (
+		entries  cmap12
+		segments []cmapEntry32
+		isBMP    = true
+	)
+	cmap, encoding, cmapVar := fnt.lazy.cmaps()
+	if cmap != nil {
+		entries = compileCmap12(cmap, func(GID) bool { return true })
+	}
+	for _, entry := range entries {
+		if entry.end > 0xFFFE { // 0xFFFF is reserved for the last segment
+			isBMP = false
+			if entry.start > 0xFFFE {
+				continue
+			}
+			entry.end = 0xFFFE
+		}
+		segments = append(segments, entry)
+	}
+	segments = append(segments, cmapEntry32{start: 0xFFFF, end: 0xFFFF, value: 0})
+	segCount := len(segments)
+	length4 := 16 + 8*segCount
+	if length4 > 0xFFFF { // too many segments
+		isBMP = false
+		segments = nil
+	}
+
+	platformEncoding := PEMicrosoftUnicodeCs
+	if encoding != fonts.EncUnicode {
+		platformEncoding = PEMicrosoftSymbolCs
+	}
+
+	var variations []byte
+	if len(cmapVar) != 0 {
+		variations = compileCmap14(cmapVar)
+	}
+
+	numTables := 0
+	if variations != nil {
+		numTables++
+	}
+	if segments != nil {
+		numTables++
+	}
+	if !isBMP {
+		numTables++
+	}
+	offset := uint32(4 + 8*numTables)
+	binarywrite(w, uint16(0))
+	binarywrite(w, uint16(numTables))
+	// the encoding records are sorted, but the format 14 subtable is written last
+	length12 := uint32(16 + 12*len(entries))
+	if variations != nil {
+		offset14 := offset
+		if segments != nil {
+			offset14 += uint32(length4)
+		}
+		if !isBMP {
+			offset14 += length12
+		}
+		binarywrite(w, []uint16{uint16(PlatformUnicode), uint16(PEUnicodeVariations)})
+		binarywrite(w, offset14)
+	}
+	if segments != nil {
+		binarywrite(w, []uint16{uint16(PlatformMicrosoft), uint16(platformEncoding)})
+		binarywrite(w, offset)
+		offset += uint32(length4)
+	}
+	if !isBMP {
+		binarywrite(w, []uint16{uint16(PlatformMicrosoft), uint16(PEMicrosoftUcs4)})
+		binarywrite(w, offset)
+	}
+
+	if segments != nil {
+		searchRange := 2 << uint(math.Floor(math.Log2(float64(segCount))))
+		binarywrite(w, []uint16{4, uint16(length4), 0, uint16(2 * segCount), uint16(searchRange),
+			uint16(math.Log2(float64(searchRange / 2))), uint16(2*segCount - searchRange)})
+		for _, entry := range segments {
+			binarywrite(w, uint16(entry.end))
+		}
+		binarywrite(w, uint16(0)) // reservedPad
+		for _, entry := range segments {
+			binarywrite(w, uint16(entry.start))
+		}
+		for _, entry := range segments {
+			binarywrite(w, uint16(entry.value-entry.start)) // modulo 0x10000
+		}
+		for range segments {
+			binarywrite(w, uint16(0)) // idRangeOffset
+		}
+	}
+	if !isBMP {
+		binarywrite(w, []uint16{12, 0})
+		binarywrite(w, []uint32{length12, 0, uint32(len(entries))})
+		for _, entry := range entries {
+			binarywrite(w, []uint32{entry.start, entry.end, entry.value})
+		}
+	}
+	if variations != nil {
+		w.Write(variations)
+	}
+	return nil
+}
+
+// compileCmap14 returns a format 14 subtable for `variations`.
+func compileCmap14(variations unicodeVariations) []byte {
+	putUint24 := func(b []byte, v rune) {
+		b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+	}
+
+	headerLength := 10 + 11*len(variations)
+	out := make([]byte, headerLength)
+	binary.BigEndian.PutUint16(out, 14)
+	binary.BigEndian.PutUint32(out[6:], uint32(len(variations)))
+	for i, vs := range variations {
+		record := out[10+11*i:]
+		putUint24(record, vs.varSelector)
+		if len(vs.defaultUVS) != 0 {
+			binary.BigEndian.PutUint32(record[3:], uint32(len(out)))
+			table := make([]byte, 4+4*len(vs.defaultUVS))
+			binary.BigEndian.PutUint32(table, uint32(len(vs.defaultUVS)))
+			for j, r := range vs.defaultUVS {
+				putUint24(table[4+4*j:], r.start)
+				table[4+4*j+3] = r.additionalCount
+			}
+			out = append(out, table...)
+		}
+		if len(vs.nonDefaultUVS) != 0 {
+			binary.BigEndian.PutUint32(out[10+11*i+7:], uint32(len(out)))
+			table := make([]byte, 4+5*len(vs.nonDefaultUVS))
+			binary.BigEndian.PutUint32(table, uint32(len(vs.nonDefaultUVS)))
+			for j, m := range vs.nonDefaultUVS {
+				putUint24(table[4+5*j:], m.unicode)
+				binary.BigEndian.PutUint16(table[4+5*j+3:], m.glyphID)
+			}
+			out = append(out, table...)
+		}
+	}
+	binary.BigEndian.PutUint32(out[2:], uint32(len(out)))
+	return out
+}
+
+func (fnt *Font) writeName(w io.Writer) error {
+	binarywrite(w, uint16(0))
+	binarywrite(w, uint16(len(fnt.Names)))
+	binarywrite(w, uint16(6+12*len(fnt.Names)))
+	offset := 0
+	for _, entry := range fnt.Names {
+		binarywrite(w, []uint16{uint16(entry.PlatformID), uint16(entry.EncodingID), uint16(entry.LanguageID),
+			uint16(entry.NameID), uint16(len(entry.Value)), uint16(offset)})
+		offset += len(entry.Value)
+	}
+	for _, entry := range fnt.Names {
+		if _, err := w.Write(entry.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fnt *Font) writeOS2(w io.Writer) error {
+	tbl := fnt.OS2
+	switch tbl.Version {
+	case 0:
+		return binarywrite(w, tbl.TableOS2Version0)
+	case 1:
+		return binarywrite(w, tbl.TableOS2Version1)
+	case 2, 3, 4: // versions 2 and 3 have the same layout as version 4
+		return binarywrite(w, tbl.TableOS2Version4)
+	default:
+		return binarywrite(w, *tbl)
+	}
+}
+
+// writePost writes a version 3 'post' table, without glyph names.
+func (fnt *Font) writePost(w io.Writer) error {
+	tbl := fnt.post
+	var isFixedPitch uint32
+	if tbl.IsFixedPitch {
+		isFixedPitch = 1
+	}
+	binarywrite(w, uint32(0x30000))
+	binarywrite(w, int32(math.Round(tbl.ItalicAngle*0x10000)))
+	binarywrite(w, tbl.UnderlinePosition)
+	binarywrite(w, tbl.UnderlineThickness)
+	binarywrite(w, isFixedPitch)
+	return binarywrite(w, [4]uint32{}) // memory usage
+}
+
 func (fnt *Font) writeMaxp(w io.Writer) error {
 	tbl := fnt.Maxp
 	tbl.NumGlyphs = uint16(fnt.NumGlyphs)
@@ -361,9 +718,10 @@ func (fnt *Font) writeLoca(w io.Writer) error {
 	return nil
 }
 
-func (fnt *Font) writeHmtx(w io.Writer) error {
+// writeHVmtx writes the 'hmtx' or 'vmtx' table, using
+// only long metrics.
+func (fnt *Font) writeHVmtx(w io.Writer, tbl TableHVmtx) error {
 	var err error
-	tbl := fnt.Hmtx
 	l := GID(fnt.NumGlyphs)
 	for i := GID(0); i < l; i++ {
 		if err = binarywrite(w, uint16(tbl[i].Advance)); err != nil {
@@ -377,6 +735,19 @@ func (fnt *Font) writeHmtx(w io.Writer) error {
 	return nil
 }
 
+// writeVorg writes the origins of the glyphs kept.
+func (fnt *Font) writeVorg(w io.Writer) error {
+	tbl := fnt.vorg
+	var metrics [][2]uint16
+	for _, m := range tbl.metrics {
+		if int(m.glyph) < fnt.NumGlyphs {
+			metrics = append(metrics, [2]uint16{uint16(m.glyph), uint16(m.origin)})
+		}
+	}
+	binarywrite(w, []uint16{1, 0, uint16(tbl.defaultOrigin), uint16(len(metrics))})
+	return binarywrite(w, metrics)
+}
+
 // WriteTable writes the table to w.
 func (fnt *Font) writeTable(w io.Writer, t Tag) error {
 	var err error
@@ -386,25 +757,35 @@ func (fnt *Font) writeTable(w io.Writer, t Tag) error {
 	case tagLoca:
 		err = fnt.writeLoca(w)
 	case tagHhea:
-		err = fnt.writeHHea(w)
+		err = writeHVhea(w, fnt.hhea)
+	case tagVhea:
+		err = writeHVhea(w, fnt.vhea)
 	case tagHead:
 		err = fnt.writeHead(w)
 	case tagMaxp:
 		err = fnt.writeMaxp(w)
 	case tagHmtx:
-		err = fnt.writeHmtx(w)
-	// case tagfpg:
-	// 	err = fnt.writeFpgm(w)
+		err = fnt.writeHVmtx(w, fnt.Hmtx)
+	case tagVmtx:
+		err = fnt.writeHVmtx(w, fnt.vmtx)
+	case tagVorg:
+		err = fnt.writeVorg(w)
+	case tagFpgm:
+		err = fnt.writeFpgm(w)
 	case tagCvt:
 		err = fnt.writeCvt(w)
 	case tagPrep:
 		err = fnt.writePrep(w)
 	case tagGlyf:
 		err = fnt.writeGlyf(w)
-	// case tagPost:
-	// 	err = fnt.writePost(w)
-	// case tagOS2:
-	// 	err = fnt.writeOs2(w)
+	case tagCmap:
+		err = fnt.writeCmap(w)
+	case tagName:
+		err = fnt.writeName(w)
+	case tagPost:
+		err = fnt.writePost(w)
+	case tagOS2:
+		err = fnt.writeOS2(w)
 	default:
 		// fmt.Printf("    skip write table %s\n", tbl)
 	}
@@ -414,107 +795,41 @@ func (fnt *Font) writeTable(w io.Writer, t Tag) error {
 	return nil
 }
 
-func calcChecksum(data []byte) uint32 {
-	sum := uint32(0)
-	c := 0
-	for c < len(data) {
-		sum += uint32(data[c])<<3 + uint32(data[c+1])<<2 + uint32(data[c+2])<<1 + uint32(data[c+3])
-		c += 4
-	}
-	return sum
-}
-
 // WriteSubset writes a valid font to w that is suitable for including in PDF
 func (fnt *Font) WriteSubset(w io.Writer) error {
 	if fnt.cff != nil {
 		return fnt.cff.WriteSubset(w)
 	}
-	var err error
-	var fontfile bytes.Buffer
-	fnt.Head.checkSumAdjustment = 0
 
-	tablesForPDF := []tableOffsetLength{}
-
-	// put only those tables in PDF which are present in the font file
-	for _, tblname := range []Tag{tagCvt, tagGlyf, tagHead, tagHhea, tagHmtx, tagLoca, tagMaxp, tagPrep} {
-		if _, ok := fnt.knowTables[tblname]; ok {
-			tbl := tableOffsetLength{}
-			tbl.tag = tblname
-			tablesForPDF = append(tablesForPDF, tbl)
-		}
-	}
-
-	// tables start at 12 (header) + table toc
-	tableOffset := uint32(12 + 16*len(tablesForPDF))
-	var newTables []tableOffsetLength
-	for _, tbl := range tablesForPDF {
-		var tableData bytes.Buffer
-		err = fnt.writeTable(&tableData, tbl.tag)
-		if err != nil {
-			return err
-		}
-		l := tableData.Len()
-		nt := tableOffsetLength{
-			length: uint32(l),
-			tag:    tbl.tag,
-			offset: tableOffset,
+	// put only those tables in PDF which are present in the font file;
+	// 'glyf' must be written before 'head' and 'loca'
+	var tables []Table
+	for _, tag := range []Tag{tagOS2, tagVorg, tagCmap, tagCvt, tagFpgm, tagGlyf, tagHead, tagHhea, tagHmtx, tagLoca, tagMaxp, tagName, tagPost, tagPrep, tagVhea, tagVmtx} {
+		switch tag {
+		case tagOS2:
+			if fnt.OS2 == nil {
+				continue // unsupported version
+			}
+		case tagVhea, tagVmtx: // written only if valid
+			if fnt.vhea == nil || len(fnt.vmtx) == 0 {
+				continue
+			}
+		case tagVorg:
+			if fnt.vorg == nil {
+				continue
+			}
 		}
-
-		switch l & 3 {
-		case 0:
-			// ok, no alignment
-		case 1:
-			binarywrite(&tableData, uint16(0))
-			binarywrite(&tableData, uint8(0))
-			l += 3
-		case 2:
-			binarywrite(&tableData, uint16(0))
-			l += 2
-		case 3:
-			binarywrite(&tableData, uint8(0))
-			l++
+		if _, ok := fnt.knowTables[tag]; !ok {
+			continue
 		}
-		nt.tabledata = tableData.Bytes()
-		tableOffset += uint32(len(nt.tabledata))
-		nt.checksum = calcChecksum(nt.tabledata)
-		newTables = append(newTables, nt)
-	}
-
-	binarywrite(&fontfile, fnt.Type)
-	cTablesRead := float64(len(newTables))
-	searchRange := (math.Pow(2, math.Floor(math.Log2(cTablesRead))) * 16)
-	entrySelector := math.Floor(math.Log2(cTablesRead))
-	rangeShift := (cTablesRead * 16.0) - searchRange
-
-	binarywrite(&fontfile, uint16(cTablesRead))
-	binarywrite(&fontfile, uint16(searchRange))
-	binarywrite(&fontfile, uint16(entrySelector))
-	binarywrite(&fontfile, uint16(rangeShift))
-
-	checksumAdjustmentOffset := 0
-	for _, tbl := range newTables {
-		binarywrite(&fontfile, []byte(tbl.tag.String()))
-		binarywrite(&fontfile, tbl.checksum)
-		binarywrite(&fontfile, tbl.offset)
-		binarywrite(&fontfile, tbl.length)
-		if tbl.tag == tagHead {
-			checksumAdjustmentOffset = int(tbl.offset) + 8
+		var data bytes.Buffer
+		if err := fnt.writeTable(&data, tag); err != nil {
+			return err
 		}
+		tables = append(tables, Table{Tag: tag, Data: data.Bytes()})
 	}
 
-	for _, tbl := range newTables {
-		binarywrite(&fontfile, tbl.tabledata)
-	}
-
-	b := fontfile.Bytes()
-	checksumFontFile := calcChecksum(b)
-	if checksumAdjustmentOffset > 0 {
-		// only if we write the head table
-		binary.BigEndian.PutUint32(b[checksumAdjustmentOffset:], checksumFontFile-0xB1B0AFBA)
-	}
-	w.Write(b)
-
-	return nil
+	return WriteTables(w, fnt.Type, tables)
 }
 
 // getCharTag returns a string of length 6 based on the characters in code point
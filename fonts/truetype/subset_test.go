@@ -0,0 +1,362 @@
+package truetype
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	hbtestdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+	type1c "github.com/boxesandglue/textlayout/fonts/type1C"
+)
+
+func TestSubset(t *testing.T) {
+	const text = "Hello wörld, ﬁne 𝐀"
+	for _, filename := range []string{
+		"Roboto-BoldItalic.ttf",
+		"DejaVuSerif.ttf",
+		"FreeSerif.ttf", // format 12 cmap
+		"SourceSansVariable-Roman.modcomp.ttf",
+	} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		original, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var gids []GID
+		for _, r := range text {
+			if gid, ok := font.NominalGlyph(r); ok {
+				gids = append(gids, gid)
+			}
+		}
+		if err = font.Subset(gids); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		if err = font.WriteSubset(&out); err != nil {
+			t.Fatal(err)
+		}
+		if sum := calcChecksum(out.Bytes()); sum != 0xB1B0AFBA {
+			t.Fatalf("%s: invalid file checksum %x", filename, sum)
+		}
+
+		subset, err := Parse(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subset.NumGlyphs != font.NumGlyphs || subset.NumGlyphs > original.NumGlyphs {
+			t.Fatalf("%s: unexpected number of glyphs %d", filename, subset.NumGlyphs)
+		}
+
+		keep := map[GID]bool{0: true}
+		for _, gid := range gids {
+			keep[gid] = true
+			original.addComponents(gid, keep)
+		}
+		for gid := GID(0); gid < GID(subset.NumGlyphs); gid++ {
+			if !keep[gid] {
+				if subset.Glyf()[gid].data != nil {
+					t.Fatalf("%s: glyph %d should be empty", filename, gid)
+				}
+				continue
+			}
+			if exp, got := original.HorizontalAdvance(gid), subset.HorizontalAdvance(gid); exp != got {
+				t.Fatalf("%s: glyph %d: expected advance %g, got %g", filename, gid, exp, got)
+			}
+			if exp, got := original.GlyphData(gid, 0, 0), subset.GlyphData(gid, 0, 0); !reflect.DeepEqual(exp, got) {
+				t.Fatalf("%s: glyph %d: expected outline %v, got %v", filename, gid, exp, got)
+			}
+		}
+
+		for _, r := range text {
+			exp, _ := original.NominalGlyph(r)
+			if got, _ := subset.NominalGlyph(r); exp != got {
+				t.Fatalf("%s: rune %c: expected glyph %d, got %d", filename, r, exp, got)
+			}
+		}
+		if got, ok := subset.NominalGlyph('z'); ok {
+			t.Fatalf("%s: unexpected glyph %d for a removed rune", filename, got)
+		}
+
+		if !reflect.DeepEqual(original.Names, subset.Names) {
+			t.Fatalf("%s: unexpected names", filename)
+		}
+		if !reflect.DeepEqual(original.OS2, subset.OS2) {
+			t.Fatalf("%s: expected OS/2 %v, got %v", filename, original.OS2, subset.OS2)
+		}
+		if original.post.ItalicAngle != subset.post.ItalicAngle || original.post.UnderlinePosition != subset.post.UnderlinePosition {
+			t.Fatalf("%s: unexpected post table %v", filename, subset.post)
+		}
+		if !bytes.Equal(original.fpgm, subset.fpgm) || !bytes.Equal(original.prep, subset.prep) || !bytes.Equal(original.cvt, subset.cvt) {
+			t.Fatalf("%s: unexpected hinting tables", filename)
+		}
+	}
+}
+
+func TestSubsetCFF(t *testing.T) {
+	const text = "Hello wörld, ﬁne"
+	files := map[string][]byte{}
+	for _, filename := range []string{
+		"CFFTest.otf",
+		"Raleway-v4020-Regular.otf",
+		"STIX-BoldItalic.otf",
+	} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filename] = file
+	}
+	// CID-keyed font
+	file, err := hbtestdata.Files.ReadFile("harfbuzz_reference/text-rendering-tests/fonts/FDArrayTest257.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["FDArrayTest257.otf"] = file
+
+	for filename, file := range files {
+		font, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		original, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var gids []GID
+		for gid := 1; gid < font.NumGlyphs; gid += 10 {
+			gids = append(gids, GID(gid))
+		}
+		for _, r := range text {
+			if gid, ok := font.NominalGlyph(r); ok {
+				gids = append(gids, gid)
+			}
+		}
+		if err = font.Subset(gids); err != nil {
+			t.Fatalf("%s: %s", filename, err)
+		}
+
+		var out bytes.Buffer
+		if err = font.WriteSubset(&out); err != nil {
+			t.Fatal(err)
+		}
+		pr, err := NewFontParser(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if L := pr.tables[tagCFF].length; out.Len() >= int(L) {
+			t.Fatalf("%s: subset is not smaller (%d >= %d)", filename, out.Len(), L)
+		}
+
+		subset, err := type1c.Parse(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subset.IsCIDFont() != original.cff.IsCIDFont() {
+			t.Fatalf("%s: unexpected CID-keyed font", filename)
+		}
+		keep := map[GID]bool{0: true}
+		numGlyphs := 1
+		for _, gid := range gids {
+			keep[gid] = true
+			if int(gid) >= numGlyphs {
+				numGlyphs = int(gid) + 1
+			}
+		}
+		if subset.NumGlyphs() != numGlyphs {
+			t.Fatalf("%s: unexpected number of glyphs %d", filename, subset.NumGlyphs())
+		}
+		for gid := 0; gid < subset.NumGlyphs(); gid++ {
+			got, _, err := subset.LoadGlyph(GID(gid))
+			if err != nil {
+				t.Fatalf("%s: glyph %d: %s", filename, gid, err)
+			}
+			if !keep[GID(gid)] {
+				if len(got) != 0 {
+					t.Fatalf("%s: glyph %d should be empty", filename, gid)
+				}
+				continue
+			}
+			exp, _, err := original.cff.LoadGlyph(GID(gid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(exp, got) {
+				t.Fatalf("%s: glyph %d: expected outline %v, got %v", filename, gid, exp, got)
+			}
+		}
+	}
+}
+
+func TestSubsetRunes(t *testing.T) {
+	file, err := testdata.Files.ReadFile("Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookups := font.LayoutTables().GSUB.Lookups
+	countSubtables := func(lookups []LookupGPOS) (nb int) {
+		for _, lookup := range lookups {
+			nb += len(lookup.Subtables)
+		}
+		return nb
+	}
+	gposSubtables := countSubtables(font.LayoutTables().GPOS.Lookups)
+
+	if err = font.SubsetRunes([]rune("fi")); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := font.NominalGlyph('f')
+	i, _ := font.NominalGlyph('i')
+
+	// the 'fi' ligature is kept
+	var ligature GID
+	for _, lookup := range lookups {
+		for _, subtable := range lookup.Subtables {
+			data, ok := subtable.Data.(GSUBLigature1)
+			if !ok {
+				continue
+			}
+			if index, ok := subtable.Coverage.Index(f); ok {
+				for _, lig := range data[index] {
+					if lig.Matches([]GID{i}) {
+						ligature = lig.Glyph
+					}
+				}
+			}
+		}
+	}
+	if ligature == 0 {
+		t.Fatal("missing fi ligature")
+	}
+	if font.Glyf()[ligature].data == nil {
+		t.Fatalf("ligature glyph %d should be kept", ligature)
+	}
+
+	// the subtables not applying to the subset are removed
+	keep := map[GID]bool{0: true}
+	for _, gid := range font.subsetCodepoints {
+		keep[gid] = true
+		font.addComponents(gid, keep)
+	}
+	if countSubtables(font.LayoutTables().GPOS.Lookups) >= gposSubtables {
+		t.Fatal("GPOS subtables should be removed")
+	}
+	for _, lookup := range font.LayoutTables().GPOS.Lookups {
+		for _, subtable := range lookup.Subtables {
+			if !coversAny(subtable.Coverage, keep) {
+				t.Fatalf("unused GPOS subtable %v", subtable)
+			}
+		}
+	}
+}
+
+func TestSubsetInvalid(t *testing.T) {
+	file, err := testdata.Files.ReadFile("DejaVuSerif.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = font.Subset([]GID{GID(font.NumGlyphs)}); err == nil {
+		t.Fatal("expected error for invalid glyph index")
+	}
+}
+
+func TestSubsetVariationSelectors(t *testing.T) {
+	font := loadFont(t, "Roboto-BoldItalic.ttf")
+	a, _ := font.NominalGlyph('a')
+	b, _ := font.NominalGlyph('b')
+	cmap, encoding, _ := font.lazy.cmaps()
+	font.lazy.setCmaps(cmap, encoding, unicodeVariations{
+		{varSelector: 0xFE0E, defaultUVS: []unicodeRange{{start: 'a', additionalCount: 1}}},
+		{varSelector: 0xFE0F, nonDefaultUVS: []uvsMapping{{unicode: 'a', glyphID: gid(b)}, {unicode: 'c', glyphID: 3}}},
+	})
+
+	if err := font.Subset([]GID{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := font.WriteSubset(&out); err != nil {
+		t.Fatal(err)
+	}
+	subset, err := Parse(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gid, ok := subset.GlyphVariationIndex('a', 0xFE0E); !ok || gid != a {
+		t.Fatalf("unexpected default variation %d", gid)
+	}
+	if gid, ok := subset.GlyphVariationIndex('a', 0xFE0F); !ok || gid != b {
+		t.Fatalf("unexpected variation %d", gid)
+	}
+	if _, ok := subset.GlyphVariationIndex('c', 0xFE0F); ok {
+		t.Fatal("unexpected variation for a removed glyph")
+	}
+}
+
+func TestSubsetVertical(t *testing.T) {
+	original := loadFont(t, "SourceSansVariable-Roman.modcomp.ttf")
+	font := loadFont(t, "SourceSansVariable-Roman.modcomp.ttf")
+	font.vorg = &tableVorg{defaultOrigin: 880}
+	font.knowTables[tagVorg] = true
+	font.vorg.metrics = append(font.vorg.metrics,
+		struct {
+			glyph  GID
+			origin int16
+		}{1, 900},
+		struct {
+			glyph  GID
+			origin int16
+		}{7, 700})
+
+	if err := font.Subset([]GID{1, 3}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := font.WriteSubset(&out); err != nil {
+		t.Fatal(err)
+	}
+	subset, err := Parse(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subset.vhea == nil || len(subset.vmtx) != subset.NumGlyphs {
+		t.Fatal("missing vertical metrics")
+	}
+	if !reflect.DeepEqual(subset.vhea.Ascent, original.vhea.Ascent) {
+		t.Fatal("unexpected vhea table")
+	}
+	for _, gid := range []GID{0, 1, 3} {
+		if exp, got := original.VerticalAdvance(gid), subset.VerticalAdvance(gid); exp != got {
+			t.Fatalf("glyph %d: expected vertical advance %g, got %g", gid, exp, got)
+		}
+		if exp, got := original.getVerticalSideBearing(gid), subset.getVerticalSideBearing(gid); exp != got {
+			t.Fatalf("glyph %d: expected top side bearing %d, got %d", gid, exp, got)
+		}
+	}
+	if _, y, _ := subset.GlyphVOrigin(1); y != 900 {
+		t.Fatalf("unexpected vertical origin %d", y)
+	}
+	if _, y, _ := subset.GlyphVOrigin(3); y != 880 {
+		t.Fatalf("unexpected vertical origin %d", y)
+	}
+	if len(subset.vorg.metrics) != 1 {
+		t.Fatalf("unexpected VORG metrics %v", subset.vorg.metrics)
+	}
+}
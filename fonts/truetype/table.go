@@ -29,6 +29,10 @@ var (
 	TagSilf = MustNewTag("Silf")
 	// tagPrep
 	tagPrep = MustNewTag("prep")
+	// tagFpgm represents the 'fpgm' table, the Font Program
+	tagFpgm = MustNewTag("fpgm")
+	// tagGasp represents the 'gasp' table, the Grid-fitting And Scan-conversion Procedure
+	tagGasp = MustNewTag("gasp")
 
 	tagCmap = MustNewTag("cmap")
 	tagKern = MustNewTag("kern")
@@ -47,12 +51,15 @@ var (
 	tagBloc = MustNewTag("bloc")
 	tagBdat = MustNewTag("bdat")
 	tagCOLR = MustNewTag("COLR")
+	tagMath = MustNewTag("MATH")
 	tagFvar = MustNewTag("fvar")
 	tagAvar = MustNewTag("avar")
 	tagGvar = MustNewTag("gvar")
 	tagMvar = MustNewTag("MVAR")
 	tagHvar = MustNewTag("HVAR")
 	tagVvar = MustNewTag("VVAR")
+	tagStat = MustNewTag("STAT")
+	tagMeta = MustNewTag("meta")
 
 	tagFeat = MustNewTag("feat")
 	tagMort = MustNewTag("mort")
@@ -75,10 +82,10 @@ var (
 	// SignatureWOFF is the magic number at the start of a WOFF file.
 	SignatureWOFF = MustNewTag("wOFF")
 
-	ttcTag = MustNewTag("ttcf")
+	// SignatureWOFF2 is the magic number at the start of a WOFF2 file.
+	SignatureWOFF2 = MustNewTag("wOF2")
 
-	// // SignatureWOFF2 is the magic number at the start of a WOFF2 file.
-	// SignatureWOFF2 = MustNewTag("wOF2")
+	ttcTag = MustNewTag("ttcf")
 )
 
 // dfontResourceDataOffset is the assumed value of a dfont file's resource data
@@ -247,6 +247,33 @@ func TestVariationSelector(t *testing.T) {
 	if !ok || gid != 2 {
 		t.Fatalf("expected 2, true ; got %d, %v", gid, ok)
 	}
+
+	for _, test := range []struct {
+		r, selector rune
+		gid         GID
+		ok          bool
+	}{
+		{33446, 917761, 2, true},
+		{33446, 917760, 1, true}, // default UVS
+		{8809, 0xFE00, 3, true},
+		{8809, 917760, 0, false},
+		{33446, 0xFE0F, 0, false},
+	} {
+		gid, ok := font.GlyphVariationIndex(test.r, test.selector)
+		if gid != test.gid || ok != test.ok {
+			t.Fatalf("%U %U: expected %d, %v ; got %d, %v", test.r, test.selector, test.gid, test.ok, gid, ok)
+		}
+	}
+
+	// round trip through the subset writer
+	_, _, variations := font.lazy.cmaps()
+	got, err := parseCmapFormat14(compileCmap14(variations), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, variations) {
+		t.Fatalf("expected %v, got %v", variations, got)
+	}
 }
 
 func TestCmap12(t *testing.T) {
@@ -269,3 +296,38 @@ func TestCmap12(t *testing.T) {
 		}
 	}
 }
+
+func TestGlyphRunes(t *testing.T) {
+	font := loadFont(t, "Roboto-BoldItalic.ttf")
+	cmap, _ := font.Cmap()
+
+	for iter := cmap.Iter(); iter.Next(); {
+		r, gid := iter.Char()
+		runes := font.GlyphRunes(gid)
+		found := false
+		for i, other := range runes {
+			if i > 0 && runes[i-1] >= other {
+				t.Fatalf("runes for glyph %d are not sorted: %v", gid, runes)
+			}
+			found = found || other == r
+		}
+		if !found {
+			t.Fatalf("rune %U missing from %v (glyph %d)", r, runes, gid)
+		}
+	}
+
+	// Ohm sign and Greek capital omega
+	omega, _ := font.NominalGlyph(0x2126)
+	if runes := font.GlyphRunes(omega); !reflect.DeepEqual(runes, []rune{0x03A9, 0x2126}) {
+		t.Fatalf("unexpected runes for omega: %v", runes)
+	}
+	if runes := font.GlyphRunes(0); runes != nil {
+		t.Fatalf("unexpected runes for .notdef: %v", runes)
+	}
+
+	// the reverse mapping follows the changes of the cmap
+	font.tables().setCmaps(fonts.CmapSimple{'a': omega}, fonts.EncUnicode, nil)
+	if runes := font.GlyphRunes(omega); !reflect.DeepEqual(runes, []rune{'a'}) {
+		t.Fatalf("unexpected runes after cmap change: %v", runes)
+	}
+}
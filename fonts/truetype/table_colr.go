@@ -0,0 +1,641 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// tableCOLR is the Color table, in version 0 (layers of solid colors)
+// or 1 (graph of paints).
+type tableCOLR struct {
+	baseGlyphs []baseGlyphRecord // version 0, sorted by glyph
+	layers     []layerRecord     // version 0
+
+	baseGlyphPaints []baseGlyphPaintRecord // version 1, sorted by glyph
+	layerList       []paint                // version 1
+	clips           []clipRecord           // version 1, sorted by glyph
+
+	// optional, for variable fonts
+	varIndexMap deltaSetMapping
+	store       VariationStore
+}
+
+type baseGlyphRecord struct {
+	glyph      gid
+	firstLayer uint16
+	numLayers  uint16
+}
+
+type layerRecord struct {
+	glyph        gid
+	paletteIndex uint16
+}
+
+type baseGlyphPaintRecord struct {
+	paint paint
+	glyph gid
+}
+
+type clipRecord struct {
+	box        ClipBox
+	start, end gid
+}
+
+// noVariationIndex is used when a paint has no variation data
+const noVariationIndex = 0xFFFFFFFF
+
+// maxPaintDepth limits the nesting of paints
+const maxPaintDepth = 64
+
+// paint is a node of the COLR version 1 paint graph.
+// The paint formats defined in the specification are mapped to
+// the following types :
+//   - PaintColrLayers (format 1) : paintColrLayers
+//   - PaintSolid, PaintVarSolid (formats 2, 3) : paintSolid
+//   - PaintLinearGradient, PaintVarLinearGradient (formats 4, 5) : paintLinearGradient
+//   - PaintRadialGradient, PaintVarRadialGradient (formats 6, 7) : paintRadialGradient
+//   - PaintSweepGradient, PaintVarSweepGradient (formats 8, 9) : paintSweepGradient
+//   - PaintGlyph (format 10) : paintGlyph
+//   - PaintColrGlyph (format 11) : paintColrGlyph
+//   - PaintTransform, PaintVarTransform (formats 12, 13) : paintTransform
+//   - PaintTranslate, PaintVarTranslate (formats 14, 15) : paintTranslate
+//   - PaintScale and its variants (formats 16 to 23) : paintScale
+//   - PaintRotate and its variants (formats 24 to 27) : paintRotate
+//   - PaintSkew and its variants (formats 28 to 31) : paintSkew
+//   - PaintComposite (format 32) : paintComposite
+//
+// For the variable formats, the fields are stored in the order
+// of their variation indices, starting at `varIndexBase`.
+// The angles are expressed in half turns, so that 1 means 180°.
+type paint interface {
+	isPaint()
+}
+
+func (paintColrLayers) isPaint()     {}
+func (paintSolid) isPaint()          {}
+func (paintLinearGradient) isPaint() {}
+func (paintRadialGradient) isPaint() {}
+func (paintSweepGradient) isPaint()  {}
+func (paintGlyph) isPaint()          {}
+func (paintColrGlyph) isPaint()      {}
+func (paintTransform) isPaint()      {}
+func (paintTranslate) isPaint()      {}
+func (paintScale) isPaint()          {}
+func (paintRotate) isPaint()         {}
+func (paintSkew) isPaint()           {}
+func (paintComposite) isPaint()      {}
+
+type paintColrLayers struct {
+	firstLayerIndex uint32
+	numLayers       uint8
+}
+
+type paintSolid struct {
+	varIndexBase uint32
+	alpha        float32
+	paletteIndex uint16
+}
+
+type colorLine struct {
+	stops  []colorStop
+	extend ColorExtend
+}
+
+type colorStop struct {
+	varIndexBase uint32
+	stopOffset   float32
+	alpha        float32
+	paletteIndex uint16
+}
+
+type paintLinearGradient struct {
+	colorLine              colorLine
+	varIndexBase           uint32
+	x0, y0, x1, y1, x2, y2 float32
+}
+
+type paintRadialGradient struct {
+	colorLine              colorLine
+	varIndexBase           uint32
+	x0, y0, r0, x1, y1, r1 float32
+}
+
+type paintSweepGradient struct {
+	colorLine            colorLine
+	varIndexBase         uint32
+	centerX, centerY     float32
+	startAngle, endAngle float32
+}
+
+type paintGlyph struct {
+	paint paint
+	glyph gid
+}
+
+type paintColrGlyph struct {
+	glyph gid
+}
+
+type paintTransform struct {
+	paint        paint
+	varIndexBase uint32
+	transform    Affine2x3
+}
+
+type paintTranslate struct {
+	paint        paint
+	varIndexBase uint32
+	dx, dy       float32
+}
+
+type paintScale struct {
+	paint            paint
+	varIndexBase     uint32
+	scaleX, scaleY   float32 // equal for uniform scales
+	centerX, centerY float32
+	uniform          bool
+	aroundCenter     bool
+}
+
+type paintRotate struct {
+	paint            paint
+	varIndexBase     uint32
+	angle            float32
+	centerX, centerY float32
+	aroundCenter     bool
+}
+
+type paintSkew struct {
+	paint                  paint
+	varIndexBase           uint32
+	xSkewAngle, ySkewAngle float32
+	centerX, centerY       float32
+	aroundCenter           bool
+}
+
+type paintComposite struct {
+	source, backdrop paint
+	mode             CompositeMode
+}
+
+// ColorExtend specifies how a gradient is extended
+// outside of its color line.
+type ColorExtend uint8
+
+const (
+	ExtendPad     ColorExtend = iota // use the color of the nearest stop
+	ExtendRepeat                     // repeat the color line
+	ExtendReflect                    // repeat the color line, alternately reversed
+)
+
+// CompositeMode specifies how a layer is composed with the content below it.
+// The first modes are the Porter-Duff operators, the others are the
+// separable and non-separable blend modes defined in the W3C Compositing and Blending
+// specification.
+type CompositeMode uint8
+
+const (
+	CompositeClear CompositeMode = iota
+	CompositeSrc
+	CompositeDest
+	CompositeSrcOver
+	CompositeDestOver
+	CompositeSrcIn
+	CompositeDestIn
+	CompositeSrcOut
+	CompositeDestOut
+	CompositeSrcAtop
+	CompositeDestAtop
+	CompositeXor
+	CompositePlus
+	CompositeScreen
+	CompositeOverlay
+	CompositeDarken
+	CompositeLighten
+	CompositeColorDodge
+	CompositeColorBurn
+	CompositeHardLight
+	CompositeSoftLight
+	CompositeDifference
+	CompositeExclusion
+	CompositeMultiply
+	CompositeHSLHue
+	CompositeHSLSaturation
+	CompositeHSLColor
+	CompositeHSLLuminosity
+)
+
+// Affine2x3 is an affine transformation, mapping (x, y) to
+// (XX*x + XY*y + DX, YX*x + YY*y + DY).
+type Affine2x3 struct {
+	XX, YX, XY, YY, DX, DY float32
+}
+
+// ClipBox is a rectangle restricting the drawing of a color glyph,
+// expressed in font units.
+type ClipBox struct {
+	XMin, YMin, XMax, YMax float32
+
+	varIndexBase uint32
+}
+
+func parseTableCOLR(data []byte, axisCount int) (out tableCOLR, err error) {
+	if len(data) < 14 {
+		return out, errors.New("invalid 'COLR' table (EOF)")
+	}
+	version := binary.BigEndian.Uint16(data)
+	numBaseGlyphRecords := int(binary.BigEndian.Uint16(data[2:]))
+	baseGlyphRecordsOffset := int(binary.BigEndian.Uint32(data[4:]))
+	layerRecordsOffset := int(binary.BigEndian.Uint32(data[8:]))
+	numLayerRecords := int(binary.BigEndian.Uint16(data[12:]))
+
+	if len(data) < baseGlyphRecordsOffset+6*numBaseGlyphRecords || len(data) < layerRecordsOffset+4*numLayerRecords {
+		return out, errors.New("invalid 'COLR' table (EOF)")
+	}
+	out.baseGlyphs = make([]baseGlyphRecord, numBaseGlyphRecords)
+	for i := range out.baseGlyphs {
+		record := data[baseGlyphRecordsOffset+6*i:]
+		out.baseGlyphs[i] = baseGlyphRecord{
+			glyph:      binary.BigEndian.Uint16(record),
+			firstLayer: binary.BigEndian.Uint16(record[2:]),
+			numLayers:  binary.BigEndian.Uint16(record[4:]),
+		}
+		if int(out.baseGlyphs[i].firstLayer)+int(out.baseGlyphs[i].numLayers) > numLayerRecords {
+			return out, errors.New("invalid 'COLR' table (layer index out of range)")
+		}
+	}
+	sort.SliceStable(out.baseGlyphs, func(i, j int) bool { return out.baseGlyphs[i].glyph < out.baseGlyphs[j].glyph })
+	out.layers = make([]layerRecord, numLayerRecords)
+	for i := range out.layers {
+		record := data[layerRecordsOffset+4*i:]
+		out.layers[i] = layerRecord{glyph: binary.BigEndian.Uint16(record), paletteIndex: binary.BigEndian.Uint16(record[2:])}
+	}
+
+	if version == 0 {
+		return out, nil
+	}
+
+	if len(data) < 34 {
+		return out, errors.New("invalid 'COLR' table (EOF)")
+	}
+	baseGlyphListOffset := binary.BigEndian.Uint32(data[14:])
+	layerListOffset := binary.BigEndian.Uint32(data[18:])
+	clipListOffset := binary.BigEndian.Uint32(data[22:])
+	varIndexMapOffset := binary.BigEndian.Uint32(data[26:])
+	itemVariationStoreOffset := binary.BigEndian.Uint32(data[30:])
+
+	pr := colrParser{data: data, paints: make(map[uint32]paint)}
+	if baseGlyphListOffset != 0 {
+		out.baseGlyphPaints, err = pr.parseBaseGlyphList(baseGlyphListOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if layerListOffset != 0 {
+		out.layerList, err = pr.parseLayerList(layerListOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if clipListOffset != 0 {
+		out.clips, err = parseClipList(data, clipListOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if varIndexMapOffset != 0 {
+		out.varIndexMap, err = parseDeltaSetMapping(data, varIndexMapOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if itemVariationStoreOffset != 0 {
+		out.store, err = parseVariationStore(data, itemVariationStoreOffset, axisCount)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// colrParser resolves the paint offsets, parsing each paint only once,
+// so that the shared subgraphs are not duplicated.
+type colrParser struct {
+	data   []byte
+	paints map[uint32]paint // by offset in data
+}
+
+func (pr *colrParser) parseBaseGlyphList(offset uint32) ([]baseGlyphPaintRecord, error) {
+	if len(pr.data) < int(offset)+4 {
+		return nil, errors.New("invalid 'COLR' base glyph list (EOF)")
+	}
+	count := int(binary.BigEndian.Uint32(pr.data[offset:]))
+	if len(pr.data) < int(offset)+4+6*count {
+		return nil, errors.New("invalid 'COLR' base glyph list (EOF)")
+	}
+	out := make([]baseGlyphPaintRecord, count)
+	for i := range out {
+		record := pr.data[int(offset)+4+6*i:]
+		out[i].glyph = binary.BigEndian.Uint16(record)
+		paintOffset := binary.BigEndian.Uint32(record[2:])
+		var err error
+		out[i].paint, err = pr.parsePaint(offset, paintOffset, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].glyph < out[j].glyph })
+	return out, nil
+}
+
+func (pr *colrParser) parseLayerList(offset uint32) ([]paint, error) {
+	if len(pr.data) < int(offset)+4 {
+		return nil, errors.New("invalid 'COLR' layer list (EOF)")
+	}
+	count := int(binary.BigEndian.Uint32(pr.data[offset:]))
+	if len(pr.data) < int(offset)+4+4*count {
+		return nil, errors.New("invalid 'COLR' layer list (EOF)")
+	}
+	out := make([]paint, count)
+	for i := range out {
+		paintOffset := binary.BigEndian.Uint32(pr.data[int(offset)+4+4*i:])
+		var err error
+		out[i], err = pr.parsePaint(offset, paintOffset, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// paintSizes stores the minimum size of each paint format, indexed by format
+var paintSizes = [...]int{0, 6, 5, 9, 16, 20, 16, 20, 12, 16, 6, 3, 7, 7, 8, 12, 8, 12, 12, 16, 6, 10, 10, 14, 6, 10, 10, 14, 8, 12, 12, 16, 8}
+
+func readF2dot14(b []byte) float32 { return fixed214ToFloat(binary.BigEndian.Uint16(b)) }
+
+func readFWord(b []byte) float32 { return float32(int16(binary.BigEndian.Uint16(b))) }
+
+// parsePaint parses the paint at `parent` + `offset`, which must not be null.
+func (pr *colrParser) parsePaint(parent, offset uint32, depth int) (paint, error) {
+	if offset == 0 {
+		return nil, errors.New("invalid 'COLR' table (null paint offset)")
+	}
+	if depth > maxPaintDepth {
+		return nil, errors.New("invalid 'COLR' table (paint graph too deep)")
+	}
+	offset += parent
+	if p, ok := pr.paints[offset]; ok {
+		return p, nil
+	}
+	if len(pr.data) <= int(offset) {
+		return nil, errors.New("invalid 'COLR' paint (EOF)")
+	}
+	data := pr.data[offset:]
+	format := data[0]
+	if format == 0 || int(format) >= len(paintSizes) {
+		return nil, fmt.Errorf("invalid 'COLR' paint format %d", format)
+	}
+	if len(data) < paintSizes[format] {
+		return nil, errors.New("invalid 'COLR' paint (EOF)")
+	}
+
+	isVar := format%2 == 1 // for formats 3 to 31
+	varIndexBase := func(pos int) uint32 {
+		if !isVar {
+			return noVariationIndex
+		}
+		return binary.BigEndian.Uint32(data[pos:])
+	}
+	child := func() (paint, error) {
+		return pr.parsePaint(offset, uint32(parseUint24(data[1:])), depth+1)
+	}
+
+	var (
+		out paint
+		err error
+	)
+	switch format {
+	case 1:
+		out = paintColrLayers{numLayers: data[1], firstLayerIndex: binary.BigEndian.Uint32(data[2:])}
+	case 2, 3:
+		out = paintSolid{paletteIndex: binary.BigEndian.Uint16(data[1:]), alpha: readF2dot14(data[3:]), varIndexBase: varIndexBase(5)}
+	case 4, 5:
+		p := paintLinearGradient{
+			x0: readFWord(data[4:]), y0: readFWord(data[6:]),
+			x1: readFWord(data[8:]), y1: readFWord(data[10:]),
+			x2: readFWord(data[12:]), y2: readFWord(data[14:]),
+			varIndexBase: varIndexBase(16),
+		}
+		p.colorLine, err = pr.parseColorLine(offset+uint32(parseUint24(data[1:])), isVar)
+		out = p
+	case 6, 7:
+		p := paintRadialGradient{
+			x0: readFWord(data[4:]), y0: readFWord(data[6:]), r0: float32(binary.BigEndian.Uint16(data[8:])),
+			x1: readFWord(data[10:]), y1: readFWord(data[12:]), r1: float32(binary.BigEndian.Uint16(data[14:])),
+			varIndexBase: varIndexBase(16),
+		}
+		p.colorLine, err = pr.parseColorLine(offset+uint32(parseUint24(data[1:])), isVar)
+		out = p
+	case 8, 9:
+		p := paintSweepGradient{
+			centerX: readFWord(data[4:]), centerY: readFWord(data[6:]),
+			startAngle: readF2dot14(data[8:]), endAngle: readF2dot14(data[10:]),
+			varIndexBase: varIndexBase(12),
+		}
+		p.colorLine, err = pr.parseColorLine(offset+uint32(parseUint24(data[1:])), isVar)
+		out = p
+	case 10:
+		p := paintGlyph{glyph: binary.BigEndian.Uint16(data[4:])}
+		p.paint, err = child()
+		out = p
+	case 11:
+		out = paintColrGlyph{glyph: binary.BigEndian.Uint16(data[1:])}
+	case 12, 13:
+		p := paintTransform{}
+		p.transform, p.varIndexBase, err = parseAffine2x3(pr.data, offset+uint32(parseUint24(data[4:])), format == 13)
+		if err != nil {
+			return nil, err
+		}
+		p.paint, err = child()
+		out = p
+	case 14, 15:
+		p := paintTranslate{dx: readFWord(data[4:]), dy: readFWord(data[6:]), varIndexBase: varIndexBase(8)}
+		p.paint, err = child()
+		out = p
+	case 16, 17, 18, 19:
+		p := paintScale{scaleX: readF2dot14(data[4:]), scaleY: readF2dot14(data[6:]), varIndexBase: varIndexBase(8)}
+		if format >= 18 {
+			p.aroundCenter = true
+			p.centerX, p.centerY = readFWord(data[8:]), readFWord(data[10:])
+			p.varIndexBase = varIndexBase(12)
+		}
+		p.paint, err = child()
+		out = p
+	case 20, 21, 22, 23:
+		p := paintScale{uniform: true, scaleX: readF2dot14(data[4:]), varIndexBase: varIndexBase(6)}
+		p.scaleY = p.scaleX
+		if format >= 22 {
+			p.aroundCenter = true
+			p.centerX, p.centerY = readFWord(data[6:]), readFWord(data[8:])
+			p.varIndexBase = varIndexBase(10)
+		}
+		p.paint, err = child()
+		out = p
+	case 24, 25, 26, 27:
+		p := paintRotate{angle: readF2dot14(data[4:]), varIndexBase: varIndexBase(6)}
+		if format >= 26 {
+			p.aroundCenter = true
+			p.centerX, p.centerY = readFWord(data[6:]), readFWord(data[8:])
+			p.varIndexBase = varIndexBase(10)
+		}
+		p.paint, err = child()
+		out = p
+	case 28, 29, 30, 31:
+		p := paintSkew{xSkewAngle: readF2dot14(data[4:]), ySkewAngle: readF2dot14(data[6:]), varIndexBase: varIndexBase(8)}
+		if format >= 30 {
+			p.aroundCenter = true
+			p.centerX, p.centerY = readFWord(data[8:]), readFWord(data[10:])
+			p.varIndexBase = varIndexBase(12)
+		}
+		p.paint, err = child()
+		out = p
+	case 32:
+		p := paintComposite{mode: CompositeMode(data[4])}
+		if p.mode > CompositeHSLLuminosity {
+			return nil, fmt.Errorf("invalid 'COLR' composite mode %d", p.mode)
+		}
+		p.source, err = child()
+		if err != nil {
+			return nil, err
+		}
+		p.backdrop, err = pr.parsePaint(offset, uint32(parseUint24(data[5:])), depth+1)
+		out = p
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pr.paints[offset] = out
+	return out, nil
+}
+
+func (pr *colrParser) parseColorLine(offset uint32, isVar bool) (out colorLine, err error) {
+	if len(pr.data) < int(offset)+3 {
+		return out, errors.New("invalid 'COLR' color line (EOF)")
+	}
+	data := pr.data[offset:]
+	out.extend = ColorExtend(data[0])
+	if out.extend > ExtendReflect { // unknown values must be treated as pad
+		out.extend = ExtendPad
+	}
+	count := int(binary.BigEndian.Uint16(data[1:]))
+	stopSize := 6
+	if isVar {
+		stopSize = 10
+	}
+	if len(data) < 3+stopSize*count {
+		return out, errors.New("invalid 'COLR' color line (EOF)")
+	}
+	out.stops = make([]colorStop, count)
+	for i := range out.stops {
+		stop := data[3+stopSize*i:]
+		out.stops[i] = colorStop{
+			stopOffset:   readF2dot14(stop),
+			paletteIndex: binary.BigEndian.Uint16(stop[2:]),
+			alpha:        readF2dot14(stop[4:]),
+			varIndexBase: noVariationIndex,
+		}
+		if isVar {
+			out.stops[i].varIndexBase = binary.BigEndian.Uint32(stop[6:])
+		}
+	}
+	return out, nil
+}
+
+func parseAffine2x3(data []byte, offset uint32, isVar bool) (out Affine2x3, varIndexBase uint32, err error) {
+	size := 24
+	if isVar {
+		size = 28
+	}
+	if len(data) < int(offset)+size {
+		return out, 0, errors.New("invalid 'COLR' transform (EOF)")
+	}
+	data = data[offset:]
+	values := parseUint32s(data, 6)
+	out = Affine2x3{
+		XX: fixed1616ToFloat(values[0]), YX: fixed1616ToFloat(values[1]),
+		XY: fixed1616ToFloat(values[2]), YY: fixed1616ToFloat(values[3]),
+		DX: fixed1616ToFloat(values[4]), DY: fixed1616ToFloat(values[5]),
+	}
+	varIndexBase = noVariationIndex
+	if isVar {
+		varIndexBase = binary.BigEndian.Uint32(data[24:])
+	}
+	return out, varIndexBase, nil
+}
+
+func parseClipList(data []byte, offset uint32) ([]clipRecord, error) {
+	if len(data) < int(offset)+5 {
+		return nil, errors.New("invalid 'COLR' clip list (EOF)")
+	}
+	clipList := data[offset:]
+	// format is ignored
+	count := int(binary.BigEndian.Uint32(clipList[1:]))
+	if len(clipList) < 5+7*count {
+		return nil, errors.New("invalid 'COLR' clip list (EOF)")
+	}
+	out := make([]clipRecord, count)
+	for i := range out {
+		record := clipList[5+7*i:]
+		out[i].start = binary.BigEndian.Uint16(record)
+		out[i].end = binary.BigEndian.Uint16(record[2:])
+		boxOffset := int(offset) + int(parseUint24(record[4:]))
+		if len(data) < boxOffset+9 {
+			return nil, errors.New("invalid 'COLR' clip box (EOF)")
+		}
+		box := data[boxOffset:]
+		out[i].box = ClipBox{
+			XMin: readFWord(box[1:]), YMin: readFWord(box[3:]),
+			XMax: readFWord(box[5:]), YMax: readFWord(box[7:]),
+			varIndexBase: noVariationIndex,
+		}
+		if box[0] == 2 {
+			if len(box) < 13 {
+				return nil, errors.New("invalid 'COLR' clip box (EOF)")
+			}
+			out[i].box.varIndexBase = binary.BigEndian.Uint32(box[9:])
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].start < out[j].start })
+	return out, nil
+}
+
+// baseGlyph returns the version 0 record for `glyph`
+func (t *tableCOLR) baseGlyph(glyph GID) (baseGlyphRecord, bool) {
+	i := sort.Search(len(t.baseGlyphs), func(i int) bool { return GID(t.baseGlyphs[i].glyph) >= glyph })
+	if i < len(t.baseGlyphs) && GID(t.baseGlyphs[i].glyph) == glyph {
+		return t.baseGlyphs[i], true
+	}
+	return baseGlyphRecord{}, false
+}
+
+// baseGlyphPaint returns the version 1 paint for `glyph`
+func (t *tableCOLR) baseGlyphPaint(glyph GID) (paint, bool) {
+	i := sort.Search(len(t.baseGlyphPaints), func(i int) bool { return GID(t.baseGlyphPaints[i].glyph) >= glyph })
+	if i < len(t.baseGlyphPaints) && GID(t.baseGlyphPaints[i].glyph) == glyph {
+		return t.baseGlyphPaints[i].paint, true
+	}
+	return nil, false
+}
+
+// clipBox returns the clip box of `glyph`, if any
+func (t *tableCOLR) clipBox(glyph GID) (ClipBox, bool) {
+	// the ranges should not overlap
+	i := sort.Search(len(t.clips), func(i int) bool { return GID(t.clips[i].end) >= glyph })
+	if i < len(t.clips) && GID(t.clips[i].start) <= glyph && glyph <= GID(t.clips[i].end) {
+		return t.clips[i].box, true
+	}
+	return ClipBox{}, false
+}
@@ -0,0 +1,259 @@
+package truetype
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+)
+
+// COLR version 1 table, with the version 0 records of the test font below,
+// and base glyphs using every paint format :
+//   - 1 : layers with solid, linear and radial gradient fills
+//   - 2 : sweep gradient inside a transform
+//   - 3 : translate and scales
+//   - 4 : rotations and skews
+//   - 5 : composite referencing glyph 4
+//   - 6 : invalid, references itself
+//   - 7 : variable translate and solid fill, with a variable clip box
+var colrV1 = deHexStr(
+	"000100010000002200000028000300000034000000620000007200000000000001e000080000000300090000000a0007" +
+		"000b000e000000070001000000cc0002000000d200030000011200040000014900050000017800060000018e00070000" +
+		"01910000000300000049000000540000007901000000030001000200001a000400040000230007000700002c01000000" +
+		"00006400c801fff6ffec001e0028020000000000320032000000030a000006000902000320000a000006000a04000010" +
+		"0000000000640000000000640100020000000140004000000230000a000006000b06000010000a0014001e0028003200" +
+		"3c0200021000000440000000000540000103000000000a00000600090c0000070000220800000c0032003c1000600000" +
+		"00020000000640004000ffff40000001000000008000ffffc00000020000000a0000ffec00000e000008000afffb1000" +
+		"0008700020001200000c60003000006400c81400000620001600000a5000000a00140a000006000a0200074000180000" +
+		"0620001a00000af000001e00281c0000080800fc001e00000c10000000000500060a000006000b020008400020000008" +
+		"170000130a000006000902000140000b00040b00060f00000c00050006000000020a0000060009030002200000000000" +
+		"00010000000c000100000016000100010000400040000008000100010000e0000000000a00140001000200030004")
+
+// recordingPainter stores the painting operations as text
+type recordingPainter []string
+
+func (rp *recordingPainter) add(format string, args ...interface{}) {
+	*rp = append(*rp, fmt.Sprintf(format, args...))
+}
+
+func (rp *recordingPainter) PushTransform(m Affine2x3) {
+	rp.add("transform %.4f %.4f %.4f %.4f %.4f %.4f", m.XX, m.YX, m.XY, m.YY, m.DX, m.DY)
+}
+func (rp *recordingPainter) PopTransform()           { rp.add("pop transform") }
+func (rp *recordingPainter) PushClipGlyph(glyph GID) { rp.add("clip glyph %d", glyph) }
+func (rp *recordingPainter) PushClipBox(box ClipBox) {
+	rp.add("clip box %g %g %g %g", box.XMin, box.YMin, box.XMax, box.YMax)
+}
+func (rp *recordingPainter) PopClip()                     { rp.add("pop clip") }
+func (rp *recordingPainter) PushLayer(mode CompositeMode) { rp.add("layer %d", mode) }
+func (rp *recordingPainter) PopLayer()                    { rp.add("pop layer") }
+
+func formatColor(c color.NRGBA) string { return fmt.Sprintf("%d,%d,%d,%d", c.R, c.G, c.B, c.A) }
+
+func formatStops(stops []ColorStop, extend ColorExtend) string {
+	out := fmt.Sprintf("ext=%d", extend)
+	for _, stop := range stops {
+		out += fmt.Sprintf(" %g:%s", stop.Offset, formatColor(stop.Color))
+	}
+	return out
+}
+
+func (rp *recordingPainter) Fill(brush Brush) {
+	switch b := brush.(type) {
+	case SolidBrush:
+		rp.add("solid %s", formatColor(b.Color))
+	case LinearGradientBrush:
+		rp.add("linear %g %g %g %g %g %g %s", b.P0[0], b.P0[1], b.P1[0], b.P1[1], b.P2[0], b.P2[1], formatStops(b.Stops, b.Extend))
+	case RadialGradientBrush:
+		rp.add("radial %g %g %g %g %g %g %s", b.C0[0], b.C0[1], b.R0, b.C1[0], b.C1[1], b.R1, formatStops(b.Stops, b.Extend))
+	case SweepGradientBrush:
+		rp.add("sweep %g %g %g %g %s", b.Center[0], b.Center[1], b.StartAngle, b.EndAngle, formatStops(b.Stops, b.Extend))
+	}
+}
+
+func loadColrFont(t *testing.T) *Font {
+	file, err := testdata.Files.ReadFile("harfbuzz_reference/in-house/fonts/53374c7ca3657be37efde7ed02ae34229a56ae1f.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+func TestPaintGlyphV0(t *testing.T) {
+	font := loadColrFont(t)
+
+	palettes := font.Palettes()
+	if len(palettes) != 2 || len(palettes[0]) != 69 {
+		t.Fatalf("unexpected palettes %v", palettes)
+	}
+	if !font.IsColorGlyph(8) || font.IsColorGlyph(9) {
+		t.Fatal("invalid color glyphs")
+	}
+
+	for i, palette := range palettes {
+		var rp recordingPainter
+		if err := font.PaintGlyph(8, i, color.NRGBA{}, &rp); err != nil {
+			t.Fatal(err)
+		}
+		expected := recordingPainter{
+			"clip glyph 9", "solid " + formatColor(palette[0]), "pop clip",
+			"clip glyph 10", "solid " + formatColor(palette[7]), "pop clip",
+			"clip glyph 11", "solid " + formatColor(palette[14]), "pop clip",
+		}
+		if !reflect.DeepEqual(rp, expected) {
+			t.Fatalf("expected %v, got %v", expected, rp)
+		}
+	}
+
+	if err := font.PaintGlyph(9, 0, color.NRGBA{}, new(recordingPainter)); err == nil {
+		t.Fatal("expected error for glyph without color description")
+	}
+}
+
+func TestPaintGlyphV1(t *testing.T) {
+	font := loadColrFont(t)
+	var err error
+	font.colr, err = parseTableCOLR(colrV1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// use the palette index as red channel to identify colors
+	palette := make([]color.NRGBA, 10)
+	for i := range palette {
+		palette[i] = color.NRGBA{R: uint8(i), A: 255}
+	}
+	font.cpal = tableCPAL{palettes: [][]color.NRGBA{palette}}
+	foreground := color.NRGBA{B: 255, A: 255}
+
+	for _, test := range []struct {
+		glyph    GID
+		coords   []float32
+		expected string
+	}{
+		{
+			1, nil, `
+clip box 0 0 100 200
+clip glyph 9
+solid 3,0,0,128
+pop clip
+clip glyph 10
+linear 0 0 100 0 0 100 ext=1 0:1,0,0,255 1:2,0,0,191
+pop clip
+clip glyph 11
+radial 10 20 30 40 50 60 ext=2 0:5,0,0,255 0.25:4,0,0,255
+pop clip
+pop clip`,
+		},
+		{
+			2, nil, `
+clip box 0 0 100 200
+clip glyph 9
+transform 1.0000 0.5000 -0.2500 2.0000 10.0000 -20.0000
+sweep 50 60 45 270 ext=0 0:6,0,0,255 1:0,0,255,255
+pop transform
+pop clip
+pop clip`,
+		},
+		{
+			3, nil, `
+transform 1.0000 0.0000 0.0000 1.0000 10.0000 -5.0000
+transform 1.7500 0.0000 0.0000 0.5000 0.0000 0.0000
+transform 1.5000 0.0000 0.0000 0.7500 -50.0000 50.0000
+transform 0.5000 0.0000 0.0000 0.5000 0.0000 0.0000
+transform 1.2500 0.0000 0.0000 1.2500 -2.5000 -5.0000
+clip glyph 10
+solid 7,0,0,255
+pop clip
+pop transform
+pop transform
+pop transform
+pop transform
+pop transform`,
+		},
+		{
+			4, nil, `
+clip box -10 -20 30 40
+transform 0.0000 1.0000 -1.0000 0.0000 0.0000 0.0000
+transform 0.7071 -0.7071 0.7071 0.7071 -19.4975 32.9289
+transform 1.0000 -0.1989 -0.4142 1.0000 0.0000 0.0000
+transform 1.0000 0.0000 -1.0000 1.0000 6.0000 0.0000
+clip glyph 11
+solid 8,0,0,255
+pop clip
+pop transform
+pop transform
+pop transform
+pop transform
+pop clip`,
+		},
+		{
+			5, nil, `
+layer 3
+clip box -10 -20 30 40
+transform 0.0000 1.0000 -1.0000 0.0000 0.0000 0.0000
+transform 0.7071 -0.7071 0.7071 0.7071 -19.4975 32.9289
+transform 1.0000 -0.1989 -0.4142 1.0000 0.0000 0.0000
+transform 1.0000 0.0000 -1.0000 1.0000 6.0000 0.0000
+clip glyph 11
+solid 8,0,0,255
+pop clip
+pop transform
+pop transform
+pop transform
+pop transform
+pop clip
+layer 23
+clip glyph 9
+solid 1,0,0,255
+pop clip
+pop layer
+pop layer`,
+		},
+		{
+			7, nil, `
+clip box 0 0 50 50
+transform 1.0000 0.0000 0.0000 1.0000 5.0000 6.0000
+clip glyph 9
+solid 2,0,0,128
+pop clip
+pop transform
+pop clip`,
+		},
+		{
+			7, []float32{0.5}, `
+clip box 10 0.5 51 51.5
+transform 1.0000 0.0000 0.0000 1.0000 10.0000 16.0000
+clip glyph 9
+solid 2,0,0,64
+pop clip
+pop transform
+pop clip`,
+		},
+	} {
+		var rp recordingPainter
+		font.varCoords = test.coords
+		if err := font.PaintGlyph(test.glyph, 0, foreground, &rp); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(rp, "\n"); got != strings.TrimSpace(test.expected) {
+			t.Errorf("glyph %d: expected\n%s\ngot\n%s", test.glyph, test.expected, got)
+		}
+	}
+
+	// version 0 records are still available
+	if !font.IsColorGlyph(8) {
+		t.Fatal("missing version 0 glyph")
+	}
+
+	if err = font.PaintGlyph(6, 0, foreground, new(recordingPainter)); err == nil {
+		t.Fatal("expected error for cyclic glyph")
+	}
+}
@@ -0,0 +1,119 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"image/color"
+)
+
+var tagCPAL = MustNewTag("CPAL")
+
+// PaletteFlags indicates the intended usage of a color palette.
+type PaletteFlags uint32
+
+const (
+	// PaletteUsableWithLightBackground indicates that the palette is
+	// appropriate when displaying the text on a light background.
+	PaletteUsableWithLightBackground PaletteFlags = 1 << iota
+	// PaletteUsableWithDarkBackground indicates that the palette is
+	// appropriate when displaying the text on a dark background.
+	PaletteUsableWithDarkBackground
+)
+
+// noPaletteLabel is used in the 'CPAL' table for missing labels.
+const noPaletteLabel NameID = 0xFFFF
+
+// tableCPAL stores the color palettes, each one with
+// the same number of entries, and the optional
+// metadata added by version 1.
+type tableCPAL struct {
+	palettes    [][]color.NRGBA
+	flags       []PaletteFlags // empty or with length len(palettes)
+	labels      []NameID       // empty or with length len(palettes)
+	entryLabels []NameID       // empty or with length numPaletteEntries
+}
+
+func parseTableCPAL(data []byte) (out tableCPAL, err error) {
+	if len(data) < 12 {
+		return out, errors.New("invalid 'CPAL' table (EOF)")
+	}
+	version := binary.BigEndian.Uint16(data)
+	numPaletteEntries := int(binary.BigEndian.Uint16(data[2:]))
+	numPalettes := int(binary.BigEndian.Uint16(data[4:]))
+	numColorRecords := int(binary.BigEndian.Uint16(data[6:]))
+	colorRecordsOffset := int(binary.BigEndian.Uint32(data[8:]))
+
+	indices, err := parseUint16s(data[12:], numPalettes)
+	if err != nil {
+		return out, errors.New("invalid 'CPAL' table (EOF)")
+	}
+	if len(data) < colorRecordsOffset+4*numColorRecords {
+		return out, errors.New("invalid 'CPAL' table (EOF)")
+	}
+	records := data[colorRecordsOffset:]
+
+	out.palettes = make([][]color.NRGBA, numPalettes)
+	for i, first := range indices {
+		if int(first)+numPaletteEntries > numColorRecords {
+			return out, errors.New("invalid 'CPAL' table (color record index out of range)")
+		}
+		palette := make([]color.NRGBA, numPaletteEntries)
+		for j := range palette {
+			record := records[4*(int(first)+j):]
+			// records are stored as BGRA
+			palette[j] = color.NRGBA{B: record[0], G: record[1], R: record[2], A: record[3]}
+		}
+		out.palettes[i] = palette
+	}
+
+	if version == 0 {
+		return out, nil
+	}
+
+	// version 1 metadata: each array is optional (zero offset)
+	header := data[12+2*numPalettes:]
+	if len(header) < 12 {
+		return out, errors.New("invalid 'CPAL' table (EOF)")
+	}
+	typesOffset := int(binary.BigEndian.Uint32(header))
+	labelsOffset := int(binary.BigEndian.Uint32(header[4:]))
+	entryLabelsOffset := int(binary.BigEndian.Uint32(header[8:]))
+
+	if typesOffset != 0 {
+		if len(data) < typesOffset+4*numPalettes {
+			return out, errors.New("invalid 'CPAL' table (EOF)")
+		}
+		out.flags = make([]PaletteFlags, numPalettes)
+		for i := range out.flags {
+			out.flags[i] = PaletteFlags(binary.BigEndian.Uint32(data[typesOffset+4*i:]))
+		}
+	}
+	if labelsOffset != 0 {
+		if len(data) < labelsOffset {
+			return out, errors.New("invalid 'CPAL' table (EOF)")
+		}
+		labels, err := parseUint16s(data[labelsOffset:], numPalettes)
+		if err != nil {
+			return out, errors.New("invalid 'CPAL' table (EOF)")
+		}
+		out.labels = make([]NameID, numPalettes)
+		for i, l := range labels {
+			out.labels[i] = NameID(l)
+		}
+	}
+	if entryLabelsOffset != 0 {
+		if len(data) < entryLabelsOffset {
+			return out, errors.New("invalid 'CPAL' table (EOF)")
+		}
+		labels, err := parseUint16s(data[entryLabelsOffset:], numPaletteEntries)
+		if err != nil {
+			return out, errors.New("invalid 'CPAL' table (EOF)")
+		}
+		out.entryLabels = make([]NameID, numPaletteEntries)
+		for i, l := range labels {
+			out.entryLabels[i] = NameID(l)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package truetype
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// CPAL version 1 table with two palettes of two entries, sharing
+// a color record, palette types and labels, but no entry labels.
+var cpalV1 = deHexStr(
+	"0001" + "0002" + "0002" + "0003" + "0000001c" + // header
+		"0000" + "0001" + // colorRecordIndices
+		"00000028" + "00000030" + "00000000" + // version 1 offsets
+		"0000ffff" + "ff000080" + "00ff00ff" + // BGRA records
+		"00000001" + "00000002" + // types
+		"0100" + "ffff") // labels
+
+func TestParseCPALV1(t *testing.T) {
+	cpal, err := parseTableCPAL(cpalV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := &Font{cpal: cpal}
+
+	red, blue, green := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 128}, color.NRGBA{G: 255, A: 255}
+	if exp := [][]color.NRGBA{{red, blue}, {blue, green}}; !reflect.DeepEqual(font.Palettes(), exp) {
+		t.Fatalf("expected %v, got %v", exp, font.Palettes())
+	}
+
+	if f := font.PaletteFlags(0); f != PaletteUsableWithLightBackground {
+		t.Fatalf("unexpected flags %d", f)
+	}
+	if f := font.PaletteFlags(1); f != PaletteUsableWithDarkBackground {
+		t.Fatalf("unexpected flags %d", f)
+	}
+	if f := font.PaletteFlags(2); f != 0 {
+		t.Fatalf("unexpected flags %d", f)
+	}
+	if i := font.SelectPalette(PaletteUsableWithDarkBackground); i != 1 {
+		t.Fatalf("expected dark palette 1, got %d", i)
+	}
+	if i := font.SelectPalette(PaletteUsableWithDarkBackground | PaletteUsableWithLightBackground); i != 0 {
+		t.Fatalf("expected default palette, got %d", i)
+	}
+
+	if name, ok := font.PaletteName(0); !ok || name != 256 {
+		t.Fatalf("unexpected palette name %d %v", name, ok)
+	}
+	if _, ok := font.PaletteName(1); ok {
+		t.Fatal("expected no name for palette 1")
+	}
+	if _, ok := font.PaletteEntryName(0); ok {
+		t.Fatal("expected no entry names")
+	}
+
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	palette := font.Palette(1, map[uint16]color.NRGBA{1: white, 5: white})
+	if exp := []color.NRGBA{blue, white}; !reflect.DeepEqual(palette, exp) {
+		t.Fatalf("expected %v, got %v", exp, palette)
+	}
+	if font.Palettes()[1][1] != green {
+		t.Fatal("overrides must not modify the font palettes")
+	}
+	if palette := font.Palette(-1, nil); !reflect.DeepEqual(palette, font.Palettes()[0]) {
+		t.Fatalf("expected default palette, got %v", palette)
+	}
+
+	foreground := color.NRGBA{A: 255}
+	for _, test := range []struct {
+		entry    uint16
+		expected color.NRGBA
+		ok       bool
+	}{
+		{0, blue, true},
+		{1, white, true},
+		{2, color.NRGBA{}, false},
+		{0xFFFF, foreground, true},
+	} {
+		c, ok := ResolveColor(palette, test.entry, foreground)
+		if c != test.expected || ok != test.ok {
+			t.Fatalf("entry %d: expected %v %v, got %v %v", test.entry, test.expected, test.ok, c, ok)
+		}
+	}
+
+	if _, err := parseTableCPAL(cpalV1[:30]); err == nil {
+		t.Fatal("expected error for truncated table")
+	}
+}
+
+func TestPaintGlyphOverrides(t *testing.T) {
+	font := loadColrFont(t)
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	colors := font.Palette(0, map[uint16]color.NRGBA{7: white})
+
+	var rp recordingPainter
+	if err := font.PaintGlyphWithColors(8, colors, color.NRGBA{}, &rp); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "solid " + formatColor(white); rp[4] != exp {
+		t.Fatalf("expected %s, got %s", exp, rp[4])
+	}
+	if exp := "solid " + formatColor(font.Palettes()[0][0]); rp[1] != exp {
+		t.Fatalf("expected %s, got %s", exp, rp[1])
+	}
+}
@@ -0,0 +1,70 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// GaspBehavior are the flags indicating the rendering
+// to use for a range of sizes.
+type GaspBehavior uint16
+
+const (
+	// GaspGridfit indicates that grid-fitting (hinting) should be used.
+	GaspGridfit GaspBehavior = 1 << iota
+	// GaspDoGray indicates that anti-aliasing should be used.
+	GaspDoGray
+	// GaspSymmetricGridfit indicates that the hinting should be done
+	// with ClearType symmetric smoothing (version 1 only).
+	GaspSymmetricGridfit
+	// GaspSymmetricSmoothing indicates that the smoothing should be done
+	// in both directions (version 1 only).
+	GaspSymmetricSmoothing
+)
+
+// GaspRange is the behavior to use for sizes up to MaxPPEM (included).
+type GaspRange struct {
+	MaxPPEM  uint16
+	Behavior GaspBehavior
+}
+
+// TableGasp is the Grid-fitting And Scan-conversion Procedure table,
+// made of ranges sorted by increasing MaxPPEM.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/gasp
+type TableGasp []GaspRange
+
+// Behavior returns the behavior to use at the given size, in pixels per em,
+// or false if the table does not cover it.
+func (t TableGasp) Behavior(ppem uint16) (GaspBehavior, bool) {
+	for _, r := range t {
+		if ppem <= r.MaxPPEM {
+			return r.Behavior, true
+		}
+	}
+	return 0, false
+}
+
+func parseTableGasp(data []byte) (TableGasp, error) {
+	if len(data) < 4 {
+		return nil, errors.New("invalid 'gasp' table (EOF)")
+	}
+	version := binary.BigEndian.Uint16(data)
+	count := int(binary.BigEndian.Uint16(data[2:]))
+	if len(data) < 4+4*count {
+		return nil, errors.New("invalid 'gasp' table (EOF)")
+	}
+	// the symmetric flags are only defined in version 1
+	mask := GaspGridfit | GaspDoGray
+	if version >= 1 {
+		mask |= GaspSymmetricGridfit | GaspSymmetricSmoothing
+	}
+	out := make(TableGasp, count)
+	for i := range out {
+		out[i].MaxPPEM = binary.BigEndian.Uint16(data[4+4*i:])
+		out[i].Behavior = GaspBehavior(binary.BigEndian.Uint16(data[4+4*i+2:])) & mask
+		if i > 0 && out[i].MaxPPEM <= out[i-1].MaxPPEM {
+			return nil, errors.New("invalid 'gasp' table (unsorted ranges)")
+		}
+	}
+	return out, nil
+}
@@ -118,6 +118,17 @@ func (t *TableGDEF) GetGlyphProps(glyph GID) GlyphProps {
 	}
 }
 
+// MarkSetCovers returns true if `glyph` belongs to the mark glyph set
+// at index `set` (see `LookupOptions.MarkFilteringSet`).
+// An invalid set index covers no glyph.
+func (t *TableGDEF) MarkSetCovers(set uint16, glyph GID) bool {
+	if int(set) >= len(t.MarkGlyphSet) {
+		return false
+	}
+	_, has := t.MarkGlyphSet[set].Index(glyph)
+	return has
+}
+
 func parseMarkGlyphSet(data []byte, offset uint16) ([]Coverage, error) {
 	if len(data) < 4+int(offset) {
 		return nil, errors.New("invalid mark glyph set (EOF)")
@@ -125,7 +136,7 @@ func parseMarkGlyphSet(data []byte, offset uint16) ([]Coverage, error) {
 	data = data[offset:]
 	// format :
 	count := binary.BigEndian.Uint16(data[2:])
-	if len(data) < 4*int(count) {
+	if len(data) < 4+4*int(count) {
 		return nil, errors.New("invalid mark glyph set (EOF)")
 	}
 	out := make([]Coverage, count)
@@ -104,3 +104,25 @@ func TestParseGDEFCaretList(t *testing.T) {
 		t.Fatalf("expected %v, got %v", expectedLigGlyphs, gdef.LigatureCaretList.LigCarets)
 	}
 }
+
+func TestMarkSetCovers(t *testing.T) {
+	gdef := TableGDEF{MarkGlyphSet: []Coverage{
+		CoverageList{3, 5},
+		CoverageRanges{{Start: 10, End: 20}},
+	}}
+	for _, test := range []struct {
+		set      uint16
+		glyph    GID
+		expected bool
+	}{
+		{0, 3, true},
+		{0, 4, false},
+		{1, 15, true},
+		{1, 5, false},
+		{2, 3, false}, // invalid set
+	} {
+		if got := gdef.MarkSetCovers(test.set, test.glyph); got != test.expected {
+			t.Fatalf("set %d, glyph %d: expected %v, got %v", test.set, test.glyph, test.expected, got)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/boxesandglue/textlayout/fonts"
@@ -41,6 +42,9 @@ func parseTableGlyf(data []byte, locaOffsets []uint32) (TableGlyf, error) {
 	out := make(TableGlyf, len(locaOffsets)-1)
 	var err error
 	for i := range out {
+		if locaOffsets[i+1] < locaOffsets[i] || int(locaOffsets[i+1]) > len(data) {
+			return nil, fmt.Errorf("invalid location for glyph %d", i)
+		}
 		lenData := locaOffsets[i+1] - locaOffsets[i]
 		// If a glyph has no outline, then loca[n] = loca [n+1].
 		if lenData == 0 {
@@ -181,6 +185,7 @@ const (
 	xIsSameOrPositiveXShortVector = 0x10
 	yShortVector                  = 0x04
 	yIsSameOrPositiveYShortVector = 0x20
+	repeatFlag                    = 0x08
 )
 
 // update the points in place
@@ -223,8 +228,6 @@ func parseSimpleGlyphData(data []byte, numberOfContours int) (out simpleGlyphDat
 
 	numPoints := int(out.endPtsOfContours[len(out.endPtsOfContours)-1]) + 1
 
-	const repeatFlag = 0x08
-
 	out.points = make([]glyphContourPoint, numPoints)
 
 	// read flags
@@ -311,7 +314,6 @@ func (c *compositeGlyphPart) hasUseMyMetrics() bool {
 // return true if arg1 and arg2 indicated an anchor point,
 // not offsets
 func (c *compositeGlyphPart) isAnchored() bool {
-	const argsAreXyValues = 0x0002
 	return c.flags&argsAreXyValues == 0
 }
 
@@ -323,7 +325,16 @@ func (c *compositeGlyphPart) isScaledOffsets() bool {
 	return c.flags&(scaledComponentOffset|unscaledComponentOffset) == scaledComponentOffset
 }
 
-const arg1And2AreWords = 1
+// composite glyph flags
+const (
+	arg1And2AreWords   = 0x0001
+	argsAreXyValues    = 0x0002
+	weHaveAScale       = 0x0008
+	moreComponents     = 0x0020
+	weHaveAnXAndYScale = 0x0040
+	weHaveATwoByTwo    = 0x0080
+	weHaveInstructions = 0x0100
+)
 
 func (c *compositeGlyphPart) argsAsTranslation() (int16, int16) {
 	// arg1 and arg2 are interpreted as signed integers here
@@ -367,17 +378,6 @@ func (c *compositeGlyphPart) transformPoints(points []contourPoint) {
 
 // data starts after the glyph header
 func parseCompositeGlyphData(data []byte) (out compositeGlyphData, err error) {
-	const (
-		_ = 1 << iota
-		_
-		_
-		weHaveAScale
-		_
-		moreComponents
-		weHaveAnXAndYScale
-		weHaveATwoByTwo
-		weHaveInstructions
-	)
 	var flags uint16
 	for do := true; do; do = flags&moreComponents != 0 {
 		var part compositeGlyphPart
@@ -452,3 +452,160 @@ func parseGlyphInstruction(data []byte) ([]byte, []byte, error) {
 	}
 	return data[2 : 2+instructionLength], data[2+instructionLength:], nil
 }
+
+// ------------------------------------- encoding -------------------------------------
+
+// encode returns the glyph as stored in the 'glyf' table,
+// or nil for an empty glyph.
+// The bounding box is written as it is: it is not recomputed from the points.
+func (g GlyphData) encode() []byte {
+	var numberOfContours int16
+	switch data := g.data.(type) {
+	case simpleGlyphData:
+		numberOfContours = int16(len(data.endPtsOfContours))
+	case compositeGlyphData:
+		numberOfContours = -1
+	default:
+		return nil
+	}
+	out := make([]byte, 10)
+	binary.BigEndian.PutUint16(out, uint16(numberOfContours))
+	binary.BigEndian.PutUint16(out[2:], uint16(g.Xmin))
+	binary.BigEndian.PutUint16(out[4:], uint16(g.Ymin))
+	binary.BigEndian.PutUint16(out[6:], uint16(g.Xmax))
+	binary.BigEndian.PutUint16(out[8:], uint16(g.Ymax))
+	switch data := g.data.(type) {
+	case simpleGlyphData:
+		out = data.appendTo(out)
+	case compositeGlyphData:
+		out = data.appendTo(out)
+	}
+	// glyph data are 2-byte aligned, to support the short 'loca' format
+	if len(out)%2 != 0 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func appendUint16(data []byte, v uint16) []byte {
+	return append(data, byte(v>>8), byte(v))
+}
+
+// appendContourPoint is the inverse of readContourPoint: it writes the
+// relative coordinate `v` in its shortest form and returns the updated flag.
+func appendContourPoint(flag byte, data []byte, v int32, shortFlag, sameFlag uint8) (byte, []byte) {
+	switch {
+	case v == 0:
+		flag |= sameFlag
+	case -0xFF <= v && v <= 0xFF:
+		flag |= shortFlag
+		if v > 0 {
+			flag |= sameFlag
+		} else {
+			v = -v
+		}
+		data = append(data, byte(v))
+	default:
+		data = appendUint16(data, uint16(v))
+	}
+	return flag, data
+}
+
+// appendTo writes the glyph data, after the glyph header.
+func (sg simpleGlyphData) appendTo(out []byte) []byte {
+	for _, end := range sg.endPtsOfContours {
+		out = appendUint16(out, end)
+	}
+	out = appendUint16(out, uint16(len(sg.instructions)))
+	out = append(out, sg.instructions...)
+
+	var (
+		flags        = make([]byte, len(sg.points))
+		dataX, dataY []byte
+		prevX, prevY int32 // coordinates are relative to the previous
+	)
+	for i, p := range sg.points {
+		flag := p.flag & (flagOnCurve | overlapSimple)
+		flag, dataX = appendContourPoint(flag, dataX, int32(p.x)-prevX, xShortVector, xIsSameOrPositiveXShortVector)
+		flag, dataY = appendContourPoint(flag, dataY, int32(p.y)-prevY, yShortVector, yIsSameOrPositiveYShortVector)
+		flags[i] = flag
+		prevX, prevY = int32(p.x), int32(p.y)
+	}
+
+	// compress the flags with the repeat flag
+	for i := 0; i < len(flags); {
+		flag := flags[i]
+		repeat := 0
+		for i+repeat+1 < len(flags) && flags[i+repeat+1] == flag && repeat < 0xFF {
+			repeat++
+		}
+		if repeat > 1 {
+			out = append(out, flag|repeatFlag, byte(repeat))
+		} else {
+			repeat = 0
+			out = append(out, flag)
+		}
+		i += repeat + 1
+	}
+
+	out = append(out, dataX...)
+	out = append(out, dataY...)
+	return out
+}
+
+func floatToFixed214(f float32) uint16 {
+	return uint16(int16(math.Round(float64(f) * (1 << 14))))
+}
+
+// appendTo writes the glyph data, after the glyph header.
+// The size of the arguments is chosen according to their values.
+func (cg compositeGlyphData) appendTo(out []byte) []byte {
+	for i, part := range cg.glyphs {
+		flags := part.flags &^ (arg1And2AreWords | moreComponents | weHaveInstructions)
+		if i != len(cg.glyphs)-1 {
+			flags |= moreComponents
+		} else if len(cg.instructions) != 0 {
+			flags |= weHaveInstructions
+		}
+
+		var arg1, arg2 int32
+		if part.isAnchored() {
+			a1, a2 := part.argsAsIndices()
+			arg1, arg2 = int32(a1), int32(a2)
+			if arg1 > 0xFF || arg2 > 0xFF {
+				flags |= arg1And2AreWords
+			}
+		} else {
+			a1, a2 := part.argsAsTranslation()
+			arg1, arg2 = int32(a1), int32(a2)
+			if arg1 < -128 || arg1 > 127 || arg2 < -128 || arg2 > 127 {
+				flags |= arg1And2AreWords
+			}
+		}
+
+		out = appendUint16(out, flags)
+		out = appendUint16(out, uint16(part.glyphIndex))
+		if flags&arg1And2AreWords != 0 {
+			out = appendUint16(out, uint16(arg1))
+			out = appendUint16(out, uint16(arg2))
+		} else {
+			out = append(out, byte(arg1), byte(arg2))
+		}
+
+		if flags&weHaveAScale != 0 {
+			out = appendUint16(out, floatToFixed214(part.scale[0]))
+		} else if flags&weHaveAnXAndYScale != 0 {
+			out = appendUint16(out, floatToFixed214(part.scale[0]))
+			out = appendUint16(out, floatToFixed214(part.scale[3]))
+		} else if flags&weHaveATwoByTwo != 0 {
+			for _, v := range part.scale {
+				out = appendUint16(out, floatToFixed214(v))
+			}
+		}
+	}
+	if len(cg.instructions) != 0 {
+		out = appendUint16(out, uint16(len(cg.instructions)))
+		out = append(out, cg.instructions...)
+	}
+	return out
+}
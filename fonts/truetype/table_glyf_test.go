@@ -443,3 +443,185 @@ func BenchmarkParseContour(b *testing.B) {
 		parseGlyphContourPoints(data[:19], data[19:19+18], points)
 	}
 }
+
+func TestGlyphEncode(t *testing.T) {
+	for _, filename := range []string{
+		"Roboto-BoldItalic.ttf",
+		"Commissioner-VF.ttf",
+		"FreeSerif.ttf",
+		"SourceSansVariable-Roman.anchor.ttf",
+	} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for gid, glyph := range font.Glyf() {
+			data := glyph.encode()
+			if glyph.data == nil {
+				if data != nil {
+					t.Errorf("%s: glyph %d: expected empty data", filename, gid)
+				}
+				continue
+			}
+			if len(data)%2 != 0 {
+				t.Errorf("%s: glyph %d: unaligned data", filename, gid)
+			}
+			if len(data) > len(glyph.rawdata)+1 { // allow for the padding byte
+				t.Errorf("%s: glyph %d: encoded data longer than the original (%d > %d)", filename, gid, len(data), len(glyph.rawdata))
+			}
+
+			got, err := parseGlyphData(data, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGlyphHeaderEqual(t, glyph, got)
+			switch exp := glyph.data.(type) {
+			case simpleGlyphData:
+				got := got.data.(simpleGlyphData)
+				if !reflect.DeepEqual(exp.endPtsOfContours, got.endPtsOfContours) || !bytes.Equal(exp.instructions, got.instructions) {
+					t.Errorf("%s: glyph %d: invalid contours or instructions", filename, gid)
+				}
+				if len(exp.points) != len(got.points) {
+					t.Fatalf("%s: glyph %d: expected %d points, got %d", filename, gid, len(exp.points), len(got.points))
+				}
+				for i, p := range exp.points {
+					assertPointEqual(t, p, got.points[i])
+					if mask := uint8(flagOnCurve | overlapSimple); p.flag&mask != got.points[i].flag&mask {
+						t.Errorf("%s: glyph %d: invalid flag for point %d", filename, gid, i)
+					}
+				}
+			case compositeGlyphData:
+				got := got.data.(compositeGlyphData)
+				if len(exp.glyphs) != len(got.glyphs) || !bytes.Equal(exp.instructions, got.instructions) {
+					t.Fatalf("%s: glyph %d: invalid components or instructions", filename, gid)
+				}
+				for i, part := range exp.glyphs {
+					gotPart := got.glyphs[i]
+					if part.glyphIndex != gotPart.glyphIndex || part.scale != gotPart.scale || part.isAnchored() != gotPart.isAnchored() {
+						t.Errorf("%s: glyph %d: invalid component %d", filename, gid, i)
+					}
+					if part.isAnchored() {
+						a1, a2 := part.argsAsIndices()
+						b1, b2 := gotPart.argsAsIndices()
+						if a1 != b1 || a2 != b2 {
+							t.Errorf("%s: glyph %d: invalid component %d anchor", filename, gid, i)
+						}
+					} else {
+						a1, a2 := part.argsAsTranslation()
+						b1, b2 := gotPart.argsAsTranslation()
+						if a1 != b1 || a2 != b2 {
+							t.Errorf("%s: glyph %d: invalid component %d offset", filename, gid, i)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// compositeFont returns a font whose glyphs are:
+//
+//	1: a square
+//	2: a triangle
+//	3: the square and the rotated triangle, positioned by point matching
+//	4: a composite referencing itself
+//	5: a composite referencing an out of range glyph
+//	6: the glyph 3 scaled by 2, using the square metrics
+func compositeFont(t *testing.T) *Font {
+	font := loadFont(t, "Roboto-BoldItalic.ttf")
+	identity := [4]float32{1, 0, 0, 1}
+	square := simpleGlyphData{endPtsOfContours: []uint16{3}, points: []glyphContourPoint{
+		{flagOnCurve, 0, 0}, {flagOnCurve, 100, 0}, {flagOnCurve, 100, 100}, {flagOnCurve, 0, 100},
+	}}
+	triangle := simpleGlyphData{endPtsOfContours: []uint16{2}, points: []glyphContourPoint{
+		{flagOnCurve, 0, 0}, {flagOnCurve, 10, 0}, {flagOnCurve, 0, 10},
+	}}
+	glyf := TableGlyf{
+		{},
+		{data: square, Xmax: 100, Ymax: 100},
+		{data: triangle, Xmax: 10, Ymax: 10},
+		{data: compositeGlyphData{glyphs: []compositeGlyphPart{
+			{flags: argsAreXyValues, glyphIndex: 1, scale: identity},
+			// the point 1 of the triangle is moved to the point 2 of the square
+			{flags: weHaveATwoByTwo, glyphIndex: 2, arg1: 2, arg2: 1, scale: [4]float32{0, 1, -1, 0}},
+		}}},
+		{data: compositeGlyphData{glyphs: []compositeGlyphPart{{flags: argsAreXyValues, glyphIndex: 4, scale: identity}}}},
+		{data: compositeGlyphData{glyphs: []compositeGlyphPart{{flags: argsAreXyValues, glyphIndex: 99, scale: identity}}}},
+		{data: compositeGlyphData{glyphs: []compositeGlyphPart{
+			{flags: argsAreXyValues | weHaveAScale | 0x0200, glyphIndex: 3, arg1: 10, scale: [4]float32{2, 0, 0, 2}},
+		}}},
+	}
+	for i := range glyf {
+		glyf[i].rawdata = glyf[i].encode()
+	}
+	font.lazy.setGlyphs(glyf, tableGvar{})
+	return font
+}
+
+func TestCompositePointMatching(t *testing.T) {
+	font := compositeFont(t)
+
+	var points []contourPoint
+	font.getPointsForGlyph(3, 0, &points)
+	expected := [][2]float32{
+		{0, 0}, {100, 0}, {100, 100}, {0, 100}, // square
+		{100, 90}, {100, 100}, {90, 90}, // rotated then moved by (100, 90)
+	}
+	if len(points) != len(expected)+phantomCount {
+		t.Fatalf("unexpected points %v", points)
+	}
+	for i, exp := range expected {
+		if points[i].X != exp[0] || points[i].Y != exp[1] {
+			t.Fatalf("point %d: expected %v, got %v", i, exp, points[i].SegmentPoint)
+		}
+	}
+	if !points[3].isEndPoint || !points[6].isEndPoint {
+		t.Fatal("missing contour end")
+	}
+
+	// the scale applies to the matched points; the translation is applied after the scale
+	points = points[:0]
+	font.getPointsForGlyph(6, 0, &points)
+	if p := points[6]; p.X != 2*90+10 || p.Y != 2*90 {
+		t.Fatalf("unexpected scaled point %v", p.SegmentPoint)
+	}
+	// the metrics are the one of the component
+	_, phantoms := font.getGlyfPoints(6, false)
+	_, exp := font.getGlyfPoints(3, false)
+	if phantoms != exp {
+		t.Fatalf("expected phantoms %v, got %v", exp, phantoms)
+	}
+
+	outline, ok := font.GlyphData(3, 0, 0).(fonts.GlyphOutline)
+	if !ok || len(outline.Segments) != 9 { // 2 MoveTo, 5 LineTo, 2 closing LineTo
+		t.Fatalf("unexpected outline %v", outline)
+	}
+}
+
+func TestCompositeInvalid(t *testing.T) {
+	font := compositeFont(t)
+
+	// too deeply nested
+	var points []contourPoint
+	font.getPointsForGlyph(4, 0, &points)
+	if len(points) != 0 {
+		t.Fatalf("unexpected points %v", points)
+	}
+	if _, err := font.glyphDataFromGlyf(4); err == nil {
+		t.Fatal("expected error for recursive composite")
+	}
+	font.getGlyfPoints(4, true)
+
+	// out of range component: empty outline
+	outline, err := font.glyphDataFromGlyf(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outline.Segments) != 0 {
+		t.Fatalf("unexpected outline %v", outline)
+	}
+}
@@ -126,3 +126,101 @@ func TestKernAAT(t *testing.T) {
 		}
 	}
 }
+
+func TestKernFormat2OT(t *testing.T) {
+	// class based kerning, with pre-multiplied class values
+	const arrayOffset, rowWidth = 30, 4
+	var buf bytes.Buffer
+	for _, v := range []int16{
+		0, 1, // version, nTables
+		0, 38, 0x0201, // version, length, coverage (format 2, horizontal)
+		rowWidth, 14, 22, arrayOffset,
+		1, 2, arrayOffset, arrayOffset + rowWidth, // left class: glyphs 1, 2
+		3, 2, 2, 0, // right class: glyphs 3, 4
+		10, -50, // row for glyph 1
+		20, -80, // row for glyph 2
+	} {
+		buf.Write([]byte{byte(uint16(v) >> 8), byte(v)})
+	}
+
+	kern, err := parseKernTable(buf.Bytes(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kern) != 1 || !kern[0].IsHorizontal() {
+		t.Fatalf("unexpected table %v", kern)
+	}
+	data, ok := kern[0].Data.(Kern2)
+	if !ok {
+		t.Fatalf("unexpected subtable %T", kern[0].Data)
+	}
+	for _, test := range []struct {
+		left, right GID
+		expected    int16
+	}{
+		{1, 3, -50},
+		{2, 3, -80},
+		{1, 4, 10},
+		{2, 4, 20},
+		{5, 3, 0}, // no left class
+	} {
+		if got := data.KernPair(test.left, test.right); got != test.expected {
+			t.Fatalf("pair (%d, %d): expected %d, got %d", test.left, test.right, test.expected, got)
+		}
+	}
+}
+
+func TestKernFormat3(t *testing.T) {
+	data := []byte{
+		0, 1, 0, 0, 0, 0, 0, 1, // version 1.0, nTables
+		0, 0, 0, 30, 0, 3, 0, 0, // length, coverage (format 3, horizontal), tupleIndex
+		0, 4, 2, 2, 2, 0, // glyphCount, kernValueCount, leftClassCount, rightClassCount, flags
+		0, 0, 0xFF, 0xE2, // kern values: 0, -30
+		0, 1, 0, 1, // left classes
+		0, 0, 1, 1, // right classes
+		0, 0, 0, 1, // kern indices
+	}
+	kern, err := parseKernTable(data, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kern) != 1 || !kern[0].IsHorizontal() || kern[0].IsCrossStream() {
+		t.Fatalf("unexpected table %v", kern)
+	}
+	k3, ok := kern[0].Data.(Kern3)
+	if !ok {
+		t.Fatalf("unexpected subtable %T", kern[0].Data)
+	}
+	for _, test := range []struct {
+		left, right GID
+		expected    int16
+	}{
+		{1, 2, -30},
+		{3, 3, -30},
+		{0, 3, 0},
+		{1, 0, 0},
+		{1, 10, 0}, // out of range
+	} {
+		if got := k3.KernPair(test.left, test.right); got != test.expected {
+			t.Fatalf("pair (%d, %d): expected %d, got %d", test.left, test.right, test.expected, got)
+		}
+	}
+
+	// invalid index
+	data[len(data)-1] = 2
+	if _, err := parseKernTable(data, 4); err == nil {
+		t.Fatal("expected error for invalid kern index")
+	}
+}
+
+func TestKernInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{0, 1, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF},                                 // too many subtables
+		{0, 0, 0, 1, 0, 0, 0, 20, 0x02, 0x01},                                // truncated format 2
+		{0, 0, 0, 1, 0, 0, 0, 14, 0x02, 0x01, 0, 4, 0xFF, 0, 0xFF, 0, 0, 14}, // invalid class offsets
+	} {
+		if _, err := parseKernTable(data, 10); err == nil {
+			t.Fatalf("expected error for %v", data)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// TableMATH provides the metrics and glyph constructions
+// required to layout mathematical formulas.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/math
+type TableMATH struct {
+	Constants MathConstants
+	GlyphInfo MathGlyphInfo
+	Variants  MathVariants
+}
+
+// MathValueRecord is a value in design units, with an optional
+// device table (which is nil if absent).
+type MathValueRecord struct {
+	Device DeviceTable
+	Value  int16
+}
+
+// MathConstant identifies a global constant of the 'MATH' table.
+type MathConstant uint8
+
+const (
+	MathScriptPercentScaleDown MathConstant = iota
+	MathScriptScriptPercentScaleDown
+	MathDelimitedSubFormulaMinHeight
+	MathDisplayOperatorMinHeight
+	MathMathLeading
+	MathAxisHeight
+	MathAccentBaseHeight
+	MathFlattenedAccentBaseHeight
+	MathSubscriptShiftDown
+	MathSubscriptTopMax
+	MathSubscriptBaselineDropMin
+	MathSuperscriptShiftUp
+	MathSuperscriptShiftUpCramped
+	MathSuperscriptBottomMin
+	MathSuperscriptBaselineDropMax
+	MathSubSuperscriptGapMin
+	MathSuperscriptBottomMaxWithSubscript
+	MathSpaceAfterScript
+	MathUpperLimitGapMin
+	MathUpperLimitBaselineRiseMin
+	MathLowerLimitGapMin
+	MathLowerLimitBaselineDropMin
+	MathStackTopShiftUp
+	MathStackTopDisplayStyleShiftUp
+	MathStackBottomShiftDown
+	MathStackBottomDisplayStyleShiftDown
+	MathStackGapMin
+	MathStackDisplayStyleGapMin
+	MathStretchStackTopShiftUp
+	MathStretchStackBottomShiftDown
+	MathStretchStackGapAboveMin
+	MathStretchStackGapBelowMin
+	MathFractionNumeratorShiftUp
+	MathFractionNumeratorDisplayStyleShiftUp
+	MathFractionDenominatorShiftDown
+	MathFractionDenominatorDisplayStyleShiftDown
+	MathFractionNumeratorGapMin
+	MathFractionNumDisplayStyleGapMin
+	MathFractionRuleThickness
+	MathFractionDenominatorGapMin
+	MathFractionDenomDisplayStyleGapMin
+	MathSkewedFractionHorizontalGap
+	MathSkewedFractionVerticalGap
+	MathOverbarVerticalGap
+	MathOverbarRuleThickness
+	MathOverbarExtraAscender
+	MathUnderbarVerticalGap
+	MathUnderbarRuleThickness
+	MathUnderbarExtraDescender
+	MathRadicalVerticalGap
+	MathRadicalDisplayStyleVerticalGap
+	MathRadicalRuleThickness
+	MathRadicalExtraAscender
+	MathRadicalKernBeforeDegree
+	MathRadicalKernAfterDegree
+	MathRadicalDegreeBottomRaisePercent
+
+	mathConstantsCount
+)
+
+// MathConstants stores the global constants, indexed by MathConstant.
+// The constants MathScriptPercentScaleDown, MathScriptScriptPercentScaleDown and
+// MathRadicalDegreeBottomRaisePercent are percentages; the constants
+// MathDelimitedSubFormulaMinHeight and MathDisplayOperatorMinHeight are unsigned.
+// Only the other constants may have a device table.
+type MathConstants [mathConstantsCount]MathValueRecord
+
+// MathGlyphInfo stores per-glyph positioning information.
+type MathGlyphInfo struct {
+	ItalicsCorrection   MathValueTable
+	TopAccentAttachment MathValueTable
+	// ExtendedShapes covers the glyphs which should be
+	// considered as extended shapes. It may be nil.
+	ExtendedShapes Coverage
+	Kerns          MathKernInfo
+}
+
+// MathValueTable associates a value to the glyphs of a coverage.
+type MathValueTable struct {
+	Coverage Coverage          // may be nil
+	Values   []MathValueRecord // with same length as Coverage.Size()
+}
+
+func (mv MathValueTable) value(glyph GID) (MathValueRecord, bool) {
+	if mv.Coverage == nil {
+		return MathValueRecord{}, false
+	}
+	index, ok := mv.Coverage.Index(glyph)
+	if !ok {
+		return MathValueRecord{}, false
+	}
+	return mv.Values[index], true
+}
+
+// MathKernCorner selects one of the four corners of a glyph
+// where the kerning information is defined.
+type MathKernCorner uint8
+
+const (
+	MathKernTopRight MathKernCorner = iota
+	MathKernTopLeft
+	MathKernBottomRight
+	MathKernBottomLeft
+)
+
+// MathKernInfo stores the cut-ins for the four corners of each covered glyph.
+type MathKernInfo struct {
+	Coverage Coverage       // may be nil
+	Kerns    [][4]*MathKern // indexed by MathKernCorner, with same length as Coverage.Size()
+}
+
+// MathKern describes the kerning at one corner of a glyph as a step
+// function of the height: KernValues[i] applies between
+// CorrectionHeights[i-1] and CorrectionHeights[i].
+type MathKern struct {
+	CorrectionHeights []MathValueRecord
+	KernValues        []MathValueRecord // with length len(CorrectionHeights) + 1
+}
+
+// MathVariants stores the size variants and the assemblies
+// used to build stretchy glyphs.
+type MathVariants struct {
+	Vertical, Horizontal MathGlyphConstructions
+	// MinConnectorOverlap is the minimum overlap of connecting
+	// glyphs during glyph construction, in design units.
+	MinConnectorOverlap uint16
+}
+
+// MathGlyphConstructions associates a construction to the glyphs of a coverage.
+type MathGlyphConstructions struct {
+	Coverage      Coverage                // may be nil
+	Constructions []MathGlyphConstruction // with same length as Coverage.Size()
+}
+
+func (mc MathGlyphConstructions) construction(glyph GID) (MathGlyphConstruction, bool) {
+	if mc.Coverage == nil {
+		return MathGlyphConstruction{}, false
+	}
+	index, ok := mc.Coverage.Index(glyph)
+	if !ok {
+		return MathGlyphConstruction{}, false
+	}
+	return mc.Constructions[index], true
+}
+
+// MathGlyphConstruction lists the ways of growing a glyph.
+type MathGlyphConstruction struct {
+	// Assembly has nil Parts if the glyph has no assembly.
+	Assembly MathGlyphAssembly
+	// Variants are sorted by increasing size.
+	Variants []MathGlyphVariant
+}
+
+// MathGlyphVariant is a pre-built size variant of a glyph.
+type MathGlyphVariant struct {
+	Glyph GID
+	// Advance is the advance along the direction of growth,
+	// in design units.
+	Advance uint16
+}
+
+// MathGlyphAssembly describes how to build a stretchy glyph
+// from several parts.
+type MathGlyphAssembly struct {
+	Parts             []MathGlyphPart // from left to right or bottom to top
+	ItalicsCorrection MathValueRecord
+}
+
+// MathGlyphPart is one part of a glyph assembly. Lengths
+// are expressed in design units.
+type MathGlyphPart struct {
+	Glyph                GID
+	StartConnectorLength uint16
+	EndConnectorLength   uint16
+	FullAdvance          uint16
+	Flags                uint16
+}
+
+// IsExtender returns true if the part may be repeated
+// to grow the assembly.
+func (part MathGlyphPart) IsExtender() bool { return part.Flags&1 != 0 }
+
+func parseTableMATH(data []byte) (out TableMATH, err error) {
+	if len(data) < 10 {
+		return out, errors.New("invalid 'MATH' table (EOF)")
+	}
+	if major := binary.BigEndian.Uint16(data); major != 1 {
+		return out, fmt.Errorf("unsupported 'MATH' table version %d", major)
+	}
+	constantsOffset := binary.BigEndian.Uint16(data[4:])
+	glyphInfoOffset := binary.BigEndian.Uint16(data[6:])
+	variantsOffset := binary.BigEndian.Uint16(data[8:])
+
+	if constantsOffset != 0 {
+		out.Constants, err = parseMathConstants(data, constantsOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if glyphInfoOffset != 0 {
+		out.GlyphInfo, err = parseMathGlyphInfo(data, glyphInfoOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if variantsOffset != 0 {
+		out.Variants, err = parseMathVariants(data, variantsOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// parseMathValueRecord parses the record at `data[offset:]`, resolving
+// the device table from `parent`.
+func parseMathValueRecord(parent []byte, offset int) (out MathValueRecord, err error) {
+	if len(parent) < offset+4 {
+		return out, errors.New("invalid math value record (EOF)")
+	}
+	out.Value = int16(binary.BigEndian.Uint16(parent[offset:]))
+	if deviceOffset := binary.BigEndian.Uint16(parent[offset+2:]); deviceOffset != 0 {
+		out.Device, err = parseDeviceTable(parent, deviceOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseMathConstants(data []byte, offset uint16) (out MathConstants, err error) {
+	// 4 int16 + 51 MathValueRecord + 1 int16
+	const size = 4*2 + 51*4 + 2
+	if len(data) < int(offset)+size {
+		return out, errors.New("invalid math constants (EOF)")
+	}
+	data = data[offset:]
+	for i := MathScriptPercentScaleDown; i <= MathDisplayOperatorMinHeight; i++ {
+		out[i].Value = int16(binary.BigEndian.Uint16(data[2*i:]))
+	}
+	for i := MathMathLeading; i < MathRadicalDegreeBottomRaisePercent; i++ {
+		out[i], err = parseMathValueRecord(data, 8+4*int(i-MathMathLeading))
+		if err != nil {
+			return out, err
+		}
+	}
+	out[MathRadicalDegreeBottomRaisePercent].Value = int16(binary.BigEndian.Uint16(data[size-2:]))
+	return out, nil
+}
+
+func parseMathGlyphInfo(data []byte, offset uint16) (out MathGlyphInfo, err error) {
+	if len(data) < int(offset)+8 {
+		return out, errors.New("invalid math glyph info (EOF)")
+	}
+	data = data[offset:]
+	italicsOffset := binary.BigEndian.Uint16(data)
+	topAccentOffset := binary.BigEndian.Uint16(data[2:])
+	extendedOffset := binary.BigEndian.Uint16(data[4:])
+	kernOffset := binary.BigEndian.Uint16(data[6:])
+
+	if italicsOffset != 0 {
+		out.ItalicsCorrection, err = parseMathValueTable(data, italicsOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if topAccentOffset != 0 {
+		out.TopAccentAttachment, err = parseMathValueTable(data, topAccentOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if extendedOffset != 0 {
+		out.ExtendedShapes, err = parseCoverage(data, uint32(extendedOffset))
+		if err != nil {
+			return out, err
+		}
+	}
+	if kernOffset != 0 {
+		out.Kerns, err = parseMathKernInfo(data, kernOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseMathValueTable(data []byte, offset uint16) (out MathValueTable, err error) {
+	if len(data) < int(offset)+4 {
+		return out, errors.New("invalid math value table (EOF)")
+	}
+	data = data[offset:]
+	coverageOffset := binary.BigEndian.Uint16(data)
+	count := int(binary.BigEndian.Uint16(data[2:]))
+
+	out.Coverage, err = parseCoverage(data, uint32(coverageOffset))
+	if err != nil {
+		return out, err
+	}
+	if out.Coverage.Size() != count {
+		return out, fmt.Errorf("invalid math value table length: %d for %d", count, out.Coverage.Size())
+	}
+	out.Values = make([]MathValueRecord, count)
+	for i := range out.Values {
+		out.Values[i], err = parseMathValueRecord(data, 4+4*i)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseMathKernInfo(data []byte, offset uint16) (out MathKernInfo, err error) {
+	if len(data) < int(offset)+4 {
+		return out, errors.New("invalid math kern info (EOF)")
+	}
+	data = data[offset:]
+	coverageOffset := binary.BigEndian.Uint16(data)
+	count := int(binary.BigEndian.Uint16(data[2:]))
+
+	out.Coverage, err = parseCoverage(data, uint32(coverageOffset))
+	if err != nil {
+		return out, err
+	}
+	if out.Coverage.Size() != count {
+		return out, fmt.Errorf("invalid math kern info length: %d for %d", count, out.Coverage.Size())
+	}
+	offsets, err := parseUint16s(data[4:], 4*count)
+	if err != nil {
+		return out, errors.New("invalid math kern info (EOF)")
+	}
+	out.Kerns = make([][4]*MathKern, count)
+	for i := range out.Kerns {
+		for corner, kernOffset := range offsets[4*i : 4*i+4] {
+			if kernOffset == 0 {
+				continue
+			}
+			kern, err := parseMathKern(data, kernOffset)
+			if err != nil {
+				return out, err
+			}
+			out.Kerns[i][corner] = &kern
+		}
+	}
+	return out, nil
+}
+
+func parseMathKern(data []byte, offset uint16) (out MathKern, err error) {
+	if len(data) < int(offset)+2 {
+		return out, errors.New("invalid math kern (EOF)")
+	}
+	data = data[offset:]
+	heightCount := int(binary.BigEndian.Uint16(data))
+	out.CorrectionHeights = make([]MathValueRecord, heightCount)
+	out.KernValues = make([]MathValueRecord, heightCount+1)
+	for i := range out.CorrectionHeights {
+		out.CorrectionHeights[i], err = parseMathValueRecord(data, 2+4*i)
+		if err != nil {
+			return out, err
+		}
+	}
+	for i := range out.KernValues {
+		out.KernValues[i], err = parseMathValueRecord(data, 2+4*(heightCount+i))
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseMathVariants(data []byte, offset uint16) (out MathVariants, err error) {
+	if len(data) < int(offset)+10 {
+		return out, errors.New("invalid math variants (EOF)")
+	}
+	data = data[offset:]
+	out.MinConnectorOverlap = binary.BigEndian.Uint16(data)
+	vertCoverageOffset := binary.BigEndian.Uint16(data[2:])
+	horizCoverageOffset := binary.BigEndian.Uint16(data[4:])
+	vertCount := int(binary.BigEndian.Uint16(data[6:]))
+	horizCount := int(binary.BigEndian.Uint16(data[8:]))
+	offsets, err := parseUint16s(data[10:], vertCount+horizCount)
+	if err != nil {
+		return out, errors.New("invalid math variants (EOF)")
+	}
+
+	out.Vertical, err = parseMathGlyphConstructions(data, vertCoverageOffset, offsets[:vertCount])
+	if err != nil {
+		return out, err
+	}
+	out.Horizontal, err = parseMathGlyphConstructions(data, horizCoverageOffset, offsets[vertCount:])
+	if err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func parseMathGlyphConstructions(data []byte, coverageOffset uint16, offsets []uint16) (out MathGlyphConstructions, err error) {
+	if coverageOffset == 0 {
+		if len(offsets) != 0 {
+			return out, errors.New("invalid math variants (missing coverage)")
+		}
+		return out, nil
+	}
+	out.Coverage, err = parseCoverage(data, uint32(coverageOffset))
+	if err != nil {
+		return out, err
+	}
+	if out.Coverage.Size() != len(offsets) {
+		return out, fmt.Errorf("invalid math variants length: %d for %d", len(offsets), out.Coverage.Size())
+	}
+	out.Constructions = make([]MathGlyphConstruction, len(offsets))
+	for i, offset := range offsets {
+		out.Constructions[i], err = parseMathGlyphConstruction(data, offset)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseMathGlyphConstruction(data []byte, offset uint16) (out MathGlyphConstruction, err error) {
+	if len(data) < int(offset)+4 {
+		return out, errors.New("invalid math glyph construction (EOF)")
+	}
+	data = data[offset:]
+	assemblyOffset := binary.BigEndian.Uint16(data)
+	count := int(binary.BigEndian.Uint16(data[2:]))
+	if len(data) < 4+4*count {
+		return out, errors.New("invalid math glyph construction (EOF)")
+	}
+	out.Variants = make([]MathGlyphVariant, count)
+	for i := range out.Variants {
+		out.Variants[i].Glyph = GID(binary.BigEndian.Uint16(data[4+4*i:]))
+		out.Variants[i].Advance = binary.BigEndian.Uint16(data[4+4*i+2:])
+	}
+	if assemblyOffset != 0 {
+		out.Assembly, err = parseMathGlyphAssembly(data, assemblyOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseMathGlyphAssembly(data []byte, offset uint16) (out MathGlyphAssembly, err error) {
+	if len(data) < int(offset)+6 {
+		return out, errors.New("invalid math glyph assembly (EOF)")
+	}
+	data = data[offset:]
+	out.ItalicsCorrection, err = parseMathValueRecord(data, 0)
+	if err != nil {
+		return out, err
+	}
+	count := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 6+10*count {
+		return out, errors.New("invalid math glyph assembly (EOF)")
+	}
+	out.Parts = make([]MathGlyphPart, count)
+	for i := range out.Parts {
+		part := data[6+10*i:]
+		out.Parts[i] = MathGlyphPart{
+			Glyph:                GID(binary.BigEndian.Uint16(part)),
+			StartConnectorLength: binary.BigEndian.Uint16(part[2:]),
+			EndConnectorLength:   binary.BigEndian.Uint16(part[4:]),
+			FullAdvance:          binary.BigEndian.Uint16(part[6:]),
+			Flags:                binary.BigEndian.Uint16(part[8:]),
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,119 @@
+package truetype
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMATH(t *testing.T) {
+	font := loadFont(t, "DejaVuSerif.ttf")
+	table := font.LayoutTables().Math
+
+	for constant, exp := range map[MathConstant]float32{
+		MathScriptPercentScaleDown:          80,
+		MathScriptScriptPercentScaleDown:    60,
+		MathDelimitedSubFormulaMinHeight:    3072,
+		MathDisplayOperatorMinHeight:        2013,
+		MathAxisHeight:                      642,
+		MathRadicalKernAfterDegree:          -1137,
+		MathRadicalDegreeBottomRaisePercent: 60,
+	} {
+		if got := font.MathConstant(constant); got != exp {
+			t.Errorf("constant %d: expected %g, got %g", constant, exp, got)
+		}
+	}
+	if font.MathConstant(mathConstantsCount) != 0 {
+		t.Error("expected 0 for invalid constant")
+	}
+
+	if font.MathMinConnectorOverlap() != 40 {
+		t.Errorf("unexpected min connector overlap %g", font.MathMinConnectorOverlap())
+	}
+	if L := len(table.Variants.Vertical.Constructions); L != 22 {
+		t.Errorf("expected 22 vertical constructions, got %d", L)
+	}
+	if L := len(table.Variants.Horizontal.Constructions); L != 12 {
+		t.Errorf("expected 12 horizontal constructions, got %d", L)
+	}
+
+	paren, _ := font.NominalGlyph('(')
+	parts, italic, ok := font.MathGlyphAssembly(paren, true)
+	if !ok || italic != 0 {
+		t.Fatal("missing assembly for (")
+	}
+	expected := []MathGlyphPart{
+		{Glyph: 2360, EndConnectorLength: 40, FullAdvance: 2421},
+		{Glyph: 2359, StartConnectorLength: 40, EndConnectorLength: 40, FullAdvance: 2445, Flags: 1},
+		{Glyph: 2358, StartConnectorLength: 40, FullAdvance: 2454},
+	}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Fatalf("expected %v, got %v", expected, parts)
+	}
+	if parts[0].IsExtender() || !parts[1].IsExtender() {
+		t.Error("invalid extender flags")
+	}
+	if _, _, ok = font.MathGlyphAssembly(paren, false); ok {
+		t.Error("unexpected horizontal assembly for (")
+	}
+}
+
+// a MathGlyphInfo table with
+//   - italics corrections for glyphs 3 and 5
+//   - top accent attachment for glyph 3
+//   - extended shape for glyph 7
+//   - top right kerning for glyph 5
+var mathGlyphInfo = []byte{
+	0, 8, 0, 28, 0, 42, 0, 48, // offsets
+	// italics correction (8)
+	0, 12, 0, 2, 0, 50, 0, 0, 0xFF, 0xEC, 0, 0,
+	0, 1, 0, 2, 0, 3, 0, 5, // coverage
+	// top accent attachment (28)
+	0, 8, 0, 1, 1, 0x2C, 0, 0,
+	0, 1, 0, 1, 0, 3, // coverage
+	// extended shapes coverage (42)
+	0, 1, 0, 1, 0, 7,
+	// kern info (48)
+	0, 12, 0, 1, 0, 18, 0, 0, 0, 0, 0, 0,
+	0, 1, 0, 1, 0, 5, // coverage
+	// top right math kern (18)
+	0, 2, 0, 100, 0, 0, 0, 200, 0, 0,
+	0, 10, 0, 0, 0, 20, 0, 0, 0, 30, 0, 0,
+}
+
+func TestMathGlyphInfo(t *testing.T) {
+	info, err := parseMathGlyphInfo(mathGlyphInfo, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var font Font
+	font.tables().layoutTables().Math.GlyphInfo = info
+
+	if font.MathItalicsCorrection(3) != 50 || font.MathItalicsCorrection(5) != -20 || font.MathItalicsCorrection(4) != 0 {
+		t.Error("invalid italics correction")
+	}
+	if v, ok := font.MathTopAccentAttachment(3); !ok || v != 300 {
+		t.Errorf("invalid top accent attachment %g", v)
+	}
+	if _, ok := font.MathTopAccentAttachment(5); ok {
+		t.Error("unexpected top accent attachment")
+	}
+	if !font.IsMathExtendedShape(7) || font.IsMathExtendedShape(3) {
+		t.Error("invalid extended shapes")
+	}
+	for _, test := range []struct {
+		height, kern float32
+	}{
+		{50, 10}, {100, 10}, {150, 20}, {200, 20}, {250, 30},
+	} {
+		if got := font.MathKerning(5, MathKernTopRight, test.height); got != test.kern {
+			t.Errorf("height %g: expected kern %g, got %g", test.height, test.kern, got)
+		}
+	}
+	if font.MathKerning(5, MathKernBottomLeft, 50) != 0 || font.MathKerning(3, MathKernTopRight, 50) != 0 {
+		t.Error("unexpected kerning")
+	}
+
+	if _, err = parseMathGlyphInfo(mathGlyphInfo[:60], 0); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
@@ -0,0 +1,98 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/boxesandglue/textlayout/language"
+)
+
+var (
+	tagDlng = MustNewTag("dlng")
+	tagSlng = MustNewTag("slng")
+)
+
+// TableMeta is the Metadata table, which provides the languages
+// a font is designed for and the languages it supports.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/meta
+type TableMeta struct {
+	// DesignLanguages are the ScriptLangTags (such as "Latn" or "zh-Hant")
+	// of the languages the font is primarily designed for ('dlng' entry).
+	DesignLanguages []string
+	// SupportedLanguages are the ScriptLangTags of the languages
+	// the font is capable of displaying ('slng' entry).
+	SupportedLanguages []string
+}
+
+func parseTableMeta(data []byte) (out TableMeta, err error) {
+	if len(data) < 16 {
+		return out, errors.New("invalid 'meta' table (EOF)")
+	}
+	// version, flags and reserved fields are ignored
+	count := int(binary.BigEndian.Uint32(data[12:]))
+	if len(data) < 16+12*count {
+		return out, errors.New("invalid 'meta' table (EOF)")
+	}
+	for i := 0; i < count; i++ {
+		record := data[16+12*i:]
+		tag := Tag(binary.BigEndian.Uint32(record))
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		length := int(binary.BigEndian.Uint32(record[8:]))
+		if len(data) < offset+length || offset+length < offset {
+			return out, errors.New("invalid 'meta' table (data map out of range)")
+		}
+		switch tag {
+		case tagDlng:
+			out.DesignLanguages = parseScriptLangTags(data[offset : offset+length])
+		case tagSlng:
+			out.SupportedLanguages = parseScriptLangTags(data[offset : offset+length])
+		}
+	}
+	return out, nil
+}
+
+// parseScriptLangTags splits the comma separated list of tags.
+func parseScriptLangTags(data []byte) []string {
+	var out []string
+	for _, tag := range strings.Split(string(data), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// scriptLangTagsContain returns true if one of the `tags`
+// has a script subtag matching `script`.
+func scriptLangTagsContain(tags []string, script language.Script) bool {
+	for _, tag := range tags {
+		for _, subtag := range strings.Split(tag, "-") {
+			if len(subtag) != 4 {
+				continue
+			}
+			// script subtags are case insensitive, but lower cased in Script
+			if s, err := language.ParseScript(strings.ToLower(subtag)); err == nil && s == script {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsDesignedFor returns true if `script` is one of the scripts
+// listed in the design languages.
+func (t TableMeta) IsDesignedFor(script language.Script) bool {
+	return scriptLangTagsContain(t.DesignLanguages, script)
+}
+
+// Supports returns true if `script` is one of the scripts
+// listed in the supported or design languages.
+func (t TableMeta) Supports(script language.Script) bool {
+	return scriptLangTagsContain(t.SupportedLanguages, script) ||
+		scriptLangTagsContain(t.DesignLanguages, script)
+}
+
+// Meta returns the design and supported languages
+// provided by the 'meta' table, which may be empty.
+func (f *Font) Meta() TableMeta { return f.meta }
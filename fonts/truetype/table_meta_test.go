@@ -0,0 +1,71 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/boxesandglue/textlayout/language"
+)
+
+func buildMetaTable(entries map[Tag]string, order []Tag) []byte {
+	out := make([]byte, 16+12*len(order))
+	binary.BigEndian.PutUint32(out, 1)
+	binary.BigEndian.PutUint32(out[12:], uint32(len(order)))
+	for i, tag := range order {
+		record := out[16+12*i:]
+		binary.BigEndian.PutUint32(record, uint32(tag))
+		binary.BigEndian.PutUint32(record[4:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(entries[tag])))
+		out = append(out, entries[tag]...)
+	}
+	return out
+}
+
+func TestParseMeta(t *testing.T) {
+	tagAppl := MustNewTag("appl")
+	data := buildMetaTable(map[Tag]string{
+		tagDlng: "Jpan",
+		tagSlng: "Latn, Jpan,Hira , zh-hant,",
+		tagAppl: "\x00\x01",
+	}, []Tag{tagAppl, tagDlng, tagSlng})
+
+	meta, err := parseTableMeta(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"Jpan"}; !reflect.DeepEqual(meta.DesignLanguages, exp) {
+		t.Fatalf("expected %v, got %v", exp, meta.DesignLanguages)
+	}
+	if exp := []string{"Latn", "Jpan", "Hira", "zh-hant"}; !reflect.DeepEqual(meta.SupportedLanguages, exp) {
+		t.Fatalf("expected %v, got %v", exp, meta.SupportedLanguages)
+	}
+
+	jpan, _ := language.ParseScript("jpan")
+	hant, _ := language.ParseScript("hant")
+	if !meta.IsDesignedFor(jpan) || meta.IsDesignedFor(language.Latin) {
+		t.Fatal("invalid design languages")
+	}
+	for _, script := range []language.Script{jpan, hant, language.Latin, language.Hiragana} {
+		if !meta.Supports(script) {
+			t.Fatalf("script %s should be supported", script)
+		}
+	}
+	if meta.Supports(language.Arabic) {
+		t.Fatal("Arabic should not be supported")
+	}
+
+	if _, err := parseTableMeta(data[:30]); err == nil {
+		t.Fatal("expected error for truncated table")
+	}
+	if _, err := parseTableMeta(data[:len(data)-1]); err == nil {
+		t.Fatal("expected error for out of range data map")
+	}
+}
+
+func TestMetaMissing(t *testing.T) {
+	font := loadFont(t, "Roboto-BoldItalic.ttf")
+	if meta := font.Meta(); meta.DesignLanguages != nil || meta.SupportedLanguages != nil {
+		t.Fatalf("unexpected meta table %v", meta)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"strconv"
 
+	"github.com/boxesandglue/textlayout/language"
 	"golang.org/x/text/encoding/charmap"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
@@ -108,12 +109,79 @@ func (names TableName) SelectEntry(name NameID) *NameEntry {
 	return nil
 }
 
+// Name returns the name for `name`, in the language which best matches `lang`
+// (a BCP 47 tag), or an empty string if not found.
+// Exact matches are preferred to matches of the primary language only,
+// and English is used as a fallback, then any language.
+// For equally matching languages, the Windows Unicode entries are preferred,
+// then the Unicode and the Macintosh Roman ones.
+// If `lang` is empty, it behaves like SelectEntry.
+func (names TableName) Name(name NameID, lang language.Language) string {
+	if lang == "" {
+		return names.getName(name)
+	}
+	best, bestScore := -1, -1
+	for i, rec := range names {
+		if rec.NameID != name || len(rec.Value) == 0 {
+			continue
+		}
+		score := rec.platformScore()
+		if rec.Language != "" {
+			switch lang.Compare(rec.Language) {
+			case language.LanguagesExactMatch:
+				score += 30
+			case language.LanguagePrimaryMatch:
+				score += 20
+			default:
+				if rec.Language.IsDerivedFrom("en") {
+					score += 10
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best == -1 {
+		return ""
+	}
+	return names[best].String()
+}
+
 type NameEntry struct {
 	Value      []byte // raw value of the name
 	PlatformID PlatformID
 	EncodingID PlatformEncodingID
 	LanguageID PlatformLanguageID
 	NameID     NameID
+	// Language is the BCP 47 tag corresponding to LanguageID,
+	// either resolved from the language tags of a format 1 table,
+	// or from the platform language codes.
+	// It is empty for unknown languages.
+	Language language.Language
+}
+
+// platformScore returns how reliably the entry may be decoded.
+func (n NameEntry) platformScore() int {
+	switch {
+	case n.PlatformID == PlatformMicrosoft && n.isUTF16():
+		return 3
+	case n.PlatformID == PlatformUnicode:
+		return 2
+	case n.isMac():
+		return 1
+	}
+	return 0
+}
+
+// isUTF16 returns true for the Unicode platform and the Windows
+// Symbol, Unicode BMP and Unicode full encodings.
+func (n NameEntry) isUTF16() bool {
+	if n.PlatformID == PlatformUnicode {
+		return true
+	}
+	return n.PlatformID == PlatformMicrosoft && (n.EncodingID == PEMicrosoftSymbolCs ||
+		n.EncodingID == PEMicrosoftUnicodeCs || n.EncodingID == PEMicrosoftUcs4)
 }
 
 func (n NameEntry) isWindows() bool {
@@ -125,11 +193,10 @@ func (n NameEntry) isMac() bool {
 }
 
 // String is a best-effort attempt to get an UTF-8 encoded version of
-// Value. Only MicrosoftUnicode (3,1 ,X), MacRomain (1,0,X) and Unicode platform
-// strings are supported.
+// Value. Only Microsoft (3,0,X), (3,1,X) and (3,10,X), MacRoman (1,0,X)
+// and Unicode platform strings are supported.
 func (n *NameEntry) String() string {
-	if n.PlatformID == PlatformUnicode || (n.PlatformID == PlatformMicrosoft &&
-		n.EncodingID == PEMicrosoftUnicodeCs) {
+	if n.isUTF16() {
 
 		decoder := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder()
 
@@ -204,6 +271,7 @@ const (
 	PEUnicodeDefault     = PlatformEncodingID(0)
 	PEUnicodeBMP         = PlatformEncodingID(3)
 	PEUnicodeFull        = PlatformEncodingID(4)
+	PEUnicodeVariations  = PlatformEncodingID(5)
 	PEUnicodeFull13      = PlatformEncodingID(6)
 	PEMacRoman           = PEUnicodeDefault
 	PEMicrosoftSymbolCs  = PlatformEncodingID(0)
@@ -346,8 +414,38 @@ func parseTableName(buf []byte) (TableName, error) {
 			EncodingID: record.EncodingID,
 			LanguageID: record.LanguageID,
 			NameID:     record.NameID,
+			Language:   platformLanguage(record.PlatformID, record.LanguageID),
 		})
 	}
 
+	if header.Format != 1 {
+		return table, nil
+	}
+
+	// the language IDs starting at 0x8000 refer to language tags
+	var langTagCount uint16
+	if err := binary.Read(r, binary.BigEndian, &langTagCount); err != nil {
+		return nil, err
+	}
+	langTags := make([]language.Language, langTagCount)
+	for i := range langTags {
+		var record struct{ Length, Offset uint16 }
+		if err := binary.Read(r, binary.BigEndian, &record); err != nil {
+			return nil, err
+		}
+		start := int(header.StringOffset) + int(record.Offset)
+		end := start + int(record.Length)
+		if end > len(buf) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		tag := NameEntry{Value: buf[start:end], PlatformID: PlatformUnicode}
+		langTags[i] = language.NewLanguage(tag.String())
+	}
+	for i, entry := range table {
+		if index := int(entry.LanguageID) - 0x8000; index >= 0 && index < len(langTags) {
+			table[i].Language = langTags[index]
+		}
+	}
+
 	return table, nil
 }
@@ -0,0 +1,75 @@
+package truetype
+
+import "github.com/boxesandglue/textlayout/language"
+
+// msLanguages maps the Windows language IDs (LCID) with a region
+// commonly found in fonts to BCP 47 tags.
+var msLanguages = map[PlatformLanguageID]language.Language{
+	0x0404: "zh-tw", 0x0804: "zh-cn", 0x0c04: "zh-hk", 0x1004: "zh-sg", 0x1404: "zh-mo",
+	0x0407: "de-de", 0x0807: "de-ch", 0x0c07: "de-at", 0x1007: "de-lu", 0x1407: "de-li",
+	0x0409: "en-us", 0x0809: "en-gb", 0x0c09: "en-au", 0x1009: "en-ca", 0x1409: "en-nz",
+	0x1809: "en-ie", 0x1c09: "en-za", 0x4009: "en-in",
+	0x040a: "es-es", 0x080a: "es-mx", 0x0c0a: "es-es", 0x2c0a: "es-ar", 0x540a: "es-us",
+	0x040c: "fr-fr", 0x080c: "fr-be", 0x0c0c: "fr-ca", 0x100c: "fr-ch", 0x140c: "fr-lu",
+	0x0410: "it-it", 0x0810: "it-ch",
+	0x0413: "nl-nl", 0x0813: "nl-be",
+	0x0414: "nb", 0x0814: "nn",
+	0x0416: "pt-br", 0x0816: "pt-pt",
+	0x041a: "hr", 0x081a: "sr-latn", 0x0c1a: "sr-cyrl", 0x141a: "bs-latn", 0x201a: "bs-cyrl",
+	0x041d: "sv-se", 0x081d: "sv-fi",
+	0x042c: "az-latn", 0x082c: "az-cyrl",
+	0x0443: "uz-latn", 0x0843: "uz-cyrl",
+	0x0450: "mn-cyrl", 0x0850: "mn-mong",
+}
+
+// msPrimaryLanguages maps the Windows primary language IDs
+// (the low 10 bits of the LCID) to BCP 47 tags.
+var msPrimaryLanguages = map[PlatformLanguageID]language.Language{
+	0x01: "ar", 0x02: "bg", 0x03: "ca", 0x04: "zh", 0x05: "cs", 0x06: "da", 0x07: "de", 0x08: "el",
+	0x09: "en", 0x0a: "es", 0x0b: "fi", 0x0c: "fr", 0x0d: "he", 0x0e: "hu", 0x0f: "is", 0x10: "it",
+	0x11: "ja", 0x12: "ko", 0x13: "nl", 0x14: "no", 0x15: "pl", 0x16: "pt", 0x17: "rm", 0x18: "ro",
+	0x19: "ru", 0x1a: "hr", 0x1b: "sk", 0x1c: "sq", 0x1d: "sv", 0x1e: "th", 0x1f: "tr", 0x20: "ur",
+	0x21: "id", 0x22: "uk", 0x23: "be", 0x24: "sl", 0x25: "et", 0x26: "lv", 0x27: "lt", 0x28: "tg",
+	0x29: "fa", 0x2a: "vi", 0x2b: "hy", 0x2c: "az", 0x2d: "eu", 0x2e: "hsb", 0x2f: "mk", 0x32: "tn",
+	0x34: "xh", 0x35: "zu", 0x36: "af", 0x37: "ka", 0x38: "fo", 0x39: "hi", 0x3a: "mt", 0x3b: "se",
+	0x3c: "ga", 0x3e: "ms", 0x3f: "kk", 0x40: "ky", 0x41: "sw", 0x42: "tk", 0x43: "uz", 0x44: "tt",
+	0x45: "bn", 0x46: "pa", 0x47: "gu", 0x48: "or", 0x49: "ta", 0x4a: "te", 0x4b: "kn", 0x4c: "ml",
+	0x4d: "as", 0x4e: "mr", 0x4f: "sa", 0x50: "mn", 0x51: "bo", 0x52: "cy", 0x53: "km", 0x54: "lo",
+	0x56: "gl", 0x57: "kok", 0x5a: "syr", 0x5b: "si", 0x5d: "iu", 0x5e: "am", 0x5f: "tzm", 0x61: "ne",
+	0x62: "fy", 0x63: "ps", 0x64: "fil", 0x65: "dv", 0x68: "ha", 0x6a: "yo", 0x6b: "quz", 0x6c: "nso",
+	0x6d: "ba", 0x6e: "lb", 0x6f: "kl", 0x70: "ig", 0x78: "ii", 0x7a: "arn", 0x7c: "moh", 0x7e: "br",
+	0x80: "ug", 0x81: "mi", 0x82: "oc", 0x83: "co", 0x84: "gsw", 0x85: "sah", 0x86: "quc", 0x87: "rw",
+	0x88: "wo", 0x8c: "prs",
+}
+
+// macLanguages maps the Macintosh language codes to BCP 47 tags.
+var macLanguages = map[PlatformLanguageID]language.Language{
+	0: "en", 1: "fr", 2: "de", 3: "it", 4: "nl", 5: "sv", 6: "es", 7: "da", 8: "pt", 9: "no",
+	10: "he", 11: "ja", 12: "ar", 13: "fi", 14: "el", 15: "is", 16: "mt", 17: "tr", 18: "hr", 19: "zh-hant",
+	20: "ur", 21: "hi", 22: "th", 23: "ko", 24: "lt", 25: "pl", 26: "hu", 27: "et", 28: "lv", 29: "se",
+	30: "fo", 31: "fa", 32: "ru", 33: "zh-hans", 34: "nl-be", 35: "ga", 36: "sq", 37: "ro", 38: "cs", 39: "sk",
+	40: "sl", 41: "yi", 42: "sr", 43: "mk", 44: "bg", 45: "uk", 46: "be", 47: "uz", 48: "kk", 49: "az-cyrl",
+	50: "az-arab", 51: "hy", 52: "ka", 53: "mo", 54: "ky", 55: "tg", 56: "tk", 57: "mn-mong", 58: "mn-cyrl", 59: "ps",
+	60: "ku", 61: "ks", 62: "sd", 63: "bo", 64: "ne", 65: "sa", 66: "mr", 67: "bn", 68: "as", 69: "gu",
+	70: "pa", 71: "or", 72: "ml", 73: "kn", 74: "ta", 75: "te", 76: "si", 77: "my", 78: "km", 79: "lo",
+	80: "vi", 81: "id", 82: "tl", 83: "ms", 84: "ms-arab", 85: "am", 86: "ti", 87: "om", 88: "so", 89: "sw",
+	90: "rw", 91: "rn", 92: "ny", 93: "mg", 94: "eo",
+	128: "cy", 129: "eu", 130: "ca", 131: "la", 132: "qu", 133: "gn", 134: "ay", 135: "tt", 136: "ug", 137: "dz",
+	138: "jv", 139: "su", 140: "gl", 141: "af", 142: "br", 143: "iu", 144: "gd", 145: "gv", 146: "ga", 147: "to",
+	148: "el-polyton", 149: "kl", 150: "az-latn",
+}
+
+// platformLanguage returns the BCP 47 tag of a language ID,
+// or an empty string if it is unknown.
+func platformLanguage(platform PlatformID, id PlatformLanguageID) language.Language {
+	switch platform {
+	case PlatformMicrosoft:
+		if lang, ok := msLanguages[id]; ok {
+			return lang
+		}
+		return msPrimaryLanguages[id&0x3FF]
+	case PlatformMac:
+		return macLanguages[id]
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/boxesandglue/textlayout/language"
+)
+
+func TestNameLanguage(t *testing.T) {
+	font := loadFont(t, "FreeSerif.ttf")
+	for _, test := range []struct {
+		lang     language.Language
+		expected string
+	}{
+		{"", "Medium"},
+		{"en-us", "Medium"},
+		{"de", "Mittel"},
+		{"de-ch", "Mittel"},
+		{"ru-ru", "Обычный"},
+		{"pl", "odmiana zwykła"},
+		{"ja", "Medium"}, // English fallback
+	} {
+		if got := font.Names.Name(NameFontSubfamily, test.lang); got != test.expected {
+			t.Fatalf("language %s: expected %q, got %q", test.lang, test.expected, got)
+		}
+	}
+	if got := font.Names.Name(NameFontFamily, "fr"); got != "FreeSerif" {
+		t.Fatalf("unexpected family %q", got)
+	}
+	if got := font.Names.Name(NameWWSFamily, "fr"); got != "" {
+		t.Fatalf("unexpected name %q", got)
+	}
+}
+
+// utf16 encodes an ASCII string
+func utf16(s string) []byte {
+	out := make([]byte, 2*len(s))
+	for i, c := range []byte(s) {
+		out[2*i+1] = c
+	}
+	return out
+}
+
+func TestNameFormat1(t *testing.T) {
+	strings := append(append(utf16("Bold"), utf16("Gras")...), utf16("fr-CA")...)
+	const stringOffset = 6 + 2*12 + 2 + 4
+	data := make([]byte, stringOffset, stringOffset+len(strings))
+	binary.BigEndian.PutUint16(data, 1)
+	binary.BigEndian.PutUint16(data[2:], 2)
+	binary.BigEndian.PutUint16(data[4:], stringOffset)
+	for i, record := range [2][6]uint16{
+		{3, 1, 0x0409, 2, 8, 0},
+		{3, 1, 0x8000, 2, 8, 8},
+	} {
+		for j, v := range record {
+			binary.BigEndian.PutUint16(data[6+12*i+2*j:], v)
+		}
+	}
+	binary.BigEndian.PutUint16(data[30:], 1)  // lang tag count
+	binary.BigEndian.PutUint16(data[32:], 10) // length
+	binary.BigEndian.PutUint16(data[34:], 16) // offset
+	data = append(data, strings...)
+
+	names, err := parseTableName(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names[0].Language != "en-us" || names[1].Language != "fr-ca" {
+		t.Fatalf("unexpected languages %s %s", names[0].Language, names[1].Language)
+	}
+	if got := names.Name(NameFontSubfamily, "fr"); got != "Gras" {
+		t.Fatalf("unexpected name %q", got)
+	}
+	if got := names.Name(NameFontSubfamily, "de"); got != "Bold" {
+		t.Fatalf("unexpected name %q", got)
+	}
+
+	if _, err := parseTableName(data[:33]); err == nil {
+		t.Fatal("expected error for truncated language tags")
+	}
+}
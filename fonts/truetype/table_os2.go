@@ -92,11 +92,13 @@ func parseTableOS2(buf []byte) (*TableOS2, error) {
 	return &out, nil
 }
 
+// useTypoMetrics and hasData return false for a nil table (the table is optional)
+
 func (t *TableOS2) useTypoMetrics() bool {
 	const useTypoMetrics = 1 << 7
-	return t.FsSelection&useTypoMetrics != 0
+	return t != nil && t.FsSelection&useTypoMetrics != 0
 }
 
 func (t *TableOS2) hasData() bool {
-	return t.USWeightClass != 0 || t.USWidthClass != 0 || t.USFirstCharIndex != 0 || t.USLastCharIndex != 0
+	return t != nil && (t.USWeightClass != 0 || t.USWidthClass != 0 || t.USFirstCharIndex != 0 || t.USLastCharIndex != 0)
 }
@@ -54,6 +54,11 @@ func parseTablePost(buf []byte, numGlyphs uint16) (TablePost, error) {
 		if err != nil {
 			return TablePost{}, err
 		}
+	case 0x25000:
+		names, err = parseNameFormat25(buf, numGlyphs)
+		if err != nil {
+			return TablePost{}, err
+		}
 	default:
 		return TablePost{}, errUnsupportedPostTable
 	}
@@ -147,3 +152,32 @@ func parseNameFormat20(buf []byte, numGlyphs uint16) (postNamesFormat20, error)
 	}
 	return postNamesFormat20{glyphNameIndexes: glyphNameIndexes, names: names}, nil
 }
+
+// postNamesFormat25 stores the names as offsets into
+// the standard Macintosh ordering (deprecated format).
+type postNamesFormat25 []int8 // size numGlyph
+
+func (p postNamesFormat25) GlyphName(x GID) string {
+	if int(x) >= len(p) {
+		return ""
+	}
+	return builtInPostNames[int(x)+int(p[x])]
+}
+
+func parseNameFormat25(buf []byte, numGlyphs uint16) (postNamesFormat25, error) {
+	// https://developer.apple.com/fonts/TrueType-Reference-Manual/RM06/Chap6post.html
+	const offsetsStart = 34
+	if len(buf) < offsetsStart || binary.BigEndian.Uint16(buf[32:]) != numGlyphs ||
+		len(buf) < offsetsStart+int(numGlyphs) {
+		return nil, errInvalidPostTable
+	}
+	out := make(postNamesFormat25, numGlyphs)
+	for x := range out {
+		out[x] = int8(buf[offsetsStart+x])
+		// the resulting index must be a valid standard name
+		if index := x + int(out[x]); index < 0 || index >= numBuiltInPostNames {
+			return nil, errInvalidPostTable
+		}
+	}
+	return out, nil
+}
@@ -2,6 +2,7 @@ package truetype
 
 import (
 	"bytes"
+	"encoding/binary"
 	"testing"
 
 	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
@@ -50,3 +51,68 @@ func TestPost(t *testing.T) {
 		}
 	}
 }
+
+func TestPostFormat25(t *testing.T) {
+	buf := make([]byte, 34+3)
+	binary.BigEndian.PutUint32(buf, 0x25000)
+	binary.BigEndian.PutUint16(buf[32:], 3)
+	buf[34], buf[35], buf[36] = 0, 1, 0xFF // .notdef, nonmarkingreturn, .null
+	post, err := parseTablePost(buf, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gid, exp := range []string{".notdef", "nonmarkingreturn", ".null"} {
+		if name := post.Names.GlyphName(GID(gid)); name != exp {
+			t.Fatalf("glyph %d: expected %s, got %s", gid, exp, name)
+		}
+	}
+
+	buf[36] = 0xFD // negative index
+	if _, err := parseTablePost(buf, 3); err == nil {
+		t.Fatal("expected error for invalid offset")
+	}
+	if _, err := parseTablePost(buf[:35], 3); err == nil {
+		t.Fatal("expected error for truncated table")
+	}
+}
+
+func TestGlyphByName(t *testing.T) {
+	for _, file := range []string{
+		"FreeSerif.ttf",             // post names
+		"Raleway-v4020-Regular.otf", // CFF charset
+		"Roboto-BoldItalic.ttf",     // synthesized names
+	} {
+		font := loadFont(t, file)
+		for gid := 0; gid < font.NumGlyphs; gid++ {
+			name := font.GlyphName(GID(gid))
+			if name == "" {
+				t.Fatalf("%s: empty name for glyph %d", file, gid)
+			}
+			if g, ok := font.GlyphByName(name); !ok || font.GlyphName(g) != name {
+				t.Fatalf("%s: invalid glyph %d for name %s", file, g, name)
+			}
+		}
+		if name := font.GlyphName(GID(font.NumGlyphs)); name != "" {
+			t.Fatalf("%s: unexpected name %s for glyph out of range", file, name)
+		}
+		if _, ok := font.GlyphByName("gid100000"); ok {
+			t.Fatalf("%s: unexpected glyph out of range", file)
+		}
+
+		// names are resolved using the cmap as a fall back
+		exp, _ := font.NominalGlyph(0xE9)
+		for _, name := range []string{"uni00E9", "eacute"} {
+			if gid, ok := font.GlyphByName(name); !ok || gid != exp {
+				t.Fatalf("%s: expected glyph %d for %s, got %d", file, exp, name, gid)
+			}
+		}
+		if _, ok := font.GlyphByName("notAGlyphName.sc"); ok {
+			t.Fatalf("%s: unexpected glyph", file)
+		}
+	}
+
+	font := loadFont(t, "Roboto-BoldItalic.ttf")
+	if gid, _ := font.GlyphByName("gid12"); gid != 12 || font.GlyphName(12) != "gid12" {
+		t.Fatal("invalid synthesized names")
+	}
+}
@@ -0,0 +1,234 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// TableSTAT is the Style Attributes table, which describes the design axes
+// of a font family, and the names of the values along these axes.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/stat
+type TableSTAT struct {
+	Axes   []STATAxis
+	Values []STATAxisValue
+	// ElidedFallbackName is the name to use when all the
+	// axis values names are elided.
+	ElidedFallbackName NameID
+}
+
+// STATAxis is a design axis of a font family.
+type STATAxis struct {
+	Tag  Tag
+	Name NameID
+	// Ordering is used to sort the axis values names, when
+	// building a style name.
+	Ordering uint16
+}
+
+// AxisValueFlags are the flags of an axis value.
+type AxisValueFlags uint16
+
+const (
+	// OlderSiblingFontAttribute indicates that the value is provided by
+	// an older font of the family, and is only informative.
+	OlderSiblingFontAttribute AxisValueFlags = 1 << iota
+	// ElidableAxisValueName indicates that the name may be omitted
+	// when building a style name (such as "Regular" for the weight).
+	ElidableAxisValueName
+)
+
+// STATAxisLocation is a position on the axis at index AxisIndex
+// in the Axes slice.
+type STATAxisLocation struct {
+	AxisIndex uint16
+	Value     float32
+}
+
+// STATAxisValue associates a name to a position or a range on one
+// or several design axes.
+type STATAxisValue struct {
+	// Locations contains one location for the formats 1, 2 and 3,
+	// and the combination of locations for the format 4.
+	// For the format 2, the location is the nominal value of the range.
+	Locations []STATAxisLocation
+	// RangeMin and RangeMax are only used for the format 2.
+	RangeMin, RangeMax float32
+	// LinkedValue is only used for the format 3, and indicates the
+	// location of the style linked to this one (such as Bold for Regular).
+	LinkedValue float32
+	Format      uint16
+	Flags       AxisValueFlags
+	Name        NameID
+}
+
+// matches returns true if the value applies to the given coordinates,
+// indexed by axis tag. The axes without coordinates match any value.
+func (v STATAxisValue) matches(axes []STATAxis, coords map[Tag]float32) bool {
+	for _, loc := range v.Locations {
+		if int(loc.AxisIndex) >= len(axes) {
+			return false
+		}
+		coord, ok := coords[axes[loc.AxisIndex].Tag]
+		if !ok {
+			continue
+		}
+		if v.Format == 2 {
+			if coord < v.RangeMin || coord > v.RangeMax {
+				return false
+			}
+		} else if coord != loc.Value {
+			return false
+		}
+	}
+	return len(v.Locations) != 0
+}
+
+// selectValues returns the values describing the style at `coords`,
+// at most one for each axis, sorted by axis ordering.
+// The values combining several axes (format 4) are preferred.
+func (t TableSTAT) selectValues(coords map[Tag]float32) []STATAxisValue {
+	var (
+		selected []STATAxisValue
+		orders   []uint16
+		used     = make(map[uint16]bool)
+	)
+	for _, multiAxis := range [2]bool{true, false} {
+	values:
+		for _, value := range t.Values {
+			if (value.Format == 4) != multiAxis || value.Flags&OlderSiblingFontAttribute != 0 ||
+				!value.matches(t.Axes, coords) {
+				continue
+			}
+			order := uint16(0xFFFF)
+			for _, loc := range value.Locations {
+				if used[loc.AxisIndex] {
+					continue values
+				}
+				if o := t.Axes[loc.AxisIndex].Ordering; o < order {
+					order = o
+				}
+			}
+			for _, loc := range value.Locations {
+				used[loc.AxisIndex] = true
+			}
+			selected = append(selected, value)
+			orders = append(orders, order)
+		}
+	}
+
+	sort.Stable(byOrdering{selected, orders})
+	return selected
+}
+
+type byOrdering struct {
+	values []STATAxisValue
+	orders []uint16
+}
+
+func (b byOrdering) Len() int           { return len(b.values) }
+func (b byOrdering) Less(i, j int) bool { return b.orders[i] < b.orders[j] }
+func (b byOrdering) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+	b.orders[i], b.orders[j] = b.orders[j], b.orders[i]
+}
+
+func parseTableSTAT(data []byte) (out TableSTAT, err error) {
+	if len(data) < 18 {
+		return out, errors.New("invalid 'STAT' table (EOF)")
+	}
+	minorVersion := binary.BigEndian.Uint16(data[2:])
+	axisSize := int(binary.BigEndian.Uint16(data[4:]))
+	axisCount := int(binary.BigEndian.Uint16(data[6:]))
+	axesOffset := int(binary.BigEndian.Uint32(data[8:]))
+	valueCount := int(binary.BigEndian.Uint16(data[12:]))
+	valuesOffset := int(binary.BigEndian.Uint32(data[14:]))
+
+	// version 1.0 has no elided fallback name
+	out.ElidedFallbackName = NameFontSubfamily
+	if minorVersion >= 1 {
+		if len(data) < 20 {
+			return out, errors.New("invalid 'STAT' table (EOF)")
+		}
+		out.ElidedFallbackName = NameID(binary.BigEndian.Uint16(data[18:]))
+	}
+
+	if axisCount != 0 {
+		if axisSize < 8 || len(data) < axesOffset+axisCount*axisSize {
+			return out, errors.New("invalid 'STAT' table design axes (EOF)")
+		}
+		out.Axes = make([]STATAxis, axisCount)
+		for i := range out.Axes {
+			record := data[axesOffset+i*axisSize:]
+			out.Axes[i].Tag = Tag(binary.BigEndian.Uint32(record))
+			out.Axes[i].Name = NameID(binary.BigEndian.Uint16(record[4:]))
+			out.Axes[i].Ordering = binary.BigEndian.Uint16(record[6:])
+		}
+	}
+
+	if valueCount != 0 {
+		if len(data) < valuesOffset+2*valueCount {
+			return out, errors.New("invalid 'STAT' table axis values (EOF)")
+		}
+		offsets := data[valuesOffset:]
+		out.Values = make([]STATAxisValue, valueCount)
+		for i := range out.Values {
+			offset := int(binary.BigEndian.Uint16(offsets[2*i:]))
+			out.Values[i], err = parseSTATAxisValue(offsets, offset)
+			if err != nil {
+				return out, err
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func parseSTATAxisValue(data []byte, offset int) (out STATAxisValue, err error) {
+	if len(data) < offset+8 {
+		return out, errors.New("invalid 'STAT' axis value (EOF)")
+	}
+	data = data[offset:]
+	out.Format = binary.BigEndian.Uint16(data)
+	out.Flags = AxisValueFlags(binary.BigEndian.Uint16(data[4:]))
+	out.Name = NameID(binary.BigEndian.Uint16(data[6:]))
+
+	var size int
+	switch out.Format {
+	case 1:
+		size = 12
+	case 2:
+		size = 20
+	case 3:
+		size = 16
+	case 4:
+		count := int(binary.BigEndian.Uint16(data[2:]))
+		if len(data) < 8+6*count {
+			return out, errors.New("invalid 'STAT' axis value format 4 (EOF)")
+		}
+		out.Locations = make([]STATAxisLocation, count)
+		for i := range out.Locations {
+			out.Locations[i].AxisIndex = binary.BigEndian.Uint16(data[8+6*i:])
+			out.Locations[i].Value = fixed1616ToFloat(binary.BigEndian.Uint32(data[8+6*i+2:]))
+		}
+		return out, nil
+	default: // unknown formats must be ignored
+		return out, nil
+	}
+
+	if len(data) < size {
+		return out, fmt.Errorf("invalid 'STAT' axis value format %d (EOF)", out.Format)
+	}
+	out.Locations = []STATAxisLocation{{
+		AxisIndex: binary.BigEndian.Uint16(data[2:]),
+		Value:     fixed1616ToFloat(binary.BigEndian.Uint32(data[8:])),
+	}}
+	if out.Format == 2 {
+		out.RangeMin = fixed1616ToFloat(binary.BigEndian.Uint32(data[12:]))
+		out.RangeMax = fixed1616ToFloat(binary.BigEndian.Uint32(data[16:]))
+	} else if out.Format == 3 {
+		out.LinkedValue = fixed1616ToFloat(binary.BigEndian.Uint32(data[12:]))
+	}
+	return out, nil
+}
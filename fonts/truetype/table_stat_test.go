@@ -0,0 +1,131 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestParseSTAT(t *testing.T) {
+	for _, filename := range []string{
+		"Commissioner-VF.ttf", "SourceSansVariable-Roman.modcomp.ttf", "SelawikVar.ttf",
+		"Estedad-VF.ttf", "Comfortaa-i.ttf", "NotoSansArabic.ttf",
+	} {
+		font := loadFont(t, filename)
+		if len(font.stat.Axes) == 0 {
+			t.Fatalf("%s: missing STAT axes", filename)
+		}
+		for _, value := range font.stat.Values {
+			for _, loc := range value.Locations {
+				if int(loc.AxisIndex) >= len(font.stat.Axes) {
+					t.Fatalf("%s: invalid axis index %d", filename, loc.AxisIndex)
+				}
+			}
+		}
+	}
+
+	font := loadFont(t, "Commissioner-VF.ttf")
+	if len(font.stat.Axes) != 4 || len(font.stat.Values) != 15 {
+		t.Fatalf("unexpected STAT table %v", font.stat)
+	}
+	if v := font.stat.Values[12]; v.Format != 4 || len(v.Locations) != 2 || v.Flags != ElidableAxisValueName {
+		t.Fatalf("unexpected format 4 value %v", v)
+	}
+	if v := font.stat.Values[9]; v.Format != 3 || v.LinkedValue != 700 {
+		t.Fatalf("unexpected format 3 value %v", v)
+	}
+
+	if _, err := parseTableSTAT(make([]byte, 10)); err == nil {
+		t.Fatal("expected error for truncated table")
+	}
+}
+
+func TestSTATStyleName(t *testing.T) {
+	font := loadFont(t, "Commissioner-VF.ttf")
+	wght, slnt, flar := MustNewTag("wght"), MustNewTag("slnt"), MustNewTag("FLAR")
+
+	for _, test := range []struct {
+		coords []Variation
+		names  []string
+		style  string
+	}{
+		{nil, []string{"Thin", "Upright", "Normal"}, "Thin"},
+		{[]Variation{{wght, 400}}, []string{"Regular", "Upright", "Normal"}, "Regular"},
+		{[]Variation{{wght, 420}, {slnt, -12}}, []string{"Regular", "Italic", "Normal"}, "Italic"},
+		{[]Variation{{wght, 700}, {slnt, -12}}, []string{"Bold", "Italic", "Normal"}, "Bold Italic"},
+		// the format 4 value is used for FLAR and VOLM
+		{[]Variation{{wght, 600}, {flar, 100}}, []string{"SemiBold", "Upright", "Flair"}, "SemiBold Flair"},
+	} {
+		if names := font.AxisValueNames(test.coords); !reflect.DeepEqual(names, test.names) {
+			t.Fatalf("%v: expected %v, got %v", test.coords, test.names, names)
+		}
+		if style := font.STATStyleName(test.coords); style != test.style {
+			t.Fatalf("%v: expected %q, got %q", test.coords, test.style, style)
+		}
+	}
+
+	summary, err := font.LoadSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Family != "Commissioner" || summary.Style != "Thin" {
+		t.Fatalf("unexpected summary %v", summary)
+	}
+
+	// non-variable font: the values describe the font
+	font = loadFont(t, "Comfortaa-i.ttf")
+	if names := font.AxisValueNames(nil); !reflect.DeepEqual(names, []string{"Regular"}) {
+		t.Fatalf("unexpected names %v", names)
+	}
+	if style := font.STATStyleName(nil); style != "Regular" {
+		t.Fatalf("unexpected style %q", style)
+	}
+
+	// no STAT table
+	font = loadFont(t, "Roboto-BoldItalic.ttf")
+	if style := font.STATStyleName(nil); style != "" {
+		t.Fatalf("unexpected style %q", style)
+	}
+}
+
+func TestSTATOlderSibling(t *testing.T) {
+	stat := TableSTAT{
+		Axes: []STATAxis{{Tag: MustNewTag("wght"), Ordering: 1}, {Tag: MustNewTag("wdth"), Ordering: 0}},
+		Values: []STATAxisValue{
+			{Format: 1, Locations: []STATAxisLocation{{0, 700}}, Flags: OlderSiblingFontAttribute, Name: 256},
+			{Format: 1, Locations: []STATAxisLocation{{0, 700}}, Name: 257},
+			{Format: 2, Locations: []STATAxisLocation{{1, 75}}, RangeMin: 70, RangeMax: 80, Name: 258},
+		},
+	}
+	coords := map[Tag]float32{MustNewTag("wght"): 700, MustNewTag("wdth"): 72}
+	values := stat.selectValues(coords)
+	if len(values) != 2 || values[0].Name != 258 || values[1].Name != 257 {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	// version 1.0, with a format 1 axis value
+	data := make([]byte, 18+8+2+12)
+	binary.BigEndian.PutUint16(data, 1)
+	binary.BigEndian.PutUint16(data[4:], 8)
+	binary.BigEndian.PutUint16(data[6:], 1)
+	binary.BigEndian.PutUint32(data[8:], 18)
+	binary.BigEndian.PutUint16(data[12:], 1)
+	binary.BigEndian.PutUint32(data[14:], 26)
+	binary.BigEndian.PutUint32(data[18:], uint32(MustNewTag("wght")))
+	binary.BigEndian.PutUint16(data[28:], 1)   // format
+	binary.BigEndian.PutUint16(data[34:], 300) // name
+	binary.BigEndian.PutUint32(data[36:], 400<<16)
+	binary.BigEndian.PutUint16(data[26:], 2) // offset
+	parsed, err := parseTableSTAT(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := TableSTAT{
+		Axes:               []STATAxis{{Tag: MustNewTag("wght")}},
+		Values:             []STATAxisValue{{Format: 1, Locations: []STATAxisLocation{{0, 400}}, Name: 300}},
+		ElidedFallbackName: NameFontSubfamily,
+	}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Fatalf("expected %v, got %v", expected, parsed)
+	}
+}
@@ -18,23 +18,15 @@ var tagSVG = NewTag('S', 'V', 'G', ' ')
 type tableSVG []svgDocumentIndexEntry
 
 func (s tableSVG) glyphData(gid GID) (fonts.GlyphSVG, bool) {
-	data, ok := s.rawGlyphData(gid)
+	entry, ok := s.entry(gid)
 	if !ok {
 		return fonts.GlyphSVG{}, false
 	}
-
-	// un-compress if needed
-	if r, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
-		var buf bytes.Buffer
-		if _, err := io.Copy(&buf, r); err == nil {
-			data = buf.Bytes()
-		}
-	}
-
-	return fonts.GlyphSVG{Source: data}, true
+	return fonts.GlyphSVG{Source: entry.document()}, true
 }
 
-func (s tableSVG) rawGlyphData(gid GID) ([]byte, bool) {
+// entry returns the document index entry containing `gid`
+func (s tableSVG) entry(gid GID) (svgDocumentIndexEntry, bool) {
 	// binary search
 	for i, j := 0, len(s); i < j; {
 		h := i + (j-i)/2
@@ -44,10 +36,34 @@ func (s tableSVG) rawGlyphData(gid GID) ([]byte, bool) {
 		} else if GID(entry.last) < gid {
 			i = h + 1
 		} else {
-			return entry.svg, true
+			return entry, true
 		}
 	}
-	return nil, false
+	return svgDocumentIndexEntry{}, false
+}
+
+// GlyphSVG returns the SVG document describing the glyph `gid`,
+// decompressed if needed, or false if the font has no SVG description for it.
+// The actual glyph description is the element with id="glyph<gid>"
+// (as in id="glyph12"), since a document may be shared by several glyphs
+// (see SVGGlyphRange).
+func (font *Font) GlyphSVG(gid GID) ([]byte, bool) {
+	entry, ok := font.svg.entry(gid)
+	if !ok {
+		return nil, false
+	}
+	return entry.document(), true
+}
+
+// SVGGlyphRange returns the range of glyphs, from `first` to `last` (included),
+// described by the same SVG document as `gid`, or false if the font
+// has no SVG description for `gid`.
+func (font *Font) SVGGlyphRange(gid GID) (first, last GID, ok bool) {
+	entry, ok := font.svg.entry(gid)
+	if !ok {
+		return 0, 0, false
+	}
+	return GID(entry.first), GID(entry.last), true
 }
 
 type svgDocumentIndexEntry struct {
@@ -59,6 +75,18 @@ type svgDocumentIndexEntry struct {
 	last  gid // The last glyph ID in the range described by this index entry. Must be >= startGlyphID.
 }
 
+// document returns the SVG document, un-compressed if needed
+func (e svgDocumentIndexEntry) document() []byte {
+	data := e.svg
+	if r, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, r); err == nil {
+			data = buf.Bytes()
+		}
+	}
+	return data
+}
+
 func parseTableSVG(buf []byte) (tableSVG, error) {
 	if len(buf) < 6 {
 		return nil, errors.New("invalid SVG table (EOF)")
@@ -50,3 +50,21 @@ func TestGlyphDataSVG(t *testing.T) {
 		t.Fatalf("unexpected glyph data %v", data)
 	}
 }
+
+func TestGlyphSVG(t *testing.T) {
+	font := loadFont(t, "chromacheck-svg.ttf")
+	if _, ok := font.GlyphSVG(0); ok {
+		t.Fatal("unexpected svg data")
+	}
+	doc, ok := font.GlyphSVG(1)
+	if !ok || !strings.Contains(string(doc), `id="glyph1"`) {
+		t.Fatalf("unexpected svg data %s", doc)
+	}
+	first, last, ok := font.SVGGlyphRange(1)
+	if !ok || first > 1 || last < 1 {
+		t.Fatalf("unexpected range %d %d", first, last)
+	}
+	if _, _, ok := font.SVGGlyphRange(0); ok {
+		t.Fatal("unexpected range")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 )
 
 func fixed1616ToFloat(fi uint32) float32 {
@@ -97,39 +98,111 @@ func parseOneVarInstance(data []byte, axisCount uint16, withPs bool) VarInstance
 
 // -------------------------- avar table --------------------------
 
-// one segment map for each axis, in the order of axes specified in the 'fvar' table.
-type tableAvar [][]axisValueMap
+type tableAvar struct {
+	// one segment map for each axis, in the order of axes specified in the 'fvar' table.
+	segments [][]axisValueMap
+
+	// version 2 only: the deltas applied to the
+	// coordinates mapped by the segments, which may depend on all the axes
+	axisIndexMap deltaSetMapping
+	store        VariationStore
+	isVersion2   bool
+}
 
 type axisValueMap struct {
 	from, to float32 // found as int16 2.14 fixed point
 }
 
-func parseTableAvar(data []byte, axisCountRef int) (tableAvar, error) {
+func parseTableAvar(data []byte, axisCountRef int) (out tableAvar, err error) {
 	const avarHeaderSize = 2 * 4
 	if len(data) < avarHeaderSize {
-		return nil, errors.New("invalid 'avar' table (EOF)")
+		return out, errors.New("invalid 'avar' table (EOF)")
 	}
-	// table.majorVersion = binary.BigEndian.Uint16(data)
+	majorVersion := binary.BigEndian.Uint16(data)
 	// table.minorVersion = binary.BigEndian.Uint16(data[2:])
 	// reserved
 	axisCount := binary.BigEndian.Uint16(data[6:])
-	out := make([][]axisValueMap, axisCount) // guarded by 16-bit constraint
 
 	if int(axisCount) != axisCountRef {
-		return nil, errors.New("invalid 'avar' table axis count")
+		return out, errors.New("invalid 'avar' table axis count")
 	}
 
-	var err error
-	data = data[avarHeaderSize:] // start at the first segment list
-	for i := range out {
-		out[i], data, err = parseSegmentList(data)
+	out.segments = make([][]axisValueMap, axisCount) // guarded by 16-bit constraint
+	segments := data[avarHeaderSize:]                // start at the first segment list
+	for i := range out.segments {
+		out.segments[i], segments, err = parseSegmentList(segments)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	if majorVersion < 2 {
+		return out, nil
+	}
+
+	// version 2: offsets are relative to the beginning of the table
+	out.isVersion2 = true
+	if len(segments) < 8 {
+		return out, errors.New("invalid 'avar' table version 2 (EOF)")
+	}
+	axisIndexMapOffset := binary.BigEndian.Uint32(segments)
+	varStoreOffset := binary.BigEndian.Uint32(segments[4:])
+	if axisIndexMapOffset != 0 {
+		out.axisIndexMap, err = parseDeltaSetMapping(data, axisIndexMapOffset)
+		if err != nil {
+			return out, err
+		}
+	}
+	if varStoreOffset != 0 {
+		out.store, err = parseVariationStore(data, varStoreOffset, axisCountRef)
 		if err != nil {
-			return nil, err
+			return out, err
 		}
 	}
 	return out, nil
 }
 
+// apply maps the normalized `coords` in place.
+func (t tableAvar) apply(coords []float32) {
+	for i, av := range t.segments {
+		if i >= len(coords) {
+			break
+		}
+		for j := 1; j < len(av); j++ {
+			previous, pair := av[j-1], av[j]
+			if coords[i] < pair.from {
+				coords[i] =
+					previous.to + (coords[i]-previous.from)*
+						(pair.to-previous.to)/(pair.from-previous.from)
+				break
+			}
+		}
+	}
+
+	if !t.isVersion2 || len(t.store.Datas) == 0 {
+		return
+	}
+
+	// the deltas are computed with the coordinates mapped by the segments,
+	// rounded to 2.14 fixed point, as HarfBuzz does
+	mapped := make([]float32, len(coords))
+	for i, c := range coords {
+		mapped[i] = float32(math.Round(float64(c)*(1<<14))) / (1 << 14)
+	}
+	for i := range coords {
+		var index VariationStoreIndex
+		if len(t.axisIndexMap) != 0 {
+			index = t.axisIndexMap.getIndex(GID(i))
+		} else { // implicit mapping
+			index = VariationStoreIndex{DeltaSetInner: uint16(i)}
+		}
+		delta := t.store.GetDelta(index, mapped)
+		v := math.Round(float64(mapped[i])*(1<<14)) + math.Round(float64(delta))
+		v = math.Max(-(1 << 14), math.Min(1<<14, v))
+		coords[i] = float32(v) / (1 << 14)
+	}
+}
+
 // data is at the start of the segment, return value at the start of the next
 func parseSegmentList(data []byte) ([]axisValueMap, []byte, error) {
 	const mapSize = 4
@@ -366,6 +439,7 @@ type tableHVvar struct {
 	// optional
 	advances         deltaSetMapping
 	leftSideBearings deltaSetMapping
+	origins          deltaSetMapping // only for VVAR, used with VORG
 }
 
 func (t tableHVvar) getAdvanceVar(glyph GID, coords []float32) float32 {
@@ -381,7 +455,19 @@ func (t tableHVvar) getSideBearingVar(glyph GID, coords []float32) float32 {
 	return t.store.GetDelta(index, coords)
 }
 
-func parseTableHVvar(data []byte, axisCount int) (out tableHVvar, err error) {
+// getOriginVar returns the variation of the vertical origin
+// provided by the 'VORG' table, or 0 if not supported.
+func (t tableHVvar) getOriginVar(glyph GID, coords []float32) float32 {
+	if t.origins == nil {
+		return 0
+	}
+	index := t.origins.getIndex(glyph)
+	return t.store.GetDelta(index, coords)
+}
+
+// parseTableHVvar parses a 'HVAR' or 'VVAR' table, `isVertical`
+// enabling the vertical origin mapping.
+func parseTableHVvar(data []byte, axisCount int, isVertical bool) (out tableHVvar, err error) {
 	if len(data) < 20 {
 		return out, errors.New("invalid metrics variation table (EOF)")
 	}
@@ -406,6 +492,15 @@ func parseTableHVvar(data []byte, axisCount int) (out tableHVvar, err error) {
 	}
 	// we don't use the right side bearings
 
+	if isVertical && len(data) >= 24 {
+		if originOffset := binary.BigEndian.Uint32(data[20:]); originOffset != 0 {
+			out.origins, err = parseDeltaSetMapping(data, originOffset)
+			if err != nil {
+				return out, err
+			}
+		}
+	}
+
 	return out, nil
 }
 
@@ -431,12 +526,19 @@ func parseDeltaSetMapping(data []byte, offset uint32) (deltaSetMapping, error) {
 	if len(data) < int(offset)+4 {
 		return nil, errors.New("invalid delta-set mapping (EOF)")
 	}
-	format := binary.BigEndian.Uint16(data[offset:])
+	format, entryFormat := data[offset], data[offset+1]
 	count := int(binary.BigEndian.Uint16(data[offset+2:]))
 	data = data[offset+4:]
+	if format == 1 { // 32-bit count
+		if len(data) < 2 {
+			return nil, errors.New("invalid delta-set mapping (EOF)")
+		}
+		count = count<<16 | int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+	}
 
-	entrySize := int((format&0x0030)>>4 + 1)
-	innerBitSize := format&0x0F + 1
+	entrySize := int((entryFormat&0x30)>>4 + 1)
+	innerBitSize := entryFormat&0x0F + 1
 	if entrySize > 4 || len(data) < entrySize*count {
 		return nil, errors.New("invalid delta-set mapping (EOF)")
 	}
@@ -521,3 +521,131 @@ func TestNormalize(t *testing.T) {
 		t.Fatalf("expected %v, got %v", exp, coords)
 	}
 }
+
+func TestParseDeltaSetMapping(t *testing.T) {
+	// format 1 (32-bit count), 2 bytes entries with 4 bits inner indices
+	data := []byte{1, 0x13, 0, 0, 0, 2, 0x00, 0x12, 0x01, 0x05}
+	m, err := parseDeltaSetMapping(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := deltaSetMapping{{DeltaSetOuter: 1, DeltaSetInner: 2}, {DeltaSetOuter: 0x10, DeltaSetInner: 5}}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+	if _, err = parseDeltaSetMapping(data[:8], 0); err == nil {
+		t.Fatal("expected error for truncated mapping")
+	}
+}
+
+// a VVAR table with one axis, varying the vertical origin of glyph 0 by 100 units
+var vvarOrigins = []byte{
+	0, 1, 0, 0, 0, 0, 0, 24, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 55, // header
+	0, 1, 0, 0, 0, 12, 0, 1, 0, 0, 0, 22, // variation store
+	0, 1, 0, 1, 0, 0, 0x40, 0, 0x40, 0, // regions
+	0, 1, 0, 0, 0, 1, 0, 0, 100, // deltas
+	0, 0, 0, 1, 0, // origins mapping
+}
+
+func TestParseVVARVorg(t *testing.T) {
+	vvar, err := parseTableHVvar(vvarOrigins, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vvar.getOriginVar(0, []float32{0.5}); got != 50 {
+		t.Fatalf("expected origin delta 50, got %g", got)
+	}
+
+	font := Font{
+		fvar:      TableFvar{Axis: make([]VarAxis, 1)},
+		varCoords: []float32{0.5},
+		hvar:      &tableHVvar{store: vvar.store},
+		vvar:      &vvar,
+		vorg:      &tableVorg{defaultOrigin: 800},
+	}
+	if x, y, ok := font.GlyphVOrigin(0); !ok || x != 25 || y != 850 {
+		t.Fatalf("unexpected vertical origin (%d, %d)", x, y)
+	}
+
+	hvar, err := parseTableHVvar(vvarOrigins, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hvar.origins != nil {
+		t.Fatal("unexpected origins mapping for HVAR")
+	}
+}
+
+func TestNamedInstances(t *testing.T) {
+	font := loadFont(t, "SourceSansVariable-Roman.modcomp.ttf")
+	instances := font.NamedInstances()
+	if len(instances) != 6 {
+		t.Fatalf("expected 6 instances, got %d", len(instances))
+	}
+	expected := NamedInstance{Name: "Semibold", PostScriptName: "SourceSansRoman-Semibold", Coords: []float32{600}}
+	if !reflect.DeepEqual(instances[3], expected) {
+		t.Fatalf("expected %v, got %v", expected, instances[3])
+	}
+
+	if !font.SetNamedInstance(3) {
+		t.Fatal("invalid instance index")
+	}
+	if exp := font.NormalizeVariations([]float32{600}); !reflect.DeepEqual(font.VarCoordinates(), exp) {
+		t.Fatalf("expected coordinates %v, got %v", exp, font.VarCoordinates())
+	}
+	if font.SetNamedInstance(6) || font.SetNamedInstance(-1) {
+		t.Fatal("expected invalid instance index")
+	}
+
+	if instances := loadFont(t, "Roboto-BoldItalic.ttf").NamedInstances(); instances != nil {
+		t.Fatalf("unexpected instances %v", instances)
+	}
+}
+
+// an 'avar' version 2 table with two axes: the first one has a segment map,
+// and the second one is shifted by 0.25 at the maximum of the first axis
+var avar2 = []byte{
+	0, 2, 0, 0, 0, 0, 0, 2, // header
+	0, 4, 0xC0, 0, 0xC0, 0, 0, 0, 0, 0, 0x20, 0, 0x30, 0, 0x40, 0, 0x40, 0, // segments for axis 0
+	0, 0, // segments for axis 1
+	0, 0, 0, 0, 0, 0, 0, 36, // axis index map and variation store offsets
+	0, 1, 0, 0, 0, 12, 0, 1, 0, 0, 0, 28, // variation store
+	0, 2, 0, 1, 0, 0, 0x40, 0, 0x40, 0, 0, 0, 0, 0, 0, 0, // regions
+	0, 2, 0, 1, 0, 1, 0, 0, 0, 0, 0x10, 0, // deltas
+}
+
+func TestAvar2(t *testing.T) {
+	avar, err := parseTableAvar(avar2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !avar.isVersion2 || len(avar.segments[0]) != 4 || len(avar.store.Datas) != 1 {
+		t.Fatalf("unexpected avar table %v", avar)
+	}
+
+	font := Font{
+		fvar: TableFvar{Axis: []VarAxis{{Maximum: 100}, {Maximum: 100}}},
+		avar: avar,
+	}
+	for _, test := range []struct {
+		design, expected []float32
+	}{
+		{[]float32{0, 0}, []float32{0, 0}},
+		// the delta uses the coordinates mapped by the segments
+		{[]float32{50, 0}, []float32{0.75, 0.1875}},
+		{[]float32{100, 0}, []float32{1, 0.25}},
+		{[]float32{0, 100}, []float32{0, 1}},
+		{[]float32{100, 100}, []float32{1, 1}}, // clamped
+	} {
+		if got := font.NormalizeVariations(test.design); !reflect.DeepEqual(got, test.expected) {
+			t.Fatalf("for %v, expected %v, got %v", test.design, test.expected, got)
+		}
+	}
+
+	if _, err = parseTableAvar(avar2[:32], 2); err == nil {
+		t.Fatal("expected error for truncated table")
+	}
+	if _, err = parseTableAvar(avar2[:40], 2); err == nil {
+		t.Fatal("expected error for truncated variation store")
+	}
+}
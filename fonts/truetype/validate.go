@@ -0,0 +1,259 @@
+package truetype
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// ValidationIssue identifies the kind of problem reported by Validate.
+type ValidationIssue uint8
+
+const (
+	// InvalidChecksum is reported when the checksum of a table does not
+	// match the one stored in the table directory.
+	InvalidChecksum ValidationIssue = iota + 1
+	// InvalidLoca is reported when the 'loca' offsets are decreasing,
+	// or point outside of the 'glyf' table.
+	InvalidLoca
+	// InvalidGlyph is reported when a glyph description can't be parsed.
+	InvalidGlyph
+	// GlyphOutOfBounds is reported when the bounding box of a glyph
+	// is not included in the font bounding box given by the 'head' table.
+	GlyphOutOfBounds
+	// InvalidHmtx is reported when the number of metrics of the 'hhea' table
+	// does not match the number of glyphs, or the length of the 'hmtx' table.
+	InvalidHmtx
+	// InvalidCmap is reported when the 'cmap' table can't be parsed, has no
+	// Unicode subtable, or maps runes to glyphs out of range.
+	InvalidCmap
+	// InvalidTableRecord is reported when a record of the table directory
+	// has been fixed in salvage mode (see ParseOptions).
+	InvalidTableRecord
+)
+
+func (v ValidationIssue) String() string {
+	switch v {
+	case InvalidChecksum:
+		return "invalid checksum"
+	case InvalidLoca:
+		return "invalid loca"
+	case InvalidGlyph:
+		return "invalid glyph"
+	case GlyphOutOfBounds:
+		return "glyph out of bounds"
+	case InvalidHmtx:
+		return "invalid hmtx"
+	case InvalidCmap:
+		return "invalid cmap"
+	case InvalidTableRecord:
+		return "invalid table record"
+	default:
+		return fmt.Sprintf("<unknown issue %d>", v)
+	}
+}
+
+// ValidationWarning is a problem found by Validate. Such problems
+// usually do not prevent the font from being used, but indicate a
+// damaged file or may produce incorrect rendering.
+type ValidationWarning struct {
+	Message string
+	Table   Tag
+	Issue   ValidationIssue
+	Glyph   GID // only valid for the issues related to one glyph
+}
+
+func (w ValidationWarning) String() string {
+	if w.Table == 0 { // not related to a table
+		return fmt.Sprintf("%s (%s)", w.Message, w.Issue)
+	}
+	return fmt.Sprintf("%s: %s (%s)", w.Table, w.Message, w.Issue)
+}
+
+// Validate checks the structure of the font, and returns the problems found,
+// which are often silently ignored when loading the font:
+//   - the table records fixed in salvage mode (see ParseOptions)
+//   - the table checksums (not available for WOFF2 files)
+//   - the monotonicity of the 'loca' offsets
+//   - the glyph bounding boxes against the 'head' font bounding box
+//   - the length of the 'hmtx' table against the number of glyphs
+//   - the coverage of the 'cmap' table
+//
+// An error is only returned if the tables required to perform
+// the checks ('head' and 'maxp') are invalid.
+func (pr *FontParser) Validate() ([]ValidationWarning, error) {
+	head, err := pr.loadHeadTable()
+	if err != nil {
+		return nil, err
+	}
+	numGlyphs, err := pr.NumGlyphs()
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]ValidationWarning(nil), pr.warnings...)
+	out = append(out, pr.validateChecksums()...)
+	out = append(out, pr.validateGlyf(head, numGlyphs)...)
+	out = append(out, pr.validateHmtx(numGlyphs)...)
+	out = append(out, pr.validateCmap(numGlyphs)...)
+	return out, nil
+}
+
+func (pr *FontParser) validateChecksums() []ValidationWarning {
+	// use a deterministic order
+	tags := make([]Tag, 0, len(pr.tables))
+	for tag := range pr.tables {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	var out []ValidationWarning
+	for _, tag := range tags {
+		section := pr.tables[tag]
+		if !section.hasChecksum {
+			continue
+		}
+		data, err := pr.GetRawTable(tag)
+		if err != nil {
+			out = append(out, ValidationWarning{
+				Table: tag, Issue: InvalidChecksum,
+				Message: fmt.Sprintf("table can't be read: %s", err),
+			})
+			continue
+		}
+		if sum := tableChecksum(tag, data); sum != section.checksum {
+			out = append(out, ValidationWarning{
+				Table: tag, Issue: InvalidChecksum,
+				Message: fmt.Sprintf("checksum is 0x%08x, expected 0x%08x", sum, section.checksum),
+			})
+		}
+	}
+	return out
+}
+
+// tableChecksum returns the checksum of `data`, padded with zeros,
+// ignoring the checkSumAdjustment field of the 'head' table.
+func tableChecksum(tag Tag, data []byte) uint32 {
+	padded := make([]byte, (len(data)+3)&^3)
+	copy(padded, data)
+	if (tag == tagHead || tag == tagBhed) && len(padded) >= 12 {
+		padded[8], padded[9], padded[10], padded[11] = 0, 0, 0, 0
+	}
+	return calcChecksum(padded)
+}
+
+func (pr *FontParser) validateGlyf(head TableHead, numGlyphs int) []ValidationWarning {
+	if !pr.HasTable(tagGlyf) {
+		return nil
+	}
+	var out []ValidationWarning
+	locaData, err := pr.GetRawTable(tagLoca)
+	if err != nil {
+		return append(out, ValidationWarning{Table: tagLoca, Issue: InvalidLoca, Message: err.Error()})
+	}
+	loca, err := parseTableLoca(locaData, numGlyphs, head.indexToLocFormat == 1)
+	if err != nil {
+		return append(out, ValidationWarning{Table: tagLoca, Issue: InvalidLoca, Message: err.Error()})
+	}
+	glyf, err := pr.GetRawTable(tagGlyf)
+	if err != nil {
+		return append(out, ValidationWarning{Table: tagGlyf, Issue: InvalidGlyph, Message: err.Error()})
+	}
+
+	for i := 0; i < numGlyphs; i++ {
+		start, end := loca[i], loca[i+1]
+		if end < start {
+			out = append(out, ValidationWarning{
+				Table: tagLoca, Issue: InvalidLoca, Glyph: GID(i),
+				Message: fmt.Sprintf("decreasing offsets %d -> %d for glyph %d", start, end, i),
+			})
+			continue
+		}
+		if int(end) > len(glyf) {
+			out = append(out, ValidationWarning{
+				Table: tagLoca, Issue: InvalidLoca, Glyph: GID(i),
+				Message: fmt.Sprintf("offset %d for glyph %d exceeds the 'glyf' length %d", end, i, len(glyf)),
+			})
+			continue
+		}
+		if start == end { // empty glyph
+			continue
+		}
+
+		glyph, err := parseGlyphData(glyf[:end], start)
+		if err != nil {
+			out = append(out, ValidationWarning{
+				Table: tagGlyf, Issue: InvalidGlyph, Glyph: GID(i),
+				Message: fmt.Sprintf("glyph %d: %s", i, err),
+			})
+			continue
+		}
+		if glyph.Xmin < head.XMin || glyph.Ymin < head.YMin || glyph.Xmax > head.XMax || glyph.Ymax > head.YMax {
+			out = append(out, ValidationWarning{
+				Table: tagGlyf, Issue: GlyphOutOfBounds, Glyph: GID(i),
+				Message: fmt.Sprintf("glyph %d bounding box (%d, %d, %d, %d) exceeds the font bounding box (%d, %d, %d, %d)",
+					i, glyph.Xmin, glyph.Ymin, glyph.Xmax, glyph.Ymax, head.XMin, head.YMin, head.XMax, head.YMax),
+			})
+		}
+	}
+	return out
+}
+
+func (pr *FontParser) validateHmtx(numGlyphs int) []ValidationWarning {
+	if !pr.HasTable(tagHhea) {
+		return nil
+	}
+	hhea, err := pr.HheaTable()
+	if err != nil {
+		return []ValidationWarning{{Table: tagHhea, Issue: InvalidHmtx, Message: err.Error()}}
+	}
+	numMetrics := int(hhea.numOfLongMetrics)
+	if numMetrics == 0 {
+		return []ValidationWarning{{Table: tagHhea, Issue: InvalidHmtx, Message: "number of metrics is 0"}}
+	}
+	if numMetrics > numGlyphs {
+		return []ValidationWarning{{
+			Table: tagHhea, Issue: InvalidHmtx,
+			Message: fmt.Sprintf("number of metrics %d exceeds the number of glyphs %d", numMetrics, numGlyphs),
+		}}
+	}
+	hmtx, err := pr.GetRawTable(tagHmtx)
+	if err != nil {
+		return []ValidationWarning{{Table: tagHmtx, Issue: InvalidHmtx, Message: err.Error()}}
+	}
+	if expected := 4*numMetrics + 2*(numGlyphs-numMetrics); len(hmtx) < expected {
+		return []ValidationWarning{{
+			Table: tagHmtx, Issue: InvalidHmtx,
+			Message: fmt.Sprintf("table length is %d, expected at least %d", len(hmtx), expected),
+		}}
+	}
+	return nil
+}
+
+func (pr *FontParser) validateCmap(numGlyphs int) []ValidationWarning {
+	cmaps, err := pr.CmapTable()
+	if err != nil {
+		return []ValidationWarning{{Table: tagCmap, Issue: InvalidCmap, Message: err.Error()}}
+	}
+	var out []ValidationWarning
+	if _, encoding := cmaps.BestEncoding(); encoding == fonts.EncOther {
+		out = append(out, ValidationWarning{Table: tagCmap, Issue: InvalidCmap, Message: "no Unicode or symbol subtable"})
+	}
+	for _, subtable := range cmaps.Cmaps {
+		outOfRange := 0
+		for iter := subtable.Cmap.Iter(); iter.Next(); {
+			if _, gid := iter.Char(); int(gid) >= numGlyphs {
+				outOfRange++
+			}
+		}
+		if outOfRange != 0 {
+			out = append(out, ValidationWarning{
+				Table: tagCmap, Issue: InvalidCmap,
+				Message: fmt.Sprintf("subtable (%d, %d) maps %d runes to glyphs out of range",
+					subtable.ID.Platform, subtable.ID.Encoding, outOfRange),
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package truetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+)
+
+func validateFile(t *testing.T, file []byte) []ValidationWarning {
+	t.Helper()
+	pr, err := NewFontParser(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings, err := pr.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return warnings
+}
+
+// countIssues returns the number of warnings for each (table, issue)
+func countIssues(warnings []ValidationWarning) map[[2]int]int {
+	out := make(map[[2]int]int)
+	for _, w := range warnings {
+		out[[2]int{int(w.Table), int(w.Issue)}]++
+	}
+	return out
+}
+
+func TestValidate(t *testing.T) {
+	for _, filename := range []string{
+		"04B_30.ttf",
+		"FreeSerif.ttf",
+		"CFFTest.otf",
+		"open-sans-v15-latin-regular.woff",
+	} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if warnings := validateFile(t, file); len(warnings) != 0 {
+			t.Fatalf("%s: unexpected warnings %v", filename, warnings)
+		}
+	}
+
+	// Roboto has a few glyphs below the font bounding box
+	file, err := testdata.Files.ReadFile("Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := validateFile(t, file)
+	if exp := map[[2]int]int{{int(tagGlyf), int(GlyphOutOfBounds)}: 3}; !equalIssues(countIssues(warnings), exp) {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+	if warnings[0].Glyph != 419 {
+		t.Fatalf("unexpected glyph %d", warnings[0].Glyph)
+	}
+}
+
+func equalIssues(a, b map[[2]int]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestValidateCorrupted(t *testing.T) {
+	original, err := testdata.Files.ReadFile("04B_30.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := NewFontParser(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// corrupt returns a copy of the font with the table `tag` modified
+	corrupt := func(tag Tag, modify func(table []byte)) []byte {
+		file := append([]byte(nil), original...)
+		section := pr.tables[tag]
+		modify(file[section.offset : section.offset+section.length])
+		return file
+	}
+	for _, test := range []struct {
+		file     []byte
+		expected map[[2]int]int
+	}{
+		{ // decreasing loca for glyph 10
+			corrupt(tagLoca, func(loca []byte) { binary.BigEndian.PutUint32(loca[4*11:], 0) }),
+			map[[2]int]int{
+				{int(tagLoca), int(InvalidChecksum)}: 1,
+				{int(tagLoca), int(InvalidLoca)}:     1,
+			},
+		},
+		{ // fewer glyphs than metrics and cmap entries
+			corrupt(tagMaxp, func(maxp []byte) { binary.BigEndian.PutUint16(maxp[4:], 50) }),
+			map[[2]int]int{
+				{int(tagMaxp), int(InvalidChecksum)}: 1,
+				{int(tagHhea), int(InvalidHmtx)}:     1,
+				{int(tagCmap), int(InvalidCmap)}:     3,
+			},
+		},
+		{ // empty font bounding box
+			corrupt(tagHead, func(head []byte) {
+				copy(head[36:44], make([]byte, 8))
+				binary.BigEndian.PutUint32(head[8:], 0xFFFFFFFF) // ignored by the checksum
+			}),
+			map[[2]int]int{
+				{int(tagHead), int(InvalidChecksum)}:  1,
+				{int(tagGlyf), int(GlyphOutOfBounds)}: 92, // all the non empty glyphs
+			},
+		},
+	} {
+		warnings := validateFile(t, test.file)
+		if got := countIssues(warnings); !equalIssues(got, test.expected) {
+			t.Fatalf("expected %v, got %v", test.expected, warnings)
+		}
+	}
+
+	warnings := validateFile(t, corrupt(tagLoca, func(loca []byte) { binary.BigEndian.PutUint32(loca[4*11:], 0) }))
+	for _, w := range warnings {
+		if w.Issue == InvalidLoca && w.Glyph != 10 {
+			t.Fatalf("unexpected glyph %d", w.Glyph)
+		}
+	}
+}
+
+func TestSalvage(t *testing.T) {
+	original, err := testdata.Files.ReadFile("04B_30.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := Parse(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// setRecord returns a copy of the font with the
+	// directory record of `tag` modified
+	setRecord := func(file []byte, tag Tag, offset, length uint32) []byte {
+		file = append([]byte(nil), file...)
+		numTables := int(binary.BigEndian.Uint16(file[4:]))
+		for i := 0; i < numTables; i++ {
+			record := file[otfHeaderLength+directoryEntryLength*i:]
+			if newTag(record) == tag {
+				binary.BigEndian.PutUint32(record[8:], offset)
+				binary.BigEndian.PutUint32(record[12:], length)
+			}
+		}
+		return file
+	}
+	pr, err := NewFontParser(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxp, post := pr.tables[tagMaxp], pr.tables[tagPost]
+	file := setRecord(original, tagMaxp, maxp.offset, uint32(len(original))) // overlapping, and out of the file
+	file = setRecord(file, tagPost, uint32(len(original)+100), post.length)  // out of the file
+
+	if _, err := Parse(bytes.NewReader(file)); err == nil {
+		t.Fatal("expected error for invalid table record")
+	}
+
+	var logged []ValidationWarning
+	opts := ParseOptions{Salvage: true, Log: func(w ValidationWarning) { logged = append(logged, w) }}
+	font, err := ParseWithOptions(bytes.NewReader(file), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.NumGlyphs != expected.NumGlyphs || font.post.Names != nil {
+		t.Fatal("invalid salvaged font")
+	}
+	exp := map[[2]int]int{
+		{int(tagMaxp), int(InvalidTableRecord)}: 1,
+		{int(tagPost), int(InvalidTableRecord)}: 1,
+	}
+	if !equalIssues(countIssues(logged), exp) {
+		t.Fatalf("unexpected warnings %v", logged)
+	}
+
+	pr, err = NewFontParserWithOptions(bytes.NewReader(file), ParseOptions{Salvage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if section := pr.tables[tagMaxp]; section.offset+section.length > uint32(len(file)) {
+		t.Fatal("table range should be clamped")
+	}
+	warnings, err := pr.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) < 2 || warnings[0].Issue != InvalidTableRecord || warnings[1].Issue != InvalidTableRecord {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+
+	// truncated directory
+	file = append([]byte(nil), original[:otfHeaderLength+directoryEntryLength*3+5]...)
+	if _, err := NewFontParser(bytes.NewReader(file)); err == nil {
+		t.Fatal("expected error for truncated directory")
+	}
+	pr, err = NewFontParserWithOptions(bytes.NewReader(file), ParseOptions{Salvage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 3 tables read are out of the file
+	if len(pr.tables) != 0 || len(pr.warnings) != 4 || pr.warnings[0].String() != "truncated table directory: 3 entries read, 14 expected (invalid table record)" {
+		t.Fatalf("unexpected salvaged directory %v", pr.warnings)
+	}
+}
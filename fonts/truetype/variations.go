@@ -160,6 +160,49 @@ func (fvar *TableFvar) normalizeCoordinates(coords []float32) []float32 {
 
 func (f *Font) Variations() TableFvar { return f.fvar }
 
+// NamedInstance is a named instance of a variable font,
+// with its names resolved through the 'name' table.
+type NamedInstance struct {
+	// Name is the subfamily name, such as "Condensed Bold".
+	Name string
+	// PostScriptName is empty if not provided by the font.
+	PostScriptName string
+	Coords         []float32 // in design units; length: number of axis
+}
+
+// NamedInstances returns the named instances of a variable font,
+// including the default instance, in the order of the 'fvar' table.
+// It returns nil for non-variable fonts.
+// See SetNamedInstance to select one of them.
+func (f *Font) NamedInstances() []NamedInstance {
+	if len(f.fvar.Instances) == 0 {
+		return nil
+	}
+	out := make([]NamedInstance, len(f.fvar.Instances))
+	for i, instance := range f.fvar.Instances {
+		out[i] = NamedInstance{
+			Name:   f.Names.getName(instance.Subfamily),
+			Coords: append([]float32(nil), instance.Coords...),
+		}
+		// 0xFFFF means no name; 0 is used when the field is absent
+		if instance.PSStringID != 0 && instance.PSStringID != 0xFFFF {
+			out[i].PostScriptName = f.Names.getName(instance.PSStringID)
+		}
+	}
+	return out
+}
+
+// SetNamedInstance applies the coordinates of the named instance
+// at `index` in the slice returned by NamedInstances.
+// It returns false if `index` is out of range.
+func (f *Font) SetNamedInstance(index int) bool {
+	if index < 0 || index >= len(f.fvar.Instances) {
+		return false
+	}
+	f.SetVarCoordinates(f.NormalizeVariations(f.fvar.Instances[index].Coords))
+	return true
+}
+
 // NormalizeVariations normalizes the given design-space coordinates. The
 // minimum and maximum values for the axis are mapped to the interval [-1,1],
 // with the default axis value mapped to 0. Any additional scaling defined in
@@ -173,18 +216,8 @@ func (f *Font) NormalizeVariations(coords []float32) []float32 {
 	// Then, if there's an `avar' table, we re-normalize this range.
 	normalized := f.fvar.normalizeCoordinates(coords)
 
-	// now applying 'avar'
-	for i, av := range f.avar {
-		for j := 1; j < len(av); j++ {
-			previous, pair := av[j-1], av[j]
-			if normalized[i] < pair.from {
-				normalized[i] =
-					previous.to + (normalized[i]-previous.from)*
-						(pair.to-previous.to)/(pair.from-previous.from)
-				break
-			}
-		}
-	}
+	// now applying 'avar', including the version 2 cross-axis mapping
+	f.avar.apply(normalized)
 
 	return normalized
 }
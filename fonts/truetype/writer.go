@@ -0,0 +1,115 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// Table is the binary content of a font table.
+type Table struct {
+	Tag  Tag
+	Data []byte
+}
+
+// RawTables returns the binary content of all the tables of the font,
+// sorted by tag, so that they may be modified and written back with WriteTables.
+func (pr *FontParser) RawTables() ([]Table, error) {
+	out := make([]Table, 0, len(pr.tables))
+	for tag := range pr.tables {
+		data, err := pr.GetRawTable(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid table %s: %s", tag, err)
+		}
+		out = append(out, Table{Tag: tag, Data: data})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out, nil
+}
+
+// WriteTables writes a font file made of `tables`, using `sfntVersion`
+// (usually TypeTrueType or TypeOpenType) as header.
+// The tables are sorted by tag and padded to a multiple of 4 bytes,
+// and their checksums are computed.
+// If a 'head' table is present, its checkSumAdjustment field
+// is updated to match the whole file; the given data is not modified.
+func WriteTables(w io.Writer, sfntVersion Tag, tables []Table) error {
+	tables = append([]Table(nil), tables...)
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Tag < tables[j].Tag })
+
+	for i, table := range tables {
+		if i > 0 && tables[i-1].Tag == table.Tag {
+			return fmt.Errorf("duplicate table %s", table.Tag)
+		}
+		if table.Tag == tagHead {
+			if len(table.Data) < 12 {
+				return errors.New("invalid head table (EOF)")
+			}
+			// the adjustment is computed with a zero value
+			head := append([]byte(nil), table.Data...)
+			binary.BigEndian.PutUint32(head[8:], 0)
+			tables[i].Data = head
+		}
+	}
+
+	// header and table directory
+	numTables := len(tables)
+	entrySelector, searchRange := 0, 0
+	if numTables != 0 {
+		entrySelector = bits.Len(uint(numTables)) - 1
+		searchRange = 16 << entrySelector
+	}
+	offset := 12 + 16*numTables
+	size := offset
+	for _, table := range tables {
+		size += (len(table.Data) + 3) &^ 3
+	}
+	if uint64(size) > 0xFFFFFFFF {
+		return errors.New("font file is too long")
+	}
+
+	file := make([]byte, size)
+	binary.BigEndian.PutUint32(file, uint32(sfntVersion))
+	binary.BigEndian.PutUint16(file[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(file[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(file[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(file[10:], uint16(16*numTables-searchRange))
+
+	checksumAdjustmentOffset := -1
+	for i, table := range tables {
+		// the padding bytes are zeros
+		copy(file[offset:], table.Data)
+		padded := file[offset : offset+(len(table.Data)+3)&^3]
+
+		record := file[12+16*i:]
+		binary.BigEndian.PutUint32(record, uint32(table.Tag))
+		binary.BigEndian.PutUint32(record[4:], calcChecksum(padded))
+		binary.BigEndian.PutUint32(record[8:], uint32(offset))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table.Data)))
+
+		if table.Tag == tagHead {
+			checksumAdjustmentOffset = offset + 8
+		}
+		offset += len(padded)
+	}
+
+	if checksumAdjustmentOffset != -1 {
+		binary.BigEndian.PutUint32(file[checksumAdjustmentOffset:], 0xB1B0AFBA-calcChecksum(file))
+	}
+
+	_, err := w.Write(file)
+	return err
+}
+
+// calcChecksum returns the sum of the big endian uint32 words of data,
+// whose length must be a multiple of 4.
+func calcChecksum(data []byte) uint32 {
+	var sum uint32
+	for c := 0; c+4 <= len(data); c += 4 {
+		sum += binary.BigEndian.Uint32(data[c:])
+	}
+	return sum
+}
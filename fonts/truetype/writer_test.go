@@ -0,0 +1,106 @@
+package truetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/truetype"
+)
+
+func TestCalcChecksum(t *testing.T) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint32(data, 0xFFFFFFFF)
+	binary.BigEndian.PutUint32(data[4:], 0x102)
+	if sum := calcChecksum(data); sum != 0x101 {
+		t.Fatalf("unexpected checksum %x", sum)
+	}
+}
+
+func TestWriteTables(t *testing.T) {
+	for _, filename := range []string{"Roboto-BoldItalic.ttf", "SourceSansVariable-Roman.modcomp.ttf", "CFFTest.otf"} {
+		file, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := NewFontParser(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables, err := pr.RawTables()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// modify a table, and provide them out of order
+		tables = append(tables, Table{Tag: MustNewTag("TEST"), Data: []byte{1, 2, 3, 4, 5}})
+		tables[0], tables[1] = tables[1], tables[0]
+
+		var out bytes.Buffer
+		if err = WriteTables(&out, pr.Type, tables); err != nil {
+			t.Fatal(err)
+		}
+		if sum := calcChecksum(out.Bytes()); sum != 0xB1B0AFBA {
+			t.Fatalf("%s: invalid file checksum %x", filename, sum)
+		}
+
+		written, err := NewFontParser(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written.Type != pr.Type || len(written.tables) != len(tables) {
+			t.Fatalf("%s: unexpected header", filename)
+		}
+		for _, table := range tables {
+			s := written.tables[table.Tag]
+			if s.offset%4 != 0 {
+				t.Fatalf("%s: table %s is not aligned", filename, table.Tag)
+			}
+			padded := out.Bytes()[s.offset : s.offset+(s.length+3)&^3]
+			expected := calcChecksum(padded)
+			if table.Tag == tagHead { // computed with a zero checkSumAdjustment
+				expected -= binary.BigEndian.Uint32(padded[8:])
+			}
+			if checksum := binary.BigEndian.Uint32(findTableRecord(t, out.Bytes(), table.Tag)[4:]); checksum != expected {
+				t.Fatalf("%s: invalid checksum for table %s", filename, table.Tag)
+			}
+			data, err := written.GetRawTable(table.Tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if table.Tag == tagHead {
+				continue // checkSumAdjustment is updated
+			}
+			if !bytes.Equal(data, table.Data) {
+				t.Fatalf("%s: invalid content for table %s", filename, table.Tag)
+			}
+		}
+
+		if _, err = Parse(bytes.NewReader(out.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// findTableRecord returns the table directory entry for `tag`.
+func findTableRecord(t *testing.T, file []byte, tag Tag) []byte {
+	numTables := int(binary.BigEndian.Uint16(file[4:]))
+	for i := 0; i < numTables; i++ {
+		record := file[12+16*i:]
+		if Tag(binary.BigEndian.Uint32(record)) == tag {
+			return record
+		}
+	}
+	t.Fatalf("missing table record for %s", tag)
+	return nil
+}
+
+func TestWriteTablesInvalid(t *testing.T) {
+	var out bytes.Buffer
+	tag := MustNewTag("TEST")
+	if err := WriteTables(&out, TypeTrueType, []Table{{Tag: tag}, {Tag: tag}}); err == nil {
+		t.Fatal("expected error for duplicate tables")
+	}
+	if err := WriteTables(&out, TypeTrueType, []Table{{Tag: tagHead, Data: make([]byte, 4)}}); err == nil {
+		t.Fatal("expected error for invalid head table")
+	}
+}
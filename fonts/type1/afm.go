@@ -76,3 +76,15 @@ func (f AFMFont) CharSet() string {
 	}
 	return v.String()
 }
+
+// Kerning returns the kerning distance for the pair (left, right),
+// identified by their glyph names, or false if the pair
+// is not defined in the font.
+func (f AFMFont) Kerning(left, right string) (int, bool) {
+	for _, pair := range f.KernPairs[left] {
+		if pair.SndChar == right {
+			return pair.KerningDistance, true
+		}
+	}
+	return 0, false
+}
@@ -26,8 +26,24 @@ func TestKernings(t *testing.T) {
 	}
 	defer f.Close()
 
-	_, err = ParseAFMFile(f)
+	afm, err := ParseAFMFile(f)
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	if kern, ok := afm.Kerning("A", "V"); !ok || kern != -145 {
+		t.Fatalf("unexpected kerning for (A, V): %d", kern)
+	}
+	if _, ok := afm.Kerning("A", "A"); ok {
+		t.Fatal("unexpected kerning for (A, A)")
+	}
+
+	font := Font{charstrings: []charstring{{name: Notdef}, {name: "A"}, {name: "V"}}}
+	if kern := font.KernPair(1, 2); kern != 0 {
+		t.Fatalf("unexpected kerning without AFM: %d", kern)
+	}
+	font.SetAFM(&afm)
+	if kern := font.KernPair(1, 2); kern != -145 {
+		t.Fatalf("unexpected kerning for (A, V): %d", kern)
+	}
 }
@@ -0,0 +1,130 @@
+package type1
+
+import (
+	"sync"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+)
+
+// glyphCache stores the result of the interpretation
+// of the charstrings, so that repeated queries for the same glyph
+// do not run the PostScript machine again.
+// It is safe for concurrent use, and a nil cache is valid (and empty).
+type glyphCache struct {
+	glyphs  map[fonts.GID]cachedGlyph
+	maxSize int // 0 means no limit
+	mu      sync.Mutex
+}
+
+type cachedGlyph struct {
+	segments []fonts.Segment // must not be mutated
+	bounds   ps.PathBounds
+	advance  int32
+	isSeac   bool
+}
+
+func newGlyphCache(maxSize int) *glyphCache {
+	return &glyphCache{glyphs: make(map[fonts.GID]cachedGlyph), maxSize: maxSize}
+}
+
+func (c *glyphCache) get(gid fonts.GID) (cachedGlyph, bool) {
+	if c == nil {
+		return cachedGlyph{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g, ok := c.glyphs[gid]
+	return g, ok
+}
+
+func (c *glyphCache) set(gid fonts.GID, g cachedGlyph) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxSize > 0 && len(c.glyphs) >= c.maxSize {
+		// evict an arbitrary entry
+		for k := range c.glyphs {
+			delete(c.glyphs, k)
+			break
+		}
+	}
+	c.glyphs[gid] = g
+}
+
+// reset empties the cache. If `maxSize` is not nil,
+// it also updates the maximum size.
+func (c *glyphCache) reset(maxSize *int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.glyphs = make(map[fonts.GID]cachedGlyph)
+	if maxSize != nil {
+		c.maxSize = *maxSize
+	}
+}
+
+func (c *glyphCache) size() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxSize
+}
+
+// SetGlyphCacheSize limits the number of glyphs whose
+// outlines and metrics are kept in memory after the first query.
+// A value of 0, which is the default, means no limit.
+// The cache is cleared.
+func (f *Font) SetGlyphCacheSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	f.cache.reset(&size)
+}
+
+// nameIndex is a lazily built map from glyph names to glyph indices.
+// It is safe for concurrent use, and a nil index is valid (names are
+// then looked up by scanning the glyphs).
+type nameIndex struct {
+	gids map[string]fonts.GID // nil until the first query
+	mu   sync.Mutex
+}
+
+func (n *nameIndex) lookup(charstrings []charstring, name string) (fonts.GID, bool) {
+	if n == nil {
+		for gid, cs := range charstrings {
+			if cs.name == name {
+				return fonts.GID(gid), true
+			}
+		}
+		return 0, false
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.gids == nil {
+		n.gids = make(map[string]fonts.GID, len(charstrings))
+		for gid, cs := range charstrings {
+			if _, ok := n.gids[cs.name]; !ok { // keep the first glyph
+				n.gids[cs.name] = fonts.GID(gid)
+			}
+		}
+	}
+	gid, ok := n.gids[name]
+	return gid, ok
+}
+
+// reset must be called when the glyphs are modified.
+func (n *nameIndex) reset() {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.gids = nil
+}
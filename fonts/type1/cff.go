@@ -0,0 +1,166 @@
+package type1
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	type1c "github.com/boxesandglue/textlayout/fonts/type1C"
+)
+
+// ToCFF converts the font to a CFF font, which may be written with
+// `WriteCFFData`, for instance to be embedded in a PDF file (as FontFile3).
+//
+// The Type 1 charstrings are interpreted and re-encoded as Type 2 charstrings:
+// subroutines, flex and seac accents are flattened, and hints are dropped.
+// The glyph indexes are preserved, but the CFF font uses the standard encoding,
+// so that the builtin encoding of the font is not stored.
+func ToCFF(f *Font) (*type1c.CFF, error) {
+	data := type1c.FontData{
+		FontName:           f.FontName,
+		Version:            f.Version,
+		Notice:             f.Notice,
+		FullName:           f.FullName,
+		FamilyName:         f.FamilyName,
+		Weight:             f.Weight,
+		UniqueID:           f.UniqueID,
+		UnderlinePosition:  float64(f.UnderlinePosition),
+		UnderlineThickness: float64(f.UnderlineThickness),
+
+		BlueValues:       roundAll(f.Private.BlueValues),
+		OtherBlues:       roundAll(f.Private.OtherBlues),
+		FamilyBlues:      roundAll(f.Private.FamilyBlues),
+		FamilyOtherBlues: roundAll(f.Private.FamilyOtherBlues),
+		BlueScale:        float64(f.Private.BlueScale),
+		BlueShift:        f.Private.BlueShift,
+		BlueFuzz:         f.Private.BlueFuzz,
+		StdHW:            int(math.Round(float64(f.Private.StdHW))),
+		StdVW:            int(math.Round(float64(f.Private.StdVW))),
+		StemSnapH:        roundAll(f.Private.StemSnapH),
+		StemSnapV:        roundAll(f.Private.StemSnapV),
+	}
+	if len(f.FontBBox) == 4 {
+		copy(data.FontBBox[:], roundAll(f.FontBBox))
+	}
+	if len(f.FontMatrix) == 6 && !isDefaultFontMatrix(f.FontMatrix) {
+		data.FontMatrix = make([]float64, 6)
+		for i, v := range f.FontMatrix {
+			data.FontMatrix[i] = float64(v)
+		}
+	}
+
+	data.GlyphNames = make([]string, len(f.charstrings))
+	data.CharStrings = make([][]byte, len(f.charstrings))
+	for gid, cs := range f.charstrings {
+		segments, _, advance, err := f.loadGlyph(fonts.GID(gid), false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glyph %s: %s", cs.name, err)
+		}
+		data.GlyphNames[gid] = cs.name
+		data.CharStrings[gid] = type2Charstring(segments, advance)
+	}
+	// some fonts do not have a .notdef glyph
+	if len(data.GlyphNames) == 0 || data.GlyphNames[0] != Notdef {
+		data.GlyphNames = append([]string{Notdef}, data.GlyphNames...)
+		data.CharStrings = append([][]byte{type2Charstring(nil, 0)}, data.CharStrings...)
+	}
+
+	return type1c.NewCFF(data)
+}
+
+func isDefaultFontMatrix(m []Fl) bool {
+	return m[0] == 0.001 && m[1] == 0 && m[2] == 0 && m[3] == 0.001 && m[4] == 0 && m[5] == 0
+}
+
+func roundAll(values []Fl) []int {
+	if values == nil {
+		return nil
+	}
+	out := make([]int, len(values))
+	for i, v := range values {
+		out[i] = int(math.Round(float64(v)))
+	}
+	return out
+}
+
+// Type 2 charstrings operators
+const (
+	t2Rlineto   = 5
+	t2Rrcurveto = 8
+	t2Endchar   = 14
+	t2Rmoveto   = 21
+
+	t2MaxArgs = 48 // maximum size of the argument stack
+)
+
+// type2Charstring encodes the given outline as a Type 2 charstring,
+// using the absolute outline coordinates and relative operators.
+// The width is always stored, since nominalWidthX is 0.
+func type2Charstring(segments []fonts.Segment, width int32) []byte {
+	var (
+		out      []byte
+		args     []int32
+		pendings byte // operator for the args, or 0
+		current  [2]int32
+	)
+	if width != 0 {
+		args = append(args, width)
+	}
+	flush := func() {
+		for _, v := range args {
+			out = appendType2Number(out, v)
+		}
+		if pendings != 0 {
+			out = append(out, pendings)
+		}
+		args, pendings = args[:0], 0
+	}
+	for _, seg := range segments {
+		var op byte
+		switch seg.Op {
+		case fonts.SegmentOpMoveTo:
+			op = t2Rmoveto
+		case fonts.SegmentOpLineTo:
+			op = t2Rlineto
+		case fonts.SegmentOpCubeTo:
+			op = t2Rrcurveto
+		default: // not produced by Type 1 charstrings
+			continue
+		}
+		points := seg.ArgsSlice()
+		// moveto can't be accumulated
+		if pendings != 0 && (op != pendings || op == t2Rmoveto || len(args)+2*len(points) > t2MaxArgs) {
+			flush()
+		}
+		for _, pt := range points {
+			x, y := int32(math.Round(float64(pt.X))), int32(math.Round(float64(pt.Y)))
+			args = append(args, x-current[0], y-current[1])
+			current = [2]int32{x, y}
+		}
+		pendings = op
+	}
+	flush()
+	return append(out, t2Endchar)
+}
+
+// appendType2Number encodes `v` as a Type 2 charstring operand,
+// clamped to the range of 16-bit integers.
+func appendType2Number(out []byte, v int32) []byte {
+	if v > math.MaxInt16 {
+		v = math.MaxInt16
+	} else if v < math.MinInt16 {
+		v = math.MinInt16
+	}
+	switch {
+	case -107 <= v && v <= 107:
+		return append(out, byte(v+139))
+	case 108 <= v && v <= 1131:
+		v -= 108
+		return append(out, byte(v>>8+247), byte(v))
+	case -1131 <= v && v <= -108:
+		v = -v - 108
+		return append(out, byte(v>>8+251), byte(v))
+	default:
+		return append(out, 28, byte(v>>8), byte(v))
+	}
+}
@@ -0,0 +1,174 @@
+package type1
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/type1"
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+	type1c "github.com/boxesandglue/textlayout/fonts/type1C"
+)
+
+func TestToCFF(t *testing.T) {
+	for _, file := range []string{"c0419bt_.pfb", "CalligrapherRegular.pfb", "Z003-MediumItalic.t1"} {
+		b, err := testdata.Files.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cff, err := ToCFF(font)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err = cff.WriteCFFData(&out); err != nil {
+			t.Fatal(err)
+		}
+
+		converted, err := type1c.Parse(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if converted.NumGlyphs() != len(font.charstrings) {
+			t.Fatalf("%s: unexpected number of glyphs %d", file, converted.NumGlyphs())
+		}
+		for gid := range font.charstrings {
+			gid := fonts.GID(gid)
+			if name := converted.GlyphName(gid); name != font.GlyphName(gid) {
+				t.Fatalf("%s: unexpected glyph name %s for %s", file, name, font.GlyphName(gid))
+			}
+			exp, err := font.LoadGlyph(gid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, bounds, err := converted.LoadGlyph(gid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bounds != exp.Bounds {
+				t.Fatalf("%s: unexpected bounds for glyph %s: %v != %v", file, font.GlyphName(gid), bounds, exp.Bounds)
+			}
+		}
+	}
+}
+
+func TestAppendType2Number(t *testing.T) {
+	for _, v := range []int32{0, 107, -107, 108, 1131, -108, -1131, 1132, -1132, 32767, -32768} {
+		b := appendType2Number(nil, v)
+		var got int32
+		switch b0 := int32(b[0]); {
+		case b0 >= 32 && b0 <= 246:
+			got = b0 - 139
+		case b0 >= 247 && b0 <= 250:
+			got = (b0-247)*256 + int32(b[1]) + 108
+		case b0 >= 251 && b0 <= 254:
+			got = -(b0-251)*256 - int32(b[1]) - 108
+		case b0 == 28:
+			got = int32(int16(uint16(b[1])<<8 | uint16(b[2])))
+		}
+		if got != v {
+			t.Fatalf("expected %d, got %d", v, got)
+		}
+	}
+}
+
+// segmentsPath is a ps.PathConsumer storing the segments
+type segmentsPath []fonts.Segment
+
+func (p *segmentsPath) MoveTo(pt fonts.SegmentPoint) {
+	*p = append(*p, fonts.Segment{Op: fonts.SegmentOpMoveTo, Args: [3]fonts.SegmentPoint{pt}})
+}
+
+func (p *segmentsPath) LineTo(pt fonts.SegmentPoint) {
+	*p = append(*p, fonts.Segment{Op: fonts.SegmentOpLineTo, Args: [3]fonts.SegmentPoint{pt}})
+}
+
+func (p *segmentsPath) CurveTo(pt1, pt2, pt3 fonts.SegmentPoint) {
+	*p = append(*p, fonts.Segment{Op: fonts.SegmentOpCubeTo, Args: [3]fonts.SegmentPoint{pt1, pt2, pt3}})
+}
+
+func TestCFFAccentedGlyph(t *testing.T) {
+	var (
+		opRmoveto = byte(21)
+		opRlineto = byte(5)
+		opEndchar = byte(14)
+	)
+	square := func(size int32) []byte {
+		cs := appendType2Number(nil, 500) // width
+		cs = append(appendType2Number(appendType2Number(cs, 0), 0), opRmoveto)
+		cs = append(appendType2Number(appendType2Number(cs, size), 0), opRlineto)
+		cs = append(appendType2Number(appendType2Number(cs, 0), size), opRlineto)
+		return append(cs, opEndchar)
+	}
+	accented := appendType2Number(nil, 500)
+	for _, v := range []int32{50, 200, 65, 194} { // adx ady A acute
+		accented = appendType2Number(accented, v)
+	}
+	accented = append(accented, opEndchar)
+
+	cff, err := type1c.NewCFF(type1c.FontData{
+		FontName:    "Test",
+		FontBBox:    [4]int{0, 0, 500, 500},
+		GlyphNames:  []string{".notdef", "A", "acute", "Aacute", "B"},
+		CharStrings: [][]byte{{opEndchar}, square(100), square(20), accented, square(50)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = cff.WriteCFFData(&out); err != nil {
+		t.Fatal(err)
+	}
+	font, err := type1c.Parse(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	components, err := font.GlyphComponents(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := (type1c.Seac{Base: 1, Accent: 2, AccentOffset: ps.Point{X: 50, Y: 200}}); components == nil || *components != exp {
+		t.Fatalf("unexpected components %v", components)
+	}
+	if components, _ = font.GlyphComponents(1); components != nil {
+		t.Fatalf("unexpected components %v", components)
+	}
+
+	segments, bounds, err := font.LoadGlyph(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 8 { // 2 closed triangles
+		t.Fatalf("unexpected segments %v", segments)
+	}
+	if exp := (ps.PathBounds{Min: ps.Point{}, Max: ps.Point{X: 100, Y: 220}}); bounds != exp {
+		t.Fatalf("unexpected bounds %v", bounds)
+	}
+
+	// the components are also resolved when streaming the outlines
+	var drawn segmentsPath
+	if bounds, err = font.DrawGlyph(3, nil, &drawn); err != nil {
+		t.Fatal(err)
+	}
+	if exp := (ps.PathBounds{Min: ps.Point{}, Max: ps.Point{X: 100, Y: 220}}); bounds != exp || !reflect.DeepEqual([]fonts.Segment(drawn), segments) {
+		t.Fatalf("unexpected outlines %v %v", drawn, bounds)
+	}
+
+	// the components are kept by subsetting
+	if err = font.Subset([]fonts.GID{3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = font.LoadGlyph(3); err != nil {
+		t.Fatal(err)
+	}
+	if font.NumGlyphs() != 4 {
+		t.Fatalf("unexpected number of glyphs %d", font.NumGlyphs())
+	}
+}
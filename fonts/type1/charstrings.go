@@ -21,11 +21,20 @@ type seac struct {
 type type1CharstringParser struct {
 	seac *seac // filled for seac operators
 
-	flexPoints []ps.Point // filled with OtherSub(2) opcodes
+	usedSubrs map[int32]bool // if not nil, filled with the called subroutines
+
+	weights []Fl // weight vector for Multiple Master fonts, used by blend othersubrs
+
+	hints *Hints // if not nil, filled with the hinting operators
+
+	// flexPoints are the absolute points registered by OtherSubr(2),
+	// the first one being the reference point (not drawn)
+	flexPoints []ps.Point
+	flexStart  ps.Point // current point when the flex started
 
 	cs ps.CharstringReader
 
-	inFlex bool // alter the behavior of moveto opcodes
+	inFlex bool // moveto opcodes only update the current point
 
 	leftBearing, advance ps.Point
 }
@@ -37,16 +46,17 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 	if !op.IsEscaped {
 		switch op.Operator {
 		case 1: // hstem
+			met.recordStems(state, false)
 			met.cs.Hstem(state)
 		case 3: // vstem
+			met.recordStems(state, true)
 			met.cs.Vstem(state)
 		case 4: // vmoveto
 			if met.inFlex {
 				if state.ArgStack.Top < 1 {
 					return errors.New("invalid vmoveto operator")
 				}
-				y := state.ArgStack.Pop()
-				met.flexPoints = append(met.flexPoints, ps.Point{X: 0, Y: y})
+				met.cs.CurrentPoint.Move(0, state.ArgStack.Pop())
 			} else {
 				err = met.cs.Vmoveto(state)
 			}
@@ -61,6 +71,9 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 		case 9: // closepath
 			met.cs.ClosePath()
 		case 10: // callsubr
+			if met.usedSubrs != nil && state.ArgStack.Top > 0 {
+				met.usedSubrs[state.ArgStack.Vals[state.ArgStack.Top-1]] = true
+			}
 			return ps.LocalSubr(state) // do not clear the arg stack
 		case 11: // return
 			return state.Return() // do not clear the arg stack
@@ -74,6 +87,9 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 			// "This command also sets the current point to (sbx, 0),
 			// but does not place the point in the character path."
 			met.cs.CurrentPoint = ps.Point{X: met.leftBearing.X, Y: 0}
+			if err := met.cs.WidthFound(); err != nil {
+				return err
+			}
 		case 14: // endchar
 			return ps.ErrInterrupt
 		case 21: // rmoveto
@@ -83,7 +99,7 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 				}
 				y := state.ArgStack.Pop()
 				x := state.ArgStack.Pop()
-				met.flexPoints = append(met.flexPoints, ps.Point{X: x, Y: y})
+				met.cs.CurrentPoint.Move(x, y)
 			} else {
 				err = met.cs.Rmoveto(state)
 			}
@@ -92,8 +108,7 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 				if state.ArgStack.Top < 1 {
 					return errors.New("invalid hmoveto operator")
 				}
-				x := state.ArgStack.Pop()
-				met.flexPoints = append(met.flexPoints, ps.Point{X: x, Y: 0})
+				met.cs.CurrentPoint.Move(state.ArgStack.Pop(), 0)
 			} else {
 				err = met.cs.Hmoveto(state)
 			}
@@ -111,8 +126,10 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 		case 0: // dotsection
 			// just clear the stack
 		case 1: // vstem3
+			met.recordStems(state, true)
 			met.cs.Vstem(state)
-		case 2:
+		case 2: // hstem3
+			met.recordStems(state, false)
 			met.cs.Hstem(state)
 		case 6: // seac
 			if state.ArgStack.Top < 5 {
@@ -121,9 +138,10 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 			met.seac = &seac{
 				aCode: state.ArgStack.Vals[state.ArgStack.Top-1],
 				bCode: state.ArgStack.Vals[state.ArgStack.Top-2],
+				// as freetype, include the side bearing of the composite glyph
 				accentOrigin: ps.Point{
 					Y: state.ArgStack.Vals[state.ArgStack.Top-3],
-					X: state.ArgStack.Vals[state.ArgStack.Top-4],
+					X: state.ArgStack.Vals[state.ArgStack.Top-4] + met.leftBearing.X,
 				},
 				accentLeftSideBearing: state.ArgStack.Vals[state.ArgStack.Top-5],
 			}
@@ -135,6 +153,11 @@ func (met *type1CharstringParser) Apply(op ps.PsOperator, state *ps.Machine) err
 			met.leftBearing.Y += state.ArgStack.Vals[state.ArgStack.Top-3]
 			met.advance.X = state.ArgStack.Vals[state.ArgStack.Top-2]
 			met.advance.Y = state.ArgStack.Vals[state.ArgStack.Top-1]
+			// as hsbw, also sets the current point
+			met.cs.CurrentPoint = met.leftBearing
+			if err := met.cs.WidthFound(); err != nil {
+				return err
+			}
 		case 16: // callothersubr
 			return met.otherSub(state) // do not clear the stack
 		case 17: // pop: actually it pushes back to the stack
@@ -164,38 +187,44 @@ func (met *type1CharstringParser) otherSub(state *ps.Machine) error {
 	}
 	index := state.ArgStack.Pop() // index
 	nbArgs := state.ArgStack.Pop()
-	state.ArgStack.PopN(nbArgs)
+	if err := state.ArgStack.PopN(nbArgs); err != nil {
+		return err
+	}
 
-	// we only support the Flex feature
+	// the return values of the othersubrs are pushed back by the following
+	// pop operators, and are read from the stack, after the popped arguments
 	switch index {
 	case 0: // end flex
+		if !met.inFlex {
+			return errors.New("EndFlex other sub called outside a flex")
+		}
 		met.inFlex = false
-
 		if nbArgs != 3 {
-			return fmt.Errorf("invalid number of arguments for StartFlex other sub: %d", nbArgs)
+			return fmt.Errorf("invalid number of arguments for EndFlex other sub: %d", nbArgs)
 		}
-		if len(met.flexPoints) < 7 {
+		if len(met.flexPoints) != 7 {
 			return fmt.Errorf("invalid number of flex points for EndFlex other sub: %d", len(met.flexPoints))
 		}
 
-		// reference point is relative to start point
-		reference := &met.flexPoints[0]
-		reference.Move(met.cs.CurrentPoint.X, met.cs.CurrentPoint.Y)
-
-		// first point is relative to reference point
-		first := &met.flexPoints[1]
-		first.Move(reference.X, reference.Y)
-
-		// make the first point relative to the start point
-		first.Move(-met.cs.CurrentPoint.X, -met.cs.CurrentPoint.Y)
-
-		met.cs.RelativeCurveTo(met.flexPoints[1], met.flexPoints[2], met.flexPoints[3])
-		met.cs.RelativeCurveTo(met.flexPoints[4], met.flexPoints[5], met.flexPoints[6])
+		// the reference point flexPoints[0] is only used for hinting
+		met.cs.CurrentPoint = met.flexStart
+		for _, curve := range [2][]ps.Point{met.flexPoints[1:4], met.flexPoints[4:7]} {
+			start := met.cs.CurrentPoint
+			met.cs.RelativeCurveTo(
+				ps.Point{X: curve[0].X - start.X, Y: curve[0].Y - start.Y},
+				ps.Point{X: curve[1].X - curve[0].X, Y: curve[1].Y - curve[0].Y},
+				ps.Point{X: curve[2].X - curve[1].X, Y: curve[2].Y - curve[1].Y},
+			)
+		}
+		if met.hints != nil {
+			met.recordFlex(state.ArgStack.Vals[state.ArgStack.Top])
+		}
 
 		// reset the flex points
 		met.flexPoints = met.flexPoints[:0]
 
-		// update the stack with the return values (after popping the args)
+		// the final point (x, y) is returned, usually
+		// followed by "pop pop setcurrentpoint"
 		state.ArgStack.Vals[state.ArgStack.Top] = met.cs.CurrentPoint.X
 		state.ArgStack.Vals[state.ArgStack.Top+1] = met.cs.CurrentPoint.Y
 	case 1: // start flex
@@ -203,14 +232,74 @@ func (met *type1CharstringParser) otherSub(state *ps.Machine) error {
 			return fmt.Errorf("invalid number of arguments for StartFlex other sub: %d", nbArgs)
 		}
 		met.inFlex = true
+		met.flexStart = met.cs.CurrentPoint
 		met.flexPoints = met.flexPoints[:0]
 	case 2: // add flex vector
 		if nbArgs != 0 {
-			return fmt.Errorf("invalid number of arguments for StartFlex other sub: %d", nbArgs)
+			return fmt.Errorf("invalid number of arguments for AddFlexVector other sub: %d", nbArgs)
+		}
+		if !met.inFlex {
+			return errors.New("AddFlexVector other sub called outside a flex")
+		}
+		// do not grow on malformed flex sequences:
+		// the number of vectors is checked when the flex ends
+		if len(met.flexPoints) <= 7 {
+			met.flexPoints = append(met.flexPoints, met.cs.CurrentPoint)
+		}
+	case 3: // hint replacement
+		if nbArgs != 1 {
+			return fmt.Errorf("invalid number of arguments for hint replacement other sub: %d", nbArgs)
 		}
-		// implemented in the moveto op codes
+		// the subroutine number is returned unchanged, and
+		// the following callsubr changes the hints
+		if met.hints != nil {
+			met.hints.replacements++
+		}
+	case 14, 15, 16, 17, 18: // blend, for Multiple Master fonts
+		if nbArgs < 0 {
+			return fmt.Errorf("invalid number of arguments for blend other sub: %d", nbArgs)
+		}
+		// the results are pushed back by the following pop operators
+		args := state.ArgStack.Vals[state.ArgStack.Top : state.ArgStack.Top+nbArgs]
+		return blend(args, blendResults(index), met.weights)
 	default:
 		// not handled
 	}
 	return nil
 }
+
+// recordStems adds the stems hints in the stack (pairs of edge, width)
+// to the collected hints, if enabled.
+func (met *type1CharstringParser) recordStems(state *ps.Machine, vertical bool) {
+	if met.hints == nil {
+		return
+	}
+	args := state.ArgStack.Vals[:state.ArgStack.Top]
+	for ; len(args) >= 2; args = args[2:] {
+		if vertical { // relative to the left side bearing
+			met.hints.VStems = append(met.hints.VStems, Stem{
+				Edge: args[0] + met.leftBearing.X, Width: args[1], Group: met.hints.replacements,
+			})
+		} else {
+			met.hints.HStems = append(met.hints.HStems, Stem{
+				Edge: args[0] + met.leftBearing.Y, Width: args[1], Group: met.hints.replacements,
+			})
+		}
+	}
+}
+
+// recordFlex adds the two curves of the flex segment
+// just interpreted to the collected hints.
+func (met *type1CharstringParser) recordFlex(depth int32) {
+	segments := met.cs.Segments
+	if len(segments) < 2 {
+		return
+	}
+	flex := Flex{Depth: depth}
+	for i, seg := range segments[len(segments)-2:] {
+		for j, pt := range seg.Args {
+			flex.Points[3*i+j] = ps.Point{X: int32(pt.X), Y: int32(pt.Y)}
+		}
+	}
+	met.hints.Flex = append(met.hints.Flex, flex)
+}
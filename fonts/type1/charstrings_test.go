@@ -2,10 +2,16 @@ package type1
 
 import (
 	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	testdata "github.com/benoitkugler/textlayout-testdata/type1"
 	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+	"github.com/boxesandglue/textlayout/fonts/simpleencodings"
 )
 
 func TestParseMetrics(t *testing.T) {
@@ -42,3 +48,282 @@ func TestParseMetrics(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadGlyph(t *testing.T) {
+	for j, filename := range filenamesBounds {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i < len(font.charstrings); i++ {
+			glyph, err := font.LoadGlyph(fonts.GID(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if glyph.Bounds != expectedBounds[j][i] || glyph.Advance != expectedAdvances[j][i] {
+				t.Fatalf("invalid glyph %d in %s", i, filename)
+			}
+			if len(glyph.Outline.Segments) == 0 && glyph.Bounds != (ps.PathBounds{}) {
+				t.Fatalf("missing outline for glyph %d in %s", i, filename)
+			}
+		}
+
+		if _, err := font.LoadGlyph(fonts.GID(len(font.charstrings))); err == nil {
+			t.Fatal("expected error for invalid glyph index")
+		}
+	}
+}
+
+func TestGlyphCache(t *testing.T) {
+	for j, filename := range filenamesBounds {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, size := range []int{0, 10} {
+			font.SetGlyphCacheSize(size)
+
+			var wg sync.WaitGroup
+			for k := 0; k < 4; k++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					// run twice to use the cache
+					for pass := 0; pass < 2; pass++ {
+						for i := 1; i < len(font.charstrings); i++ {
+							_, bounds, adv, err := font.loadGlyph(fonts.GID(i), false)
+							if err != nil {
+								t.Error(err)
+								return
+							}
+							if bounds != expectedBounds[j][i] || adv != expectedAdvances[j][i] {
+								t.Errorf("invalid glyph %d in %s", i, filename)
+								return
+							}
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := len(font.cache.glyphs); size != 0 && got > size {
+				t.Fatalf("cache size exceeded: %d > %d", got, size)
+			} else if size == 0 && got != len(font.charstrings)-1 {
+				t.Fatalf("unexpected cache size %d", got)
+			}
+		}
+
+		// the outlines returned are copies
+		glyph, _ := font.LoadGlyph(1)
+		for i := range glyph.Outline.Segments {
+			glyph.Outline.Segments[i].Args[0].X += 1000
+		}
+		if other, _ := font.LoadGlyph(1); other.Bounds != glyph.Bounds || (len(other.Outline.Segments) != 0 &&
+			other.Outline.Segments[0] == glyph.Outline.Segments[0]) {
+			t.Fatal("cached outline modified")
+		}
+	}
+}
+
+func TestLoadGlyphWithHints(t *testing.T) {
+	var (
+		opHstem           = csOp(1, false)
+		opVstem           = csOp(3, false)
+		opCallsubr        = csOp(10, false)
+		opSetcurrentpoint = csOp(33, true)
+	)
+	flex := []interface{}{0, 1, opCallothersubr, 100, 0, opRmoveto, 0, 2, opCallothersubr}
+	for _, pt := range [][2]int{{-50, 10}, {20, 5}, {30, 0}, {30, 0}, {20, -5}, {50, -10}} {
+		flex = append(flex, pt[0], pt[1], opRmoveto, 0, 2, opCallothersubr)
+	}
+	flex = append(flex, 50, 200, 0, 3, 0, opCallothersubr, opPop, opPop, opSetcurrentpoint)
+
+	items := []interface{}{10, 500, opHsbw, 100, 20, opHstem, 30, 40, opVstem, 0, 0, opRmoveto}
+	items = append(items, flex...)
+	items = append(items, 1, 1, 3, opCallothersubr, opPop, opCallsubr, opClosepath, opEndchar)
+	font := Font{
+		charstrings: []charstring{
+			{name: Notdef, data: buildCharstring(0, 500, opHsbw, opEndchar)},
+			{name: "A", data: buildCharstring(items...)},
+		},
+		subrs: [][]byte{
+			nil,
+			buildCharstring(300, 10, opHstem, opReturn),
+		},
+	}
+
+	glyph, err := font.LoadGlyphWithHints(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if glyph.Advance != 500 {
+		t.Fatalf("unexpected advance %d", glyph.Advance)
+	}
+	hints := glyph.Hints
+	if exp := []Stem{{Edge: 100, Width: 20}, {Edge: 300, Width: 10, Group: 1}}; len(hints.HStems) != 2 ||
+		hints.HStems[0] != exp[0] || hints.HStems[1] != exp[1] {
+		t.Fatalf("unexpected horizontal stems %v", hints.HStems)
+	}
+	if exp := (Stem{Edge: 40, Width: 40}); len(hints.VStems) != 1 || hints.VStems[0] != exp {
+		t.Fatalf("unexpected vertical stems %v", hints.VStems)
+	}
+	if len(hints.Flex) != 1 {
+		t.Fatalf("unexpected flex %v", hints.Flex)
+	}
+	if fl := hints.Flex[0]; fl.Depth != 50 || fl.Points[5] != (ps.Point{X: 210, Y: 0}) {
+		t.Fatalf("unexpected flex %v", fl)
+	}
+
+	// hints are not collected by default
+	if glyph, _ = font.LoadGlyph(1); glyph.Hints != nil {
+		t.Fatal("unexpected hints")
+	}
+}
+
+func TestSeacFallback(t *testing.T) {
+	opSeac := csOp(6, true)
+	square := buildCharstring(0, 500, opHsbw, 0, 0, opRmoveto, 100, 0, opRlineto, 0, 100, opRlineto, opClosepath, opEndchar)
+	var enc simpleencodings.Encoding
+	enc[65] = "Abase" // re-encoded base
+	font := Font{
+		Encoding: &enc,
+		charstrings: []charstring{
+			{name: Notdef, data: buildCharstring(0, 500, opHsbw, opEndchar)},
+			{name: "Abase", data: square},
+			{name: "acute.alt", data: square}, // only found by name
+			// 'A' and 'acute' in the standard encoding
+			{name: "Aacute", data: buildCharstring(0, 500, opHsbw, 0, 0, 200, 65, 194, opSeac)},
+		},
+	}
+	font.synthesizeCmap()
+
+	glyph, err := font.LoadGlyph(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := (ps.PathBounds{Min: ps.Point{X: 0, Y: 0}, Max: ps.Point{X: 100, Y: 300}}); glyph.Bounds != exp {
+		t.Fatalf("unexpected bounds %v", glyph.Bounds)
+	}
+
+	font.charstrings[2].name = "unknown"
+	font.synthesizeCmap()
+	font.cache = nil
+	if _, err = font.LoadGlyph(3); err == nil {
+		t.Fatal("expected error for missing seac component")
+	}
+}
+
+func TestFontMatrix(t *testing.T) {
+	square := buildCharstring(0, 500, opHsbw, 0, 0, opRmoveto, 100, 0, opRlineto, 0, 100, opRlineto, opClosepath, opEndchar)
+	font := Font{
+		charstrings: []charstring{{name: "A", data: square}},
+		FontBBox:    []Fl{0, -100, 1000, 900},
+	}
+	for _, test := range []struct {
+		matrix  []Fl
+		upem    uint16
+		advance float32
+		extents fonts.GlyphExtents
+		ymax    float32
+	}{
+		{[]Fl{0.001, 0, 0, 0.001, 0, 0}, 1000, 500, fonts.GlyphExtents{Width: 100, Height: -100, YBearing: 100}, 900},
+		{[]Fl{0.0005, 0, 0, 0.0005, 0, 0}, 2000, 500, fonts.GlyphExtents{Width: 100, Height: -100, YBearing: 100}, 900},
+		// anisotropic
+		{[]Fl{0.0005, 0, 0, 0.001, 0, 0}, 2000, 500, fonts.GlyphExtents{Width: 100, Height: -200, YBearing: 200}, 1800},
+		// oblique
+		{[]Fl{0.001, 0, 0.0005, 0.001, 0, 0}, 1000, 500, fonts.GlyphExtents{Width: 150, Height: -100, YBearing: 100}, 900},
+	} {
+		font.FontMatrix = test.matrix
+		if upem := font.Upem(); upem != test.upem {
+			t.Fatalf("unexpected upem %d", upem)
+		}
+		if adv := font.HorizontalAdvance(0); adv != test.advance {
+			t.Fatalf("unexpected advance %f", adv)
+		}
+		if ext, _ := font.GlyphExtents(0, 0, 0); ext != test.extents {
+			t.Fatalf("unexpected extents %v for %v", ext, test.matrix)
+		}
+		if ext, _ := font.FontHExtents(); ext.Ascender != test.ymax {
+			t.Fatalf("unexpected ascender %f", ext.Ascender)
+		}
+		outline := font.GlyphData(0, 0, 0).(fonts.GlyphOutline)
+		if last := outline.Segments[2].Args[0]; last.X != float32(test.extents.Width) || last.Y != -test.extents.Height {
+			t.Fatalf("unexpected outline point %v", last)
+		}
+	}
+}
+
+// formatContours uses the format of expectedOutlines
+func formatContours(segments []fonts.Segment) []string {
+	var (
+		contours [][]string
+		current  []string
+	)
+	format := func(pt fonts.SegmentPoint, offCurve bool) string {
+		s := fmt.Sprintf("%d,%d", int(pt.X), int(pt.Y))
+		if offCurve {
+			return "~" + s
+		}
+		return s
+	}
+	for _, seg := range segments {
+		switch seg.Op {
+		case fonts.SegmentOpMoveTo:
+			if current != nil {
+				contours = append(contours, current)
+			}
+			current = []string{format(seg.Args[0], false)}
+		case fonts.SegmentOpLineTo:
+			current = append(current, format(seg.Args[0], false))
+		case fonts.SegmentOpCubeTo:
+			current = append(current, format(seg.Args[0], true), format(seg.Args[1], true), format(seg.Args[2], false))
+		}
+	}
+	if current != nil {
+		contours = append(contours, current)
+	}
+	out := make([]string, len(contours))
+	for i, contour := range contours {
+		if len(contour) > 1 && contour[len(contour)-1] == contour[0] {
+			contour = contour[:len(contour)-1]
+		}
+		out[i] = strings.Join(contour, " ")
+	}
+	return out
+}
+
+func TestOutlinesFreetype(t *testing.T) {
+	loaded := map[string]*Font{}
+	for _, exp := range expectedOutlines {
+		font := loaded[exp.filename]
+		if font == nil {
+			b, err := testdata.Files.ReadFile(exp.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			font, err = Parse(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			loaded[exp.filename] = font
+		}
+		glyph, err := font.LoadGlyph(fonts.GID(exp.gid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := formatContours(glyph.Outline.Segments)
+		if !reflect.DeepEqual(got, exp.contours) {
+			t.Fatalf("%s: unexpected outline for glyph %s:\n%v\nexpected\n%v", exp.filename, font.GlyphName(fonts.GID(exp.gid)), got, exp.contours)
+		}
+	}
+}
@@ -0,0 +1,542 @@
+package type1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	tk "github.com/benoitkugler/pstokenizer"
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+)
+
+// CIDSystemInfo identifies the character collection of a CID-keyed font.
+type CIDSystemInfo struct {
+	Registry, Ordering string
+	Supplement         int
+}
+
+// CIDFontDict is one of the font dictionaries of a CID-keyed font,
+// used by a subset of its glyphs.
+type CIDFontDict struct {
+	FontName   string
+	FontMatrix []Fl
+
+	// Private stores the hinting values of the dictionary
+	Private PrivateDict
+
+	subrs [][]byte // local subroutines, decrypted
+	lenIV int
+
+	// where to read the subroutines in the binary data
+	subrMapOffset, sdBytes, subrCount int
+}
+
+type cidGlyph struct {
+	data []byte // decrypted charstring
+	cid  uint16
+	fd   uint8 // index into FDArray
+}
+
+// CIDFont exposes the content of a CID-keyed Type 1 font
+// (CIDFontType 0), as described in the Adobe Technical Note #5014.
+// Such fonts are used to store large character collections, typically CJK.
+//
+// The glyphs are numbered by increasing CID, so that the glyph index (GID)
+// of a glyph may be different from its CID. Use `GIDForCID` and `CIDForGID`
+// to convert between the two.
+type CIDFont struct {
+	CIDFontName   string
+	CIDSystemInfo CIDSystemInfo
+	FontBBox      []Fl
+	FontMatrix    []Fl
+
+	// FDArray stores the font dictionaries, with their own Private dictionary.
+	FDArray []CIDFontDict
+
+	// CIDCount is the number of CIDs in the CIDMap, including
+	// the ones with no glyph.
+	CIDCount int
+
+	glyphs   []cidGlyph // indexed by GID
+	cidToGID map[uint16]fonts.GID
+
+	// where to read the CID map in the binary data
+	cidMapOffset, fdBytes, gdBytes int
+}
+
+// ParseCID parses a CID-keyed PostScript font (CIDFontType 0), in
+// the form of a /CIDInit ProcSet resource, whose data is either binary
+// or hex encoded.
+func ParseCID(file fonts.Resource) (*CIDFont, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	font, err := parseCID(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDFont file: %s", err)
+	}
+	return font, nil
+}
+
+func parseCID(data []byte) (*CIDFont, error) {
+	const startData = "StartData"
+	index := bytes.Index(data, []byte(startData))
+	if index == -1 {
+		return nil, errors.New("missing StartData")
+	}
+	header := data[:index]
+
+	var (
+		out CIDFont
+		p   parser
+	)
+	p.lexer = newLexer(header)
+	isHex, length, err := p.readCIDHeader(&out)
+	if err != nil {
+		return nil, err
+	}
+
+	// the data starts after exactly one white space
+	binaryData := data[index+len(startData):]
+	if len(binaryData) == 0 || !tk.IsAsciiWhitespace(binaryData[0]) {
+		return nil, errors.New("invalid StartData operator")
+	}
+	binaryData = binaryData[1:]
+	if isHex {
+		end := bytes.IndexByte(binaryData, '>')
+		if end == -1 {
+			return nil, errors.New("unterminated hex data")
+		}
+		binaryData = hexToBinary(binaryData[:end])
+	}
+	if length > len(binaryData) {
+		return nil, fmt.Errorf("invalid data length %d (for %d)", length, len(binaryData))
+	}
+	binaryData = binaryData[:length]
+
+	for i := range out.FDArray {
+		if err = out.FDArray[i].readSubrs(binaryData); err != nil {
+			return nil, err
+		}
+	}
+	if err = out.readCIDMap(binaryData); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// readCIDHeader parses the PostScript code preceding the binary data,
+// returning the data format and length found in the "(Binary) <length> StartData"
+// sequence.
+func (p *parser) readCIDHeader(font *CIDFont) (isHex bool, length int, err error) {
+	// the resource is wrapped in various procsets and dictionaries,
+	// so we simply look for the keys we are interested in
+	for {
+		token, err := p.lexer.nextToken()
+		if err != nil {
+			return false, 0, err
+		}
+		if token.Kind == 0 || token.Kind == tk.EOF {
+			break
+		}
+		switch token.Kind {
+		case tk.String: // (Binary) or (Hex)
+			isHex = string(token.Value) == "Hex"
+		case tk.Integer:
+			length, _ = token.Int()
+		case tk.Name:
+			err = p.readCIDValue(string(token.Value), font)
+		}
+		if err != nil {
+			return false, 0, err
+		}
+	}
+	if len(font.FDArray) == 0 {
+		return false, 0, errors.New("missing FDArray")
+	}
+	return isHex, length, nil
+}
+
+func (p *parser) readCIDValue(key string, font *CIDFont) error {
+	var (
+		value []tk.Token
+		err   error
+	)
+	switch key {
+	case "CIDSystemInfo":
+		var dict map[string][]tk.Token
+		dict, err = p.readSimpleDict()
+		if err != nil {
+			return err
+		}
+		for key, value := range dict {
+			if len(value) == 0 {
+				continue
+			}
+			switch key {
+			case "Registry":
+				font.CIDSystemInfo.Registry = string(value[0].Value)
+			case "Ordering":
+				font.CIDSystemInfo.Ordering = string(value[0].Value)
+			case "Supplement":
+				font.CIDSystemInfo.Supplement, _ = value[0].Int()
+			}
+		}
+		return nil
+	case "FDArray":
+		font.FDArray, err = p.readFDArray()
+		return err
+	case "CIDFontName", "FontBBox", "FontMatrix", "CIDMapOffset", "FDBytes", "GDBytes", "CIDCount":
+		value, err = p.readDictValue()
+		if err != nil {
+			return err
+		}
+		if len(value) == 0 {
+			return fmt.Errorf("missing value for key %s", key)
+		}
+	default: // ignored
+		return nil
+	}
+	switch key {
+	case "CIDFontName":
+		font.CIDFontName = string(value[0].Value)
+	case "FontBBox":
+		font.FontBBox, err = p.arrayToNumbers(value)
+	case "FontMatrix":
+		font.FontMatrix, err = p.arrayToNumbers(value)
+	case "CIDMapOffset":
+		font.cidMapOffset, err = value[0].Int()
+		if err == nil && font.cidMapOffset < 0 {
+			err = fmt.Errorf("invalid CIDMapOffset %d", font.cidMapOffset)
+		}
+	case "FDBytes":
+		font.fdBytes, err = value[0].Int()
+		if err == nil && font.fdBytes != 0 && font.fdBytes != 1 {
+			err = fmt.Errorf("unsupported FDBytes %d", font.fdBytes)
+		}
+	case "GDBytes":
+		font.gdBytes, err = value[0].Int()
+		if err == nil && (font.gdBytes < 0 || font.gdBytes > 4) {
+			err = fmt.Errorf("invalid GDBytes %d", font.gdBytes)
+		}
+	case "CIDCount":
+		font.CIDCount, err = value[0].Int()
+		if err == nil && (font.CIDCount < 0 || font.CIDCount > 0x10000) {
+			err = fmt.Errorf("invalid CIDCount %d", font.CIDCount)
+		}
+	}
+	return err
+}
+
+// Reads the /FDArray array, of the form
+// "N array dup 0 <font dict> put ... def".
+func (p *parser) readFDArray() ([]CIDFontDict, error) {
+	lengthT, err := p.read(tk.Integer)
+	if err != nil {
+		return nil, err
+	}
+	length, _ := lengthT.Int()
+	if length > 256 {
+		return nil, fmt.Errorf("invalid FDArray length %d", length)
+	}
+	if err = p.readWithName(tk.Other, "array"); err != nil {
+		return nil, err
+	}
+	fds := make([]CIDFontDict, length)
+	for i := 0; i < length; i++ {
+		// premature end
+		if !p.lexer.peekToken().IsOther("dup") {
+			break
+		}
+		if err = p.readWithName(tk.Other, "dup"); err != nil {
+			return nil, err
+		}
+		indexT, err := p.read(tk.Integer)
+		if err != nil {
+			return nil, err
+		}
+		index, _ := indexT.Int()
+		if index < 0 || index >= length {
+			return nil, fmt.Errorf("out of range font dict index %d (for %d)", index, length)
+		}
+		fds[index], err = p.readCIDFontDict()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.readPut(); err != nil {
+			return nil, err
+		}
+	}
+	err = p.readDef()
+	return fds, err
+}
+
+// Reads one font dict of the FDArray, of the form
+// "N dict begin ... currentdict end".
+func (p *parser) readCIDFontDict() (CIDFontDict, error) {
+	out := CIDFontDict{lenIV: 4, Private: defaultPrivateDict()}
+	if _, err := p.read(tk.Integer); err != nil {
+		return out, err
+	}
+	if err := p.readWithName(tk.Other, "dict"); err != nil {
+		return out, err
+	}
+	if _, err := p.readMaybe(tk.Other, "dup"); err != nil {
+		return out, err
+	}
+	if err := p.readWithName(tk.Other, "begin"); err != nil {
+		return out, err
+	}
+	for p.lexer.peekToken().Kind == tk.Name {
+		keyT, err := p.read(tk.Name)
+		if err != nil {
+			return out, err
+		}
+		switch key := string(keyT.Value); key {
+		case "Private":
+			err = p.readCIDPrivate(&out)
+		case "FontInfo":
+			_, err = p.readSimpleDict()
+		default:
+			var value []tk.Token
+			value, err = p.readDictValue()
+			if err != nil {
+				return out, err
+			}
+			if len(value) == 0 {
+				continue
+			}
+			switch key {
+			case "FontName":
+				out.FontName = string(value[0].Value)
+			case "FontMatrix":
+				out.FontMatrix, err = p.arrayToNumbers(value)
+			}
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+	if _, err := p.readMaybe(tk.Other, "currentdict"); err != nil {
+		return out, err
+	}
+	err := p.readWithName(tk.Other, "end")
+	return out, err
+}
+
+// Reads the /Private dictionary of a font dict, which
+// also stores the location of the subroutines.
+func (p *parser) readCIDPrivate(fd *CIDFontDict) error {
+	if _, err := p.read(tk.Integer); err != nil {
+		return err
+	}
+	if err := p.readWithName(tk.Other, "dict"); err != nil {
+		return err
+	}
+	if _, err := p.readMaybe(tk.Other, "dup"); err != nil {
+		return err
+	}
+	if err := p.readWithName(tk.Other, "begin"); err != nil {
+		return err
+	}
+	for p.lexer.peekToken().Kind == tk.Name {
+		key, err := p.read(tk.Name)
+		if err != nil {
+			return err
+		}
+		if string(key.Value) == "OtherSubrs" {
+			if err = p.readOtherSubrs(); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := p.readDictValue()
+		if err != nil {
+			return err
+		}
+		if len(value) == 0 {
+			continue
+		}
+		switch string(key.Value) {
+		case "lenIV":
+			fd.lenIV, err = value[0].Int()
+			if err == nil && fd.lenIV < -1 {
+				err = fmt.Errorf("invalid lenIV %d", fd.lenIV)
+			}
+		case "SubrMapOffset":
+			fd.subrMapOffset, err = value[0].Int()
+		case "SDBytes":
+			fd.sdBytes, err = value[0].Int()
+		case "SubrCount":
+			fd.subrCount, err = value[0].Int()
+		default:
+			err = p.readPrivate(key.Value, value, &fd.Private)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := p.readWithName(tk.Other, "end"); err != nil {
+		return err
+	}
+	return p.readDef()
+}
+
+// readOffsets reads `count` offsets of `size` bytes, starting at `start`.
+func readOffsets(data []byte, start, size, count int) ([]int, error) {
+	if start < 0 || size < 0 || size > 4 || count < 0 || start+size*count > len(data) {
+		return nil, fmt.Errorf("invalid offsets array (start %d, size %d, count %d)", start, size, count)
+	}
+	out := make([]int, count)
+	for i := range out {
+		var v int
+		for _, b := range data[start+i*size : start+(i+1)*size] {
+			v = v<<8 | int(b)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// readSubrs uses the SubrMap to fetch the subroutines.
+func (fd *CIDFontDict) readSubrs(data []byte) error {
+	if fd.subrCount == 0 {
+		return nil
+	}
+	offsets, err := readOffsets(data, fd.subrMapOffset, fd.sdBytes, fd.subrCount+1)
+	if err != nil {
+		return err
+	}
+	fd.subrs = make([][]byte, fd.subrCount)
+	for i := range fd.subrs {
+		start, end := offsets[i], offsets[i+1]
+		if start > end || end > len(data) {
+			return fmt.Errorf("invalid subroutine offsets %d, %d", start, end)
+		}
+		subr := append([]byte(nil), data[start:end]...)
+		fd.subrs[i] = decrypt(subr, CHARSTRING_KEY, fd.lenIV)
+	}
+	return nil
+}
+
+// readCIDMap uses the CIDMap to fetch the charstrings.
+func (f *CIDFont) readCIDMap(data []byte) error {
+	// the header values are checked when parsed, but the fields may also be set directly
+	if f.CIDCount < 0 || f.CIDCount > 0x10000 {
+		return fmt.Errorf("invalid CIDCount %d", f.CIDCount)
+	}
+	if f.fdBytes != 0 && f.fdBytes != 1 {
+		return fmt.Errorf("unsupported FDBytes %d", f.fdBytes)
+	}
+	entrySize := f.fdBytes + f.gdBytes
+	start, count := f.cidMapOffset, f.CIDCount+1
+	if start < 0 || f.gdBytes < 0 || f.gdBytes > 4 || start+entrySize*count > len(data) {
+		return errors.New("invalid CIDMap")
+	}
+	f.cidToGID = make(map[uint16]fonts.GID)
+	f.glyphs = f.glyphs[:0]
+	for cid := 0; cid < f.CIDCount; cid++ {
+		pos := start + cid*entrySize
+		var fd uint8
+		if f.fdBytes == 1 {
+			fd = data[pos]
+		}
+		// the end of the charstring is given by the next entry
+		offsets, err := readOffsets(data, pos+f.fdBytes, f.gdBytes, 1)
+		if err != nil {
+			return err
+		}
+		nextOffsets, err := readOffsets(data, pos+entrySize+f.fdBytes, f.gdBytes, 1)
+		if err != nil {
+			return err
+		}
+		begin, end := offsets[0], nextOffsets[0]
+		if begin == end { // no glyph for this CID
+			continue
+		}
+		if begin > end || end > len(data) {
+			return fmt.Errorf("invalid charstring offsets %d, %d for CID %d", begin, end, cid)
+		}
+		if int(fd) >= len(f.FDArray) {
+			return fmt.Errorf("invalid font dict index %d for CID %d", fd, cid)
+		}
+		charstring := append([]byte(nil), data[begin:end]...)
+		f.cidToGID[uint16(cid)] = fonts.GID(len(f.glyphs))
+		f.glyphs = append(f.glyphs, cidGlyph{
+			data: decrypt(charstring, CHARSTRING_KEY, f.FDArray[fd].lenIV),
+			cid:  uint16(cid),
+			fd:   fd,
+		})
+	}
+	return nil
+}
+
+// NumGlyphs returns the number of glyphs in the font,
+// which may be less than `CIDCount`.
+func (f *CIDFont) NumGlyphs() int { return len(f.glyphs) }
+
+// GIDForCID returns the glyph index for the given CID, or false
+// if the font has no glyph for it.
+func (f *CIDFont) GIDForCID(cid uint16) (fonts.GID, bool) {
+	gid, ok := f.cidToGID[cid]
+	return gid, ok
+}
+
+// CIDForGID returns the CID of the given glyph, or false
+// for invalid glyph indexes.
+func (f *CIDFont) CIDForGID(gid fonts.GID) (uint16, bool) {
+	if int(gid) >= len(f.glyphs) {
+		return 0, false
+	}
+	return f.glyphs[gid].cid, true
+}
+
+// CIDs returns the sorted list of the CIDs with a glyph.
+func (f *CIDFont) CIDs() []uint16 {
+	out := make([]uint16, len(f.glyphs))
+	for i, g := range f.glyphs {
+		out[i] = g.cid
+	}
+	return out
+}
+
+// FontDict returns the font dict used by the given glyph,
+// or nil for invalid glyph indexes.
+func (f *CIDFont) FontDict(gid fonts.GID) *CIDFontDict {
+	if int(gid) >= len(f.glyphs) {
+		return nil
+	}
+	return &f.FDArray[f.glyphs[gid].fd]
+}
+
+// LoadGlyph interprets the charstring of the given glyph,
+// returning its outline, bounds and advance.
+// Since there is no encoding, the 'seac' operator is not supported.
+func (f *CIDFont) LoadGlyph(gid fonts.GID) (Glyph, error) {
+	if int(gid) >= len(f.glyphs) {
+		return Glyph{}, errors.New("invalid glyph index")
+	}
+	glyph := f.glyphs[gid]
+	var (
+		psi    ps.Machine
+		parser type1CharstringParser
+	)
+	err := psi.Run(glyph.data, f.FDArray[glyph.fd].subrs, nil, &parser)
+	if err != nil {
+		return Glyph{}, err
+	}
+	if parser.seac != nil {
+		return Glyph{}, errors.New("unsupported seac operator in CIDFont")
+	}
+	return Glyph{
+		Outline: fonts.GlyphOutline{Segments: parser.cs.Segments},
+		Bounds:  parser.cs.Bounds,
+		Advance: parser.advance.X,
+	}, nil
+}
@@ -0,0 +1,217 @@
+package type1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// buildCIDFont returns a minimal CID-keyed font, with two font dicts,
+// glyphs for CIDs 0, 1 and 3, and one subroutine for the second font dict.
+func buildCIDFont(asHex bool) []byte {
+	const (
+		fdBytes = 1
+		gdBytes = 2
+		sdBytes = 2
+	)
+	subr := buildCharstring(0, 0, opRmoveto, 100, 0, opRlineto, 0, 100, opRlineto, opClosepath, opReturn)
+	type glyph struct {
+		fd   byte
+		data []byte
+	}
+	glyphs := []glyph{
+		{0, buildCharstring(0, 1000, opHsbw, opEndchar)},
+		{0, buildCharstring(0, 500, opHsbw, 0, 0, opRmoveto, 200, 200, opRlineto, opClosepath, opEndchar)},
+		{0, nil}, // no glyph for CID 2
+		{1, buildCharstring(10, 800, opHsbw, 0, csOp(10, false), opEndchar)},
+	}
+
+	// layout: SubrMap | CIDMap | subrs | charstrings
+	const subrMapOffset = 0
+	cidMapOffset := subrMapOffset + 2*sdBytes
+	dataOffset := cidMapOffset + (len(glyphs)+1)*(fdBytes+gdBytes)
+
+	var data []byte
+	putOffset := func(v, size int) {
+		for i := size - 1; i >= 0; i-- {
+			data = append(data, byte(v>>(8*i)))
+		}
+	}
+	encSubr := encrypt(subr, CHARSTRING_KEY, 4)
+	putOffset(dataOffset, sdBytes)
+	putOffset(dataOffset+len(encSubr), sdBytes)
+	offset := dataOffset + len(encSubr)
+	var charstrings []byte
+	for _, g := range glyphs {
+		data = append(data, g.fd)
+		putOffset(offset, gdBytes)
+		if g.data != nil {
+			cs := encrypt(g.data, CHARSTRING_KEY, 4)
+			charstrings = append(charstrings, cs...)
+			offset += len(cs)
+		}
+	}
+	data = append(data, 0)
+	putOffset(offset, gdBytes)
+	data = append(data, encSubr...)
+	data = append(data, charstrings...)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `%%!PS-Adobe-3.0 Resource-CIDFont
+%%%%DocumentNeededResources: ProcSet (CIDInit)
+%%%%BeginResource: CIDFont (TestCID)
+/CIDInit /ProcSet findresource begin
+20 dict begin
+/CIDFontName /TestCID def
+/CIDFontType 0 def
+/CIDSystemInfo 3 dict dup begin
+/Registry (Adobe) def
+/Ordering (Japan1) def
+/Supplement 2 def
+end def
+/FontBBox [0 -120 1000 880] def
+/CIDMapOffset %d def
+/FDBytes %d def
+/GDBytes %d def
+/CIDCount %d def
+/FDArray 2 array
+dup 0
+%%ADOBeginFontDict
+9 dict begin
+/FontName /TestCID-Alpha def
+/FontType 1 def
+/FontMatrix [0.001 0 0 0.001 0 0] def
+/PaintType 0 def
+/Private 8 dict dup begin
+/MinFeature {16 16} def
+/BlueValues [-12 0 500 512] def
+/StdHW [40] def
+/StdVW [70] def
+/SubrMapOffset 0 def
+/SDBytes 2 def
+/SubrCount 0 def
+end def
+currentdict end
+%%ADOEndFontDict
+put
+dup 1
+%%ADOBeginFontDict
+9 dict begin
+/FontName /TestCID-Kanji def
+/FontType 1 def
+/FontMatrix [0.001 0 0 0.001 0 0] def
+/PaintType 0 def
+/Private 8 dict dup begin
+/MinFeature {16 16} def
+/StdVW [90] def
+/LanguageGroup 1 def
+/SubrMapOffset %d def
+/SDBytes %d def
+/SubrCount 1 def
+end def
+currentdict end
+%%ADOEndFontDict
+put
+def
+`, cidMapOffset, fdBytes, gdBytes, len(glyphs), subrMapOffset, sdBytes)
+
+	if asHex {
+		fmt.Fprintf(&b, "%%%%BeginData: %d Binary Bytes\n(Hex) %d StartData\n", len(data), len(data))
+		b.WriteString(strings.ToUpper(hex.EncodeToString(data)))
+		b.WriteString(">\n")
+	} else {
+		fmt.Fprintf(&b, "%%%%BeginData: %d Binary Bytes\n(Binary) %d StartData ", len(data), len(data))
+		b.Write(data)
+	}
+	b.WriteString("\n%%EndData\n%%EndResource\n%%EOF\n")
+	return b.Bytes()
+}
+
+func TestParseCID(t *testing.T) {
+	for _, asHex := range []bool{false, true} {
+		font, err := ParseCID(bytes.NewReader(buildCIDFont(asHex)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if font.CIDFontName != "TestCID" || font.CIDCount != 4 {
+			t.Fatalf("unexpected font %s %d", font.CIDFontName, font.CIDCount)
+		}
+		if exp := (CIDSystemInfo{"Adobe", "Japan1", 2}); font.CIDSystemInfo != exp {
+			t.Fatalf("unexpected CIDSystemInfo %v", font.CIDSystemInfo)
+		}
+		if len(font.FDArray) != 2 {
+			t.Fatalf("unexpected FDArray length %d", len(font.FDArray))
+		}
+		if fd := font.FDArray[0]; fd.FontName != "TestCID-Alpha" || fd.Private.StdVW != 70 || len(fd.Private.BlueValues) != 4 {
+			t.Fatalf("unexpected font dict %v", fd)
+		}
+		if fd := font.FDArray[1]; fd.FontName != "TestCID-Kanji" || fd.Private.StdVW != 90 || fd.Private.LanguageGroup != 1 {
+			t.Fatalf("unexpected font dict %v", fd)
+		}
+
+		if font.NumGlyphs() != 3 {
+			t.Fatalf("unexpected number of glyphs %d", font.NumGlyphs())
+		}
+		if _, ok := font.GIDForCID(2); ok {
+			t.Fatal("unexpected glyph for CID 2")
+		}
+		gid, ok := font.GIDForCID(3)
+		if !ok || gid != 2 {
+			t.Fatalf("unexpected GID %d for CID 3", gid)
+		}
+		if cid, _ := font.CIDForGID(gid); cid != 3 {
+			t.Fatalf("unexpected CID %d for GID 2", cid)
+		}
+		if cids := font.CIDs(); len(cids) != 3 || cids[2] != 3 {
+			t.Fatalf("unexpected CIDs %v", cids)
+		}
+		if fd := font.FontDict(gid); fd.FontName != "TestCID-Kanji" {
+			t.Fatalf("unexpected font dict %s", fd.FontName)
+		}
+
+		for gid, exp := range []struct {
+			advance  int32
+			min, max [2]int32
+		}{
+			{1000, [2]int32{}, [2]int32{}},
+			{500, [2]int32{0, 0}, [2]int32{200, 200}},
+			{800, [2]int32{10, 0}, [2]int32{110, 100}}, // using a subroutine
+		} {
+			glyph, err := font.LoadGlyph(fonts.GID(gid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if glyph.Advance != exp.advance {
+				t.Fatalf("unexpected advance %d for glyph %d", glyph.Advance, gid)
+			}
+			if b := glyph.Bounds; [2]int32{b.Min.X, b.Min.Y} != exp.min || [2]int32{b.Max.X, b.Max.Y} != exp.max {
+				t.Fatalf("unexpected bounds %v for glyph %d", b, gid)
+			}
+		}
+		if _, err := font.LoadGlyph(3); err == nil {
+			t.Fatal("expected error for invalid glyph")
+		}
+	}
+}
+
+func TestParseCIDInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("%!PS-Adobe-3.0 Resource-CIDFont\n"),
+		[]byte("%!PS-Adobe-3.0 Resource-CIDFont\n(Binary) 0 StartData "),
+		bytes.Replace(buildCIDFont(false), []byte("/CIDCount 4"), []byte("/CIDCount 400"), 1),
+		bytes.Replace(buildCIDFont(false), []byte("/CIDCount 4"), []byte("/CIDCount -1"), 1),
+		bytes.Replace(buildCIDFont(false), []byte("/FDBytes 1"), []byte("/FDBytes -1"), 1),
+		bytes.Replace(buildCIDFont(false), []byte("/FDBytes 1"), []byte("/FDBytes 2"), 1),
+		bytes.Replace(buildCIDFont(false), []byte("/CIDMapOffset "), []byte("/CIDMapOffset -"), 1),
+		bytes.Replace(buildCIDFont(false), []byte("/StdVW [70] def"), []byte("/StdVW [70] def\n/lenIV -2 def"), 1),
+	} {
+		if _, err := ParseCID(bytes.NewReader(data)); err == nil {
+			t.Fatal("expected error for invalid CIDFont")
+		}
+	}
+}
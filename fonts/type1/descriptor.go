@@ -20,13 +20,13 @@ type fontDescriptor struct {
 // ScanFont lazily parse `file` to extract the information about the font.
 // If no error occurs, the returned slice has always length 1.
 func ScanFont(file fonts.Resource) ([]fonts.FontDescriptor, error) {
-	seg1, _, err := openPfb(file)
+	seg1, _, err := openPfb(file, false)
 	if err != nil {
-		return nil, fmt.Errorf("invalid .pfb font file: %s", err)
+		return nil, fmt.Errorf("invalid Type1 font file: %s", err)
 	}
-	font, err := parse(seg1, nil)
+	font, err := parse(seg1, nil, false)
 	if err != nil {
-		return nil, fmt.Errorf("invalid .pfb font file: %s", err)
+		return nil, fmt.Errorf("invalid Type1 font file: %s", err)
 	}
 
 	fd := fontDescriptor{
@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
 )
 
 // font metrics
@@ -32,6 +33,59 @@ func (f *Font) Upem() uint16 {
 	return upemY
 }
 
+// unitsMatrix maps the charstring coordinates to font units (see Upem).
+type unitsMatrix [6]float64
+
+// unitsMatrix returns the FontMatrix scaled by Upem, so that
+// the usual [0.001 0 0 0.001 0 0] matrix is mapped to the identity.
+// The boolean is false if the transformation is the identity,
+// in which case it may be skipped.
+func (f *Font) unitsMatrix() (unitsMatrix, bool) {
+	m := unitsMatrix{1, 0, 0, 1, 0, 0}
+	if len(f.FontMatrix) < 6 {
+		return m, false
+	}
+	upem := Fl(f.Upem())
+	for i, v := range f.FontMatrix[:6] {
+		m[i] = float64(v * upem) // avoid float32 to float64 rounding errors
+	}
+	// tolerate rounding errors in the matrix
+	const eps = 1e-6
+	isIdentity := math.Abs(m[0]-1) < eps && math.Abs(m[1]) < eps && math.Abs(m[2]) < eps &&
+		math.Abs(m[3]-1) < eps && math.Abs(m[4]) < eps && math.Abs(m[5]) < eps
+	return m, !isIdentity
+}
+
+func (m unitsMatrix) apply(x, y float32) (float32, float32) {
+	fx, fy := float64(x), float64(y)
+	return float32(m[0]*fx + m[2]*fy + m[4]), float32(m[1]*fx + m[3]*fy + m[5])
+}
+
+// applyBounds returns the bounding box of the transformed corners of `b`
+func (m unitsMatrix) applyBounds(b ps.PathBounds) ps.PathBounds {
+	var out ps.PathBounds
+	for i, corner := range [4]ps.Point{b.Min, {X: b.Max.X, Y: b.Min.Y}, b.Max, {X: b.Min.X, Y: b.Max.Y}} {
+		x, y := m.apply(float32(corner.X), float32(corner.Y))
+		pt := ps.Point{X: int32(math.Round(float64(x))), Y: int32(math.Round(float64(y)))}
+		if i == 0 {
+			out.Min, out.Max = pt, pt
+		} else {
+			out.Enlarge(pt)
+		}
+	}
+	return out
+}
+
+// applySegments transforms `segments` in place.
+func (m unitsMatrix) applySegments(segments []fonts.Segment) {
+	for i := range segments {
+		args := segments[i].ArgsSlice()
+		for j := range args {
+			args[j].X, args[j].Y = m.apply(args[j].X, args[j].Y)
+		}
+	}
+}
+
 func (f *Font) GlyphName(gid fonts.GID) string {
 	if int(gid) >= len(f.charstrings) {
 		return ""
@@ -39,12 +93,15 @@ func (f *Font) GlyphName(gid fonts.GID) string {
 	return f.charstrings[gid].name
 }
 
+// LineMetric returns the underline metrics, scaled to font units
+// according to the FontMatrix.
 func (f *Font) LineMetric(metric fonts.LineMetric) (float32, bool) {
+	m, _ := f.unitsMatrix()
 	switch metric {
 	case fonts.UnderlinePosition:
-		return float32(f.PSInfo.UnderlinePosition), true
+		return float32(float64(f.PSInfo.UnderlinePosition) * m[3]), true
 	case fonts.UnderlineThickness:
-		return float32(f.PSInfo.UnderlineThickness), true
+		return float32(float64(f.PSInfo.UnderlineThickness) * m[3]), true
 	default:
 		// CapHeight and XHeight are stored in .afm files
 		return 0, false
@@ -57,6 +114,13 @@ func (f *Font) FontHExtents() (fonts.FontExtents, bool) {
 		return extents, false
 	}
 	yMin, yMax := f.FontBBox[1], f.FontBBox[3]
+	if m, ok := f.unitsMatrix(); ok {
+		bbox := m.applyBounds(ps.PathBounds{
+			Min: ps.Point{X: int32(f.FontBBox[0]), Y: int32(yMin)},
+			Max: ps.Point{X: int32(f.FontBBox[2]), Y: int32(yMax)},
+		})
+		yMin, yMax = Fl(bbox.Min.Y), Fl(bbox.Max.Y)
+	}
 	// following freetype here
 	extents.Ascender = float32(yMax)
 	extents.Descender = float32(yMin)
@@ -83,14 +147,18 @@ func (f *Font) NominalGlyph(ch rune) (fonts.GID, bool) {
 }
 
 // HorizontalAdvance returns the advance of the glyph with index `index`
-// The return value is expressed in font units.
+// The return value is expressed in font units, applying the FontMatrix
+// if needed (see Upem).
 // 0 is returned for invalid index values and for invalid
 // charstring glyph data.
 func (f *Font) HorizontalAdvance(gid fonts.GID) float32 {
-	_, _, adv, err := f.loadGlyph(gid, false)
+	adv, err := f.glyphAdvance(gid)
 	if err != nil {
 		return 0
 	}
+	if m, ok := f.unitsMatrix(); ok {
+		return float32(float64(adv) * m[0])
+	}
 	return float32(adv)
 }
 
@@ -106,11 +174,16 @@ func (Font) GlyphVOrigin(fonts.GID) (x, y int32, found bool) {
 	return 0, 0, false
 }
 
+// GlyphExtents returns the bounding box of the glyph, in font units,
+// applying the FontMatrix if needed (see Upem).
 func (f *Font) GlyphExtents(glyph fonts.GID, _, _ uint16) (fonts.GlyphExtents, bool) {
 	_, bbox, _, err := f.loadGlyph(glyph, false)
 	if err != nil {
 		return fonts.GlyphExtents{}, false
 	}
+	if m, ok := f.unitsMatrix(); ok {
+		bbox = m.applyBounds(bbox)
+	}
 	return bbox.ToExtents(), true
 }
 
@@ -118,7 +191,103 @@ func (Font) NormalizeVariations(coords []float32) []float32 { return coords }
 
 var _ fonts.FaceRenderer = (*Font)(nil)
 
-// GlyphData returns the outlines of the given glyph.
+// Glyph is the result of the interpretation
+// of a glyph charstring.
+// All the values are expressed in charstring units, that is
+// before the FontMatrix is applied.
+type Glyph struct {
+	// Hints is only filled by LoadGlyphWithHints
+	Hints *Hints
+
+	Outline fonts.GlyphOutline
+	Bounds  ps.PathBounds
+	Advance int32 // horizontal advance, as defined by the 'hsbw' or 'sbw' operators
+}
+
+// Stem is an horizontal or vertical stem hint.
+type Stem struct {
+	// Edge is the bottom (or left) edge of the stem, in absolute coordinates.
+	Edge int32
+	// Width is the height (or width) of the stem.
+	// Ghost stems have a width of -20 or -21.
+	Width int32
+	// Group is incremented for each hint replacement
+	// (see the OtherSubrs 3), starting at 0.
+	Group int
+}
+
+// Flex is a flex segment: two curves which may be rendered
+// as a straight line at small sizes.
+type Flex struct {
+	// Points are the control and end points of the two curves,
+	// in absolute coordinates.
+	Points [6]ps.Point
+	// Depth is the flex height, in 1/100 of device pixel,
+	// under which the curves are rendered as a line.
+	Depth int32
+}
+
+// Hints stores the hinting operators of a glyph charstring.
+type Hints struct {
+	HStems []Stem // defined by the 'hstem' and 'hstem3' operators
+	VStems []Stem // defined by the 'vstem' and 'vstem3' operators
+	Flex   []Flex
+
+	replacements int // current group
+}
+
+// LoadGlyph interprets the charstring of the given glyph,
+// returning its outline, bounds and advance.
+// Accented glyphs defined with the 'seac' operator are resolved
+// by merging the outlines of their components.
+func (f *Font) LoadGlyph(gid fonts.GID) (Glyph, error) {
+	segments, bounds, advance, err := f.loadGlyph(gid, false)
+	if err != nil {
+		return Glyph{}, err
+	}
+	return Glyph{
+		Outline: fonts.GlyphOutline{Segments: append([]fonts.Segment(nil), segments...)},
+		Bounds:  bounds,
+		Advance: advance,
+	}, nil
+}
+
+// LoadGlyphWithHints is the same as LoadGlyph, but also collects the
+// hints of the glyph, so that a hinting-aware rasterizer may use them.
+// For accented (seac) glyphs, the hints of the base glyph are returned.
+func (f *Font) LoadGlyphWithHints(gid fonts.GID) (Glyph, error) {
+	glyph, err := f.LoadGlyph(gid)
+	if err != nil {
+		return Glyph{}, err
+	}
+	glyph.Hints, err = f.loadHints(gid)
+	return glyph, err
+}
+
+func (f *Font) loadHints(gid fonts.GID) (*Hints, error) {
+	var (
+		psi    ps.Machine
+		parser = type1CharstringParser{weights: f.weightVector(), hints: new(Hints)}
+	)
+	if err := psi.Run(f.charstrings[gid].data, f.subrs, nil, &parser); err != nil {
+		return nil, err
+	}
+	if parser.seac == nil {
+		return parser.hints, nil
+	}
+	base, err := f.seacComponent(parser.seac.bCode)
+	if err != nil {
+		return nil, err
+	}
+	parser = type1CharstringParser{weights: f.weightVector(), hints: new(Hints)}
+	if err := psi.Run(f.charstrings[base].data, f.subrs, nil, &parser); err != nil {
+		return nil, err
+	}
+	return parser.hints, nil
+}
+
+// GlyphData returns the outlines of the given glyph, in font units
+// (see Upem).
 // The returned value is either a fonts.GlyphOutline or nil if an error
 // occurred.
 func (f *Font) GlyphData(gid fonts.GID, _, _ uint16) fonts.GlyphData {
@@ -126,5 +295,9 @@ func (f *Font) GlyphData(gid fonts.GID, _, _ uint16) fonts.GlyphData {
 	if err != nil {
 		return nil
 	}
+	segments = append([]fonts.Segment(nil), segments...)
+	if m, ok := f.unitsMatrix(); ok {
+		m.applySegments(segments)
+	}
 	return fonts.GlyphOutline{Segments: segments}
 }
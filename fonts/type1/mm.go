@@ -0,0 +1,453 @@
+package type1
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	tk "github.com/benoitkugler/pstokenizer"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+)
+
+// MultipleMaster stores the information found in
+// Multiple Master fonts, which interpolate between
+// several master designs.
+// See "Adobe Technical Note #5015, Type 1 Font Format Supplement".
+type MultipleMaster struct {
+	// AxisTypes are the names of the design axes,
+	// like Weight or Width (BlendAxisTypes entry).
+	AxisTypes []string
+	// DesignPositions stores, for each master,
+	// its normalized position (0 or 1) on each axis (BlendDesignPositions entry).
+	DesignPositions [][]Fl
+	// DesignMap stores, for each axis, the piecewise linear mapping
+	// from design coordinates to normalized coordinates,
+	// as (design, normalized) pairs (BlendDesignMap entry).
+	DesignMap [][][2]Fl
+	// WeightVector stores the contribution of each master
+	// for the current instance. Its values sum to 1.
+	WeightVector []Fl
+}
+
+// readMultipleMaster extracts the Multiple Master entry `key`, if any, updating `mm`,
+// which is allocated if needed.
+func (p *parser) readMultipleMaster(mm *MultipleMaster, key string, value []tk.Token) (*MultipleMaster, error) {
+	var err error
+	switch key {
+	case "BlendAxisTypes", "BlendDesignPositions", "BlendDesignMap", "WeightVector":
+		if mm == nil {
+			mm = new(MultipleMaster)
+		}
+	default:
+		return mm, nil
+	}
+	switch key {
+	case "BlendAxisTypes":
+		mm.AxisTypes = nil
+		for _, token := range value {
+			if token.Kind == tk.Name {
+				mm.AxisTypes = append(mm.AxisTypes, string(token.Value))
+			}
+		}
+	case "BlendDesignPositions":
+		mm.DesignPositions, err = nestedArray(value)
+	case "BlendDesignMap":
+		var maps [][][]Fl
+		maps, err = nestedArray3(value)
+		mm.DesignMap = nil
+		for _, m := range maps {
+			var axis [][2]Fl
+			for _, pair := range m {
+				if len(pair) != 2 {
+					return nil, errors.New("invalid BlendDesignMap entry")
+				}
+				axis = append(axis, [2]Fl{pair[0], pair[1]})
+			}
+			mm.DesignMap = append(mm.DesignMap, axis)
+		}
+	case "WeightVector":
+		mm.WeightVector, err = p.arrayToNumbers(value)
+	}
+	return mm, err
+}
+
+// nestedArray parses an array of arrays of numbers, like [[0 0] [1 0]]
+func nestedArray(value []tk.Token) ([][]Fl, error) {
+	if len(value) < 2 || value[0].Kind != tk.StartArray {
+		return nil, errors.New("expected array")
+	}
+	var (
+		out     [][]Fl
+		current []Fl
+		depth   int
+	)
+	for _, token := range value {
+		switch token.Kind {
+		case tk.StartArray:
+			depth++
+			if depth > 2 {
+				return nil, errors.New("unexpected nested array")
+			}
+			current = nil
+		case tk.EndArray:
+			if depth == 2 {
+				out = append(out, current)
+			}
+			depth--
+		case tk.Integer, tk.Float:
+			if depth != 2 {
+				return nil, errors.New("unexpected number in nested array")
+			}
+			f, _ := token.Float()
+			current = append(current, Fl(f))
+		}
+	}
+	return out, nil
+}
+
+// nestedArray3 parses an array of arrays of arrays of numbers, like [[[0 0] [1 1]]]
+func nestedArray3(value []tk.Token) ([][][]Fl, error) {
+	if len(value) < 2 || value[0].Kind != tk.StartArray {
+		return nil, errors.New("expected array")
+	}
+	var (
+		out   [][][]Fl
+		start int
+		depth int
+	)
+	for i, token := range value {
+		switch token.Kind {
+		case tk.StartArray:
+			depth++
+			if depth == 2 {
+				start = i
+			}
+		case tk.EndArray:
+			if depth == 2 {
+				inner, err := nestedArray(value[start : i+1])
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, inner)
+			}
+			depth--
+		}
+	}
+	return out, nil
+}
+
+// NormalizeDesign maps the given design coordinates (one per axis)
+// to normalized coordinates, in [0, 1], using the BlendDesignMap.
+// Axes with no mapping are supposed to be already normalized.
+func (mm *MultipleMaster) NormalizeDesign(design []Fl) []Fl {
+	out := make([]Fl, len(design))
+	for i, coord := range design {
+		if i >= len(mm.DesignMap) || len(mm.DesignMap[i]) == 0 {
+			out[i] = clamp01(coord)
+			continue
+		}
+		m := mm.DesignMap[i]
+		if coord <= m[0][0] {
+			out[i] = m[0][1]
+			continue
+		}
+		out[i] = m[len(m)-1][1]
+		for j := 1; j < len(m); j++ {
+			if coord > m[j][0] {
+				continue
+			}
+			d0, n0, d1, n1 := m[j-1][0], m[j-1][1], m[j][0], m[j][1]
+			if d1 == d0 {
+				out[i] = n1
+			} else {
+				out[i] = n0 + (coord-d0)*(n1-n0)/(d1-d0)
+			}
+			break
+		}
+	}
+	return out
+}
+
+func clamp01(f Fl) Fl {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// Weights returns the weight vector for the given normalized coordinates.
+// The weight of each master is the product, for each axis, of
+// t or (1 - t), depending on the position of the master on the axis.
+func (mm *MultipleMaster) Weights(normalized []Fl) []Fl {
+	out := make([]Fl, len(mm.DesignPositions))
+	for i, position := range mm.DesignPositions {
+		w := Fl(1)
+		for axis, p := range position {
+			t := Fl(0)
+			if axis < len(normalized) {
+				t = clamp01(normalized[axis])
+			}
+			if p >= 0.5 {
+				w *= t
+			} else {
+				w *= 1 - t
+			}
+		}
+		out[i] = w
+	}
+	return out
+}
+
+// SetDesignCoordinates selects the instance of a Multiple Master font
+// given by `design` (one coordinate per axis, in design units), updating
+// the WeightVector used when interpreting the charstrings.
+// An error is returned for regular fonts.
+func (f *Font) SetDesignCoordinates(design []Fl) error {
+	weights, err := f.designWeights(design)
+	if err != nil {
+		return err
+	}
+	f.MM.WeightVector = weights
+	f.cache.reset(nil)
+	return nil
+}
+
+// designWeights checks `design` and returns the associated weight vector.
+func (f *Font) designWeights(design []Fl) ([]Fl, error) {
+	if f.MM == nil || len(f.MM.DesignPositions) == 0 {
+		return nil, errors.New("not a Multiple Master font")
+	}
+	if nbAxis := len(f.MM.DesignPositions[0]); len(design) != nbAxis {
+		return nil, fmt.Errorf("invalid number of coordinates: expected %d, got %d", nbAxis, len(design))
+	}
+	return f.MM.Weights(f.MM.NormalizeDesign(design)), nil
+}
+
+func (f *Font) weightVector() []Fl {
+	if f.MM == nil {
+		return nil
+	}
+	return f.MM.WeightVector
+}
+
+// blend implements the othersubrs 14 to 18, used in Multiple Master fonts
+// to interpolate `nbResults` values between the masters.
+// `args` holds the values for the first master, followed by the deltas
+// for the other masters, and is updated in place: the results are stored in
+// its first `nbResults` values.
+// With no weights, the first master is used.
+func blend(args []int32, nbResults int, weights []Fl) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	if len(args) != nbResults*len(weights) {
+		return fmt.Errorf("invalid number of arguments for blend other sub: %d", len(args))
+	}
+	deltas := args[nbResults:]
+	for i := 0; i < nbResults; i++ {
+		v := Fl(args[i])
+		for j := 1; j < len(weights); j++ {
+			v += Fl(deltas[0]) * weights[j]
+			deltas = deltas[1:]
+		}
+		args[i] = int32(math.Round(float64(v)))
+	}
+	return nil
+}
+
+// blendResults returns the number of values returned by the
+// blend othersubr `index`, or 0 if `index` is not a blend othersubr.
+func blendResults(index int32) int {
+	switch index {
+	case 14, 15, 16, 17:
+		return int(index) - 13
+	case 18:
+		return 6
+	default:
+		return 0
+	}
+}
+
+// Instance returns a font for the design instance given
+// by `design` (see SetDesignCoordinates). `f` is not modified.
+// The charstrings and subroutines are rewritten so that the blend
+// othersubrs are replaced by their result.
+// Note that the other parts of the font are copied as they are: when written,
+// the returned font still contains the Multiple Master entries (like /Blend
+// or /WeightVector), as well as the blended values of its Private dictionary.
+// Only blends whose arguments are literal numbers are supported.
+func (f *Font) Instance(design []Fl) (*Font, error) {
+	weights, err := f.designWeights(design)
+	if err != nil {
+		return nil, err
+	}
+
+	out := *f
+	out.MM = nil
+	out.cache = newGlyphCache(f.cache.size())
+	out.names = new(nameIndex)
+	out.subrs = make([][]byte, len(f.subrs))
+	for i, subr := range f.subrs {
+		flat, err := flattenBlends(subr, weights)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subroutine %d: %s", i, err)
+		}
+		out.subrs[i] = flat
+	}
+	out.charstrings = make([]charstring, len(f.charstrings))
+	for i, cs := range f.charstrings {
+		flat, err := flattenBlends(cs.data, weights)
+		if err != nil {
+			return nil, fmt.Errorf("invalid charstring for glyph %s: %s", cs.name, err)
+		}
+		out.charstrings[i] = charstring{name: cs.name, data: flat}
+	}
+	out.synthesizeCmap()
+	return &out, nil
+}
+
+// csItem is either a number or an operator
+type csItem struct {
+	op       ps.PsOperator
+	value    int32
+	isNumber bool
+}
+
+// decodeCharstring splits a Type 1 charstring into numbers and operators
+func decodeCharstring(data []byte) ([]csItem, error) {
+	var out []csItem
+	for len(data) > 0 {
+		b := data[0]
+		switch {
+		case b == 12:
+			if len(data) < 2 {
+				return nil, errors.New("invalid escaped operator")
+			}
+			out = append(out, csItem{op: ps.PsOperator{Operator: data[1], IsEscaped: true}})
+			data = data[2:]
+		case b < 32:
+			out = append(out, csItem{op: ps.PsOperator{Operator: b}})
+			data = data[1:]
+		case b < 247:
+			out = append(out, csItem{value: int32(b) - 139, isNumber: true})
+			data = data[1:]
+		case b < 251:
+			if len(data) < 2 {
+				return nil, errors.New("invalid number")
+			}
+			out = append(out, csItem{value: int32(b-247)*256 + int32(data[1]) + 108, isNumber: true})
+			data = data[2:]
+		case b < 255:
+			if len(data) < 2 {
+				return nil, errors.New("invalid number")
+			}
+			out = append(out, csItem{value: -int32(b-251)*256 - int32(data[1]) - 108, isNumber: true})
+			data = data[2:]
+		default:
+			if len(data) < 5 {
+				return nil, errors.New("invalid number")
+			}
+			out = append(out, csItem{value: int32(binary.BigEndian.Uint32(data[1:])), isNumber: true})
+			data = data[5:]
+		}
+	}
+	return out, nil
+}
+
+// appendNumber encodes `v` using the Type 1 charstring number encoding.
+func appendNumber(dst []byte, v int32) []byte {
+	switch {
+	case -107 <= v && v <= 107:
+		return append(dst, byte(v+139))
+	case 108 <= v && v <= 1131:
+		v -= 108
+		return append(dst, byte(v/256+247), byte(v%256))
+	case -1131 <= v && v <= -108:
+		v = -v - 108
+		return append(dst, byte(v/256+251), byte(v%256))
+	default:
+		return append(dst, 255, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+}
+
+// encodeCharstring is the inverse of decodeCharstring.
+func encodeCharstring(items []csItem) []byte {
+	var out []byte
+	for _, item := range items {
+		if item.isNumber {
+			out = appendNumber(out, item.value)
+		} else if item.op.IsEscaped {
+			out = append(out, 12, item.op.Operator)
+		} else {
+			out = append(out, item.op.Operator)
+		}
+	}
+	return out
+}
+
+// flattenBlends replaces the sequences
+// <args> <n> <index> callothersubr pop ... pop, where index is a blend othersubr,
+// by the blended values.
+func flattenBlends(data []byte, weights []Fl) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+	items, err := decodeCharstring(data)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out     []csItem
+		changed bool
+	)
+	for i := 0; i < len(items); i++ {
+		item := items[i]
+		if item.isNumber || !item.op.IsEscaped || item.op.Operator != 16 { // callothersubr
+			out = append(out, item)
+			continue
+		}
+		L := len(out)
+		if L < 2 || !out[L-1].isNumber || !out[L-2].isNumber {
+			return nil, errors.New("unsupported computed arguments for callothersubr")
+		}
+		nbResults := blendResults(out[L-1].value)
+		if nbResults == 0 { // other othersubrs are kept
+			out = append(out, item)
+			continue
+		}
+		nbArgs := int(out[L-2].value)
+		if L-2 < nbArgs || nbArgs < 0 {
+			return nil, errors.New("invalid number of arguments for callothersubr")
+		}
+		args := make([]int32, nbArgs)
+		for j, arg := range out[L-2-nbArgs : L-2] {
+			if !arg.isNumber {
+				return nil, errors.New("unsupported computed arguments for blend")
+			}
+			args[j] = arg.value
+		}
+		if err = blend(args, nbResults, weights); err != nil {
+			return nil, err
+		}
+		// the results are pushed back with pop operators
+		for j := 0; j < nbResults; j++ {
+			if i+1+j >= len(items) || items[i+1+j].isNumber || items[i+1+j].op != (ps.PsOperator{Operator: 17, IsEscaped: true}) {
+				return nil, errors.New("missing pop operator after blend")
+			}
+		}
+		i += nbResults
+		out = out[:L-2-nbArgs]
+		for _, v := range args[:nbResults] {
+			out = append(out, csItem{value: v, isNumber: true})
+		}
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return encodeCharstring(out), nil
+}
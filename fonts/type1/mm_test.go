@@ -0,0 +1,209 @@
+package type1
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// csOp returns the encoding of a (possibly escaped) operator
+func csOp(op byte, escaped bool) []byte {
+	if escaped {
+		return []byte{12, op}
+	}
+	return []byte{op}
+}
+
+// buildCharstring encodes a charstring made of numbers (int)
+// and operators ([]byte)
+func buildCharstring(items ...interface{}) []byte {
+	var out []byte
+	for _, item := range items {
+		switch item := item.(type) {
+		case int:
+			out = appendNumber(out, int32(item))
+		case []byte:
+			out = append(out, item...)
+		}
+	}
+	return out
+}
+
+var (
+	opHsbw          = csOp(13, false)
+	opEndchar       = csOp(14, false)
+	opRmoveto       = csOp(21, false)
+	opRlineto       = csOp(5, false)
+	opClosepath     = csOp(9, false)
+	opReturn        = csOp(11, false)
+	opCallothersubr = csOp(16, true)
+	opPop           = csOp(17, true)
+)
+
+// buildMMFont returns a minimal Multiple Master font, with one Weight axis
+// and two masters.
+func buildMMFont() []byte {
+	const cleartext = `%!PS-AdobeFont-1.0: TestMM 001.000
+14 dict begin
+/FontInfo 5 dict dup begin
+/FullName (Test MM) readonly def
+/FamilyName (Test) readonly def
+/BlendAxisTypes [/Weight] def
+/BlendDesignPositions [[0] [1]] def
+/BlendDesignMap [[[100 0] [900 1]]] def
+end readonly def
+/FontName /TestMM def
+/Encoding StandardEncoding def
+/PaintType 0 def
+/FontType 1 def
+/FontMatrix [0.001 0 0 0.001 0 0] readonly def
+/FontBBox {0 0 1000 1000} readonly def
+/WeightVector [1 0] def
+/Blend 3 dict dup begin
+/FontBBox {{0 0} {0 0} {1000 1000} {1000 1000}} def
+/Private 2 dict def
+/FontInfo 1 dict dup begin
+/ItalicAngle [0 0] def
+end readonly def
+end def
+currentdict end
+currentfile eexec
+`
+	charstrings := []charstring{
+		{name: Notdef, data: buildCharstring(0, 500, opHsbw, opEndchar)},
+		// advance: 200 to 600
+		{name: "space", data: buildCharstring(0, 200, 400, 2, 14, opCallothersubr, opPop, opHsbw, opEndchar)},
+		// square of side 100 to 200
+		{name: "A", data: buildCharstring(0, 500, opHsbw,
+			0, 0, 0, 0, 4, 15, opCallothersubr, opPop, opPop, opRmoveto,
+			100, 0, 100, 0, 4, 15, opCallothersubr, opPop, opPop, opRlineto,
+			0, 100, 0, 100, 4, 15, opCallothersubr, opPop, opPop, opRlineto,
+			-100, 0, -100, 0, 4, 15, opCallothersubr, opPop, opPop, opRlineto,
+			opClosepath, opEndchar)},
+	}
+
+	var private bytes.Buffer
+	private.WriteString(`dup /Private 8 dict dup begin
+/RD {string currentfile exch readstring pop} executeonly def
+/ND {noaccess def} executeonly def
+/NP {noaccess put} executeonly def
+/BlueValues [] def
+/MinFeature {16 16} def
+/password 5839 def
+/Subrs 4 array
+`)
+	for i := 0; i < 4; i++ {
+		data := encrypt(opReturn, CHARSTRING_KEY, 4)
+		fmt.Fprintf(&private, "dup %d %d RD ", i, len(data))
+		private.Write(data)
+		private.WriteString(" NP\n")
+	}
+	private.WriteString("ND\n2 index /CharStrings 3 dict dup begin\n")
+	for _, cs := range charstrings {
+		data := encrypt(cs.data, CHARSTRING_KEY, 4)
+		fmt.Fprintf(&private, "/%s %d RD ", cs.name, len(data))
+		private.Write(data)
+		private.WriteString(" ND\n")
+	}
+	private.WriteString("end\nend\nreadonly put\nnoaccess put\ndup /FontName get exch definefont pop\nmark currentfile closefile\n")
+
+	out := append([]byte(cleartext), encrypt(private.Bytes(), eexecKey, 4)...)
+	return out
+}
+
+func TestMultipleMaster(t *testing.T) {
+	font, err := Parse(bytes.NewReader(buildMMFont()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.MM == nil {
+		t.Fatal("expected Multiple Master font")
+	}
+	if len(font.MM.AxisTypes) != 1 || font.MM.AxisTypes[0] != "Weight" {
+		t.Fatalf("unexpected axis types %v", font.MM.AxisTypes)
+	}
+	if len(font.MM.DesignPositions) != 2 || len(font.MM.DesignMap) != 1 {
+		t.Fatalf("unexpected MM data %v", font.MM)
+	}
+
+	space, ok := font.NominalGlyph(' ')
+	if !ok {
+		t.Fatal("missing space")
+	}
+	A, _ := font.NominalGlyph('A')
+
+	// default instance
+	if adv := font.HorizontalAdvance(space); adv != 200 {
+		t.Fatalf("unexpected advance %f", adv)
+	}
+
+	// Instance does not change the selected instance
+	if _, err = font.Instance([]Fl{900}); err != nil {
+		t.Fatal(err)
+	}
+	if adv := font.HorizontalAdvance(space); adv != 200 || font.MM.WeightVector[0] != 1 {
+		t.Fatalf("unexpected advance %f after Instance", adv)
+	}
+
+	for _, test := range []struct {
+		design  Fl
+		advance float32
+		size    int32
+	}{
+		{100, 200, 100},
+		{500, 400, 150},
+		{900, 600, 200},
+		{2000, 600, 200},
+	} {
+		if err = font.SetDesignCoordinates([]Fl{test.design}); err != nil {
+			t.Fatal(err)
+		}
+		if adv := font.HorizontalAdvance(space); adv != test.advance {
+			t.Fatalf("unexpected advance %f for %f", adv, test.design)
+		}
+		glyph, err := font.LoadGlyph(A)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size := glyph.Bounds.Max.X - glyph.Bounds.Min.X; size != test.size {
+			t.Fatalf("unexpected size %d for %f", size, test.design)
+		}
+
+		instance, err := font.Instance([]Fl{test.design})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if instance.MM != nil {
+			t.Fatal("unexpected Multiple Master instance")
+		}
+		if adv := instance.HorizontalAdvance(space); adv != test.advance {
+			t.Fatalf("unexpected advance %f for instance %f", adv, test.design)
+		}
+		instanceGlyph, err := instance.LoadGlyph(A)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if instanceGlyph.Bounds != glyph.Bounds {
+			t.Fatalf("unexpected bounds for instance %f", test.design)
+		}
+
+		// the written instance uses the flattened charstrings
+		var buf bytes.Buffer
+		if err = instance.WriteSubset(&buf); err != nil {
+			t.Fatal(err)
+		}
+		written, err := Parse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if adv := written.HorizontalAdvance(fonts.GID(space)); adv != test.advance {
+			t.Fatalf("unexpected advance %f for written instance %f", adv, test.design)
+		}
+	}
+
+	if err = font.SetDesignCoordinates([]Fl{1, 2}); err == nil {
+		t.Fatal("expected error for invalid coordinates")
+	}
+}
@@ -0,0 +1,110 @@
+package type1
+
+// Reference outlines for glyphs using flex, hint replacement and seac,
+// generated with freetype (FT_LOAD_NO_SCALE | FT_LOAD_NO_HINTING).
+// Off-curve points are prefixed by '~', and the closing point of each contour
+// is omitted when it is the same as the first point.
+
+var expectedOutlines = [...]struct {
+	filename string
+	gid      int
+	contours []string
+}{
+	{ // H
+		"CalligrapherRegular.pfb", 43, []string{
+			"777,388 670,385 ~670,390 ~669,410 668,444 ~666,479 ~665,507 665,529 665,539 ~665,550 ~665,562 666,573 ~666,584 ~666,596 666,607 666,618 666,631 666,646 666,663 666,682 666,703 666,728 633,704 600,681 562,655 524,628 524,564 524,501 523,442 522,383 404,381 ~365,380 ~325,380 286,380 248,381 211,381 ~210,428 ~210,473 210,516 ~209,560 ~209,603 209,644 210,718 138,666 ~133,663 ~124,657 110,649 ~98,641 ~84,633 70,626 70,553 70,496 70,454 72,306 77,83 ~77,66 ~78,49 79,33 ~80,17 ~82,5 86,-4 ~88,-13 ~96,-17 109,-17 ~114,-17 ~119,-15 126,-12 183,28 ~196,39 ~227,57 276,83 264,99 ~259,94 ~252,91 245,91 ~236,91 ~228,95 223,102 ~220,109 ~217,118 214,130 ~212,141 ~211,154 211,168 211,213 211,270 242,273 271,275 485,275 527,272 528,187 533,54 536,22 ~539,-2 ~550,-14 568,-14 ~573,-14 ~578,-13 584,-10 ~590,-8 ~600,-2 614,7 ~628,16 ~651,33 683,56 686,59 751,103 740,121 ~733,114 ~725,111 716,111 ~712,111 ~706,112 696,116 ~688,118 ~680,126 676,138 ~670,151 ~668,175 668,212 ~668,212 ~667,255 667,255 ~667,255 ~668,270 668,270 670,286 ~674,289 ~679,294 686,298 ~693,304 ~700,310 707,317 ~731,337 ~754,361",
+		},
+	},
+	{ // bracketleft
+		"CalligrapherRegular.pfb", 62, []string{
+			"322,-53 ~311,-56 ~294,-59 271,-61 ~248,-63 ~228,-64 209,-64 ~209,-49 ~208,2 206,88 ~205,174 ~204,244 204,299 ~204,311 ~205,360 206,447 ~208,534 ~209,605 209,661 ~209,661 ~260,664 260,664 ~260,664 ~308,661 308,661 320,718 ~306,717 ~288,715 264,714 ~242,713 ~220,712 201,712 ~184,712 ~166,713 146,714 ~125,715 ~108,717 93,718 ~94,659 ~94,598 95,535 ~96,472 ~96,398 96,314 ~96,309 ~96,253 94,146 ~94,38 ~93,-52 93,-124 ~108,-123 ~125,-121 144,-120 ~164,-119 ~182,-118 197,-118 ~217,-118 ~236,-119 254,-120 ~273,-121 ~288,-123 300,-124",
+		},
+	},
+	{ // bracketright
+		"CalligrapherRegular.pfb", 64, []string{
+			"222,-124 ~221,-63 ~221,-1 220,64 ~219,129 ~219,203 219,286 ~219,291 ~220,347 220,454 ~222,560 ~222,648 222,718 ~209,717 ~192,715 169,714 ~146,713 ~126,712 108,712 ~70,712 ~39,714 15,718 -7,653 ~4,656 ~21,659 44,661 ~67,663 ~87,664 106,664 ~106,649 ~107,598 108,512 ~110,426 ~111,356 111,301 ~111,289 ~110,240 108,153 ~107,66 ~106,-5 106,-61 ~106,-61 ~55,-64 55,-64 ~55,-64 ~7,-61 7,-61 -5,-124 ~10,-123 ~32,-121 59,-120 ~86,-119 ~110,-118 130,-118 ~149,-118 ~167,-119 182,-120 ~198,-121 ~211,-123",
+		},
+	},
+	{ // e
+		"CalligrapherRegular.pfb", 72, []string{
+			"428,100 417,118 404,112 ~379,102 ~354,97 331,97 ~284,97 ~245,112 215,143 ~185,174 ~164,202 154,228 ~142,253 ~137,281 137,310 ~137,337 ~143,358 155,373 ~167,388 ~182,396 201,396 ~206,396 ~222,392 248,386 ~273,378 ~286,360 286,330 ~286,319 ~282,308 274,297 ~266,283 ~248,264 219,239 ~219,239 ~206,230 206,230 ~206,230 ~219,213 219,213 263,242 303,267 307,270 349,300 ~370,315 ~386,329 398,340 ~408,351 ~416,362 420,374 ~425,384 ~427,395 427,406 ~427,431 ~417,451 396,468 ~375,485 ~347,493 310,493 ~285,493 ~259,488 234,476 ~208,466 ~178,445 142,416 ~108,386 ~80,352 59,316 ~38,278 ~28,241 28,203 ~28,146 ~41,103 68,76 ~95,48 ~110,32 114,29 ~117,26 ~130,16 151,0 ~172,-15 ~198,-23 229,-23 ~248,-23 ~267,-19 284,-12 ~302,-4 ~318,5 331,16 ~344,26 ~371,49 410,84",
+		},
+	},
+	{ // eacute
+		"CalligrapherRegular.pfb", 112, []string{
+			"428,100 417,118 404,112 ~379,102 ~354,97 331,97 ~284,97 ~245,112 215,143 ~185,174 ~164,202 154,228 ~142,253 ~137,281 137,310 ~137,337 ~143,358 155,373 ~167,388 ~182,396 201,396 ~206,396 ~222,392 248,386 ~273,378 ~286,360 286,330 ~286,319 ~282,308 274,297 ~266,283 ~248,264 219,239 ~219,239 ~206,230 206,230 ~206,230 ~219,213 219,213 263,242 303,267 307,270 349,300 ~370,315 ~386,329 398,340 ~408,351 ~416,362 420,374 ~425,384 ~427,395 427,406 ~427,431 ~417,451 396,468 ~375,485 ~347,493 310,493 ~285,493 ~259,488 234,476 ~208,466 ~178,445 142,416 ~108,386 ~80,352 59,316 ~38,278 ~28,241 28,203 ~28,146 ~41,103 68,76 ~95,48 ~110,32 114,29 ~117,26 ~130,16 151,0 ~172,-15 ~198,-23 229,-23 ~248,-23 ~267,-19 284,-12 ~302,-4 ~318,5 331,16 ~344,26 ~371,49 410,84",
+			"389,638 304,693 159,559 214,536",
+		},
+	},
+	{ // sterling
+		"CalligrapherRegular.pfb", 133, []string{
+			"619,125 605,124 531,120 ~503,119 ~474,119 444,118 ~413,117 ~381,117 346,117 330,117 304,119 ~300,140 ~298,161 296,180 ~296,200 ~295,226 295,257 300,305 328,305 ~344,305 ~358,305 371,304 ~384,303 ~397,302 412,299 433,295 555,417 ~526,417 ~484,417 428,416 ~372,415 ~330,415 303,415 303,474 ~303,508 ~305,535 310,556 ~315,576 ~322,596 333,616 ~344,637 ~364,647 393,647 ~414,647 ~430,641 440,630 ~450,618 ~458,607 463,598 ~468,588 ~474,576 480,562 ~480,562 ~486,545 486,545 ~486,545 ~522,562 522,562 ~541,573 ~556,583 569,592 ~582,601 ~592,608 600,614 ~608,621 ~612,630 612,641 ~612,649 ~610,657 606,664 ~601,672 ~591,682 574,692 ~557,704 ~538,710 516,712 ~495,715 ~479,716 468,716 ~431,716 ~388,702 338,674 ~289,646 ~249,615 218,580 ~188,545 ~173,519 173,500 172,454 172,442 170,416 155,416 138,416 125,416 112,417 0,305 163,305 163,259 ~163,188 ~162,135 158,102 ~156,68 ~150,34 141,-1 177,1 ~210,1 ~238,1 261,2 ~284,2 ~303,2 316,2 324,1 359,-1 434,-5 489,-9 529,25 ~560,54 ~590,87",
+		},
+	},
+	{ // partialdiff
+		"CalligrapherRegular.pfb", 152, []string{
+			"777,388 670,385 ~670,390 ~669,410 668,444 ~666,479 ~665,507 665,529 665,539 ~665,550 ~665,562 666,573 ~666,584 ~666,596 666,607 666,618 666,631 666,646 666,663 666,682 666,703 666,728 633,704 600,681 562,655 524,628 524,564 524,501 523,442 522,383 404,381 ~365,380 ~325,380 286,380 248,381 211,381 ~210,428 ~210,473 210,516 ~209,560 ~209,603 209,644 210,718 138,666 ~133,663 ~124,657 110,649 ~98,641 ~84,633 70,626 70,553 70,496 70,454 72,306 77,83 ~77,66 ~78,49 79,33 ~80,17 ~82,5 86,-4 ~88,-13 ~96,-17 109,-17 ~114,-17 ~119,-15 126,-12 183,28 ~196,39 ~227,57 276,83 264,99 ~259,94 ~252,91 245,91 ~236,91 ~228,95 223,102 ~220,109 ~217,118 214,130 ~212,141 ~211,154 211,168 211,213 211,270 242,273 271,275 485,275 527,272 528,187 533,54 536,22 ~539,-2 ~550,-14 568,-14 ~573,-14 ~578,-13 584,-10 ~590,-8 ~600,-2 614,7 ~628,16 ~651,33 683,56 686,59 751,103 740,121 ~733,114 ~725,111 716,111 ~712,111 ~706,112 696,116 ~688,118 ~680,126 676,138 ~670,151 ~668,175 668,212 ~668,212 ~667,255 667,255 ~667,255 ~668,270 668,270 670,286 ~674,289 ~679,294 686,298 ~693,304 ~700,310 707,317 ~731,337 ~754,361",
+			"527,757 426,877 324,877 221,757 301,757 362,846 410,757",
+		},
+	},
+	{ // Adieresis
+		"CalligrapherRegular.pfb", 98, []string{
+			"754,77 746,96 718,101 ~707,103 ~693,116 677,139 ~661,162 ~646,188 631,214 ~616,242 ~605,263 598,278 ~590,293 ~574,330 550,387 ~526,444 ~504,496 486,540 ~466,586 ~455,614 450,628 ~446,640 ~443,651 441,658 430,696 ~426,707 ~420,715 412,718 ~404,721 ~397,722 392,719 ~381,714 ~370,708 359,703 ~348,698 ~337,692 326,685 ~315,678 ~304,673 294,668 ~283,663 ~276,660 271,658 252,601 221,513 ~212,482 ~196,443 176,396 ~154,350 ~135,307 118,268 ~101,228 ~82,186 62,140 ~42,96 ~26,63 13,42 ~0,21 ~-12,3 -23,-14 ~-12,-10 ~1,-6 15,-1 ~29,4 ~40,8 49,12 158,60 163,102 ~164,112 ~166,121 168,128 ~170,135 ~172,142 175,149 181,173 321,174 472,172 494,131 522,85 ~543,50 ~559,25 570,12 ~581,-1 ~594,-8 611,-8 ~625,-9 ~646,0 674,19 ~703,38 ~729,58",
+			"428,268 306,265 216,267 320,523",
+			"523,804 ~499,818 ~481,840 470,869 465,882 437,863 413,845 ~398,834 ~391,822 391,809 ~391,799 ~395,787 405,774 ~413,761 ~425,754 439,754 ~442,754 ~447,755 452,756",
+			"327,804 ~303,818 ~285,840 274,869 269,882 241,863 217,845 ~202,834 ~195,822 195,809 ~195,799 ~199,787 209,774 ~217,761 ~229,754 243,754 ~246,754 ~251,755 256,756",
+		},
+	},
+	{ // summation
+		"CalligrapherRegular.pfb", 153, []string{
+			"569,67 563,109 561,147 558,190 552,254 ~545,307 ~539,349 532,380 ~526,410 ~518,436 510,456 ~500,478 ~488,488 472,488 ~461,488 ~446,482 430,471 ~412,460 ~393,444 370,422 ~347,402 ~330,384 318,370 ~305,357 ~279,327 240,280 206,240 206,342 206,569 ~205,594 ~204,618 203,640 ~202,664 ~202,689 202,717 ~202,762 ~188,785 160,785 ~155,785 ~151,784 147,782 122,770 104,759 87,748 ~58,729 ~32,712 8,696 ~-17,681 ~-29,672 -28,670 -23,651 -14,657 ~0,664 ~12,667 21,667 ~35,667 ~45,658 50,641 ~56,624 ~60,608 62,595 ~65,582 ~66,562 66,537 72,443 73,417 ~72,370 ~72,320 72,268 72,102 70,45 67,-7 90,-16 ~115,46 ~144,99 178,143 ~212,187 ~236,217 251,232 ~266,247 ~277,259 285,266 ~293,273 ~304,283 318,294 ~332,305 ~346,311 360,311 ~379,311 ~392,300 400,278 ~408,255 ~414,234 418,214 ~422,195 ~426,168 430,134 ~434,99 ~437,72 438,51 ~440,30 ~441,7 441,-18 474,5 508,27 538,48",
+			"557,557 456,677 354,677 251,557 331,557 392,646 440,557",
+		},
+	},
+	{ // exclam
+		"c0419bt_.pfb", 2, []string{
+			"210,67 ~210,23 ~246,-11 301,-11 ~357,-11 ~392,22 392,67 ~392,111 ~357,144 301,144 ~245,144 ~210,111",
+			"244,546 261,229 ~262,208 ~278,192 301,192 ~324,192 ~340,208 341,229 358,546 ~358,548 ~358,549 358,551 ~358,589 ~336,612 301,612 ~266,612 ~244,589 244,551 ~244,549 ~244,548",
+		},
+	},
+	{ // percent
+		"c0419bt_.pfb", 6, []string{
+			"101,485 ~101,410 ~160,353 244,353 ~328,353 ~386,410 386,485 ~386,560 ~328,617 244,617 ~161,617 ~101,560",
+			"215,131 ~215,56 ~274,-1 358,-1 ~442,-1 ~500,56 500,131 ~500,206 ~442,262 358,262 ~275,262 ~215,206",
+			"454,399 127,269 ~115,264 ~106,253 106,242 ~106,227 ~118,215 134,215 ~138,215 ~142,216 147,218 475,348 ~487,353 ~494,364 494,375 ~494,391 ~484,402 468,402 ~464,402 ~459,401",
+			"162,485 ~162,528 ~194,559 244,559 ~294,559 ~326,528 326,485 ~326,442 ~294,411 244,411 ~194,411 ~162,442",
+			"275,131 ~275,174 ~308,205 358,205 ~408,205 ~440,174 440,131 ~440,88 ~408,56 358,56 ~308,56 ~275,88",
+		},
+	},
+	{ // Aacute
+		"c0419bt_.pfb", 150, []string{
+			"225,509 59,69 ~55,69 ~51,69 47,69 ~14,69 ~1,61 1,35 ~1,5 ~15,0 52,0 180,0 ~218,0 ~237,3 237,35 ~237,63 ~219,69 181,69 134,69 176,185 424,185 471,69 426,69 ~388,69 ~371,63 371,35 ~371,3 ~390,0 429,0 562,0 ~594,0 ~606,7 606,35 ~606,62 ~594,69 560,69 ~557,69 ~555,69 552,69 361,546 ~348,578 ~335,579 307,579 168,579 ~122,579 ~100,579 100,544 ~100,517 ~117,509 154,509",
+			"200,250 294,509 400,250",
+			"176,663 189,637 444,727 ~452,730 ~457,738 457,746 ~457,749 ~457,754 455,757 439,786 ~434,796 ~426,801 416,801 ~414,801 ~410,800 408,799",
+		},
+	},
+	{ // Acircumflex
+		"c0419bt_.pfb", 151, []string{
+			"225,509 59,69 ~55,69 ~51,69 47,69 ~14,69 ~1,61 1,35 ~1,5 ~15,0 52,0 180,0 ~218,0 ~237,3 237,35 ~237,63 ~219,69 181,69 134,69 176,185 424,185 471,69 426,69 ~388,69 ~371,63 371,35 ~371,3 ~390,0 429,0 562,0 ~594,0 ~606,7 606,35 ~606,62 ~594,69 560,69 ~557,69 ~555,69 552,69 361,546 ~348,578 ~335,579 307,579 168,579 ~122,579 ~100,579 100,544 ~100,517 ~117,509 154,509",
+			"200,250 294,509 400,250",
+			"281,719 422,644 ~425,642 ~428,642 431,642 ~440,642 ~449,649 449,660 ~449,665 ~447,670 443,673 308,779 ~300,786 ~291,789 281,789 ~271,789 ~262,786 254,779 119,673 ~115,670 ~113,665 113,660 ~113,649 ~122,642 131,642 ~134,642 ~137,642 140,644",
+		},
+	},
+	{ // B
+		"Z003-MediumItalic.t1", 1, []string{
+			"90,1 ~116,1 ~117,1 148,0 182,0 ~188,0 ~201,0 217,-1 ~284,-2 ~284,-2 302,-2 ~371,-2 ~422,11 490,44 ~580,90 ~624,147 624,220 ~624,251 ~609,280 584,299 ~563,315 ~541,321 493,327 ~593,371 ~642,426 642,490 ~642,557 ~589,585 464,585 449,585 457,616 376,581 ~290,568 ~241,553 196,526 ~116,477 ~75,407 75,319 ~75,298 ~77,284 82,254 162,291 ~153,338 ~152,345 152,365 ~152,433 ~173,475 224,508 ~263,534 ~304,546 367,550 293,254 ~251,81 ~234,59 137,49",
+			"376,292 ~395,294 ~404,294 414,294 ~493,294 ~537,259 537,197 ~537,159 ~521,115 496,87 ~469,56 ~429,40 379,40 ~352,40 ~330,42 231,53 ~329,140 ~345,164 366,254",
+			"442,557 ~490,556 ~502,554 516,547 ~540,536 ~555,507 555,474 ~555,427 ~532,379 498,353 ~467,330 ~437,321 383,320",
+		},
+	},
+	{ // E
+		"Z003-MediumItalic.t1", 4, []string{
+			"362,307 419,534 450,534 ~490,535 ~522,536 528,536 ~569,536 ~574,530 602,465 668,530 ~664,552 ~657,562 640,569 426,565 432,586 362,565 ~266,564 ~205,545 148,500 ~98,461 ~76,420 76,368 ~76,342 ~80,322 94,287 166,335 ~157,368 ~153,392 153,418 ~153,496 ~208,528 345,532 289,307 ~272,290 ~267,284 250,264 278,264 ~226,61 ~224,56 136,33 98,0 131,-1 190,-1 ~278,-2 ~286,-2 324,-4 ~443,-8 ~443,-8 489,-8 ~541,-8 ~555,-2 600,38 ~646,80 ~663,104 663,127 ~663,132 ~661,140 659,150 583,122 ~586,101 ~587,94 587,84 ~587,46 ~574,41 478,41 218,41 ~315,130 ~316,132 352,269 ~403,272 ~409,272 433,274 ~455,275 ~470,276 476,276 ~480,277 ~491,277 503,278 ~520,291 ~523,293 541,307",
+		},
+	},
+	{ // K
+		"Z003-MediumItalic.t1", 10, []string{
+			"840,-48 ~800,-104 ~779,-120 745,-120 ~710,-120 ~675,-91 646,-38 ~617,16 ~603,52 552,193 ~531,250 ~520,282 496,346 ~649,476 ~693,505 737,505 ~755,505 ~768,502 796,490 833,564 ~808,574 ~795,577 777,577 ~752,577 ~735,571 711,554 ~696,544 ~696,544 568,441 468,357 ~465,354 ~453,345 436,332 488,541 ~517,549 ~525,554 540,572 ~495,575 ~473,576 448,576 ~369,576 ~306,565 262,544 ~187,507 ~146,448 146,376 ~146,350 ~150,334 163,301 245,336 ~229,375 ~223,399 223,431 ~223,501 ~273,539 366,539 ~376,539 ~393,538 414,536 404,497 ~344,247 ~329,197 298,129 ~265,66 ~227,40 170,43 132,43 88,0 225,0 ~295,0 ~299,0 318,8 ~338,17 ~360,29 383,43 262,43 ~384,165 ~400,191 432,316 439,294 ~445,277 ~445,276 457,239 475,179 ~518,39 ~548,-30 584,-71 ~628,-121 ~689,-147 763,-147 ~796,-147 ~814,-144 851,-130",
+		},
+	},
+}
@@ -0,0 +1,298 @@
+package type1
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/boxesandglue/textlayout/fonts/glyphsnames"
+	"github.com/boxesandglue/textlayout/fonts/simpleencodings"
+)
+
+// NamePDF returns the PDF name of the font.
+func (f *Font) NamePDF() string {
+	panic("not implemented")
+}
+
+// WidthsPDF returns a /Widths array suitable for embedding in a PDF file.
+// Type 1 fonts are simple fonts, so the array is indexed by character code,
+// using the builtin Encoding of the font (or the standard encoding if the
+// font has none). It always covers the codes 0 to 255, meaning
+// the font dictionary should use /FirstChar 0 and /LastChar 255.
+// The widths are expressed in the PDF glyph space (1000 units per em),
+// using the FontMatrix to scale the advances found in the charstrings.
+func (f *Font) WidthsPDF() string {
+	scale := f.pdfScale()
+
+	enc := f.Encoding
+	if enc == nil {
+		enc = &simpleencodings.AdobeStandard
+	}
+
+	var b strings.Builder
+	b.WriteString("[")
+	for code, name := range enc {
+		if code != 0 {
+			b.WriteByte(' ')
+		}
+		var width float64
+		if gid, ok := f.GlyphIndexByName(name); ok && name != "" {
+			// use the charstring units, as FontMatrix is handled by pdfScale
+			if advance, err := f.glyphAdvance(gid); err == nil {
+				width = math.Round(10*float64(advance)*scale) / 10
+			}
+		}
+		b.WriteString(strconv.FormatFloat(width, 'f', -1, 64))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// pdfScale returns the factor used to convert font units to
+// the PDF glyph space, where 1000 units is one em.
+func (f *Font) pdfScale() float64 {
+	if len(f.FontMatrix) < 4 || f.FontMatrix[0] == 0 {
+		return 1
+	}
+	return float64(f.FontMatrix[0]) * 1000
+}
+
+// CMapPDF returns a ToUnicode CMap string to be used in a PDF file.
+// As for WidthsPDF, the character codes are given by the builtin
+// Encoding of the font (or the standard encoding).
+// The glyph names are mapped to Unicode using the Adobe Glyph List algorithm,
+// so that ligatures (like "f_f_i") are mapped to several runes.
+func (f *Font) CMapPDF() string {
+	enc := f.Encoding
+	if enc == nil {
+		enc = &simpleencodings.AdobeStandard
+	}
+	type bfchar struct {
+		code  byte
+		runes []rune
+	}
+	var chars []bfchar
+	for code, name := range enc {
+		if _, ok := f.GlyphIndexByName(name); name == "" || name == Notdef || !ok {
+			continue
+		}
+		if runes := glyphsnames.GlyphToRunes(name); len(runes) != 0 {
+			chars = append(chars, bfchar{byte(code), runes})
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CIDSystemInfo << /Registry (Adobe)/Ordering (UCS)/Supplement 0>> def
+/CMapName /Adobe-Identity-UCS def /CMapType 2 def
+1 begincodespacerange
+<00><FF>
+endcodespacerange
+`)
+	// at most 100 entries are allowed in a block
+	for len(chars) != 0 {
+		block := chars
+		if len(block) > 100 {
+			block = block[:100]
+		}
+		chars = chars[len(block):]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(block))
+		for _, char := range block {
+			fmt.Fprintf(&b, "<%02X><", char.code)
+			for _, u := range utf16.Encode(char.runes) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap CMapName currentdict /CMap defineresource pop end end")
+	return b.String()
+}
+
+// AscenderPDF returns the /Ascent value for the PDF file
+func (f *Font) AscenderPDF() int {
+	panic("not implemented")
+}
+
+// DescenderPDF returns the /Descent value for the PDF file
+func (f *Font) DescenderPDF() int {
+	panic("not implemented")
+}
+
+// CapHeightPDF returns the /CapHeight value for the PDF file
+func (f *Font) CapHeightPDF() int {
+	panic("not implemented")
+}
+
+// BoundingBoxPDF returns the /FontBBox value for the PDF file
+func (f *Font) BoundingBoxPDF() string {
+	panic("not implemented")
+}
+
+// PDF font descriptor flags, see the section 9.8.2 of the PDF specification.
+const (
+	flagFixedPitch  = 1 << 0
+	flagSerif       = 1 << 1
+	flagSymbolic    = 1 << 2
+	flagScript      = 1 << 3
+	flagNonsymbolic = 1 << 5
+	flagItalic      = 1 << 6
+)
+
+// FlagsPDF returns the /Flags value for the PDF file.
+// The font is considered symbolic if its builtin Encoding uses glyphs
+// outside of the standard Latin character set.
+// Since Type1 fonts do not store their classification, the Serif and Script
+// flags are guessed from the font names.
+func (f *Font) FlagsPDF() int {
+	var flags int
+	if f.IsFixedPitch {
+		flags |= flagFixedPitch
+	}
+	if f.ItalicAngle != 0 {
+		flags |= flagItalic
+	}
+
+	name := strings.ToLower(f.FontName + " " + f.FamilyName + " " + f.FullName)
+	if isSerifName(name) {
+		flags |= flagSerif
+	}
+	for _, script := range [...]string{"script", "calligraph", "chancery", "handwrit"} {
+		if strings.Contains(name, script) {
+			flags |= flagScript
+			break
+		}
+	}
+
+	if f.isSymbolic() {
+		flags |= flagSymbolic
+	} else {
+		flags |= flagNonsymbolic
+	}
+	return flags
+}
+
+// isSerifName guesses the presence of serifs from the
+// lower cased font name.
+func isSerifName(name string) bool {
+	if strings.Contains(name, "sans") || strings.Contains(name, "gothic") || strings.Contains(name, "grotesk") {
+		return false
+	}
+	for _, serif := range [...]string{
+		"serif", "times", "roman", "garamond", "georgia", "century", "bodoni", "palatino",
+		"baskerville", "caslon", "minion", "schoolbook", "bookman", "cheltenham", "didot",
+		"courier", "antiqua", "caecilia", "clarendon", "plantin", "sabon", "utopia",
+	} {
+		if strings.Contains(name, serif) {
+			return true
+		}
+	}
+	return false
+}
+
+// standardLatin is the set of glyph names of the standard Latin character set,
+// see Annex D of the PDF specification.
+var standardLatin = func() map[string]bool {
+	out := make(map[string]bool)
+	for _, enc := range [...]*simpleencodings.Encoding{
+		&simpleencodings.AdobeStandard, &simpleencodings.WinAnsi, &simpleencodings.MacRoman,
+	} {
+		for _, name := range enc {
+			if name != "" {
+				out[name] = true
+			}
+		}
+	}
+	return out
+}()
+
+// isSymbolic returns true if the builtin encoding of the font
+// is not a subset of the standard Latin character set.
+func (f *Font) isSymbolic() bool {
+	if f.Encoding == nil || f.Encoding == &simpleencodings.AdobeStandard {
+		return false
+	}
+	for _, name := range f.Encoding {
+		if name != "" && name != Notdef && !standardLatin[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// ItalicAnglePDF returns the /ItalicAngle value for the PDF file
+func (f *Font) ItalicAnglePDF() int {
+	panic("not implemented")
+}
+
+// StemVPDF returns the /StemV value for the PDF file.
+// It is read from the StdVW (or StemSnapV) hint of the Private dictionary,
+// and otherwise estimated from the 'l' glyph (see estimateStemV).
+func (f *Font) StemVPDF() int {
+	stemV := f.Private.StdVW
+	if stemV == 0 && len(f.Private.StemSnapV) != 0 {
+		stemV = f.Private.StemSnapV[0]
+	}
+	if stemV == 0 {
+		stemV = f.estimateStemV()
+	}
+	return int(math.Round(float64(stemV) * f.pdfScale()))
+}
+
+// estimateStemV returns the narrowest vertical stem hint of the 'l' glyph,
+// or, if it has no such hint, the width of its bounding box
+// (which includes the serifs, if any).
+func (f *Font) estimateStemV() Fl {
+	gid, ok := f.GlyphIndexByName("l")
+	if !ok {
+		return 0
+	}
+	glyph, err := f.LoadGlyphWithHints(gid)
+	if err != nil {
+		return 0
+	}
+	var stemV int32
+	for _, stem := range glyph.Hints.VStems {
+		if stem.Width > 0 && (stemV == 0 || stem.Width < stemV) { // ignore the ghost stems
+			stemV = stem.Width
+		}
+	}
+	if stemV == 0 {
+		stemV = glyph.Bounds.Max.X - glyph.Bounds.Min.X
+	}
+	return Fl(stemV)
+}
+
+// XHeightPDF returns the /XHeight value for the PDF file.
+// It is read from the alignment zones of the BlueValues entry of the
+// Private dictionary, using the lowest zone above the baseline,
+// and otherwise from the top of the 'x' glyph.
+func (f *Font) XHeightPDF() int {
+	xHeight := f.Private.xHeight()
+	if xHeight == 0 {
+		if gid, ok := f.GlyphIndexByName("x"); ok {
+			if _, bounds, _, err := f.loadGlyph(gid, false); err == nil {
+				xHeight = Fl(bounds.Max.Y)
+			}
+		}
+	}
+	return int(math.Round(float64(xHeight) * f.pdfScale()))
+}
+
+// xHeight returns the bottom of the lowest alignment zone
+// above the baseline, or 0.
+func (pr *PrivateDict) xHeight() Fl {
+	var out Fl
+	// BlueValues start with the baseline overshoot zone
+	for i := 2; i+1 < len(pr.BlueValues); i += 2 {
+		if bottom := pr.BlueValues[i]; bottom > 0 && (out == 0 || bottom < out) {
+			out = bottom
+		}
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+package type1
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/type1"
+	"github.com/boxesandglue/textlayout/fonts/simpleencodings"
+)
+
+func TestWidthsPDF(t *testing.T) {
+	for _, filename := range []string{
+		"c0419bt_.pfb",
+		"CalligrapherRegular.pfb",
+		"Z003-MediumItalic.t1",
+	} {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		widths := font.WidthsPDF()
+		if !strings.HasPrefix(widths, "[") || !strings.HasSuffix(widths, "]") {
+			t.Fatalf("invalid widths array %s", widths)
+		}
+		entries := strings.Fields(widths[1 : len(widths)-1])
+		if len(entries) != 256 {
+			t.Fatalf("expected 256 widths, got %d", len(entries))
+		}
+		// the space is encoded at 32 in all the test fonts
+		if entries[32] == "0" {
+			t.Fatalf("missing width for space in %s", filename)
+		}
+	}
+
+	b, err := testdata.Files.ReadFile("Z003-MediumItalic.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	widthsFor := func() []string {
+		widths := font.WidthsPDF()
+		return strings.Fields(widths[1 : len(widths)-1])
+	}
+	// values from the AFM file of Zapf Chancery : C 32 ; WX 220 ; N space,
+	// C 33 ; WX 280 ; N exclam, C 65 ; WX 620 ; N A
+	expected := map[byte][3]string{
+		0:   {"0", "0", "0"}, // not encoded
+		' ': {"220", "440", "107.4"},
+		'!': {"280", "560", "136.7"},
+		'A': {"620", "1240", "302.7"},
+	}
+	for i, matrix := range [...][]Fl{
+		{0.001, 0, 0, 0.001, 0, 0},
+		{0.002, 0, 0, 0.002, 0, 0},
+		{1. / 2048, 0, 0, 1. / 2048, 0, 0},
+	} {
+		font.FontMatrix = matrix
+		entries := widthsFor()
+		for code, exp := range expected {
+			if entries[code] != exp[i] {
+				t.Errorf("for code %d and FontMatrix %v, expected width %s, got %s", code, matrix, exp[i], entries[code])
+			}
+		}
+	}
+
+	// the advances are the ones found in the 'hsbw' operators
+	b, err = testdata.Files.ReadFile("c0419bt_.pfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code, width := range widthsFor() {
+		if name := font.Encoding[code]; name != "" && name != Notdef && width != "602" { // monospaced font
+			t.Errorf("unexpected width %s for glyph %s", width, name)
+		}
+	}
+}
+
+func TestFlagsPDF(t *testing.T) {
+	times := Font{Encoding: &simpleencodings.AdobeStandard}
+	times.FontName = "Times-Italic"
+	times.ItalicAngle = -15
+	if flags := times.FlagsPDF(); flags != flagSerif|flagNonsymbolic|flagItalic {
+		t.Fatalf("unexpected flags %b", flags)
+	}
+
+	var latin simpleencodings.Encoding
+	latin['A'], latin[0xC4] = "A", "Adieresis"
+	mono := Font{Encoding: &latin}
+	mono.FontName = "LetterGothic"
+	mono.IsFixedPitch = true
+	if flags := mono.FlagsPDF(); flags != flagFixedPitch|flagNonsymbolic {
+		t.Fatalf("unexpected flags %b", flags)
+	}
+
+	symbol := Font{Encoding: &simpleencodings.ZapfDingbats}
+	symbol.FontName = "ZapfDingbats"
+	if flags := symbol.FlagsPDF(); flags != flagSymbolic {
+		t.Fatalf("unexpected flags %b", flags)
+	}
+
+	for _, filename := range []string{
+		"c0419bt_.pfb",
+		"CalligrapherRegular.pfb",
+		"Z003-MediumItalic.t1",
+	} {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		flags := font.FlagsPDF()
+		if (flags&flagSymbolic == 0) == (flags&flagNonsymbolic == 0) {
+			t.Fatalf("exactly one of Symbolic and Nonsymbolic must be set, got %b", flags)
+		}
+	}
+}
+
+func TestPrivateHints(t *testing.T) {
+	b, err := testdata.Files.ReadFile("Z003-MediumItalic.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// /BlueValues [-13 0 391 411 573 591] def
+	// /StdHW [48] def
+	// /StdVW [78] def
+	if len(font.Private.BlueValues) != 6 || font.Private.StdHW != 48 || font.Private.StdVW != 78 {
+		t.Fatalf("unexpected Private dict %v", font.Private)
+	}
+	if stemV := font.StemVPDF(); stemV != 78 {
+		t.Fatalf("unexpected StemV %d", stemV)
+	}
+	if xHeight := font.XHeightPDF(); xHeight != 391 {
+		t.Fatalf("unexpected XHeight %d", xHeight)
+	}
+
+	// fallback to the glyphs : the 'l' glyph has no hints,
+	// and its bounding box is [87 -16 434 678]
+	font.Private = PrivateDict{}
+	if stemV := font.StemVPDF(); stemV != 347 {
+		t.Fatalf("unexpected StemV %d", stemV)
+	}
+	gid, _ := font.NominalGlyph('x')
+	glyph, _ := font.LoadGlyph(gid)
+	if xHeight := font.XHeightPDF(); xHeight != int(glyph.Bounds.Max.Y) {
+		t.Fatalf("unexpected XHeight %d", xHeight)
+	}
+
+	b, err = testdata.Files.ReadFile("c0419bt_.pfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stemV := font.StemVPDF(); stemV != 73 { // StdVW
+		t.Fatalf("unexpected StemV %d", stemV)
+	}
+	// fallback to the vertical stem hint of 'l' : 268 74 vstem
+	font.Private = PrivateDict{}
+	if stemV := font.StemVPDF(); stemV != 74 {
+		t.Fatalf("unexpected StemV %d", stemV)
+	}
+}
+
+func TestCMapPDF(t *testing.T) {
+	b, err := testdata.Files.ReadFile("c0419bt_.pfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// use a custom encoding, with ligatures and non standard names
+	enc := *font.Encoding
+	enc[1] = "f_f_i"
+	enc[2] = "uni00410042"
+	enc[3] = "A.sc"
+	font.Encoding = &enc
+	font.charstrings = append(font.charstrings,
+		charstring{name: "f_f_i"}, charstring{name: "uni00410042"}, charstring{name: "A.sc"})
+
+	cmap := font.CMapPDF()
+	for _, exp := range []string{
+		"<00><FF>",
+		"<41><0041>",
+		"<20><0020>",
+		"<01><006600660069>",
+		"<02><00410042>",
+		"<03><0041>",
+	} {
+		if !strings.Contains(cmap, exp) {
+			t.Fatalf("missing %s in CMap:\n%s", exp, cmap)
+		}
+	}
+	if n := strings.Count(cmap, "beginbfchar"); n != strings.Count(cmap, "endbfchar") || n < 2 {
+		t.Fatalf("invalid bfchar blocks in CMap:\n%s", cmap)
+	}
+}
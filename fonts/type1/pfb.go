@@ -3,7 +3,7 @@ package type1
 import (
 	"errors"
 	"fmt"
-	"io"
+	"math"
 	"strings"
 
 	"github.com/boxesandglue/textlayout/fonts"
@@ -26,16 +26,34 @@ func Load(file fonts.Resource) (fonts.Faces, error) {
 	return fonts.Faces{f}, nil
 }
 
-// Parse parses an Adobe Type 1 (.pfb) font file.
+// Parse parses an Adobe Type 1 font file, either in binary
+// segmented form (.pfb) or in ASCII form (.pfa), where the eexec
+// portion is hex encoded.
 // See `ParseAFMFile` to read the associated Adobe font metric file.
 func Parse(pfb fonts.Resource) (*Font, error) {
-	seg1, seg2, err := openPfb(pfb)
+	return ParseWithOptions(pfb, ParseOptions{})
+}
+
+// ParseOptions controls how damaged font files are handled.
+type ParseOptions struct {
+	// Permissive enables a recovery mode for damaged fonts, as found
+	// for instance in old PDF files: truncated eexec sections are accepted,
+	// and the charstrings found before the damaged part are kept.
+	// Also, the lenIV value is checked against the content of the charstrings,
+	// and replaced by a more plausible one if needed.
+	Permissive bool
+}
+
+// ParseWithOptions is the same as `Parse`, but allows to
+// customize the parsing with `opts`.
+func ParseWithOptions(pfb fonts.Resource, opts ParseOptions) (*Font, error) {
+	seg1, seg2, err := openPfb(pfb, opts.Permissive)
 	if err != nil {
-		return nil, fmt.Errorf("invalid .pfb font file: %s", err)
+		return nil, fmt.Errorf("invalid Type1 font file: %s", err)
 	}
-	font, err := parse(seg1, seg2)
+	font, err := parse(seg1, seg2, opts.Permissive)
 	if err != nil {
-		return nil, fmt.Errorf("invalid .pfb font file: %s", err)
+		return nil, fmt.Errorf("invalid Type1 font file: %s", err)
 	}
 
 	// we follow freetype by placing the .notdef glyph at GID 0
@@ -44,10 +62,39 @@ func Parse(pfb fonts.Resource) (*Font, error) {
 	font.checkAndSwapGlyphNotdef()
 
 	font.synthesizeCmap()
+	font.cache = newGlyphCache(0)
+	font.names = new(nameIndex)
 
 	return &font, nil
 }
 
+// PrivateDict stores the hinting values found in the /Private dictionary
+// of the font. All the values are expressed in font units.
+// Missing BlueScale, BlueShift and BlueFuzz entries are set to their
+// default values (see `defaultPrivateDict`).
+type PrivateDict struct {
+	// Alignment zones, as pairs of (bottom, top) values
+	BlueValues, OtherBlues, FamilyBlues, FamilyOtherBlues []Fl
+
+	// Dominant stems widths, or 0 if missing
+	StdHW, StdVW Fl
+
+	StemSnapH, StemSnapV []Fl
+
+	BlueScale Fl
+	BlueShift int
+	BlueFuzz  int
+
+	LanguageGroup int
+	ForceBold     bool
+}
+
+// defaultPrivateDict returns a dictionary with the default
+// values defined in the Type 1 specification.
+func defaultPrivateDict() PrivateDict {
+	return PrivateDict{BlueScale: 0.039625, BlueShift: 7, BlueFuzz: 1}
+}
+
 type charstring struct {
 	name string
 	data []byte
@@ -66,6 +113,23 @@ type Font struct {
 	charstrings []charstring // slice indexed by glyph index
 	FontMatrix  []Fl
 
+	// raw content, used to write the font back
+	cleartext []byte // the ASCII segment
+	private   []byte // the decrypted binary segment, or nil if it could not be parsed
+	spans     spans
+	lenIV     int
+
+	afm *AFMFont // optional, see SetAFM
+
+	cache *glyphCache // see SetGlyphCacheSize
+	names *nameIndex  // see GlyphIndexByName
+
+	// MM is only non nil for Multiple Master fonts
+	MM *MultipleMaster
+
+	// Private stores the hinting values of the font
+	Private PrivateDict
+
 	fonts.PSInfo
 
 	StrokeWidth Fl
@@ -160,13 +224,25 @@ func (f *Font) checkAndSwapGlyphNotdef() {
 }
 
 // Type1 fonts have no natural notion of Unicode code points
-// We use a glyph names table to identify the most commonly used runes
+// We use a glyph names table to identify the most commonly used runes,
+// following the Adobe Glyph List conventions (including uniXXXX and uXXXXXX names).
+// Names which do not map to exactly one rune (like ligatures) are skipped.
+// When several glyphs map to the same rune, the first one is used, unless
+// it is a variant (like "a.sc" or "a.alt") and a regular glyph is found later.
 func (f *Font) synthesizeCmap() {
 	f.cmap = make(map[rune]fonts.GID)
+	isVariant := make(map[rune]bool)
 	for gid, charstring := range f.charstrings {
-		glyphName := charstring.name
-		r, _ := glyphsnames.GlyphToRune(glyphName)
+		runes := glyphsnames.GlyphToRunes(charstring.name)
+		if len(runes) != 1 {
+			continue
+		}
+		r, variant := runes[0], strings.IndexByte(charstring.name, '.') != -1
+		if _, ok := f.cmap[r]; ok && (variant || !isVariant[r]) {
+			continue
+		}
 		f.cmap[r] = fonts.GID(gid)
+		isVariant[r] = variant
 	}
 }
 
@@ -180,38 +256,66 @@ func (f *Font) loadGlyph(index fonts.GID, inSeac bool) ([]fonts.Segment, ps.Path
 		return nil, ps.PathBounds{}, 0, errors.New("invalid glyph index")
 	}
 
+	if glyph, ok := f.cache.get(index); ok {
+		if inSeac && glyph.isSeac {
+			return nil, ps.PathBounds{}, 0, errors.New("invalid nested seac operator")
+		}
+		return glyph.segments, glyph.bounds, glyph.advance, nil
+	}
+
 	var (
 		psi    ps.Machine
-		parser type1CharstringParser
+		parser = type1CharstringParser{weights: f.weightVector()}
 	)
 	err := psi.Run(f.charstrings[index].data, f.subrs, nil, &parser)
 	if err != nil {
 		return nil, ps.PathBounds{}, 0, err
 	}
+	glyph := cachedGlyph{
+		segments: parser.cs.Segments,
+		bounds:   parser.cs.Bounds,
+		advance:  parser.advance.X,
+	}
 	// handle the special case of seac glyph
 	if parser.seac != nil {
 		if inSeac {
 			return nil, ps.PathBounds{}, 0, errors.New("invalid nested seac operator")
 		}
-		var (
-			bounds   ps.PathBounds
-			segments []fonts.Segment
-		)
-		segments, bounds, err = f.seacMetrics(*parser.seac)
+		glyph.segments, glyph.bounds, err = f.seacMetrics(*parser.seac)
 		if err != nil {
 			return nil, ps.PathBounds{}, 0, err
 		}
-		return segments, bounds, parser.advance.X, err
+		glyph.isSeac = true
 	}
-	return parser.cs.Segments, parser.cs.Bounds, parser.advance.X, err
+	f.cache.set(index, glyph)
+	return glyph.segments, glyph.bounds, glyph.advance, nil
+}
+
+// glyphAdvance returns the advance of the glyph with index `index`,
+// in font units, only interpreting its charstring until the
+// 'hsbw' or 'sbw' operator.
+func (f *Font) glyphAdvance(index fonts.GID) (int32, error) {
+	if int(index) >= len(f.charstrings) {
+		return 0, errors.New("invalid glyph index")
+	}
+	if glyph, ok := f.cache.get(index); ok {
+		return glyph.advance, nil
+	}
+	var (
+		psi    ps.Machine
+		parser = type1CharstringParser{weights: f.weightVector()}
+	)
+	parser.cs.WidthOnly = true
+	err := psi.Run(f.charstrings[index].data, f.subrs, nil, &parser)
+	return parser.advance.X, err
 }
 
 func (f *Font) seacMetrics(seac seac) ([]fonts.Segment, ps.PathBounds, error) {
-	aGlyph, err := f.glyphIndexFromStandardCode(seac.aCode)
+	aGlyph, err := f.seacComponent(seac.aCode)
 	if err != nil {
 		return nil, ps.PathBounds{}, err
 	}
-	bGlyph, err := f.glyphIndexFromStandardCode(seac.bCode)
+	bGlyph, err := f.seacComponent(seac.bCode)
 	if err != nil {
 		return nil, ps.PathBounds{}, err
 	}
@@ -225,105 +329,82 @@ func (f *Font) seacMetrics(seac seac) ([]fonts.Segment, ps.PathBounds, error) {
 		return nil, ps.PathBounds{}, err
 	}
 
-	// translate the accent
+	// translate the accent, so that its origin is at (adx - asb, ady)
 	// See the erratum https://adobe-type-tools.github.io/font-tech-notes/pdfs/5015.Type1_Supp.pdf
-	offsetOriginX := boundsBase.Min.X - boundsAccent.Min.X + seac.accentOrigin.X
+	offsetOriginX := seac.accentOrigin.X - seac.accentLeftSideBearing
 	offsetOriginY := seac.accentOrigin.Y
 	boundsAccent.Min.Move(offsetOriginX, offsetOriginY)
 	boundsAccent.Max.Move(offsetOriginX, offsetOriginY)
+	// the component segments may be cached: use a copy
+	segments := make([]fonts.Segment, len(segmentsBase), len(segmentsBase)+len(segmentsAccent))
+	copy(segments, segmentsBase)
 	offsetOriginXF, offsetOriginYF := float32(offsetOriginX), float32(offsetOriginY)
-	for i := range segmentsAccent {
-		argsSlice := segmentsAccent[i].ArgsSlice()
+	for _, seg := range segmentsAccent {
+		argsSlice := seg.ArgsSlice()
 		for j := range argsSlice {
 			argsSlice[j].Move(offsetOriginXF, offsetOriginYF)
 		}
+		segments = append(segments, seg)
 	}
 
 	// union with the base
 	boundsBase.Enlarge(boundsAccent.Min)
 	boundsBase.Enlarge(boundsAccent.Max)
-	segmentsBase = append(segmentsBase, segmentsAccent...)
 
-	return segmentsBase, boundsBase, nil
+	return segments, boundsBase, nil
 }
 
-func (f *Font) glyphIndexFromStandardCode(code int32) (fonts.GID, error) {
-	if code < 0 || int(code) > len(simpleencodings.AdobeStandard) {
+// seacComponent resolves the glyph used by a seac operator.
+// The code should refer to the standard encoding, but some fonts
+// use re-encoded glyphs, so that we fall back to the builtin encoding,
+// and then to the glyph names, looking for a glyph with the same
+// Unicode value as the standard one (like "A.alt" or "uni0041" for "A").
+func (f *Font) seacComponent(code int32) (fonts.GID, error) {
+	if code < 0 || int(code) >= len(simpleencodings.AdobeStandard) {
 		return 0, fmt.Errorf("invalid char code in seac: %d", code)
 	}
 	glyphName := simpleencodings.AdobeStandard[code]
-	for gid, charstring := range f.charstrings {
-		if charstring.name == glyphName {
-			return fonts.GID(gid), nil
+	if gid, ok := f.GlyphIndexByName(glyphName); ok && glyphName != "" {
+		return gid, nil
+	}
+	if f.Encoding != nil {
+		if name := f.Encoding[code]; name != "" && name != Notdef {
+			if gid, ok := f.GlyphIndexByName(name); ok {
+				return gid, nil
+			}
 		}
 	}
-	return 0, fmt.Errorf("unknown glyph name in seac: %s", glyphName)
-}
-
-func (Font) LoadBitmaps() []fonts.BitmapSize { return nil }
-
-// NamePDF returns the PDF name of the font.
-func (f *Font) NamePDF() string {
-	panic("not implemented")
-}
-
-// WidthsPDF returns a width entry suitable for embedding in a PDF file.
-func (f *Font) WidthsPDF() string {
-	panic("not implemented")
-}
-
-// CMapPDF returns a CMap string to be used in a PDF file
-func (f *Font) CMapPDF() string {
-	panic("not implemented")
-}
-
-// AscenderPDF returns the /Ascent value for the PDF file
-func (f *Font) AscenderPDF() int {
-	panic("not implemented")
-}
-
-// DescenderPDF returns the /Descent value for the PDF file
-func (f *Font) DescenderPDF() int {
-	panic("not implemented")
-}
-
-// CapHeightPDF returns the /CapHeight value for the PDF file
-func (f *Font) CapHeightPDF() int {
-	panic("not implemented")
-}
-
-// BoundingBoxPDF returns the /FontBBox value for the PDF file
-func (f *Font) BoundingBoxPDF() string {
-	panic("not implemented")
-}
-
-// FlagsPDF returns the /Flags value for the PDF file
-func (f *Font) FlagsPDF() int {
-	panic("not implemented")
-}
-
-// ItalicAnglePDF returns the /ItalicAngle value for the PDF file
-func (f *Font) ItalicAnglePDF() int {
-	panic("not implemented")
-}
-
-// StemVPDF returns the /StemV value for the PDF file
-func (f *Font) StemVPDF() int {
-	panic("not implemented")
+	if r, ok := glyphsnames.GlyphToRune(glyphName); ok && glyphName != "" {
+		if gid, ok := f.cmap[r]; ok {
+			return gid, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown glyph in seac for char code %d (%s)", code, glyphName)
 }
 
-// XHeightPDF returns the /XHeight value for the PDF file
-func (f *Font) XHeightPDF() int {
-	panic("not implemented")
+// GlyphIndexByName returns the glyph with the given name,
+// as used for instance in PDF /Differences arrays.
+// If several glyphs share the same name, the first one is returned.
+func (f *Font) GlyphIndexByName(name string) (fonts.GID, bool) {
+	return f.names.lookup(f.charstrings, name)
 }
 
-// Subset removes all data from the font except the one needed for the given
-// code points.
-func (f *Font) Subset(codepoints []fonts.GID) error {
-	panic("not implemented")
-}
+func (Font) LoadBitmaps() []fonts.BitmapSize { return nil }
 
-// WriteSubset writes a valid font to w that is suitable for including in PDF
-func (f *Font) WriteSubset(w io.Writer) error {
-	panic("not implemented")
+// SetAFM attaches the metrics read from the .afm file
+// associated to the font (see `ParseAFMFile`).
+// They are used to provide pair kerning (see `KernPair`).
+// Passing nil removes the metrics.
+func (f *Font) SetAFM(afm *AFMFont) { f.afm = afm }
+
+// KernPair returns the kerning value for the given pair, as defined
+// in the AFM file attached with `SetAFM`, or 0.
+// The value is expressed in font units and is negative when glyphs should be closer.
+func (f *Font) KernPair(left, right fonts.GID) int16 {
+	if f.afm == nil || int(left) >= len(f.charstrings) || int(right) >= len(f.charstrings) {
+		return 0
+	}
+	kern, _ := f.afm.Kerning(f.charstrings[left].name, f.charstrings[right].name)
+	// AFM values are expressed in 1/1000 of em
+	return int16(math.Round(float64(kern) / f.pdfScale()))
 }
@@ -30,9 +30,14 @@ const (
 
 	// marker of the binary segment
 	binaryMarker = 0x02
+
+	// marker of the end of file
+	eofMarker = 0x03
 )
 
-func readOneRecord(pfb fonts.Resource, expectedMarker byte, totalSize int64) ([]byte, error) {
+// readOneRecord reads a .pfb segment. If `permissive` is true,
+// truncated segments are accepted.
+func readOneRecord(pfb fonts.Resource, expectedMarker byte, totalSize int64, permissive bool) ([]byte, error) {
 	var buffer [6]byte
 
 	_, err := io.ReadFull(pfb, buffer[:])
@@ -49,20 +54,27 @@ func readOneRecord(pfb fonts.Resource, expectedMarker byte, totalSize int64) ([]
 
 	size := int64(binary.LittleEndian.Uint32(buffer[2:]))
 	if size >= totalSize {
-		return nil, errors.New("corrupted .pfb file")
+		if !permissive {
+			return nil, errors.New("corrupted .pfb file")
+		}
+		size = totalSize
 	}
 	out := make([]byte, size)
-	_, err = io.ReadFull(pfb, out)
+	n, err := io.ReadFull(pfb, out)
 	if err != nil {
+		if permissive && n != 0 {
+			return out[:n], nil
+		}
 		return nil, fmt.Errorf("invalid .pfb file: %s", err)
 	}
 	return out, nil
 }
 
-// fetchs the segments of a .pfb font file.
+// fetchs the segments of a .pfb or .pfa font file.
 // see https://www.adobe.com/content/dam/acom/en/devnet/font/pdfs/5040.Download_Fonts.pdf
 // IBM PC format
-func openPfb(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
+// If `permissive` is true, a truncated or missing binary segment is accepted.
+func openPfb(pfb fonts.Resource, permissive bool) (segment1, segment2 []byte, err error) {
 	totalSize, err := pfb.Seek(0, io.SeekEnd)
 	if err != nil {
 		return nil, nil, err
@@ -73,10 +85,11 @@ func openPfb(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
 	}
 
 	// ascii record
-	segment1, err = readOneRecord(pfb, asciiMarker, totalSize)
+	segment1, err = readOneRecord(pfb, asciiMarker, totalSize, permissive)
 	if err != nil {
-		// try with the brute force approach for file who have no tag
-		segment1, segment2, err = seekMarkers(pfb)
+		// no segment markers: this is either a .pfa file
+		// or a file with a missing tag
+		segment1, segment2, err = openPfa(pfb)
 		if err == nil {
 			return segment1, segment2, nil
 		}
@@ -84,8 +97,11 @@ func openPfb(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
 	}
 
 	// binary record
-	segment2, err = readOneRecord(pfb, binaryMarker, totalSize)
+	segment2, err = readOneRecord(pfb, binaryMarker, totalSize, permissive)
 	if err != nil {
+		if permissive {
+			return segment1, nil, nil
+		}
 		return nil, nil, err
 	}
 	// ignore the last segment, which is not needed
@@ -93,9 +109,11 @@ func openPfb(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
 	return segment1, segment2, nil
 }
 
-// fallback when no binary marker are present:
-// we look for the currentfile exec pattern, then for the cleartomark
-func seekMarkers(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
+// openPfa handles files without segment markers, which is the case of .pfa
+// files (where the eexec portion is usually hex encoded), but also of some
+// binary files.
+// We look for the currentfile eexec pattern, then for the cleartomark
+func openPfa(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
 	_, err = pfb.Seek(0, io.SeekStart)
 	if err != nil {
 		return nil, nil, err
@@ -126,11 +144,26 @@ func seekMarkers(pfb fonts.Resource) (segment1, segment2 []byte, err error) {
 	if len(segment2) != 0 && tk.IsAsciiWhitespace(segment2[0]) { // end of line
 		segment2 = segment2[1:]
 	}
+	segment2 = trimTrailer(segment2)
 	return segment1, segment2, nil
 }
 
+// trimTrailer removes the "zeros" and the cleartomark
+// found at the end of the font file.
+func trimTrailer(segment2 []byte) []byte {
+	index := bytes.LastIndex(segment2, []byte("cleartomark"))
+	if index == -1 {
+		return segment2
+	}
+	return bytes.TrimRight(segment2[:index], "0"+spaces)
+}
+
 type parser struct {
 	lexer lexer
+
+	// if true, the charstrings read before an error
+	// are kept, and lenIV is checked (see `ParseOptions`)
+	permissive bool
 }
 
 type lexer struct {
@@ -165,8 +198,8 @@ type Encoding struct {
 // See "Adobe Type 1 Font Format, Adobe Systems (1999)"
 //
 // Ported from the code from John Hewson
-func parse(segment1, segment2 []byte) (Font, error) {
-	p := parser{}
+func parse(segment1, segment2 []byte, permissive bool) (Font, error) {
+	p := parser{permissive: permissive}
 	out, err := p.parseASCII(segment1)
 	if err != nil {
 		return Font{}, err
@@ -190,6 +223,7 @@ func (p *parser) parseASCII(bytes []byte) (Font, error) {
 	}
 
 	var out Font
+	out.cleartext = bytes
 	p.lexer = newLexer(bytes)
 
 	// (corrupt?) synthetic font
@@ -248,6 +282,7 @@ func (p *parser) parseASCII(bytes []byte) (Font, error) {
 		}
 
 		// key/value
+		start := p.lexer.CurrentPosition()
 		keyT, err := p.read(tk.Name)
 		if err != nil {
 			return out, err
@@ -258,13 +293,25 @@ func (p *parser) parseASCII(bytes []byte) (Font, error) {
 			if err != nil {
 				return out, err
 			}
-			out.PSInfo = p.readFontInfo(dict)
+			p.readFontInfo(dict, &out.PSInfo)
+			for key, value := range dict {
+				out.MM, err = p.readMultipleMaster(out.MM, key, value)
+				if err != nil {
+					return out, err
+				}
+			}
 		case "Metrics":
 			_, err = p.readSimpleDict()
+		case "Blend": // Multiple Master fonts
+			err = p.skipDict()
 		case "Encoding":
 			out.Encoding, err = p.readEncoding()
+			out.spans.encoding = [2]int{start, p.lexer.CurrentPosition()}
 		default:
 			err = p.readSimpleValue(key, &out)
+			if key == "FontName" {
+				out.spans.fontName = [2]int{start, p.lexer.CurrentPosition()}
+			}
 		}
 		if err != nil {
 			return out, err
@@ -315,6 +362,8 @@ func (p *parser) readSimpleValue(key string, font *Font) error {
 		font.FontMatrix, err = p.arrayToNumbers(value)
 	case "FontBBox":
 		font.FontBBox, err = p.arrayToNumbers(value)
+	default:
+		font.MM, err = p.readMultipleMaster(font.MM, key, value)
 	}
 	return err
 }
@@ -405,8 +454,9 @@ func (p *parser) arrayToNumbers(value []tk.Token) ([]Fl, error) {
 }
 
 // Extracts values from the /FontInfo dictionary.
-func (p *parser) readFontInfo(fontInfo map[string][]tk.Token) fonts.PSInfo {
-	var out fonts.PSInfo
+// The other fields of `out` (like FontName, which may be
+// defined before the dictionary) are left untouched.
+func (p *parser) readFontInfo(fontInfo map[string][]tk.Token, out *fonts.PSInfo) {
 	for key, value := range fontInfo {
 		switch key {
 		case "version":
@@ -429,7 +479,6 @@ func (p *parser) readFontInfo(fontInfo map[string][]tk.Token) fonts.PSInfo {
 			out.UnderlineThickness, _ = value[0].Int()
 		}
 	}
-	return out
 }
 
 // Reads a dictionary whose values are simple, i.e., do not contain nested dictionaries.
@@ -474,6 +523,12 @@ func (p *parser) readSimpleDict() (map[string][]tk.Token, error) {
 		if err != nil {
 			return nil, err
 		}
+		if string(keyT.Value) == "Blend" { // Multiple Master fonts
+			if err = p.skipDict(); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		value, err := p.readDictValue()
 		if err != nil {
 			return nil, err
@@ -494,6 +549,37 @@ func (p *parser) readSimpleDict() (map[string][]tk.Token, error) {
 	return dict, nil
 }
 
+// Skips a nested dictionary, of the form "3 dict dup begin ... end def".
+func (p *parser) skipDict() error {
+	if _, err := p.read(tk.Integer); err != nil {
+		return err
+	}
+	if err := p.readWithName(tk.Other, "dict"); err != nil {
+		return err
+	}
+	if _, err := p.readMaybe(tk.Other, "dup"); err != nil {
+		return err
+	}
+	if err := p.readWithName(tk.Other, "begin"); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		token, err := p.lexer.nextToken()
+		if err != nil {
+			return err
+		}
+		switch {
+		case token.Kind == 0 || token.Kind == tk.EOF:
+			return errors.New("unexpected end of nested dictionary")
+		case token.IsOther("begin"):
+			depth++
+		case token.IsOther("end"):
+			depth--
+		}
+	}
+	return p.readDef()
+}
+
 // Reads a simple value from a dictionary.
 func (p *parser) readDictValue() ([]tk.Token, error) {
 	value, err := p.readValue()
@@ -656,7 +742,7 @@ func (p *parser) parseBinary(bytes []byte, font *Font) error {
 
 	// find /Private dict
 	peekToken := p.lexer.peekToken()
-	for string(peekToken.Value) != "Private" {
+	for peekToken.Kind != 0 && peekToken.Kind != tk.EOF && string(peekToken.Value) != "Private" {
 		// for a more thorough validation, the presence of "begin" before Private
 		// determines how code before and following charstrings should look
 		// it is not currently checked anyway
@@ -666,7 +752,7 @@ func (p *parser) parseBinary(bytes []byte, font *Font) error {
 		}
 		peekToken = p.lexer.peekToken()
 	}
-	if peekToken.Kind == 0 {
+	if peekToken.Kind == 0 || peekToken.Kind == tk.EOF {
 		return errors.New("/Private token not found")
 	}
 
@@ -691,8 +777,60 @@ func (p *parser) parseBinary(bytes []byte, font *Font) error {
 		return err
 	}
 
-	lenIV := 4 // number of random bytes at start of charstring
+	font.Private = defaultPrivateDict()
+	lenIV, err := p.readPrivateEntries(length, font)
+	// in permissive mode, we try to find the charstrings anyway
+	if err != nil && !p.permissive {
+		return err
+	}
+
+	// some fonts have "2 index" here, others have "end noaccess put"
+	// sometimes followed by "put". Either way, we just skip until
+	// the /CharStrings dict is found
+	for {
+		n := p.lexer.peekToken()
+		if n.Kind == tk.Name && string(n.Value) == "CharStrings" {
+			break
+		}
+		if n.Kind == 0 || n.Kind == tk.EOF {
+			return errors.New("/CharStrings token not found")
+		}
+		_, err := p.lexer.nextToken()
+		if err != nil {
+			return err
+		}
+	}
 
+	// CharStrings dict
+	start := p.lexer.CurrentPosition()
+	if err = p.readWithName(tk.Name, "CharStrings"); err != nil {
+		return err
+	}
+	font.charstrings, err = p.readCharStrings(lenIV)
+	if err != nil {
+		return err
+	}
+	font.spans.charstrings = [2]int{start, p.lexer.CurrentPosition()}
+
+	if p.permissive { // the charstrings are still encrypted
+		lenIV = guessLenIV(font.charstrings, lenIV)
+		for i, subr := range font.subrs {
+			font.subrs[i] = decrypt(subr, CHARSTRING_KEY, lenIV)
+		}
+		for i, cs := range font.charstrings {
+			font.charstrings[i].data = decrypt(cs.data, CHARSTRING_KEY, lenIV)
+		}
+	}
+
+	font.private = decrypted
+	font.lenIV = lenIV
+	return nil
+}
+
+// readPrivateEntries reads the `length` entries of the /Private dictionary,
+// and returns the lenIV value (number of random bytes at start of charstrings).
+func (p *parser) readPrivateEntries(length int, font *Font) (int, error) {
+	lenIV := 4 // default value
 	for i := 0; i < length; i++ {
 		// premature end
 		if p.lexer.peekToken().Kind != tk.Name {
@@ -700,162 +838,142 @@ func (p *parser) parseBinary(bytes []byte, font *Font) error {
 		}
 
 		// key/value
+		start := p.lexer.CurrentPosition()
 		key, err := p.read(tk.Name)
 		if err != nil {
-			return err
+			return lenIV, err
 		}
 
 		switch string(key.Value) {
 		case "Subrs":
 			font.subrs, err = p.readSubrs(lenIV)
+			font.spans.subrs = [2]int{start, p.lexer.CurrentPosition()}
 		case "OtherSubrs":
 			err = p.readOtherSubrs()
 		case "lenIV":
 			vs, err := p.readDictValue()
 			if err != nil {
-				return err
+				return lenIV, err
 			}
 			lenIV, err = vs[0].Int()
 		case "ND":
 			if _, err = p.read(tk.StartProc); err != nil {
-				return err
+				return lenIV, err
 			}
 			// the access restrictions are not mandatory
 			if _, err = p.readMaybe(tk.Other, "noaccess"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if err = p.readWithName(tk.Other, "def"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.read(tk.EndProc); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.readMaybe(tk.Other, "executeonly"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if err = p.readWithName(tk.Other, "def"); err != nil {
-				return err
+				return lenIV, err
 			}
 		case "NP":
 			if _, err = p.read(tk.StartProc); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.readMaybe(tk.Other, "noaccess"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.read(tk.Other); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.read(tk.EndProc); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.readMaybe(tk.Other, "executeonly"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if err = p.readWithName(tk.Other, "def"); err != nil {
-				return err
+				return lenIV, err
 			}
 		case "RD":
 			// /RD {string currentfile exch readstring pop} bind executeonly def
 			if _, err = p.read(tk.StartProc); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.readProc(); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.readMaybe(tk.Other, "bind"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if _, err = p.readMaybe(tk.Other, "executeonly"); err != nil {
-				return err
+				return lenIV, err
 			}
 			if err = p.readWithName(tk.Other, "def"); err != nil {
-				return err
+				return lenIV, err
 			}
 		default:
 			var vs []tk.Token
 			vs, err = p.readDictValue()
 			if err != nil {
-				return err
+				return lenIV, err
 			}
-			err = p.readPrivate(key.Value, vs)
-		}
-
-		if err != nil {
-			return err
+			err = p.readPrivate(key.Value, vs, &font.Private)
 		}
-	}
 
-	// some fonts have "2 index" here, others have "end noaccess put"
-	// sometimes followed by "put". Either way, we just skip until
-	// the /CharStrings dict is found
-	for {
-		n := p.lexer.peekToken()
-		if n.Kind == tk.Name && string(n.Value) == "CharStrings" {
-			break
-		}
-		_, err := p.lexer.nextToken()
 		if err != nil {
-			return err
+			return lenIV, err
 		}
 	}
 
-	// CharStrings dict
-	if err = p.readWithName(tk.Name, "CharStrings"); err != nil {
-		return err
-	}
-	font.charstrings, err = p.readCharStrings(lenIV)
-	return err
+	return lenIV, nil
 }
 
 // Extracts values from the /Private dictionary.
-func (p *parser) readPrivate(key []byte, value []tk.Token) error {
-	// TODO: complete if needed
-	// 		 switch (key)
-	// 		 {
-	// 			 case "BlueValues":
-	// 				 font.blueValues = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "OtherBlues":
-	// 				 font.otherBlues = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "FamilyBlues":
-	// 				 font.familyBlues = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "FamilyOtherBlues":
-	// 				 font.familyOtherBlues = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "BlueScale":
-	// 				 font.blueScale = value[0].floatValue();
-	// 				 break;
-	// 			 case "BlueShift":
-	// 				 font.blueShift = value[0].intValue();
-	// 				 break;
-	// 			 case "BlueFuzz":
-	// 				 font.blueFuzz = value[0].intValue();
-	// 				 break;
-	// 			 case "StdHW":
-	// 				 font.stdHW = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "StdVW":
-	// 				 font.stdVW = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "StemSnapH":
-	// 				 font.stemSnapH = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "StemSnapV":
-	// 				 font.stemSnapV = arrayToNumbers(value);
-	// 				 break;
-	// 			 case "ForceBold":
-	// 				 font.forceBold = value[0].booleanValue();
-	// 				 break;
-	// 			 case "LanguageGroup":
-	// 				 font.languageGroup = value[0].intValue();
-	// 				 break;
-	// 			 default:
-	// 				 break;
-	// 		 }
-	return nil
+func (p *parser) readPrivate(key []byte, value []tk.Token, private *PrivateDict) error {
+	if len(value) == 0 {
+		return nil
+	}
+	var err error
+	switch string(key) {
+	case "BlueValues":
+		private.BlueValues, err = p.arrayToNumbers(value)
+	case "OtherBlues":
+		private.OtherBlues, err = p.arrayToNumbers(value)
+	case "FamilyBlues":
+		private.FamilyBlues, err = p.arrayToNumbers(value)
+	case "FamilyOtherBlues":
+		private.FamilyOtherBlues, err = p.arrayToNumbers(value)
+	case "BlueScale":
+		var f float64
+		f, err = value[0].Float()
+		private.BlueScale = Fl(f)
+	case "BlueShift":
+		private.BlueShift, err = value[0].Int()
+	case "BlueFuzz":
+		private.BlueFuzz, err = value[0].Int()
+	case "StdHW":
+		var vs []Fl
+		vs, err = p.arrayToNumbers(value)
+		if len(vs) != 0 {
+			private.StdHW = vs[0]
+		}
+	case "StdVW":
+		var vs []Fl
+		vs, err = p.arrayToNumbers(value)
+		if len(vs) != 0 {
+			private.StdVW = vs[0]
+		}
+	case "StemSnapH":
+		private.StemSnapH, err = p.arrayToNumbers(value)
+	case "StemSnapV":
+		private.StemSnapV, err = p.arrayToNumbers(value)
+	case "ForceBold":
+		private.ForceBold = value[0].IsOther("true")
+	case "LanguageGroup":
+		private.LanguageGroup, err = value[0].Int()
+	}
+	return err
 }
 
 // Reads the /Subrs array.
@@ -872,40 +990,48 @@ func (p *parser) readSubrs(lenIV int) ([][]byte, error) {
 		return nil, err
 	}
 
-	for i := 0; i < length; i++ {
+	err = p.readSubrsEntries(subrs, lenIV)
+	// in permissive mode, keep the subroutines read so far
+	if err != nil && !p.permissive {
+		return nil, err
+	}
+	return subrs, nil
+}
+
+func (p *parser) readSubrsEntries(subrs [][]byte, lenIV int) error {
+	for range subrs {
 		// premature end
 		if !p.lexer.peekToken().IsOther("dup") {
 			break
 		}
 
-		if err = p.readWithName(tk.Other, "dup"); err != nil {
-			return nil, err
+		if err := p.readWithName(tk.Other, "dup"); err != nil {
+			return err
 		}
 		indexT, err := p.read(tk.Integer)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		index, _ := indexT.Int()
 		if _, err = p.read(tk.Integer); err != nil {
-			return nil, err
+			return err
 		}
-		if index >= length {
-			return nil, fmt.Errorf("out of range charstring index %d (for %d)", index, length)
+		if index < 0 || index >= len(subrs) {
+			return fmt.Errorf("out of range charstring index %d (for %d)", index, len(subrs))
 		}
 
 		// RD
 		charstring, err := p.read(tk.CharString)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		subrs[index] = decrypt([]byte(charstring.Value), CHARSTRING_KEY, lenIV)
+		subrs[index] = p.decryptCharstring(charstring.Value, lenIV)
 		err = p.readPut()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
-	err = p.readDef()
-	return subrs, err
+	return p.readDef()
 }
 
 // OtherSubrs are embedded PostScript procedures which we can safely ignore
@@ -965,42 +1091,65 @@ func (p *parser) readCharStrings(lenIV int) ([]charstring, error) {
 	}
 
 	charstrings := make([]charstring, length)
+	n, err := p.readCharStringsEntries(charstrings, lenIV)
+	if err != nil {
+		if p.permissive { // keep the glyphs read so far
+			return charstrings[:n], nil
+		}
+		return nil, err
+	}
+	// the dictionary may be larger than needed
+	return charstrings[:n], nil
+}
+
+// readCharStringsEntries fills `charstrings` and returns the number of
+// glyphs read.
+func (p *parser) readCharStringsEntries(charstrings []charstring, lenIV int) (int, error) {
 	for i := range charstrings {
 		// premature end
-		if tok := p.lexer.peekToken(); tok.Kind == 0 || tok.IsOther("end") {
-			break
+		if tok := p.lexer.peekToken(); tok.Kind == 0 || tok.Kind == tk.EOF || tok.IsOther("end") {
+			return i, p.readWithName(tk.Other, "end")
 		}
 		// key/value
 		nameT, err := p.read(tk.Name)
 		if err != nil {
-			return nil, err
+			return i, err
 		}
 
 		// RD
 		_, err = p.read(tk.Integer)
 		if err != nil {
-			return nil, err
+			return i, err
 		}
 		charstring, err := p.read(tk.CharString)
 		if err != nil {
-			return nil, err
+			return i, err
 		}
 
 		charstrings[i].name = string(nameT.Value)
-		charstrings[i].data = decrypt(charstring.Value, CHARSTRING_KEY, lenIV)
+		charstrings[i].data = p.decryptCharstring(charstring.Value, lenIV)
 
 		err = p.readDef()
 		if err != nil {
-			return nil, err
+			return i + 1, err
 		}
 	}
 
 	// some fonts have one "end", others two
-	err = p.readWithName(tk.Other, "end")
+	err := p.readWithName(tk.Other, "end")
 	// since checking ends here, this does not matter ....
 	// more thorough checking would see whether there is "begin" before /Private
 	// and expect a "def" somewhere, otherwise a "put"
-	return charstrings, err
+	return len(charstrings), err
+}
+
+// decryptCharstring decrypts a charstring or a subroutine. In permissive mode,
+// the decryption is delayed until lenIV is validated (see `guessLenIV`).
+func (p *parser) decryptCharstring(data []byte, lenIV int) []byte {
+	if p.permissive {
+		return data
+	}
+	return decrypt(data, CHARSTRING_KEY, lenIV)
 }
 
 // Reads the sequence "noaccess def" or equivalent.
@@ -1126,6 +1275,60 @@ func decrypt(cipherBytes []byte, r uint16, n int) []byte {
 	return cipherBytes[n:]
 }
 
+// guessLenIV returns the lenIV value for which the (still encrypted)
+// charstrings look valid, that is, start with a hsbw or sbw operator.
+// `declared` is kept unless another value gives better results.
+func guessLenIV(charstrings []charstring, declared int) int {
+	best, bestScore := declared, lenIVScore(charstrings, declared)
+	for lenIV := -1; lenIV <= 4; lenIV++ {
+		if score := lenIVScore(charstrings, lenIV); score > bestScore {
+			best, bestScore = lenIV, score
+		}
+	}
+	return best
+}
+
+// lenIVScore returns the number of valid charstrings among the first ones,
+// when decrypted with `lenIV`.
+func lenIVScore(charstrings []charstring, lenIV int) int {
+	const maxChecked = 10
+	score := 0
+	for i, cs := range charstrings {
+		if i == maxChecked {
+			break
+		}
+		plain := decrypt(append([]byte(nil), cs.data...), CHARSTRING_KEY, lenIV)
+		if startsWithSidebearing(plain) {
+			score++
+		}
+	}
+	return score
+}
+
+// startsWithSidebearing returns true if the (decrypted) charstring
+// starts with a well formed hsbw or sbw operator, as required by the
+// Type 1 specification.
+func startsWithSidebearing(cs []byte) bool {
+	nbArgs := 0
+	for i := 0; i < len(cs); nbArgs++ {
+		switch b := cs[i]; {
+		case 32 <= b && b <= 246:
+			i++
+		case 247 <= b && b <= 254:
+			i += 2
+		case b == 255:
+			i += 5
+		case b == 13: // hsbw
+			return nbArgs == 2
+		case b == 12: // sbw
+			return i+1 < len(cs) && cs[i+1] == 7 && nbArgs == 4
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 // Check whether binary or hex encoded. See Adobe Type 1 Font Format specification
 // 7.2 eexec encryption
 func isBinary(bytes []byte) bool {
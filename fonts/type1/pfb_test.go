@@ -3,6 +3,8 @@ package type1
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	tokenizer "github.com/benoitkugler/pstokenizer"
@@ -67,7 +69,7 @@ func TestTokenize(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	s1, s2, err := openPfb(bytes.NewReader(b))
+	s1, s2, err := openPfb(bytes.NewReader(b), false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -157,6 +159,44 @@ func TestCharstrings(t *testing.T) {
 	}
 }
 
+func TestGlyphAdvance(t *testing.T) {
+	for _, filename := range []string{
+		"c0419bt_.pfb",
+		"CalligrapherRegular.pfb",
+		"Z003-MediumItalic.t1",
+	} {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// compute the advances before the outlines are cached
+		advances := make([]int32, len(font.charstrings))
+		for gid := range font.charstrings {
+			advances[gid], err = font.glyphAdvance(fonts.GID(gid))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for gid, advance := range advances {
+			_, _, exp, err := font.loadGlyph(fonts.GID(gid), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if advance != exp {
+				t.Fatalf("%s, glyph %d: expected advance %d, got %d", filename, gid, exp, advance)
+			}
+		}
+		if _, err := font.glyphAdvance(fonts.GID(len(font.charstrings))); err == nil {
+			t.Fatal("expected error for invalid glyph index")
+		}
+	}
+}
+
 func TestScanDescription(t *testing.T) {
 	for _, filename := range []string{
 		"c0419bt_.pfb",
@@ -190,3 +230,287 @@ func TestScanDescription(t *testing.T) {
 		}
 	}
 }
+
+// toPfa converts a .pfb file to the ASCII .pfa format
+func toPfa(pfb []byte) ([]byte, error) {
+	s1, s2, err := openPfb(bytes.NewReader(pfb), false)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.Write(s1)
+	out.WriteString("\r\n")
+	for len(s2) > 0 {
+		n := 32
+		if len(s2) < n {
+			n = len(s2)
+		}
+		fmt.Fprintf(&out, "%x\r\n", s2[:n])
+		s2 = s2[n:]
+	}
+	for i := 0; i < 8; i++ {
+		out.WriteString("0000000000000000000000000000000000000000000000000000000000000000\r\n")
+	}
+	out.WriteString("cleartomark\r\n")
+	return out.Bytes(), nil
+}
+
+func TestOpenPfa(t *testing.T) {
+	for _, filename := range []string{
+		"c0419bt_.pfb",
+		"CalligrapherRegular.pfb",
+	} {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pfa, err := toPfa(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fontPfb, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fontPfa, err := Parse(bytes.NewReader(pfa))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(fontPfa.charstrings) != len(fontPfb.charstrings) {
+			t.Fatalf("expected %d glyphs, got %d", len(fontPfb.charstrings), len(fontPfa.charstrings))
+		}
+		for i, cs := range fontPfb.charstrings {
+			if cs.name != fontPfa.charstrings[i].name || !bytes.Equal(cs.data, fontPfa.charstrings[i].data) {
+				t.Fatalf("invalid charstring for glyph %d", i)
+			}
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	for _, filename := range []string{
+		"c0419bt_.pfb",
+		"CalligrapherRegular.pfb",
+		"Z003-MediumItalic.t1",
+	} {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// modify the font
+		font.FontName = "Renamed"
+		enc := *font.Encoding
+		enc['a'], enc['b'] = enc['b'], enc['a']
+		font.Encoding = &enc
+
+		for _, format := range []Format{PFB, PFA} {
+			var out bytes.Buffer
+			if err = font.Write(&out, format); err != nil {
+				t.Fatal(err)
+			}
+			if format == PFB && out.Bytes()[0] != startMarker {
+				t.Fatal("missing segment marker")
+			} else if format == PFA && out.Bytes()[0] != '%' {
+				t.Fatal("invalid PFA file")
+			}
+
+			font2, err := Parse(bytes.NewReader(out.Bytes()))
+			if err != nil {
+				t.Fatalf("%s (format %d): %s", filename, format, err)
+			}
+			if font2.FontName != "Renamed" {
+				t.Fatalf("%s (format %d): unexpected font name %s", filename, format, font2.FontName)
+			}
+			if *font2.Encoding != enc {
+				t.Fatal("unexpected encoding")
+			}
+			if len(font2.charstrings) != len(font.charstrings) || len(font2.subrs) != len(font.subrs) {
+				t.Fatal("unexpected number of glyphs")
+			}
+			for i, cs := range font.charstrings {
+				if font2.charstrings[i].name != cs.name || !bytes.Equal(font2.charstrings[i].data, cs.data) {
+					t.Fatalf("unexpected charstring for glyph %s", cs.name)
+				}
+			}
+			if font2.Private.StdVW != font.Private.StdVW || font2.FontBBox[2] != font.FontBBox[2] {
+				t.Fatal("unexpected font content")
+			}
+		}
+	}
+}
+
+func TestWriteUnmodified(t *testing.T) {
+	b, err := testdata.Files.ReadFile("CalligrapherRegular.pfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without FontName and Encoding spans, the cleartext is written
+	// as it is, except for its trailing spaces
+	font.spans.fontName, font.spans.encoding = [2]int{}, [2]int{}
+	font.cleartext = append(bytes.TrimRight(font.cleartext, spaces), " \r\n"...)
+	cleartext := append([]byte(nil), font.cleartext...)
+	var out bytes.Buffer
+	if err = font.Write(&out, PFA); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(font.cleartext, cleartext) {
+		t.Fatal("font modified by Write")
+	}
+}
+
+func TestParsePermissive(t *testing.T) {
+	b, err := testdata.Files.ReadFile("CalligrapherRegular.pfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGlyphs := func(font *Font, maxGlyphs int) {
+		t.Helper()
+		if len(font.charstrings) == 0 || len(font.charstrings) > maxGlyphs {
+			t.Fatalf("unexpected number of glyphs %d", len(font.charstrings))
+		}
+		// the last glyph may be truncated
+		for i, cs := range font.charstrings[:len(font.charstrings)-1] {
+			if exp := ref.charstrings[i]; cs.name != exp.name || !bytes.Equal(cs.data, exp.data) {
+				t.Fatalf("unexpected charstring for glyph %s", exp.name)
+			}
+		}
+	}
+
+	var pfb, pfa bytes.Buffer
+	if err = ref.Write(&pfb, PFB); err != nil {
+		t.Fatal(err)
+	}
+	if err = ref.Write(&pfa, PFA); err != nil {
+		t.Fatal(err)
+	}
+
+	// truncated eexec section
+	truncated := pfb.Bytes()[:len(ref.cleartext)+6+len(ref.private)/2]
+	if _, err = Parse(bytes.NewReader(truncated)); err == nil {
+		t.Fatal("expected error for truncated font")
+	}
+	font, err := ParseWithOptions(bytes.NewReader(truncated), ParseOptions{Permissive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGlyphs(font, len(ref.charstrings)-1)
+
+	// missing trailing zeros
+	noTrailer := pfa.Bytes()[:bytes.LastIndex(pfa.Bytes(), []byte(strings.Repeat("0", 64)+"\n"))]
+	noTrailer = bytes.TrimRight(noTrailer, "0\n")
+	for _, opts := range []ParseOptions{{}, {Permissive: true}} {
+		font, err = ParseWithOptions(bytes.NewReader(noTrailer), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGlyphs(font, len(ref.charstrings))
+	}
+
+	// as in strict mode, errors in the binary section are ignored
+	damaged := *ref
+	damaged.private = bytes.Replace(ref.private, []byte("/CharStrings"), []byte("/CharStringz"), 1)
+	var noCharstrings bytes.Buffer
+	if err = damaged.Write(&noCharstrings, PFB); err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(noCharstrings.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.FontName != ref.FontName {
+		t.Fatalf("unexpected font name %s", font.FontName)
+	}
+
+	// wrong lenIV: the charstrings are still encrypted with 4 bytes
+	wrong := *ref
+	wrong.private = bytes.Replace(ref.private, []byte("/password 5839 def"), []byte("/lenIV 1 def      "), 1)
+	var wrongLenIV bytes.Buffer
+	if err = wrong.Write(&wrongLenIV, PFB); err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(wrongLenIV.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.lenIV != 1 {
+		t.Fatalf("unexpected lenIV %d", font.lenIV)
+	}
+	font, err = ParseWithOptions(bytes.NewReader(wrongLenIV.Bytes()), ParseOptions{Permissive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.lenIV != 4 {
+		t.Fatalf("unexpected lenIV %d", font.lenIV)
+	}
+	checkGlyphs(font, len(ref.charstrings))
+	if _, err = font.LoadGlyph(10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGlyphIndexByName(t *testing.T) {
+	b, err := testdata.Files.ReadFile("CalligrapherRegular.pfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gid, cs := range font.charstrings {
+		if got, ok := font.GlyphIndexByName(cs.name); !ok || got != fonts.GID(gid) {
+			t.Fatalf("unexpected glyph %d for %s", got, cs.name)
+		}
+	}
+	if _, ok := font.GlyphIndexByName("not-a-glyph"); ok {
+		t.Fatal("unexpected glyph")
+	}
+
+	// the index is updated after subsetting
+	gidB, _ := font.GlyphIndexByName("B")
+	if err = font.Subset([]fonts.GID{gidB}); err != nil {
+		t.Fatal(err)
+	}
+	if gid, ok := font.GlyphIndexByName("B"); !ok || gid != 1 {
+		t.Fatalf("unexpected glyph %d for B", gid)
+	}
+	if _, ok := font.GlyphIndexByName("A"); ok {
+		t.Fatal("unexpected glyph A in subset")
+	}
+}
+
+func TestSynthesizeCmap(t *testing.T) {
+	var font Font
+	for _, name := range []string{
+		Notdef, "a.sc", "a", "a.alt", "uni0042", "u1F600", "f_f_i", "unknown", "Alpha", "uni0391", "c.sc", "uni00410042",
+	} {
+		font.charstrings = append(font.charstrings, charstring{name: name})
+	}
+	font.synthesizeCmap()
+
+	expected := fonts.CmapSimple{
+		'a':     2, // regular glyph preferred
+		'B':     4,
+		0x1F600: 5,
+		0x0391:  8, // first glyph
+		'c':     10,
+	}
+	if !reflect.DeepEqual(font.cmap, expected) {
+		t.Fatalf("unexpected cmap %v", font.cmap)
+	}
+}
@@ -0,0 +1,151 @@
+package type1
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+	"github.com/boxesandglue/textlayout/fonts/simpleencodings"
+)
+
+// Subset removes all data from the font except the one needed for the given
+// glyphs. The .notdef glyph and the components of accented (seac) glyphs
+// are always kept, and the glyph indices are renumbered accordingly.
+// The unused subroutines are replaced by empty ones, so that the
+// indices of the remaining ones are preserved.
+// The glyphs not present in the builtin Encoding are assigned free codes,
+// and the codes of the removed glyphs are set to .notdef.
+// Subset must only be called once.
+func (f *Font) Subset(codepoints []fonts.GID) error {
+	keep := make([]bool, len(f.charstrings))
+	if len(keep) != 0 {
+		keep[0] = true // .notdef
+	}
+	for _, gid := range codepoints {
+		if int(gid) >= len(f.charstrings) {
+			return fmt.Errorf("invalid glyph index %d", gid)
+		}
+		keep[gid] = true
+	}
+
+	// add the seac components and collect the used subroutines;
+	// by convention, the subroutines 0 to 3 are reserved for flex and hint replacement
+	usedSubrs := map[int32]bool{0: true, 1: true, 2: true, 3: true}
+	for gid := range f.charstrings {
+		if !keep[gid] {
+			continue
+		}
+		var (
+			psi    ps.Machine
+			parser = type1CharstringParser{usedSubrs: usedSubrs}
+		)
+		if err := psi.Run(f.charstrings[gid].data, f.subrs, nil, &parser); err != nil {
+			return fmt.Errorf("invalid charstring for glyph %d: %s", gid, err)
+		}
+		if parser.seac == nil {
+			continue
+		}
+		for _, code := range [2]int32{parser.seac.aCode, parser.seac.bCode} {
+			component, err := f.seacComponent(code)
+			if err != nil {
+				return err
+			}
+			if !keep[component] {
+				keep[component] = true
+				if component < fonts.GID(gid) { // not visited yet
+					parser := type1CharstringParser{usedSubrs: usedSubrs}
+					if err := psi.Run(f.charstrings[component].data, f.subrs, nil, &parser); err != nil {
+						return fmt.Errorf("invalid charstring for glyph %d: %s", component, err)
+					}
+				}
+			}
+		}
+	}
+
+	for i := range f.subrs {
+		if !usedSubrs[int32(i)] {
+			f.subrs[i] = []byte{11} // return
+		}
+	}
+
+	charstrings := f.charstrings[:0]
+	kept := make(map[string]bool)
+	for gid, cs := range f.charstrings {
+		if keep[gid] {
+			charstrings = append(charstrings, cs)
+			kept[cs.name] = true
+		}
+	}
+	f.charstrings = charstrings
+
+	f.Encoding = subsetEncoding(f.Encoding, kept)
+	f.synthesizeCmap()
+	f.cache.reset(nil)
+	f.names.reset()
+	return nil
+}
+
+// subsetEncoding returns a new encoding, restricted to the glyphs in `kept`
+// and where the glyphs not already encoded are given free codes.
+func subsetEncoding(enc *simpleencodings.Encoding, kept map[string]bool) *simpleencodings.Encoding {
+	if enc == nil {
+		enc = &simpleencodings.AdobeStandard
+	}
+	var out simpleencodings.Encoding
+	encoded := make(map[string]bool)
+	for code, name := range enc {
+		if kept[name] && name != Notdef {
+			out[code] = name
+			encoded[name] = true
+		}
+	}
+	// sort for determinism
+	var toEncode []string
+	for name := range kept {
+		if !encoded[name] && name != Notdef {
+			toEncode = append(toEncode, name)
+		}
+	}
+	sort.Strings(toEncode)
+	code := 1
+	for _, name := range toEncode {
+		for code < len(out) && out[code] != "" {
+			code++
+		}
+		if code == len(out) { // no more room
+			break
+		}
+		out[code] = name
+	}
+	return &out
+}
+
+// WriteSubset writes the font to w, in the format expected by PDF
+// for embedded Type 1 fonts (FontFile streams): the clear text portion,
+// the binary eexec encrypted portion and the trailer are simply concatenated.
+// See `PDFLengths` for the length of each portion.
+func (f *Font) WriteSubset(w io.Writer) error {
+	cleartext, binary, trailer, err := f.segments()
+	if err != nil {
+		return err
+	}
+	for _, segment := range [3][]byte{cleartext, binary, trailer} {
+		if _, err = w.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PDFLengths returns the lengths of the three portions written
+// by `WriteSubset`, as required by the /Length1, /Length2 and /Length3
+// entries of PDF FontFile streams.
+func (f *Font) PDFLengths() (length1, length2, length3 int, err error) {
+	cleartext, binary, trailer, err := f.segments()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return len(cleartext), len(binary), len(trailer), nil
+}
@@ -0,0 +1,85 @@
+package type1
+
+import (
+	"bytes"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/type1"
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+func TestSubset(t *testing.T) {
+	for _, filename := range []string{
+		"c0419bt_.pfb",
+		"CalligrapherRegular.pfb",
+		"Z003-MediumItalic.t1",
+	} {
+		b, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			gids  []fonts.GID
+			names []string
+		)
+		for _, r := range "Hello world" {
+			gid, ok := font.NominalGlyph(r)
+			if !ok {
+				t.Fatalf("missing glyph for %c in %s", r, filename)
+			}
+			gids = append(gids, gid)
+		}
+		gids = fonts.RemoveDuplicates(gids)
+		for _, gid := range gids {
+			names = append(names, font.GlyphName(gid))
+		}
+		expectedAdvances := map[string]float32{}
+		for _, gid := range gids {
+			expectedAdvances[font.GlyphName(gid)] = font.HorizontalAdvance(gid)
+		}
+
+		if err = font.Subset(gids); err != nil {
+			t.Fatal(err)
+		}
+		if len(font.charstrings) != len(gids)+1 {
+			t.Fatalf("unexpected number of glyphs %d in subset", len(font.charstrings))
+		}
+
+		var out bytes.Buffer
+		if err = font.WriteSubset(&out); err != nil {
+			t.Fatal(err)
+		}
+		l1, l2, l3, err := font.PDFLengths()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l1+l2+l3 != out.Len() {
+			t.Fatalf("inconsistent lengths %d %d %d for %d", l1, l2, l3, out.Len())
+		}
+
+		subset, err := Parse(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(subset.charstrings) != len(font.charstrings) {
+			t.Fatalf("unexpected number of glyphs %d in written subset", len(subset.charstrings))
+		}
+		for _, name := range names {
+			gid, ok := subset.GlyphIndexByName(name)
+			if !ok {
+				t.Fatalf("missing glyph %s in subset", name)
+			}
+			if adv := subset.HorizontalAdvance(gid); adv != expectedAdvances[name] {
+				t.Fatalf("invalid advance for %s: expected %f, got %f", name, expectedAdvances[name], adv)
+			}
+			if subset.GlyphData(gid, 0, 0) == nil {
+				t.Fatalf("invalid outline for %s", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package type1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/boxesandglue/textlayout/fonts/simpleencodings"
+)
+
+// spans stores the positions of the parts of the font
+// which are regenerated when writing it back.
+// Each span is a [start, end) pair.
+type spans struct {
+	fontName    [2]int // in the cleartext segment
+	encoding    [2]int // in the cleartext segment
+	subrs       [2]int // in the decrypted binary segment
+	charstrings [2]int // in the decrypted binary segment
+}
+
+// Type 1 Encryption (eexec, charstring), the inverse of `decrypt`.
+// `r` is the key and `n` the number of random bytes (lenIV)
+// prepended to the plain text.
+func encrypt(plainBytes []byte, r uint16, n int) []byte {
+	// lenIV of -1 means no encryption (not documented)
+	if n == -1 {
+		return append([]byte(nil), plainBytes...)
+	}
+	const (
+		c1 uint16 = 52845
+		c2 uint16 = 22719
+	)
+	// the "random" bytes are zeros, which guarantees that
+	// the first ciphertext byte is not an hexadecimal digit
+	out := make([]byte, n+len(plainBytes))
+	copy(out[n:], plainBytes)
+	for i, p := range out {
+		c := p ^ byte(r>>8)
+		out[i] = c
+		r = (uint16(c)+r)*c1 + c2
+	}
+	return out
+}
+
+// psNames stores the names of the procedures used
+// to read charstrings, which varies between fonts.
+type psNames struct {
+	rd, nd, np string
+}
+
+func newPsNames(private []byte) psNames {
+	if bytes.Contains(private, []byte("/RD")) {
+		return psNames{rd: "RD", nd: "ND", np: "NP"}
+	}
+	return psNames{rd: "-|", nd: "|-", np: "|"}
+}
+
+// writeEncoding returns the PostScript code defining `enc`.
+func writeEncoding(enc *simpleencodings.Encoding) []byte {
+	if enc == &simpleencodings.AdobeStandard {
+		return []byte("/Encoding StandardEncoding def")
+	}
+	var b bytes.Buffer
+	b.WriteString("/Encoding 256 array\n0 1 255 {1 index exch /.notdef put} for\n")
+	for code, name := range enc {
+		if name == "" || name == Notdef {
+			continue
+		}
+		fmt.Fprintf(&b, "dup %d /%s put\n", code, name)
+	}
+	b.WriteString("readonly def")
+	return b.Bytes()
+}
+
+// writeSubrs returns the PostScript code defining the /Subrs array.
+// Missing subroutines are replaced by a simple return.
+func (f *Font) writeSubrs(names psNames) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "/Subrs %d array\n", len(f.subrs))
+	for i, subr := range f.subrs {
+		if subr == nil {
+			subr = []byte{11} // return
+		}
+		data := encrypt(subr, CHARSTRING_KEY, f.lenIV)
+		fmt.Fprintf(&b, "dup %d %d %s ", i, len(data), names.rd)
+		b.Write(data)
+		fmt.Fprintf(&b, " %s\n", names.np)
+	}
+	b.WriteString(names.nd)
+	return b.Bytes()
+}
+
+// writeCharStrings returns the PostScript code defining the /CharStrings dictionary.
+func (f *Font) writeCharStrings(names psNames) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "/CharStrings %d dict dup begin\n", len(f.charstrings))
+	for _, cs := range f.charstrings {
+		data := encrypt(cs.data, CHARSTRING_KEY, f.lenIV)
+		fmt.Fprintf(&b, "/%s %d %s ", cs.name, len(data), names.rd)
+		b.Write(data)
+		fmt.Fprintf(&b, " %s\n", names.nd)
+	}
+	b.WriteString("end")
+	return b.Bytes()
+}
+
+// segments returns the 3 segments of the font, regenerating
+// the Encoding, the Subrs and the CharStrings, and keeping the other
+// parts untouched.
+// The binary segment is eexec encrypted.
+func (f *Font) segments() (cleartext, binary, trailer []byte, err error) {
+	if f.private == nil {
+		return nil, nil, nil, errors.New("missing binary segment: font can't be written")
+	}
+
+	cleartext = f.cleartext
+	// start by the last span so that the positions of the first one are still valid
+	var encoding, fontName []byte
+	if f.spans.encoding != [2]int{} && f.Encoding != nil {
+		encoding = writeEncoding(f.Encoding)
+	}
+	if f.spans.fontName != [2]int{} && f.FontName != "" {
+		fontName = []byte("/FontName /" + f.FontName + " def")
+	}
+	if f.spans.fontName[0] > f.spans.encoding[0] {
+		cleartext = splice(cleartext, f.spans.fontName, fontName)
+		cleartext = splice(cleartext, f.spans.encoding, encoding)
+	} else {
+		cleartext = splice(cleartext, f.spans.encoding, encoding)
+		cleartext = splice(cleartext, f.spans.fontName, fontName)
+	}
+	// make sure the eexec is followed by a line break
+	// (copying the cleartext, which may still be f.cleartext)
+	trimmed := bytes.TrimRight(cleartext, spaces)
+	cleartext = append(trimmed[:len(trimmed):len(trimmed)], '\n')
+
+	private := f.private
+	// remove the potential trailer found in fonts without segment markers
+	if end := bytes.Index(private[f.spans.charstrings[1]:], []byte("closefile")); end != -1 {
+		private = private[:f.spans.charstrings[1]+end+len("closefile")]
+	}
+	names := newPsNames(private)
+	// start by the last span so that the positions of the first one are still valid
+	private = splice(private, f.spans.charstrings, f.writeCharStrings(names))
+	if sp := f.spans.subrs; sp != [2]int{} {
+		private = splice(private, sp, f.writeSubrs(names))
+	}
+	private = append(private, '\n')
+	binary = encrypt(private, eexecKey, 4)
+
+	trailer = []byte(strings.Repeat(strings.Repeat("0", 64)+"\n", 8) + "cleartomark\n")
+	return cleartext, binary, trailer, nil
+}
+
+// splice returns a copy of `data`, where the bytes in `span`
+// are replaced by a new line and `content`.
+// If `content` is nil, `data` is returned unchanged.
+func splice(data []byte, span [2]int, content []byte) []byte {
+	if content == nil {
+		return data
+	}
+	out := make([]byte, 0, len(data)-(span[1]-span[0])+len(content)+1)
+	out = append(out, data[:span[0]]...)
+	out = append(out, '\n')
+	out = append(out, content...)
+	out = append(out, data[span[1]:]...)
+	return out
+}
+
+// Format is the file format used when writing a font.
+type Format uint8
+
+const (
+	// PFB is the binary format, where the segments are
+	// prefixed by headers.
+	PFB Format = iota
+	// PFA is the ASCII format, where the eexec encrypted
+	// portion is hex encoded.
+	PFA
+)
+
+// Write serializes the font in the given format, re-encrypting
+// the charstrings and the private dictionary.
+// The Encoding, the FontName, the subroutines and the charstrings
+// are regenerated from the content of the font, meaning that
+// they may be modified before writing; the other parts of the font
+// are written back unchanged.
+func (f *Font) Write(w io.Writer, format Format) error {
+	cleartext, encrypted, trailer, err := f.segments()
+	if err != nil {
+		return err
+	}
+	switch format {
+	case PFB:
+		for _, segment := range [...]struct {
+			marker byte
+			data   []byte
+		}{
+			{asciiMarker, cleartext},
+			{binaryMarker, encrypted},
+			{asciiMarker, trailer},
+		} {
+			var header [6]byte
+			header[0], header[1] = startMarker, segment.marker
+			binary.LittleEndian.PutUint32(header[2:], uint32(len(segment.data)))
+			if _, err = w.Write(header[:]); err != nil {
+				return err
+			}
+			if _, err = w.Write(segment.data); err != nil {
+				return err
+			}
+		}
+		_, err = w.Write([]byte{startMarker, eofMarker})
+		return err
+	case PFA:
+		if _, err = w.Write(cleartext); err != nil {
+			return err
+		}
+		if _, err = w.Write(binaryToHex(encrypted)); err != nil {
+			return err
+		}
+		_, err = w.Write(trailer)
+		return err
+	default:
+		return fmt.Errorf("unsupported format %d", format)
+	}
+}
+
+// binaryToHex returns the hex encoding of `data`,
+// using lines of 64 characters.
+func binaryToHex(data []byte) []byte {
+	const lineLength = 32 // in bytes
+	encoded := hex.EncodeToString(data)
+	var out bytes.Buffer
+	for len(encoded) > 2*lineLength {
+		out.WriteString(encoded[:2*lineLength])
+		out.WriteByte('\n')
+		encoded = encoded[2*lineLength:]
+	}
+	out.WriteString(encoded)
+	out.WriteByte('\n')
+	return out.Bytes()
+}
@@ -0,0 +1,122 @@
+package type1c
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FontData stores the content needed to build a new CFF font
+// from scratch, see `NewCFF`.
+type FontData struct {
+	FontName string
+
+	// Optional font informations
+	Version, Notice, Copyright, FullName, FamilyName, Weight string
+
+	UniqueID           int
+	FontBBox           [4]int
+	FontMatrix         []float64 // optional, default to [0.001 0 0 0.001 0 0]
+	UnderlinePosition  float64
+	UnderlineThickness float64
+
+	// Private dictionary values
+	BlueValues, OtherBlues, FamilyBlues, FamilyOtherBlues []int
+	BlueScale                                             float64
+	BlueShift, BlueFuzz                                   int
+	StdHW, StdVW                                          int
+	StemSnapH, StemSnapV                                  []int
+	DefaultWidthX, NominalWidthX                          int
+
+	// GlyphNames is the charset of the font. The first glyph must be .notdef.
+	GlyphNames []string
+	// CharStrings are the Type 2 charstrings of the glyphs,
+	// in the same order as GlyphNames.
+	CharStrings [][]byte
+	// Subrs are the optional local subroutines.
+	Subrs [][]byte
+}
+
+// NewCFF builds a CFF font file containing one font, described by `data`.
+// The font is not CID-keyed and uses the standard encoding. It may be written
+// with `WriteCFFData`.
+func NewCFF(data FontData) (*CFF, error) {
+	if len(data.CharStrings) == 0 {
+		return nil, errors.New("missing charstrings")
+	}
+	if len(data.GlyphNames) != len(data.CharStrings) {
+		return nil, fmt.Errorf("invalid charset length %d (for %d glyphs)", len(data.GlyphNames), len(data.CharStrings))
+	}
+	if data.GlyphNames[0] != ".notdef" {
+		return nil, errors.New("the first glyph must be .notdef")
+	}
+
+	cff := &CFF{
+		Major:      1,
+		Minor:      0,
+		HdrSize:    4,
+		offsetSize: 4,
+		fontnames:  []string{data.FontName},
+	}
+	cff.initStrings()
+
+	fnt := &Font{
+		global:             cff,
+		version:            cff.addString(data.Version),
+		notice:             cff.addString(data.Notice),
+		copyright:          cff.addString(data.Copyright),
+		fullname:           cff.addString(data.FullName),
+		familyname:         cff.addString(data.FamilyName),
+		weight:             cff.addString(data.Weight),
+		uniqueid:           data.UniqueID,
+		bbox:               data.FontBBox[:],
+		fontMatrix:         data.FontMatrix,
+		underlinePosition:  data.UnderlinePosition,
+		underlineThickness: data.UnderlineThickness,
+
+		bluevalues:       data.BlueValues,
+		otherblues:       data.OtherBlues,
+		familyblues:      data.FamilyBlues,
+		familyotherblues: data.FamilyOtherBlues,
+		bluescale:        data.BlueScale,
+		blueshift:        data.BlueShift,
+		bluefuzz:         data.BlueFuzz,
+		stdhw:            data.StdHW,
+		stdvw:            data.StdVW,
+		stemsnaph:        data.StemSnapH,
+		stemsnapv:        data.StemSnapV,
+		defaultWidthX:    data.DefaultWidthX,
+		nominalWidthX:    data.NominalWidthX,
+
+		CharStrings: data.CharStrings,
+		subrsIndex:  data.Subrs,
+	}
+	fnt.charset = make([]SID, len(data.GlyphNames))
+	for i, name := range data.GlyphNames[1:] {
+		fnt.charset[i+1] = cff.addString(name)
+	}
+
+	// the actual offsets are resolved in WriteCFFData, but must be
+	// non zero so that the corresponding entries are written in the top dict
+	fnt.charsetOffset = 1
+	fnt.charstringsOffset = 1
+	fnt.privatedictoffset = 1
+	fnt.privatedictsize = len(fnt.cffEncodePrivateDict())
+
+	cff.Font = []*Font{fnt}
+	fnt.synthesizeCmap()
+	return cff, nil
+}
+
+// addString returns the SID of `s`, adding it to the string index
+// if needed. The empty string is mapped to 0, meaning "not present".
+func (c *CFF) addString(s string) SID {
+	if s == "" {
+		return 0
+	}
+	if sid, ok := c.stringToInt[s]; ok {
+		return SID(sid)
+	}
+	c.strings = append(c.strings, s)
+	c.stringToInt[s] = len(c.strings) - 1
+	return SID(len(c.strings) - 1)
+}
@@ -0,0 +1,590 @@
+package type1c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+)
+
+var errInvalidCFF2 = errors.New("invalid CFF2 table")
+
+// CFF2 is the content of a 'CFF2' table, which stores the Type 2
+// charstrings of OpenType fonts, with support for font variations.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/cff2
+type CFF2 struct {
+	globalSubrs [][]byte
+	charstrings [][]byte
+
+	// fdSelect maps each glyph to its font dict, and
+	// is nil when the font has only one font dict
+	fdSelect []uint16
+	privates []cff2Private // one for each font dict
+
+	vstore cff2VariationStore
+}
+
+// cff2Private stores the content of the Private DICT
+// needed to interpret the charstrings
+type cff2Private struct {
+	subrs   [][]byte
+	vsindex int32 // default item variation data
+}
+
+// cff2VariationStore stores the regions used by the blend operator.
+// After successful parsing, every region indexes are valid.
+type cff2VariationStore struct {
+	regions       [][]cff2RegionAxis // for each region, for each axis
+	regionIndexes [][]uint16         // for each item variation data
+}
+
+// cff2RegionAxis stores the start, peak and end coordinates.
+type cff2RegionAxis [3]float32
+
+// evaluate returns the factor of the axis for the normalized coordinate `coord`
+func (reg cff2RegionAxis) evaluate(coord float32) float32 {
+	start, peak, end := reg[0], reg[1], reg[2]
+	if peak == 0 || coord == peak {
+		return 1.
+	}
+
+	if coord <= start || end <= coord {
+		return 0.
+	}
+
+	if coord < peak {
+		return (coord - start) / (peak - start)
+	}
+	return (end - coord) / (end - peak)
+}
+
+// scalars returns the scalar of each region used by
+// the item variation data `vsindex`.
+func (vs cff2VariationStore) scalars(vsindex int32, coords []float32) ([]float32, error) {
+	if vsindex < 0 || int(vsindex) >= len(vs.regionIndexes) {
+		return nil, fmt.Errorf("invalid variation store index %d", vsindex)
+	}
+	indexes := vs.regionIndexes[vsindex]
+	out := make([]float32, len(indexes))
+	for i, regionIndex := range indexes {
+		region := vs.regions[regionIndex]
+		if len(coords) == 0 { // default instance
+			continue
+		}
+		v := float32(1)
+		for axis, coord := range coords {
+			if axis < len(region) {
+				v *= region[axis].evaluate(coord)
+			}
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// ParseCFF2 parses the content of a 'CFF2' table.
+func ParseCFF2(data []byte) (*CFF2, error) {
+	if len(data) < 5 {
+		return nil, errInvalidCFF2
+	}
+	if major := data[0]; major != 2 {
+		return nil, fmt.Errorf("unsupported CFF2 version %d", major)
+	}
+	headerSize, topDictLength := int(data[2]), int(binary.BigEndian.Uint16(data[3:]))
+	if len(data) < headerSize+topDictLength {
+		return nil, errInvalidCFF2
+	}
+
+	var (
+		psi ps.Machine
+		top cff2DictHandler
+		out CFF2
+		err error
+	)
+	if err = psi.Run(data[headerSize:headerSize+topDictLength], nil, nil, &top); err != nil {
+		return nil, fmt.Errorf("invalid CFF2 Top DICT: %s", err)
+	}
+
+	out.globalSubrs, err = parseCFF2Index(data, headerSize+topDictLength)
+	if err != nil {
+		return nil, err
+	}
+	out.charstrings, err = parseCFF2Index(data, int(top.charstrings))
+	if err != nil {
+		return nil, err
+	}
+	if len(out.charstrings) == 0 {
+		return nil, errors.New("invalid CFF2 table: missing charstrings")
+	}
+
+	if top.vstoreOffset != 0 {
+		out.vstore, err = parseCFF2VariationStore(data, int(top.vstoreOffset))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fontDicts, err := parseCFF2Index(data, int(top.fdArray))
+	if err != nil {
+		return nil, err
+	}
+	if len(fontDicts) == 0 {
+		return nil, errors.New("invalid CFF2 table: missing font dicts")
+	}
+	out.privates = make([]cff2Private, len(fontDicts))
+	for i, fontDict := range fontDicts {
+		var handler cff2DictHandler
+		if err = psi.Run(fontDict, nil, nil, &handler); err != nil {
+			return nil, fmt.Errorf("invalid CFF2 Font DICT: %s", err)
+		}
+		out.privates[i], err = parseCFF2Private(data, handler.privateOffset, handler.privateSize, out.vstore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if top.fdSelect != 0 {
+		out.fdSelect, err = parseFDSelect(data, int(top.fdSelect), len(out.charstrings), len(out.privates))
+		if err != nil {
+			return nil, err
+		}
+	} else if len(out.privates) != 1 {
+		return nil, errors.New("invalid CFF2 table: missing FDSelect")
+	}
+
+	return &out, nil
+}
+
+func parseCFF2Private(data []byte, offset, size int32, vstore cff2VariationStore) (out cff2Private, err error) {
+	if offset < 0 || size < 0 || len(data) < int(offset)+int(size) {
+		return out, errors.New("invalid CFF2 Private DICT (EOF)")
+	}
+	var (
+		psi     ps.Machine
+		handler = cff2DictHandler{vstore: vstore}
+	)
+	if err = psi.Run(data[offset:offset+size], nil, nil, &handler); err != nil {
+		return out, fmt.Errorf("invalid CFF2 Private DICT: %s", err)
+	}
+	out.vsindex = handler.vsindex
+	if handler.subrs != 0 { // relative to the Private DICT
+		out.subrs, err = parseCFF2Index(data, int(offset)+int(handler.subrs))
+	}
+	return out, err
+}
+
+// parseCFF2Index parses the INDEX starting at `offset`,
+// whose count is stored on 4 bytes (instead of 2 in CFF).
+func parseCFF2Index(data []byte, offset int) ([][]byte, error) {
+	if offset < 0 || len(data) < offset+4 {
+		return nil, errors.New("invalid CFF2 INDEX (EOF)")
+	}
+	count := int(binary.BigEndian.Uint32(data[offset:]))
+	if count == 0 {
+		return nil, nil
+	}
+	if len(data) < offset+5 {
+		return nil, errors.New("invalid CFF2 INDEX (EOF)")
+	}
+	offSize := int(data[offset+4])
+	if offSize < 1 || offSize > 4 {
+		return nil, fmt.Errorf("invalid CFF2 INDEX offset size %d", offSize)
+	}
+	offsetsStart := offset + 5
+	if (len(data)-offsetsStart)/offSize < count+1 {
+		return nil, errors.New("invalid CFF2 INDEX (EOF)")
+	}
+	readOffset := func(i int) int {
+		var v int
+		for _, b := range data[offsetsStart+i*offSize : offsetsStart+(i+1)*offSize] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+	// the offsets are relative to the byte preceding the data
+	dataStart := offsetsStart + (count+1)*offSize - 1
+	out := make([][]byte, count)
+	start := readOffset(0)
+	for i := range out {
+		end := readOffset(i + 1)
+		if start < 1 || end < start || len(data) < dataStart+end {
+			return nil, errors.New("invalid CFF2 INDEX offsets")
+		}
+		out[i] = data[dataStart+start : dataStart+end]
+		start = end
+	}
+	return out, nil
+}
+
+// parseFDSelect returns the font dict index of each glyph
+func parseFDSelect(data []byte, offset, numGlyphs, numFontDicts int) ([]uint16, error) {
+	if offset < 0 || len(data) < offset+1 {
+		return nil, errors.New("invalid FDSelect (EOF)")
+	}
+	data = data[offset:]
+	out := make([]uint16, numGlyphs)
+	switch format := data[0]; format {
+	case 0:
+		if len(data) < 1+numGlyphs {
+			return nil, errors.New("invalid FDSelect (EOF)")
+		}
+		for i := range out {
+			out[i] = uint16(data[1+i])
+		}
+	case 3, 4:
+		// ranges of (first glyph, font dict), followed by a sentinel glyph
+		gidSize, fdSize := 2, 1
+		if format == 4 {
+			gidSize, fdSize = 4, 2
+		}
+		readUint := func(b []byte, size int) int {
+			var v int
+			for _, c := range b[:size] {
+				v = v<<8 | int(c)
+			}
+			return v
+		}
+		if len(data) < 1+gidSize {
+			return nil, errors.New("invalid FDSelect (EOF)")
+		}
+		nRanges := readUint(data[1:], gidSize)
+		rangeSize := gidSize + fdSize
+		if (len(data)-1-2*gidSize)/rangeSize < nRanges {
+			return nil, errors.New("invalid FDSelect (EOF)")
+		}
+		ranges := data[1+gidSize:]
+		for i := 0; i < nRanges; i++ {
+			first := readUint(ranges[i*rangeSize:], gidSize)
+			fd := readUint(ranges[i*rangeSize+gidSize:], fdSize)
+			next := readUint(ranges[(i+1)*rangeSize:], gidSize) // the sentinel for the last range
+			if first > next || next > numGlyphs {
+				return nil, errors.New("invalid FDSelect range")
+			}
+			for gid := first; gid < next; gid++ {
+				out[gid] = uint16(fd)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported FDSelect format %d", format)
+	}
+	for _, fd := range out {
+		if int(fd) >= numFontDicts {
+			return nil, fmt.Errorf("invalid font dict index %d", fd)
+		}
+	}
+	return out, nil
+}
+
+func fixed214ToFloat(v uint16) float32 { return float32(int16(v)) / (1 << 14) }
+
+func parseCFF2VariationStore(data []byte, offset int) (out cff2VariationStore, err error) {
+	// the store is preceded by its length
+	if len(data) < offset+2+8 {
+		return out, errors.New("invalid CFF2 variation store (EOF)")
+	}
+	data = data[offset+2:]
+	// format is ignored
+	regionsOffset := int(binary.BigEndian.Uint32(data[2:]))
+	count := int(binary.BigEndian.Uint16(data[6:]))
+
+	if len(data) < regionsOffset+4 {
+		return out, errors.New("invalid CFF2 variation regions list (EOF)")
+	}
+	regionsData := data[regionsOffset:]
+	axisCount := int(binary.BigEndian.Uint16(regionsData))
+	regionCount := int(binary.BigEndian.Uint16(regionsData[2:]))
+	if len(regionsData) < 4+6*axisCount*regionCount {
+		return out, errors.New("invalid CFF2 variation regions list (EOF)")
+	}
+	out.regions = make([][]cff2RegionAxis, regionCount)
+	for i := range out.regions {
+		region := make([]cff2RegionAxis, axisCount)
+		for j := range region {
+			axisData := regionsData[4+(i*axisCount+j)*6:]
+			start := fixed214ToFloat(binary.BigEndian.Uint16(axisData))
+			peak := fixed214ToFloat(binary.BigEndian.Uint16(axisData[2:]))
+			end := fixed214ToFloat(binary.BigEndian.Uint16(axisData[4:]))
+			if start > peak || peak > end || (start < 0 && end > 0 && peak != 0) {
+				return out, errors.New("invalid CFF2 variation regions list")
+			}
+			region[j] = cff2RegionAxis{start, peak, end}
+		}
+		out.regions[i] = region
+	}
+
+	if len(data) < 8+4*count {
+		return out, errors.New("invalid CFF2 variation store (EOF)")
+	}
+	out.regionIndexes = make([][]uint16, count)
+	for i := range out.regionIndexes {
+		// the deltas are stored in the charstrings: only the regions are needed
+		subtableOffset := int(binary.BigEndian.Uint32(data[8+4*i:]))
+		if len(data) < subtableOffset+6 {
+			return out, errors.New("invalid CFF2 item variation data (EOF)")
+		}
+		subtable := data[subtableOffset:]
+		regionIndexCount := int(binary.BigEndian.Uint16(subtable[4:]))
+		if len(subtable) < 6+2*regionIndexCount {
+			return out, errors.New("invalid CFF2 item variation data (EOF)")
+		}
+		indexes := make([]uint16, regionIndexCount)
+		for j := range indexes {
+			indexes[j] = binary.BigEndian.Uint16(subtable[6+2*j:])
+			if int(indexes[j]) >= regionCount {
+				return out, fmt.Errorf("invalid CFF2 region index %d", indexes[j])
+			}
+		}
+		out.regionIndexes[i] = indexes
+	}
+	return out, nil
+}
+
+// cff2DictHandler reads the values of the Top, Font and Private DICTs
+// needed to interpret the charstrings.
+type cff2DictHandler struct {
+	vstore cff2VariationStore // used by blend, in Private DICTs
+
+	// Top DICT
+	charstrings, fdArray, fdSelect, vstoreOffset int32
+
+	// Font DICT
+	privateSize, privateOffset int32
+
+	// Private DICT
+	subrs   int32 // relative to the Private DICT
+	vsindex int32
+}
+
+func (cff2DictHandler) Context() ps.PsContext { return ps.TopDict }
+
+func (h *cff2DictHandler) Apply(op ps.PsOperator, state *ps.Machine) error {
+	var err error
+	if !op.IsEscaped {
+		switch op.Operator {
+		case 17: // CharStrings
+			h.charstrings, err = popOffset(state)
+		case 24: // vstore
+			h.vstoreOffset, err = popOffset(state)
+		case 18: // Private
+			if state.ArgStack.Top < 2 {
+				return errors.New("invalid Private operator")
+			}
+			h.privateOffset = state.ArgStack.Pop()
+			h.privateSize = state.ArgStack.Pop()
+		case 19: // Subrs
+			h.subrs, err = popOffset(state)
+		case 22: // vsindex
+			if state.ArgStack.Top < 1 {
+				return errors.New("invalid vsindex operator")
+			}
+			h.vsindex = state.ArgStack.Pop()
+		case 23: // blend
+			// the values are only used for hinting: only the default values are kept
+			return h.blend(state) // do not clear the arg stack
+		}
+	} else {
+		switch op.Operator {
+		case 36: // FDArray
+			h.fdArray, err = popOffset(state)
+		case 37: // FDSelect
+			h.fdSelect, err = popOffset(state)
+		}
+	}
+	state.ArgStack.Clear()
+	return err
+}
+
+func (h *cff2DictHandler) blend(state *ps.Machine) error {
+	if state.ArgStack.Top < 1 {
+		return errors.New("invalid blend operator")
+	}
+	n := state.ArgStack.Pop()
+	if int(h.vsindex) >= len(h.vstore.regionIndexes) || h.vsindex < 0 {
+		return fmt.Errorf("invalid variation store index %d", h.vsindex)
+	}
+	k := int32(len(h.vstore.regionIndexes[h.vsindex]))
+	if n < 0 || state.ArgStack.Top < n*(k+1) {
+		return fmt.Errorf("invalid number of operands for blend: %d", n)
+	}
+	return state.ArgStack.PopN(n * k)
+}
+
+func popOffset(state *ps.Machine) (int32, error) {
+	if state.ArgStack.Top < 1 {
+		return 0, errors.New("missing DICT operand")
+	}
+	return state.ArgStack.Pop(), nil
+}
+
+// NumGlyphs returns the number of glyphs in the table.
+func (f *CFF2) NumGlyphs() int { return len(f.charstrings) }
+
+// LoadGlyph parses the glyph charstring to compute segments and path bounds,
+// applying the variations for the normalized coordinates `coords`,
+// which may be empty to select the default instance.
+// The blended values are rounded to integers.
+// It returns an error if the glyph is invalid or if decoding the charstring fails.
+func (f *CFF2) LoadGlyph(glyph fonts.GID, coords []float32) ([]fonts.Segment, ps.PathBounds, error) {
+	return f.loadGlyph(glyph, coords, nil)
+}
+
+// DrawGlyph is the same as LoadGlyph, but sends the outlines to `consumer`
+// as the charstring is interpreted, instead of storing them.
+// If not nil, `transform` is applied to the outlines as they are emitted.
+// Only the path bounds are returned, in font units.
+func (f *CFF2) DrawGlyph(glyph fonts.GID, coords []float32, transform *ps.Matrix, consumer ps.PathConsumer) (ps.PathBounds, error) {
+	loader, err := f.runCharstring(glyph, coords, nil, transform, consumer)
+	return loader.cs.Bounds, err
+}
+
+// loadGlyph also collects the hints of the glyph if `hints` is not nil.
+func (f *CFF2) loadGlyph(glyph fonts.GID, coords []float32, hints *Hints) ([]fonts.Segment, ps.PathBounds, error) {
+	loader, err := f.runCharstring(glyph, coords, hints, nil, nil)
+	return loader.cs.Segments, loader.cs.Bounds, err
+}
+
+// runCharstring interprets the charstring of `glyph`, sending its outlines
+// (transformed by `transform`, if not nil) to `consumer`, or storing them if it is nil.
+func (f *CFF2) runCharstring(glyph fonts.GID, coords []float32, hints *Hints, transform *ps.Matrix, consumer ps.PathConsumer) (*cff2CharstringHandler, error) {
+	if int(glyph) >= len(f.charstrings) {
+		return new(cff2CharstringHandler), fmt.Errorf("invalid glyph index %d", glyph)
+	}
+	private := f.privates[0]
+	if f.fdSelect != nil {
+		private = f.privates[f.fdSelect[glyph]]
+	}
+
+	var (
+		psi    ps.Machine
+		loader = cff2CharstringHandler{vstore: &f.vstore, coords: coords, vsindex: private.vsindex, hints: hints}
+	)
+	loader.cs.Consumer, loader.cs.Transform = consumer, transform
+	err := psi.Run(f.charstrings[glyph], private.subrs, f.globalSubrs, &loader)
+	// there is no endchar operator in CFF2 charstrings
+	loader.cs.ClosePath()
+	return &loader, err
+}
+
+// cff2CharstringHandler implements the operators of CFF2 charstrings,
+// which have no width, but support variations
+type cff2CharstringHandler struct {
+	cs ps.CharstringReader
+
+	vstore  *cff2VariationStore
+	coords  []float32
+	vsindex int32
+	scalars []float32 // for vsindex, computed on first use
+
+	hints *Hints // if not nil, filled with the hinting operators
+}
+
+func (cff2CharstringHandler) Context() ps.PsContext { return ps.Type2Charstring }
+
+func (met *cff2CharstringHandler) Apply(op ps.PsOperator, state *ps.Machine) error {
+	var err error
+	if !op.IsEscaped {
+		switch op.Operator {
+		case 10: // callsubr
+			return ps.LocalSubr(state) // do not clear the arg stack
+		case 29: // callgsubr
+			return ps.GlobalSubr(state) // do not clear the arg stack
+		case 15: // vsindex
+			if state.ArgStack.Top < 1 {
+				return errors.New("invalid vsindex operator")
+			}
+			met.vsindex, met.scalars = state.ArgStack.Pop(), nil
+		case 16: // blend
+			return met.blend(state) // do not clear the arg stack
+		case 21: // rmoveto
+			err = met.cs.Rmoveto(state)
+		case 22: // hmoveto
+			err = met.cs.Hmoveto(state)
+		case 4: // vmoveto
+			err = met.cs.Vmoveto(state)
+		case 1, 18: // hstem, hstemhm
+			met.hints.recordStems(state, false)
+			met.cs.Hstem(state)
+		case 3, 23: // vstem, vstemhm
+			met.hints.recordStems(state, true)
+			met.cs.Vstem(state)
+		case 19, 20: // hintmask, cntrmask
+			met.hints.recordStems(state, true)
+			mask := met.cs.Hintmask(state)
+			met.hints.recordMask(mask, op.Operator == 20, len(met.cs.Segments))
+			// the stack is managed by the previous call
+			return nil
+		case 5: // rlineto
+			met.cs.Rlineto(state)
+		case 6: // hlineto
+			met.cs.Hlineto(state)
+		case 7: // vlineto
+			met.cs.Vlineto(state)
+		case 8: // rrcurveto
+			met.cs.Rrcurveto(state)
+		case 24: // rcurveline
+			err = met.cs.Rcurveline(state)
+		case 25: // rlinecurve
+			err = met.cs.Rlinecurve(state)
+		case 26: // vvcurveto
+			met.cs.Vvcurveto(state)
+		case 27: // hhcurveto
+			met.cs.Hhcurveto(state)
+		case 30: // vhcurveto
+			met.cs.Vhcurveto(state)
+		case 31: // hvcurveto
+			met.cs.Hvcurveto(state)
+		default:
+			// return and endchar are not allowed in CFF2
+			err = fmt.Errorf("invalid operator %s in CFF2 charstring", op)
+		}
+	} else {
+		switch op.Operator {
+		case 34: // hflex
+			err = met.cs.Hflex(state)
+		case 35: // flex
+			err = met.cs.Flex(state)
+		case 36: // hflex1
+			err = met.cs.Hflex1(state)
+		case 37: // flex1
+			err = met.cs.Flex1(state)
+		default:
+			err = fmt.Errorf("invalid operator %s in CFF2 charstring", op)
+		}
+	}
+	state.ArgStack.Clear()
+	return err
+}
+
+// blend replaces the n default values and their n*k deltas
+// (where k is the number of regions) by the n interpolated values
+func (met *cff2CharstringHandler) blend(state *ps.Machine) error {
+	if state.ArgStack.Top < 1 {
+		return errors.New("invalid blend operator")
+	}
+	n := state.ArgStack.Pop()
+	if met.scalars == nil {
+		var err error
+		met.scalars, err = met.vstore.scalars(met.vsindex, met.coords)
+		if err != nil {
+			return err
+		}
+	}
+	k := int32(len(met.scalars))
+	base := state.ArgStack.Top - n*(k+1)
+	if n < 0 || base < 0 {
+		return fmt.Errorf("invalid number of operands for blend: %d", n)
+	}
+	vals := state.ArgStack.Vals[:]
+	for i := int32(0); i < n; i++ {
+		v := float32(vals[base+i])
+		for j, delta := range vals[base+n+i*k : base+n+(i+1)*k] {
+			v += float32(delta) * met.scalars[j]
+		}
+		vals[base+i] = int32(math.Round(float64(v)))
+	}
+	state.ArgStack.Top = base + n
+	return nil
+}
@@ -1,33 +1,199 @@
 package type1c
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/boxesandglue/textlayout/fonts"
 	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+	"github.com/boxesandglue/textlayout/fonts/simpleencodings"
 )
 
 // LoadGlyph parses the glyph charstring to compute segments and path bounds.
+// Accented glyphs (see GlyphComponents) are resolved by merging the outlines
+// of their components.
 // It returns an error if the glyph is invalid or if decoding the charstring fails.
 func (f *Font) LoadGlyph(glyph fonts.GID) ([]fonts.Segment, ps.PathBounds, error) {
+	return f.loadGlyph(glyph, nil)
+}
+
+// loadGlyph also collects the hints of the glyph if `hints` is not nil.
+func (f *Font) loadGlyph(glyph fonts.GID, hints *Hints) ([]fonts.Segment, ps.PathBounds, error) {
+	loader, err := f.runCharstring(glyph, hints, nil, nil)
+	if err != nil {
+		return nil, ps.PathBounds{}, err
+	}
+	if loader.seac == nil {
+		return loader.cs.Segments, loader.cs.Bounds, nil
+	}
+	components, err := f.seacComponents(*loader.seac)
+	if err != nil {
+		return nil, ps.PathBounds{}, err
+	}
+	var path segmentsPath
+	bounds, err := f.seacOutlines(components, nil, &path)
+	if err != nil {
+		return nil, ps.PathBounds{}, err
+	}
+	return path, bounds, nil
+}
+
+// DrawGlyph is the same as LoadGlyph, but sends the outlines to `consumer`
+// as the charstring is interpreted, instead of storing them.
+// If not nil, `transform` is applied to the outlines as they are emitted,
+// for instance FontMatrix(glyph).Multiply(userTransform) to get them in user space.
+// Only the path bounds are returned, in font units.
+func (f *Font) DrawGlyph(glyph fonts.GID, transform *ps.Matrix, consumer ps.PathConsumer) (ps.PathBounds, error) {
+	loader, err := f.runCharstring(glyph, nil, transform, consumer)
+	if err != nil {
+		return ps.PathBounds{}, err
+	}
+	if loader.seac == nil {
+		return loader.cs.Bounds, nil
+	}
+	components, err := f.seacComponents(*loader.seac)
+	if err != nil {
+		return ps.PathBounds{}, err
+	}
+	return f.seacOutlines(components, transform, consumer)
+}
+
+// runCharstring interprets the charstring of `glyph`, sending its outlines
+// (transformed by `transform`, if not nil) to `consumer`, or storing them if it is nil.
+func (f *Font) runCharstring(glyph fonts.GID, hints *Hints, transform *ps.Matrix, consumer ps.PathConsumer) (*type2CharstringHandler, error) {
 	var (
 		psi    ps.Machine
-		loader type2CharstringHandler
-		err    error
+		loader = type2CharstringHandler{hints: hints}
 	)
-	// if f.fdSelect != nil {
-	// 	index, err = f.fdSelect.fontDictIndex(glyph)
-	// 	if err != nil {
-	// 		return nil, ps.PathBounds{}, err
-	// 	}
-	// }
+	loader.cs.Consumer, loader.cs.Transform = consumer, transform
 	if int(glyph) >= len(f.CharStrings) {
-		return nil, ps.PathBounds{}, fmt.Errorf("invalid glyph index %d", glyph)
+		return nil, fmt.Errorf("invalid glyph index %d", glyph)
+	}
+	fd, err := f.fontDict(glyph)
+	if err != nil {
+		return nil, err
+	}
+	loader.nominalWidthX, loader.width = int32(fd.nominalWidthX), int32(fd.defaultWidthX)
+
+	err = psi.Run(f.CharStrings[glyph], fd.subrsIndex, f.global.globalSubrIndex, &loader)
+	return &loader, err
+}
+
+// Seac describes an accented glyph, built from two other glyphs
+// with the 'endchar' operator, the Type 2 equivalent
+// of the Type 1 'seac' operator.
+type Seac struct {
+	Base, Accent fonts.GID
+	// AccentOffset is the translation applied to the accent.
+	AccentOffset ps.Point
+}
+
+// GlyphComponents returns the components of `glyph` if it is an accented glyph,
+// or nil otherwise.
+// An error is returned if the glyph is invalid, or if its components are
+// not found in the font.
+func (f *Font) GlyphComponents(glyph fonts.GID) (*Seac, error) {
+	loader, err := f.runCharstring(glyph, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if loader.seac == nil {
+		return nil, nil
 	}
+	components, err := f.seacComponents(*loader.seac)
+	if err != nil {
+		return nil, err
+	}
+	return &components, nil
+}
 
-	subrs := f.subrsIndex
-	err = psi.Run(f.CharStrings[glyph], subrs, f.global.globalSubrIndex, &loader)
-	return loader.cs.Segments, loader.cs.Bounds, err
+// seacArgs are the arguments of the 'endchar' operator
+type seacArgs struct {
+	adx, ady     int32
+	bchar, achar int32
+}
+
+func (f *Font) seacComponents(args seacArgs) (Seac, error) {
+	if f.IsCIDFont() {
+		return Seac{}, errors.New("unsupported accented glyph in CID-keyed font")
+	}
+	base, err := f.seacComponent(args.bchar)
+	if err != nil {
+		return Seac{}, err
+	}
+	accent, err := f.seacComponent(args.achar)
+	if err != nil {
+		return Seac{}, err
+	}
+	return Seac{Base: base, Accent: accent, AccentOffset: ps.Point{X: args.adx, Y: args.ady}}, nil
+}
+
+// seacComponent returns the glyph named as the
+// character `code` in the standard encoding.
+func (f *Font) seacComponent(code int32) (fonts.GID, error) {
+	if code < 0 || int(code) >= len(simpleencodings.AdobeStandard) {
+		return 0, fmt.Errorf("invalid char code in endchar: %d", code)
+	}
+	name := simpleencodings.AdobeStandard[code]
+	if name != "" {
+		for gid := range f.charset {
+			if f.GlyphName(fonts.GID(gid)) == name {
+				return fonts.GID(gid), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown glyph in endchar for char code %d (%s)", code, name)
+}
+
+// seacOutlines draws the outlines of the base and the (translated) accent,
+// and returns their union bounds.
+// Nested accented glyphs are not supported.
+func (f *Font) seacOutlines(components Seac, transform *ps.Matrix, consumer ps.PathConsumer) (ps.PathBounds, error) {
+	base, err := f.runCharstring(components.Base, nil, transform, consumer)
+	if err != nil {
+		return ps.PathBounds{}, err
+	}
+	offset := components.AccentOffset
+	accentTransform := ps.Matrix{1, 0, 0, 1, float32(offset.X), float32(offset.Y)}
+	if transform != nil {
+		accentTransform = accentTransform.Multiply(*transform)
+	}
+	accent, err := f.runCharstring(components.Accent, nil, &accentTransform, consumer)
+	if err != nil {
+		return ps.PathBounds{}, err
+	}
+	if base.seac != nil || accent.seac != nil {
+		return ps.PathBounds{}, errors.New("invalid nested accented glyph")
+	}
+
+	bounds := base.cs.Bounds
+	if !accent.cs.IsEmpty() {
+		minA, maxA := accent.cs.Bounds.Min, accent.cs.Bounds.Max
+		minA.Move(offset.X, offset.Y)
+		maxA.Move(offset.X, offset.Y)
+		if base.cs.IsEmpty() {
+			bounds = ps.PathBounds{Min: minA, Max: maxA}
+		} else {
+			bounds.Enlarge(minA)
+			bounds.Enlarge(maxA)
+		}
+	}
+	return bounds, nil
+}
+
+// segmentsPath stores the outlines it receives.
+type segmentsPath []fonts.Segment
+
+func (p *segmentsPath) MoveTo(pt fonts.SegmentPoint) {
+	*p = append(*p, fonts.Segment{Op: fonts.SegmentOpMoveTo, Args: [3]fonts.SegmentPoint{pt}})
+}
+
+func (p *segmentsPath) LineTo(pt fonts.SegmentPoint) {
+	*p = append(*p, fonts.Segment{Op: fonts.SegmentOpLineTo, Args: [3]fonts.SegmentPoint{pt}})
+}
+
+func (p *segmentsPath) CurveTo(pt1, pt2, pt3 fonts.SegmentPoint) {
+	*p = append(*p, fonts.Segment{Op: fonts.SegmentOpCubeTo, Args: [3]fonts.SegmentPoint{pt1, pt2, pt3}})
 }
 
 // type2CharstringHandler implements operators needed to fetch Type2 charstring metrics
@@ -39,10 +205,29 @@ type type2CharstringHandler struct {
 	// `width` must be initialized to default width
 	nominalWidthX int32
 	width         int32
+	widthChecked  bool // the width is only found in the first stack-clearing operator
+
+	hints *Hints // if not nil, filled with the hinting operators
+
+	seac *seacArgs // filled for accented glyphs
 }
 
 func (type2CharstringHandler) Context() ps.PsContext { return ps.Type2Charstring }
 
+// checkWidth reads the width, if `hasWidth` is true and
+// the operator is the first one clearing the stack.
+// It returns ps.ErrInterrupt once the width is known, in width only mode.
+func (met *type2CharstringHandler) checkWidth(state *ps.Machine, hasWidth bool) error {
+	if met.widthChecked {
+		return nil
+	}
+	met.widthChecked = true
+	if hasWidth {
+		met.width = met.nominalWidthX + state.ArgStack.Vals[0]
+	}
+	return met.cs.WidthFound()
+}
+
 func (met *type2CharstringHandler) Apply(op ps.PsOperator, state *ps.Machine) error {
 	var err error
 	if !op.IsEscaped {
@@ -50,8 +235,13 @@ func (met *type2CharstringHandler) Apply(op ps.PsOperator, state *ps.Machine) er
 		case 11: // return
 			return state.Return() // do not clear the arg stack
 		case 14: // endchar
-			if state.ArgStack.Top > 0 { // width is optional
-				met.width = met.nominalWidthX + state.ArgStack.Vals[0]
+			top := state.ArgStack.Top
+			if err := met.checkWidth(state, top == 1 || top == 5); err != nil {
+				return err
+			}
+			if top >= 4 { // accented glyph
+				args := state.ArgStack.Vals[top-4 : top]
+				met.seac = &seacArgs{adx: args[0], ady: args[1], bchar: args[2], achar: args[3]}
 			}
 			met.cs.ClosePath()
 			return ps.ErrInterrupt
@@ -60,31 +250,41 @@ func (met *type2CharstringHandler) Apply(op ps.PsOperator, state *ps.Machine) er
 		case 29: // callgsubr
 			return ps.GlobalSubr(state) // do not clear the arg stack
 		case 21: // rmoveto
-			if state.ArgStack.Top > 2 { // width is optional
-				met.width = met.nominalWidthX + state.ArgStack.Vals[0]
+			if err := met.checkWidth(state, state.ArgStack.Top > 2); err != nil {
+				return err
 			}
 			err = met.cs.Rmoveto(state)
 		case 22: // hmoveto
-			if state.ArgStack.Top > 1 { // width is optional
-				met.width = met.nominalWidthX + state.ArgStack.Vals[0]
+			if err := met.checkWidth(state, state.ArgStack.Top > 1); err != nil {
+				return err
 			}
 			err = met.cs.Hmoveto(state)
 		case 4: // vmoveto
-			if state.ArgStack.Top > 1 { // width is optional
-				met.width = met.nominalWidthX + state.ArgStack.Vals[0]
+			if err := met.checkWidth(state, state.ArgStack.Top > 1); err != nil {
+				return err
 			}
 			err = met.cs.Vmoveto(state)
 		case 1, 18: // hstem, hstemhm
+			if err := met.checkWidth(state, state.ArgStack.Top&1 != 0); err != nil {
+				return err
+			}
+			met.hints.recordStems(state, false)
 			met.cs.Hstem(state)
 		case 3, 23: // vstem, vstemhm
+			if err := met.checkWidth(state, state.ArgStack.Top&1 != 0); err != nil {
+				return err
+			}
+			met.hints.recordStems(state, true)
 			met.cs.Vstem(state)
 		case 19, 20: // hintmask, cntrmask
 			// variable number of arguments, but always even
 			// for xxxmask, if there are arguments on the stack, then this is an impliied stem
-			if state.ArgStack.Top&1 != 0 {
-				met.width = met.nominalWidthX + state.ArgStack.Vals[0]
+			if err := met.checkWidth(state, state.ArgStack.Top&1 != 0); err != nil {
+				return err
 			}
-			met.cs.Hintmask(state)
+			met.hints.recordStems(state, true)
+			mask := met.cs.Hintmask(state)
+			met.hints.recordMask(mask, op.Operator == 20, len(met.cs.Segments))
 			// the stack is managed by the previous call
 			return nil
 
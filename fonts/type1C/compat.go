@@ -5,7 +5,12 @@ import (
 	"github.com/boxesandglue/textlayout/fonts/glyphsnames"
 )
 
+// GlyphName returns the name of the glyph `g`, or an empty string
+// for CID-keyed fonts, whose charset stores CIDs instead of names.
 func (fnt *Font) GlyphName(g fonts.GID) string {
+	if fnt.IsCIDFont() {
+		return ""
+	}
 	if int(g) < len(fnt.charset) {
 		sid := fnt.charset[int(g)]
 		if int(sid) < len(fnt.global.strings) {
@@ -0,0 +1,219 @@
+package type1c
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// Type 2 charstring operators used when encoding outlines
+const (
+	t2Hstem     = 1
+	t2Vstem     = 3
+	t2Rlineto   = 5
+	t2Rrcurveto = 8
+	t2Endchar   = 14
+	t2Hstemhm   = 18
+	t2Hintmask  = 19
+	t2Cntrmask  = 20
+	t2Rmoveto   = 21
+	t2Vstemhm   = 23
+
+	t2MaxArgs = 48 // maximum size of the argument stack
+)
+
+// ToCFF de-blends the variable outlines at the normalized coordinates `coords`
+// (which may be empty to select the default instance), and returns a static
+// CFF font, which may be written with `WriteCFFData`, for instance to be
+// embedded in a PDF file.
+//
+// Since CFF2 tables only store outlines, the names and font informations are
+// taken from `data`, whose CharStrings and Subrs fields are ignored. If
+// `data.GlyphNames` is empty, the glyphs are named "gidN".
+// `advances` are the advance widths of the glyphs (usually read from the 'hmtx'
+// table, with the 'HVAR' deltas applied), which may be nil to only use
+// `data.DefaultWidthX`.
+//
+// The charstrings are re-encoded: subroutines are flattened, while the blended
+// hints are preserved. The glyph indexes are preserved.
+func (f *CFF2) ToCFF(coords []float32, data FontData, advances []int32) (*CFF, error) {
+	if advances != nil && len(advances) != len(f.charstrings) {
+		return nil, fmt.Errorf("invalid number of advances %d (for %d glyphs)", len(advances), len(f.charstrings))
+	}
+	if len(data.GlyphNames) == 0 {
+		data.GlyphNames = make([]string, len(f.charstrings))
+		data.GlyphNames[0] = ".notdef"
+		for gid := 1; gid < len(data.GlyphNames); gid++ {
+			data.GlyphNames[gid] = fmt.Sprintf("gid%d", gid)
+		}
+	}
+
+	data.Subrs = nil
+	data.CharStrings = make([][]byte, len(f.charstrings))
+	for gid := range f.charstrings {
+		segments, _, hints, err := f.LoadGlyphWithHints(fonts.GID(gid), coords)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glyph %d: %s", gid, err)
+		}
+		var width *int32
+		if advances != nil && advances[gid] != int32(data.DefaultWidthX) {
+			w := advances[gid] - int32(data.NominalWidthX)
+			width = &w
+		}
+		data.CharStrings[gid] = append(encodeCharstring(segments, hints, width), t2Endchar)
+	}
+
+	return NewCFF(data)
+}
+
+// ToCFF2 wraps the static outlines of the font in a CFF2 table, which may
+// be written with `WriteCFF2Data`.
+//
+// The charstrings are re-encoded without width and endchar operator:
+// subroutines and accented glyphs are flattened, and the hints are
+// preserved, except for accented glyphs. Since CFF2 tables only store
+// outlines, the names, widths and font informations are dropped.
+func (f *Font) ToCFF2() (*CFF2, error) {
+	out := &CFF2{
+		charstrings: make([][]byte, len(f.CharStrings)),
+		privates:    []cff2Private{{}},
+	}
+	for gid := range f.CharStrings {
+		glyph := fonts.GID(gid)
+		components, err := f.GlyphComponents(glyph)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glyph %d: %s", gid, err)
+		}
+		var (
+			segments []fonts.Segment
+			hints    *Hints
+		)
+		if components != nil { // the hints of the components do not match the merged outlines
+			segments, _, err = f.LoadGlyph(glyph)
+		} else {
+			segments, _, hints, err = f.LoadGlyphWithHints(glyph)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid glyph %d: %s", gid, err)
+		}
+		out.charstrings[gid] = encodeCharstring(segments, hints, nil)
+	}
+	return out, nil
+}
+
+// charstringEncoder accumulates the operands of path operators
+type charstringEncoder struct {
+	out     []byte
+	args    []int32
+	pending byte // operator for the args, or 0
+}
+
+func (enc *charstringEncoder) flush() {
+	for _, v := range enc.args {
+		if v > math.MaxInt16 {
+			v = math.MaxInt16
+		} else if v < math.MinInt16 {
+			v = math.MinInt16
+		}
+		enc.out = append(enc.out, cffDictEncodeNumber(int64(v))...)
+	}
+	if enc.pending != 0 {
+		enc.out = append(enc.out, enc.pending)
+	}
+	enc.args, enc.pending = enc.args[:0], 0
+}
+
+// stems writes the stems with `op`, using as many operators as
+// required by the size of the argument stack.
+func (enc *charstringEncoder) stems(stems []Stem, op byte) {
+	var pos int32 // positions are relative within an operator
+	for _, stem := range stems {
+		if len(enc.args)+2 > t2MaxArgs {
+			enc.pending = op
+			enc.flush()
+			pos = 0
+		}
+		enc.args = append(enc.args, stem.Edge-pos, stem.Width)
+		pos = stem.Edge + stem.Width
+	}
+	if len(stems) != 0 {
+		enc.pending = op
+		enc.flush()
+	}
+}
+
+// encodeCharstring encodes the given outline and hints (which may be nil)
+// as a charstring, using the absolute outline coordinates and relative operators.
+// If `width` is not nil, it is stored as the first operand.
+// The returned charstring has no endchar operator, as required in CFF2.
+func encodeCharstring(segments []fonts.Segment, hints *Hints, width *int32) []byte {
+	var (
+		enc     charstringEncoder
+		masks   []HintMask
+		current [2]int32
+		start   [2]int32 // of the current contour
+	)
+	if width != nil {
+		enc.args = append(enc.args, *width)
+		// the width is only recognized before a stem or moveto operator
+		if len(segments) != 0 && segments[0].Op != fonts.SegmentOpMoveTo {
+			enc.args = append(enc.args, 0, 0)
+			enc.pending = t2Rmoveto
+			enc.flush()
+		}
+	}
+	if hints != nil && len(hints.HStems)+len(hints.VStems) != 0 {
+		hOp, vOp := byte(t2Hstem), byte(t2Vstem)
+		if len(hints.Masks) != 0 {
+			hOp, vOp = t2Hstemhm, t2Vstemhm
+		}
+		enc.stems(hints.HStems, hOp)
+		enc.stems(hints.VStems, vOp)
+		masks = hints.Masks
+	}
+
+	for i, seg := range segments {
+		for ; len(masks) != 0 && masks[0].Segment <= i; masks = masks[1:] {
+			enc.flush()
+			op := byte(t2Hintmask)
+			if masks[0].Counter {
+				op = t2Cntrmask
+			}
+			enc.out = append(append(enc.out, op), masks[0].Mask...)
+		}
+
+		var op byte
+		switch seg.Op {
+		case fonts.SegmentOpMoveTo:
+			op = t2Rmoveto
+		case fonts.SegmentOpLineTo:
+			op = t2Rlineto
+		case fonts.SegmentOpCubeTo:
+			op = t2Rrcurveto
+		default: // not produced by Type 2 charstrings
+			continue
+		}
+		points := seg.ArgsSlice()
+		end := [2]int32{int32(math.Round(float64(points[len(points)-1].X))), int32(math.Round(float64(points[len(points)-1].Y)))}
+		// the contours are implicitly closed, without changing the current point
+		if op == t2Rlineto && end == start && (i+1 == len(segments) || segments[i+1].Op == fonts.SegmentOpMoveTo) {
+			continue
+		}
+		// moveto can't be accumulated
+		if enc.pending != 0 && (op != enc.pending || op == t2Rmoveto || len(enc.args)+2*len(points) > t2MaxArgs) {
+			enc.flush()
+		}
+		for _, pt := range points {
+			x, y := int32(math.Round(float64(pt.X))), int32(math.Round(float64(pt.Y)))
+			enc.args = append(enc.args, x-current[0], y-current[1])
+			current = [2]int32{x, y}
+		}
+		if op == t2Rmoveto {
+			start = current
+		}
+		enc.pending = op
+	}
+	enc.flush()
+	return enc.out
+}
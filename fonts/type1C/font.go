@@ -4,9 +4,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"sort"
-
-	"github.com/boxesandglue/textlayout/fonts"
 )
 
 // Top DICT Data - see CFF spec 9 p. 14
@@ -16,6 +13,7 @@ func (f *Font) parseDict(dict []byte) {
 	f.bluescale = 0.039625
 	operands := make([]int, 0, 48)
 	operandsf := make([]float64, 0, 48)
+	numbers := make([]float64, 0, 48) // all the operands, in order
 	popInt := func() int {
 		var last int
 		if len(operands) > 0 {
@@ -26,12 +24,17 @@ func (f *Font) parseDict(dict []byte) {
 	}
 
 	pos := -1
+	isOperator := false
 	for {
 		pos++
 		if len(dict) <= pos {
 			return
 		}
+		if isOperator { // the previous operator consumed the operands
+			numbers = numbers[:0]
+		}
 		b0 := dict[pos]
+		isOperator = b0 <= 21
 		if b0 == 0 {
 			// version
 			f.version = SID(popInt())
@@ -95,12 +98,19 @@ func (f *Font) parseDict(dict []byte) {
 					f.underlineThickness = operandsf[0]
 				}
 			case 7:
-				// fontmatrix ignore
+				// FontMatrix, usually made of real and integer operands
+				if len(numbers) == 6 {
+					f.fontMatrix = append([]float64(nil), numbers...)
+				}
 				operands = operands[:0]
 			case 8:
 				// StrokeWidth
 			case 9:
-				f.bluescale = operandsf[0]
+				if len(operandsf) > 0 {
+					f.bluescale = operandsf[0]
+				} else {
+					f.bluescale = float64(popInt()) // integer value
+				}
 				operands = operands[:0]
 			case 10:
 				f.blueshift = popInt()
@@ -124,12 +134,14 @@ func (f *Font) parseDict(dict []byte) {
 				f.initialRandomSeed = popInt()
 			case 30:
 				// ROS
-				f.registry = SID(popInt())
-				f.ordering = SID(popInt())
 				f.supplement = popInt()
-			case 31:
-				// CIDFontVersion
-				popInt()
+				f.ordering = SID(popInt())
+				f.registry = SID(popInt())
+			case 1, 5, 6, 17, 20, 21, 22, 23:
+				// isFixedPitch, PaintType, CharstringType, LanguageGroup,
+				// SyntheticBase, PostScript, BaseFontName, BaseFontBlend: ignored
+			case 31, 32, 33, 35:
+				// CIDFontVersion, CIDFontRevision, CIDFontType, UIDBase: ignored
 			case 34:
 				// CID count
 				f.cidcount = popInt()
@@ -177,6 +189,7 @@ func (f *Font) parseDict(dict []byte) {
 			pos += 2
 			val := int(b1)<<8 | int(b2)
 			operands = append(operands, val)
+			numbers = append(numbers, float64(val))
 		} else if b0 == 29 {
 			b1 := dict[pos+1]
 			b2 := dict[pos+2]
@@ -185,13 +198,14 @@ func (f *Font) parseDict(dict []byte) {
 			pos += 4
 			val := int(b1)<<24 | int(b2)<<16 | int(b3)<<8 | int(b4)
 			operands = append(operands, val)
+			numbers = append(numbers, float64(val))
 		} else if b0 == 30 {
 			// float
 			valbefore := 0
 			valafter := 0
 			digitsafter := 0
 			mode := "before"
-			shift := 1
+			exponent, negative := 0, false
 		parsefloat:
 			for {
 				b1 := dict[pos+1]
@@ -209,9 +223,9 @@ func (f *Font) parseDict(dict []byte) {
 							valafter = 10*valafter + int(nibble)
 							digitsafter++
 						} else if mode == "E-" {
-							shift = int(nibble) * -1
+							exponent = 10*exponent - int(nibble)
 						} else if mode == "E" {
-							shift = int(nibble)
+							exponent = 10*exponent + int(nibble)
 						}
 					} else if nibble == 0xa {
 						mode = "after"
@@ -220,7 +234,7 @@ func (f *Font) parseDict(dict []byte) {
 					} else if nibble == 0xc {
 						mode = "E-"
 					} else if nibble == 0xe {
-						valbefore = valbefore * -1
+						negative = true
 					}
 					if firstnibble {
 						nibble = n2
@@ -236,21 +250,28 @@ func (f *Font) parseDict(dict []byte) {
 			}
 			var flt = float64(valbefore)
 			flt += (float64(valafter) / float64(div))
-			flt = math.Pow(flt, float64(shift))
+			flt *= math.Pow10(exponent)
+			if negative {
+				flt = -flt
+			}
 			operandsf = append(operandsf, flt)
+			numbers = append(numbers, flt)
 		} else if b0 >= 32 && b0 <= 246 {
 			val := int(b0) - 139
 			operands = append(operands, val)
+			numbers = append(numbers, float64(val))
 		} else if b0 >= 247 && b0 <= 250 {
 			b1 := dict[pos+1]
 			pos++
 			val := (int(b0)-247)*256 + int(b1) + 108
 			operands = append(operands, val)
+			numbers = append(numbers, float64(val))
 		} else if b0 >= 251 && b0 <= 254 {
 			b1 := dict[pos+1]
 			pos++
 			val := -(int(b0)-251)*256 - int(b1) - 108
 			operands = append(operands, val)
+			numbers = append(numbers, float64(val))
 		} else {
 			fmt.Println("b0", b0)
 			panic("not implemented yet")
@@ -282,16 +303,22 @@ func (f *Font) readSubrIndex(r io.ReadSeeker) error {
 
 func (f *Font) readEncoding(r io.ReadSeeker) error {
 	var err error
+	// 0 and 1 are the predefined Standard and Expert encodings,
+	// and CID-keyed fonts have no encoding
+	if f.encodingOffset <= 1 || f.IsCIDFont() {
+		return nil
+	}
 	f.encoding = make(map[int]int)
 
 	r.Seek(int64(f.encodingOffset), io.SeekStart)
 	read(r, &f.encodingFormat)
-	switch f.encodingFormat {
+	// the supplemental encodings (high bit of the format) are ignored
+	switch f.encodingFormat & 0x7f {
 	case 0:
 		var c uint8
 		read(r, &c)
 		var enc uint8
-		// is this correct???
+		// codes of the glyphs 1, 2, ...
 		for i := 0; i < int(c); i++ {
 			read(r, &enc)
 			f.encoding[i+1] = int(enc)
@@ -299,16 +326,20 @@ func (f *Font) readEncoding(r io.ReadSeeker) error {
 	case 1:
 		var nRanges uint8
 		read(r, &nRanges)
+		gid := 1
 		for i := 0; i < int(nRanges); i++ {
 			var first uint8
 			var nLeft uint8
-			if err = read(r, first); err != nil {
+			if err = read(r, &first); err != nil {
 				return err
 			}
-			if err = read(r, nLeft); err != nil {
+			if err = read(r, &nLeft); err != nil {
 				return err
 			}
-			// we don't need the encoding, so we ignore it
+			for code := int(first); code <= int(first)+int(nLeft); code++ {
+				f.encoding[gid] = code
+				gid++
+			}
 		}
 	default:
 		panic(fmt.Sprintf("not implemented yet: encoding format %d", f.encodingFormat))
@@ -398,6 +429,32 @@ func (f *Font) readPrivateDict(r io.ReadSeeker) error {
 	return nil
 }
 
+// readFontDicts reads the FDArray and FDSelect of a CID-keyed font,
+// with the private dicts and local subroutines of each font dict.
+// `data` is the whole CFF content.
+func (f *Font) readFontDicts(r io.ReadSeeker, data []byte) error {
+	if _, err := r.Seek(f.fdarray, io.SeekStart); err != nil {
+		return err
+	}
+	dicts := cffReadIndexData(r, "FDArray")
+	f.fontDicts = make([]*Font, len(dicts))
+	for i, dict := range dicts {
+		fd := &Font{global: f.global}
+		fd.parseDict(dict)
+		if err := fd.readPrivateDict(r); err != nil {
+			return err
+		}
+		if err := fd.readSubrIndex(r); err != nil {
+			return err
+		}
+		f.fontDicts[i] = fd
+	}
+
+	var err error
+	f.fdIndexes, err = parseFDSelect(data, int(f.fdselect), len(f.CharStrings), len(f.fontDicts))
+	return err
+}
+
 // GetRawIndexData returns a byte slice of the index
 func (f *Font) GetRawIndexData(r io.ReadSeeker, index mainIndex) ([]byte, error) {
 	var indexStart int64
@@ -496,70 +553,3 @@ func (f *Font) IsCIDFont() bool {
 func (f *Font) WriteSubset(w io.Writer) error {
 	return f.global.WriteCFFData(w)
 }
-
-// Subset changes the font so that only the given code points remain in the font. Subset must only be called once.
-func (f *Font) Subset(codepoints []fonts.GID) {
-	var globalSubr [][]byte
-	globalSubr = f.global.globalSubrIndex
-	fonts.RemoveDuplicates(codepoints)
-	cpIdx := 0
-	charstringsIdx := 0
-	for {
-		cp := int(codepoints[cpIdx])
-		for j := 1; j+charstringsIdx < cp; j++ {
-			f.CharStrings[j+charstringsIdx] = []byte{0xe}
-			f.charset[j+charstringsIdx] = 0
-		}
-		cpIdx++
-		charstringsIdx = cp
-		if cpIdx >= len(codepoints) {
-			break
-		}
-	}
-
-	lastcp := codepoints[len(codepoints)-1]
-	f.CharStrings = f.CharStrings[:lastcp+1]
-	f.charset = f.charset[:lastcp+1]
-
-	usedGlobalSubrsMap = make(map[int]bool)
-	usedLocalSubrsMap = make(map[int]bool)
-
-	for _, cp := range codepoints {
-		cs := f.CharStrings[cp]
-		getSubrsIndex(f.nominalWidthX, f.defaultWidthX, globalSubr, f.subrsIndex, cs, nil)
-	}
-
-	clearSubr(globalSubr, usedGlobalSubrsMap)
-	clearSubr(f.subrsIndex, usedLocalSubrsMap)
-}
-
-func clearSubr(subr [][]byte, usedSubrs map[int]bool) {
-	if len(usedSubrs) == 0 {
-		return
-	}
-	usedSubrSlice := []int{}
-
-	for k := range usedSubrs {
-		usedSubrSlice = append(usedSubrSlice, k)
-	}
-
-	sort.Ints(usedSubrSlice)
-
-	subrIdx := 0
-	i := 0
-	for {
-		subri := usedSubrSlice[subrIdx]
-		for j := 0; j+i < subri; j++ {
-			subr[j+i] = []byte{}
-		}
-		i = subri + 1
-		subrIdx++
-
-		if subrIdx >= len(usedSubrSlice) {
-			break
-		}
-	}
-	lastidx := usedSubrSlice[len(usedSubrSlice)-1]
-	subr = subr[:lastidx+1]
-
-}
@@ -0,0 +1,150 @@
+package type1c
+
+import (
+	"fmt"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+)
+
+// PrivateDictValues exposes the hinting and width values
+// of a Private DICT.
+// The blues and stem snaps are stored with absolute values
+// (they are delta encoded in the font file).
+type PrivateDictValues struct {
+	BlueValues, OtherBlues, FamilyBlues, FamilyOtherBlues []int
+	BlueScale                                             float64
+	BlueShift, BlueFuzz                                   int
+	StdHW, StdVW                                          int
+	StemSnapH, StemSnapV                                  []int
+	// DefaultWidthX is the width of the glyphs whose
+	// charstring has no width.
+	DefaultWidthX int
+	// NominalWidthX is added to the width found in the charstrings.
+	NominalWidthX int
+}
+
+// NumFontDicts returns the number of font dicts, that is the length of the
+// FDArray for CID-keyed fonts, or 1 for the other ones.
+func (f *Font) NumFontDicts() int {
+	if f.IsCIDFont() {
+		return len(f.fontDicts)
+	}
+	return 1
+}
+
+// FontDictIndex returns the index of the font dict used by `glyph`,
+// as defined by the FDSelect of CID-keyed fonts. It is always 0
+// for the other fonts.
+func (f *Font) FontDictIndex(glyph fonts.GID) (int, error) {
+	if int(glyph) >= len(f.CharStrings) {
+		return 0, fmt.Errorf("invalid glyph index %d", glyph)
+	}
+	if !f.IsCIDFont() {
+		return 0, nil
+	}
+	if int(glyph) >= len(f.fdIndexes) {
+		return 0, fmt.Errorf("invalid glyph index %d", glyph)
+	}
+	return int(f.fdIndexes[glyph]), nil
+}
+
+// fontDict returns the font dict used by `glyph`, which is `f`
+// itself for fonts which are not CID-keyed.
+func (f *Font) fontDict(glyph fonts.GID) (*Font, error) {
+	index, err := f.FontDictIndex(glyph)
+	if err != nil {
+		return nil, err
+	}
+	if !f.IsCIDFont() {
+		return f, nil
+	}
+	return f.fontDicts[index], nil
+}
+
+// defaultFontMatrix maps the usual 1000 units per em to the text space.
+var defaultFontMatrix = ps.Matrix{0.001, 0, 0, 0.001, 0, 0}
+
+// FontMatrix returns the matrix mapping the outlines of `glyph` (in font units)
+// to the text space. For CID-keyed fonts, the matrix of the font dict of
+// the glyph is combined with the one of the Top DICT.
+func (f *Font) FontMatrix(glyph fonts.GID) (ps.Matrix, error) {
+	fd, err := f.fontDict(glyph)
+	if err != nil {
+		return ps.Matrix{}, err
+	}
+	top, hasTop := toMatrix(f.fontMatrix)
+	if fd != f {
+		if m, ok := toMatrix(fd.fontMatrix); ok {
+			if !hasTop { // the default Top DICT matrix would scale the outlines twice
+				return m, nil
+			}
+			return m.Multiply(top), nil
+		}
+	}
+	if !hasTop {
+		return defaultFontMatrix, nil
+	}
+	return top, nil
+}
+
+// toMatrix returns false if `values` is not a valid matrix.
+func toMatrix(values []float64) (ps.Matrix, bool) {
+	var m ps.Matrix
+	if len(values) != 6 {
+		return m, false
+	}
+	for i, v := range values {
+		m[i] = float32(v)
+	}
+	return m, true
+}
+
+// PrivateDict returns the values of the Private DICT
+// of the font dict `fd` (see FontDictIndex).
+func (f *Font) PrivateDict(fd int) (PrivateDictValues, error) {
+	if fd < 0 || fd >= f.NumFontDicts() {
+		return PrivateDictValues{}, fmt.Errorf("invalid font dict index %d", fd)
+	}
+	dict := f
+	if f.IsCIDFont() {
+		dict = f.fontDicts[fd]
+	}
+	return PrivateDictValues{
+		BlueValues:       parseDelta(dict.bluevalues),
+		OtherBlues:       parseDelta(dict.otherblues),
+		FamilyBlues:      parseDelta(dict.familyblues),
+		FamilyOtherBlues: parseDelta(dict.familyotherblues),
+		BlueScale:        dict.bluescale,
+		BlueShift:        dict.blueshift,
+		BlueFuzz:         dict.bluefuzz,
+		StdHW:            dict.stdhw,
+		StdVW:            dict.stdvw,
+		StemSnapH:        parseDelta(dict.stemsnaph),
+		StemSnapV:        parseDelta(dict.stemsnapv),
+		DefaultWidthX:    dict.defaultWidthX,
+		NominalWidthX:    dict.nominalWidthX,
+	}, nil
+}
+
+// GlyphAdvance returns the advance of `glyph`, decoded from its charstring
+// with the widths of the Private DICT of its font dict.
+// The interpretation stops as soon as the width is known, so that
+// this method is much faster than loading the glyph outlines.
+func (f *Font) GlyphAdvance(glyph fonts.GID) (int32, error) {
+	if int(glyph) >= len(f.CharStrings) {
+		return 0, fmt.Errorf("invalid glyph index %d", glyph)
+	}
+	fd, err := f.fontDict(glyph)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		psi    ps.Machine
+		loader type2CharstringHandler
+	)
+	loader.cs.WidthOnly = true
+	loader.nominalWidthX, loader.width = int32(fd.nominalWidthX), int32(fd.defaultWidthX)
+	err = psi.Run(f.CharStrings[glyph], fd.subrsIndex, f.global.globalSubrIndex, &loader)
+	return loader.width, err
+}
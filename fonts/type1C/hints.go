@@ -0,0 +1,87 @@
+package type1c
+
+import (
+	"github.com/boxesandglue/textlayout/fonts"
+	ps "github.com/boxesandglue/textlayout/fonts/psinterpreter"
+)
+
+// Stem is an horizontal or vertical stem hint.
+type Stem struct {
+	// Edge is the bottom (or left) edge of the stem, in absolute coordinates.
+	Edge int32
+	// Width is the height (or width) of the stem.
+	// Edge hints have a width of -20 or -21.
+	Width int32
+}
+
+// HintMask is a 'hintmask' or 'cntrmask' operator.
+type HintMask struct {
+	// Mask has one bit per stem hint, the horizontal stems coming first,
+	// followed by the vertical ones. The most significant bit of the
+	// first byte is the first stem.
+	Mask []byte
+	// Counter is true for 'cntrmask' operators, which define counter
+	// groups rather than the active hints.
+	Counter bool
+	// Segment is the number of segments drawn before the operator,
+	// that is the index of the first segment the mask applies to.
+	Segment int
+}
+
+// Hints stores the hinting operators of a Type 2 glyph charstring.
+// The stems are stored in the order of their definition, so that
+// they match the bits of the masks.
+type Hints struct {
+	HStems []Stem // defined by the 'hstem' and 'hstemhm' operators
+	VStems []Stem // defined by the 'vstem' and 'vstemhm' operators, or before the first 'hintmask'
+	Masks  []HintMask
+}
+
+// recordStems adds the stems hints in the stack (pairs of relative edge, width)
+// to the collected hints, if enabled.
+func (h *Hints) recordStems(state *ps.Machine, vertical bool) {
+	if h == nil {
+		return
+	}
+	// skip the optional width
+	args := state.ArgStack.Vals[state.ArgStack.Top&1 : state.ArgStack.Top]
+	var pos int32
+	for ; len(args) >= 2; args = args[2:] {
+		stem := Stem{Edge: pos + args[0], Width: args[1]}
+		pos = stem.Edge + stem.Width
+		if vertical {
+			h.VStems = append(h.VStems, stem)
+		} else {
+			h.HStems = append(h.HStems, stem)
+		}
+	}
+}
+
+func (h *Hints) recordMask(mask []byte, counter bool, segment int) {
+	if h == nil {
+		return
+	}
+	h.Masks = append(h.Masks, HintMask{Mask: mask, Counter: counter, Segment: segment})
+}
+
+// LoadGlyphWithHints is the same as LoadGlyph, but also collects the
+// hints of the glyph, so that a hinting-aware rasterizer may use them.
+func (f *Font) LoadGlyphWithHints(glyph fonts.GID) ([]fonts.Segment, ps.PathBounds, *Hints, error) {
+	hints := new(Hints)
+	segments, bounds, err := f.loadGlyph(glyph, hints)
+	if err != nil {
+		return nil, ps.PathBounds{}, nil, err
+	}
+	return segments, bounds, hints, nil
+}
+
+// LoadGlyphWithHints is the same as LoadGlyph, but also collects the
+// hints of the glyph, whose stems are blended for the normalized coordinates `coords`.
+func (f *CFF2) LoadGlyphWithHints(glyph fonts.GID, coords []float32) ([]fonts.Segment, ps.PathBounds, *Hints, error) {
+	hints := new(Hints)
+	segments, bounds, err := f.loadGlyph(glyph, coords, hints)
+	if err != nil {
+		return nil, ps.PathBounds{}, nil, err
+	}
+	return segments, bounds, hints, nil
+}
@@ -103,6 +103,7 @@ func (c *CFF) readDictIndex(r io.Reader) error {
 		fnt := &Font{
 			underlineThickness: 50,
 			underlinePosition:  -100,
+			cidcount:           8720,
 		}
 		fnt.parseDict(cffFont)
 		c.Font = append(c.Font, fnt)
@@ -237,6 +238,18 @@ func Parse(r io.ReadSeeker) (*Font, error) {
 		if fnt.subrsOffset > 0 {
 			fnt.parseIndex(r, LocalSubrsIndex)
 		}
+		if fnt.IsCIDFont() {
+			if _, err := r.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			if err = fnt.readFontDicts(r, data); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return cff.Font[0], nil
@@ -0,0 +1,122 @@
+package type1c
+
+import (
+	"fmt"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// Subset changes the font so that only the given glyphs (and .notdef) remain in the font,
+// with the components of the accented glyphs.
+// The glyph indices are preserved: the unused glyphs are replaced by empty ones,
+// and the font is truncated after the last glyph kept.
+// The unused subroutines and font dicts are removed, and the remaining ones are renumbered.
+// Subset must only be called once.
+func (f *Font) Subset(glyphs []fonts.GID) error {
+	keep := map[fonts.GID]bool{0: true} // .notdef
+	numGlyphs := 1
+	for _, gid := range glyphs {
+		if int(gid) >= len(f.CharStrings) {
+			return fmt.Errorf("invalid glyph index %d", gid)
+		}
+		keep[gid] = true
+	}
+	// the components of accented glyphs are needed to render them
+	for _, gid := range glyphs {
+		components, err := f.GlyphComponents(gid)
+		if err != nil {
+			return fmt.Errorf("glyph %d: %s", gid, err)
+		}
+		if components != nil {
+			keep[components.Base], keep[components.Accent] = true, true
+		}
+	}
+	for gid := range keep {
+		if int(gid) >= numGlyphs {
+			numGlyphs = int(gid) + 1
+		}
+	}
+
+	fontDicts, fdIndexes := []*Font{f}, make([]uint16, numGlyphs)
+	if f.IsCIDFont() {
+		fontDicts = f.fontDicts
+		copy(fdIndexes, f.fdIndexes)
+	}
+
+	// find the used subroutines
+	sc := subrsCollector{
+		globalSubrs: f.global.globalSubrIndex,
+		globalCalls: make(map[int][]callSite),
+		globalFDs:   make(map[int]int),
+	}
+	localCalls := make([]map[int][]callSite, len(fontDicts)) // nil for unused font dicts
+	glyphCalls := make([][]callSite, numGlyphs)
+	for gid := range glyphCalls {
+		if !keep[fonts.GID(gid)] {
+			continue
+		}
+		fd := int(fdIndexes[gid])
+		if localCalls[fd] == nil {
+			localCalls[fd] = make(map[int][]callSite)
+		}
+		sc.fd, sc.localSubrs, sc.localCalls = fd, fontDicts[fd].subrsIndex, localCalls[fd]
+		calls, err := sc.walk(f.CharStrings[gid], &type2state{stack: make([]int, 0, 48)})
+		if err != nil {
+			return fmt.Errorf("glyph %d: %s", gid, err)
+		}
+		glyphCalls[gid] = calls
+	}
+
+	// renumber the subroutines, and the font dicts
+	global := newSubrsRenumbering(sc.globalSubrs, sc.globalCalls)
+	locals := make([]subrsRenumbering, len(fontDicts))
+	newFDs := make([]int, len(fontDicts))
+	var keptFontDicts []*Font
+	for fd, calls := range localCalls {
+		if calls == nil {
+			continue
+		}
+		locals[fd] = newSubrsRenumbering(fontDicts[fd].subrsIndex, calls)
+		newFDs[fd] = len(keptFontDicts)
+		keptFontDicts = append(keptFontDicts, fontDicts[fd])
+	}
+
+	// rewrite the subroutine calls
+	for old, calls := range sc.globalCalls {
+		var local subrsRenumbering
+		if fd, ok := sc.globalFDs[old]; ok {
+			local = locals[fd]
+		}
+		global.subrs[global.indexes[old]] = rewriteCalls(sc.globalSubrs[old], calls, global, local)
+	}
+	for fd, local := range locals {
+		for old, calls := range localCalls[fd] {
+			local.subrs[local.indexes[old]] = rewriteCalls(fontDicts[fd].subrsIndex[old], calls, global, local)
+		}
+	}
+	charstrings := make([][]byte, numGlyphs)
+	for gid := range charstrings {
+		if !keep[fonts.GID(gid)] {
+			charstrings[gid] = []byte{0xe} // endchar
+			if !f.IsCIDFont() {
+				f.charset[gid] = 0
+			}
+			fdIndexes[gid] = 0
+			continue
+		}
+		fd := fdIndexes[gid]
+		charstrings[gid] = rewriteCalls(f.CharStrings[gid], glyphCalls[gid], global, locals[fd])
+		fdIndexes[gid] = uint16(newFDs[fd])
+	}
+
+	f.CharStrings = charstrings
+	f.charset = f.charset[:numGlyphs]
+	f.global.globalSubrIndex = global.subrs
+	for fd, local := range locals {
+		fontDicts[fd].subrsIndex = local.subrs
+	}
+	if f.IsCIDFont() {
+		f.fontDicts, f.fdIndexes = keptFontDicts, fdIndexes
+	}
+	return nil
+}
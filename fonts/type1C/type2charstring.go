@@ -1,9 +1,14 @@
 package type1c
 
 import (
+	"errors"
 	"fmt"
 )
 
+// maxSubrsDepth is the maximum nesting of subroutines calls, see the Type 2
+// charstring spec, Appendix B.
+const maxSubrsDepth = 10
+
 func calculateBias(subrs [][]byte) int {
 	if len(subrs) < 1240 {
 		return 107
@@ -14,220 +19,224 @@ func calculateBias(subrs [][]byte) int {
 	return 32768
 }
 
-var (
-	usedGlobalSubrsMap map[int]bool
-	usedLocalSubrsMap  map[int]bool
-)
-
 type type2state struct {
-	stack         []int
-	cHints        int
-	hasWd         bool
-	defaultWidthX int
-	nominalWidthX int
-	width         int
+	stack  []int
+	cHints int
 }
 
 func (state *type2state) clearStack() {
 	state.stack = state.stack[:0]
 }
 
-func (state *type2state) pop() int {
+func (state *type2state) pop() (int, error) {
+	if len(state.stack) == 0 {
+		return 0, errors.New("invalid charstring: empty stack")
+	}
 	var i int
 	i, state.stack = state.stack[len(state.stack)-1], state.stack[:len(state.stack)-1]
-	return i
-}
-
-func (state *type2state) popN(n int) {
-	state.stack = state.stack[:len(state.stack)-n]
+	return i, nil
 }
 
 func (state *type2state) push(n int) {
 	state.stack = append(state.stack, n)
 }
 
+// clearEven clears the stack, and returns the number of pairs of arguments
+// (an additional width argument is ignored).
 func (state *type2state) clearEven() int {
 	halfEvenStack := len(state.stack) / 2
-	state.stack = state.stack[:len(state.stack)-halfEvenStack*2]
+	state.clearStack()
 	return halfEvenStack
 }
 
-// checkWd returns true if the last argument has read as a width.
-func (state *type2state) checkWd() bool {
-	if state.hasWd {
-		return false
-	}
-	if len(state.stack) == 0 {
-		return false
-	}
-	num := state.stack[0]
-	if num != state.defaultWidthX {
-		state.stack = state.stack[1:]
-		state.width = state.nominalWidthX + num
-		state.hasWd = true
-		return true
-	}
-	return false
+// callSite is a subroutine call in a charstring.
+type callSite struct {
+	start, end int  // position of the subroutine number in the charstring
+	global     bool // callgsubr or callsubr
+	index      int  // unbiased subroutine index
 }
 
-// getSubrsIndex goes recursively into all subroutines called by the char string cs and
-// sets the entries in the global maps usedGlobalSubrsMap and usedLocalSubrsMap to true
-// if the subroutine is used.
-func getSubrsIndex(nominalWidthX int, defaultWidthX int, globalSubrs [][]byte, localSubrs [][]byte, cs []byte, state *type2state) {
-	if state == nil {
-		state = &type2state{}
-		state.stack = make([]int, 0, 48)
-		state.nominalWidthX = nominalWidthX
-		state.defaultWidthX = defaultWidthX
-	}
+// subrsCollector walks through charstrings to find the subroutines
+// they use, and where they are called.
+type subrsCollector struct {
+	globalSubrs [][]byte
+	globalCalls map[int][]callSite // call sites in each used global subroutine
+	globalFDs   map[int]int        // font dict of the global subroutines calling local ones
 
-	localBias := calculateBias(localSubrs)
-	globalBias := calculateBias(globalSubrs)
+	// local subroutines of the current font dict
+	fd         int
+	localSubrs [][]byte
+	localCalls map[int][]callSite
 
-	pos := -1
-	for {
-		pos++
-		if len(cs) <= pos {
-			break
-		}
-		b0 := cs[pos]
-		if b0 == 1 {
-			// hstem
-			state.cHints += state.clearEven()
-			state.checkWd()
-		} else if b0 == 3 {
-			// vstem
-			state.cHints += state.clearEven()
-			state.checkWd()
-		} else if b0 == 4 {
-			// vmoveto
-			state.clearStack()
-		} else if b0 == 5 {
-			// rlineto
-			state.clearStack()
-		} else if b0 == 6 {
-			// hlineto
-			state.clearStack()
-		} else if b0 == 7 {
-			// vlineto
-			state.clearStack()
-		} else if b0 == 8 {
-			// rrcurveto
-			state.clearStack()
-		} else if b0 == 10 {
-			subrIdx := state.pop() + localBias
+	depth int
+}
 
-			if subrIdx < len(localSubrs) {
-				getSubrsIndex(nominalWidthX, defaultWidthX, globalSubrs, localSubrs, localSubrs[subrIdx], state)
-				usedLocalSubrsMap[subrIdx] = true
-			} else {
-				panic("subrIdx must be < len(localSubrs)")
+// walk interprets the charstring `cs`, recording the subroutines used,
+// and returns the calls found in `cs`. `state` is shared between
+// a charstring and the subroutines it calls, so that the hints
+// may be counted.
+func (sc *subrsCollector) walk(cs []byte, state *type2state) ([]callSite, error) {
+	var (
+		calls              []callSite
+		numStart, numEnd   int
+		lastTokenIsANumber bool
+	)
+	localBias, globalBias := calculateBias(sc.localSubrs), calculateBias(sc.globalSubrs)
+
+	for pos := 0; pos < len(cs); {
+		b0 := cs[pos]
+		isNumber := true
+		start := pos
+		switch {
+		case b0 == 28:
+			if pos+3 > len(cs) {
+				return nil, errors.New("invalid charstring (EOF)")
 			}
-			state.checkWd()
-		} else if b0 == 11 {
-			// return
-		} else if b0 == 12 {
-			// escape
-			b1 := cs[pos+1]
+			state.push(int(int16(uint16(cs[pos+1])<<8 | uint16(cs[pos+2]))))
+			pos += 3
+		case b0 >= 32 && b0 <= 246:
+			state.push(int(b0) - 139)
 			pos++
-			if b1 == 1 {
-
+		case b0 >= 247 && b0 <= 254:
+			if pos+2 > len(cs) {
+				return nil, errors.New("invalid charstring (EOF)")
 			}
-		} else if b0 == 14 {
-			// endchar
-		} else if b0 == 18 {
-			// hstemhm
+			if b0 <= 250 {
+				state.push((int(b0)-247)*256 + int(cs[pos+1]) + 108)
+			} else {
+				state.push(-(int(b0)-251)*256 - int(cs[pos+1]) - 108)
+			}
+			pos += 2
+		case b0 == 255:
+			if pos+5 > len(cs) {
+				return nil, errors.New("invalid charstring (EOF)")
+			}
+			// 16.16 fixed number
+			v := int32(uint32(cs[pos+1])<<24 | uint32(cs[pos+2])<<16 | uint32(cs[pos+3])<<8 | uint32(cs[pos+4]))
+			state.push(int(v >> 16))
+			pos += 5
+		default:
+			isNumber = false
+		}
+		if isNumber {
+			numStart, numEnd, lastTokenIsANumber = start, pos, true
+			continue
+		}
+
+		pos++
+		switch b0 {
+		case 1, 3, 18, 23: // hstem, vstem, hstemhm, vstemhm
 			state.cHints += state.clearEven()
-			state.checkWd()
-		} else if b0 == 19 {
-			// hintmask
+		case 19, 20: // hintmask, cntrmask
+			// arguments on the stack are implicit vstems
 			state.cHints += state.clearEven()
-			// advance over then number several bytes: each hint has one bit, so
-			// 1-8 hints use one byte, 9-16 use two bytes and so on
-			bits := state.cHints / 8
-			if state.cHints%8 != 0 {
-				bits++
+			pos += (state.cHints + 7) / 8
+		case 10, 29: // callsubr, callgsubr
+			if !lastTokenIsANumber {
+				return nil, errors.New("unsupported charstring: computed subroutine index")
 			}
-
-			for i := 0; i < bits; i++ {
-				pos++
+			index, err := state.pop()
+			if err != nil {
+				return nil, err
 			}
-		} else if b0 == 20 {
-			// cntrmask
-			for i := 0; i <= state.cHints/8; i++ {
-				pos++
+			call := callSite{start: numStart, end: numEnd, global: b0 == 29}
+			if call.global {
+				call.index = index + globalBias
+			} else {
+				call.index = index + localBias
 			}
-		} else if b0 == 21 {
-			// rmoveto
-			state.popN(2)
-		} else if b0 == 22 {
-			// hmoveto
-			state.clearStack()
-		} else if b0 == 23 {
-			// vstemhm
-			state.cHints += state.clearEven()
-		} else if b0 == 24 {
-			// rcurveline
-			state.clearStack()
-		} else if b0 == 25 {
-			// rlinecurve
-			state.clearStack()
-		} else if b0 == 26 {
-			// vvcurveto
+			calls = append(calls, call)
+			if err = sc.walkSubr(call, state); err != nil {
+				return nil, err
+			}
+		case 11, 14: // return, endchar
+			return calls, nil
+		case 12: // escape
+			pos++
 			state.clearStack()
-		} else if b0 == 27 {
-			// hhcurveto
+		default:
 			state.clearStack()
-		} else if b0 == 28 {
-			// shortint
-			a1 := int(cs[pos+1])
-			a2 := int(cs[pos+2])
-			var i int16
-			i = (int16(a1) << 8) | (int16(a2))
-			state.push(int(i))
-			pos += 2
-		} else if b0 == 29 {
-			subrIdx := state.pop() + globalBias
+		}
+		lastTokenIsANumber = false
+	}
+	return calls, nil
+}
 
-			if subrIdx < len(globalSubrs) {
-				getSubrsIndex(nominalWidthX, defaultWidthX, globalSubrs, localSubrs, globalSubrs[subrIdx], state)
-				usedGlobalSubrsMap[subrIdx] = true
-			} else {
-				panic("subrIdx must be < len(globalSubrs)")
+// walkSubr walks through the subroutine called at `call`.
+func (sc *subrsCollector) walkSubr(call callSite, state *type2state) error {
+	subrs, used := sc.localSubrs, sc.localCalls
+	if call.global {
+		subrs, used = sc.globalSubrs, sc.globalCalls
+	}
+	if call.index < 0 || call.index >= len(subrs) {
+		return fmt.Errorf("invalid subroutine index %d (for length %d)", call.index, len(subrs))
+	}
+	if sc.depth >= maxSubrsDepth {
+		return errors.New("invalid charstring: too many nested subroutines")
+	}
+
+	sc.depth++
+	calls, err := sc.walk(subrs[call.index], state)
+	sc.depth--
+	if err != nil {
+		return err
+	}
+
+	if _, seen := used[call.index]; !seen {
+		used[call.index] = calls
+	}
+	if call.global {
+		for _, c := range calls {
+			if c.global {
+				continue
 			}
-			state.checkWd()
-		} else if b0 == 30 {
-			// vhcurveto
-			state.clearStack()
-		} else if b0 == 31 {
-			// hvcurveto
-			state.clearStack()
-		} else if b0 >= 32 && b0 <= 246 {
-			var val int
-			val = int(b0) - 139
-			state.push(val)
-		} else if b0 >= 247 && b0 <= 250 {
-			b1 := cs[pos+1]
-			pos++
-			var val int
-			val = (int(b0)-247)*256 + int(b1) + 108
-			state.push(val)
-		} else if b0 >= 251 && b0 <= 254 {
-			b1 := cs[pos+1]
-			pos++
-			val := -(int(b0)-251)*256 - int(b1) - 108
-			state.push(val)
-		} else if b0 == 255 {
-			a1 := int(cs[pos+1])
-			a2 := int(cs[pos+2])
-			a3 := int(cs[pos+3])
-			a4 := int(cs[pos+4])
-			tmp := ((a1 << 24) | (a2 << 16) | (a3 << 8) | a4) / 65536
-			state.push(tmp)
-			pos += 4
+			// the local subroutines are renumbered independently for each font dict
+			if fd, has := sc.globalFDs[call.index]; has && fd != sc.fd {
+				return fmt.Errorf("unsupported global subroutine %d, calling local subroutines of several font dicts", call.index)
+			}
+			sc.globalFDs[call.index] = sc.fd
+			break
+		}
+	}
+	return nil
+}
+
+// subrsRenumbering maps the used subroutines to their new index.
+type subrsRenumbering struct {
+	subrs   [][]byte    // new subroutines, in the original order
+	indexes map[int]int // old index -> new index
+}
+
+func newSubrsRenumbering(subrs [][]byte, used map[int][]callSite) subrsRenumbering {
+	out := subrsRenumbering{indexes: make(map[int]int, len(used))}
+	for i, subr := range subrs {
+		if _, ok := used[i]; ok {
+			out.indexes[i] = len(out.subrs)
+			out.subrs = append(out.subrs, subr)
+		}
+	}
+	return out
+}
+
+// rewriteCalls returns a copy of `cs` where the subroutine calls
+// use the new indexes.
+func rewriteCalls(cs []byte, calls []callSite, global, local subrsRenumbering) []byte {
+	if len(calls) == 0 {
+		return cs
+	}
+	globalBias, localBias := calculateBias(global.subrs), calculateBias(local.subrs)
+	out := make([]byte, 0, len(cs))
+	last := 0
+	for _, call := range calls {
+		var index int
+		if call.global {
+			index = global.indexes[call.index] - globalBias
 		} else {
-			fmt.Println("b", b0)
-			// state.clearStack()
+			index = local.indexes[call.index] - localBias
 		}
+		out = append(out, cs[last:call.start]...)
+		out = append(out, cffDictEncodeNumber(int64(index))...) // same encoding for small integers
+		last = call.end
 	}
+	return append(out, cs[last:]...)
 }
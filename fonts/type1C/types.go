@@ -86,6 +86,9 @@ type Font struct {
 	familyotherblues   []int
 	fdarray            int64
 	fdselect           int64
+	fdIndexes          []uint16  // FDSelect, for CID-keyed fonts
+	fontDicts          []*Font   // FDArray, for CID-keyed fonts
+	fontMatrix         []float64 // nil for the default value
 	fullname           SID
 	familyname         SID
 	initialRandomSeed  int
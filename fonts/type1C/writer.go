@@ -175,19 +175,7 @@ func (c *CFF) WriteCFFData(w io.Writer) error {
 
 	// We need to save the string index and the global subr index to be added
 	// after the dict index.
-	// The dict index needs information about offsets. These offsets need to take into
-	// account the length of the string index and the global subr index.
 	var stringGlobalSubrIndex bytes.Buffer
-	var dictIndex bytes.Buffer
-
-	// Now let's the dict index into a temporary buffer so we know the length
-	// of the buffer.
-	_, err = c.writeIndex(&dictIndex, DictIndex)
-	if err != nil {
-		return err
-	}
-	dictIndexLen := dictIndex.Len()
-
 	for _, idx := range []mainIndex{StringIndex, GlobalSubrIndex} {
 		_, err := c.writeIndex(&stringGlobalSubrIndex, idx)
 		if err != nil {
@@ -195,28 +183,27 @@ func (c *CFF) WriteCFFData(w io.Writer) error {
 		}
 	}
 
+	// let's assume one font only for now
 	cf := c.Font[c.Fontindex]
-	fi, err := cf.fontInfo()
+
+	// The dict index needs information about offsets, which depend on its length.
+	// Since the offsets are encoded with a fixed size, the length of the dict index
+	// is known once the font data is laid out, with any (non zero) offsets.
+	if err = cf.writeFontData(1); err != nil {
+		return err
+	}
+	var dictIndex bytes.Buffer
+	dictIndexLen, err := c.writeIndex(&dictIndex, DictIndex)
 	if err != nil {
 		return err
 	}
-	// let's assume one font only for now
+
 	// offsets are now header + name index + len(dictindex) + len(string index) + len(global subr index) + offsets
 	// that is                         cur + len(dictindex) + stringGlobalSubrIndex.Len() + offsets
 	baselen := cur + dictIndexLen + stringGlobalSubrIndex.Len()
-	// encodings can be ignored
-	cf.encodingOffset = 0
-
-	// the encoded size of the offsets can change. We calculate the delta and add this to the baselen
-	prevLen := len(cffDictEncodeNumber(int64(cf.charstringsOffset))) + len(cffDictEncodeNumber(int64(cf.charsetOffset))) + len(cffDictEncodeNumber(int64(cf.privatedictoffset)))
-	newLen := len(cffDictEncodeNumber(int64(baselen+fi.CharStringsOffset))) + len(cffDictEncodeNumber(int64(baselen+fi.CharSetOffset))) + len(cffDictEncodeNumber(int64(baselen+fi.PrivateDictOffset)))
-	delta := newLen - prevLen
-
-	baselen += delta
-	cf.charstringsOffset = int64(baselen + fi.CharStringsOffset)
-	cf.charsetOffset = int64(baselen + fi.CharSetOffset)
-	cf.privatedictoffset = int64(baselen + fi.PrivateDictOffset)
-	cf.privatedictsize = fi.PrivateDictSize
+	if err = cf.writeFontData(baselen); err != nil {
+		return err
+	}
 
 	// now we can write all data
 	// header + NameIndex is already written to w
@@ -229,7 +216,7 @@ func (c *CFF) WriteCFFData(w io.Writer) error {
 	stringGlobalSubrIndex.WriteTo(w)
 
 	// For the selected font, the char string, private dict and local subr index are written.
-	// The data field is created in fontInfo() above.
+	// The data field is created in writeFontData() above.
 	_, err = w.Write(cf.data)
 	if err != nil {
 		return err
@@ -243,43 +230,125 @@ func (c *CFF) WriteCFFData(w io.Writer) error {
 // c.Font[c.Fontindex].Subset(c.globalSubrIndex, codepoints)
 // }
 
-type fontinfo struct {
-	CharSetOffset     int
-	CharStringsOffset int
-	EncodingOffset    int
-	PrivateDictSize   int
-	PrivateDictOffset int
-}
-
-// fontInfo writes the charstrings, charset and private dict index
-func (f *Font) fontInfo() (*fontinfo, error) {
-	fi := &fontinfo{}
-	fi.CharSetOffset = 0
+// writeFontData writes the charstrings, charset, encoding, FDSelect, FDArray,
+// private dicts and local subr indexes in the data field, and updates the
+// offsets of the font, starting at `base`.
+func (f *Font) writeFontData(base int) error {
 	var b bytes.Buffer
 
-	for _, index := range []mainIndex{CharStringsIndex, CharSet, PrivateDict, LocalSubrsIndex} {
-		switch index {
-		case CharSet:
-			fi.CharSetOffset = b.Len()
-		case CharStringsIndex:
-			fi.CharStringsOffset = b.Len()
-		case PrivateDict:
-			fi.PrivateDictOffset = b.Len()
-		case Encoding:
-			fi.EncodingOffset = b.Len()
-		case LocalSubrsIndex:
-			cur := b.Len()
-			fi.PrivateDictSize = cur - fi.PrivateDictOffset
+	f.charstringsOffset = int64(base + b.Len())
+	if _, err := f.writeIndex(&b, CharStringsIndex); err != nil {
+		return err
+	}
+	f.charsetOffset = int64(base + b.Len())
+	if _, err := f.writeIndex(&b, CharSet); err != nil {
+		return err
+	}
+
+	if f.IsCIDFont() {
+		f.fdselect = int64(base + b.Len())
+		if _, err := writeFDSelect(&b, f.fdIndexes[:len(f.CharStrings)], len(f.fontDicts)); err != nil {
+			return err
 		}
-		if index != LocalSubrsIndex || len(f.subrsIndex) > 0 {
-			if _, err := f.writeIndex(&b, index); err != nil {
-				return nil, err
+		f.fdarray = int64(base + b.Len())
+		if _, err := f.writeFDArray(&b, base); err != nil {
+			return err
+		}
+	} else {
+		// a nil encoding means one of the predefined ones
+		if f.encoding != nil {
+			f.encodingOffset = base + b.Len()
+			if _, err := f.writeIndex(&b, Encoding); err != nil {
+				return err
 			}
 		}
-
+		f.privatedictoffset = int64(base + b.Len())
+		if err := f.writePrivateData(&b); err != nil {
+			return err
+		}
 	}
+
 	f.data = b.Bytes()
-	return fi, nil
+	return nil
+}
+
+// writePrivateData writes the private dict, followed by the local subrs,
+// and updates the private dict size.
+func (f *Font) writePrivateData(w io.Writer) error {
+	var err error
+	f.privatedictsize, err = f.writeIndex(w, PrivateDict)
+	if err != nil {
+		return err
+	}
+	_, err = f.writeIndex(w, LocalSubrsIndex)
+	return err
+}
+
+// writeFDArray writes the FDArray of a CID-keyed font, followed by the private data of
+// each font dict. `base` is the start of the data field.
+func (f *Font) writeFDArray(w *bytes.Buffer, base int) (int, error) {
+	// the private data are written after the FDArray, whose length
+	// does not depend on the (fixed size) offsets
+	var private bytes.Buffer
+	privateOffsets := make([]int, len(f.fontDicts))
+	for i, fd := range f.fontDicts {
+		privateOffsets[i] = private.Len()
+		if err := fd.writePrivateData(&private); err != nil {
+			return 0, err
+		}
+	}
+	dicts := make([][]byte, len(f.fontDicts))
+	for i, fd := range f.fontDicts {
+		dicts[i] = fd.cffEncodeFontDict()
+	}
+	var fdArray bytes.Buffer
+	l, err := writeIndexData(&fdArray, dicts, "FDArray")
+	if err != nil {
+		return 0, err
+	}
+
+	start := base + w.Len() + l
+	for i, fd := range f.fontDicts {
+		fd.privatedictoffset = int64(start + privateOffsets[i])
+		dicts[i] = fd.cffEncodeFontDict()
+	}
+	if l, err = writeIndexData(w, dicts, "FDArray"); err != nil {
+		return 0, err
+	}
+	n, err := private.WriteTo(w)
+	return l + int(n), err
+}
+
+// writeFDSelect writes the font dict index of each glyph, using format 3.
+func writeFDSelect(w io.Writer, fdIndexes []uint16, numFontDicts int) (int, error) {
+	if numFontDicts > 256 {
+		return 0, fmt.Errorf("too many font dicts: %d", numFontDicts)
+	}
+	type fdRange struct {
+		First uint16
+		FD    uint8
+	}
+	var ranges []fdRange
+	numGlyphs := len(fdIndexes)
+	for gid, fd := range fdIndexes {
+		if len(ranges) == 0 || ranges[len(ranges)-1].FD != uint8(fd) {
+			ranges = append(ranges, fdRange{First: uint16(gid), FD: uint8(fd)})
+		}
+	}
+	if err := write(w, uint8(3)); err != nil {
+		return 0, err
+	}
+	if err := write(w, uint16(len(ranges))); err != nil {
+		return 0, err
+	}
+	if err := write(w, ranges); err != nil {
+		return 0, err
+	}
+	// sentinel
+	if err := write(w, uint16(numGlyphs)); err != nil {
+		return 0, err
+	}
+	return 5 + 3*len(ranges), nil
 }
 
 // cffDictEncodeFloat encodes a number. If the number is an integer number, it will be encoded by cffDictEncodeNumber().
@@ -338,6 +407,12 @@ func cffDictEncodeFloat(num float64) []byte {
 	return ret
 }
 
+// cffDictEncodeOffset encodes an offset with a fixed size, so that the
+// length of a dict does not depend on the offsets it contains.
+func cffDictEncodeOffset(offset int64) []byte {
+	return []byte{29, byte(offset >> 24), byte(offset >> 16), byte(offset >> 8), byte(offset)}
+}
+
 func cffDictEncodeNumber(num int64) []byte {
 	if num >= -107 && num <= 107 {
 		return []byte{byte(num) + 139}
@@ -370,6 +445,13 @@ func cffDictEncodeNumber(num int64) []byte {
 // cffEncodeTopDict returns a byte slice of the encoded dictionary
 func (f *Font) cffEncodeTopDict() []byte {
 	var b []byte
+	if f.IsCIDFont() {
+		// ROS must be the first operator
+		b = append(b, cffDictEncodeNumber(int64(f.registry))...)
+		b = append(b, cffDictEncodeNumber(int64(f.ordering))...)
+		b = append(b, cffDictEncodeNumber(int64(f.supplement))...)
+		b = append(b, 12, 30)
+	}
 	if i := f.version; i != 0 {
 		b = append(b, cffDictEncodeNumber(int64(i))...)
 		b = append(b, 0)
@@ -405,6 +487,12 @@ func (f *Font) cffEncodeTopDict() []byte {
 		b = append(b, cffDictEncodeNumber(int64(f.bbox[3]))...)
 		b = append(b, 5)
 	}
+	if len(f.fontMatrix) == 6 {
+		for _, v := range f.fontMatrix {
+			b = append(b, cffDictEncodeFloat(v)...)
+		}
+		b = append(b, 12, 7)
+	}
 	if num := f.underlinePosition; num != -100 {
 		b = append(b, cffDictEncodeFloat(num)...)
 		b = append(b, 12, 3)
@@ -414,22 +502,52 @@ func (f *Font) cffEncodeTopDict() []byte {
 		b = append(b, 12, 4)
 	}
 	if num := f.charsetOffset; num != 0 {
-		b = append(b, cffDictEncodeNumber(int64(num))...)
+		b = append(b, cffDictEncodeOffset(num)...)
 		b = append(b, 15)
 	}
 	if num := f.encodingOffset; num != 0 {
-		b = append(b, cffDictEncodeNumber(int64(num))...)
+		b = append(b, cffDictEncodeOffset(int64(num))...)
 		b = append(b, 16)
 	}
 	if num := f.charstringsOffset; num != 0 {
-		b = append(b, cffDictEncodeNumber(int64(num))...)
+		b = append(b, cffDictEncodeOffset(num)...)
 		b = append(b, 17)
 	}
 	if num := f.privatedictoffset; num != 0 {
 		b = append(b, cffDictEncodeNumber(int64(f.privatedictsize))...)
-		b = append(b, cffDictEncodeNumber(int64(num))...)
+		b = append(b, cffDictEncodeOffset(num)...)
 		b = append(b, 18)
 	}
+	if f.IsCIDFont() {
+		if num := f.cidcount; num != 8720 {
+			b = append(b, cffDictEncodeNumber(int64(num))...)
+			b = append(b, 12, 34)
+		}
+		b = append(b, cffDictEncodeOffset(f.fdarray)...)
+		b = append(b, 12, 36)
+		b = append(b, cffDictEncodeOffset(f.fdselect)...)
+		b = append(b, 12, 37)
+	}
+	return b
+}
+
+// cffEncodeFontDict returns a byte slice of the encoded Font DICT,
+// used in the FDArray of CID-keyed fonts.
+func (f *Font) cffEncodeFontDict() []byte {
+	var b []byte
+	if i := f.name; i != 0 {
+		b = append(b, cffDictEncodeNumber(int64(i))...)
+		b = append(b, 12, 38)
+	}
+	if len(f.fontMatrix) == 6 {
+		for _, v := range f.fontMatrix {
+			b = append(b, cffDictEncodeFloat(v)...)
+		}
+		b = append(b, 12, 7)
+	}
+	b = append(b, cffDictEncodeNumber(int64(f.privatedictsize))...)
+	b = append(b, cffDictEncodeOffset(f.privatedictoffset)...)
+	b = append(b, 18)
 	return b
 }
 
@@ -465,6 +583,10 @@ func (f *Font) cffEncodePrivateDict() []byte {
 		b = append(b, cffDictEncodeFloat(num)...)
 		b = append(b, 12, 9)
 	}
+	if num := f.blueshift; num != 7 {
+		b = append(b, cffDictEncodeNumber(int64(num))...)
+		b = append(b, 12, 10)
+	}
 	if num := f.bluefuzz; num != 1 {
 		b = append(b, cffDictEncodeFloat(float64(f.bluefuzz))...)
 		b = append(b, 12, 11)
@@ -498,7 +620,14 @@ func (f *Font) cffEncodePrivateDict() []byte {
 		b = append(b, 21)
 	}
 	if len(f.subrsIndex) > 0 {
-		b = append(b, cffDictEncodeNumber(int64(len(b)+2))...)
+		// the offset is relative to the start of the private dict,
+		// and the subrs are written right after it:
+		// take into account the length of the offset itself
+		offset := len(b) + 2
+		for len(b)+len(cffDictEncodeNumber(int64(offset)))+1 != offset {
+			offset = len(b) + len(cffDictEncodeNumber(int64(offset))) + 1
+		}
+		b = append(b, cffDictEncodeNumber(int64(offset))...)
 		b = append(b, 19)
 	}
 	return b
@@ -622,16 +751,23 @@ func (f *Font) writeEncoding(w io.Writer) (int, error) {
 		return 0, err
 	}
 
-	if err = write(w, uint8(len(f.encoding))); err != nil {
-		return 0, err
+	// codes of the glyphs 1, 2, ... (the font may have been subsetted)
+	var codes []uint8
+	for gid := 1; gid < len(f.CharStrings) && len(codes) < 255; gid++ {
+		code, ok := f.encoding[gid]
+		if !ok {
+			break
+		}
+		codes = append(codes, uint8(code))
 	}
 
-	for i := 1; i <= len(f.encoding); i++ {
-		if err = write(w, uint8(f.encoding[i])); err != nil {
-			return 0, err
-		}
+	if err = write(w, uint8(len(codes))); err != nil {
+		return 0, err
+	}
+	if err = write(w, codes); err != nil {
+		return 0, err
 	}
-	return 2 + len(f.encoding), nil
+	return 2 + len(codes), nil
 }
 
 // writeIndex returns the number of bytes written to the index and an error.
@@ -652,3 +788,146 @@ func (f *Font) writeIndex(w io.Writer, index mainIndex) (int, error) {
 
 	return 0, fmt.Errorf("Could not write index %d", index)
 }
+
+// WriteCFF2Data writes the content of a 'CFF2' table to w.
+// The font dicts only store the Private DICT values needed
+// to interpret the charstrings (local subroutines and vsindex).
+func (f *CFF2) WriteCFF2Data(w io.Writer) error {
+	var globalSubrs, vstore, charstrings, fdSelect, privates bytes.Buffer
+	writeCFF2Index(&globalSubrs, f.globalSubrs)
+	writeCFF2Index(&charstrings, f.charstrings)
+	if len(f.vstore.regions) != 0 {
+		f.vstore.write(&vstore)
+	}
+	if f.fdSelect != nil {
+		if _, err := writeFDSelect(&fdSelect, f.fdSelect, len(f.privates)); err != nil {
+			return err
+		}
+	}
+	privateOffsets, privateSizes := make([]int, len(f.privates)), make([]int, len(f.privates))
+	for i, private := range f.privates {
+		dict := private.encode()
+		privateOffsets[i], privateSizes[i] = privates.Len(), len(dict)
+		privates.Write(dict)
+		if len(private.subrs) != 0 {
+			writeCFF2Index(&privates, private.subrs)
+		}
+	}
+
+	// the offsets have a fixed size, so that the length of the
+	// top dict and of the FDArray are known before the layout
+	topDictLength := 6 + 7
+	if f.fdSelect != nil {
+		topDictLength += 7
+	}
+	if vstore.Len() != 0 {
+		topDictLength += 6
+	}
+	vstoreOffset := 5 + topDictLength + globalSubrs.Len()
+	charstringsOffset := vstoreOffset + vstore.Len()
+	fdSelectOffset := charstringsOffset + charstrings.Len()
+	fdArrayOffset := fdSelectOffset + fdSelect.Len()
+	privatesOffset := fdArrayOffset + 5 + (len(f.privates)+1)*4 + len(f.privates)*11
+
+	fontDicts := make([][]byte, len(f.privates))
+	for i := range fontDicts {
+		dict := append(cffDictEncodeOffset(int64(privateSizes[i])), cffDictEncodeOffset(int64(privatesOffset+privateOffsets[i]))...)
+		fontDicts[i] = append(dict, 18)
+	}
+	var fdArray bytes.Buffer
+	writeCFF2Index(&fdArray, fontDicts)
+
+	topDict := append(cffDictEncodeOffset(int64(charstringsOffset)), 17)
+	topDict = append(append(topDict, cffDictEncodeOffset(int64(fdArrayOffset))...), 12, 36)
+	if f.fdSelect != nil {
+		topDict = append(append(topDict, cffDictEncodeOffset(int64(fdSelectOffset))...), 12, 37)
+	}
+	if vstore.Len() != 0 {
+		topDict = append(append(topDict, cffDictEncodeOffset(int64(vstoreOffset))...), 24)
+	}
+
+	header := []byte{2, 0, 5, byte(len(topDict) >> 8), byte(len(topDict))}
+	for _, data := range [][]byte{header, topDict, globalSubrs.Bytes(), vstore.Bytes(), charstrings.Bytes(), fdSelect.Bytes(), fdArray.Bytes(), privates.Bytes()} {
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encode returns the Private DICT, whose local subroutines
+// are expected to follow it.
+func (p cff2Private) encode() []byte {
+	var out []byte
+	if p.vsindex != 0 {
+		out = append(cffDictEncodeNumber(int64(p.vsindex)), 22)
+	}
+	if len(p.subrs) != 0 { // the offset is relative to the Private DICT
+		out = append(append(out, cffDictEncodeOffset(int64(len(out)+6))...), 19)
+	}
+	return out
+}
+
+// writeCFF2Index writes the data slices as an INDEX whose count is
+// stored on 4 bytes, using 4 bytes offsets.
+func writeCFF2Index(w *bytes.Buffer, data [][]byte) {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	w.Write(header[:])
+	if len(data) == 0 {
+		return
+	}
+	w.WriteByte(4)
+	offset := uint32(1)
+	binary.BigEndian.PutUint32(header[:], offset)
+	w.Write(header[:])
+	for _, b := range data {
+		offset += uint32(len(b))
+		binary.BigEndian.PutUint32(header[:], offset)
+		w.Write(header[:])
+	}
+	for _, b := range data {
+		w.Write(b)
+	}
+}
+
+// write writes the variation store, preceded by its length.
+// Since the deltas are stored in the charstrings, the item
+// variation data only contain the region indexes.
+func (vs cff2VariationStore) write(w *bytes.Buffer) {
+	var axisCount int
+	if len(vs.regions) != 0 {
+		axisCount = len(vs.regions[0])
+	}
+	regionsOffset := 8 + 4*len(vs.regionIndexes)
+	subtableOffset := regionsOffset + 4 + 6*axisCount*len(vs.regions)
+
+	var store []byte
+	store = binary.BigEndian.AppendUint16(store, 1) // format
+	store = binary.BigEndian.AppendUint32(store, uint32(regionsOffset))
+	store = binary.BigEndian.AppendUint16(store, uint16(len(vs.regionIndexes)))
+	for _, indexes := range vs.regionIndexes {
+		store = binary.BigEndian.AppendUint32(store, uint32(subtableOffset))
+		subtableOffset += 6 + 2*len(indexes)
+	}
+	store = binary.BigEndian.AppendUint16(store, uint16(axisCount))
+	store = binary.BigEndian.AppendUint16(store, uint16(len(vs.regions)))
+	for _, region := range vs.regions {
+		for _, axis := range region {
+			for _, v := range axis {
+				store = binary.BigEndian.AppendUint16(store, uint16(int16(math.Round(float64(v)*(1<<14)))))
+			}
+		}
+	}
+	for _, indexes := range vs.regionIndexes {
+		store = binary.BigEndian.AppendUint16(store, 0) // item count
+		store = binary.BigEndian.AppendUint16(store, 0) // word delta count
+		store = binary.BigEndian.AppendUint16(store, uint16(len(indexes)))
+		for _, index := range indexes {
+			store = binary.BigEndian.AppendUint16(store, index)
+		}
+	}
+
+	w.Write(binary.BigEndian.AppendUint16(nil, uint16(len(store))))
+	w.Write(store)
+}
@@ -190,7 +190,7 @@ func LoadGraphite(font *truetype.Font) (*GraphiteFace, error) {
 	out.cmap, _ = font.Cmap()
 	out.names = font.Names
 
-	hmtx, glyphs := font.Hmtx, font.Glyf
+	hmtx, glyphs := font.Hmtx, font.Glyf()
 	tables := font.Graphite
 
 	out.sill, err = parseTableSill(tables.Sill)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/boxesandglue/textlayout/fonts/binaryreader"
 	"github.com/boxesandglue/textlayout/fonts/truetype"
+	"github.com/boxesandglue/textlayout/language"
 )
 
 // FeatureValue specifies a value for a given feature.
@@ -40,15 +41,57 @@ func (feats FeaturesValue) FindFeature(id Tag) *FeatureValue {
 type tableFeat []feature
 
 type feature struct {
-	settings []featureSetting
+	settings []FeatureSetting
 	id       Tag
 	flags    uint16
 	label    truetype.NameID
 }
 
-type featureSetting struct {
+// FeatureSetting is one of the values a feature may take.
+type FeatureSetting struct {
 	Value int16
-	Label truetype.NameID
+	Label truetype.NameID // entry of the 'name' table
+}
+
+// FeatureInfo describes a feature of a Graphite font, for
+// instance to present it in a user interface.
+type FeatureInfo struct {
+	ID    Tag
+	Label truetype.NameID // entry of the 'name' table
+	// Settings are the possible values, the first being the default.
+	Settings []FeatureSetting
+}
+
+// Features returns the features defined by the font, in the
+// order of its 'Feat' table. Their IDs may be used in a `FeaturesValue`.
+// The labels are resolved with `Name`.
+func (f *GraphiteFace) Features() []FeatureInfo {
+	out := make([]FeatureInfo, len(f.feat))
+	for i, feat := range f.feat {
+		out[i] = FeatureInfo{
+			ID:       zeroToSpace(feat.id),
+			Label:    feat.label,
+			Settings: append([]FeatureSetting(nil), feat.settings...),
+		}
+	}
+	return out
+}
+
+// Name returns the entry `name` of the 'name' table, in the language
+// which best matches `lang`, or an empty string.
+// See truetype.TableName.Name for the details.
+func (f *GraphiteFace) Name(name truetype.NameID, lang language.Language) string {
+	return f.names.Name(name, lang)
+}
+
+// Languages returns the languages for which the font defines
+// specific feature values, to be used with `FeaturesForLang`.
+func (f *GraphiteFace) Languages() []Tag {
+	out := make([]Tag, len(f.sill))
+	for i, rec := range f.sill {
+		out[i] = padLanguage(rec.langcode)
+	}
+	return out
 }
 
 // return the feature with their first setting selected (or 0)
@@ -128,7 +171,7 @@ func parseTableFeat(data []byte) (tableFeat, error) {
 	}
 
 	// parse the settings array
-	allSettings := make([]featureSetting, maxSettingsLength)
+	allSettings := make([]FeatureSetting, maxSettingsLength)
 	err = r.ReadStruct(allSettings)
 	if err != nil {
 		return nil, fmt.Errorf("invalid Feat table: %s", err)
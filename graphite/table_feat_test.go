@@ -317,3 +317,58 @@ func TestGetFeature(t *testing.T) {
 		t.Fatal("feature not found")
 	}
 }
+
+func TestFeaturesInfo(t *testing.T) {
+	ft := loadGraphite(t, "Padauk.ttf")
+	feats := ft.Features()
+	if len(feats) != 11 {
+		t.Fatalf("expected 11 features, got %d", len(feats))
+	}
+
+	kdot := feats[0]
+	if kdot.ID != truetype.MustNewTag("kdot") || len(kdot.Settings) != 2 {
+		t.Fatalf("unexpected feature %v", kdot)
+	}
+	if name := ft.Name(kdot.Label, "en"); name != "Khamti style dots" {
+		t.Errorf("unexpected label %s", name)
+	}
+	if name := ft.Name(kdot.Settings[1].Label, ""); name != "True" {
+		t.Errorf("unexpected setting label %s", name)
+	}
+
+	// the IDs match the default values
+	defaults := ft.FeaturesForLang(0)
+	for _, feat := range feats {
+		if defaults.FindFeature(feat.ID) == nil {
+			t.Errorf("feature %s not found", feat.ID)
+		}
+	}
+
+	langs := ft.Languages()
+	if len(langs) != 3 || langs[0] != truetype.MustNewTag("kht ") {
+		t.Errorf("unexpected languages %v", langs)
+	}
+}
+
+func TestSetNumericFeature(t *testing.T) {
+	ft := loadGraphite(t, "charis.ttf")
+	// Uppercase Eng alternates
+	const id Tag = 1024
+	var found bool
+	for _, feat := range ft.Features() {
+		found = found || feat.ID == id
+	}
+	if !found {
+		t.Fatal("feature 1024 not found")
+	}
+
+	shape := func(value int16) GID {
+		feats := ft.FeaturesForLang(0)
+		feats.FindFeature(id).Value = value
+		seg := ft.Shape(nil, []rune{0x014A}, 0, feats, 0)
+		return GID(seg.First.GID())
+	}
+	if def, alt := shape(0), shape(1); def == alt {
+		t.Fatalf("feature 1024 has no effect (glyph %d)", def)
+	}
+}
@@ -42,6 +42,15 @@ func zeroToSpace(x Tag) Tag {
 	}
 }
 
+// padLanguage replaces the trailing zero bytes of a language code
+// by spaces, like "kht\x00" by "kht ".
+func padLanguage(x Tag) Tag {
+	for mask, space := Tag(0xFF), Tag(0x20); mask != 0 && x&mask == 0; mask, space = mask<<8, space<<8 {
+		x |= space
+	}
+	return x
+}
+
 // getFeatures selects the features and values for the given language, or
 // the default ones if the language is not found.
 func (si tableSill) getFeatures(langname Tag, features tableFeat) FeaturesValue {
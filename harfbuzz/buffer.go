@@ -1,7 +1,6 @@
 package harfbuzz
 
 import (
-	"math"
 	"sort"
 
 	"github.com/boxesandglue/textlayout/fonts"
@@ -32,7 +31,7 @@ const (
 	bsfHasDefaultIgnorables
 	bsfHasSpaceFallback
 	bsfHasGPOSAttachment
-	bsfHasUnsafeToBreak
+	bsfHasGlyphFlags
 	bsfHasCGJ
 	bsfDefault bufferScratchFlags = 0x00000000
 
@@ -92,6 +91,14 @@ type Buffer struct {
 	// Precise the cluster handling behavior.
 	ClusterLevel ClusterLevel
 
+	// Trace, if not nil, is called at each step of the OpenType layout
+	// (see `TraceEvent`), with the current content of the buffer, in the spirit
+	// of the --trace option of hb-shape. This is useful to debug fonts and shaping issues,
+	// but slows down the shaping.
+	// Note that the glyph positions are only meaningful during the GPOS table,
+	// and that `buffer` must not be modified.
+	Trace func(buffer *Buffer, font *Font, event TraceEvent)
+
 	// some pathological cases can be constructed
 	// (for example with GSUB tables), where the size of the buffer
 	// grows out of bounds
@@ -173,6 +180,33 @@ func (b *Buffer) AddRunes(text []rune, itemOffset, itemLength int) {
 	b.context[1] = text[itemOffset+itemLength : s]
 }
 
+// SetPreContext sets the text logically preceding the content of the buffer,
+// which is used, for instance, to join Arabic letters across run boundaries
+// (when a run is split by a color or font change).
+// Only the last runes of `context` are used.
+// Since `AddRunes` sets the context, this method should be called after
+// the text has been added.
+func (b *Buffer) SetPreContext(context []rune) {
+	pre := make([]rune, 0, contextLength)
+	// the pre-context is stored outward, that is in reverse order
+	for i := len(context) - 1; i >= 0 && len(pre) < contextLength; i-- {
+		pre = append(pre, context[i])
+	}
+	b.context[0] = pre
+}
+
+// SetPostContext sets the text logically following the content of the buffer.
+// Only the first runes of `context` are used.
+// Since `AddRune` and `AddRunes` set the context, this method should be called after
+// the text has been added.
+// See also `SetPreContext`.
+func (b *Buffer) SetPostContext(context []rune) {
+	if len(context) > contextLength {
+		context = context[:contextLength]
+	}
+	b.context[1] = append([]rune(nil), context...)
+}
+
 // GuessSegmentProperties fills unset buffer segment properties based on buffer Unicode
 // contents and can be used when no other information is available.
 //
@@ -440,18 +474,76 @@ done:
 	b.skipGlyph()
 }
 
-// unsafeToBreak adds the flag `GlyphFlagUnsafeToBreak`
+// unsafeToBreak adds the flags `GlyphUnsafeToBreak` and `GlyphUnsafeToConcat`
 // when needed, between `start` and `end`.
 func (b *Buffer) unsafeToBreak(start, end int) {
-	if end-start < 2 {
+	b.setGlyphFlags(GlyphUnsafeToBreak|GlyphUnsafeToConcat, start, end, true, false)
+}
+
+// unsafeToConcat adds the flag `GlyphUnsafeToConcat` when needed, between `start` and `end`,
+// if the buffer is asked to produce it.
+func (b *Buffer) unsafeToConcat(start, end int) {
+	if b.Flags&ProduceUnsafeToConcat == 0 {
+		return
+	}
+	b.setGlyphFlags(GlyphUnsafeToConcat, start, end, true, false)
+}
+
+// unsafeToBreakFromOutbuffer is the same as unsafeToBreak, but `start`
+// refers to the output buffer and `end` to the input buffer.
+func (b *Buffer) unsafeToBreakFromOutbuffer(start, end int) {
+	b.setGlyphFlags(GlyphUnsafeToBreak|GlyphUnsafeToConcat, start, end, true, true)
+}
+
+// unsafeToConcatFromOutbuffer is the same as unsafeToConcat, but `start`
+// refers to the output buffer and `end` to the input buffer.
+func (b *Buffer) unsafeToConcatFromOutbuffer(start, end int) {
+	if b.Flags&ProduceUnsafeToConcat == 0 {
 		return
 	}
-	b.unsafeToBreakImpl(start, end)
+	b.setGlyphFlags(GlyphUnsafeToConcat, start, end, false, true)
 }
 
-func (b *Buffer) unsafeToBreakImpl(start, end int) {
-	cluster := findMinCluster(b.Info, start, end, maxInt)
-	b.unsafeToBreakSetMask(b.Info, start, end, cluster)
+// setGlyphFlags adds `mask` to the glyphs between `start` and `end`.
+// If `interior` is true, the glyphs of the first cluster are not flagged.
+// If `fromOutBuffer` is true, `start` refers to the output buffer.
+func (b *Buffer) setGlyphFlags(mask GlyphMask, start, end int, interior, fromOutBuffer bool) {
+	end = min(end, len(b.Info))
+
+	if interior && !fromOutBuffer && end-start < 2 {
+		return
+	}
+
+	b.scratchFlags |= bsfHasGlyphFlags
+
+	if !fromOutBuffer || !b.haveOutput {
+		if !interior {
+			for i := start; i < end; i++ {
+				b.Info[i].Mask |= mask
+			}
+		} else {
+			cluster := findMinCluster(b.Info, start, end, maxInt)
+			b.setGlyphFlagsInterior(b.Info, start, end, cluster, mask)
+		}
+		return
+	}
+
+	//   assert (start <= out_len);
+	//   assert (idx <= end);
+
+	if !interior {
+		for i := start; i < len(b.outInfo); i++ {
+			b.outInfo[i].Mask |= mask
+		}
+		for i := b.idx; i < end; i++ {
+			b.Info[i].Mask |= mask
+		}
+	} else {
+		cluster := findMinCluster(b.outInfo, start, len(b.outInfo), maxInt)
+		cluster = findMinCluster(b.Info, b.idx, end, cluster)
+		b.setGlyphFlagsInterior(b.outInfo, start, len(b.outInfo), cluster, mask)
+		b.setGlyphFlagsInterior(b.Info, b.idx, end, cluster, mask)
+	}
 }
 
 // return the smallest cluster between `cluster` and  infos[start:end]
@@ -462,33 +554,15 @@ func findMinCluster(infos []GlyphInfo, start, end, cluster int) int {
 	return cluster
 }
 
-func (b *Buffer) unsafeToBreakSetMask(infos []GlyphInfo,
-	start, end, cluster int,
-) {
+// setGlyphFlagsInterior adds `mask` to the glyphs not in `cluster`
+func (b *Buffer) setGlyphFlagsInterior(infos []GlyphInfo, start, end, cluster int, mask GlyphMask) {
 	for i := start; i < end; i++ {
 		if cluster != infos[i].Cluster {
-			b.scratchFlags |= bsfHasUnsafeToBreak
-			infos[i].Mask |= GlyphUnsafeToBreak
+			infos[i].Mask |= mask
 		}
 	}
 }
 
-func (b *Buffer) unsafeToBreakFromOutbuffer(start, end int) {
-	if !b.haveOutput {
-		b.unsafeToBreakImpl(start, end)
-		return
-	}
-
-	//   assert (start <= out_len);
-	//   assert (idx <= end);
-
-	cluster := math.MaxInt32
-	cluster = findMinCluster(b.outInfo, start, len(b.outInfo), cluster)
-	cluster = findMinCluster(b.Info, b.idx, end, cluster)
-	b.unsafeToBreakSetMask(b.outInfo, start, len(b.outInfo), cluster)
-	b.unsafeToBreakSetMask(b.Info, b.idx, end, cluster)
-}
-
 // reset `b.outInfo`, and adjust `pos` to have
 // same length as `Info` (without zeroing its values)
 func (b *Buffer) clearPositions() {
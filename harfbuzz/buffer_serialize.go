@@ -0,0 +1,329 @@
+package harfbuzz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// ported from harfbuzz/src/hb-buffer-serialize.cc Copyright © 2012,2013  Google, Inc. Behdad Esfahbod
+
+// SerializeFormat selects the textual representation used
+// by `Buffer.Serialize` and `Buffer.Deserialize`.
+type SerializeFormat uint8
+
+const (
+	// SerializeText is the compact format used by the hb-shape tool,
+	// of the form [name=cluster@xOffset,yOffset+xAdvance,yAdvance|...]
+	SerializeText SerializeFormat = iota
+	// SerializeJSON is a JSON array of objects, with the keys
+	// "g" (glyph), "cl" (cluster), "dx", "dy" (offsets), "ax", "ay" (advances),
+	// "fl" (flags) and "xb", "yb", "w", "h" (extents).
+	SerializeJSON
+)
+
+// SerializeFlags controls the content of the serialized glyphs.
+// The zero value outputs the glyph names, clusters and positions.
+type SerializeFlags uint8
+
+const (
+	// SerializeNoClusters does not output the clusters.
+	SerializeNoClusters SerializeFlags = 1 << iota
+	// SerializeNoPositions does not output the offsets nor the advances.
+	SerializeNoPositions
+	// SerializeNoGlyphNames outputs glyph indices instead of names.
+	SerializeNoGlyphNames
+	// SerializeGlyphExtents outputs the glyph extents.
+	SerializeGlyphExtents
+	// SerializeGlyphFlags outputs the glyph flags (see `GlyphUnsafeToBreak`).
+	SerializeGlyphFlags
+	// SerializeNoAdvances does not output the advances, which are
+	// instead accumulated in the offsets of the next glyphs.
+	SerializeNoAdvances
+)
+
+// Serialize returns a textual representation of the glyphs in the buffer, which should
+// have been shaped, compatible with the output of the hb-shape tool.
+// `font` is used to fetch the glyph names and extents: if it is nil,
+// the glyph indices are written and the extents are omitted.
+// An empty buffer is serialized as an empty string.
+func (b *Buffer) Serialize(font *Font, format SerializeFormat, flags SerializeFlags) string {
+	if len(b.Info) == 0 {
+		return "" // as the reference implementation
+	}
+	if font == nil {
+		flags |= SerializeNoGlyphNames
+		flags &^= SerializeGlyphExtents
+	}
+	if format == SerializeJSON {
+		return b.serializeJSON(font, flags)
+	}
+	return b.serializeText(font, flags)
+}
+
+func (b *Buffer) serializeText(font *Font, flags SerializeFlags) string {
+	var (
+		gs   strings.Builder
+		x, y Position
+	)
+	gs.WriteByte('[')
+	for i, glyph := range b.Info {
+		if i != 0 {
+			gs.WriteByte('|')
+		}
+		if flags&SerializeNoGlyphNames != 0 {
+			fmt.Fprintf(&gs, "%d", glyph.Glyph)
+		} else {
+			gs.WriteString(font.glyphToString(glyph.Glyph))
+		}
+
+		if flags&SerializeNoClusters == 0 {
+			fmt.Fprintf(&gs, "=%d", glyph.Cluster)
+		}
+		pos := b.Pos[i]
+
+		if flags&SerializeNoPositions == 0 {
+			if x+pos.XOffset != 0 || y+pos.YOffset != 0 {
+				fmt.Fprintf(&gs, "@%d,%d", x+pos.XOffset, y+pos.YOffset)
+			}
+			if flags&SerializeNoAdvances == 0 {
+				fmt.Fprintf(&gs, "+%d", pos.XAdvance)
+				if pos.YAdvance != 0 {
+					fmt.Fprintf(&gs, ",%d", pos.YAdvance)
+				}
+			}
+		}
+
+		if flags&SerializeGlyphFlags != 0 {
+			if glyph.Mask&glyphFlagDefined != 0 {
+				fmt.Fprintf(&gs, "#%X", glyph.Mask&glyphFlagDefined)
+			}
+		}
+
+		if flags&SerializeGlyphExtents != 0 {
+			extents, _ := font.GlyphExtents(glyph.Glyph)
+			fmt.Fprintf(&gs, "<%d,%d,%d,%d>", extents.XBearing, extents.YBearing, extents.Width, extents.Height)
+		}
+
+		if flags&SerializeNoAdvances != 0 {
+			x += pos.XAdvance
+			y += pos.YAdvance
+		}
+	}
+	gs.WriteByte(']')
+	return gs.String()
+}
+
+func (b *Buffer) serializeJSON(font *Font, flags SerializeFlags) string {
+	var (
+		gs   strings.Builder
+		x, y Position
+	)
+	gs.WriteByte('[')
+	for i, glyph := range b.Info {
+		if i != 0 {
+			gs.WriteByte(',')
+		}
+		gs.WriteString(`{"g":`)
+		if flags&SerializeNoGlyphNames != 0 {
+			fmt.Fprintf(&gs, "%d", glyph.Glyph)
+		} else {
+			name, _ := json.Marshal(font.glyphToString(glyph.Glyph))
+			gs.Write(name)
+		}
+
+		if flags&SerializeNoClusters == 0 {
+			fmt.Fprintf(&gs, `,"cl":%d`, glyph.Cluster)
+		}
+		pos := b.Pos[i]
+
+		if flags&SerializeNoPositions == 0 {
+			fmt.Fprintf(&gs, `,"dx":%d,"dy":%d`, x+pos.XOffset, y+pos.YOffset)
+			if flags&SerializeNoAdvances == 0 {
+				fmt.Fprintf(&gs, `,"ax":%d,"ay":%d`, pos.XAdvance, pos.YAdvance)
+			}
+		}
+
+		if flags&SerializeGlyphFlags != 0 {
+			if glyph.Mask&glyphFlagDefined != 0 {
+				fmt.Fprintf(&gs, `,"fl":%d`, glyph.Mask&glyphFlagDefined)
+			}
+		}
+
+		if flags&SerializeGlyphExtents != 0 {
+			extents, _ := font.GlyphExtents(glyph.Glyph)
+			fmt.Fprintf(&gs, `,"xb":%d,"yb":%d,"w":%d,"h":%d`, extents.XBearing, extents.YBearing, extents.Width, extents.Height)
+		}
+		gs.WriteByte('}')
+
+		if flags&SerializeNoAdvances != 0 {
+			x += pos.XAdvance
+			y += pos.YAdvance
+		}
+	}
+	gs.WriteByte(']')
+	return gs.String()
+}
+
+// Deserialize parses `s`, as returned by `Serialize`, and appends the glyphs to the buffer,
+// setting their index, cluster, position and flags. The extents are ignored.
+// `font` is used to resolve the glyph names: if it is nil, only glyph indices
+// (and names of the form gidDDD) are supported.
+// Note that the offsets of glyphs serialized with `SerializeNoAdvances` are
+// accumulated, so that the original positions can't be recovered.
+func (b *Buffer) Deserialize(s string, font *Font, format SerializeFormat) error {
+	if format == SerializeJSON {
+		return b.deserializeJSON(s, font)
+	}
+	return b.deserializeText(s, font)
+}
+
+// glyphFromString resolves a glyph name, a gidDDD name or a glyph index.
+func (f *Font) glyphFromString(s string) (fonts.GID, bool) {
+	if f != nil {
+		if face, ok := f.face.(interface {
+			GlyphByName(name string) (fonts.GID, bool)
+		}); ok {
+			if gid, ok := face.GlyphByName(s); ok {
+				return gid, true
+			}
+		}
+	}
+	gid, err := strconv.ParseUint(strings.TrimPrefix(s, "gid"), 10, 32)
+	return fonts.GID(gid), err == nil
+}
+
+func (b *Buffer) deserializeText(s string, font *Font) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return fmt.Errorf("invalid serialized buffer %q", s)
+	}
+
+	var infos []GlyphInfo
+	var positions []GlyphPosition
+	for _, item := range strings.Split(s[1:len(s)-1], "|") {
+		var (
+			info GlyphInfo
+			pos  GlyphPosition
+		)
+		// the glyph name is ended by the first field separator
+		end := strings.IndexAny(item, "=@+#<")
+		if end == -1 {
+			end = len(item)
+		}
+		gid, ok := font.glyphFromString(item[:end])
+		if !ok {
+			return fmt.Errorf("invalid glyph %q", item[:end])
+		}
+		info.Glyph = gid
+
+		for item = item[end:]; item != ""; {
+			sep := item[0]
+			end = strings.IndexAny(item[1:], "=@+#<") + 1
+			if end == 0 {
+				end = len(item)
+			}
+			field := item[1:end]
+			item = item[end:]
+
+			var err error
+			switch sep {
+			case '=':
+				info.Cluster, err = strconv.Atoi(field)
+			case '@':
+				if !strings.Contains(field, ",") {
+					err = errors.New("missing y offset")
+				} else {
+					err = parsePositions(field, &pos.XOffset, &pos.YOffset)
+				}
+			case '+':
+				err = parsePositions(field, &pos.XAdvance, &pos.YAdvance)
+			case '#':
+				var mask uint64
+				mask, err = strconv.ParseUint(field, 16, 32)
+				info.Mask = GlyphMask(mask) & glyphFlagDefined
+			case '<':
+				if !strings.HasSuffix(field, ">") {
+					err = errors.New("missing closing >")
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("invalid serialized glyph field %q: %s", field, err)
+			}
+		}
+
+		infos = append(infos, info)
+		positions = append(positions, pos)
+	}
+
+	b.Info = append(b.Info, infos...)
+	b.Pos = append(b.Pos, positions...)
+	return nil
+}
+
+// parsePositions parses `x` or `x,y`.
+func parsePositions(s string, x, y *Position) error {
+	xs, ys, hasY := strings.Cut(s, ",")
+	v, err := strconv.ParseInt(xs, 10, 32)
+	if err != nil {
+		return err
+	}
+	*x = Position(v)
+	if hasY {
+		v, err = strconv.ParseInt(ys, 10, 32)
+		if err != nil {
+			return err
+		}
+		*y = Position(v)
+	}
+	return nil
+}
+
+// serializedGlyph is the JSON representation of a glyph
+type serializedGlyph struct {
+	G  json.RawMessage `json:"g"`
+	Cl int             `json:"cl"`
+	Dx Position        `json:"dx"`
+	Dy Position        `json:"dy"`
+	Ax Position        `json:"ax"`
+	Ay Position        `json:"ay"`
+	Fl GlyphMask       `json:"fl"`
+}
+
+func (b *Buffer) deserializeJSON(s string, font *Font) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	var glyphs []serializedGlyph
+	if err := json.Unmarshal([]byte(s), &glyphs); err != nil {
+		return fmt.Errorf("invalid serialized buffer: %s", err)
+	}
+
+	infos := make([]GlyphInfo, len(glyphs))
+	positions := make([]GlyphPosition, len(glyphs))
+	for i, glyph := range glyphs {
+		// the glyph is either a name or an index
+		var name string
+		if err := json.Unmarshal(glyph.G, &name); err != nil {
+			name = string(glyph.G)
+		}
+		gid, ok := font.glyphFromString(name)
+		if !ok {
+			return fmt.Errorf("invalid glyph %s", glyph.G)
+		}
+		infos[i] = GlyphInfo{Glyph: gid, Cluster: glyph.Cl, Mask: glyph.Fl & glyphFlagDefined}
+		positions[i] = GlyphPosition{XOffset: glyph.Dx, YOffset: glyph.Dy, XAdvance: glyph.Ax, YAdvance: glyph.Ay}
+	}
+
+	b.Info = append(b.Info, infos...)
+	b.Pos = append(b.Pos, positions...)
+	return nil
+}
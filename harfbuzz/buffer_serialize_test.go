@@ -0,0 +1,109 @@
+package harfbuzz
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ported from harfbuzz/test/api/test-buffer-serialize.c Copyright © 2021  Behdad Esfahbod
+
+func testSerializeBuffer() *Buffer {
+	return &Buffer{
+		Info: []GlyphInfo{
+			{Glyph: 1, Cluster: 0},
+			{Glyph: 4, Cluster: 0, Mask: GlyphUnsafeToBreak},
+			{Glyph: 2, Cluster: 2},
+		},
+		Pos: []GlyphPosition{
+			{XAdvance: 500},
+			{XOffset: -20, YOffset: 30, XAdvance: 0},
+			{XAdvance: 480, YAdvance: -10},
+		},
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	buf := testSerializeBuffer()
+	for _, test := range []struct {
+		format SerializeFormat
+		flags  SerializeFlags
+		exp    string
+	}{
+		{SerializeText, 0, "[1=0+500|4=0@-20,30+0|2=2+480,-10]"},
+		{SerializeText, SerializeNoClusters | SerializeGlyphFlags, "[1+500|4@-20,30+0#1|2+480,-10]"},
+		{SerializeText, SerializeNoAdvances, "[1=0|4=0@480,30|2=2@500,0]"},
+		{SerializeText, SerializeNoPositions, "[1=0|4=0|2=2]"},
+		{SerializeJSON, 0, `[{"g":1,"cl":0,"dx":0,"dy":0,"ax":500,"ay":0},{"g":4,"cl":0,"dx":-20,"dy":30,"ax":0,"ay":0},{"g":2,"cl":2,"dx":0,"dy":0,"ax":480,"ay":-10}]`},
+		{SerializeJSON, SerializeNoPositions | SerializeGlyphFlags, `[{"g":1,"cl":0},{"g":4,"cl":0,"fl":1},{"g":2,"cl":2}]`},
+	} {
+		if got := buf.Serialize(nil, test.format, test.flags); got != test.exp {
+			t.Fatalf("format %d, flags %d: expected\n%s, got\n%s", test.format, test.flags, test.exp, got)
+		}
+	}
+
+	if got := new(Buffer).Serialize(nil, SerializeJSON, 0); got != "" {
+		t.Fatalf("expected empty string, got %s", got)
+	}
+}
+
+func TestDeserialize(t *testing.T) {
+	exp := testSerializeBuffer()
+	for _, format := range []SerializeFormat{SerializeText, SerializeJSON} {
+		var got Buffer
+		if err := got.Deserialize(exp.Serialize(nil, format, SerializeGlyphFlags|SerializeGlyphExtents), nil, format); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(exp.Info, got.Info) || !reflect.DeepEqual(exp.Pos, got.Pos) {
+			t.Fatalf("format %d: expected %v %v, got %v %v", format, exp.Info, exp.Pos, got.Info, got.Pos)
+		}
+	}
+
+	for _, input := range []string{
+		"1=0+500",
+		"[a=0]",
+		"[1=x]",
+		"[1=0@2]",
+		"[1=0<1,2,3,4]",
+	} {
+		if err := new(Buffer).Deserialize(input, nil, SerializeText); err == nil {
+			t.Fatalf("expected error for %s", input)
+		}
+	}
+	if err := new(Buffer).Deserialize(`[{"g":"a"}]`, nil, SerializeJSON); err == nil {
+		t.Fatal("expected error for invalid glyph name")
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	font := NewFont(openFontFile("fonts/SourceSansVariable-Roman.anchor.ttf"))
+	buf := NewBuffer()
+	buf.AddRunes([]rune("Hello, world"), 0, -1)
+	buf.GuessSegmentProperties()
+	buf.Shape(font, nil)
+
+	for _, format := range []SerializeFormat{SerializeText, SerializeJSON} {
+		s := buf.Serialize(font, format, SerializeGlyphFlags)
+		var got Buffer
+		if err := got.Deserialize(s, font, format); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Info) != len(buf.Info) {
+			t.Fatalf("expected %d glyphs, got %d", len(buf.Info), len(got.Info))
+		}
+		for i, info := range got.Info {
+			if exp := buf.Info[i]; info.Glyph != exp.Glyph || info.Cluster != exp.Cluster || info.Mask != exp.Mask&glyphFlagDefined {
+				t.Fatalf("glyph %d: expected %v, got %v", i, exp, info)
+			}
+		}
+		for i, pos := range got.Pos {
+			if exp := buf.Pos[i]; pos.XAdvance != exp.XAdvance || pos.YAdvance != exp.YAdvance ||
+				pos.XOffset != exp.XOffset || pos.YOffset != exp.YOffset {
+				t.Fatalf("glyph %d: expected %v, got %v", i, exp, pos)
+			}
+		}
+		// the output is stable
+		if again := got.Serialize(font, format, SerializeGlyphFlags); again != s {
+			t.Fatalf("expected %s, got %s", s, again)
+		}
+	}
+}
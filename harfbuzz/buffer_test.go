@@ -207,6 +207,46 @@ func TestBuffer(t *testing.T) {
 	}
 }
 
+func TestBufferContext(t *testing.T) {
+	buffer := NewBuffer()
+	buffer.AddRunes([]rune("abc"), 0, -1)
+	buffer.SetPreContext([]rune("0123456"))
+	buffer.SetPostContext([]rune("6543210"))
+	if s := string(buffer.context[0]); s != "65432" {
+		t.Fatalf("unexpected pre-context %s", s)
+	}
+	if s := string(buffer.context[1]); s != "65432" {
+		t.Fatalf("unexpected post-context %s", s)
+	}
+
+	// a Beh with context on both sides is shaped in its medial form
+	font := NewFont(openFontFileTT("NotoSansArabic.ttf"))
+	text := []rune{0x0628, 0x0628, 0x0628}
+
+	ref := NewBuffer()
+	ref.AddRunes(text, 1, 1)
+	ref.GuessSegmentProperties()
+	ref.Shape(font, nil)
+
+	alone := NewBuffer()
+	alone.AddRune(text[1], 1)
+	alone.GuessSegmentProperties()
+	alone.Shape(font, nil)
+	if alone.Info[0].Glyph == ref.Info[0].Glyph {
+		t.Fatal("expected a different glyph without context")
+	}
+
+	buffer = NewBuffer()
+	buffer.AddRune(text[1], 1)
+	buffer.SetPreContext(text[:1])
+	buffer.SetPostContext(text[2:])
+	buffer.GuessSegmentProperties()
+	buffer.Shape(font, nil)
+	if diff := bufferDiff(buffer, ref, ^fonts.GID(0), 0); diff != bufferDiffFlagEqual {
+		t.Fatalf("unexpected shaping with context: %d", diff)
+	}
+}
+
 /*
  * Comparing buffers.
  */
@@ -0,0 +1,87 @@
+package harfbuzz
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// ported from src/hb-cache.hh Copyright © 2012  Google, Inc. Behdad Esfahbod
+
+// cacheBits is the log2 of the number of entries of a cache
+const cacheBits = 8
+
+// cache is a small, fixed size cache mapping uint32 keys to uint32 values,
+// where keys sharing the same lowest bits simply replace each other.
+// Each entry stores 1 + the key in its upper half, so that 0 is an empty
+// entry, and the value in its lower half.
+// The entries are accessed atomically, so that a cache may be
+// used concurrently.
+type cache [1 << cacheBits]uint64
+
+func (c *cache) get(key uint32) (uint32, bool) {
+	entry := atomic.LoadUint64(&c[key&(1<<cacheBits-1)])
+	if entry>>32 != uint64(key)+1 {
+		return 0, false
+	}
+	return uint32(entry), true
+}
+
+func (c *cache) set(key, value uint32) {
+	if key == math.MaxUint32 { // 1 + key would overflow
+		return
+	}
+	atomic.StoreUint64(&c[key&(1<<cacheBits-1)], (uint64(key)+1)<<32|uint64(value))
+}
+
+func (c *cache) clear() {
+	for i := range c {
+		atomic.StoreUint64(&c[i], 0)
+	}
+}
+
+// fontCaches speeds up the queries to the face done during shaping,
+// when the same glyphs are used many times.
+type fontCaches struct {
+	cmap                 cache // rune -> glyph, only for the runes supported by the font
+	hAdvances, vAdvances cache // glyph -> advance in font units, as float32 bits
+}
+
+func (fc *fontCaches) clear() {
+	fc.cmap.clear()
+	fc.hAdvances.clear()
+	fc.vAdvances.clear()
+}
+
+// getNominalGlyph is the same as `Face.NominalGlyph`, but uses the cache of the font.
+func (f *Font) getNominalGlyph(r rune) (fonts.GID, bool) {
+	if g, ok := f.caches.cmap.get(uint32(r)); ok {
+		return fonts.GID(g), true
+	}
+	g, ok := f.face.NominalGlyph(r)
+	if ok {
+		f.caches.cmap.set(uint32(r), uint32(g))
+	}
+	return g, ok
+}
+
+// horizontalAdvance is the same as `Face.HorizontalAdvance`, but uses the cache of the font.
+func (f *Font) horizontalAdvance(glyph fonts.GID) float32 {
+	if adv, ok := f.caches.hAdvances.get(uint32(glyph)); ok {
+		return math.Float32frombits(adv)
+	}
+	adv := f.face.HorizontalAdvance(glyph)
+	f.caches.hAdvances.set(uint32(glyph), math.Float32bits(adv))
+	return adv
+}
+
+// verticalAdvance is the same as `Face.VerticalAdvance`, but uses the cache of the font.
+func (f *Font) verticalAdvance(glyph fonts.GID) float32 {
+	if adv, ok := f.caches.vAdvances.get(uint32(glyph)); ok {
+		return math.Float32frombits(adv)
+	}
+	adv := f.face.VerticalAdvance(glyph)
+	f.caches.vAdvances.set(uint32(glyph), math.Float32bits(adv))
+	return adv
+}
@@ -0,0 +1,77 @@
+package harfbuzz
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+func TestCache(t *testing.T) {
+	var c cache
+	if _, ok := c.get(0); ok {
+		t.Fatal("expected empty cache")
+	}
+	c.set(0, 10)
+	c.set(1, 11)
+	if v, ok := c.get(0); !ok || v != 10 {
+		t.Fatalf("unexpected value %d %v", v, ok)
+	}
+	// same lowest bits
+	c.set(1<<cacheBits, 12)
+	if _, ok := c.get(0); ok {
+		t.Fatal("expected replaced entry")
+	}
+	if v, ok := c.get(1 << cacheBits); !ok || v != 12 {
+		t.Fatalf("unexpected value %d %v", v, ok)
+	}
+	if v, ok := c.get(1); !ok || v != 11 {
+		t.Fatalf("unexpected value %d %v", v, ok)
+	}
+	c.set(math.MaxUint32, 1)
+	if _, ok := c.get(math.MaxUint32); ok {
+		t.Fatal("unexpected cached key")
+	}
+	c.clear()
+	if _, ok := c.get(1); ok {
+		t.Fatal("expected empty cache")
+	}
+}
+
+func TestFontCaches(t *testing.T) {
+	face := openFontFile("perf_reference/fonts/Roboto-Regular.ttf")
+	font := NewFont(face)
+	for pass := 0; pass < 2; pass++ { // the second pass uses the cache
+		for r := rune(0); r < 0x300; r++ {
+			exp, expOk := face.NominalGlyph(r)
+			got, gotOk := font.getNominalGlyph(r)
+			if exp != got || expOk != gotOk {
+				t.Fatalf("rune %d: expected %d %v, got %d %v", r, exp, expOk, got, gotOk)
+			}
+		}
+		for g := fonts.GID(0); g < 600; g++ {
+			if exp, got := face.HorizontalAdvance(g), font.horizontalAdvance(g); exp != got {
+				t.Fatalf("glyph %d: expected advance %g, got %g", g, exp, got)
+			}
+			if exp, got := face.VerticalAdvance(g), font.verticalAdvance(g); exp != got {
+				t.Fatalf("glyph %d: expected advance %g, got %g", g, exp, got)
+			}
+		}
+	}
+}
+
+func TestFontCachesVariations(t *testing.T) {
+	face := openFontFile("harfbuzz_reference/text-rendering-tests/fonts/Selawik-variable.ttf")
+	font := NewFont(face)
+	glyph, _ := face.NominalGlyph('a')
+
+	regular := font.horizontalAdvance(glyph)
+	font.SetVarCoordsDesign([]float32{700})
+	bold := font.horizontalAdvance(glyph)
+	if bold == regular {
+		t.Fatalf("expected a different advance after changing the variations, got %g", bold)
+	}
+	if exp := face.HorizontalAdvance(glyph); bold != exp {
+		t.Fatalf("expected %g, got %g", exp, bold)
+	}
+}
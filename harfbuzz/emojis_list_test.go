@@ -1,4 +1,4 @@
-package harfbuzz
+package harfbuzz_test
 
 // Code generated by unicodedata/generate/main.go DO NOT EDIT.
 
@@ -1,4 +1,4 @@
-package harfbuzz
+package harfbuzz_test
 
 import (
 	"fmt"
@@ -15,6 +15,6 @@ func TestEmojisSequences(t *testing.T) {
 		clusters := strings.Repeat("|1=0", len(sequence))[1:]
 		test := fmt.Sprintf("fonts/AdobeBlank2.ttf;--no-glyph-names --no-positions;%s;[%s]", strings.Join(runes, ","), clusters)
 
-		parseAndRunTest(t, ".", test, runOneTest)
+		runTest(t, runner, ".", test)
 	}
 }
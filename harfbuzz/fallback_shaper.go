@@ -1,5 +1,9 @@
 package harfbuzz
 
+import (
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+)
+
 // ported from harfbuzz/src/hb-fallback-shape.cc Copyright © 2011  Google, Inc. Behdad Esfahbod
 
 var _ shaper = shaperFallback{}
@@ -13,8 +17,11 @@ func (shaperFallback) kind() shaperKind { return skFallback }
 func (shaperFallback) compile(props SegmentProperties, userFeatures []Feature) {
 }
 
-func (shaperFallback) shape(font *Font, buffer *Buffer, _ []Feature) {
-	space, hasSpace := font.face.NominalGlyph(' ')
+// The fallback shaper applies pair kerning when the face implements
+// truetype.SimpleKerns (like Type1 fonts with AFM metrics),
+// unless the 'kern' feature is disabled.
+func (shaperFallback) shape(font *Font, buffer *Buffer, features []Feature) {
+	space, hasSpace := font.getNominalGlyph(' ')
 
 	buffer.clearPositions()
 
@@ -27,7 +34,7 @@ func (shaperFallback) shape(font *Font, buffer *Buffer, _ []Feature) {
 			pos[i].XAdvance = 0
 			pos[i].YAdvance = 0
 		} else {
-			info[i].Glyph, _ = font.face.NominalGlyph(info[i].codepoint)
+			info[i].Glyph, _ = font.getNominalGlyph(info[i].codepoint)
 			pos[i].XAdvance, pos[i].YAdvance = font.GlyphAdvanceForDirection(info[i].Glyph, direction)
 			pos[i].XOffset, pos[i].YOffset = font.subtractGlyphOriginForDirection(info[i].Glyph, direction,
 				pos[i].XOffset, pos[i].YOffset)
@@ -38,5 +45,36 @@ func (shaperFallback) shape(font *Font, buffer *Buffer, _ []Feature) {
 		buffer.Reverse()
 	}
 
+	if kerns, ok := font.face.(tt.SimpleKerns); ok && direction.isHorizontal() && fallbackKerningEnabled(features) {
+		fallbackKern(kerns, font, buffer)
+	}
+
 	buffer.clearGlyphFlags(0)
 }
+
+// fallbackKerningEnabled returns false if the kerning
+// is disabled for the whole buffer
+func fallbackKerningEnabled(features []Feature) bool {
+	kernTag := tt.NewTag('k', 'e', 'r', 'n')
+	enabled := true
+	for _, feat := range features {
+		if feat.Tag == kernTag && feat.Start == FeatureGlobalStart && feat.End == FeatureGlobalEnd {
+			enabled = feat.Value != 0
+		}
+	}
+	return enabled
+}
+
+// fallbackKern applies the kerning between adjacent glyphs,
+// which are expected in visual order.
+func fallbackKern(kerns tt.SimpleKerns, font *Font, buffer *Buffer) {
+	info := buffer.Info
+	pos := buffer.Pos
+	for i := 0; i+1 < len(info); i++ {
+		rawKern := kerns.KernPair(info[i].Glyph, info[i+1].Glyph)
+		if rawKern == 0 {
+			continue
+		}
+		pos[i].XAdvance += font.emScaleX(rawKern)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/boxesandglue/textlayout/fonts"
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
 )
 
 // ported from harfbuzz/test/api/test-shape.c  Copyright © 2011  Google, Inc. Behdad Esfahbod
@@ -73,3 +74,35 @@ func TestShape(t *testing.T) {
 	font.XScale = 100
 	testFont(t, font)
 }
+
+type dummyFaceKern struct {
+	dummyFaceShape
+}
+
+func (dummyFaceKern) KernPair(left, right fonts.GID) int16 {
+	if left == 1 && right == 2 { // Te
+		return -20
+	}
+	return 0
+}
+
+func TestShapeFallbackKern(t *testing.T) {
+	font := NewFont(dummyFaceKern{dummyFaceShape{xScale: 100}})
+	font.XScale = 100
+
+	for _, test := range []struct {
+		features []Feature
+		expected []int
+	}{
+		{nil, []int{8, 6, 5, 10}},
+		{[]Feature{{Tag: tt.NewTag('k', 'e', 'r', 'n'), Value: 0, Start: FeatureGlobalStart, End: FeatureGlobalEnd}}, []int{10, 6, 5, 10}},
+	} {
+		buffer := NewBuffer()
+		buffer.Props.Direction = LeftToRight
+		buffer.AddRunes([]rune("TesT"), 0, 4)
+		buffer.Shape(font, test.features)
+		for i, pos := range buffer.Pos {
+			assertEqualInt(t, test.expected[i], int(pos.XAdvance))
+		}
+	}
+}
@@ -51,8 +51,8 @@ type FaceOpenType interface {
 // settings).
 //
 // Font are constructed with `NewFont` and adjusted by accessing the fields
-// XPpem, YPpem, Ptem, XScale, YScale and with the method `SetVarCoordsDesign` for
-// variable fonts.
+// XPpem, YPpem, Ptem, XScale, YScale, XEmbolden, YEmbolden, EmboldenInPlace, Slant
+// and with the method `SetVarCoordsDesign` for variable fonts.
 type Font struct {
 	face Face
 
@@ -64,6 +64,9 @@ type Font struct {
 	gsubAccels, gposAccels []otLayoutLookupAccelerator // accelerators for lookup
 	faceUpem               int32                       // cached value of Face.Upem()
 
+	// cached glyphs and advances, shared by the copies of the font
+	caches *fontCaches
+
 	// Point size of the font. Set to zero to unset.
 	// This is used in AAT layout, when applying 'trak' table.
 	Ptem float32
@@ -80,6 +83,25 @@ type Font struct {
 	// Is is used to select bitmap sizes and to perform some OpenType
 	// positioning.
 	XPpem, YPpem uint16
+
+	// Horizontal and vertical synthetic emboldening strengths, used to
+	// produce a faux-bold style when the bold face is missing.
+	// They are expressed as a fraction of the scale : 0.02 is a typical value.
+	// Set to zero to disable emboldening (default).
+	//
+	// The advances of the glyphs are increased by the strength, unless
+	// `EmboldenInPlace` is true. The extents and the outlines
+	// (see `GlyphOutline`) are also adjusted.
+	XEmbolden, YEmbolden float32
+	EmboldenInPlace      bool
+
+	// Synthetic slant ratio, used to produce a faux-italic (oblique) style
+	// when the italic face is missing : positive values slant to the right,
+	// and 0.2 is a typical value. Set to zero to disable slanting (default).
+	//
+	// Slanting only affects the offsets of the glyphs (when they are not zero),
+	// the extents and the outlines (see `GlyphOutline`).
+	Slant float32
 }
 
 // NewFont constructs a new font object from the specified face.
@@ -91,11 +113,14 @@ type Font struct {
 // required for OpenType and Graphite layout, which will influence
 // the shaping plan used in `Buffer.Shape`.
 //
-// The `face` object should not be modified after this call.
+// The `face` object should not be modified after this call, since the
+// glyphs and advances it provides are cached : use `SetVarCoordsDesign`
+// to change its variations.
 func NewFont(face Face) *Font {
 	var font Font
 
 	font.face = face
+	font.caches = new(fontCaches)
 	font.faceUpem = Position(font.face.Upem())
 	font.XScale = font.faceUpem
 	font.YScale = font.faceUpem
@@ -127,6 +152,7 @@ func NewFont(face Face) *Font {
 func (f *Font) SetVarCoordsDesign(coords []float32) {
 	if varFace, ok := f.face.(FaceOpenType); ok {
 		varFace.SetVarCoordinates(varFace.NormalizeVariations(coords))
+		f.caches.clear()
 	}
 }
 
@@ -136,7 +162,7 @@ func (f *Font) SetVarCoordsDesign(coords []float32) {
 func (f *Font) Face() fonts.Face { return f.face }
 
 func (f *Font) nominalGlyph(r rune, notFound fonts.GID) (fonts.GID, bool) {
-	g, ok := f.face.NominalGlyph(r)
+	g, ok := f.getNominalGlyph(r)
 	if !ok {
 		g = notFound
 	}
@@ -179,6 +205,7 @@ func (f *Font) GlyphExtents(glyph fonts.GID) (out GlyphExtents, ok bool) {
 	out.Width = f.emScalefX(ext.Width)
 	out.YBearing = f.emScalefY(ext.YBearing)
 	out.Height = f.emScalefY(ext.Height)
+	f.syntheticGlyphExtents(&out)
 	return out, true
 }
 
@@ -197,15 +224,27 @@ func (f *Font) GlyphAdvanceForDirection(glyph fonts.GID, dir Direction) (x, y Po
 // GlyphHAdvance fetches the advance for a glyph ID in the font,
 // for horizontal text segments.
 func (f *Font) GlyphHAdvance(glyph fonts.GID) Position {
-	adv := f.face.HorizontalAdvance(glyph)
-	return f.emScalefX(adv)
+	adv := f.emScalefX(f.horizontalAdvance(glyph))
+	if strength := f.xStrength(); strength != 0 && !f.EmboldenInPlace && adv != 0 {
+		if f.XScale < 0 {
+			strength = -strength
+		}
+		adv += strength
+	}
+	return adv
 }
 
 // Fetches the advance for a glyph ID in the font,
 // for vertical text segments.
 func (f *Font) getGlyphVAdvance(glyph fonts.GID) Position {
-	adv := f.face.VerticalAdvance(glyph)
-	return f.emScalefY(adv)
+	adv := f.emScalefY(f.verticalAdvance(glyph))
+	if strength := f.yStrength(); strength != 0 && !f.EmboldenInPlace && adv != 0 {
+		if f.YScale < 0 {
+			strength = -strength
+		}
+		adv -= strength // vertical advances are negative
+	}
+	return adv
 }
 
 // Subtracts the origin coordinates from an (X,Y) point coordinate,
@@ -271,7 +310,7 @@ func (f *Font) getHExtendsAscender() Position {
 }
 
 func (f *Font) hasGlyph(ch rune) bool {
-	_, ok := f.face.NominalGlyph(ch)
+	_, ok := f.getNominalGlyph(ch)
 	return ok
 }
 
@@ -83,10 +83,24 @@ const (
 	// This can be used to optimize paragraph layout, by avoiding re-shaping
 	// of each line after line-breaking, or limiting the reshaping to a small piece around the
 	// breaking point only.
+	// Note that this flag implies `GlyphUnsafeToConcat`.
 	GlyphUnsafeToBreak GlyphMask = 0x00000001
 
+	// Indicates that if input text is changed on one side of the beginning of the cluster
+	// this glyph is part of, then the shaping results for the other side might change.
+	// Note that the absence of this flag does NOT mean that the results are unchanged:
+	// it only means that the two sides may be shaped separately, and then concatenated,
+	// when the text is broken at this position, while re-shaping both sides after a
+	// change is required when the flag is present.
+	// This can be used to optimize paragraph layout, by shaping the paragraph once,
+	// and only re-shaping the text around line breaks which do not carry this flag.
+	//
+	// This flag is only computed if `ProduceUnsafeToConcat` is set in
+	// `Buffer.Flags`, since it has a (small) performance cost.
+	GlyphUnsafeToConcat GlyphMask = 0x00000002
+
 	// OR of all defined flags
-	glyphFlagDefined GlyphMask = GlyphUnsafeToBreak
+	glyphFlagDefined GlyphMask = GlyphUnsafeToBreak | GlyphUnsafeToConcat
 )
 
 // GlyphInfo holds information about the
@@ -185,11 +199,7 @@ func (info *GlyphInfo) setGeneralCategory(genCat generalCategory) {
 
 func (info *GlyphInfo) setCluster(cluster int, mask GlyphMask) {
 	if info.Cluster != cluster {
-		if mask&GlyphUnsafeToBreak != 0 {
-			info.Mask |= GlyphUnsafeToBreak
-		} else {
-			info.Mask &= ^GlyphUnsafeToBreak
-		}
+		info.Mask = (info.Mask &^ glyphFlagDefined) | (mask & glyphFlagDefined)
 	}
 	info.Cluster = cluster
 }
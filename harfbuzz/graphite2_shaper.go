@@ -211,5 +211,5 @@ func (sh *shaperGraphite) shape(font *Font, buffer *Buffer, features []Feature)
 		buffer.reverseClusters()
 	}
 
-	buffer.clearGlyphFlags(GlyphUnsafeToBreak)
+	buffer.clearGlyphFlags(GlyphUnsafeToBreak | GlyphUnsafeToConcat)
 }
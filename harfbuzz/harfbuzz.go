@@ -145,6 +145,10 @@ const (
 	// not be inserted in the rendering of incorrect
 	// character sequences (such at <0905 093E>).
 	DoNotinsertDottedCircle
+	// Flag indicating that the `GlyphUnsafeToConcat` glyph flag
+	// should be produced by the shaper. By default it will not
+	// be produced, since it incurs a cost.
+	ProduceUnsafeToConcat
 )
 
 // ClusterLevel allows selecting more fine-grained Cluster handling.
@@ -462,6 +466,20 @@ func max32(a, b uint32) uint32 {
 	return b
 }
 
+func minF(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxF(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func isAlpha(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
 func isAlnum(c byte) bool { return isAlpha(c) || (c >= '0' && c <= '9') }
 func toUpper(c byte) byte {
@@ -3,6 +3,7 @@ package harfbuzz
 import (
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/boxesandglue/textlayout/fonts"
 	tt "github.com/boxesandglue/textlayout/fonts/truetype"
@@ -150,13 +151,19 @@ type complexShaperArabic struct {
 	complexShaperNil
 
 	plan arabicShapePlan
+
+	// the fallback plan requires a font, so that it is lazily
+	// built by the first shaping call, and then reused,
+	// since the shape plans are cached for a given face
+	fallbackOnce sync.Once
+	fallbackPlan *arabicFallbackPlan
 }
 
-func (complexShaperArabic) marksBehavior() (zeroWidthMarks, bool) {
+func (*complexShaperArabic) marksBehavior() (zeroWidthMarks, bool) {
 	return zeroWidthMarksByGdefLate, true
 }
 
-func (complexShaperArabic) normalizationPreference() normalizationMode {
+func (*complexShaperArabic) normalizationPreference() normalizationMode {
 	return nmDefault
 }
 
@@ -230,7 +237,6 @@ func (cs *complexShaperArabic) collectFeatures(plan *otShapePlanner) {
 }
 
 type arabicShapePlan struct {
-	fallbackPlan *arabicFallbackPlan
 	/* The "+ 1" in the next array is to accommodate for the "NONE" command,
 	* which is not an OpenType feature, but this simplifies the code by not
 	* having to do a "if (... < NONE) ..." and just rely on the fact that
@@ -287,6 +293,12 @@ func arabicJoining(buffer *Buffer) {
 		if entry.prevAction != arabNone && prev != -1 {
 			info[prev].complexAux = entry.prevAction
 			buffer.unsafeToBreak(prev, i+1)
+		} else if prev == -1 {
+			if thisType >= joiningTypeR {
+				buffer.unsafeToConcatFromOutbuffer(0, i+1)
+			}
+		} else if thisType >= joiningTypeR || (2 <= state && state <= 5) { // states that have a possible prevAction
+			buffer.unsafeToConcat(prev, i+1)
 		}
 
 		info[i].complexAux = entry.currAction
@@ -337,19 +349,17 @@ func (cs *complexShaperArabic) setupMasks(plan *otShapePlan, buffer *Buffer, _ *
 }
 
 func arabicFallbackShape(plan *otShapePlan, font *Font, buffer *Buffer) {
-	arabicPlan := plan.shaper.(*complexShaperArabic).plan
+	cs := plan.shaper.(*complexShaperArabic)
 
-	if !arabicPlan.doFallback {
+	if !cs.plan.doFallback {
 		return
 	}
 
-	fallbackPlan := arabicPlan.fallbackPlan
-	if fallbackPlan == nil {
-		// this sucks. We need a font to build the fallback plan...
-		fallbackPlan = newArabicFallbackPlan(plan, font)
-	}
+	// the synthesized lookups only depend on the face, which
+	// is the same for all the uses of the plan
+	cs.fallbackOnce.Do(func() { cs.fallbackPlan = newArabicFallbackPlan(plan, font) })
 
-	fallbackPlan.shape(font, buffer)
+	cs.fallbackPlan.shape(font, buffer)
 }
 
 //  /*
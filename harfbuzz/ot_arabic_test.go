@@ -7,3 +7,33 @@ func TestNumArabicLookup(t *testing.T) {
 		t.Error()
 	}
 }
+
+func TestArabicFallbackPlan(t *testing.T) {
+	// this font has no GSUB table, but maps the presentation forms
+	font := NewFont(openFontFile("harfbuzz_reference/in-house/fonts/df768b9c257e0c9c35786c47cae15c46571d56be.ttf"))
+	shape := func() string {
+		buffer := NewBuffer()
+		buffer.AddRunes([]rune{0x0633, 0x0644, 0x0627, 0x0645, 0x062A, 0x06CC}, 0, -1)
+		buffer.GuessSegmentProperties()
+		buffer.Shape(font, nil)
+		return buffer.Serialize(font, SerializeText, SerializeNoPositions)
+	}
+
+	exp := "[uni06CC.fina=5|uni062A.medi=4|uni0645.init=3|uni06440627.fina=1|uni0633.init=0]"
+	if got := shape(); got != exp {
+		t.Fatalf("expected %s, got %s", exp, got)
+	}
+
+	buffer := NewBuffer()
+	buffer.AddRunes([]rune{0x0633}, 0, -1)
+	buffer.GuessSegmentProperties()
+	cs := NewShapePlan(font, buffer.Props, nil).shaper.(*shaperOpenType).plan.shaper.(*complexShaperArabic)
+	fallback := cs.fallbackPlan
+	if fallback == nil || fallback.numLookups == 0 {
+		t.Fatal("missing fallback plan")
+	}
+	// the fallback plan is reused
+	if got := shape(); got != exp || cs.fallbackPlan != fallback {
+		t.Fatalf("unexpected fallback plan or shaping %s", got)
+	}
+}
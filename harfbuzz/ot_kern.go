@@ -28,7 +28,9 @@ func kern(driver tt.SimpleKerns, crossStream bool, font *Font, buffer *Buffer, k
 		}
 
 		skippyIter.reset(idx, 1)
-		if !skippyIter.next() {
+		var unsafeTo int
+		if !skippyIter.next(&unsafeTo) {
+			buffer.unsafeToConcat(idx, unsafeTo)
 			idx++
 			continue
 		}
@@ -40,6 +42,7 @@ func kern(driver tt.SimpleKerns, crossStream bool, font *Font, buffer *Buffer, k
 		kern := Position(rawKern)
 
 		if rawKern == 0 {
+			buffer.unsafeToConcat(i, j+1)
 			goto skip
 		}
 
@@ -1,6 +1,8 @@
 package harfbuzz
 
 import (
+	"sort"
+
 	"github.com/boxesandglue/textlayout/fonts"
 	tt "github.com/boxesandglue/textlayout/fonts/truetype"
 )
@@ -212,6 +214,93 @@ func FindFeatureForLang(table *tt.TableLayout, scriptIndex, languageIndex int, f
 	return NoFeatureIndex
 }
 
+// ScriptTags returns the tags of the scripts in the given layout table,
+// in the order of the Scripts slice, so that the index of a tag is its script index.
+func ScriptTags(table *tt.TableLayout) []tt.Tag {
+	out := make([]tt.Tag, len(table.Scripts))
+	for i, s := range table.Scripts {
+		out[i] = s.Tag
+	}
+	return out
+}
+
+// LanguageTags returns the tags of the language systems of the script
+// at `scriptIndex` in the given layout table, so that the index of a tag is its language index.
+// The default language system, if any, has no tag and is not included.
+func LanguageTags(table *tt.TableLayout, scriptIndex int) []tt.Tag {
+	if scriptIndex == NoScriptIndex || scriptIndex >= len(table.Scripts) {
+		return nil
+	}
+	languages := table.Scripts[scriptIndex].Languages
+	out := make([]tt.Tag, len(languages))
+	for i, l := range languages {
+		out[i] = l.Tag
+	}
+	return out
+}
+
+// FeatureTags returns the tags of all the features in the given layout table,
+// in the order of the Features slice, so that the index of a tag is its feature index.
+// Note that a tag may appear several times, for instance when it has
+// different lookups for different scripts.
+func FeatureTags(table *tt.TableLayout) []tt.Tag {
+	out := make([]tt.Tag, len(table.Features))
+	for i, f := range table.Features {
+		out[i] = f.Tag
+	}
+	return out
+}
+
+// LanguageFeatureIndexes returns the indices of the features enabled by the language
+// system at `languageIndex` (which may be `DefaultLanguageIndex`), underneath the script at `scriptIndex`.
+// The required feature, if any, is not included.
+func LanguageFeatureIndexes(table *tt.TableLayout, scriptIndex, languageIndex int) []uint16 {
+	if scriptIndex == NoScriptIndex || scriptIndex >= len(table.Scripts) {
+		return nil
+	}
+	return table.Scripts[scriptIndex].GetLangSys(uint16(languageIndex)).Features
+}
+
+// LanguageFeatureTags is the same as `LanguageFeatureIndexes`, but returns the
+// tags of the features.
+func LanguageFeatureTags(table *tt.TableLayout, scriptIndex, languageIndex int) []tt.Tag {
+	indices := LanguageFeatureIndexes(table, scriptIndex, languageIndex)
+	out := make([]tt.Tag, 0, len(indices))
+	for _, index := range indices {
+		if int(index) < len(table.Features) { // index is not sanitized in tt.Parse
+			out = append(out, table.Features[index].Tag)
+		}
+	}
+	return out
+}
+
+// FeatureLookups returns the indices of the lookups (in the GSUB or GPOS lookup list)
+// of the feature at `featureIndex` in the given layout table, or nil if the feature is not found.
+func FeatureLookups(table *tt.TableLayout, featureIndex uint16) []uint16 {
+	if int(featureIndex) >= len(table.Features) {
+		return nil
+	}
+	return getFeatureLookupsWithVar(table, featureIndex, noVariationsIndex)
+}
+
+// CollectFeatureTags returns the sorted list of the feature tags found
+// in the GSUB and GPOS tables, without duplicates. It may be used
+// to present the features actually supported by a font.
+func CollectFeatureTags(tables *tt.LayoutTables) []tt.Tag {
+	seen := map[tt.Tag]bool{}
+	var out []tt.Tag
+	for _, table := range [2]*tt.TableLayout{&tables.GSUB.TableLayout, &tables.GPOS.TableLayout} {
+		for _, f := range table.Features {
+			if !seen[f.Tag] {
+				seen[f.Tag] = true
+				out = append(out, f.Tag)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
 // Fetches the tag of a requested feature index in the given layout table,
 // underneath the specified script and language. Returns -1 if no feature is requested.
 func getRequiredFeature(g *tt.TableLayout, scriptIndex, languageIndex int) (uint16, tt.Tag) {
@@ -228,7 +228,9 @@ func (it *skippingIterator) reject() {
 
 func (it *skippingIterator) maySkip(info *GlyphInfo) uint8 { return it.matcher.maySkip(it.c, info) }
 
-func (it *skippingIterator) next() bool {
+// next advances to the next matching glyph. If it fails and `unsafeTo` is not nil,
+// it is set to the end of the glyphs which were inspected.
+func (it *skippingIterator) next(unsafeTo *int) bool {
 	for it.idx+it.numItems < it.end {
 		it.idx++
 		info := &it.c.buffer.Info[it.idx]
@@ -248,13 +250,21 @@ func (it *skippingIterator) next() bool {
 		}
 
 		if skip == no {
+			if unsafeTo != nil {
+				*unsafeTo = it.idx + 1
+			}
 			return false
 		}
 	}
+	if unsafeTo != nil {
+		*unsafeTo = it.end
+	}
 	return false
 }
 
-func (it *skippingIterator) prev() bool {
+// prev moves back to the previous matching glyph. If it fails and `unsafeFrom` is not nil,
+// it is set to the start of the glyphs which were inspected.
+func (it *skippingIterator) prev(unsafeFrom *int) bool {
 	L := len(it.c.buffer.outInfo)
 	//    assert (num_items > 0);
 	for it.idx > it.numItems-1 {
@@ -283,9 +293,15 @@ func (it *skippingIterator) prev() bool {
 		}
 
 		if skip == no {
+			if unsafeFrom != nil {
+				*unsafeFrom = max(1, it.idx) - 1
+			}
 			return false
 		}
 	}
+	if unsafeFrom != nil {
+		*unsafeFrom = 0
+	}
 	return false
 }
 
@@ -402,8 +418,7 @@ func (c *otApplyContext) matchPropertiesMark(glyph fonts.GID, glyphProps uint16,
 	/* If using mark filtering sets, the high uint16 of
 	 * matchProps has the set index. */
 	if tt.LookupFlag(matchProps)&tt.UseMarkFilteringSet != 0 {
-		_, has := c.gdef.MarkGlyphSet[matchProps>>16].Index(glyph)
-		return has
+		return c.gdef.MarkSetCovers(uint16(matchProps>>16), glyph)
 	}
 
 	/* The second byte of matchProps has the meaning
@@ -486,6 +501,7 @@ func (c *otApplyContext) contextApplyLookup(input []uint16, lookupRecord []tt.Se
 	var matchPositions [maxContextLength]int
 	hasMatch, matchLength, _ := c.matchInput(input, lookupContext, &matchPositions)
 	if !hasMatch {
+		c.buffer.unsafeToConcat(c.buffer.idx, c.buffer.idx+matchLength)
 		return false
 	}
 	c.buffer.unsafeToBreak(c.buffer.idx, c.buffer.idx+matchLength)
@@ -505,13 +521,15 @@ func (c *otApplyContext) chainContextApplyLookup(backtrack, input, lookahead []u
 		return false
 	}
 
-	hasMatch, startIndex := c.matchBacktrack(backtrack, lookupContexts[0])
+	hasMatch, endIndex := c.matchLookahead(lookahead, lookupContexts[2], matchLength)
 	if !hasMatch {
+		c.buffer.unsafeToConcat(c.buffer.idx, endIndex)
 		return false
 	}
 
-	hasMatch, endIndex := c.matchLookahead(lookahead, lookupContexts[2], matchLength)
+	hasMatch, startIndex := c.matchBacktrack(backtrack, lookupContexts[0])
 	if !hasMatch {
+		c.buffer.unsafeToConcatFromOutbuffer(startIndex, endIndex)
 		return false
 	}
 
@@ -603,6 +621,8 @@ func (c *wouldApplyContext) wouldMatchInput(input []uint16, matchFunc matcherFun
 }
 
 // `input` starts with second glyph (`inputCount` = len(input)+1)
+// If the match fails, the returned length is still the end of the range
+// (relative to the current index) which must be marked unsafe to concat, or 0.
 func (c *otApplyContext) matchInput(input []uint16, matchFunc matcherFunc,
 	matchPositions *[maxContextLength]int) (bool, int, uint8) {
 	count := len(input) + 1
@@ -651,8 +671,9 @@ func (c *otApplyContext) matchInput(input []uint16, matchFunc matcherFunc,
 	ligbase := ligbaseNotChecked
 	matchPositions[0] = buffer.idx
 	for i := 1; i < count; i++ {
-		if !skippyIter.next() {
-			return false, 0, 0
+		var unsafeTo int
+		if !skippyIter.next(&unsafeTo) {
+			return false, unsafeTo - buffer.idx, 0
 		}
 
 		matchPositions[i] = skippyIter.idx
@@ -961,8 +982,9 @@ func (c *otApplyContext) matchBacktrack(backtrack []uint16, matchFunc matcherFun
 	skippyIter.setMatchFunc(matchFunc, backtrack)
 
 	for i := 0; i < len(backtrack); i++ {
-		if !skippyIter.prev() {
-			return false, 0
+		var unsafeFrom int
+		if !skippyIter.prev(&unsafeFrom) {
+			return false, unsafeFrom
 		}
 	}
 
@@ -975,8 +997,9 @@ func (c *otApplyContext) matchLookahead(lookahead []uint16, matchFunc matcherFun
 	skippyIter.setMatchFunc(matchFunc, lookahead)
 
 	for i := 0; i < len(lookahead); i++ {
-		if !skippyIter.next() {
-			return false, 0
+		var unsafeTo int
+		if !skippyIter.next(&unsafeTo) {
+			return false, unsafeTo
 		}
 	}
 
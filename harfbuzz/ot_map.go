@@ -343,11 +343,12 @@ func bsearchFeature(features []featureMap, tag tt.Tag) *featureMap {
 }
 
 type lookupMap struct {
-	index    uint16
-	autoZWNJ bool // = 1;
-	autoZWJ  bool // = 1;
-	random   bool // = 1;
-	mask     GlyphMask
+	index      uint16
+	autoZWNJ   bool // = 1;
+	autoZWJ    bool // = 1;
+	random     bool // = 1;
+	mask       GlyphMask
+	featureTag tt.Tag
 
 	// HB_INTERNAL static int cmp (const void *pa, const void *pb)
 	// {
@@ -425,13 +426,18 @@ func (m *otMap) getStageLookups(tableIndex, stage int) []lookupMap {
 func (m *otMap) addLookups(table *tt.TableLayout, tableIndex int, featureIndex uint16, variationsIndex int,
 	mask GlyphMask, autoZwnj, autoZwj, random bool) {
 	lookupIndices := getFeatureLookupsWithVar(table, featureIndex, variationsIndex)
+	var featureTag tt.Tag
+	if int(featureIndex) < len(table.Features) {
+		featureTag = table.Features[featureIndex].Tag
+	}
 	for _, lookupInd := range lookupIndices {
 		lookup := lookupMap{
-			mask:     mask,
-			index:    lookupInd,
-			autoZWNJ: autoZwnj,
-			autoZWJ:  autoZwj,
-			random:   random,
+			mask:       mask,
+			index:      lookupInd,
+			autoZWNJ:   autoZwnj,
+			autoZWJ:    autoZwj,
+			random:     random,
+			featureTag: featureTag,
 		}
 		m.lookups[tableIndex] = append(m.lookups[tableIndex], lookup)
 	}
@@ -444,7 +450,9 @@ func (m *otMap) substitute(plan *otShapePlan, font *Font, buffer *Buffer) {
 	}
 
 	proxy := otProxy{otProxyMeta: proxyGSUB, accels: font.gsubAccels}
+	m.traceTable(TraceStartTable, 0, font, buffer)
 	m.apply(proxy, plan, font, buffer)
+	m.traceTable(TraceEndTable, 0, font, buffer)
 
 	if debugMode >= 1 {
 		fmt.Println("SUBSTITUTE - end table GSUB")
@@ -458,7 +466,9 @@ func (m *otMap) position(plan *otShapePlan, font *Font, buffer *Buffer) {
 	}
 
 	proxy := otProxy{otProxyMeta: proxyGPOS, accels: font.gposAccels}
+	m.traceTable(TraceStartTable, 1, font, buffer)
 	m.apply(proxy, plan, font, buffer)
+	m.traceTable(TraceEndTable, 1, font, buffer)
 
 	if debugMode >= 1 {
 		fmt.Println("POSITION - end table GPOS")
@@ -478,12 +488,23 @@ func (m *otMap) apply(proxy otProxy, plan *otShapePlan, font *Font, buffer *Buff
 		}
 
 		for ; i < stage.lastLookup; i++ {
-			lookupIndex := m.lookups[tableIndex][i].index
+			lookup := m.lookups[tableIndex][i]
+			lookupIndex := lookup.index
 
 			if debugMode >= 1 {
 				fmt.Printf("\t\tLookup %d start\n", lookupIndex)
 			}
 
+			if buffer.tracing() {
+				// applying the lookup would be a no-op anyway
+				if reason := skipReason(buffer, lookup, &proxy.accels[lookupIndex]); reason != "" {
+					m.traceLookup(TraceSkipLookup, tableIndex, stageI, lookup, reason, font, buffer)
+					m.traceLookup(TraceEndLookup, tableIndex, stageI, lookup, "", font, buffer)
+					continue
+				}
+				m.traceLookup(TraceStartLookup, tableIndex, stageI, lookup, "", font, buffer)
+			}
+
 			c.lookupIndex = lookupIndex
 			c.setLookupMask(m.lookups[tableIndex][i].mask)
 			c.setAutoZWJ(m.lookups[tableIndex][i].autoZWJ)
@@ -501,6 +522,8 @@ func (m *otMap) apply(proxy otProxy, plan *otShapePlan, font *Font, buffer *Buff
 				fmt.Println(c.buffer.Info)
 			}
 
+			m.traceLookup(TraceEndLookup, tableIndex, stageI, lookup, "", font, buffer)
+
 		}
 
 		if stage.pauseFunc != nil {
@@ -508,6 +531,10 @@ func (m *otMap) apply(proxy otProxy, plan *otShapePlan, font *Font, buffer *Buff
 				fmt.Println("\t\tExecuting pause function")
 			}
 
+			if buffer.tracing() {
+				buffer.trace(font, TraceEvent{Kind: TracePause, Table: tableTags[tableIndex], Stage: stageI,
+					Message: fmt.Sprintf("calling the shaper function of stage %d", stageI)})
+			}
 			stage.pauseFunc(plan, font, buffer)
 		}
 	}
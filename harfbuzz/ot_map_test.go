@@ -1,9 +1,12 @@
 package harfbuzz
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 
 	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+	"github.com/boxesandglue/textlayout/language"
 )
 
 func TestOTFeature(t *testing.T) {
@@ -60,3 +63,90 @@ func TestOTFeature(t *testing.T) {
 	// g_assert_cmpuint (9, ==, text_size);
 	// g_assert_cmpstr (text, ==, "FontForge");
 }
+
+func TestVerticalFeatures(t *testing.T) {
+	vert, vrt2 := tt.NewTag('v', 'e', 'r', 't'), tt.NewTag('v', 'r', 't', '2')
+	props := SegmentProperties{Direction: TopToBottom, Script: language.Han}
+
+	// featureValue returns the global value of the last request for tag,
+	// which is the one kept when the map is compiled
+	featureValue := func(planner *otShapePlanner, tag tt.Tag) (uint32, bool) {
+		value, found := uint32(0), false
+		for _, info := range planner.map_.featureInfos {
+			if info.Tag == tag {
+				value, found = info.defaultValue, true
+			}
+		}
+		return value, found
+	}
+
+	var tables tt.LayoutTables
+	tables.GSUB.Features = []tt.FeatureRecord{{Tag: vert}, {Tag: vrt2}}
+
+	// 'vert' is always enabled, even if the font provides 'vrt2'
+	planner := newOtShapePlanner(&tables, props)
+	planner.collectFeatures(nil)
+	if v, ok := featureValue(planner, vert); !ok || v != 1 {
+		t.Fatal("expected 'vert' feature")
+	}
+	if _, ok := featureValue(planner, vrt2); ok {
+		t.Fatal("unexpected 'vrt2' feature")
+	}
+
+	// 'vrt2' is only used on request
+	planner = newOtShapePlanner(&tables, props)
+	planner.collectFeatures([]Feature{
+		{Tag: vert, Value: 0, Start: FeatureGlobalStart, End: FeatureGlobalEnd},
+		{Tag: vrt2, Value: 1, Start: FeatureGlobalStart, End: FeatureGlobalEnd},
+	})
+	if v, ok := featureValue(planner, vert); !ok || v != 0 {
+		t.Fatal("expected disabled 'vert' feature")
+	}
+	if v, ok := featureValue(planner, vrt2); !ok || v != 1 {
+		t.Fatal("expected 'vrt2' feature")
+	}
+}
+
+func TestLayoutIntrospection(t *testing.T) {
+	tables := openFontFile("perf_reference/fonts/Roboto-Regular.ttf").LayoutTables()
+	gsub := &tables.GSUB.TableLayout
+
+	scripts := ScriptTags(gsub)
+	if exp := []tt.Tag{
+		tt.NewTag('D', 'F', 'L', 'T'), tt.NewTag('c', 'y', 'r', 'l'),
+		tt.NewTag('g', 'r', 'e', 'k'), tt.NewTag('l', 'a', 't', 'n'),
+	}; !reflect.DeepEqual(scripts, exp) {
+		t.Fatalf("expected %v, got %v", exp, scripts)
+	}
+	if tags := LanguageTags(gsub, NoScriptIndex); tags != nil {
+		t.Fatalf("expected no languages, got %v", tags)
+	}
+
+	smcp := tt.NewTag('s', 'm', 'c', 'p')
+	features := LanguageFeatureTags(gsub, 0, DefaultLanguageIndex)
+	if len(features) != len(LanguageFeatureIndexes(gsub, 0, DefaultLanguageIndex)) {
+		t.Fatal("inconsistent feature tags and indices")
+	}
+	index := FindFeatureForLang(gsub, 0, DefaultLanguageIndex, smcp)
+	if index == NoFeatureIndex || FeatureTags(gsub)[index] != smcp {
+		t.Fatal("missing smcp feature")
+	}
+	if len(FeatureLookups(gsub, index)) == 0 {
+		t.Fatal("missing lookups for smcp")
+	}
+	if FeatureLookups(gsub, NoFeatureIndex) != nil {
+		t.Fatal("expected no lookups for an invalid feature")
+	}
+
+	all := CollectFeatureTags(&tables)
+	for i := 1; i < len(all); i++ {
+		if all[i-1] >= all[i] {
+			t.Fatalf("tags are not sorted and unique: %v", all)
+		}
+	}
+	for _, tag := range []tt.Tag{smcp, tt.NewTag('s', 's', '0', '1'), tt.NewTag('k', 'e', 'r', 'n')} {
+		if i := sort.Search(len(all), func(i int) bool { return all[i] >= tag }); i == len(all) || all[i] != tag {
+			t.Fatalf("missing feature %s in %v", tag, all)
+		}
+	}
+}
@@ -457,6 +457,17 @@ func (c *otContext) setupMasksFraction() {
 			for end < count && info[end].unicode.generalCategory() == decimalNumber {
 				end++
 			}
+			// only apply the features to complete fractions,
+			// with digits on both sides of the slash
+			if start == i || end == i+1 {
+				if start == i {
+					buffer.unsafeToConcat(start, start+1)
+				}
+				if end == i+1 {
+					buffer.unsafeToConcat(end-1, end)
+				}
+				continue
+			}
 
 			buffer.unsafeToBreak(start, end)
 
@@ -690,6 +701,15 @@ func (c *otContext) positionComplex() {
 	if c.plan.fallbackMarkPositioning {
 		fallbackMarkPosition(c.plan, c.font, c.buffer, adjustOffsetsWhenZeroing)
 	}
+
+	// the synthetic slant also applies to the vertical offsets
+	if slant := c.font.slantXY(); slant != 0 {
+		for i := range pos {
+			if pos[i].YOffset != 0 {
+				pos[i].XOffset += roundf(slant * float32(pos[i].YOffset))
+			}
+		}
+	}
 }
 
 func (c *otContext) position() {
@@ -711,7 +731,7 @@ func (c *otContext) position() {
 /* Propagate cluster-level glyph flags to be the same on all cluster glyphs.
  * Simplifies using them. */
 func propagateFlags(buffer *Buffer) {
-	if buffer.scratchFlags&bsfHasUnsafeToBreak == 0 {
+	if buffer.scratchFlags&bsfHasGlyphFlags == 0 {
 		return
 	}
 
@@ -719,12 +739,9 @@ func propagateFlags(buffer *Buffer) {
 
 	iter, count := buffer.clusterIterator()
 	for start, end := iter.next(); start < count; start, end = iter.next() {
-		var mask uint32
+		var mask GlyphMask
 		for i := start; i < end; i++ {
-			if info[i].Mask&GlyphUnsafeToBreak != 0 {
-				mask = GlyphUnsafeToBreak
-				break
-			}
+			mask |= info[i].Mask & glyphFlagDefined
 		}
 		if mask != 0 {
 			for i := start; i < end; i++ {
@@ -304,8 +304,11 @@ func parsePrivateUseSubtag(privateUseSubtag string, prefix string, normalize fun
 
 // NewOTTagsFromScriptAndLanguage converts an `language.Script` and an `Language`
 // to script and language tags.
+// The language is first canonicalized (see language.Language.Canonicalize), so that
+// deprecated subtags, like "iw" for Hebrew, are supported.
 func NewOTTagsFromScriptAndLanguage(script language.Script, language language.Language) (scriptTags, languageTags []tt.Tag) {
 	if language != "" {
+		language = language.Canonicalize()
 		langStr := languageToString(language)
 		limit := -1
 		privateUseSubtag := ""
@@ -1,6 +1,7 @@
 package harfbuzz
 
 import (
+	"reflect"
 	"testing"
 
 	tt "github.com/boxesandglue/textlayout/fonts/truetype"
@@ -473,3 +474,21 @@ func TestOtTagFromLanguage(t *testing.T) {
 		t.Fatalf("exected [lana], got %v", scs)
 	}
 }
+
+func TestOtTagDeprecatedLanguage(t *testing.T) {
+	// deprecated subtags are replaced by their preferred value
+	for _, test := range [][2]string{
+		{"iw", "he"},
+		{"in", "id"},
+		{"ji", "yi"},
+		{"i-klingon", "tlh"},
+		{"zh-yue", "yue"},
+	} {
+		_, deprecated := NewOTTagsFromScriptAndLanguage(0, language.NewLanguage(test[0]))
+		_, preferred := NewOTTagsFromScriptAndLanguage(0, language.NewLanguage(test[1]))
+		if len(preferred) == 0 || !reflect.DeepEqual(deprecated, preferred) {
+			t.Fatalf("unexpected tags for %s: %v (expected %v)", test[0], deprecated, preferred)
+		}
+	}
+	testTags(t, 0, "iw", 0, 1, "IWR ")
+}
@@ -0,0 +1,184 @@
+package harfbuzz
+
+import "sort"
+
+// Paragraph stores the result of shaping a whole paragraph, and
+// provides the shaping of its lines without re-shaping them from scratch.
+//
+// Line breaking (and justification) typically requires trying a lot of
+// break points, and thus shaping the same text many times.
+// Instead, a paragraph is shaped once, and the glyphs of a line are copied
+// from the paragraph, except around the line boundaries marked with the
+// `GlyphUnsafeToBreak` flag, where the text is shaped again.
+type Paragraph struct {
+	glyphs *Buffer // the shaped paragraph
+
+	text    []rune
+	context [2][]rune // context of the whole paragraph
+
+	font     *Font
+	features []Feature
+}
+
+// NewParagraph shapes the content of `buffer`, and stores
+// the result to provide the shaping of the lines of the paragraph (see `Line`).
+// `buffer` must have been filled with the whole text of the paragraph,
+// using `AddRunes` (so that the cluster of each rune is its index in the text),
+// and its properties (`Props`, `Flags`, `ClusterLevel`, etc...) must be set.
+// `buffer` is shaped in place and must not be modified afterwards.
+func NewParagraph(buffer *Buffer, font *Font, features []Feature) *Paragraph {
+	p := &Paragraph{font: font, features: features}
+
+	p.text = make([]rune, len(buffer.Info))
+	for i, info := range buffer.Info {
+		p.text[i] = info.codepoint
+	}
+	p.context[0] = append([]rune(nil), buffer.context[0]...)
+	p.context[1] = append([]rune(nil), buffer.context[1]...)
+
+	buffer.Shape(font, features)
+	p.glyphs = buffer
+	return p
+}
+
+// Glyphs returns the shaped paragraph, which must not be modified.
+func (p *Paragraph) Glyphs() *Buffer { return p.glyphs }
+
+// glyphIndex returns the index of the glyph where the text is split
+// at `index`, that is, the first glyph whose cluster is >= `index`
+// for forward directions, or < `index` for backward ones.
+// It assumes monotone clusters.
+func (p *Paragraph) glyphIndex(index int) int {
+	info := p.glyphs.Info
+	if p.glyphs.Props.Direction.isForward() {
+		return sort.Search(len(info), func(i int) bool { return info[i].Cluster >= index })
+	}
+	return sort.Search(len(info), func(i int) bool { return info[i].Cluster < index })
+}
+
+// IsSafeToBreak returns true if the paragraph may be broken before
+// the rune at `index` in the text, meaning that the shaping
+// of the text on each side of the break is given by the glyphs of the paragraph.
+// The start and the end of the text are always safe.
+func (p *Paragraph) IsSafeToBreak(index int) bool {
+	if index <= 0 || index >= len(p.text) {
+		return true
+	}
+	if p.glyphs.ClusterLevel == Characters {
+		// unsafe-to-break flags are meaningless without monotone clusters
+		return false
+	}
+
+	// the glyph starting the cluster at `index`, in logical order
+	g := p.glyphIndex(index)
+	if !p.glyphs.Props.Direction.isForward() {
+		g--
+	}
+	if g < 0 || g >= len(p.glyphs.Info) {
+		return false
+	}
+	info := p.glyphs.Info[g]
+	// `index` must start a cluster
+	return info.Cluster == index && info.Mask&GlyphUnsafeToBreak == 0
+}
+
+// Line returns the shaping of the runes text[start:end] of the paragraph,
+// with the rest of the paragraph as context, which is the same as shaping
+// them from scratch, but usually faster.
+// The clusters of the returned glyphs are the indices in the text of the paragraph.
+func (p *Paragraph) Line(start, end int) *Buffer {
+	if p.glyphs.ClusterLevel == Characters {
+		return p.shape(start, end)
+	}
+
+	// find the safe break points closest to the line boundaries:
+	// the glyphs between them are copied from the paragraph
+	safeStart := start
+	for safeStart < end && !p.IsSafeToBreak(safeStart) {
+		safeStart++
+	}
+	safeEnd := end
+	for safeEnd > start && !p.IsSafeToBreak(safeEnd) {
+		safeEnd--
+	}
+	if safeStart >= safeEnd {
+		return p.shape(start, end)
+	}
+
+	var head, tail *Buffer
+	if start < safeStart {
+		head = p.shape(start, safeStart)
+	}
+	if safeEnd < end {
+		tail = p.shape(safeEnd, end)
+	}
+
+	out := p.newBuffer()
+	glyphStart, glyphEnd := p.glyphIndex(safeStart), p.glyphIndex(safeEnd)
+	if !p.glyphs.Props.Direction.isForward() {
+		// glyphs are in visual order
+		glyphStart, glyphEnd = glyphEnd, glyphStart
+		head, tail = tail, head
+	}
+	if head != nil {
+		out.Info = append(out.Info, head.Info...)
+		out.Pos = append(out.Pos, head.Pos...)
+	}
+	out.Info = append(out.Info, p.glyphs.Info[glyphStart:glyphEnd]...)
+	out.Pos = append(out.Pos, p.glyphs.Pos[glyphStart:glyphEnd]...)
+	if tail != nil {
+		out.Info = append(out.Info, tail.Info...)
+		out.Pos = append(out.Pos, tail.Pos...)
+	}
+	return out
+}
+
+// newBuffer returns an empty buffer with the settings used for the paragraph.
+func (p *Paragraph) newBuffer() *Buffer {
+	out := NewBuffer()
+	out.Props = p.glyphs.Props
+	out.Flags = p.glyphs.Flags
+	out.ClusterLevel = p.glyphs.ClusterLevel
+	out.Invisible = p.glyphs.Invisible
+	out.NotFound = p.glyphs.NotFound
+	return out
+}
+
+// shape shapes text[start:end], with the rest of the paragraph as context.
+func (p *Paragraph) shape(start, end int) *Buffer {
+	buffer := p.newBuffer()
+	if start > 0 {
+		buffer.Flags &^= Bot
+	}
+	if end < len(p.text) {
+		buffer.Flags &^= Eot
+	}
+
+	for i, r := range p.text[start:end] {
+		buffer.append(r, start+i)
+	}
+
+	// pre-context, ordered outward
+	for i := start - 1; i >= 0 && len(buffer.context[0]) < contextLength; i-- {
+		buffer.context[0] = append(buffer.context[0], p.text[i])
+	}
+	for _, r := range p.context[0] {
+		if len(buffer.context[0]) >= contextLength {
+			break
+		}
+		buffer.context[0] = append(buffer.context[0], r)
+	}
+	// post-context
+	for i := end; i < len(p.text) && len(buffer.context[1]) < contextLength; i++ {
+		buffer.context[1] = append(buffer.context[1], p.text[i])
+	}
+	for _, r := range p.context[1] {
+		if len(buffer.context[1]) >= contextLength {
+			break
+		}
+		buffer.context[1] = append(buffer.context[1], r)
+	}
+
+	buffer.Shape(p.font, p.features)
+	return buffer
+}
@@ -0,0 +1,56 @@
+package harfbuzz
+
+import (
+	"testing"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+func TestParagraphLine(t *testing.T) {
+	for _, test := range []struct {
+		font string
+		text string
+	}{
+		{"perf_reference/fonts/Roboto-Regular.ttf", "Office affine: AVATAR, To Ty fi ffl."},
+		{"perf_reference/fonts/Amiri-Regular.ttf", "بِسْمِ اللّٰهِ الرَّحْمٰنِ لا للا"},
+		{"perf_reference/fonts/NotoSansDevanagari-Regular.ttf", "हिन्दी क्षत्रिय"},
+	} {
+		font := NewFont(openFontFile(test.font))
+		text := []rune(test.text)
+
+		buffer := NewBuffer()
+		buffer.AddRunes(text, 0, -1)
+		buffer.GuessSegmentProperties()
+		paragraph := NewParagraph(buffer, font, nil)
+
+		if diff := bufferDiff(paragraph.Line(0, len(text)), paragraph.Glyphs(), ^fonts.GID(0), 0); diff != bufferDiffFlagEqual {
+			t.Fatalf("%s: full line: unexpected diff %d", test.text, diff)
+		}
+
+		for start := 0; start < len(text); start++ {
+			for end := start + 1; end <= len(text); end++ {
+				got, exp := paragraph.Line(start, end), paragraph.shape(start, end)
+				if diff := bufferDiff(got, exp, ^fonts.GID(0), 0); diff != bufferDiffFlagEqual {
+					t.Fatalf("%s: line [%d, %d]: unexpected diff %d", test.text, start, end, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestParagraphIsSafeToBreak(t *testing.T) {
+	font := NewFont(openFontFile("perf_reference/fonts/Roboto-Regular.ttf"))
+	text := []rune("fi a")
+
+	buffer := NewBuffer()
+	buffer.AddRunes(text, 0, -1)
+	buffer.GuessSegmentProperties()
+	paragraph := NewParagraph(buffer, font, nil)
+
+	// the "fi" ligature may not be broken
+	for index, exp := range []bool{true, false, true, true, true} {
+		if got := paragraph.IsSafeToBreak(index); got != exp {
+			t.Fatalf("index %d: expected %v, got %v", index, exp, got)
+		}
+	}
+}
@@ -25,9 +25,27 @@ import (
 //
 // It also depends on the properties of the segment of text : the `Props`
 // field of the buffer must be set before calling `Shape`.
+//
+// The shaping plans are cached, so that repeated calls with the same face,
+// segment properties and features reuse the compiled lookups (see `NewShapePlan`).
 func (b *Buffer) Shape(font *Font, features []Feature) {
-	shapePlan := newShapePlanCached(font, b.Props, features, font.varCoords())
-	shapePlan.execute(font, b, features)
+	shapePlan := NewShapePlan(font, b.Props, features)
+	shapePlan.Execute(font, b, features)
+}
+
+// ShapeFull is the same as `Shape`, but only uses the shapers in `shapers`, tried
+// in order, among "graphite2", "ot" and "fallback" (see `ShapePlan.Shaper`).
+// By default (or if `shapers` is empty), Graphite is preferred for the faces
+// supporting it, then OpenType, and the fallback shaper is used for the other faces.
+// It returns false, leaving the buffer unchanged, if none of the given shapers
+// supports the face of `font`.
+func (b *Buffer) ShapeFull(font *Font, features []Feature, shapers []string) bool {
+	shapePlan := NewShapePlanFull(font, b.Props, features, shapers)
+	if shapePlan == nil {
+		return false
+	}
+	shapePlan.Execute(font, b, features)
+	return true
 }
 
 type shaperKind uint8
@@ -51,25 +69,29 @@ type shaper interface {
 	shape(*Font, *Buffer, []Feature)
 }
 
-// Shape plans are an internal mechanism. Each plan contains state
-// describing how HarfBuzz will shape a particular text segment, based on
-// the combination of segment properties and the capabilities in the
-// font face in use.
-//
-// Shape plans are not used for shaping directly, but can be queried to
-// access certain information about how shaping will perform, given a set
-// of specific input parameters (script, language, direction, features,
-// etc.).
+// ShapePlan contains the state describing how a particular text segment
+// is shaped, based on the combination of segment properties, user features
+// and the capabilities of the font face in use.
+// In particular, it stores the GSUB and GPOS lookups collected for
+// the requested script, language and features.
 //
-// Most client programs will not need to deal with shape plans directly.
-type shapePlan struct {
+// Most client programs will not need to deal with shape plans directly,
+// since `Buffer.Shape` uses the cached plans, but they may be stored
+// to avoid the cache lookup when shaping many segments with the same properties.
+// A plan is safe for concurrent use.
+type ShapePlan struct {
 	shaper       shaper
 	props        SegmentProperties
 	userFeatures []Feature
 }
 
-func (plan *shapePlan) init(copy bool, font *Font, props SegmentProperties,
-	userFeatures []Feature, coords []float32) {
+// defaultShapers is the order in which the shapers are tried
+var defaultShapers = [...]string{"graphite2", "ot", "fallback"}
+
+// init returns false if no shaper in `shapers` (or in `defaultShapers` if empty)
+// supports the font
+func (plan *ShapePlan) init(copy bool, font *Font, props SegmentProperties,
+	userFeatures []Feature, coords []float32, shapers []string) bool {
 	plan.props = props
 	if !copy {
 		plan.userFeatures = userFeatures
@@ -87,46 +109,57 @@ func (plan *shapePlan) init(copy bool, font *Font, props SegmentProperties,
 	}
 
 	// Choose shaper.
-	if font.gr != nil {
-		plan.shaper = (*shaperGraphite)(font.gr)
-	} else if font.otTables != nil {
-		plan.shaper = newShaperOpenType(font.otTables, coords)
-	} else {
-		plan.shaper = shaperFallback{}
+	if len(shapers) == 0 {
+		shapers = defaultShapers[:]
 	}
-}
-
-func (plan shapePlan) userFeaturesMatch(other shapePlan) bool {
-	if len(plan.userFeatures) != len(other.userFeatures) {
-		return false
-	}
-	for i, feat := range plan.userFeatures {
-		if feat.Tag != other.userFeatures[i].Tag || feat.Value != other.userFeatures[i].Value ||
-			(feat.Start == FeatureGlobalStart && feat.End == FeatureGlobalEnd) !=
-				(other.userFeatures[i].Start == FeatureGlobalStart && other.userFeatures[i].End == FeatureGlobalEnd) {
-			return false
+	for _, shaper := range shapers {
+		switch shaper {
+		case "graphite2":
+			if font.gr != nil {
+				plan.shaper = (*shaperGraphite)(font.gr)
+				return true
+			}
+		case "ot":
+			if font.otTables != nil {
+				plan.shaper = newShaperOpenType(font.otTables, coords)
+				return true
+			}
+		case "fallback":
+			plan.shaper = shaperFallback{}
+			return true
 		}
 	}
-	return true
+	return false
 }
 
-func (plan shapePlan) equal(other shapePlan) bool {
-	return plan.props == other.props &&
-		plan.userFeaturesMatch(other) && plan.shaper.kind() == other.shaper.kind()
+// Props returns the segment properties the plan was created for.
+func (plan *ShapePlan) Props() SegmentProperties { return plan.props }
+
+// Shaper returns the name of the shaper used by the plan:
+// "ot", "graphite2" or "fallback".
+func (plan *ShapePlan) Shaper() string {
+	switch plan.shaper.kind() {
+	case skOpenType:
+		return "ot"
+	case skGraphite:
+		return "graphite2"
+	default:
+		return "fallback"
+	}
 }
 
-// Constructs a shaping plan for a combination of @face, @userFeatures, @props,
-// plus the variation-space coordinates @coords.
-// See newShapePlanCached for caching support.
+// newShapePlan constructs a shaping plan for a combination of `font`, `userFeatures`, `props`,
+// plus the variation-space coordinates `coords`.
+// See NewShapePlan for caching support.
 func newShapePlan(font *Font, props SegmentProperties,
-	userFeatures []Feature, coords []float32) *shapePlan {
+	userFeatures []Feature, coords []float32, shapers []string) *ShapePlan {
 	if debugMode >= 1 {
 		fmt.Printf("NEW SHAPE PLAN: face:%p features:%v coords:%v\n", &font.face, userFeatures, coords)
 	}
 
-	var sp shapePlan
+	var sp ShapePlan
 
-	sp.init(true, font, props, userFeatures, coords)
+	sp.init(true, font, props, userFeatures, coords, shapers)
 
 	if debugMode >= 1 {
 		fmt.Println("NEW SHAPE PLAN - compiling shaper plan")
@@ -136,9 +169,11 @@ func newShapePlan(font *Font, props SegmentProperties,
 	return &sp
 }
 
-// Executes the given shaping plan on the specified `buffer`, using
-// the given `font` and `features`.
-func (sp *shapePlan) execute(font *Font, buffer *Buffer, features []Feature) {
+// Execute shapes `buffer` with the plan, using the given `font` and `features`,
+// which must be the ones used to create the plan.
+// Note that the `Props` of the buffer are ignored: the ones of the plan are used
+// to select the shaping behavior.
+func (sp *ShapePlan) Execute(font *Font, buffer *Buffer, features []Feature) {
 	if debugMode >= 1 {
 		fmt.Printf("EXECUTE shape plan %p features:%v shaper:%T\n", sp, features, sp.shaper)
 	}
@@ -150,40 +185,92 @@ func (sp *shapePlan) execute(font *Font, buffer *Buffer, features []Feature) {
  * Caching
  */
 
+// planCacheKey identifies the plans which may be shared:
+// only the global-ness of the user features matters, not their exact range.
+type planCacheKey struct {
+	face       Face
+	props      SegmentProperties
+	features   string // see featuresKey
+	kind       shaperKind
+	variations otShapePlanKey // only used by the OpenType shaper
+}
+
 var (
-	planCache     = map[Face][]*shapePlan{}
-	planCacheLock sync.Mutex
+	planCache     = map[planCacheKey]*ShapePlan{}
+	planCacheLock sync.RWMutex
 )
 
-// creates (or returns) a cached shaping plan suitable for reuse, for a combination
-// of `face`, `userFeatures`, `props`, plus the variation-space coordinates `coords`.
-func newShapePlanCached(font *Font, props SegmentProperties,
-	userFeatures []Feature, coords []float32) *shapePlan {
+// featuresKey returns a compact and comparable representation of `features`
+func featuresKey(features []Feature) string {
+	if len(features) == 0 {
+		return ""
+	}
+	key := make([]byte, 0, len(features)*9)
+	for _, feat := range features {
+		global := byte(0)
+		if feat.Start == FeatureGlobalStart && feat.End == FeatureGlobalEnd {
+			global = 1
+		}
+		key = append(key, byte(feat.Tag>>24), byte(feat.Tag>>16), byte(feat.Tag>>8), byte(feat.Tag),
+			byte(feat.Value>>24), byte(feat.Value>>16), byte(feat.Value>>8), byte(feat.Value), global)
+	}
+	return string(key)
+}
 
-	var key shapePlan
-	key.init(false, font, props, userFeatures, coords)
+// NewShapePlan creates (or returns) a cached shaping plan suitable for reuse,
+// for a combination of the face of `font`, its variation coordinates, `props` and `userFeatures`.
+// Plans are shared between goroutines, and are kept until `ClearShapePlanCache`
+// is called.
+func NewShapePlan(font *Font, props SegmentProperties, userFeatures []Feature) *ShapePlan {
+	return NewShapePlanFull(font, props, userFeatures, nil)
+}
 
-	planCacheLock.Lock()
-	defer planCacheLock.Unlock()
+// NewShapePlanFull is the same as `NewShapePlan`, but only uses the shapers in `shapers`,
+// tried in order (see `Buffer.ShapeFull`). It returns nil if none of them supports the
+// face of `font`.
+func NewShapePlanFull(font *Font, props SegmentProperties, userFeatures []Feature, shapers []string) *ShapePlan {
+	coords := font.varCoords()
 
-	plans := planCache[font.face]
+	var plan ShapePlan
+	if !plan.init(false, font, props, userFeatures, coords, shapers) {
+		return nil
+	}
+	key := planCacheKey{face: font.face, props: props, features: featuresKey(userFeatures), kind: plan.shaper.kind()}
+	if ot, ok := plan.shaper.(*shaperOpenType); ok {
+		key.variations = ot.key
+	}
 
-	for _, plan := range plans {
-		if plan.equal(key) {
-			if debugMode >= 1 {
-				fmt.Printf("\tPLAN %p fulfilled from cache\n", plan)
-			}
-			return plan
+	planCacheLock.RLock()
+	cached := planCache[key]
+	planCacheLock.RUnlock()
+	if cached != nil {
+		if debugMode >= 1 {
+			fmt.Printf("\tPLAN %p fulfilled from cache\n", cached)
 		}
+		return cached
 	}
-	plan := newShapePlan(font, props, userFeatures, coords)
 
-	plans = append(plans, plan)
-	planCache[font.face] = plans
+	// compile outside of the lock, so that other plans are not blocked
+	newPlan := newShapePlan(font, props, userFeatures, coords, shapers)
+
+	planCacheLock.Lock()
+	defer planCacheLock.Unlock()
+	if cached := planCache[key]; cached != nil { // concurrently inserted
+		return cached
+	}
+	planCache[key] = newPlan
 
 	if debugMode >= 1 {
-		fmt.Printf("\tPLAN %p inserted into cache\n", plan)
+		fmt.Printf("\tPLAN %p inserted into cache\n", newPlan)
 	}
 
-	return plan
+	return newPlan
+}
+
+// ClearShapePlanCache removes all the cached shaping plans,
+// releasing the references to the font faces they hold.
+func ClearShapePlanCache() {
+	planCacheLock.Lock()
+	defer planCacheLock.Unlock()
+	planCache = map[planCacheKey]*ShapePlan{}
 }
@@ -0,0 +1,143 @@
+package harfbuzz_test
+
+import (
+	"path"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+	"github.com/boxesandglue/textlayout/harfbuzz/shapingtest"
+)
+
+// run the test cases directly copied from harfbuzz/test/shaping
+
+var runner = shapingtest.Runner{FS: testdata.Files, Verify: true}
+
+// runTest parses and runs one test, given as a line of a .tests file
+// found in `dir`.
+func runTest(t *testing.T, runner shapingtest.Runner, dir, line string) {
+	test, err := shapingtest.ParseTest(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Run(test, dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// the FreeType and HarfBuzz implementations of the vertical origin differ,
+// and we match HarfBuzz : verticalOriginFixes maps the expected output of the
+// tests using FreeType to the output of HarfBuzz
+var verticalOriginFixes = map[string]string{
+	"[uni300C.vert=0@-512,-578+0,-1024]":                    "[uni300C.vert=0@-512,-189+0,-1024]",
+	"[gid1=0@-654,-2128+0,-2789|gid2=1@-665,-2125+0,-2789]": "[gid1=0@-654,-1468+0,-2048|gid2=1@-665,-1462+0,-2048]",
+}
+
+var disabledShapeTests = map[string]bool{
+	// requires proprietary fonts from the system (see the file)
+	"harfbuzz_reference/in-house/tests/macos.tests": true,
+
+	// need to check later on --pg
+	"harfbuzz_reference/in-house/tests/language-tags.tests":     true,
+	"harfbuzz_reference/text-rendering-tests/tests/CFF-1.tests": true,
+	"harfbuzz_reference/text-rendering-tests/tests/CFF-2.tests": true,
+
+	// already handled in emojis_test.go
+	"harfbuzz_reference/in-house/tests/emoji-clusters.tests": true,
+
+	// disabled by harfbuzz (see harfbuzz/test/shaping/data/text-rendering-tests/DISABLED)
+	"harfbuzz_reference/text-rendering-tests/tests/CMAP-3.tests":    true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHARAN-1.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHBALI-1.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHBALI-2.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHKNDA-2.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHKNDA-3.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-1.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-10.tests": true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-2.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-3.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-4.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-5.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-6.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-7.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-8.tests":  true,
+	"harfbuzz_reference/text-rendering-tests/tests/SHLANA-9.tests":  true,
+}
+
+// shapeTestFiles returns the test files of the HarfBuzz test suite,
+// except the disabled ones.
+func shapeTestFiles(t *testing.T) []string {
+	var out []string
+	for _, dir := range []string{
+		"harfbuzz_reference/aots/tests",
+		"harfbuzz_reference/in-house/tests",
+		"harfbuzz_reference/text-rendering-tests/tests",
+	} {
+		files, err := testdata.Files.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			if filename := path.Join(dir, file.Name()); !disabledShapeTests[filename] {
+				out = append(out, filename)
+			}
+		}
+	}
+	return out
+}
+
+func TestShapeExpected(t *testing.T) {
+	for _, filename := range shapeTestFiles(t) {
+		errs, err := runner.RunFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, err := range errs {
+			if f, ok := err.(*shapingtest.Failure); ok && verticalOriginFixes[f.Expected] == f.Got {
+				continue
+			}
+			t.Errorf("%s: %s", filename, err)
+		}
+	}
+}
+
+func TestDebug(t *testing.T) {
+	dir := "harfbuzz_reference/in-house"
+	testString := `fonts/2a670df15b73a5dc75a5cc491bde5ac93c5077dc.ttf;;U+11124,U+2060,U+11127;[u11124=0+514|uni25CC=1+547|u11127=1+0]`
+
+	// skip the verifications, to reduce stdout clutter when debugging
+	runTest(t, shapingtest.Runner{FS: testdata.Files}, dir, testString)
+}
+
+func TestGraphite(t *testing.T) {
+	// expected inputs are computed with the reference harfbuzz binary
+	testsGraphite := []string{
+		`fonts/Simple-Graphite-Font.ttf;;0x0061,0x0062,0x0063;[a=0+462|B=1+676|C=2+694]`,
+		`fonts/Simple-Graphite-Font.ttf;--direction=r;0x0061,0x0062,0x0063;[C=2+694|B=1+676|a=0+462]`,
+	}
+	for _, test := range testsGraphite {
+		runTest(t, runner, ".", test)
+	}
+}
+
+func TestAutomaticFractions(t *testing.T) {
+	dir := "harfbuzz_reference/in-house"
+	for _, test := range []string{
+		`fonts/15dfc433a135a658b9f4b1a861b5cdd9658ccbb9.ttf;;U+0031,U+0032,U+2044,U+0034;[one.numr=0+600|two.numr=1+600|fraction=2+252|four.small=3+600]`,
+		`fonts/15dfc433a135a658b9f4b1a861b5cdd9658ccbb9.ttf;;U+0031,U+0032,U+2044;[one=0+1090|two=1+1090|fraction=2+252]`,
+		`fonts/15dfc433a135a658b9f4b1a861b5cdd9658ccbb9.ttf;;U+2044,U+0034;[fraction=0+252|four=1+1090]`,
+	} {
+		runTest(t, runner, dir, test)
+	}
+}
+
+func TestFeatureRanges(t *testing.T) {
+	dir := "perf_reference"
+	for _, test := range []string{
+		`fonts/Roboto-Regular.ttf;--features="smcp" --no-positions --no-glyph-names;U+0061,U+0062,U+0063,U+0064;[1969=0|1968=1|1967=2|1966=3]`,
+		`fonts/Roboto-Regular.ttf;--features="smcp[1:3]" --no-positions --no-glyph-names;U+0061,U+0062,U+0063,U+0064;[70=0|1968=1|1967=2|73=3]`,
+		`fonts/Roboto-Regular.ttf;--features="smcp[2:]" --no-positions --no-glyph-names;U+0061,U+0062,U+0063,U+0064;[70=0|71=1|1967=2|1966=3]`,
+		`fonts/Roboto-Regular.ttf;--features="smcp[:1]" --no-positions --no-glyph-names;U+0061,U+0062,U+0063,U+0064;[1969=0|71=1|72=2|73=3]`,
+	} {
+		runTest(t, runner, dir, test)
+	}
+}
@@ -1,4 +1,4 @@
-package harfbuzz
+package harfbuzz_test
 
 // This file use a reference Harfbuzz binary to compare outputs and record fails
 
@@ -11,27 +11,41 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/boxesandglue/textlayout/fonts"
+	testdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+	"github.com/boxesandglue/textlayout/harfbuzz/shapingtest"
 )
 
 // use a reference library to extensively test the shaping process
 
 const referenceDir = "<XXX>/harfbuzz"
 
+type fontID struct {
+	file  string
+	index string // face index, as given in the options
+}
+
 type shapingInput struct {
-	font     fonts.FaceID
+	font     fontID
 	features string
 	text     []rune
 }
 
-func (sh shapingInput) testOptions() testOptions {
-	// var out testOptions
-	out, err := parseOptions("") // default values
-	check(err)
-	out.input.text = sh.text
-	out.shaper.features = sh.features
-	out.fontOpts.fontRef = sh.font
-	return out
+func (sh shapingInput) test() shapingtest.Test {
+	options := "--face-index=" + sh.font.index
+	if sh.features != "" {
+		options += " --features=" + sh.features
+	}
+	return shapingtest.Test{FontFile: sh.font.file, Options: options, Text: sh.text}
+}
+
+// optionValue returns the value of the `name` option, or an empty string
+func optionValue(options, name string) string {
+	for _, field := range strings.Fields(options) {
+		if strings.HasPrefix(field, "--"+name+"=") {
+			return strings.TrimPrefix(field, "--"+name+"=")
+		}
+	}
+	return ""
 }
 
 func formatRunes(runes []rune) string {
@@ -44,11 +58,11 @@ func formatRunes(runes []rune) string {
 
 // return stdout
 func referenceShaping(t *testing.T, input shapingInput) string {
-	fontFile, err := filepath.Abs(input.font.File)
+	fontFile, err := filepath.Abs(input.font.file)
 	if err != nil {
 		t.Fatal(err)
 	}
-	args := []string{fontFile, fmt.Sprintf("--face-index=%d", input.font.Index), "-u"}
+	args := []string{fontFile, "--face-index=" + input.font.index, "-u"}
 	args = append(args, formatRunes(input.text))
 	if input.features != "" {
 		args = append(args, "--features="+input.features)
@@ -68,20 +82,34 @@ type aggregatedInput struct {
 	features []string // all possibles features
 }
 
-func aggregateInputs(t *testing.T) map[fonts.FaceID]aggregatedInput {
-	out := make(map[fonts.FaceID]aggregatedInput)
+func aggregateInputs(t *testing.T) map[fontID]aggregatedInput {
+	out := make(map[fontID]aggregatedInput)
 
-	walkShapeTests(t, func(_ *testing.T, driver testOptions, _, _, glyphsExpected string) {
-		if glyphsExpected == "*" {
-			return
+	for _, filename := range shapeTestFiles(t) {
+		content, err := testdata.Files.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tests, err := shapingtest.ParseFile(content)
+		if err != nil {
+			t.Fatal(err)
 		}
-		l := out[driver.fontOpts.fontRef]
-		l.runes = append(l.runes, driver.input.text...)
-		if driver.shaper.features != "" {
-			l.features = append(l.features, driver.shaper.features)
+		for _, test := range tests {
+			if test.Expected == "*" {
+				continue
+			}
+			font := fontID{file: filepath.Join(filepath.Dir(filename), test.FontFile), index: optionValue(test.Options, "face-index")}
+			if font.index == "" {
+				font.index = "0"
+			}
+			l := out[font]
+			l.runes = append(l.runes, test.Text...)
+			if features := optionValue(test.Options, "features"); features != "" {
+				l.features = append(l.features, features)
+			}
+			out[font] = l
 		}
-		out[driver.fontOpts.fontRef] = l
-	})
+	}
 
 	return out
 }
@@ -96,7 +124,7 @@ func randText(possible []rune, maxSize int) []rune {
 	return out
 }
 
-func fuzzReferenceShaping(possibles map[fonts.FaceID]aggregatedInput, nbTry, maxInputSize int, t *testing.T) {
+func fuzzReferenceShaping(possibles map[fontID]aggregatedInput, nbTry, maxInputSize int, t *testing.T) {
 	var (
 		failures  []shapingInput
 		expecteds []string
@@ -115,7 +143,7 @@ func fuzzReferenceShaping(possibles map[fonts.FaceID]aggregatedInput, nbTry, max
 
 				// some tests font pass the verify
 				// since we compare to harfbuzz output it is redondant anyway
-				got, err := in.testOptions().shape(false)
+				got, err := shapingtest.Runner{FS: testdata.Files}.Shape(in.test(), ".")
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -140,7 +168,7 @@ func fuzzReferenceShaping(possibles map[fonts.FaceID]aggregatedInput, nbTry, max
 }
 
 // func TestReference(t *testing.T) {
-// 	out := referenceShaping(t, shapingInput{fonts.FaceID{"harfbuzz_reference/aots/fonts/gsub4_1_multiple_ligsets_f1.otf", 0}, "", []rune{21, 21, 22, 19}})
+// 	out := referenceShaping(t, shapingInput{fontID{"harfbuzz_reference/aots/fonts/gsub4_1_multiple_ligsets_f1.otf", "0"}, "", []rune{21, 21, 22, 19}})
 // 	fmt.Println(out)
 // }
 
@@ -0,0 +1,250 @@
+package shapingtest
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+	"github.com/boxesandglue/textlayout/harfbuzz"
+	"github.com/boxesandglue/textlayout/language"
+)
+
+type formatOptions struct {
+	hideGlyphNames bool
+	hidePositions  bool
+	hideAdvances   bool
+	hideClusters   bool
+	showExtents    bool
+	showFlags      bool
+}
+
+// flags returns the serialization flags matching the options
+func (opt formatOptions) flags() harfbuzz.SerializeFlags {
+	var flags harfbuzz.SerializeFlags
+	if opt.hideGlyphNames {
+		flags |= harfbuzz.SerializeNoGlyphNames
+	}
+	if opt.hidePositions {
+		flags |= harfbuzz.SerializeNoPositions
+	}
+	if opt.hideAdvances {
+		flags |= harfbuzz.SerializeNoAdvances
+	}
+	if opt.hideClusters {
+		flags |= harfbuzz.SerializeNoClusters
+	}
+	if opt.showExtents {
+		flags |= harfbuzz.SerializeGlyphExtents
+	}
+	if opt.showFlags {
+		flags |= harfbuzz.SerializeGlyphFlags
+	}
+	return flags
+}
+
+const fontSizeUpem = 0x7FFFFFFF
+
+type fontOptions struct {
+	shaper     string // only "fallback" is meaningful
+	faceIndex  int
+	variations []tt.Variation
+
+	sizeX, sizeY int
+	ptem         float64
+	xPpem, yPpem uint16
+}
+
+func (opts *fontOptions) parseVariations(s string) error {
+	// remove possible quote
+	s = strings.Trim(s, `"`)
+
+	variations := strings.Split(s, ",")
+	opts.variations = make([]tt.Variation, len(variations))
+
+	var err error
+	for i, variation := range variations {
+		opts.variations[i], err = harfbuzz.ParseVariation(variation)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (opts *fontOptions) parseFontSize(arg string) error {
+	if arg == "upem" {
+		opts.sizeX, opts.sizeY = fontSizeUpem, fontSizeUpem
+		return nil
+	}
+	n, err := fmt.Sscanf(arg, "%d %d", &opts.sizeX, &opts.sizeY)
+	if err != io.EOF {
+		return fmt.Errorf("font-size argument should be one or two space-separated numbers")
+	}
+	if n == 1 {
+		opts.sizeY = opts.sizeX
+	}
+	return nil
+}
+
+func (opts *fontOptions) parseFontPpem(arg string) error {
+	n, err := fmt.Sscanf(arg, "%d %d", &opts.xPpem, &opts.yPpem)
+	if err != io.EOF {
+		return fmt.Errorf("font-ppem argument should be one or two space-separated integers")
+	}
+	if n == 1 {
+		opts.yPpem = opts.xPpem
+	}
+	return nil
+}
+
+type shapeOptions struct {
+	features     []harfbuzz.Feature
+	props        harfbuzz.SegmentProperties
+	flags        harfbuzz.ShapingOptions
+	invisible    int
+	clusterLevel harfbuzz.ClusterLevel
+}
+
+func (opts *shapeOptions) parseFeatures(s string) error {
+	// remove possible quote
+	s = strings.Trim(s, `"`)
+	if s == "" {
+		return nil
+	}
+
+	features := strings.Split(s, ",")
+	opts.features = make([]harfbuzz.Feature, len(features))
+
+	var err error
+	for i, feature := range features {
+		opts.features[i], err = harfbuzz.ParseFeature(feature)
+		if err != nil {
+			return fmt.Errorf("parsing features %s: %s", s, err)
+		}
+	}
+	return nil
+}
+
+func (opts *shapeOptions) parseDirection(s string) error {
+	if s == "" {
+		return fmt.Errorf("invalid empty direction")
+	}
+	switch s[0] {
+	case 'l', 'L':
+		opts.props.Direction = harfbuzz.LeftToRight
+	case 'r', 'R':
+		opts.props.Direction = harfbuzz.RightToLeft
+	case 't', 'T':
+		opts.props.Direction = harfbuzz.TopToBottom
+	case 'b', 'B':
+		opts.props.Direction = harfbuzz.BottomToTop
+	default:
+		return fmt.Errorf("invalid direction %s", s)
+	}
+	return nil
+}
+
+type options struct {
+	font                  fontOptions
+	shape                 shapeOptions
+	format                formatOptions
+	textBefore, textAfter []rune
+}
+
+// parseOptions parses the options, written in command line format
+func parseOptions(s string) (options, error) {
+	flags := flag.NewFlagSet("options", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	var opts options
+
+	format := &opts.format
+	flags.BoolVar(&format.hideClusters, "no-clusters", false, "Do not output cluster indices")
+	flags.BoolVar(&format.hideGlyphNames, "no-glyph-names", false, "Output glyph indices instead of names")
+	flags.BoolVar(&format.hidePositions, "no-positions", false, "Do not output glyph positions")
+	flags.BoolVar(&format.hideAdvances, "no-advances", false, "Do not output glyph advances")
+	flags.BoolVar(&format.showExtents, "show-extents", false, "Output glyph extents")
+	flags.BoolVar(&format.showFlags, "show-flags", false, "Output glyph flags")
+	ned := flags.Bool("ned", false, "No Extra Data; Do not output clusters or advances")
+
+	shape := &opts.shape
+	flags.Func("features", "Comma-separated list of font features", shape.parseFeatures)
+	flags.StringVar(&opts.font.shaper, "shaper", "", "Force a shaper")
+	flags.String("shapers", "", "(ignored)")
+	flags.String("list-shapers", "", "(ignored)")
+	flags.Func("direction", "Set text direction (default: auto)", shape.parseDirection)
+	flags.Func("language", "Set text language (default: $LANG)", func(s string) error {
+		shape.props.Language = language.NewLanguage(s)
+		return nil
+	})
+	flags.Func("script", "Set text script, as an ISO-15924 tag (default: auto)", func(s string) error {
+		var err error
+		shape.props.Script, err = language.ParseScript(s)
+		return err
+	})
+	bot := flags.Bool("bot", false, "Treat text as beginning-of-paragraph")
+	eot := flags.Bool("eot", false, "Treat text as end-of-paragraph")
+	removeIgnorables := flags.Bool("remove-default-ignorables", false, "Remove Default-Ignorable characters")
+	preserveIgnorables := flags.Bool("preserve-default-ignorables", false, "Preserve Default-Ignorable characters")
+	flags.IntVar(&shape.invisible, "invisible-glyph", 0, "Glyph value to replace Default-Ignorables with")
+	flags.Func("cluster-level", "Cluster merging level (0/1/2, default: 0)", func(s string) error {
+		l, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid cluster-level option: %s", err)
+		}
+		if l < 0 || l > 2 {
+			return fmt.Errorf("invalid cluster-level option : %d", l)
+		}
+		shape.clusterLevel = harfbuzz.ClusterLevel(l)
+		return nil
+	})
+
+	font := &opts.font
+	font.sizeX, font.sizeY = fontSizeUpem, fontSizeUpem
+	flags.IntVar(&font.faceIndex, "face-index", 0, "Set face index (default: 0)")
+	flags.Func("font-size", "Font size", font.parseFontSize)
+	flags.Func("font-ppem", "Set x,y pixels per EM (default: 0; disabled)", font.parseFontPpem)
+	flags.Float64Var(&font.ptem, "font-ptem", 0, "Set font point-size (default: 0; disabled)")
+	flags.Func("variations", "Comma-separated list of font variations", font.parseVariations)
+	flags.String("font-funcs", "", "(ignored)")
+	flags.String("ft-load-flags", "", "(ignored)")
+
+	flags.Func("unicodes-before", "Set Unicode codepoints context before each line", func(s string) (err error) {
+		opts.textBefore, err = parseUnicodes(s)
+		return err
+	})
+	flags.Func("unicodes-after", "Set Unicode codepoints context after each line", func(s string) (err error) {
+		opts.textAfter, err = parseUnicodes(s)
+		return err
+	})
+
+	var args []string
+	if s = strings.TrimSpace(s); s != "" {
+		args = strings.Fields(s)
+	}
+	if err := flags.Parse(args); err != nil {
+		return options{}, fmt.Errorf("invalid options %s: %s", s, err)
+	}
+
+	if *ned {
+		format.hideClusters = true
+		format.hideAdvances = true
+	}
+	if *bot {
+		shape.flags |= harfbuzz.Bot
+	}
+	if *eot {
+		shape.flags |= harfbuzz.Eot
+	}
+	if *removeIgnorables {
+		shape.flags |= harfbuzz.RemoveDefaultIgnorables
+	}
+	if *preserveIgnorables {
+		shape.flags |= harfbuzz.PreserveDefaultIgnorables
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,241 @@
+// Package shapingtest runs the shaping test files of the HarfBuzz test suite
+// (harfbuzz/test/shaping/data), so that users of the harfbuzz package may check
+// their integration (fonts, options, build) against the upstream expectations.
+//
+// Each line of a test file is made of four fields separated by a semicolon :
+// the font file (optionally followed by @ and its SHA1 hash), the options
+// of the hb-shape tool, the input unicodes and the expected glyph stream, as
+// serialized by hb-shape. For instance :
+//
+//	../fonts/Roboto.ttf;--direction=l --features=-liga;U+0066,U+0069;[f=0+561|i=1+250]
+//
+// An expected output of "*" means the output is not checked.
+package shapingtest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+	"github.com/boxesandglue/textlayout/harfbuzz"
+)
+
+// ported from harfbuzz/util/hb-shape.cc, main-font-text.hh Copyright © 2010, 2011,2012  Google, Inc. Behdad Esfahbod
+
+// ErrInvalidFaceIndex is returned when the face index of a test
+// is out of range. HarfBuzz shapes with an empty font in this case,
+// which is not supported, so that such tests should be skipped.
+var ErrInvalidFaceIndex = errors.New("invalid face index")
+
+// Test is one line of a test file.
+type Test struct {
+	// FontFile is the path of the font file, relative to the
+	// directory of the test file.
+	FontFile string
+	// FontHash is the optional SHA1 hash of the font file, in hexadecimal.
+	FontHash string
+	// Options are the command line options of hb-shape.
+	Options string
+	// Text is the input to shape.
+	Text []rune
+	// Expected is the expected serialized output, or "*".
+	Expected string
+}
+
+// ParseTest parses one line of a test file.
+func ParseTest(line string) (Test, error) {
+	chunks := strings.Split(line, ";")
+	if L := len(chunks); L != 4 {
+		return Test{}, fmt.Errorf("invalid test line %s : %d chunks", line, L)
+	}
+	var out Test
+	fontFile := chunks[0]
+	if i := strings.IndexByte(fontFile, '@'); i != -1 {
+		fontFile, out.FontHash = fontFile[:i], fontFile[i+1:]
+	}
+	out.FontFile = fontFile
+	out.Options = chunks[1]
+	out.Expected = strings.TrimSpace(chunks[3])
+
+	var err error
+	out.Text, err = parseUnicodes(chunks[2])
+	if err != nil {
+		return Test{}, fmt.Errorf("invalid test line %s : %s", line, err)
+	}
+	return out, nil
+}
+
+// ParseFile parses the content of a test file, skipping empty lines and comments.
+func ParseFile(content []byte) ([]Test, error) {
+	var out []Test
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		test, err := ParseTest(strings.TrimSpace(line))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+		out = append(out, test)
+	}
+	return out, nil
+}
+
+func parseUnicodes(s string) ([]rune, error) {
+	runes := strings.Split(s, ",")
+	text := make([]rune, len(runes))
+	for i, r := range runes {
+		if _, err := fmt.Sscanf(r, "U+%x", &text[i]); err == nil {
+			continue
+		}
+		if _, err := fmt.Sscanf(r, "0x%x", &text[i]); err == nil {
+			continue
+		}
+		if _, err := fmt.Sscanf(r, "%x", &text[i]); err == nil {
+			continue
+		}
+		return text, fmt.Errorf("invalid unicode rune : %s", r)
+	}
+	return text, nil
+}
+
+// Failure is the error returned when the output of a test
+// does not match the expected one.
+type Failure struct {
+	Test     Test
+	Expected string
+	Got      string
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("for font %s and options %s, expected :\n%s\ngot :\n%s", f.Test.FontFile, f.Test.Options, f.Expected, f.Got)
+}
+
+// Runner shapes the tests, reading the test and font files from
+// a file system.
+type Runner struct {
+	// FS is the file system storing the test files and the fonts.
+	FS fs.FS
+
+	// Verify enables additional checks of the shaping output, as done by
+	// the HarfBuzz test suite : monotone clusters, and consistency
+	// of the unsafe-to-break and unsafe-to-concat flags.
+	Verify bool
+}
+
+// Shape shapes `test` and returns the serialized output.
+// `dir` is the directory of the test file, used to resolve the font file.
+func (r Runner) Shape(test Test, dir string) (string, error) {
+	opts, err := parseOptions(test.Options)
+	if err != nil {
+		return "", err
+	}
+	font, err := r.loadFont(path.Join(dir, test.FontFile), test.FontHash, opts.font)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := opts.shape.newBuffer(test.Text, opts.textBefore, opts.textAfter, 0, len(test.Text))
+	buffer.Shape(font, opts.shape.features)
+
+	if r.Verify && test.Expected != "*" {
+		if err := opts.shape.verify(buffer, test.Text, opts.textBefore, opts.textAfter, font); err != nil {
+			return "", err
+		}
+	}
+
+	return buffer.Serialize(font, harfbuzz.SerializeText, opts.format.flags()), nil
+}
+
+// Run shapes `test` and compares the result with the expected output,
+// returning a *Failure if they differ.
+// `dir` is the directory of the test file, used to resolve the font file.
+func (r Runner) Run(test Test, dir string) error {
+	got, err := r.Shape(test, dir)
+	if err != nil {
+		return err
+	}
+	if got = strings.TrimSpace(got); test.Expected != "*" && got != test.Expected {
+		return &Failure{Test: test, Expected: test.Expected, Got: got}
+	}
+	return nil
+}
+
+// RunFile runs all the tests of the test file at `filename`, and returns
+// the errors encountered (nil if all tests passed).
+// Tests with an invalid face index are skipped.
+func (r Runner) RunFile(filename string) ([]error, error) {
+	content, err := fs.ReadFile(r.FS, filename)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := ParseFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid test file %s: %s", filename, err)
+	}
+	var errs []error
+	dir := path.Dir(filename)
+	for _, test := range tests {
+		err := r.Run(test, dir)
+		if err == nil || errors.Is(err, ErrInvalidFaceIndex) {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	return errs, nil
+}
+
+func (r Runner) loadFont(filename, hash string, opts fontOptions) (*harfbuzz.Font, error) {
+	content, err := fs.ReadFile(r.FS, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if hash != "" {
+		sum := sha1.Sum(content)
+		if got := hex.EncodeToString(sum[:]); got != hash {
+			return nil, fmt.Errorf("invalid font file (%s) hash: expected %s, got %s", filename, hash, got)
+		}
+	}
+
+	faces, err := tt.Load(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if opts.faceIndex >= len(faces) {
+		return nil, fmt.Errorf("%w %d for font %s", ErrInvalidFaceIndex, opts.faceIndex, filename)
+	}
+	face := faces[opts.faceIndex]
+
+	if ft, ok := face.(harfbuzz.FaceOpenType); ok {
+		tt.SetVariations(ft, opts.variations)
+	}
+
+	if opts.shaper == "fallback" {
+		// hide the OpenType capabilities of the face
+		face = struct{ fonts.Face }{face}
+	}
+
+	font := harfbuzz.NewFont(face)
+
+	upem := int(face.Upem())
+	if opts.sizeX == fontSizeUpem {
+		opts.sizeX = upem
+	}
+	if opts.sizeY == fontSizeUpem {
+		opts.sizeY = upem
+	}
+	font.XScale = int32(opts.sizeX)
+	font.YScale = int32(opts.sizeY)
+	font.XPpem, font.YPpem = opts.xPpem, opts.yPpem
+	font.Ptem = float32(opts.ptem)
+
+	return font, nil
+}
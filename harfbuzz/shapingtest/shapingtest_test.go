@@ -0,0 +1,61 @@
+package shapingtest
+
+import (
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+)
+
+func TestParseTest(t *testing.T) {
+	test, err := ParseTest("../fonts/a.ttf@0123;--direction=r --features=-liga;U+0628,0x0644,627;[a=0+10]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.FontFile != "../fonts/a.ttf" || test.FontHash != "0123" || test.Options != "--direction=r --features=-liga" {
+		t.Fatalf("unexpected test %v", test)
+	}
+	if string(test.Text) != "بلا" || test.Expected != "[a=0+10]" {
+		t.Fatalf("unexpected test %v", test)
+	}
+
+	for _, line := range []string{
+		"a.ttf;;U+0628",
+		"a.ttf;;U+XYZ;[]",
+	} {
+		if _, err := ParseTest(line); err == nil {
+			t.Fatalf("expected error for %s", line)
+		}
+	}
+
+	if _, err := parseOptions("--unknown-option"); err == nil {
+		t.Fatal("expected error for invalid option")
+	}
+}
+
+func TestRunner(t *testing.T) {
+	runner := Runner{FS: testdata.Files, Verify: true}
+	dir := "harfbuzz_reference/in-house/tests"
+
+	test, err := ParseTest("../fonts/e68a88939e0f06e34d2bc911f09b70890289c8fd.ttf;;U+0041;[gid1=0+1000]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := runner.Shape(test, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Expected = got
+	if err := runner.Run(test, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	test.Expected = "[invalid]"
+	if _, ok := runner.Run(test, dir).(*Failure); !ok {
+		t.Fatal("expected failure")
+	}
+
+	test.FontHash = "invalid"
+	if err := runner.Run(test, dir); err == nil {
+		t.Fatal("expected error for invalid hash")
+	}
+}
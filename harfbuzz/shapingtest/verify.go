@@ -0,0 +1,158 @@
+package shapingtest
+
+import (
+	"fmt"
+
+	"github.com/boxesandglue/textlayout/fonts"
+	"github.com/boxesandglue/textlayout/harfbuzz"
+)
+
+// newBuffer returns a buffer filled with text[start:end], using the rest
+// of the text, preceded by `before` and followed by `after`, as context.
+// The clusters are the indices in `text`.
+func (opts shapeOptions) newBuffer(text, before, after []rune, start, end int) *harfbuzz.Buffer {
+	buffer := harfbuzz.NewBuffer()
+	buffer.AddRunes(text, start, end-start)
+	buffer.SetPreContext(append(append([]rune(nil), before...), text[:start]...))
+	buffer.SetPostContext(append(append([]rune(nil), text[end:]...), after...))
+
+	buffer.Props = opts.props
+	buffer.Flags = opts.flags
+	if start > 0 {
+		buffer.Flags &^= harfbuzz.Bot
+	}
+	if end < len(text) {
+		buffer.Flags &^= harfbuzz.Eot
+	}
+	buffer.Invisible = fonts.GID(opts.invisible)
+	buffer.ClusterLevel = opts.clusterLevel
+	buffer.GuessSegmentProperties()
+	return buffer
+}
+
+func (opts shapeOptions) hasMonotoneClusters() bool {
+	return opts.clusterLevel == harfbuzz.MonotoneGraphemes || opts.clusterLevel == harfbuzz.MonotoneCharacters
+}
+
+func isForward(dir harfbuzz.Direction) bool {
+	return dir == harfbuzz.LeftToRight || dir == harfbuzz.TopToBottom
+}
+
+// serialize returns a representation of the glyphs used to compare buffers
+func serialize(buffer *harfbuzz.Buffer, font *harfbuzz.Font) string {
+	return buffer.Serialize(font, harfbuzz.SerializeText, harfbuzz.SerializeNoGlyphNames)
+}
+
+// verify performs the additional checks of the HarfBuzz test suite on the
+// shaped `buffer`, whose input is `text`.
+func (opts shapeOptions) verify(buffer *harfbuzz.Buffer, text, before, after []rune, font *harfbuzz.Font) error {
+	if err := opts.verifyMonotone(buffer); err != nil {
+		return err
+	}
+	if err := opts.verifySafeToBreak(buffer, text, before, after, font); err != nil {
+		return err
+	}
+	return opts.verifyUnsafeToConcat(buffer, text, before, after, font)
+}
+
+// verifyMonotone checks that clusters are monotone.
+func (opts shapeOptions) verifyMonotone(buffer *harfbuzz.Buffer) error {
+	if !opts.hasMonotoneClusters() {
+		return nil
+	}
+	forward := isForward(buffer.Props.Direction)
+	info := buffer.Info
+	for i := 1; i < len(info); i++ {
+		if info[i-1].Cluster != info[i].Cluster && (info[i-1].Cluster < info[i].Cluster) != forward {
+			return fmt.Errorf("cluster at index %d is not monotone", i)
+		}
+	}
+	return nil
+}
+
+// verifySafeToBreak checks that shaping the text split at the
+// safe-to-break positions gives the same result.
+func (opts shapeOptions) verifySafeToBreak(buffer *harfbuzz.Buffer, text, before, after []rune, font *harfbuzz.Font) error {
+	if !opts.hasMonotoneClusters() {
+		// Cannot perform this check without monotone clusters.
+		return nil
+	}
+
+	reconstruction := harfbuzz.NewBuffer()
+	reconstruction.Props = buffer.Props
+
+	info := buffer.Info
+	forward := isForward(buffer.Props.Direction)
+	textStart, textEnd := len(text), len(text)
+	if forward {
+		textStart, textEnd = 0, 0
+	}
+	for end := 1; end <= len(info); end++ {
+		offset := 1
+		if forward {
+			offset = 0
+		}
+		if end < len(info) && (info[end].Cluster == info[end-1].Cluster ||
+			info[end-offset].Mask&harfbuzz.GlyphUnsafeToBreak != 0) {
+			continue
+		}
+
+		// shape the text corresponding to the glyphs up to end
+		if end == len(info) {
+			if forward {
+				textEnd = len(text)
+			} else {
+				textStart = 0
+			}
+		} else {
+			if forward {
+				textEnd = info[end].Cluster
+			} else {
+				textStart = info[end-1].Cluster
+			}
+		}
+		if textStart >= textEnd {
+			return fmt.Errorf("safe-to-break test failed: invalid text range %d >= %d", textStart, textEnd)
+		}
+
+		fragment := opts.newBuffer(text, before, after, textStart, textEnd)
+		fragment.Props = buffer.Props
+		fragment.Shape(font, opts.features)
+		reconstruction.Info = append(reconstruction.Info, fragment.Info...)
+		reconstruction.Pos = append(reconstruction.Pos, fragment.Pos...)
+
+		if forward {
+			textStart = textEnd
+		} else {
+			textEnd = textStart
+		}
+	}
+
+	if exp, got := serialize(buffer, font), serialize(reconstruction, font); exp != got {
+		return fmt.Errorf("safe-to-break test failed: expected %s, got %s", exp, got)
+	}
+	return nil
+}
+
+// verifyUnsafeToConcat checks that asking for the unsafe-to-concat flag does not
+// change the shaping result, and that unsafe-to-break glyphs are also unsafe-to-concat.
+func (opts shapeOptions) verifyUnsafeToConcat(buffer *harfbuzz.Buffer, text, before, after []rune, font *harfbuzz.Font) error {
+	if opts.flags&harfbuzz.ProduceUnsafeToConcat != 0 {
+		return nil
+	}
+
+	concat := opts.newBuffer(text, before, after, 0, len(text))
+	concat.Props = buffer.Props
+	concat.Flags |= harfbuzz.ProduceUnsafeToConcat
+	concat.Shape(font, opts.features)
+
+	if exp, got := serialize(buffer, font), serialize(concat, font); exp != got {
+		return fmt.Errorf("unsafe-to-concat test failed: expected %s, got %s", exp, got)
+	}
+	for i, info := range concat.Info {
+		if info.Mask&harfbuzz.GlyphUnsafeToBreak != 0 && info.Mask&harfbuzz.GlyphUnsafeToConcat == 0 {
+			return fmt.Errorf("glyph %d is unsafe to break but not unsafe to concat", i)
+		}
+	}
+	return nil
+}
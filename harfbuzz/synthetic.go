@@ -0,0 +1,272 @@
+package harfbuzz
+
+import (
+	"math"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+// ported from harfbuzz/src/hb-font.hh, hb-font.cc, hb-outline.cc Copyright © 2023  Behdad Esfahbod
+// The emboldening algorithm is itself a port of FreeType's FT_Outline_EmboldenXY.
+
+// xStrength returns the horizontal emboldening strength, in scaled units.
+func (f *Font) xStrength() Position {
+	return roundf(float32(math.Abs(float64(f.XScale))) * f.XEmbolden)
+}
+
+// yStrength returns the vertical emboldening strength, in scaled units.
+func (f *Font) yStrength() Position {
+	return roundf(float32(math.Abs(float64(f.YScale))) * f.YEmbolden)
+}
+
+// slantXY returns the slant ratio, taking into account the scale.
+func (f *Font) slantXY() float32 {
+	if f.YScale == 0 {
+		return 0
+	}
+	return f.Slant * float32(f.XScale) / float32(f.YScale)
+}
+
+// syntheticGlyphExtents applies the synthetic slant and emboldening to `extents`.
+func (f *Font) syntheticGlyphExtents(extents *GlyphExtents) {
+	if slant := f.slantXY(); slant != 0 {
+		x1, y1 := float32(extents.XBearing), float32(extents.YBearing)
+		x2, y2 := float32(extents.XBearing+extents.Width), float32(extents.YBearing+extents.Height)
+
+		x1 += float32(math.Floor(float64(minF(y1*slant, y2*slant))))
+		x2 += float32(math.Ceil(float64(maxF(y1*slant, y2*slant))))
+
+		extents.XBearing = int32(x1)
+		extents.Width = int32(x2) - extents.XBearing
+	}
+
+	xStrength, yStrength := f.xStrength(), f.yStrength()
+	if xStrength == 0 && yStrength == 0 {
+		return
+	}
+	if f.YScale < 0 {
+		yStrength = -yStrength
+	}
+	extents.YBearing += yStrength
+	extents.Height -= yStrength
+
+	if f.XScale < 0 {
+		xStrength = -xStrength
+	}
+	if f.EmboldenInPlace {
+		extents.XBearing -= xStrength / 2
+	}
+	extents.Width += xStrength
+}
+
+// GlyphOutline returns the outline of `glyph`, with the synthetic slant and
+// emboldening of the font applied, or false if the face does not
+// provide an outline for it.
+// As for `fonts.GlyphOutline`, the coordinates are expressed in font units.
+func (f *Font) GlyphOutline(glyph fonts.GID) (fonts.GlyphOutline, bool) {
+	renderer, ok := f.face.(fonts.FaceRenderer)
+	if !ok {
+		return fonts.GlyphOutline{}, false
+	}
+	var outline fonts.GlyphOutline
+	switch data := renderer.GlyphData(glyph, f.XPpem, f.YPpem).(type) {
+	case fonts.GlyphOutline:
+		outline = data
+	case fonts.GlyphSVG:
+		outline = data.Outline
+	default:
+		return fonts.GlyphOutline{}, false
+	}
+
+	if f.Slant == 0 && f.XEmbolden == 0 && f.YEmbolden == 0 {
+		return outline, true
+	}
+
+	// do not modify the data of the face
+	outline.Segments = append([]fonts.Segment(nil), outline.Segments...)
+
+	contours := outlineContours(outline.Segments)
+
+	// in font units, the slant does not depend on the scale
+	if f.Slant != 0 {
+		for _, contour := range contours {
+			for _, pt := range contour {
+				pt.X += pt.Y * f.Slant
+			}
+		}
+	}
+
+	if f.XEmbolden != 0 || f.YEmbolden != 0 {
+		upem := float32(f.faceUpem)
+		xStrength, yStrength := upem*f.XEmbolden, upem*f.YEmbolden
+		var xShift float32
+		if !f.EmboldenInPlace {
+			xShift = xStrength / 2
+		}
+		yShift := yStrength / 2
+		if f.XScale < 0 {
+			xShift = -xShift
+		}
+		if f.YScale < 0 {
+			yShift = -yShift
+		}
+		emboldenOutline(contours, xStrength, yStrength, xShift, yShift)
+	}
+
+	return outline, true
+}
+
+// outlineContours returns the points of each contour of the outline,
+// pointing into `segments`.
+func outlineContours(segments []fonts.Segment) [][]*fonts.SegmentPoint {
+	var (
+		contours [][]*fonts.SegmentPoint
+		current  []*fonts.SegmentPoint
+	)
+	for i := range segments {
+		seg := &segments[i]
+		if seg.Op == fonts.SegmentOpMoveTo && len(current) != 0 {
+			contours = append(contours, current)
+			current = nil
+		}
+		args := seg.ArgsSlice()
+		for j := range args {
+			current = append(current, &args[j])
+		}
+	}
+	if len(current) != 0 {
+		contours = append(contours, current)
+	}
+	return contours
+}
+
+// controlArea returns the signed area of the polygons formed by the (on and off curve) points.
+func controlArea(contours [][]*fonts.SegmentPoint) float32 {
+	var a float32
+	for _, contour := range contours {
+		for i, pi := range contour {
+			pj := contour[(i+1)%len(contour)]
+			a += pi.X*pj.Y - pi.Y*pj.X
+		}
+	}
+	return a * .5
+}
+
+type outlineVector struct{ x, y float32 }
+
+// normalizeLen normalizes `v` and returns its original length.
+func (v *outlineVector) normalizeLen() float32 {
+	l := float32(math.Hypot(float64(v.x), float64(v.y)))
+	if l != 0 {
+		v.x /= l
+		v.y /= l
+	}
+	return l
+}
+
+// emboldenOutline moves the points of the contours outward (or inward for negative
+// strengths), and translates them by (xShift, yShift).
+func emboldenOutline(contours [][]*fonts.SegmentPoint, xStrength, yStrength, xShift, yShift float32) {
+	if xStrength == 0 && yStrength == 0 {
+		return
+	}
+
+	xStrength /= 2
+	yStrength /= 2
+
+	orientationNegative := controlArea(contours) < 0
+
+	for _, points := range contours {
+		var (
+			in, out, anchor, shift outlineVector
+			lIn, lOut, lAnchor     float32
+		)
+		last := len(points) - 1
+
+		// Counter j cycles though the points; counter i advances only
+		// when points are moved; anchor k marks the first moved point.
+		for i, j, k := last, 0, -1; j != i && i != k; {
+			if j != k {
+				out.x = points[j].X - points[i].X
+				out.y = points[j].Y - points[i].Y
+				lOut = out.normalizeLen()
+
+				if lOut == 0 {
+					j = nextIndex(j, last)
+					continue
+				}
+			} else {
+				out = anchor
+				lOut = lAnchor
+			}
+
+			if lIn != 0 {
+				if k < 0 {
+					k = i
+					anchor = in
+					lAnchor = lIn
+				}
+
+				d := in.x*out.x + in.y*out.y
+
+				// shift only if turn is less than ~160 degrees
+				if d > -15./16 {
+					d = d + 1
+
+					// shift components along lateral bisector in proper orientation
+					shift.x = in.y + out.y
+					shift.y = in.x + out.x
+
+					if orientationNegative {
+						shift.x = -shift.x
+					} else {
+						shift.y = -shift.y
+					}
+
+					// restrict shift magnitude to better handle collapsing segments
+					q := out.x*in.y - out.y*in.x
+					if orientationNegative {
+						q = -q
+					}
+
+					l := minF(lIn, lOut)
+
+					// non-strict inequalities avoid divide-by-zero when q == l == 0
+					if xStrength*q <= l*d {
+						shift.x = shift.x * xStrength / d
+					} else {
+						shift.x = shift.x * l / q
+					}
+
+					if yStrength*q <= l*d {
+						shift.y = shift.y * yStrength / d
+					} else {
+						shift.y = shift.y * l / q
+					}
+				} else {
+					shift = outlineVector{}
+				}
+
+				for ; i != j; i = nextIndex(i, last) {
+					points[i].X += xShift + shift.x
+					points[i].Y += yShift + shift.y
+				}
+			} else {
+				i = j
+			}
+
+			in = out
+			lIn = lOut
+
+			j = nextIndex(j, last)
+		}
+	}
+}
+
+// nextIndex cycles through [0, last]
+func nextIndex(i, last int) int {
+	if i < last {
+		return i + 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package harfbuzz
+
+import (
+	"testing"
+
+	"github.com/boxesandglue/textlayout/fonts"
+)
+
+func TestSyntheticBoldMetrics(t *testing.T) {
+	font := NewFont(openFontFile("perf_reference/fonts/Roboto-Regular.ttf"))
+	glyph, _ := font.face.NominalGlyph('a')
+
+	advance := font.GlyphHAdvance(glyph)
+	extents, _ := font.GlyphExtents(glyph)
+
+	font.XEmbolden, font.YEmbolden = 0.02, 0.02
+	strength := font.xStrength()
+	if strength != roundf(float32(font.XScale)*0.02) || strength == 0 {
+		t.Fatalf("unexpected strength %d", strength)
+	}
+	if got := font.GlyphHAdvance(glyph); got != advance+strength {
+		t.Fatalf("expected advance %d, got %d", advance+strength, got)
+	}
+	boldExtents, _ := font.GlyphExtents(glyph)
+	if exp := (GlyphExtents{
+		XBearing: extents.XBearing, YBearing: extents.YBearing + strength,
+		Width: extents.Width + strength, Height: extents.Height - strength,
+	}); boldExtents != exp {
+		t.Fatalf("expected extents %v, got %v", exp, boldExtents)
+	}
+
+	font.EmboldenInPlace = true
+	if got := font.GlyphHAdvance(glyph); got != advance {
+		t.Fatalf("expected advance %d, got %d", advance, got)
+	}
+	boldExtents, _ = font.GlyphExtents(glyph)
+	if exp := extents.XBearing - strength/2; boldExtents.XBearing != exp {
+		t.Fatalf("expected x bearing %d, got %d", exp, boldExtents.XBearing)
+	}
+}
+
+func TestSyntheticSlant(t *testing.T) {
+	font := NewFont(openFontFile("perf_reference/fonts/Roboto-Regular.ttf"))
+	glyph, _ := font.face.NominalGlyph('l')
+
+	extents, _ := font.GlyphExtents(glyph)
+	outline, _ := font.GlyphOutline(glyph)
+
+	font.Slant = 0.2
+	slantedExtents, _ := font.GlyphExtents(glyph)
+	// 'l' is above the baseline, so that the top is shifted to the right
+	if slantedExtents.XBearing != extents.XBearing || slantedExtents.Width <= extents.Width {
+		t.Fatalf("unexpected slanted extents %v (from %v)", slantedExtents, extents)
+	}
+
+	slanted, _ := font.GlyphOutline(glyph)
+	for i, seg := range slanted.Segments {
+		for j, pt := range seg.ArgsSlice() {
+			orig := outline.Segments[i].Args[j]
+			if pt.Y != orig.Y || pt.X != orig.X+orig.Y*0.2 {
+				t.Fatalf("unexpected slanted point %v (from %v)", pt, orig)
+			}
+		}
+	}
+
+	// the face data is not modified
+	if again, _ := font.face.(fonts.FaceRenderer).GlyphData(glyph, 0, 0).(fonts.GlyphOutline); again.Segments[1] != outline.Segments[1] {
+		t.Fatal("face outline modified")
+	}
+}
+
+func TestEmboldenOutline(t *testing.T) {
+	square := func(clockwise bool) fonts.GlyphOutline {
+		points := []fonts.SegmentPoint{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}, {X: 0, Y: 100}}
+		if clockwise {
+			points[1], points[3] = points[3], points[1]
+		}
+		out := fonts.GlyphOutline{Segments: []fonts.Segment{{Op: fonts.SegmentOpMoveTo}}}
+		out.Segments[0].Args[0] = points[0]
+		for _, pt := range points[1:] {
+			seg := fonts.Segment{Op: fonts.SegmentOpLineTo}
+			seg.Args[0] = pt
+			out.Segments = append(out.Segments, seg)
+		}
+		return out
+	}
+
+	for _, clockwise := range []bool{true, false} {
+		outline := square(clockwise)
+		emboldenOutline(outlineContours(outline.Segments), 10, 10, 0, 0)
+		for _, seg := range outline.Segments {
+			pt := seg.Args[0]
+			if (pt.X != -5 && pt.X != 105) || (pt.Y != -5 && pt.Y != 105) {
+				t.Fatalf("clockwise %v: unexpected point %v", clockwise, pt)
+			}
+		}
+	}
+}
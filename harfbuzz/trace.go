@@ -0,0 +1,110 @@
+package harfbuzz
+
+import (
+	"fmt"
+
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+)
+
+// TraceKind identifies the step of the shaping reported by a `TraceEvent`.
+type TraceKind uint8
+
+const (
+	// TraceStartTable is emitted before applying the GSUB or GPOS table.
+	TraceStartTable TraceKind = iota
+	// TraceEndTable is emitted after applying the GSUB or GPOS table.
+	TraceEndTable
+	// TraceStartLookup is emitted before applying a lookup.
+	TraceStartLookup
+	// TraceSkipLookup is emitted instead of `TraceStartLookup` when a lookup
+	// is not applied, with the reason in `TraceEvent.Message`.
+	TraceSkipLookup
+	// TraceEndLookup is emitted after applying a lookup, or skipping it.
+	TraceEndLookup
+	// TracePause is emitted before calling the function specific
+	// to the script shaper, between two stages of a table (for instance
+	// to reorder the Indic syllables).
+	TracePause
+)
+
+// TraceEvent describes a step of the OpenType shaping.
+// See `Buffer.Trace`.
+type TraceEvent struct {
+	// Message is a human readable description of the event,
+	// similar to the one printed by hb-shape --trace.
+	Message string
+
+	Kind TraceKind
+	// Table is either 'GSUB' or 'GPOS'.
+	Table tt.Tag
+	// Stage is the index of the current stage in the table.
+	Stage int
+
+	// Lookup is the index of the lookup in the table, and
+	// Feature the tag of the feature this lookup was collected for.
+	// They are only meaningful for lookup events.
+	Lookup  uint16
+	Feature tt.Tag
+}
+
+// tracing returns true if the buffer has a trace function.
+func (b *Buffer) tracing() bool { return b.Trace != nil }
+
+func (b *Buffer) trace(font *Font, event TraceEvent) {
+	if b.tracing() {
+		b.Trace(b, font, event)
+	}
+}
+
+func (m *otMap) traceTable(kind TraceKind, tableIndex int, font *Font, buffer *Buffer) {
+	if !buffer.tracing() {
+		return
+	}
+	table := tableTags[tableIndex]
+	message := "start table " + table.String()
+	if kind == TraceEndTable {
+		message = "end table " + table.String()
+	}
+	buffer.trace(font, TraceEvent{Kind: kind, Table: table, Message: message})
+}
+
+func (m *otMap) traceLookup(kind TraceKind, tableIndex, stage int, lookup lookupMap, reason string, font *Font, buffer *Buffer) {
+	if !buffer.tracing() {
+		return
+	}
+	event := TraceEvent{Kind: kind, Table: tableTags[tableIndex], Stage: stage, Lookup: lookup.index, Feature: lookup.featureTag}
+	switch kind {
+	case TraceStartLookup:
+		event.Message = fmt.Sprintf("start lookup %d feature '%s'", lookup.index, lookup.featureTag)
+	case TraceSkipLookup:
+		event.Message = fmt.Sprintf("skipped lookup %d feature '%s' because %s", lookup.index, lookup.featureTag, reason)
+	case TraceEndLookup:
+		event.Message = fmt.Sprintf("end lookup %d feature '%s'", lookup.index, lookup.featureTag)
+	}
+	buffer.trace(font, event)
+}
+
+// skipReason returns a non empty string if the lookup can't apply
+// to any glyph of the buffer.
+func skipReason(buffer *Buffer, lookup lookupMap, accel *otLayoutLookupAccelerator) string {
+	if len(buffer.Info) == 0 {
+		return "the buffer is empty"
+	}
+	hasMask, hasGlyph := false, false
+	for _, info := range buffer.Info {
+		if info.Mask&lookup.mask != 0 {
+			hasMask = true
+			if accel.digest.mayHave(info.Glyph) {
+				hasGlyph = true
+				break
+			}
+		}
+	}
+	if !hasMask {
+		return "the feature is not enabled for any glyph"
+	}
+	if !hasGlyph {
+		return "no glyph matches"
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package harfbuzz
+
+import (
+	"strings"
+	"testing"
+
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+)
+
+func TestTrace(t *testing.T) {
+	font := NewFont(openFontFile("perf_reference/fonts/Roboto-Regular.ttf"))
+
+	var (
+		events []TraceEvent
+		states []string
+	)
+	buffer := NewBuffer()
+	buffer.AddRunes([]rune("office"), 0, -1)
+	buffer.GuessSegmentProperties()
+	buffer.Trace = func(buffer *Buffer, font *Font, event TraceEvent) {
+		events = append(events, event)
+		states = append(states, buffer.Serialize(font, SerializeText, SerializeNoPositions))
+	}
+	buffer.Shape(font, nil)
+
+	if len(events) == 0 {
+		t.Fatal("missing trace events")
+	}
+	if first, last := events[0], events[len(events)-1]; first.Kind != TraceStartTable || first.Table != tt.TagGsub ||
+		last.Kind != TraceEndTable || last.Table != tt.TagGpos {
+		t.Fatalf("unexpected first and last events %v %v", first, last)
+	}
+
+	liga := tt.NewTag('l', 'i', 'g', 'a')
+	var ligaApplied, skipped bool
+	for i, event := range events {
+		if event.Message == "" {
+			t.Fatalf("missing message for event %v", event)
+		}
+		switch event.Kind {
+		case TraceStartLookup:
+			if end := events[i+1]; end.Kind == TraceEndLookup && end.Feature == liga && states[i] != states[i+1] {
+				ligaApplied = true
+			}
+		case TraceSkipLookup:
+			skipped = true
+			if !strings.Contains(event.Message, "because") {
+				t.Fatalf("missing reason in %s", event.Message)
+			}
+			if end := events[i+1]; end.Kind != TraceEndLookup || end.Lookup != event.Lookup {
+				t.Fatalf("unexpected event after skip: %v", end)
+			}
+		}
+	}
+	if !ligaApplied {
+		t.Fatalf("expected the 'liga' feature to change the buffer: %v", states)
+	}
+	if !skipped {
+		t.Fatal("expected skipped lookups")
+	}
+}
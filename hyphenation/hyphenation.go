@@ -0,0 +1,219 @@
+// Package hyphenation implements the hyphenation algorithm of
+// F. M. Liang, used by TeX, which finds the positions where a word
+// may be hyphenated using language dependent patterns.
+//
+// The patterns are read from TeX hyphenation files, such as the ones
+// distributed by the hyph-utf8 project.
+package hyphenation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// reference : F. M. Liang, Word Hy-phen-a-tion by Com-put-er, Stanford University, 1983
+
+// Patterns stores the hyphenation patterns and exceptions
+// of a language.
+type Patterns struct {
+	patterns   map[string][]uint8 // letters (with '.' for the word boundaries) -> values
+	exceptions map[string][]int   // lower case word -> hyphenation positions
+	maxLength  int                // of the patterns, in runes
+
+	// LeftMin and RightMin are the minimum number of
+	// letters kept before and after a hyphen.
+	LeftMin, RightMin int
+}
+
+// Parse reads a TeX hyphenation file, made of a `\patterns{...}` command, and
+// optionally of a `\hyphenation{...}` command listing exceptions, like "ta-ble".
+// A file containing only patterns, without any command, is also supported.
+// Comments (starting with %) are ignored, and the ^^xx notation of TeX
+// is supported.
+// LeftMin and RightMin are set to 2 and 3, the values used by TeX for English.
+func Parse(r io.Reader) (*Patterns, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Patterns{
+		patterns:   make(map[string][]uint8),
+		exceptions: make(map[string][]int),
+		LeftMin:    2,
+		RightMin:   3,
+	}
+
+	src := stripComments(content)
+	patterns, hasPatterns, err := command(src, `\patterns`)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, hasExceptions, err := command(src, `\hyphenation`)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPatterns && !hasExceptions { // raw list of patterns
+		patterns = src
+	}
+
+	for _, pattern := range strings.Fields(patterns) {
+		if err := out.addPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, word := range strings.Fields(exceptions) {
+		out.AddException(word)
+	}
+	return out, nil
+}
+
+// stripComments removes the comments and decodes the ^^xx notation.
+func stripComments(content []byte) string {
+	var sb strings.Builder
+	for _, line := range bytes.Split(content, []byte{'\n'}) {
+		if i := bytes.IndexByte(line, '%'); i != -1 {
+			line = line[:i]
+		}
+		sb.WriteString(decodeCarets(string(line)))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// decodeCarets replaces the ^^xx notation by the Latin-1 character xx
+func decodeCarets(s string) string {
+	if !strings.Contains(s, "^^") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "^^") && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				sb.WriteRune(rune(c))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// command returns the argument of the command `name`, if present.
+func command(src, name string) (string, bool, error) {
+	start := strings.Index(src, name)
+	if start == -1 {
+		return "", false, nil
+	}
+	rest := strings.TrimLeftFunc(src[start+len(name):], unicode.IsSpace)
+	if !strings.HasPrefix(rest, "{") {
+		return "", false, fmt.Errorf("missing argument for %s", name)
+	}
+	end := strings.IndexByte(rest, '}')
+	if end == -1 {
+		return "", false, fmt.Errorf("unclosed argument for %s", name)
+	}
+	return rest[1:end], true, nil
+}
+
+// addPattern adds a pattern like "hen5at"
+func (p *Patterns) addPattern(pattern string) error {
+	var (
+		letters []rune
+		values  = []uint8{0}
+	)
+	for _, r := range pattern {
+		if '0' <= r && r <= '9' {
+			if values[len(values)-1] != 0 {
+				return fmt.Errorf("invalid pattern %s", pattern)
+			}
+			values[len(values)-1] = uint8(r - '0')
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+	if len(letters) == 0 {
+		return errors.New("invalid empty pattern")
+	}
+	p.patterns[string(letters)] = values
+	if len(letters) > p.maxLength {
+		p.maxLength = len(letters)
+	}
+	return nil
+}
+
+// AddException registers the hyphenation of a word, given with hyphens,
+// like "ta-ble", overriding the patterns.
+func (p *Patterns) AddException(word string) {
+	var (
+		letters   []rune
+		positions []int
+	)
+	for _, r := range word {
+		if r == '-' {
+			positions = append(positions, len(letters))
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+	}
+	p.exceptions[string(letters)] = positions
+}
+
+// Hyphenate returns the positions where `word` may be hyphenated :
+// a position i means that a hyphen may be inserted before word[i].
+// The positions are sorted, and respect LeftMin and RightMin.
+func (p *Patterns) Hyphenate(word []rune) []int {
+	lower := make([]rune, len(word))
+	for i, r := range word {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var out []int
+	if positions, ok := p.exceptions[string(lower)]; ok {
+		for _, pos := range positions {
+			if p.isAllowed(pos, len(word)) {
+				out = append(out, pos)
+			}
+		}
+		return out
+	}
+
+	// values[i] is the value of the position before w[i]
+	w := make([]rune, 0, len(word)+2)
+	w = append(w, '.')
+	w = append(w, lower...)
+	w = append(w, '.')
+	values := make([]uint8, len(w)+1)
+	for start := range w {
+		for end := start + 1; end <= len(w) && end-start <= p.maxLength; end++ {
+			pattern, ok := p.patterns[string(w[start:end])]
+			if !ok {
+				continue
+			}
+			for i, v := range pattern {
+				if v > values[start+i] {
+					values[start+i] = v
+				}
+			}
+		}
+	}
+
+	for i := 1; i < len(word); i++ {
+		// the position before word[i] is the position before w[i+1]
+		if values[i+1]%2 == 1 && p.isAllowed(i, len(word)) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (p *Patterns) isAllowed(pos, length int) bool {
+	return pos >= p.LeftMin && length-pos >= p.RightMin
+}
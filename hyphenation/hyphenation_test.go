@@ -0,0 +1,82 @@
+package hyphenation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// the example of the TeXbook, appendix H
+const patternsTeX = `% some English patterns
+\patterns{ % the patterns
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n
+  .ca4p % word boundary
+}
+\hyphenation{ta-ble
+  PRO-JECT}
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse(strings.NewReader(patternsTeX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.patterns) != 10 || len(p.exceptions) != 2 {
+		t.Fatalf("unexpected patterns %v and exceptions %v", p.patterns, p.exceptions)
+	}
+	if v := p.patterns["henat"]; !reflect.DeepEqual(v, []uint8{0, 0, 0, 5, 0, 0}) {
+		t.Errorf("unexpected values %v", v)
+	}
+	if v := p.patterns[".cap"]; !reflect.DeepEqual(v, []uint8{0, 0, 0, 4, 0}) {
+		t.Errorf("unexpected values %v", v)
+	}
+
+	// raw pattern list, with the TeX ^^ notation
+	p, err = Parse(strings.NewReader("1^^e9 \n a1b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.patterns["é"]; !ok || len(p.patterns) != 2 {
+		t.Errorf("unexpected patterns %v", p.patterns)
+	}
+
+	for _, invalid := range []string{`\patterns{a12b}`, `\patterns{ab`, `\hyphenation ab`} {
+		if _, err = Parse(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestHyphenate(t *testing.T) {
+	p, err := Parse(strings.NewReader(patternsTeX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		word     string
+		expected []int
+	}{
+		{"hyphenation", []int{2, 6}},   // hy-phen-ation
+		{"Hyphenation", []int{2, 6}},   // case insensitive
+		{"table", []int{2}},            // exception
+		{"project", []int{3}},          // exception, case insensitive
+		{"nation", []int{2}},           // na-tion
+		{"at", nil},                    // too short
+		{"capcap", nil},                // even values inhibit breaks
+		{"", nil},                      // empty
+		{"unknown", nil},               // no pattern
+		{"hyphenhyphen", []int{2, 8}},  // hy-phenhy-phen : 'nh' is not broken
+		{"hyphenations", []int{2, 6}},  // hy-phen-ations
+		{"hyphenationss", []int{2, 6}}, // hy-phen-ationss
+	} {
+		got := p.Hyphenate([]rune(test.word))
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("for %s, expected %v, got %v", test.word, test.expected, got)
+		}
+	}
+
+	p.LeftMin, p.RightMin = 3, 5
+	if got := p.Hyphenate([]rune("hyphenation")); !reflect.DeepEqual(got, []int{6}) {
+		t.Errorf("expected [6], got %v", got)
+	}
+}
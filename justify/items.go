@@ -0,0 +1,255 @@
+package justify
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/boxesandglue/textlayout/harfbuzz"
+	"github.com/boxesandglue/textlayout/segmenter"
+	ucd "github.com/boxesandglue/textlayout/unicodedata"
+)
+
+// Options controls how shaped text is converted into items.
+type Options struct {
+	// SpaceStretch and SpaceShrink are the stretchability and
+	// shrinkability of the spaces, relative to their natural width.
+	SpaceStretch, SpaceShrink float32
+	// KashidaStretch, if positive, allows the elongation of
+	// joined Arabic letters, up to the given amount for each pair
+	// of letters, in the unit of the glyph positions.
+	KashidaStretch float32
+	// HyphenPenalty is the penalty of breaking after a hyphen,
+	// or at a hyphenation point.
+	HyphenPenalty float32
+
+	// Hyphenate, if not nil, returns the positions where `word` may be
+	// hyphenated, as done by hyphenation.Patterns.Hyphenate.
+	Hyphenate func(word []rune) []int
+	// HyphenWidth is the width of the hyphen inserted when breaking
+	// at a hyphenation point. See `HyphenWidth`.
+	HyphenWidth float32
+}
+
+// DefaultOptions returns the options used by TeX for a Latin text :
+// spaces stretch up to half their width and shrink up to one third of it.
+func DefaultOptions() Options {
+	return Options{SpaceStretch: 1. / 2, SpaceShrink: 1. / 3, HyphenPenalty: 50}
+}
+
+// NewItems builds the items describing the paragraph `text`, shaped in
+// horizontal direction into `runs`, whose clusters must be the indices of the runes
+// in `text`, as done by harfbuzz.Buffer.AddRunes(text, offset, length).
+// `breaks` are the line break opportunities, as returned by segmenter.LineBreaks(text).
+//
+// The shaping is not redone at the line ends, which is only an approximation
+// for fonts using contextual substitutions or kerning across break opportunities.
+func NewItems(text []rune, runs []*harfbuzz.Buffer, breaks []segmenter.BreakOpportunity, opts Options) []Item {
+	advances := make([]float32, len(text))
+	clusterStarts := make([]bool, len(text))
+	for _, run := range runs {
+		for i, info := range run.Info {
+			if c := info.Cluster; c >= 0 && c < len(text) {
+				advances[c] += float32(run.Pos[i].XAdvance)
+				clusterStarts[c] = true
+			}
+		}
+	}
+
+	var hyphens []bool // hyphenation points
+	if opts.Hyphenate != nil {
+		hyphens = hyphenationPoints(text, opts.Hyphenate)
+	}
+
+	var (
+		items []Item
+		box   Item
+		inBox bool
+	)
+	flushBox := func() {
+		if inBox {
+			items = append(items, box)
+			inBox = false
+		}
+	}
+
+	for i := 0; i < len(text); {
+		class := ucd.LookupBreakClass(text[i])
+
+		if i > 0 {
+			prevClass := ucd.LookupBreakClass(text[i-1])
+			switch breaks[i] {
+			case segmenter.BreakMandatory:
+				flushBox()
+				items = append(items, Item{Kind: Glue, Offset: i, Stretch: Fill},
+					Item{Kind: Penalty, Offset: i, Penalty: -Infinity})
+			case segmenter.BreakAllowed:
+				if prevClass != ucd.BreakSP { // breaks after spaces are handled by the glue
+					flushBox()
+					it := Item{Kind: Penalty, Offset: i}
+					if prevClass == ucd.BreakHY {
+						it.Flagged, it.Penalty = true, opts.HyphenPenalty
+					}
+					items = append(items, it)
+				}
+			case segmenter.NoBreak:
+				if inBox && hyphens != nil && hyphens[i] && clusterStarts[i] {
+					flushBox()
+					items = append(items, Item{
+						Kind: Penalty, Offset: i, Width: opts.HyphenWidth,
+						Penalty: opts.HyphenPenalty, Flagged: true,
+					})
+				} else if inBox && opts.KashidaStretch > 0 && clusterStarts[i] {
+					if k := joiningBefore(text, i); k != -1 {
+						flushBox()
+						items = append(items, Item{Kind: Penalty, Offset: i, Penalty: Infinity},
+							Item{Kind: Glue, Offset: k, Stretch: opts.KashidaStretch, Kashida: true})
+					}
+				}
+			}
+		}
+
+		switch class {
+		case ucd.BreakSP:
+			end := i
+			var width float32
+			for ; end < len(text) && ucd.LookupBreakClass(text[end]) == ucd.BreakSP; end++ {
+				width += advances[end]
+			}
+			flushBox()
+			if breaks[end] != segmenter.BreakMandatory { // trailing spaces are dropped
+				if breaks[end] == segmenter.NoBreak {
+					items = append(items, Item{Kind: Penalty, Offset: i, Penalty: Infinity})
+				}
+				items = append(items, Item{
+					Kind: Glue, Offset: i, Width: width,
+					Stretch: width * opts.SpaceStretch, Shrink: width * opts.SpaceShrink,
+				})
+			}
+			i = end
+			continue
+		case ucd.BreakBK, ucd.BreakCR, ucd.BreakLF, ucd.BreakNL:
+			// new lines are handled by the mandatory break
+		default:
+			if !inBox {
+				box, inBox = Item{Kind: Box, Offset: i}, true
+			}
+			box.Width += advances[i]
+		}
+		i++
+	}
+	flushBox()
+
+	return append(items, Item{Kind: Glue, Offset: len(text), Stretch: Fill},
+		Item{Kind: Penalty, Offset: len(text), Penalty: -Infinity})
+}
+
+// hyphenationPoints returns the positions in `text` where a hyphen
+// may be inserted, calling `hyphenate` on each word.
+func hyphenationPoints(text []rune, hyphenate func(word []rune) []int) []bool {
+	out := make([]bool, len(text))
+	isWordRune := func(r rune) bool { return unicode.In(r, unicode.L, unicode.Mn) }
+	for start := 0; start < len(text); {
+		if !isWordRune(text[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(text) && isWordRune(text[end]) {
+			end++
+		}
+		for _, pos := range hyphenate(text[start:end]) {
+			if 0 < pos && pos < end-start {
+				out[start+pos] = true
+			}
+		}
+		start = end
+	}
+	return out
+}
+
+// joiningBefore returns the index of the letter before text[i]
+// (skipping transparent marks) if it is joined to text[i], or -1.
+func joiningBefore(text []rune, i int) int {
+	switch joiningType(text[i]) {
+	case ucd.D, ucd.R, ucd.Alaph, ucd.DalathRish:
+	default:
+		return -1
+	}
+	for k := i - 1; k >= 0; k-- {
+		switch joiningType(text[k]) {
+		case ucd.T:
+			continue
+		case ucd.D, ucd.L:
+			return k
+		}
+		return -1
+	}
+	return -1
+}
+
+// joiningType returns the Arabic joining type of `r`, where
+// marks and format characters not listed are transparent
+func joiningType(r rune) ucd.ArabicJoining {
+	if jt, ok := ucd.ArabicJoinings[r]; ok {
+		return jt
+	}
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return ucd.T
+	}
+	return ucd.U
+}
+
+// Apply adds the adjustments of the spaces to the horizontal advances
+// of the glyphs in `runs`, which must be the shaped runs used to build
+// the items. Kashida adjustments are ignored, since they require
+// inserting glyphs : see `InsertKashidas`.
+func Apply(runs []*harfbuzz.Buffer, adjustments []Adjustment) {
+	deltas := map[int]float32{}
+	for _, adj := range adjustments {
+		if !adj.Kashida {
+			deltas[adj.Offset] += adj.Delta
+		}
+	}
+	for _, run := range runs {
+		for i, info := range run.Info {
+			delta, ok := deltas[info.Cluster]
+			if !ok {
+				continue
+			}
+			run.Pos[i].XAdvance += harfbuzz.Position(math.Round(float64(delta)))
+			delete(deltas, info.Cluster) // adjust only one glyph per cluster
+		}
+	}
+}
+
+// hyphen returns the hyphen character supported by `font`
+func hyphen(font *harfbuzz.Font) rune {
+	if _, ok := font.Face().NominalGlyph(0x2010); ok {
+		return 0x2010
+	}
+	return '-'
+}
+
+// HyphenWidth returns the advance of the hyphen inserted by `ShapeLine`,
+// to be used as Options.HyphenWidth.
+func HyphenWidth(font *harfbuzz.Font) float32 {
+	glyph, _ := font.Face().NominalGlyph(hyphen(font))
+	return float32(font.GlyphHAdvance(glyph))
+}
+
+// ShapeLine shapes again the content of `line`, since the shaping of the whole paragraph
+// may be invalid at the line boundaries (for instance for ligatures or Arabic joining).
+// A hyphen is appended if the line is hyphenated.
+// The clusters of the returned buffer are the indices in `text`, the hyphen using
+// the index of the end of the line.
+func ShapeLine(text []rune, items []Item, line Line, font *harfbuzz.Font, features []harfbuzz.Feature) *harfbuzz.Buffer {
+	start, end := items[line.Start].Offset, items[line.End].Offset
+	buf := harfbuzz.NewBuffer()
+	buf.AddRunes(text, start, end-start)
+	if line.Hyphenated {
+		buf.AddRune(hyphen(font), end) // also clears the post-context
+	}
+	buf.GuessSegmentProperties()
+	buf.Shape(font, features)
+	return buf
+}
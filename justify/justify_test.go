@@ -0,0 +1,438 @@
+package justify
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	testdata "github.com/benoitkugler/textlayout-testdata/harfbuzz"
+	tt "github.com/boxesandglue/textlayout/fonts/truetype"
+	"github.com/boxesandglue/textlayout/harfbuzz"
+	"github.com/boxesandglue/textlayout/hyphenation"
+	"github.com/boxesandglue/textlayout/segmenter"
+)
+
+// shapeMonospace simulates the shaping of `text`, with one glyph
+// per rune and the given advances for letters and spaces.
+func shapeMonospace(text []rune, letter, space harfbuzz.Position) *harfbuzz.Buffer {
+	buf := harfbuzz.NewBuffer()
+	for i, r := range text {
+		adv := letter
+		if r == ' ' {
+			adv = space
+		}
+		buf.Info = append(buf.Info, harfbuzz.GlyphInfo{Cluster: i})
+		buf.Pos = append(buf.Pos, harfbuzz.GlyphPosition{XAdvance: adv})
+	}
+	return buf
+}
+
+func newItems(text []rune, opts Options) ([]Item, *harfbuzz.Buffer) {
+	buf := shapeMonospace(text, 100, 50)
+	return NewItems(text, []*harfbuzz.Buffer{buf}, segmenter.LineBreaks(text), opts), buf
+}
+
+// lineWidth returns the width of the line after justification
+func lineWidth(items []Item, line Line) float32 {
+	var width float32
+	for _, it := range items[line.Start:line.End] {
+		switch it.Kind {
+		case Box:
+			width += it.Width
+		case Glue:
+			width += it.Width
+			if line.Ratio >= 0 && !math.IsInf(float64(it.Stretch), 1) {
+				width += line.Ratio * it.Stretch
+			} else if line.Ratio < 0 {
+				width += line.Ratio * it.Shrink
+			}
+		}
+	}
+	if end := items[line.End]; end.Kind == Penalty {
+		width += end.Width
+	}
+	return width
+}
+
+func lineText(text []rune, items []Item, line Line) string {
+	return string(text[items[line.Start].Offset:items[line.End].Offset])
+}
+
+func TestNewItems(t *testing.T) {
+	text := []rune("ab  c-d\nef")
+	items, _ := newItems(text, DefaultOptions())
+	expected := []Item{
+		{Kind: Box, Offset: 0, Width: 200},
+		{Kind: Glue, Offset: 2, Width: 100, Stretch: 50, Shrink: 100 * DefaultOptions().SpaceShrink},
+		{Kind: Box, Offset: 4, Width: 200},
+		{Kind: Penalty, Offset: 6, Penalty: 50, Flagged: true},
+		{Kind: Box, Offset: 6, Width: 100},
+		{Kind: Glue, Offset: 8, Stretch: Fill},
+		{Kind: Penalty, Offset: 8, Penalty: -Infinity},
+		{Kind: Box, Offset: 8, Width: 200},
+		{Kind: Glue, Offset: 10, Stretch: Fill},
+		{Kind: Penalty, Offset: 10, Penalty: -Infinity},
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("expected %d items, got %v", len(expected), items)
+	}
+	for i, it := range items {
+		if it != expected[i] {
+			t.Errorf("item %d: expected %v, got %v", i, expected[i], it)
+		}
+	}
+
+	// no break before a closing parenthesis
+	items, _ = newItems([]rune("a )"), DefaultOptions())
+	if items[1].Kind != Penalty || items[1].Penalty != Infinity || items[2].Kind != Glue {
+		t.Errorf("expected a forbidden break, got %v", items)
+	}
+}
+
+func TestBreakLines(t *testing.T) {
+	text := []rune("aaa bb cccc dd eee ffff g hh iiiii jj k llll mm nnn")
+	items, _ := newItems(text, DefaultOptions())
+	params := DefaultParams(1600)
+	lines := BreakLines(items, params)
+	if len(lines) < 2 {
+		t.Fatalf("expected several lines, got %v", lines)
+	}
+	for i, line := range lines {
+		if line.Ratio < -1 || line.Ratio > params.Tolerance {
+			t.Errorf("line %d: invalid ratio %f", i, line.Ratio)
+		}
+		if i == len(lines)-1 {
+			if line.Ratio != 0 {
+				t.Errorf("expected a natural last line, got %f", line.Ratio)
+			}
+			continue
+		}
+		if w := lineWidth(items, line); math.Abs(float64(w-1600)) > 1e-2 {
+			t.Errorf("line %d (%s): expected width 1600, got %f", i, lineText(text, items, line), w)
+		}
+	}
+
+	// all the text is used
+	var got string
+	for _, line := range lines {
+		got += lineText(text, items, line) + " "
+	}
+	if got != string(text)+" " {
+		t.Errorf("unexpected lines %s", got)
+	}
+}
+
+func TestBreakLinesMandatory(t *testing.T) {
+	text := []rune("aa bb\ncc")
+	items, _ := newItems(text, DefaultOptions())
+	lines := BreakLines(items, DefaultParams(2000))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %v", lines)
+	}
+	if s := lineText(text, items, lines[0]); s != "aa bb\n" {
+		t.Errorf("unexpected first line %q", s)
+	}
+	if adjs := Adjustments(items, lines); len(adjs) != 0 {
+		t.Errorf("expected no adjustments, got %v", adjs)
+	}
+}
+
+func TestBreakLinesVariableWidths(t *testing.T) {
+	text := []rune("aa bb cc dd ee ff gg hh")
+	items, _ := newItems(text, DefaultOptions())
+	params := DefaultParams(0)
+	params.LineWidths = []float32{500, 1000}
+	lines := BreakLines(items, params)
+	for i, line := range lines[:len(lines)-1] {
+		target := params.lineWidth(i)
+		if w := lineWidth(items, line); math.Abs(float64(w-target)) > 1e-2 {
+			t.Errorf("line %d: expected width %f, got %f", i, target, w)
+		}
+	}
+}
+
+func TestBreakLinesOverfull(t *testing.T) {
+	text := []rune("aa bbbbbbbbbb cc")
+	items, _ := newItems(text, DefaultOptions())
+	lines := BreakLines(items, DefaultParams(500))
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %v", lines)
+	}
+	if s := lineText(text, items, lines[1]); s != "bbbbbbbbbb" {
+		t.Errorf("unexpected overfull line %q", s)
+	}
+	if lines[1].Ratio != -1 {
+		t.Errorf("expected a fully shrunk line, got %f", lines[1].Ratio)
+	}
+}
+
+func TestKashida(t *testing.T) {
+	text := []rune("ببب بَبا") // beh is dual joining, fatha transparent and alef right joining
+	opts := DefaultOptions()
+	opts.KashidaStretch = 200
+	items, _ := newItems(text, opts)
+
+	var kashidas []int
+	for _, it := range items {
+		if it.Kashida {
+			kashidas = append(kashidas, it.Offset)
+		}
+	}
+	if exp := []int{0, 1, 4, 6}; !equalInts(kashidas, exp) {
+		t.Fatalf("expected kashidas at %v, got %v", exp, kashidas)
+	}
+
+	text = append(text, []rune(" ببب")...)
+	items, _ = newItems(text, opts)
+	lines := BreakLines(items, DefaultParams(1000))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %v", lines)
+	}
+	adjs := Adjustments(items, lines)
+	var total float32
+	for _, adj := range adjs {
+		total += adj.Delta
+	}
+	if exp := float32(1000 - 750); math.Abs(float64(total-exp)) > 1e-2 {
+		t.Errorf("expected total adjustment %f, got %f", exp, total)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestApply(t *testing.T) {
+	text := []rune("aaa bb cccc dd eee ffff g hh")
+	items, buf := newItems(text, DefaultOptions())
+	lines := BreakLines(items, DefaultParams(1200))
+	Apply([]*harfbuzz.Buffer{buf}, Adjustments(items, lines))
+
+	for _, line := range lines[:len(lines)-1] {
+		start, end := items[line.Start].Offset, items[line.End].Offset
+		var width harfbuzz.Position
+		for _, pos := range buf.Pos[start:end] {
+			width += pos.XAdvance
+		}
+		if d := width - 1200; d < -2 || d > 2 { // rounding errors
+			t.Errorf("line %q: expected width 1200, got %d", string(text[start:end]), width)
+		}
+	}
+}
+
+func TestHyphenation(t *testing.T) {
+	patterns, err := hyphenation.Parse(strings.NewReader(`\patterns{hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.Hyphenate = patterns.Hyphenate
+	opts.HyphenWidth = 100
+
+	text := []rune("a hyphenation")
+	items, _ := newItems(text, opts)
+	var hyphens []int
+	for _, it := range items {
+		if it.Kind == Penalty && it.Flagged {
+			hyphens = append(hyphens, it.Offset)
+			if it.Width != 100 {
+				t.Errorf("unexpected hyphen width %f", it.Width)
+			}
+		}
+	}
+	if exp := []int{4, 8}; !equalInts(hyphens, exp) {
+		t.Fatalf("expected hyphenation points %v, got %v", exp, hyphens)
+	}
+
+	lines := BreakLines(items, DefaultParams(850))
+	var got []string
+	for _, line := range lines {
+		s := lineText(text, items, line)
+		if line.Hyphenated {
+			s += "-"
+		}
+		got = append(got, s)
+	}
+	if exp := "a hyphen-|ation"; strings.Join(got, "|") != exp {
+		t.Errorf("expected %s, got %s", exp, strings.Join(got, "|"))
+	}
+}
+
+func TestShapeLine(t *testing.T) {
+	file, err := testdata.Files.ReadFile("perf_reference/fonts/Roboto-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := tt.Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := harfbuzz.NewFont(face)
+
+	text := []rune("The justification of hyphenated words")
+	buf := harfbuzz.NewBuffer()
+	buf.AddRunes(text, 0, -1)
+	buf.GuessSegmentProperties()
+	buf.Shape(font, nil)
+
+	patterns, err := hyphenation.Parse(strings.NewReader(`\patterns{hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.Hyphenate = patterns.Hyphenate
+	opts.HyphenWidth = HyphenWidth(font)
+	if opts.HyphenWidth <= 0 {
+		t.Fatalf("invalid hyphen width %f", opts.HyphenWidth)
+	}
+
+	items := NewItems(text, []*harfbuzz.Buffer{buf}, segmenter.LineBreaks(text), opts)
+	lines := BreakLines(items, DefaultParams(12000))
+	var hyphenated int
+	for _, line := range lines {
+		lineBuf := ShapeLine(text, items, line, font, nil)
+		end := items[line.End].Offset
+		if !line.Hyphenated {
+			continue
+		}
+		hyphenated++
+		last := lineBuf.Info[len(lineBuf.Info)-1]
+		hyphenGlyph, _ := face.NominalGlyph(hyphen(font))
+		if last.Cluster != end || last.Glyph != hyphenGlyph {
+			t.Errorf("expected a hyphen at the end of the line, got %v", last)
+		}
+	}
+	if hyphenated == 0 {
+		t.Errorf("expected hyphenated lines, got %v", lines)
+	}
+}
+
+func TestKashidaPoints(t *testing.T) {
+	file, err := testdata.Files.ReadFile("perf_reference/fonts/Amiri-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := tt.Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := harfbuzz.NewFont(face)
+
+	text := []rune("ببب بَبا")
+	buf := harfbuzz.NewBuffer()
+	buf.AddRunes(text, 0, -1)
+	buf.GuessSegmentProperties()
+	buf.Shape(font, nil)
+
+	points := KashidaPoints(text, buf, font)
+	var letters []int
+	for _, p := range points {
+		letters = append(letters, p.Letter)
+	}
+	if exp := []int{6, 4, 1, 0}; !equalInts(letters, exp) { // visual order
+		t.Fatalf("expected kashidas after %v, got %v", exp, letters)
+	}
+
+	var width harfbuzz.Position
+	for _, pos := range buf.Pos {
+		width += pos.XAdvance
+	}
+	L := len(buf.Info)
+	target := width + 1234
+	if !StretchWithKashidas(buf, font, points, target) {
+		t.Fatal("unexpected failure")
+	}
+	tatweel, _, _ := tatweelGlyph(font)
+	var tatweels int
+	width = 0
+	for i, info := range buf.Info {
+		width += buf.Pos[i].XAdvance
+		if info.Glyph == tatweel {
+			tatweels++
+		}
+		if i > 0 && info.Cluster > buf.Info[i-1].Cluster {
+			t.Errorf("clusters are not monotone at %d", i)
+		}
+	}
+	if width != target {
+		t.Errorf("expected width %d, got %d", target, width)
+	}
+	if tatweels < len(points) || len(buf.Info) != L+tatweels {
+		t.Errorf("unexpected tatweels count %d", tatweels)
+	}
+}
+
+func TestApplySpacing(t *testing.T) {
+	spacing := Spacing{Letter: 10, Word: 20}
+
+	text := []rune("ab c")
+	buf := shapeMonospace(text, 100, 50)
+	got := ApplySpacing(text, buf, spacing)
+	for i, exp := range []harfbuzz.Position{110, 110, 80, 100} {
+		if got[i].XAdvance != exp {
+			t.Errorf("glyph %d: expected advance %d, got %d", i, exp, got[i].XAdvance)
+		}
+	}
+	if buf.Pos[0].XAdvance != 100 {
+		t.Error("buffer should not be modified")
+	}
+
+	// a base and its mark, in separate clusters
+	text = []rune("éx")
+	got = ApplySpacing(text, shapeMonospace(text, 100, 50), spacing)
+	for i, exp := range []harfbuzz.Position{100, 110, 100} {
+		if got[i].XAdvance != exp {
+			t.Errorf("glyph %d: expected advance %d, got %d", i, exp, got[i].XAdvance)
+		}
+	}
+
+	// a ligature
+	text = []rune("ffi")
+	buf = shapeMonospace(text, 100, 50)
+	buf.Info, buf.Pos = buf.Info[:1], buf.Pos[:1]
+	if got = ApplySpacing(text, buf, spacing); got[0].XAdvance != 100 {
+		t.Errorf("unexpected spacing in a ligature: %d", got[0].XAdvance)
+	}
+}
+
+func TestApplySpacingArabic(t *testing.T) {
+	file, err := testdata.Files.ReadFile("perf_reference/fonts/Amiri-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := tt.Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := harfbuzz.NewFont(face)
+
+	text := []rune("بب دب")
+	buf := harfbuzz.NewBuffer()
+	buf.AddRunes(text, 0, -1)
+	buf.GuessSegmentProperties()
+	buf.Shape(font, nil)
+
+	got := ApplySpacing(text, buf, Spacing{Letter: 10, Word: 20})
+	deltas := map[int]harfbuzz.Position{}
+	for i, info := range buf.Info {
+		deltas[info.Cluster] += got[i].XAdvance - buf.Pos[i].XAdvance
+	}
+	// in visual order, the spacing is added after the glyphs of the cluster
+	// following the boundary in logical order : no spacing between the joined behs,
+	// but after the space and the dal, which does not join the following beh
+	expected := map[int]harfbuzz.Position{0: 0, 1: 0, 2: 30, 3: 10, 4: 10}
+	for cluster, exp := range expected {
+		if deltas[cluster] != exp {
+			t.Errorf("cluster %d: expected spacing %d, got %d", cluster, exp, deltas[cluster])
+		}
+	}
+}
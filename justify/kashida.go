@@ -0,0 +1,130 @@
+package justify
+
+import (
+	"github.com/boxesandglue/textlayout/fonts"
+	"github.com/boxesandglue/textlayout/harfbuzz"
+)
+
+// tatweel is the Arabic character used to elongate joinings
+const tatweel = 0x0640
+
+// tatweelGlyph returns the glyph used by `font` for a tatweel, and its advance.
+// Since some fonts (like Amiri) map the tatweel to an empty glyph, substituted
+// during shaping, the tatweel is shaped instead of using the cmap.
+func tatweelGlyph(font *harfbuzz.Font) (fonts.GID, harfbuzz.Position, bool) {
+	if _, ok := font.Face().NominalGlyph(tatweel); !ok {
+		return 0, 0, false
+	}
+	buf := harfbuzz.NewBuffer()
+	buf.AddRune(tatweel, 0)
+	buf.GuessSegmentProperties()
+	buf.Shape(font, nil)
+	if len(buf.Info) != 1 || buf.Pos[0].XAdvance <= 0 {
+		return 0, 0, false
+	}
+	return buf.Info[0].Glyph, buf.Pos[0].XAdvance, true
+}
+
+// KashidaPoint is a position in a shaped run where
+// tatweels may be inserted, to elongate the joining
+// between two Arabic letters.
+type KashidaPoint struct {
+	// Index is the index of the glyph in the run before which
+	// the tatweels are inserted.
+	Index int
+	// Letter is the index in the text of the elongated letter,
+	// that is the first of the two joined letters, in logical order.
+	// It matches the Offset of the kashida adjustments.
+	Letter int
+}
+
+// KashidaPoints returns the positions in the shaped run `buf` where
+// tatweels may be inserted, sorted by glyph index. `text` is the text used to build
+// `buf`, whose clusters must be the indices of the runes in `text`.
+// It returns nil if `font` has no usable tatweel glyph.
+func KashidaPoints(text []rune, buf *harfbuzz.Buffer, font *harfbuzz.Font) []KashidaPoint {
+	if _, _, ok := tatweelGlyph(font); !ok {
+		return nil
+	}
+	var out []KashidaPoint
+	for i := 1; i < len(buf.Info); i++ {
+		c1, c2 := buf.Info[i-1].Cluster, buf.Info[i].Cluster
+		if c1 == c2 {
+			continue
+		}
+		// the boundary between two clusters is before the
+		// second one in logical order, whatever the direction
+		next := c1
+		if c2 > c1 {
+			next = c2
+		}
+		if next >= len(text) {
+			continue
+		}
+		if k := joiningBefore(text, next); k != -1 {
+			out = append(out, KashidaPoint{Index: i, Letter: k})
+		}
+	}
+	return out
+}
+
+// InsertKashidas elongates the letters of the shaped run `buf` by inserting tatweels
+// at `points`, as returned by `KashidaPoints`, so that the joining at points[i] is
+// widened by widths[i]. When a width is not a multiple of the tatweel advance,
+// the tatweels are overlapped, so that the run is widened by exactly the sum of `widths`.
+// The inserted glyphs use the cluster of the elongated letter.
+// It returns false, leaving `buf` unchanged, if `font` has no usable tatweel glyph.
+func InsertKashidas(buf *harfbuzz.Buffer, font *harfbuzz.Font, points []KashidaPoint, widths []harfbuzz.Position) bool {
+	glyph, advance, ok := tatweelGlyph(font)
+	if !ok {
+		return false
+	}
+
+	var (
+		infos     = make([]harfbuzz.GlyphInfo, 0, len(buf.Info))
+		positions = make([]harfbuzz.GlyphPosition, 0, len(buf.Pos))
+		p         int
+	)
+	for i := range buf.Info {
+		for ; p < len(points) && points[p].Index == i; p++ {
+			width := widths[p]
+			if width <= 0 {
+				continue
+			}
+			cluster := buf.Info[i].Cluster
+			if i > 0 && buf.Info[i-1].Cluster < cluster {
+				cluster = buf.Info[i-1].Cluster
+			}
+			n := (width + advance - 1) / advance
+			for t := harfbuzz.Position(0); t < n; t++ {
+				infos = append(infos, harfbuzz.GlyphInfo{Cluster: cluster, Glyph: glyph})
+				positions = append(positions, harfbuzz.GlyphPosition{XAdvance: width*(t+1)/n - width*t/n})
+			}
+		}
+		infos = append(infos, buf.Info[i])
+		positions = append(positions, buf.Pos[i])
+	}
+	buf.Info, buf.Pos = infos, positions
+	return true
+}
+
+// StretchWithKashidas widens the shaped run `buf` to `width`, by inserting tatweels
+// evenly distributed among the given `points` (see `KashidaPoints` and `InsertKashidas`).
+// It returns false, leaving `buf` unchanged, if the run may not be widened this way.
+func StretchWithKashidas(buf *harfbuzz.Buffer, font *harfbuzz.Font, points []KashidaPoint, width harfbuzz.Position) bool {
+	var current harfbuzz.Position
+	for _, pos := range buf.Pos {
+		current += pos.XAdvance
+	}
+	extra := width - current
+	if extra <= 0 || len(points) == 0 {
+		return false
+	}
+	n := harfbuzz.Position(len(points))
+	widths := make([]harfbuzz.Position, len(points))
+	for i := range widths {
+		t := harfbuzz.Position(i)
+		widths[i] = extra*(t+1)/n - extra*t/n
+	}
+	return InsertKashidas(buf, font, points, widths)
+}
@@ -0,0 +1,420 @@
+// Package justify breaks paragraphs into lines, using the total fit
+// algorithm of Knuth and Plass, and computes the adjustments required to
+// justify the resulting lines.
+//
+// A paragraph is described as a sequence of items (boxes, glue and penalties),
+// which may be built from shaped text with `NewItems`.
+package justify
+
+import "math"
+
+// reference : D. E. Knuth and M. F. Plass, Breaking Paragraphs into Lines,
+// Software - Practice and Experience 11 (1981)
+
+// ItemKind is the type of an item.
+type ItemKind uint8
+
+const (
+	// Box is an unbreakable content with a fixed width, like a word.
+	Box ItemKind = iota
+	// Glue is a space which may be stretched or shrunk. It is a break
+	// opportunity when it immediately follows a box, and is discarded
+	// at the start of a line.
+	Glue
+	// Penalty is a break opportunity, with the cost given by its Penalty field.
+	Penalty
+)
+
+// Infinity is the penalty forbidding a break, while -Infinity forces it.
+const Infinity = 10000
+
+// Fill is the stretchability of the glue ending a paragraph, which
+// absorbs any extra space.
+var Fill = float32(math.Inf(1))
+
+// Item is a box, glue or penalty.
+type Item struct {
+	// Offset is the index in the text of the first rune of the item.
+	Offset int
+	// Width is the natural width of the item. For a penalty, it is
+	// the width of the content (like a hyphen) added at the end of
+	// the line when breaking at this item.
+	Width float32
+	// Stretch and Shrink are the maximum amounts by which a glue may
+	// be stretched or shrunk.
+	Stretch, Shrink float32
+	// Penalty is the cost of breaking at a penalty item, between
+	// -Infinity and Infinity.
+	Penalty float32
+	Kind    ItemKind
+	// Flagged marks a penalty item at which breaking inserts a hyphen :
+	// two consecutive lines ending with flagged penalties are discouraged.
+	Flagged bool
+	// Kashida marks a glue item elongating the Arabic letter at Offset,
+	// instead of a white space.
+	Kashida bool
+}
+
+func (it Item) isForcedBreak() bool { return it.Kind == Penalty && it.Penalty <= -Infinity }
+
+// Params controls the choice of the line breaks.
+type Params struct {
+	// LineWidths are the widths of the successive lines. The last width
+	// is used for the remaining lines. It must not be empty.
+	LineWidths []float32
+	// Tolerance is the maximum adjustment ratio of a line. If no solution
+	// is found, lines are allowed to be as loose as required, and
+	// overfull lines are accepted when unavoidable.
+	Tolerance float32
+	// LinePenalty is added to the badness of each line, favoring
+	// fewer lines.
+	LinePenalty float32
+	// FlaggedDemerits are added when two consecutive lines end
+	// with a flagged penalty.
+	FlaggedDemerits float32
+	// FitnessDemerits are added when two consecutive lines have
+	// very different tightness.
+	FitnessDemerits float32
+}
+
+// DefaultParams returns the parameters suggested by Knuth and Plass,
+// for lines of constant width.
+func DefaultParams(lineWidth float32) Params {
+	return Params{
+		LineWidths:      []float32{lineWidth},
+		Tolerance:       2,
+		LinePenalty:     10,
+		FlaggedDemerits: 3000,
+		FitnessDemerits: 3000,
+	}
+}
+
+func (p Params) lineWidth(line int) float32 {
+	if line >= len(p.LineWidths) {
+		return p.LineWidths[len(p.LineWidths)-1]
+	}
+	return p.LineWidths[line]
+}
+
+// Line is a line of a broken paragraph.
+type Line struct {
+	// Start and End delimit the items of the line : items[Start:End].
+	// The item at End is the break : if it is a penalty, its width should
+	// be added at the end of the line.
+	Start, End int
+	// Ratio is the adjustment ratio of the line : its glue items are
+	// stretched by Ratio times their stretchability when it is positive,
+	// and shrunk by -Ratio times their shrinkability otherwise.
+	Ratio float32
+	// Hyphenated is true if the line ends at a hyphenation point,
+	// that is a flagged penalty with a non zero width : a hyphen
+	// must then be inserted.
+	Hyphenated bool
+}
+
+// sums are the cumulative dimensions of the items
+type sums struct {
+	width, stretch, shrink float32
+	fill                   int // number of glue items with Fill stretchability
+}
+
+// node is a feasible break
+type node struct {
+	prev     *node
+	position int // index of the break item
+	next     int // index of the first item of the next line
+	line     int // number of lines before the break
+	fitness  int
+	totals   sums // sums of the items before `next`
+	demerits float32
+	ratio    float32 // of the line ending at this break
+}
+
+// fitnessClass classifies the lines, from tight (0) to very loose (3).
+func fitnessClass(ratio float32) int {
+	switch {
+	case ratio < -0.5:
+		return 0
+	case ratio <= 0.5:
+		return 1
+	case ratio <= 1:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// badness is 100|r|^3, bounded to avoid overflows when
+// computing the demerits of very loose lines
+func badness(ratio float32) float32 {
+	r := math.Abs(float64(ratio))
+	return float32(math.Min(100*r*r*r, 1e10))
+}
+
+// isBreakpoint returns true if a line may be broken at items[i].
+func isBreakpoint(items []Item, i int) bool {
+	switch items[i].Kind {
+	case Glue:
+		return i > 0 && items[i-1].Kind == Box
+	case Penalty:
+		return items[i].Penalty < Infinity
+	default:
+		return false
+	}
+}
+
+type breaker struct {
+	items  []Item
+	totals []sums // totals[i] is the sum of items[:i]
+	params Params
+}
+
+func newBreaker(items []Item, params Params) breaker {
+	totals := make([]sums, len(items)+1)
+	for i, it := range items {
+		s := totals[i]
+		switch it.Kind {
+		case Box:
+			s.width += it.Width
+		case Glue:
+			s.width += it.Width
+			s.shrink += it.Shrink
+			if math.IsInf(float64(it.Stretch), 1) {
+				s.fill++
+			} else {
+				s.stretch += it.Stretch
+			}
+		}
+		totals[i+1] = s
+	}
+	return breaker{items: items, totals: totals, params: params}
+}
+
+// ratio returns the adjustment ratio of the line from `a` to the break at `end`,
+// which is +Inf (resp. -Inf) if the line is too short (resp. too long) and
+// may not be stretched (resp. shrunk)
+func (b breaker) ratio(a *node, end int) float32 {
+	target := b.params.lineWidth(a.line)
+	s := b.totals[end]
+	width := s.width - a.totals.width
+	if b.items[end].Kind == Penalty {
+		width += b.items[end].Width
+	}
+	switch {
+	case width < target:
+		if s.fill > a.totals.fill {
+			return 0
+		}
+		if stretch := s.stretch - a.totals.stretch; stretch > 0 {
+			return (target - width) / stretch
+		}
+		return float32(math.Inf(1))
+	case width > target:
+		if shrink := s.shrink - a.totals.shrink; shrink > 0 {
+			return (target - width) / shrink
+		}
+		return float32(math.Inf(-1))
+	default:
+		return 0
+	}
+}
+
+func (b breaker) demerits(a *node, end int, ratio float32) float32 {
+	it := b.items[end]
+	d := b.params.LinePenalty + badness(ratio)
+	d *= d
+	if it.Kind == Penalty {
+		if it.Penalty >= 0 {
+			d += it.Penalty * it.Penalty
+		} else if it.Penalty > -Infinity {
+			d -= it.Penalty * it.Penalty
+		}
+		if prev := b.items[a.position]; it.Flagged && a.prev != nil && prev.Kind == Penalty && prev.Flagged {
+			d += b.params.FlaggedDemerits
+		}
+	}
+	if diff := fitnessClass(ratio) - a.fitness; diff > 1 || diff < -1 {
+		d += b.params.FitnessDemerits
+	}
+	return a.demerits + d
+}
+
+// newNode returns the node for a break at `end`, following `a`.
+func (b breaker) newNode(a *node, end int, ratio, demerits float32) *node {
+	next := end
+	for ; next < len(b.items); next++ {
+		if it := b.items[next]; it.Kind == Box || (next > end && it.isForcedBreak()) {
+			break
+		}
+	}
+	n := &node{
+		prev:     a,
+		position: end,
+		next:     next,
+		line:     a.line + 1,
+		fitness:  fitnessClass(ratio),
+		totals:   b.totals[next],
+		demerits: demerits,
+		ratio:    ratio,
+	}
+	// lines which may not be adjusted are left as is,
+	// and overfull lines are shrunk as much as possible
+	if math.IsInf(float64(n.ratio), 1) {
+		n.ratio = 0
+	} else if n.ratio < -1 {
+		n.ratio = -1
+	}
+	return n
+}
+
+// run returns the last node of the best solution, or nil if no solution is found.
+// In emergency mode, the tolerance is ignored and overfull lines are accepted
+// if needed.
+func (b breaker) run(emergency bool) *node {
+	tolerance := b.params.Tolerance
+	if emergency {
+		tolerance = float32(math.Inf(1))
+	}
+	active := []*node{{fitness: 1}}
+	for i, it := range b.items {
+		if !isBreakpoint(b.items, i) {
+			continue
+		}
+
+		// best new break for each fitness class and line width
+		type candidate struct {
+			node     *node
+			line     int
+			fitness  int
+			ratio    float32
+			demerits float32
+		}
+		var (
+			candidates      []candidate
+			lastDeactivated *node
+		)
+		kept := active[:0]
+		for _, a := range active {
+			r := b.ratio(a, i)
+			if r < -1 || it.isForcedBreak() {
+				lastDeactivated = a
+			} else {
+				kept = append(kept, a)
+			}
+			if r < -1 || r > tolerance {
+				continue
+			}
+			d := b.demerits(a, i, r)
+			line := a.line
+			if line >= len(b.params.LineWidths) {
+				line = len(b.params.LineWidths) // lines with the same width are equivalent
+			}
+			c := candidate{node: a, line: line, fitness: fitnessClass(r), ratio: r, demerits: d}
+			found := false
+			for j, other := range candidates {
+				if other.line == c.line && other.fitness == c.fitness {
+					found = true
+					if c.demerits < other.demerits {
+						candidates[j] = c
+					}
+					break
+				}
+			}
+			if !found {
+				candidates = append(candidates, c)
+			}
+		}
+		active = kept
+
+		if emergency && len(active) == 0 && len(candidates) == 0 && lastDeactivated != nil {
+			// no way to avoid an overfull line
+			r := b.ratio(lastDeactivated, i)
+			candidates = append(candidates, candidate{node: lastDeactivated, ratio: r, demerits: b.demerits(lastDeactivated, i, r)})
+		}
+
+		for _, c := range candidates {
+			active = append(active, b.newNode(c.node, i, c.ratio, c.demerits))
+		}
+		if len(active) == 0 {
+			return nil
+		}
+	}
+
+	var best *node
+	for _, a := range active {
+		if a.position == len(b.items)-1 && a.prev != nil && (best == nil || a.demerits < best.demerits) {
+			best = a
+		}
+	}
+	return best
+}
+
+// BreakLines chooses the line breaks of the paragraph described by `items`,
+// minimizing the total demerits of the lines.
+// The items must end with a forced break, usually preceded by a
+// glue with Fill stretchability, as done by `NewItems`.
+func BreakLines(items []Item, params Params) []Line {
+	if len(items) == 0 || len(params.LineWidths) == 0 {
+		return nil
+	}
+	b := newBreaker(items, params)
+	best := b.run(false)
+	if best == nil {
+		best = b.run(true)
+	}
+	if best == nil {
+		return nil
+	}
+
+	var lines []Line
+	for n := best; n.prev != nil; n = n.prev {
+		end := items[n.position]
+		lines = append(lines, Line{
+			Start: n.prev.next, End: n.position, Ratio: n.ratio,
+			Hyphenated: end.Kind == Penalty && end.Flagged && end.Width != 0,
+		})
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// Adjustment is the extra advance required to justify a line.
+type Adjustment struct {
+	// Offset is the index in the text of the space to widen,
+	// or of the Arabic letter to elongate.
+	Offset int
+	// Delta is the extra advance, negative when shrinking, in the unit
+	// of the widths of the items.
+	Delta float32
+	// Kashida is true if the letter at Offset should be
+	// elongated (for instance with tatweels), instead of
+	// widening a space.
+	Kashida bool
+}
+
+// Adjustments returns the stretching or shrinking of the glue items
+// of the lines, as returned by `BreakLines`.
+func Adjustments(items []Item, lines []Line) []Adjustment {
+	var out []Adjustment
+	for _, line := range lines {
+		for _, it := range items[line.Start:line.End] {
+			if it.Kind != Glue {
+				continue
+			}
+			var delta float32
+			if line.Ratio >= 0 {
+				if !math.IsInf(float64(it.Stretch), 1) {
+					delta = line.Ratio * it.Stretch
+				}
+			} else {
+				delta = line.Ratio * it.Shrink
+			}
+			if delta != 0 {
+				out = append(out, Adjustment{Offset: it.Offset, Delta: delta, Kashida: it.Kashida})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package justify
+
+import (
+	"unicode"
+
+	"github.com/boxesandglue/textlayout/harfbuzz"
+)
+
+// Spacing describes the extra space added between letters
+// and after words, as the CSS letter-spacing and word-spacing properties.
+type Spacing struct {
+	// Letter is added between clusters.
+	Letter harfbuzz.Position
+	// Word is added after the word separators.
+	Word harfbuzz.Position
+}
+
+// isWordSeparator returns true for the word separators listed by CSS Text
+func isWordSeparator(r rune) bool {
+	switch r {
+	case 0x0020, 0x00A0, 0x1361, 0x10100, 0x10101, 0x1039F, 0x1091F:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplySpacing returns the positions of the shaped run `buf`, adjusted by `spacing`.
+// `text` is the text used to build `buf`, whose clusters must be the indices
+// of the runes in `text`. `buf` is not modified.
+//
+// Letter spacing is only added at the boundaries between clusters, so never
+// inside a ligature, and not between a base and its marks, nor between two
+// joined Arabic letters, where it would break the joining. It is not added
+// after the last cluster of the run.
+// Word spacing is added after the word separators, like spaces.
+func ApplySpacing(text []rune, buf *harfbuzz.Buffer, spacing Spacing) []harfbuzz.GlyphPosition {
+	out := append([]harfbuzz.GlyphPosition(nil), buf.Pos...)
+	for i := range buf.Info {
+		cluster := buf.Info[i].Cluster
+		if i+1 < len(buf.Info) && buf.Info[i+1].Cluster == cluster {
+			continue // the spacing is added after the last glyph of the cluster
+		}
+		if cluster < 0 || cluster >= len(text) {
+			continue
+		}
+
+		var delta harfbuzz.Position
+		if isWordSeparator(text[cluster]) {
+			delta += spacing.Word
+		}
+		if i+1 < len(buf.Info) {
+			// the boundary is before the cluster coming
+			// last in logical order, whatever the direction
+			next := buf.Info[i+1].Cluster
+			if next < cluster {
+				next = cluster
+			}
+			if next < len(text) && !unicode.In(text[next], unicode.Mn, unicode.Mc, unicode.Me) && joiningBefore(text, next) == -1 {
+				delta += spacing.Letter
+			}
+		}
+		out[i].XAdvance += delta
+	}
+	return out
+}
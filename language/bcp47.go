@@ -0,0 +1,252 @@
+package language
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// reference : RFC 5646, Tags for Identifying Languages
+
+// Tag is a BCP 47 language tag, split into its subtags,
+// which are stored in lower case.
+type Tag struct {
+	// Primary is the primary language subtag, like "en" or "yue".
+	// The irregular grandfathered tags, like "i-default", are stored
+	// whole in Primary.
+	Primary string
+	// ExtLang is the optional extended language subtag, like "yue" in "zh-yue".
+	ExtLang string
+	// Script is the optional script subtag, like "latn", which may be
+	// converted to a Script with ParseScript.
+	Script string
+	// Region is the optional region subtag, like "us" or "419".
+	Region string
+	// Variants are the optional variant subtags, like "1901".
+	Variants []string
+	// Extensions are the optional extensions, with their singleton,
+	// like "u-nu-arab".
+	Extensions []string
+	// PrivateUse is the optional private use subtag, with its "x-" prefix.
+	PrivateUse string
+}
+
+func isAlphaString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigitString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnumString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !('a' <= s[i] && s[i] <= 'z' || '0' <= s[i] && s[i] <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isVariant(s string) bool {
+	return isAlnumString(s) && (5 <= len(s) && len(s) <= 8 || len(s) == 4 && '0' <= s[0] && s[0] <= '9')
+}
+
+// ParseTag splits `tag` into its subtags, following the syntax of BCP 47.
+// As for NewLanguage, the parsing is case insensitive and '_' may be used instead of '-'.
+// The tag is not canonicalized : see Tag.Canonicalize.
+// An error is returned if `tag` is not well-formed.
+func ParseTag(tag string) (Tag, error) {
+	lang := string(NewLanguage(tag))
+	if _, ok := grandfatheredTags[lang]; ok {
+		return Tag{Primary: lang}, nil
+	}
+
+	var out Tag
+	if strings.HasPrefix(lang, "x-") {
+		out.PrivateUse = lang
+		return out, out.checkPrivateUse(tag)
+	}
+
+	subtags := strings.Split(lang, "-")
+	primary := subtags[0]
+	if !isAlphaString(primary) || len(primary) < 2 || len(primary) > 8 {
+		return out, fmt.Errorf("invalid language tag %s: invalid primary language subtag", tag)
+	}
+	out.Primary = primary
+	i := 1
+	if len(primary) <= 3 && i < len(subtags) && len(subtags[i]) == 3 && isAlphaString(subtags[i]) {
+		out.ExtLang = subtags[i]
+		i++
+	}
+	if i < len(subtags) && len(subtags[i]) == 4 && isAlphaString(subtags[i]) {
+		out.Script = subtags[i]
+		i++
+	}
+	if i < len(subtags) && (len(subtags[i]) == 2 && isAlphaString(subtags[i]) || len(subtags[i]) == 3 && isDigitString(subtags[i])) {
+		out.Region = subtags[i]
+		i++
+	}
+	for ; i < len(subtags) && isVariant(subtags[i]); i++ {
+		for _, variant := range out.Variants {
+			if variant == subtags[i] {
+				return out, fmt.Errorf("invalid language tag %s: duplicate variant %s", tag, variant)
+			}
+		}
+		out.Variants = append(out.Variants, subtags[i])
+	}
+	for i < len(subtags) && len(subtags[i]) == 1 && subtags[i] != "x" {
+		singleton := subtags[i]
+		for _, ext := range out.Extensions {
+			if ext[:1] == singleton {
+				return out, fmt.Errorf("invalid language tag %s: duplicate extension %s", tag, singleton)
+			}
+		}
+		start := i
+		for i++; i < len(subtags) && len(subtags[i]) >= 2 && len(subtags[i]) <= 8 && isAlnumString(subtags[i]); i++ {
+		}
+		if i == start+1 {
+			return out, fmt.Errorf("invalid language tag %s: empty extension %s", tag, singleton)
+		}
+		out.Extensions = append(out.Extensions, strings.Join(subtags[start:i], "-"))
+	}
+	if i < len(subtags) && subtags[i] == "x" {
+		out.PrivateUse = strings.Join(subtags[i:], "-")
+		return out, out.checkPrivateUse(tag)
+	}
+	if i < len(subtags) {
+		return out, fmt.Errorf("invalid language tag %s: unexpected subtag %s", tag, subtags[i])
+	}
+	return out, nil
+}
+
+func (t Tag) checkPrivateUse(tag string) error {
+	subtags := strings.Split(t.PrivateUse, "-")[1:]
+	if len(subtags) == 0 {
+		return fmt.Errorf("invalid language tag %s: empty private use subtag", tag)
+	}
+	for _, subtag := range subtags {
+		if len(subtag) == 0 || len(subtag) > 8 || !isAlnumString(subtag) {
+			return fmt.Errorf("invalid language tag %s: invalid private use subtag %s", tag, subtag)
+		}
+	}
+	return nil
+}
+
+// subtags returns the subtags, up to the variants
+func (t Tag) subtags() []string {
+	var out []string
+	for _, s := range [...]string{t.Primary, t.ExtLang, t.Script, t.Region} {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return append(out, t.Variants...)
+}
+
+// String returns the tag, with the case conventionally used
+// for the subtags, like "zh-Hant-TW".
+func (t Tag) String() string {
+	var subtags []string
+	if t.Primary != "" {
+		subtags = append(subtags, t.Primary)
+	}
+	if t.ExtLang != "" {
+		subtags = append(subtags, t.ExtLang)
+	}
+	if t.Script != "" {
+		subtags = append(subtags, strings.ToUpper(t.Script[:1])+t.Script[1:])
+	}
+	if t.Region != "" {
+		subtags = append(subtags, strings.ToUpper(t.Region))
+	}
+	subtags = append(subtags, t.Variants...)
+	subtags = append(subtags, t.Extensions...)
+	if t.PrivateUse != "" {
+		subtags = append(subtags, t.PrivateUse)
+	}
+	return strings.Join(subtags, "-")
+}
+
+// Language returns the tag as a Language.
+func (t Tag) Language() Language { return NewLanguage(t.String()) }
+
+// Canonicalize returns the canonical form of the tag, as defined by BCP 47, using
+// the replacements of the IANA Language Subtag Registry :
+//   - the grandfathered and redundant tags are replaced by their preferred value, like "i-klingon" by "tlh"
+//   - the extended language subtags replace their prefix, like "zh-yue" by "yue"
+//   - the deprecated subtags are replaced, like "iw" by "he"
+//   - the extensions are sorted by singleton
+func (t Tag) Canonicalize() Tag {
+	if preferred := grandfatheredTags[t.Primary]; preferred != "" {
+		canonical, err := ParseTag(preferred)
+		if err != nil { // should not happen with a valid registry
+			return t
+		}
+		canonical.Extensions, canonical.PrivateUse = t.Extensions, t.PrivateUse
+		t = canonical
+	}
+
+	// redundant tags may be followed by other subtags
+	subtags := t.subtags()
+	for n := len(subtags); n >= 2; n-- {
+		if preferred := redundantAliases[strings.Join(subtags[:n], "-")]; preferred != "" {
+			rest := append([]string{preferred}, subtags[n:]...)
+			canonical, err := ParseTag(strings.Join(rest, "-"))
+			if err != nil { // should not happen with a valid registry
+				return t
+			}
+			canonical.Extensions, canonical.PrivateUse = t.Extensions, t.PrivateUse
+			t = canonical
+			break
+		}
+	}
+
+	if t.ExtLang != "" && extlangPrefixes[t.ExtLang] == t.Primary {
+		t.Primary, t.ExtLang = t.ExtLang, ""
+	}
+	if preferred, ok := languageAliases[t.Primary]; ok {
+		t.Primary = preferred
+	}
+	if preferred, ok := scriptAliases[t.Script]; ok {
+		t.Script = preferred
+	}
+	if preferred, ok := regionAliases[t.Region]; ok {
+		t.Region = preferred
+	}
+	if len(t.Variants) != 0 {
+		variants := make([]string, len(t.Variants))
+		for i, variant := range t.Variants {
+			if preferred, ok := variantAliases[variant]; ok {
+				variant = preferred
+			}
+			variants[i] = variant
+		}
+		t.Variants = variants
+	}
+	if len(t.Extensions) != 0 {
+		t.Extensions = append([]string(nil), t.Extensions...)
+		sort.Strings(t.Extensions)
+	}
+	return t
+}
+
+// Canonicalize returns the canonical form of `l` (see Tag.Canonicalize),
+// or `l` if it is not a well-formed BCP 47 tag.
+func (l Language) Canonicalize() Language {
+	tag, err := ParseTag(string(l))
+	if err != nil {
+		return l
+	}
+	return tag.Canonicalize().Language()
+}
@@ -0,0 +1,92 @@
+package language
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected Tag
+	}{
+		{"en", Tag{Primary: "en"}},
+		{"fr_CA", Tag{Primary: "fr", Region: "ca"}},
+		{"zh-Hant-TW", Tag{Primary: "zh", Script: "hant", Region: "tw"}},
+		{"zh-yue-HK", Tag{Primary: "zh", ExtLang: "yue", Region: "hk"}},
+		{"es-419", Tag{Primary: "es", Region: "419"}},
+		{"sl-rozaj-biske-1994", Tag{Primary: "sl", Variants: []string{"rozaj", "biske", "1994"}}},
+		{"de-CH-1901", Tag{Primary: "de", Region: "ch", Variants: []string{"1901"}}},
+		{"en-US-u-islamcal-a-bbb-x-private", Tag{Primary: "en", Region: "us", Extensions: []string{"u-islamcal", "a-bbb"}, PrivateUse: "x-private"}},
+		{"x-whatever", Tag{PrivateUse: "x-whatever"}},
+		{"qaa-Qaaa-QM-x-southern", Tag{Primary: "qaa", Script: "qaaa", Region: "qm", PrivateUse: "x-southern"}},
+		{"i-default", Tag{Primary: "i-default"}},
+		{"zh-min-nan", Tag{Primary: "zh-min-nan"}},
+	} {
+		got, err := ParseTag(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("for %s, expected %v, got %v", test.input, test.expected, got)
+		}
+	}
+
+	for _, invalid := range []string{
+		"", "a", "en-", "de-419-DE", "a-DE", "ar-a-aaa-b-bbb-a-ccc", "en-a", "x-", "en-x-toolongsubtag", "de-1901-1901", "1en",
+	} {
+		if _, err := ParseTag(invalid); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	for _, test := range []struct {
+		input, expected string
+	}{
+		{"en-us", "en-US"},
+		{"zh-hant-tw", "zh-Hant-TW"},
+		{"zh-yue-HK", "yue-HK"},
+		{"zh-cmn-Hans-CN", "cmn-Hans-CN"},
+		{"iw-IL", "he-IL"},
+		{"in", "id"},
+		{"mo", "ro"},
+		{"de-DD", "de-DE"},
+		{"ja-Latn-heploc", "ja-Latn-alalc97"},
+		{"i-klingon", "tlh"},
+		{"zh-min-nan", "nan"},
+		{"en-GB-oed", "en-GB-oxendict"},
+		{"i-default", "i-default"},
+		{"sgn-BR", "bzs"},
+		{"sgn-BE-FR", "sfb"},
+		{"sgn-US-x-foo", "ase-x-foo"},
+		{"en-b-ccc-a-bbb", "en-a-bbb-b-ccc"},
+		{"x-private", "x-private"},
+	} {
+		tag, err := ParseTag(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tag.Canonicalize().String(); got != test.expected {
+			t.Errorf("for %s, expected %s, got %s", test.input, test.expected, got)
+		}
+	}
+
+	if l := NewLanguage("iw_IL").Canonicalize(); l != "he-il" {
+		t.Errorf("unexpected canonical language %s", l)
+	}
+	if l := NewLanguage("not-a-valid-tag").Canonicalize(); l != "not-a-valid-tag" {
+		t.Errorf("unexpected canonical language %s", l)
+	}
+}
+
+func TestTagScript(t *testing.T) {
+	tag, err := ParseTag("sr-Latn-RS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script, err := ParseScript(tag.Script); err != nil || script != Latin {
+		t.Errorf("unexpected script %s", script)
+	}
+}
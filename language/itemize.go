@@ -0,0 +1,118 @@
+package language
+
+// ported from ICU scrptrun.cpp (extra/scrptrun) Copyright (C) 1999-2016, International Business Machines Corporation and others.
+
+// ScriptRun is a run of text using a single script,
+// given by a position in the itemized text.
+// Offset and Length are suitable to be passed to harfbuzz.Buffer.AddRunes.
+type ScriptRun struct {
+	Offset, Length int
+	Script         Script
+}
+
+// pairedChars lists the opening (even indices) and closing (odd indices)
+// characters of the pairs handled by `SplitByScript`.
+var pairedChars = [...]rune{
+	0x0028, 0x0029, // ascii paired punctuation
+	0x003c, 0x003e,
+	0x005b, 0x005d,
+	0x007b, 0x007d,
+	0x00ab, 0x00bb, // guillemets
+	0x2018, 0x2019, // general punctuation
+	0x201c, 0x201d,
+	0x2039, 0x203a,
+	0x3008, 0x3009, // chinese paired punctuation
+	0x300a, 0x300b,
+	0x300c, 0x300d,
+	0x300e, 0x300f,
+	0x3010, 0x3011,
+	0x3014, 0x3015,
+	0x3016, 0x3017,
+	0x3018, 0x3019,
+	0x301a, 0x301b,
+}
+
+// pairIndex returns the index of `r` in `pairedChars`, or -1
+func pairIndex(r rune) int {
+	for i, c := range pairedChars {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+type parenEntry struct {
+	pairIndex int
+	script    Script
+}
+
+// SplitByScript splits `text` into runs of a single script, as expected by
+// the shaper. Common and inherited characters (like spaces, digits, punctuation
+// or combining marks) are merged with the surrounding script, so that a run of
+// common characters only happens when the whole text is common.
+// Paired characters (like parenthesis or quotes) are attributed to the
+// same script : in "Ελληνικά (ελληνικά) English", the parenthesis are Greek.
+func SplitByScript(text []rune) []ScriptRun {
+	var (
+		runs  []ScriptRun
+		stack []parenEntry // open paired characters
+	)
+
+	for start := 0; start < len(text); {
+		script := Common
+		startSP := len(stack) - 1 // index of the last entry pushed before the run
+
+		end := start
+		for ; end < len(text); end++ {
+			ch := text[end]
+			sc := LookupScript(ch)
+			pi := pairIndex(ch)
+
+			// paired characters get the script of their opening character
+			if pi >= 0 {
+				if pi&1 == 0 {
+					stack = append(stack, parenEntry{pairIndex: pi, script: script})
+				} else if len(stack) != 0 {
+					opening := pi &^ 1
+					for len(stack) != 0 && stack[len(stack)-1].pairIndex != opening {
+						stack = stack[:len(stack)-1]
+					}
+					if len(stack)-1 < startSP {
+						startSP = len(stack) - 1
+					}
+					if len(stack) != 0 {
+						sc = stack[len(stack)-1].script
+					}
+				}
+			}
+
+			if !script.IsSameScript(sc) {
+				break
+			}
+
+			if !script.IsRealScript() && sc.IsRealScript() {
+				script = sc
+				// now that we have a final script, fix the opening characters
+				// pushed before we knew it
+				for startSP < len(stack)-1 {
+					startSP++
+					stack[startSP].script = script
+				}
+			}
+
+			// a closing paired character is popped from the stack
+			if pi >= 0 && pi&1 != 0 && len(stack) != 0 {
+				stack = stack[:len(stack)-1]
+				if startSP >= len(stack) {
+					startSP = len(stack) - 1
+				}
+			}
+		}
+
+		runs = append(runs, ScriptRun{Offset: start, Length: end - start, Script: script})
+		start = end
+	}
+
+	return runs
+}
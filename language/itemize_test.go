@@ -0,0 +1,28 @@
+package language
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitByScript(t *testing.T) {
+	for _, test := range []struct {
+		text     string
+		expected []ScriptRun
+	}{
+		{"", nil},
+		{"123 !", []ScriptRun{{0, 5, Common}}},
+		{"1. Привет", []ScriptRun{{0, 9, Cyrillic}}},
+		{"été", []ScriptRun{{0, 5, Latin}}},
+		{"Hello Привет", []ScriptRun{{0, 6, Latin}, {6, 6, Cyrillic}}},
+		{"Ελληνικά (ελληνικά) English", []ScriptRun{{0, 20, Greek}, {20, 7, Latin}}},
+		{"abc (Привет) def", []ScriptRun{{0, 5, Latin}, {5, 6, Cyrillic}, {11, 5, Latin}}},
+		// the brackets get the script of the text preceding the opening one
+		{"«Привет» [abc]", []ScriptRun{{0, 10, Cyrillic}, {10, 3, Latin}, {13, 1, Cyrillic}}},
+		{"abc) def", []ScriptRun{{0, 8, Latin}}},
+	} {
+		if got := SplitByScript([]rune(test.text)); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("for %q, expected %v, got %v", test.text, test.expected, got)
+		}
+	}
+}
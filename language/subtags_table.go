@@ -0,0 +1,426 @@
+package language
+
+// Code generated by unicodedata/generate/main.go DO NOT EDIT.
+
+// deprecated language subtag -> preferred value
+var languageAliases = map[string]string{ // 92 entries
+	"aam": "aas",
+	"adp": "dz",
+	"asd": "snz",
+	"aue": "ktz",
+	"ayx": "nun",
+	"bgm": "bcg",
+	"bic": "bir",
+	"bjd": "drl",
+	"blg": "iba",
+	"ccq": "rki",
+	"cjr": "mom",
+	"cka": "cmr",
+	"cmk": "xch",
+	"coy": "pij",
+	"cqu": "quh",
+	"dit": "dif",
+	"drh": "khk",
+	"drr": "kzk",
+	"drw": "prs",
+	"gav": "dev",
+	"gfx": "vaj",
+	"ggn": "gvr",
+	"gli": "kzk",
+	"gti": "nyc",
+	"guv": "duz",
+	"hrr": "jal",
+	"ibi": "opa",
+	"ilw": "gal",
+	"in":  "id",
+	"iw":  "he",
+	"jeg": "oyb",
+	"ji":  "yi",
+	"jw":  "jv",
+	"kgc": "tdf",
+	"kgh": "kml",
+	"koj": "kwv",
+	"krm": "bmf",
+	"ktr": "dtp",
+	"kvs": "gdj",
+	"kwq": "yam",
+	"kxe": "tvd",
+	"kxl": "kru",
+	"kzj": "dtp",
+	"kzt": "dtp",
+	"lii": "raq",
+	"llo": "ngt",
+	"lmm": "rmx",
+	"meg": "cir",
+	"mo":  "ro",
+	"mst": "mry",
+	"mwj": "vaj",
+	"myd": "aog",
+	"myt": "mry",
+	"nad": "xny",
+	"ncp": "kdz",
+	"nns": "nbr",
+	"nnx": "ngv",
+	"nts": "pij",
+	"nxu": "bpp",
+	"oun": "vaj",
+	"pat": "kxr",
+	"pcr": "adx",
+	"pmc": "huw",
+	"pmu": "phr",
+	"ppa": "bfy",
+	"ppr": "lcq",
+	"pry": "prt",
+	"puz": "pub",
+	"sca": "hle",
+	"skk": "oyb",
+	"tdu": "dtp",
+	"thc": "tpo",
+	"thw": "ola",
+	"thx": "oyb",
+	"tie": "ras",
+	"tkk": "twm",
+	"tlw": "weo",
+	"tmp": "tyj",
+	"tne": "kak",
+	"tnf": "prs",
+	"tsf": "taj",
+	"uok": "ema",
+	"xba": "cax",
+	"xia": "acn",
+	"xkh": "waw",
+	"xrq": "dmw",
+	"ybd": "rki",
+	"yma": "lrr",
+	"ymt": "mtm",
+	"yos": "zom",
+	"yuu": "yug",
+	"zir": "scv",
+}
+
+// deprecated script subtag -> preferred value
+var scriptAliases = map[string]string{ // 0 entries
+}
+
+// deprecated region subtag -> preferred value
+var regionAliases = map[string]string{ // 6 entries
+	"bu": "mm",
+	"dd": "de",
+	"fx": "fr",
+	"tp": "tl",
+	"yd": "ye",
+	"zr": "cd",
+}
+
+// deprecated variant subtag -> preferred value
+var variantAliases = map[string]string{ // 1 entries
+	"heploc": "alalc97",
+}
+
+// extended language subtag -> prefix
+var extlangPrefixes = map[string]string{ // 245 entries
+	"aao": "ar",
+	"abh": "ar",
+	"abv": "ar",
+	"acm": "ar",
+	"acq": "ar",
+	"acw": "ar",
+	"acx": "ar",
+	"acy": "ar",
+	"adf": "ar",
+	"ads": "sgn",
+	"aeb": "ar",
+	"aec": "ar",
+	"aed": "sgn",
+	"aen": "sgn",
+	"afb": "ar",
+	"afg": "sgn",
+	"ajp": "ar",
+	"apc": "ar",
+	"apd": "ar",
+	"arb": "ar",
+	"arq": "ar",
+	"ars": "ar",
+	"ary": "ar",
+	"arz": "ar",
+	"ase": "sgn",
+	"asf": "sgn",
+	"asp": "sgn",
+	"asq": "sgn",
+	"asw": "sgn",
+	"auz": "ar",
+	"avl": "ar",
+	"ayh": "ar",
+	"ayl": "ar",
+	"ayn": "ar",
+	"ayp": "ar",
+	"bbz": "ar",
+	"bfi": "sgn",
+	"bfk": "sgn",
+	"bjn": "ms",
+	"bog": "sgn",
+	"bqn": "sgn",
+	"bqy": "sgn",
+	"btj": "ms",
+	"bve": "ms",
+	"bvl": "sgn",
+	"bvu": "ms",
+	"bzs": "sgn",
+	"cdo": "zh",
+	"cds": "sgn",
+	"cjy": "zh",
+	"cmn": "zh",
+	"cnp": "zh",
+	"coa": "ms",
+	"cpx": "zh",
+	"csc": "sgn",
+	"csd": "sgn",
+	"cse": "sgn",
+	"csf": "sgn",
+	"csg": "sgn",
+	"csl": "sgn",
+	"csn": "sgn",
+	"csp": "zh",
+	"csq": "sgn",
+	"csr": "sgn",
+	"csx": "sgn",
+	"czh": "zh",
+	"czo": "zh",
+	"doq": "sgn",
+	"dse": "sgn",
+	"dsl": "sgn",
+	"dup": "ms",
+	"ecs": "sgn",
+	"ehs": "sgn",
+	"esl": "sgn",
+	"esn": "sgn",
+	"eso": "sgn",
+	"eth": "sgn",
+	"fcs": "sgn",
+	"fse": "sgn",
+	"fsl": "sgn",
+	"fss": "sgn",
+	"gan": "zh",
+	"gds": "sgn",
+	"gom": "kok",
+	"gse": "sgn",
+	"gsg": "sgn",
+	"gsm": "sgn",
+	"gss": "sgn",
+	"gus": "sgn",
+	"hab": "sgn",
+	"haf": "sgn",
+	"hak": "zh",
+	"hds": "sgn",
+	"hji": "ms",
+	"hks": "sgn",
+	"hos": "sgn",
+	"hps": "sgn",
+	"hsh": "sgn",
+	"hsl": "sgn",
+	"hsn": "zh",
+	"icl": "sgn",
+	"iks": "sgn",
+	"ils": "sgn",
+	"inl": "sgn",
+	"ins": "sgn",
+	"ise": "sgn",
+	"isg": "sgn",
+	"isr": "sgn",
+	"jak": "ms",
+	"jax": "ms",
+	"jcs": "sgn",
+	"jhs": "sgn",
+	"jks": "sgn",
+	"jls": "sgn",
+	"jos": "sgn",
+	"jsl": "sgn",
+	"jus": "sgn",
+	"kgi": "sgn",
+	"knn": "kok",
+	"kvb": "ms",
+	"kvk": "sgn",
+	"kvr": "ms",
+	"kxd": "ms",
+	"lbs": "sgn",
+	"lce": "ms",
+	"lcf": "ms",
+	"liw": "ms",
+	"lls": "sgn",
+	"lsb": "sgn",
+	"lsg": "sgn",
+	"lsl": "sgn",
+	"lsn": "sgn",
+	"lso": "sgn",
+	"lsp": "sgn",
+	"lst": "sgn",
+	"lsv": "sgn",
+	"lsy": "sgn",
+	"ltg": "lv",
+	"lvs": "lv",
+	"lws": "sgn",
+	"lzh": "zh",
+	"max": "ms",
+	"mdl": "sgn",
+	"meo": "ms",
+	"mfa": "ms",
+	"mfb": "ms",
+	"mfs": "sgn",
+	"min": "ms",
+	"mnp": "zh",
+	"mqg": "ms",
+	"mre": "sgn",
+	"msd": "sgn",
+	"msi": "ms",
+	"msr": "sgn",
+	"mui": "ms",
+	"mzc": "sgn",
+	"mzg": "sgn",
+	"mzy": "sgn",
+	"nan": "zh",
+	"nbs": "sgn",
+	"ncs": "sgn",
+	"nsi": "sgn",
+	"nsl": "sgn",
+	"nsp": "sgn",
+	"nsr": "sgn",
+	"nzs": "sgn",
+	"okl": "sgn",
+	"orn": "ms",
+	"ors": "ms",
+	"pel": "ms",
+	"pga": "ar",
+	"pgz": "sgn",
+	"pks": "sgn",
+	"prl": "sgn",
+	"prz": "sgn",
+	"psc": "sgn",
+	"psd": "sgn",
+	"pse": "ms",
+	"psg": "sgn",
+	"psl": "sgn",
+	"pso": "sgn",
+	"psp": "sgn",
+	"psr": "sgn",
+	"pys": "sgn",
+	"rms": "sgn",
+	"rsi": "sgn",
+	"rsl": "sgn",
+	"rsm": "sgn",
+	"sdl": "sgn",
+	"sfb": "sgn",
+	"sfs": "sgn",
+	"sgg": "sgn",
+	"sgx": "sgn",
+	"shu": "ar",
+	"slf": "sgn",
+	"sls": "sgn",
+	"sqk": "sgn",
+	"sqs": "sgn",
+	"sqx": "sgn",
+	"ssh": "ar",
+	"ssp": "sgn",
+	"ssr": "sgn",
+	"svk": "sgn",
+	"swc": "sw",
+	"swh": "sw",
+	"swl": "sgn",
+	"syy": "sgn",
+	"szs": "sgn",
+	"tmw": "ms",
+	"tse": "sgn",
+	"tsm": "sgn",
+	"tsq": "sgn",
+	"tss": "sgn",
+	"tsy": "sgn",
+	"tza": "sgn",
+	"ugn": "sgn",
+	"ugy": "sgn",
+	"ukl": "sgn",
+	"uks": "sgn",
+	"urk": "ms",
+	"uzn": "uz",
+	"uzs": "uz",
+	"vgt": "sgn",
+	"vkk": "ms",
+	"vkt": "ms",
+	"vsi": "sgn",
+	"vsl": "sgn",
+	"vsv": "sgn",
+	"wbs": "sgn",
+	"wuu": "zh",
+	"xki": "sgn",
+	"xml": "sgn",
+	"xmm": "ms",
+	"xms": "sgn",
+	"yds": "sgn",
+	"ygs": "sgn",
+	"yhs": "sgn",
+	"ysl": "sgn",
+	"ysm": "sgn",
+	"yue": "zh",
+	"zib": "sgn",
+	"zlm": "ms",
+	"zmi": "ms",
+	"zsl": "sgn",
+	"zsm": "ms",
+}
+
+// grandfathered tag -> preferred value (possibly empty)
+var grandfatheredTags = map[string]string{ // 26 entries
+	"art-lojban":  "jbo",
+	"cel-gaulish": "",
+	"en-gb-oed":   "en-gb-oxendict",
+	"i-ami":       "ami",
+	"i-bnn":       "bnn",
+	"i-default":   "",
+	"i-enochian":  "",
+	"i-hak":       "hak",
+	"i-klingon":   "tlh",
+	"i-lux":       "lb",
+	"i-mingo":     "",
+	"i-navajo":    "nv",
+	"i-pwn":       "pwn",
+	"i-tao":       "tao",
+	"i-tay":       "tay",
+	"i-tsu":       "tsu",
+	"no-bok":      "nb",
+	"no-nyn":      "nn",
+	"sgn-be-fr":   "sfb",
+	"sgn-be-nl":   "vgt",
+	"sgn-ch-de":   "sgg",
+	"zh-guoyu":    "cmn",
+	"zh-hakka":    "hak",
+	"zh-min":      "",
+	"zh-min-nan":  "nan",
+	"zh-xiang":    "hsn",
+}
+
+// redundant tag -> preferred value
+var redundantAliases = map[string]string{ // 25 entries
+	"sgn-br":      "bzs",
+	"sgn-co":      "csn",
+	"sgn-de":      "gsg",
+	"sgn-dk":      "dsl",
+	"sgn-es":      "ssp",
+	"sgn-fr":      "fsl",
+	"sgn-gb":      "bfi",
+	"sgn-gr":      "gss",
+	"sgn-ie":      "isg",
+	"sgn-it":      "ise",
+	"sgn-jp":      "jsl",
+	"sgn-mx":      "mfs",
+	"sgn-ni":      "ncs",
+	"sgn-nl":      "dse",
+	"sgn-no":      "nsl",
+	"sgn-pt":      "psr",
+	"sgn-se":      "swl",
+	"sgn-us":      "ase",
+	"sgn-za":      "sfs",
+	"zh-cmn":      "cmn",
+	"zh-cmn-hans": "cmn-hans",
+	"zh-cmn-hant": "cmn-hant",
+	"zh-gan":      "gan",
+	"zh-wuu":      "wuu",
+	"zh-yue":      "yue",
+}
@@ -0,0 +1,358 @@
+// Package segmenter implements Unicode text segmentation algorithms,
+// such as the line breaking algorithm described in Unicode Standard Annex #14.
+package segmenter
+
+import (
+	"unicode"
+
+	ucd "github.com/boxesandglue/textlayout/unicodedata"
+)
+
+// BreakOpportunity indicates if a line may be broken before a rune.
+type BreakOpportunity uint8
+
+const (
+	// NoBreak forbids a line break.
+	NoBreak BreakOpportunity = iota
+	// BreakAllowed indicates a line break opportunity.
+	BreakAllowed
+	// BreakMandatory indicates a line break which must happen,
+	// after a new line character or at the end of the text.
+	BreakMandatory
+)
+
+// lineClass returns the break class of `r`, resolved according
+// to rule LB1.
+func lineClass(r rune) *unicode.RangeTable {
+	switch class := ucd.LookupBreakClass(r); class {
+	case ucd.BreakAI, ucd.BreakSG, ucd.BreakXX:
+		return ucd.BreakAL
+	case ucd.BreakSA:
+		if unicode.In(r, unicode.Mn, unicode.Mc) {
+			return ucd.BreakCM
+		}
+		return ucd.BreakAL
+	case ucd.BreakCJ:
+		return ucd.BreakNS
+	default:
+		return class
+	}
+}
+
+func isIn(class *unicode.RangeTable, classes ...*unicode.RangeTable) bool {
+	for _, c := range classes {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+// isEastAsianWide returns true for the East_Asian_Width property values
+// F (fullwidth), W (wide) and H (halfwidth), used by rule LB30.
+func isEastAsianWide(r rune) bool {
+	switch ucd.LookupEastAsianWidth(r) {
+	case ucd.EastAsianFullwidth, ucd.EastAsianWide, ucd.EastAsianHalfwidth:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnassigned returns true for the runes of the general category Cn,
+// which is not included in unicode.Categories for older Go versions.
+func isUnassigned(r rune) bool {
+	category := ucd.LookupType(r)
+	return category == nil || category == unicode.Categories["Cn"]
+}
+
+// lineBreaker stores the state needed to apply the pair rules
+// of the algorithm, between the previous runes and the current one.
+type lineBreaker struct {
+	prev      *unicode.RangeTable // class of the previous rune, after rule LB9 and LB10
+	prevPrev  *unicode.RangeTable // class of the rune before `prev`
+	prevRaw   *unicode.RangeTable // class of the previous rune, before rule LB9
+	beforeSP  *unicode.RangeTable // class of the last rune which is not a space
+	prevRune  rune                // the rune with class `prev`
+	riCount   int                 // number of consecutive regional indicators, up to `prev`
+	hasPrev   bool
+	prevIsCn  bool // prevRune is not assigned
+	prevIsExt bool // prevRune is Extended_Pictographic
+}
+
+// next returns the break opportunity before `r`, and updates the state.
+func (lb *lineBreaker) next(r rune) BreakOpportunity {
+	class := lineClass(r)
+	if !lb.hasPrev { // LB2
+		lb.update(r, class, false)
+		return NoBreak
+	}
+
+	attached := false
+	out := lb.breakBefore(r, class, &attached)
+	lb.update(r, class, attached)
+	return out
+}
+
+// update records `r` as the previous rune
+func (lb *lineBreaker) update(r rune, class *unicode.RangeTable, attached bool) {
+	lb.hasPrev = true
+	lb.prevRaw = class
+	if attached { // LB9 : the previous rune keeps its class
+		return
+	}
+	if isIn(class, ucd.BreakCM, ucd.BreakZWJ) { // LB10
+		class = ucd.BreakAL
+	}
+
+	if class == ucd.BreakRI {
+		lb.riCount++
+	} else {
+		lb.riCount = 0
+	}
+	if class != ucd.BreakSP {
+		lb.beforeSP = class
+	}
+	lb.prevPrev, lb.prev = lb.prev, class
+	lb.prevRune = r
+	lb.prevIsCn = isUnassigned(r)
+	lb.prevIsExt = unicode.Is(ucd.Extended_Pictographic, r)
+}
+
+// breakBefore applies the rules LB4 to LB31, with b the class of `r`.
+// `attached` is set to true when `r` is a combining mark attached to the previous rune (LB9).
+func (lb *lineBreaker) breakBefore(r rune, b *unicode.RangeTable, attached *bool) BreakOpportunity {
+	// LB4, LB5 : always break after hard line breaks
+	switch lb.prevRaw {
+	case ucd.BreakBK, ucd.BreakLF, ucd.BreakNL:
+		return BreakMandatory
+	case ucd.BreakCR:
+		if b == ucd.BreakLF {
+			return NoBreak
+		}
+		return BreakMandatory
+	}
+	// LB6 : do not break before hard line breaks
+	if isIn(b, ucd.BreakBK, ucd.BreakCR, ucd.BreakLF, ucd.BreakNL) {
+		return NoBreak
+	}
+	// LB7 : do not break before spaces or zero width space
+	if isIn(b, ucd.BreakSP, ucd.BreakZW) {
+		return NoBreak
+	}
+	a := lb.prev
+	// LB8 : break before any character following a zero-width space,
+	// even if one or more spaces intervene
+	if lb.beforeSP == ucd.BreakZW && isIn(a, ucd.BreakZW, ucd.BreakSP) {
+		return BreakAllowed
+	}
+	// LB8a : do not break after a zero width joiner
+	if lb.prevRaw == ucd.BreakZWJ {
+		if isIn(b, ucd.BreakCM, ucd.BreakZWJ) && !isIn(a, ucd.BreakBK, ucd.BreakCR, ucd.BreakLF, ucd.BreakNL, ucd.BreakSP, ucd.BreakZW) {
+			*attached = true
+		}
+		return NoBreak
+	}
+	// LB9 : do not break a combining character sequence
+	if isIn(b, ucd.BreakCM, ucd.BreakZWJ) {
+		if !isIn(a, ucd.BreakBK, ucd.BreakCR, ucd.BreakLF, ucd.BreakNL, ucd.BreakSP, ucd.BreakZW) {
+			*attached = true
+			return NoBreak
+		}
+		// LB10 : treat any remaining combining mark as AL
+		b = ucd.BreakAL
+	}
+
+	// LB11 : do not break before or after word joiner
+	if b == ucd.BreakWJ || a == ucd.BreakWJ {
+		return NoBreak
+	}
+	// LB12 : do not break after NBSP and related characters
+	if a == ucd.BreakGL {
+		return NoBreak
+	}
+	// LB12a : do not break before NBSP and related characters, except after spaces and hyphens
+	if b == ucd.BreakGL && !isIn(a, ucd.BreakSP, ucd.BreakBA, ucd.BreakHY) {
+		return NoBreak
+	}
+	// LB13 : do not break before ‘]’ or ‘!’ or ‘;’ or ‘/’, even after spaces
+	if isIn(b, ucd.BreakCL, ucd.BreakCP, ucd.BreakEX, ucd.BreakIS, ucd.BreakSY) {
+		return NoBreak
+	}
+	// LB14 : do not break after ‘[’, even after spaces
+	if lb.beforeSP == ucd.BreakOP {
+		return NoBreak
+	}
+	// LB15 : do not break within ‘”[’, even with intervening spaces
+	if lb.beforeSP == ucd.BreakQU && b == ucd.BreakOP {
+		return NoBreak
+	}
+	// LB16 : do not break between closing punctuation and a nonstarter, even with intervening spaces
+	if isIn(lb.beforeSP, ucd.BreakCL, ucd.BreakCP) && b == ucd.BreakNS {
+		return NoBreak
+	}
+	// LB17 : do not break within ‘——’, even with intervening spaces
+	if lb.beforeSP == ucd.BreakB2 && b == ucd.BreakB2 {
+		return NoBreak
+	}
+	// LB18 : break after spaces
+	if a == ucd.BreakSP {
+		return BreakAllowed
+	}
+	// LB19 : do not break before or after quotation marks
+	if b == ucd.BreakQU || a == ucd.BreakQU {
+		return NoBreak
+	}
+	// LB20 : break before and after unresolved CB
+	if b == ucd.BreakCB || a == ucd.BreakCB {
+		return BreakAllowed
+	}
+	// LB21 : do not break before hyphen-minus, other hyphens, fixed-width spaces,
+	// small kana, and other non-starters, or after acute accents
+	if isIn(b, ucd.BreakBA, ucd.BreakHY, ucd.BreakNS) || a == ucd.BreakBB {
+		return NoBreak
+	}
+	// LB21a : don't break after Hebrew + Hyphen
+	if isIn(a, ucd.BreakHY, ucd.BreakBA) && lb.prevPrev == ucd.BreakHL {
+		return NoBreak
+	}
+	// LB21b : don’t break between Solidus and Hebrew letters
+	if a == ucd.BreakSY && b == ucd.BreakHL {
+		return NoBreak
+	}
+	// LB22 : do not break before ellipses
+	if b == ucd.BreakIN {
+		return NoBreak
+	}
+	// LB23 : do not break between digits and letters
+	if (isIn(a, ucd.BreakAL, ucd.BreakHL) && b == ucd.BreakNU) || (a == ucd.BreakNU && isIn(b, ucd.BreakAL, ucd.BreakHL)) {
+		return NoBreak
+	}
+	// LB23a : do not break between numeric prefixes and ideographs, or between ideographs and numeric postfixes
+	if (a == ucd.BreakPR && isIn(b, ucd.BreakID, ucd.BreakEB, ucd.BreakEM)) ||
+		(isIn(a, ucd.BreakID, ucd.BreakEB, ucd.BreakEM) && b == ucd.BreakPO) {
+		return NoBreak
+	}
+	// LB24 : do not break between numeric prefix/postfix and letters
+	if (isIn(a, ucd.BreakPR, ucd.BreakPO) && isIn(b, ucd.BreakAL, ucd.BreakHL)) ||
+		(isIn(a, ucd.BreakAL, ucd.BreakHL) && isIn(b, ucd.BreakPR, ucd.BreakPO)) {
+		return NoBreak
+	}
+	// LB25 : do not break between the following pairs of classes relevant to numbers
+	if (isIn(a, ucd.BreakCL, ucd.BreakCP, ucd.BreakNU) && isIn(b, ucd.BreakPO, ucd.BreakPR)) ||
+		(isIn(a, ucd.BreakPO, ucd.BreakPR) && isIn(b, ucd.BreakOP, ucd.BreakNU)) ||
+		(isIn(a, ucd.BreakHY, ucd.BreakIS, ucd.BreakNU, ucd.BreakSY) && b == ucd.BreakNU) {
+		return NoBreak
+	}
+	// LB26 : do not break a Korean syllable
+	if (a == ucd.BreakJL && isIn(b, ucd.BreakJL, ucd.BreakJV, ucd.BreakH2, ucd.BreakH3)) ||
+		(isIn(a, ucd.BreakJV, ucd.BreakH2) && isIn(b, ucd.BreakJV, ucd.BreakJT)) ||
+		(isIn(a, ucd.BreakJT, ucd.BreakH3) && b == ucd.BreakJT) {
+		return NoBreak
+	}
+	// LB27 : treat a Korean Syllable Block the same as ID
+	if (isIn(a, ucd.BreakJL, ucd.BreakJV, ucd.BreakJT, ucd.BreakH2, ucd.BreakH3) && b == ucd.BreakPO) ||
+		(a == ucd.BreakPR && isIn(b, ucd.BreakJL, ucd.BreakJV, ucd.BreakJT, ucd.BreakH2, ucd.BreakH3)) {
+		return NoBreak
+	}
+	// LB28 : do not break between alphabetics
+	if isIn(a, ucd.BreakAL, ucd.BreakHL) && isIn(b, ucd.BreakAL, ucd.BreakHL) {
+		return NoBreak
+	}
+	// LB29 : do not break between numeric punctuation and alphabetics
+	if a == ucd.BreakIS && isIn(b, ucd.BreakAL, ucd.BreakHL) {
+		return NoBreak
+	}
+	// LB30 : do not break between letters, numbers, or ordinary symbols
+	// and opening or closing parentheses
+	if (isIn(a, ucd.BreakAL, ucd.BreakHL, ucd.BreakNU) && b == ucd.BreakOP && !isEastAsianWide(r)) ||
+		(a == ucd.BreakCP && !isEastAsianWide(lb.prevRune) && isIn(b, ucd.BreakAL, ucd.BreakHL, ucd.BreakNU)) {
+		return NoBreak
+	}
+	// LB30a : break between two regional indicator symbols if and only if there
+	// are an even number of regional indicators preceding the position of the break
+	if a == ucd.BreakRI && b == ucd.BreakRI && lb.riCount%2 == 1 {
+		return NoBreak
+	}
+	// LB30b : do not break between an emoji base (or potential emoji) and an emoji modifier
+	if b == ucd.BreakEM && (a == ucd.BreakEB || (lb.prevIsExt && lb.prevIsCn)) {
+		return NoBreak
+	}
+	// LB31 : break everywhere else
+	return BreakAllowed
+}
+
+// LineBreaks returns the line break opportunities of `text`, following
+// the Unicode Line Breaking Algorithm (UAX #14) without tailoring.
+// The returned slice has length len(text)+1 : its i-th element tells
+// if the line may be broken before text[i]. The first element is always
+// `NoBreak`, and the last one is always `BreakMandatory` (for non empty text).
+func LineBreaks(text []rune) []BreakOpportunity {
+	if len(text) == 0 {
+		return nil
+	}
+	out := make([]BreakOpportunity, len(text)+1)
+	var lb lineBreaker
+	for i, r := range text {
+		out[i] = lb.next(r)
+	}
+	out[len(text)] = BreakMandatory // LB3
+	return out
+}
+
+// Line is a segment of text between two line break opportunities :
+// a line breaker never splits it.
+type Line struct {
+	Offset, Length int // position in the text, in runes
+	// Mandatory is true if the line must be broken after this segment.
+	Mandatory bool
+}
+
+// LineIterator iterates over the segments of a text delimited
+// by line break opportunities, which may be combined with the metrics
+// given by the shaper to choose where to actually break lines.
+//
+// Usage :
+//
+//	iter := NewLineIterator(text)
+//	for iter.Next() {
+//		line := iter.Line()
+//		...
+//	}
+type LineIterator struct {
+	text  []rune
+	lb    lineBreaker
+	line  Line
+	start int // start of the next segment
+	pos   int // index of the next rune to process
+}
+
+// NewLineIterator returns an iterator over the segments of `text`.
+func NewLineIterator(text []rune) *LineIterator {
+	return &LineIterator{text: text}
+}
+
+// Next advances to the next segment, which is then available through `Line`,
+// returning false when the end of the text has been reached.
+func (it *LineIterator) Next() bool {
+	if it.start >= len(it.text) {
+		return false
+	}
+	for it.pos < len(it.text) {
+		index := it.pos
+		opp := it.lb.next(it.text[index])
+		it.pos++
+		if index > it.start && opp != NoBreak {
+			it.line = Line{Offset: it.start, Length: index - it.start, Mandatory: opp == BreakMandatory}
+			it.start = index
+			return true
+		}
+	}
+	// LB3 : always break at the end of text
+	it.line = Line{Offset: it.start, Length: len(it.text) - it.start, Mandatory: true}
+	it.start = len(it.text)
+	return true
+}
+
+// Line returns the current segment.
+func (it *LineIterator) Line() Line { return it.line }
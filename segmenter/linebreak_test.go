@@ -0,0 +1,219 @@
+package segmenter
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// breaks returns the positions of the break opportunities, excluding the end of text
+func breaks(text string) (allowed, mandatory []int) {
+	opps := LineBreaks([]rune(text))
+	for i, opp := range opps[:len(opps)-1] {
+		switch opp {
+		case BreakAllowed:
+			allowed = append(allowed, i)
+		case BreakMandatory:
+			mandatory = append(mandatory, i)
+		}
+	}
+	return allowed, mandatory
+}
+
+func TestLineBreaks(t *testing.T) {
+	if LineBreaks(nil) != nil {
+		t.Fatal("expected no break for empty text")
+	}
+
+	for _, test := range []struct {
+		text      string
+		allowed   []int
+		mandatory []int
+	}{
+		{"Hello", nil, nil},
+		{"Hello world", []int{6}, nil},
+		{"Hello   world", []int{8}, nil},
+		{"a\nb", nil, []int{2}},
+		{"a\r\nb", nil, []int{3}},
+		{"(abc) def", []int{6}, nil},
+		{"1,234.5 km", []int{8}, nil},
+		{"$100 is 50%", []int{5, 8}, nil},
+		{"a\u00a0b", nil, nil},                   // no break around NBSP
+		{"a-b", []int{2}, nil},                   // break after hyphens
+		{"a\u0301b", nil, nil},                   // combining mark
+		{"中文字", []int{1, 2}, nil},                // ideographs
+		{"中。文", []int{2}, nil},                   // no break before a closing punctuation
+		{"a\u200bb", []int{2}, nil},              // zero width space
+		{"a\u2060b", nil, nil},                   // word joiner
+		{"\"Hi\" you", []int{5}, nil},            // quotation marks
+		{"🇫🇷🇩🇪", []int{2}, nil},                  // regional indicators pairs
+		{"👍🏽 ok", []int{3}, nil},                 // emoji modifier
+		{"\U0001F468\u200d\U0001F469", nil, nil}, // zero width joiner
+		{"(  a", nil, nil},                       // after opening punctuation, even with spaces
+		{"x)(y", []int{2}, nil},                  // parenthesis
+	} {
+		allowed, mandatory := breaks(test.text)
+		if !reflect.DeepEqual(allowed, test.allowed) || !reflect.DeepEqual(mandatory, test.mandatory) {
+			t.Errorf("for %q, expected %v %v, got %v %v", test.text, test.allowed, test.mandatory, allowed, mandatory)
+		}
+	}
+}
+
+func TestLineIterator(t *testing.T) {
+	text := []rune("Hello world\nNext line")
+	var got []Line
+	iter := NewLineIterator(text)
+	for iter.Next() {
+		got = append(got, iter.Line())
+	}
+	expected := []Line{
+		{0, 6, false},
+		{6, 6, true},
+		{12, 5, false},
+		{17, 4, true},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	if NewLineIterator(nil).Next() {
+		t.Fatal("expected no line for empty text")
+	}
+
+	// the iterator and LineBreaks agree
+	text = []rune("Les «guillemets» (et les parenthèses) — 12,5 % — 中文。\r\nFin")
+	opps := LineBreaks(text)
+	iter = NewLineIterator(text)
+	for iter.Next() {
+		line := iter.Line()
+		end := line.Offset + line.Length
+		if (opps[end] == BreakMandatory) != line.Mandatory || opps[end] == NoBreak {
+			t.Fatalf("unexpected line %v", line)
+		}
+		for i := line.Offset + 1; i < end; i++ {
+			if opps[i] != NoBreak {
+				t.Fatalf("unexpected break at %d inside line %v", i, line)
+			}
+		}
+	}
+}
+
+// parseLineBreakTest parses a line of LineBreakTest.txt, returning
+// the text and the expected break opportunities, or nil for comments.
+func parseLineBreakTest(line string) ([]rune, []BreakOpportunity, error) {
+	if i := strings.IndexByte(line, '#'); i != -1 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, nil, nil
+	}
+	var (
+		text []rune
+		opps []BreakOpportunity
+	)
+	for _, field := range fields {
+		switch field {
+		case "\u00f7": // division sign
+			opps = append(opps, BreakAllowed)
+		case "\u00d7": // multiplication sign
+			opps = append(opps, NoBreak)
+		default:
+			r, err := strconv.ParseUint(field, 16, 32)
+			if err != nil {
+				return nil, nil, err
+			}
+			text = append(text, rune(r))
+		}
+	}
+	if len(opps) != len(text)+1 {
+		return nil, nil, fmt.Errorf("invalid test case %s", line)
+	}
+	return text, opps, nil
+}
+
+// lineBreakTestSkipped are the test cases of LineBreakTest.txt which
+// rely on the tailoring of LB25 described in the Example 7 of the Section 8.2
+// of UAX #14, which matches numbers as a whole, while LineBreaks
+// only applies the default pair rules.
+var lineBreakTestSkipped = map[string]bool{
+	// CL, CP ÷ PO, PR : the closing punctuation does not follow a number
+	"007D \u00f7 0025":             true,
+	"007D \u00d7 0308 \u00f7 0025": true,
+	"007D \u00f7 0024":             true,
+	"007D \u00d7 0308 \u00f7 0024": true,
+	"0029 \u00f7 0025":             true,
+	"0029 \u00d7 0308 \u00f7 0025": true,
+	"0029 \u00f7 0024":             true,
+	"0029 \u00d7 0308 \u00f7 0024": true,
+	// PO, PR ÷ OP : the opening punctuation is not followed by a number
+	"0025 \u00f7 2329":             true,
+	"0025 \u00d7 0308 \u00f7 2329": true,
+	"0025 \u00f7 0028":             true,
+	"0025 \u00d7 0308 \u00f7 0028": true,
+	"0024 \u00f7 2329":             true,
+	"0024 \u00d7 0308 \u00f7 2329": true,
+	"0024 \u00f7 0028":             true,
+	"0024 \u00d7 0308 \u00f7 0028": true,
+	// IS, SY ÷ NU : the punctuation does not follow a number
+	"002C \u00f7 0030":             true,
+	"002C \u00d7 0308 \u00f7 0030": true,
+	"002F \u00f7 0030":             true,
+	"002F \u00d7 0308 \u00f7 0030": true,
+	"0065 \u00d7 0071 \u00d7 0075 \u00d7 0061 \u00d7 006C \u00d7 0073 \u00d7 0020 \u00d7 002E \u00f7 0033 \u00d7 0035 \u00d7 0020 \u00f7 0063 \u00d7 0065 \u00d7 006E \u00d7 0074 \u00d7 0073": true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 0020":                                                             true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 0020 \u00f7 0915":                                                 true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 0020 \u00f7 672C":                                                 true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 3000 \u00f7 672C":                                                 true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 3000 \u00f7 307E":                                                 true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 3000 \u00f7 0033":                                                 true,
+	"0061 \u00d7 002E \u00f7 0032 \u00d7 3000 \u00f7 300C":                                                 true,
+	"0041 \u00d7 002E \u00f7 0031 \u00d7 0020 \u00f7 BABB":                                                 true,
+	"BD24 \u00f7 C5B4 \u00d7 002E \u00d7 0020 \u00f7 0041 \u00d7 002E \u00f7 0032 \u00d7 0020 \u00f7 BCFC": true,
+	"BD10 \u00f7 C694 \u00d7 002E \u00d7 0020 \u00f7 0041 \u00d7 002E \u00f7 0033 \u00d7 0020 \u00f7 BABB": true,
+	"C694 \u00d7 002E \u00d7 0020 \u00f7 0041 \u00d7 002E \u00f7 0034 \u00d7 0020 \u00f7 BABB":             true,
+	// PR ÷ OP, in code snippets
+	"0063 \u00d7 006F \u00d7 0064 \u00d7 0065 \u00d7 005C \u00f7 0028 \u00d7 0073 \u00d7 005C \u00d7 0029": true,
+	"0063 \u00d7 006F \u00d7 0064 \u00d7 0065 \u00d7 005C \u00f7 007B \u00d7 0073 \u00d7 005C \u00d7 007D": true,
+}
+
+// TestLineBreakConformance runs the test cases of LineBreakTest.txt
+// (downloaded by the generate package of unicodedata).
+func TestLineBreakConformance(t *testing.T) {
+	b, err := os.ReadFile("testdata/LineBreakTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tested int
+	for i, line := range strings.Split(string(b), "\n") {
+		text, expected, err := parseLineBreakTest(line)
+		if err != nil {
+			t.Fatalf("line %d: %s", i+1, err)
+		}
+		if text == nil {
+			continue
+		}
+		// the skip list omits the leading and trailing break opportunities
+		fields := strings.Fields(strings.Split(line, "#")[0])
+		if lineBreakTestSkipped[strings.Join(fields[1:len(fields)-1], " ")] {
+			continue
+		}
+		tested++
+		got := LineBreaks(text)
+		// the mandatory breaks are not distinguished in the test file
+		for j, opp := range got {
+			if opp == BreakMandatory {
+				got[j] = BreakAllowed
+			}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("line %d: expected %v, got %v (%s)", i+1, expected, got, line)
+		}
+	}
+	if tested == 0 {
+		t.Fatal("no test case found")
+	}
+}
@@ -0,0 +1,126 @@
+package unicodedata
+
+// Code generated by generate/main.go DO NOT EDIT.
+
+var bidiBrackets = map[rune]bidiBracket{ // 120 entries
+	0x0028: {0x0029, BracketOpen},
+	0x0029: {0x0028, BracketClose},
+	0x005b: {0x005d, BracketOpen},
+	0x005d: {0x005b, BracketClose},
+	0x007b: {0x007d, BracketOpen},
+	0x007d: {0x007b, BracketClose},
+	0x0f3a: {0x0f3b, BracketOpen},
+	0x0f3b: {0x0f3a, BracketClose},
+	0x0f3c: {0x0f3d, BracketOpen},
+	0x0f3d: {0x0f3c, BracketClose},
+	0x169b: {0x169c, BracketOpen},
+	0x169c: {0x169b, BracketClose},
+	0x2045: {0x2046, BracketOpen},
+	0x2046: {0x2045, BracketClose},
+	0x207d: {0x207e, BracketOpen},
+	0x207e: {0x207d, BracketClose},
+	0x208d: {0x208e, BracketOpen},
+	0x208e: {0x208d, BracketClose},
+	0x2308: {0x2309, BracketOpen},
+	0x2309: {0x2308, BracketClose},
+	0x230a: {0x230b, BracketOpen},
+	0x230b: {0x230a, BracketClose},
+	0x2329: {0x232a, BracketOpen},
+	0x232a: {0x2329, BracketClose},
+	0x2768: {0x2769, BracketOpen},
+	0x2769: {0x2768, BracketClose},
+	0x276a: {0x276b, BracketOpen},
+	0x276b: {0x276a, BracketClose},
+	0x276c: {0x276d, BracketOpen},
+	0x276d: {0x276c, BracketClose},
+	0x276e: {0x276f, BracketOpen},
+	0x276f: {0x276e, BracketClose},
+	0x2770: {0x2771, BracketOpen},
+	0x2771: {0x2770, BracketClose},
+	0x2772: {0x2773, BracketOpen},
+	0x2773: {0x2772, BracketClose},
+	0x2774: {0x2775, BracketOpen},
+	0x2775: {0x2774, BracketClose},
+	0x27c5: {0x27c6, BracketOpen},
+	0x27c6: {0x27c5, BracketClose},
+	0x27e6: {0x27e7, BracketOpen},
+	0x27e7: {0x27e6, BracketClose},
+	0x27e8: {0x27e9, BracketOpen},
+	0x27e9: {0x27e8, BracketClose},
+	0x27ea: {0x27eb, BracketOpen},
+	0x27eb: {0x27ea, BracketClose},
+	0x27ec: {0x27ed, BracketOpen},
+	0x27ed: {0x27ec, BracketClose},
+	0x27ee: {0x27ef, BracketOpen},
+	0x27ef: {0x27ee, BracketClose},
+	0x2983: {0x2984, BracketOpen},
+	0x2984: {0x2983, BracketClose},
+	0x2985: {0x2986, BracketOpen},
+	0x2986: {0x2985, BracketClose},
+	0x2987: {0x2988, BracketOpen},
+	0x2988: {0x2987, BracketClose},
+	0x2989: {0x298a, BracketOpen},
+	0x298a: {0x2989, BracketClose},
+	0x298b: {0x298c, BracketOpen},
+	0x298c: {0x298b, BracketClose},
+	0x298d: {0x2990, BracketOpen},
+	0x298e: {0x298f, BracketClose},
+	0x298f: {0x298e, BracketOpen},
+	0x2990: {0x298d, BracketClose},
+	0x2991: {0x2992, BracketOpen},
+	0x2992: {0x2991, BracketClose},
+	0x2993: {0x2994, BracketOpen},
+	0x2994: {0x2993, BracketClose},
+	0x2995: {0x2996, BracketOpen},
+	0x2996: {0x2995, BracketClose},
+	0x2997: {0x2998, BracketOpen},
+	0x2998: {0x2997, BracketClose},
+	0x29d8: {0x29d9, BracketOpen},
+	0x29d9: {0x29d8, BracketClose},
+	0x29da: {0x29db, BracketOpen},
+	0x29db: {0x29da, BracketClose},
+	0x29fc: {0x29fd, BracketOpen},
+	0x29fd: {0x29fc, BracketClose},
+	0x2e22: {0x2e23, BracketOpen},
+	0x2e23: {0x2e22, BracketClose},
+	0x2e24: {0x2e25, BracketOpen},
+	0x2e25: {0x2e24, BracketClose},
+	0x2e26: {0x2e27, BracketOpen},
+	0x2e27: {0x2e26, BracketClose},
+	0x2e28: {0x2e29, BracketOpen},
+	0x2e29: {0x2e28, BracketClose},
+	0x3008: {0x3009, BracketOpen},
+	0x3009: {0x3008, BracketClose},
+	0x300a: {0x300b, BracketOpen},
+	0x300b: {0x300a, BracketClose},
+	0x300c: {0x300d, BracketOpen},
+	0x300d: {0x300c, BracketClose},
+	0x300e: {0x300f, BracketOpen},
+	0x300f: {0x300e, BracketClose},
+	0x3010: {0x3011, BracketOpen},
+	0x3011: {0x3010, BracketClose},
+	0x3014: {0x3015, BracketOpen},
+	0x3015: {0x3014, BracketClose},
+	0x3016: {0x3017, BracketOpen},
+	0x3017: {0x3016, BracketClose},
+	0x3018: {0x3019, BracketOpen},
+	0x3019: {0x3018, BracketClose},
+	0x301a: {0x301b, BracketOpen},
+	0x301b: {0x301a, BracketClose},
+	0xfe59: {0xfe5a, BracketOpen},
+	0xfe5a: {0xfe59, BracketClose},
+	0xfe5b: {0xfe5c, BracketOpen},
+	0xfe5c: {0xfe5b, BracketClose},
+	0xfe5d: {0xfe5e, BracketOpen},
+	0xfe5e: {0xfe5d, BracketClose},
+	0xff08: {0xff09, BracketOpen},
+	0xff09: {0xff08, BracketClose},
+	0xff3b: {0xff3d, BracketOpen},
+	0xff3d: {0xff3b, BracketClose},
+	0xff5b: {0xff5d, BracketOpen},
+	0xff5d: {0xff5b, BracketClose},
+	0xff5f: {0xff60, BracketOpen},
+	0xff60: {0xff5f, BracketClose},
+	0xff62: {0xff63, BracketOpen},
+	0xff63: {0xff62, BracketClose},
+}
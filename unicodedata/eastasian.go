@@ -0,0 +1,494 @@
+package unicodedata
+
+// Code generated by generate/main.go DO NOT EDIT.
+
+var eastAsianWidthRanges = [...]propertyRange{
+	{start: 0x0020, end: 0x007e, value: 5},     // Na
+	{start: 0x00a1, end: 0x00a1, value: 1},     // A
+	{start: 0x00a2, end: 0x00a3, value: 5},     // Na
+	{start: 0x00a4, end: 0x00a4, value: 1},     // A
+	{start: 0x00a5, end: 0x00a6, value: 5},     // Na
+	{start: 0x00a7, end: 0x00a8, value: 1},     // A
+	{start: 0x00aa, end: 0x00aa, value: 1},     // A
+	{start: 0x00ac, end: 0x00ac, value: 5},     // Na
+	{start: 0x00ad, end: 0x00ae, value: 1},     // A
+	{start: 0x00af, end: 0x00af, value: 5},     // Na
+	{start: 0x00b0, end: 0x00b4, value: 1},     // A
+	{start: 0x00b6, end: 0x00ba, value: 1},     // A
+	{start: 0x00bc, end: 0x00bf, value: 1},     // A
+	{start: 0x00c6, end: 0x00c6, value: 1},     // A
+	{start: 0x00d0, end: 0x00d0, value: 1},     // A
+	{start: 0x00d7, end: 0x00d8, value: 1},     // A
+	{start: 0x00de, end: 0x00e1, value: 1},     // A
+	{start: 0x00e6, end: 0x00e6, value: 1},     // A
+	{start: 0x00e8, end: 0x00ea, value: 1},     // A
+	{start: 0x00ec, end: 0x00ed, value: 1},     // A
+	{start: 0x00f0, end: 0x00f0, value: 1},     // A
+	{start: 0x00f2, end: 0x00f3, value: 1},     // A
+	{start: 0x00f7, end: 0x00fa, value: 1},     // A
+	{start: 0x00fc, end: 0x00fc, value: 1},     // A
+	{start: 0x00fe, end: 0x00fe, value: 1},     // A
+	{start: 0x0101, end: 0x0101, value: 1},     // A
+	{start: 0x0111, end: 0x0111, value: 1},     // A
+	{start: 0x0113, end: 0x0113, value: 1},     // A
+	{start: 0x011b, end: 0x011b, value: 1},     // A
+	{start: 0x0126, end: 0x0127, value: 1},     // A
+	{start: 0x012b, end: 0x012b, value: 1},     // A
+	{start: 0x0131, end: 0x0133, value: 1},     // A
+	{start: 0x0138, end: 0x0138, value: 1},     // A
+	{start: 0x013f, end: 0x0142, value: 1},     // A
+	{start: 0x0144, end: 0x0144, value: 1},     // A
+	{start: 0x0148, end: 0x014b, value: 1},     // A
+	{start: 0x014d, end: 0x014d, value: 1},     // A
+	{start: 0x0152, end: 0x0153, value: 1},     // A
+	{start: 0x0166, end: 0x0167, value: 1},     // A
+	{start: 0x016b, end: 0x016b, value: 1},     // A
+	{start: 0x01ce, end: 0x01ce, value: 1},     // A
+	{start: 0x01d0, end: 0x01d0, value: 1},     // A
+	{start: 0x01d2, end: 0x01d2, value: 1},     // A
+	{start: 0x01d4, end: 0x01d4, value: 1},     // A
+	{start: 0x01d6, end: 0x01d6, value: 1},     // A
+	{start: 0x01d8, end: 0x01d8, value: 1},     // A
+	{start: 0x01da, end: 0x01da, value: 1},     // A
+	{start: 0x01dc, end: 0x01dc, value: 1},     // A
+	{start: 0x0251, end: 0x0251, value: 1},     // A
+	{start: 0x0261, end: 0x0261, value: 1},     // A
+	{start: 0x02c4, end: 0x02c4, value: 1},     // A
+	{start: 0x02c7, end: 0x02c7, value: 1},     // A
+	{start: 0x02c9, end: 0x02cb, value: 1},     // A
+	{start: 0x02cd, end: 0x02cd, value: 1},     // A
+	{start: 0x02d0, end: 0x02d0, value: 1},     // A
+	{start: 0x02d8, end: 0x02db, value: 1},     // A
+	{start: 0x02dd, end: 0x02dd, value: 1},     // A
+	{start: 0x02df, end: 0x02df, value: 1},     // A
+	{start: 0x0300, end: 0x036f, value: 1},     // A
+	{start: 0x0391, end: 0x03a1, value: 1},     // A
+	{start: 0x03a3, end: 0x03a9, value: 1},     // A
+	{start: 0x03b1, end: 0x03c1, value: 1},     // A
+	{start: 0x03c3, end: 0x03c9, value: 1},     // A
+	{start: 0x0401, end: 0x0401, value: 1},     // A
+	{start: 0x0410, end: 0x044f, value: 1},     // A
+	{start: 0x0451, end: 0x0451, value: 1},     // A
+	{start: 0x1100, end: 0x115f, value: 3},     // W
+	{start: 0x2010, end: 0x2010, value: 1},     // A
+	{start: 0x2013, end: 0x2016, value: 1},     // A
+	{start: 0x2018, end: 0x2019, value: 1},     // A
+	{start: 0x201c, end: 0x201d, value: 1},     // A
+	{start: 0x2020, end: 0x2022, value: 1},     // A
+	{start: 0x2024, end: 0x2027, value: 1},     // A
+	{start: 0x2030, end: 0x2030, value: 1},     // A
+	{start: 0x2032, end: 0x2033, value: 1},     // A
+	{start: 0x2035, end: 0x2035, value: 1},     // A
+	{start: 0x203b, end: 0x203b, value: 1},     // A
+	{start: 0x203e, end: 0x203e, value: 1},     // A
+	{start: 0x2074, end: 0x2074, value: 1},     // A
+	{start: 0x207f, end: 0x207f, value: 1},     // A
+	{start: 0x2081, end: 0x2084, value: 1},     // A
+	{start: 0x20a9, end: 0x20a9, value: 2},     // H
+	{start: 0x20ac, end: 0x20ac, value: 1},     // A
+	{start: 0x2103, end: 0x2103, value: 1},     // A
+	{start: 0x2105, end: 0x2105, value: 1},     // A
+	{start: 0x2109, end: 0x2109, value: 1},     // A
+	{start: 0x2113, end: 0x2113, value: 1},     // A
+	{start: 0x2116, end: 0x2116, value: 1},     // A
+	{start: 0x2121, end: 0x2122, value: 1},     // A
+	{start: 0x2126, end: 0x2126, value: 1},     // A
+	{start: 0x212b, end: 0x212b, value: 1},     // A
+	{start: 0x2153, end: 0x2154, value: 1},     // A
+	{start: 0x215b, end: 0x215e, value: 1},     // A
+	{start: 0x2160, end: 0x216b, value: 1},     // A
+	{start: 0x2170, end: 0x2179, value: 1},     // A
+	{start: 0x2189, end: 0x2189, value: 1},     // A
+	{start: 0x2190, end: 0x2199, value: 1},     // A
+	{start: 0x21b8, end: 0x21b9, value: 1},     // A
+	{start: 0x21d2, end: 0x21d2, value: 1},     // A
+	{start: 0x21d4, end: 0x21d4, value: 1},     // A
+	{start: 0x21e7, end: 0x21e7, value: 1},     // A
+	{start: 0x2200, end: 0x2200, value: 1},     // A
+	{start: 0x2202, end: 0x2203, value: 1},     // A
+	{start: 0x2207, end: 0x2208, value: 1},     // A
+	{start: 0x220b, end: 0x220b, value: 1},     // A
+	{start: 0x220f, end: 0x220f, value: 1},     // A
+	{start: 0x2211, end: 0x2211, value: 1},     // A
+	{start: 0x2215, end: 0x2215, value: 1},     // A
+	{start: 0x221a, end: 0x221a, value: 1},     // A
+	{start: 0x221d, end: 0x2220, value: 1},     // A
+	{start: 0x2223, end: 0x2223, value: 1},     // A
+	{start: 0x2225, end: 0x2225, value: 1},     // A
+	{start: 0x2227, end: 0x222c, value: 1},     // A
+	{start: 0x222e, end: 0x222e, value: 1},     // A
+	{start: 0x2234, end: 0x2237, value: 1},     // A
+	{start: 0x223c, end: 0x223d, value: 1},     // A
+	{start: 0x2248, end: 0x2248, value: 1},     // A
+	{start: 0x224c, end: 0x224c, value: 1},     // A
+	{start: 0x2252, end: 0x2252, value: 1},     // A
+	{start: 0x2260, end: 0x2261, value: 1},     // A
+	{start: 0x2264, end: 0x2267, value: 1},     // A
+	{start: 0x226a, end: 0x226b, value: 1},     // A
+	{start: 0x226e, end: 0x226f, value: 1},     // A
+	{start: 0x2282, end: 0x2283, value: 1},     // A
+	{start: 0x2286, end: 0x2287, value: 1},     // A
+	{start: 0x2295, end: 0x2295, value: 1},     // A
+	{start: 0x2299, end: 0x2299, value: 1},     // A
+	{start: 0x22a5, end: 0x22a5, value: 1},     // A
+	{start: 0x22bf, end: 0x22bf, value: 1},     // A
+	{start: 0x2312, end: 0x2312, value: 1},     // A
+	{start: 0x231a, end: 0x231b, value: 3},     // W
+	{start: 0x2329, end: 0x232a, value: 3},     // W
+	{start: 0x23e9, end: 0x23ec, value: 3},     // W
+	{start: 0x23f0, end: 0x23f0, value: 3},     // W
+	{start: 0x23f3, end: 0x23f3, value: 3},     // W
+	{start: 0x2460, end: 0x24e9, value: 1},     // A
+	{start: 0x24eb, end: 0x254b, value: 1},     // A
+	{start: 0x2550, end: 0x2573, value: 1},     // A
+	{start: 0x2580, end: 0x258f, value: 1},     // A
+	{start: 0x2592, end: 0x2595, value: 1},     // A
+	{start: 0x25a0, end: 0x25a1, value: 1},     // A
+	{start: 0x25a3, end: 0x25a9, value: 1},     // A
+	{start: 0x25b2, end: 0x25b3, value: 1},     // A
+	{start: 0x25b6, end: 0x25b7, value: 1},     // A
+	{start: 0x25bc, end: 0x25bd, value: 1},     // A
+	{start: 0x25c0, end: 0x25c1, value: 1},     // A
+	{start: 0x25c6, end: 0x25c8, value: 1},     // A
+	{start: 0x25cb, end: 0x25cb, value: 1},     // A
+	{start: 0x25ce, end: 0x25d1, value: 1},     // A
+	{start: 0x25e2, end: 0x25e5, value: 1},     // A
+	{start: 0x25ef, end: 0x25ef, value: 1},     // A
+	{start: 0x25fd, end: 0x25fe, value: 3},     // W
+	{start: 0x2605, end: 0x2606, value: 1},     // A
+	{start: 0x2609, end: 0x2609, value: 1},     // A
+	{start: 0x260e, end: 0x260f, value: 1},     // A
+	{start: 0x2614, end: 0x2615, value: 3},     // W
+	{start: 0x261c, end: 0x261c, value: 1},     // A
+	{start: 0x261e, end: 0x261e, value: 1},     // A
+	{start: 0x2640, end: 0x2640, value: 1},     // A
+	{start: 0x2642, end: 0x2642, value: 1},     // A
+	{start: 0x2648, end: 0x2653, value: 3},     // W
+	{start: 0x2660, end: 0x2661, value: 1},     // A
+	{start: 0x2663, end: 0x2665, value: 1},     // A
+	{start: 0x2667, end: 0x266a, value: 1},     // A
+	{start: 0x266c, end: 0x266d, value: 1},     // A
+	{start: 0x266f, end: 0x266f, value: 1},     // A
+	{start: 0x267f, end: 0x267f, value: 3},     // W
+	{start: 0x2693, end: 0x2693, value: 3},     // W
+	{start: 0x269e, end: 0x269f, value: 1},     // A
+	{start: 0x26a1, end: 0x26a1, value: 3},     // W
+	{start: 0x26aa, end: 0x26ab, value: 3},     // W
+	{start: 0x26bd, end: 0x26be, value: 3},     // W
+	{start: 0x26bf, end: 0x26bf, value: 1},     // A
+	{start: 0x26c4, end: 0x26c5, value: 3},     // W
+	{start: 0x26c6, end: 0x26cd, value: 1},     // A
+	{start: 0x26ce, end: 0x26ce, value: 3},     // W
+	{start: 0x26cf, end: 0x26d3, value: 1},     // A
+	{start: 0x26d4, end: 0x26d4, value: 3},     // W
+	{start: 0x26d5, end: 0x26e1, value: 1},     // A
+	{start: 0x26e3, end: 0x26e3, value: 1},     // A
+	{start: 0x26e8, end: 0x26e9, value: 1},     // A
+	{start: 0x26ea, end: 0x26ea, value: 3},     // W
+	{start: 0x26eb, end: 0x26f1, value: 1},     // A
+	{start: 0x26f2, end: 0x26f3, value: 3},     // W
+	{start: 0x26f4, end: 0x26f4, value: 1},     // A
+	{start: 0x26f5, end: 0x26f5, value: 3},     // W
+	{start: 0x26f6, end: 0x26f9, value: 1},     // A
+	{start: 0x26fa, end: 0x26fa, value: 3},     // W
+	{start: 0x26fb, end: 0x26fc, value: 1},     // A
+	{start: 0x26fd, end: 0x26fd, value: 3},     // W
+	{start: 0x26fe, end: 0x26ff, value: 1},     // A
+	{start: 0x2705, end: 0x2705, value: 3},     // W
+	{start: 0x270a, end: 0x270b, value: 3},     // W
+	{start: 0x2728, end: 0x2728, value: 3},     // W
+	{start: 0x273d, end: 0x273d, value: 1},     // A
+	{start: 0x274c, end: 0x274c, value: 3},     // W
+	{start: 0x274e, end: 0x274e, value: 3},     // W
+	{start: 0x2753, end: 0x2755, value: 3},     // W
+	{start: 0x2757, end: 0x2757, value: 3},     // W
+	{start: 0x2776, end: 0x277f, value: 1},     // A
+	{start: 0x2795, end: 0x2797, value: 3},     // W
+	{start: 0x27b0, end: 0x27b0, value: 3},     // W
+	{start: 0x27bf, end: 0x27bf, value: 3},     // W
+	{start: 0x27e6, end: 0x27ed, value: 5},     // Na
+	{start: 0x2985, end: 0x2986, value: 5},     // Na
+	{start: 0x2b1b, end: 0x2b1c, value: 3},     // W
+	{start: 0x2b50, end: 0x2b50, value: 3},     // W
+	{start: 0x2b55, end: 0x2b55, value: 3},     // W
+	{start: 0x2b56, end: 0x2b59, value: 1},     // A
+	{start: 0x2e80, end: 0x2e99, value: 3},     // W
+	{start: 0x2e9b, end: 0x2ef3, value: 3},     // W
+	{start: 0x2f00, end: 0x2fd5, value: 3},     // W
+	{start: 0x2ff0, end: 0x2ffb, value: 3},     // W
+	{start: 0x3000, end: 0x3000, value: 4},     // F
+	{start: 0x3001, end: 0x303e, value: 3},     // W
+	{start: 0x3041, end: 0x3096, value: 3},     // W
+	{start: 0x3099, end: 0x30ff, value: 3},     // W
+	{start: 0x3105, end: 0x312f, value: 3},     // W
+	{start: 0x3131, end: 0x318e, value: 3},     // W
+	{start: 0x3190, end: 0x31e3, value: 3},     // W
+	{start: 0x31f0, end: 0x321e, value: 3},     // W
+	{start: 0x3220, end: 0x3247, value: 3},     // W
+	{start: 0x3248, end: 0x324f, value: 1},     // A
+	{start: 0x3250, end: 0x4dbf, value: 3},     // W
+	{start: 0x4e00, end: 0xa48c, value: 3},     // W
+	{start: 0xa490, end: 0xa4c6, value: 3},     // W
+	{start: 0xa960, end: 0xa97c, value: 3},     // W
+	{start: 0xac00, end: 0xd7a3, value: 3},     // W
+	{start: 0xe000, end: 0xf8ff, value: 1},     // A
+	{start: 0xf900, end: 0xfaff, value: 3},     // W
+	{start: 0xfe00, end: 0xfe0f, value: 1},     // A
+	{start: 0xfe10, end: 0xfe19, value: 3},     // W
+	{start: 0xfe30, end: 0xfe52, value: 3},     // W
+	{start: 0xfe54, end: 0xfe66, value: 3},     // W
+	{start: 0xfe68, end: 0xfe6b, value: 3},     // W
+	{start: 0xff01, end: 0xff60, value: 4},     // F
+	{start: 0xff61, end: 0xffbe, value: 2},     // H
+	{start: 0xffc2, end: 0xffc7, value: 2},     // H
+	{start: 0xffca, end: 0xffcf, value: 2},     // H
+	{start: 0xffd2, end: 0xffd7, value: 2},     // H
+	{start: 0xffda, end: 0xffdc, value: 2},     // H
+	{start: 0xffe0, end: 0xffe6, value: 4},     // F
+	{start: 0xffe8, end: 0xffee, value: 2},     // H
+	{start: 0xfffd, end: 0xfffd, value: 1},     // A
+	{start: 0x16fe0, end: 0x16fe4, value: 3},   // W
+	{start: 0x16ff0, end: 0x16ff1, value: 3},   // W
+	{start: 0x17000, end: 0x187f7, value: 3},   // W
+	{start: 0x18800, end: 0x18cd5, value: 3},   // W
+	{start: 0x18d00, end: 0x18d08, value: 3},   // W
+	{start: 0x1b000, end: 0x1b11e, value: 3},   // W
+	{start: 0x1b150, end: 0x1b152, value: 3},   // W
+	{start: 0x1b164, end: 0x1b167, value: 3},   // W
+	{start: 0x1b170, end: 0x1b2fb, value: 3},   // W
+	{start: 0x1f004, end: 0x1f004, value: 3},   // W
+	{start: 0x1f0cf, end: 0x1f0cf, value: 3},   // W
+	{start: 0x1f100, end: 0x1f10a, value: 1},   // A
+	{start: 0x1f110, end: 0x1f12d, value: 1},   // A
+	{start: 0x1f130, end: 0x1f169, value: 1},   // A
+	{start: 0x1f170, end: 0x1f18d, value: 1},   // A
+	{start: 0x1f18e, end: 0x1f18e, value: 3},   // W
+	{start: 0x1f18f, end: 0x1f190, value: 1},   // A
+	{start: 0x1f191, end: 0x1f19a, value: 3},   // W
+	{start: 0x1f19b, end: 0x1f1ac, value: 1},   // A
+	{start: 0x1f200, end: 0x1f202, value: 3},   // W
+	{start: 0x1f210, end: 0x1f23b, value: 3},   // W
+	{start: 0x1f240, end: 0x1f248, value: 3},   // W
+	{start: 0x1f250, end: 0x1f251, value: 3},   // W
+	{start: 0x1f260, end: 0x1f265, value: 3},   // W
+	{start: 0x1f300, end: 0x1f320, value: 3},   // W
+	{start: 0x1f32d, end: 0x1f335, value: 3},   // W
+	{start: 0x1f337, end: 0x1f37c, value: 3},   // W
+	{start: 0x1f37e, end: 0x1f393, value: 3},   // W
+	{start: 0x1f3a0, end: 0x1f3ca, value: 3},   // W
+	{start: 0x1f3cf, end: 0x1f3d3, value: 3},   // W
+	{start: 0x1f3e0, end: 0x1f3f0, value: 3},   // W
+	{start: 0x1f3f4, end: 0x1f3f4, value: 3},   // W
+	{start: 0x1f3f8, end: 0x1f43e, value: 3},   // W
+	{start: 0x1f440, end: 0x1f440, value: 3},   // W
+	{start: 0x1f442, end: 0x1f4fc, value: 3},   // W
+	{start: 0x1f4ff, end: 0x1f53d, value: 3},   // W
+	{start: 0x1f54b, end: 0x1f54e, value: 3},   // W
+	{start: 0x1f550, end: 0x1f567, value: 3},   // W
+	{start: 0x1f57a, end: 0x1f57a, value: 3},   // W
+	{start: 0x1f595, end: 0x1f596, value: 3},   // W
+	{start: 0x1f5a4, end: 0x1f5a4, value: 3},   // W
+	{start: 0x1f5fb, end: 0x1f64f, value: 3},   // W
+	{start: 0x1f680, end: 0x1f6c5, value: 3},   // W
+	{start: 0x1f6cc, end: 0x1f6cc, value: 3},   // W
+	{start: 0x1f6d0, end: 0x1f6d2, value: 3},   // W
+	{start: 0x1f6d5, end: 0x1f6d7, value: 3},   // W
+	{start: 0x1f6eb, end: 0x1f6ec, value: 3},   // W
+	{start: 0x1f6f4, end: 0x1f6fc, value: 3},   // W
+	{start: 0x1f7e0, end: 0x1f7eb, value: 3},   // W
+	{start: 0x1f90c, end: 0x1f93a, value: 3},   // W
+	{start: 0x1f93c, end: 0x1f945, value: 3},   // W
+	{start: 0x1f947, end: 0x1f978, value: 3},   // W
+	{start: 0x1f97a, end: 0x1f9cb, value: 3},   // W
+	{start: 0x1f9cd, end: 0x1f9ff, value: 3},   // W
+	{start: 0x1fa70, end: 0x1fa74, value: 3},   // W
+	{start: 0x1fa78, end: 0x1fa7a, value: 3},   // W
+	{start: 0x1fa80, end: 0x1fa86, value: 3},   // W
+	{start: 0x1fa90, end: 0x1faa8, value: 3},   // W
+	{start: 0x1fab0, end: 0x1fab6, value: 3},   // W
+	{start: 0x1fac0, end: 0x1fac2, value: 3},   // W
+	{start: 0x1fad0, end: 0x1fad6, value: 3},   // W
+	{start: 0x20000, end: 0x2fffd, value: 3},   // W
+	{start: 0x30000, end: 0x3fffd, value: 3},   // W
+	{start: 0xe0100, end: 0xe01ef, value: 1},   // A
+	{start: 0xf0000, end: 0xffffd, value: 1},   // A
+	{start: 0x100000, end: 0x10fffd, value: 1}, // A
+}
+
+var verticalOrientationRanges = [...]propertyRange{
+	{start: 0x00a7, end: 0x00a7, value: 1},     // U
+	{start: 0x00a9, end: 0x00a9, value: 1},     // U
+	{start: 0x00ae, end: 0x00ae, value: 1},     // U
+	{start: 0x00b1, end: 0x00b1, value: 1},     // U
+	{start: 0x00bc, end: 0x00be, value: 1},     // U
+	{start: 0x00d7, end: 0x00d7, value: 1},     // U
+	{start: 0x00f7, end: 0x00f7, value: 1},     // U
+	{start: 0x02ea, end: 0x02eb, value: 1},     // U
+	{start: 0x1100, end: 0x11ff, value: 1},     // U
+	{start: 0x1401, end: 0x167f, value: 1},     // U
+	{start: 0x18b0, end: 0x18ff, value: 1},     // U
+	{start: 0x2016, end: 0x2016, value: 1},     // U
+	{start: 0x2020, end: 0x2021, value: 1},     // U
+	{start: 0x2030, end: 0x2031, value: 1},     // U
+	{start: 0x203b, end: 0x203c, value: 1},     // U
+	{start: 0x2042, end: 0x2042, value: 1},     // U
+	{start: 0x2047, end: 0x2049, value: 1},     // U
+	{start: 0x2051, end: 0x2051, value: 1},     // U
+	{start: 0x2065, end: 0x2065, value: 1},     // U
+	{start: 0x20dd, end: 0x20e0, value: 1},     // U
+	{start: 0x20e2, end: 0x20e4, value: 1},     // U
+	{start: 0x2100, end: 0x2101, value: 1},     // U
+	{start: 0x2103, end: 0x2109, value: 1},     // U
+	{start: 0x210f, end: 0x210f, value: 1},     // U
+	{start: 0x2113, end: 0x2114, value: 1},     // U
+	{start: 0x2116, end: 0x2117, value: 1},     // U
+	{start: 0x211e, end: 0x2123, value: 1},     // U
+	{start: 0x2125, end: 0x2125, value: 1},     // U
+	{start: 0x2127, end: 0x2127, value: 1},     // U
+	{start: 0x2129, end: 0x2129, value: 1},     // U
+	{start: 0x212e, end: 0x212e, value: 1},     // U
+	{start: 0x2135, end: 0x213f, value: 1},     // U
+	{start: 0x2145, end: 0x214a, value: 1},     // U
+	{start: 0x214c, end: 0x214d, value: 1},     // U
+	{start: 0x214f, end: 0x2189, value: 1},     // U
+	{start: 0x218c, end: 0x218f, value: 1},     // U
+	{start: 0x221e, end: 0x221e, value: 1},     // U
+	{start: 0x2234, end: 0x2235, value: 1},     // U
+	{start: 0x2300, end: 0x2307, value: 1},     // U
+	{start: 0x230c, end: 0x231f, value: 1},     // U
+	{start: 0x2324, end: 0x2328, value: 1},     // U
+	{start: 0x2329, end: 0x232a, value: 3},     // Tr
+	{start: 0x232b, end: 0x232b, value: 1},     // U
+	{start: 0x237d, end: 0x239a, value: 1},     // U
+	{start: 0x23be, end: 0x23cd, value: 1},     // U
+	{start: 0x23cf, end: 0x23cf, value: 1},     // U
+	{start: 0x23d1, end: 0x23db, value: 1},     // U
+	{start: 0x23e2, end: 0x2422, value: 1},     // U
+	{start: 0x2424, end: 0x24ff, value: 1},     // U
+	{start: 0x25a0, end: 0x2619, value: 1},     // U
+	{start: 0x2620, end: 0x2767, value: 1},     // U
+	{start: 0x2776, end: 0x2793, value: 1},     // U
+	{start: 0x2b12, end: 0x2b2f, value: 1},     // U
+	{start: 0x2b50, end: 0x2b59, value: 1},     // U
+	{start: 0x2b97, end: 0x2b97, value: 1},     // U
+	{start: 0x2bb8, end: 0x2bd1, value: 1},     // U
+	{start: 0x2bd3, end: 0x2beb, value: 1},     // U
+	{start: 0x2bf0, end: 0x2bff, value: 1},     // U
+	{start: 0x2e50, end: 0x2e51, value: 1},     // U
+	{start: 0x2e80, end: 0x3000, value: 1},     // U
+	{start: 0x3001, end: 0x3002, value: 2},     // Tu
+	{start: 0x3003, end: 0x3007, value: 1},     // U
+	{start: 0x3008, end: 0x3011, value: 3},     // Tr
+	{start: 0x3012, end: 0x3013, value: 1},     // U
+	{start: 0x3014, end: 0x301f, value: 3},     // Tr
+	{start: 0x3020, end: 0x302f, value: 1},     // U
+	{start: 0x3030, end: 0x3030, value: 3},     // Tr
+	{start: 0x3031, end: 0x3040, value: 1},     // U
+	{start: 0x3041, end: 0x3041, value: 2},     // Tu
+	{start: 0x3042, end: 0x3042, value: 1},     // U
+	{start: 0x3043, end: 0x3043, value: 2},     // Tu
+	{start: 0x3044, end: 0x3044, value: 1},     // U
+	{start: 0x3045, end: 0x3045, value: 2},     // Tu
+	{start: 0x3046, end: 0x3046, value: 1},     // U
+	{start: 0x3047, end: 0x3047, value: 2},     // Tu
+	{start: 0x3048, end: 0x3048, value: 1},     // U
+	{start: 0x3049, end: 0x3049, value: 2},     // Tu
+	{start: 0x304a, end: 0x3062, value: 1},     // U
+	{start: 0x3063, end: 0x3063, value: 2},     // Tu
+	{start: 0x3064, end: 0x3082, value: 1},     // U
+	{start: 0x3083, end: 0x3083, value: 2},     // Tu
+	{start: 0x3084, end: 0x3084, value: 1},     // U
+	{start: 0x3085, end: 0x3085, value: 2},     // Tu
+	{start: 0x3086, end: 0x3086, value: 1},     // U
+	{start: 0x3087, end: 0x3087, value: 2},     // Tu
+	{start: 0x3088, end: 0x308d, value: 1},     // U
+	{start: 0x308e, end: 0x308e, value: 2},     // Tu
+	{start: 0x308f, end: 0x3094, value: 1},     // U
+	{start: 0x3095, end: 0x3096, value: 2},     // Tu
+	{start: 0x3097, end: 0x309a, value: 1},     // U
+	{start: 0x309b, end: 0x309c, value: 2},     // Tu
+	{start: 0x309d, end: 0x309f, value: 1},     // U
+	{start: 0x30a0, end: 0x30a0, value: 3},     // Tr
+	{start: 0x30a1, end: 0x30a1, value: 2},     // Tu
+	{start: 0x30a2, end: 0x30a2, value: 1},     // U
+	{start: 0x30a3, end: 0x30a3, value: 2},     // Tu
+	{start: 0x30a4, end: 0x30a4, value: 1},     // U
+	{start: 0x30a5, end: 0x30a5, value: 2},     // Tu
+	{start: 0x30a6, end: 0x30a6, value: 1},     // U
+	{start: 0x30a7, end: 0x30a7, value: 2},     // Tu
+	{start: 0x30a8, end: 0x30a8, value: 1},     // U
+	{start: 0x30a9, end: 0x30a9, value: 2},     // Tu
+	{start: 0x30aa, end: 0x30c2, value: 1},     // U
+	{start: 0x30c3, end: 0x30c3, value: 2},     // Tu
+	{start: 0x30c4, end: 0x30e2, value: 1},     // U
+	{start: 0x30e3, end: 0x30e3, value: 2},     // Tu
+	{start: 0x30e4, end: 0x30e4, value: 1},     // U
+	{start: 0x30e5, end: 0x30e5, value: 2},     // Tu
+	{start: 0x30e6, end: 0x30e6, value: 1},     // U
+	{start: 0x30e7, end: 0x30e7, value: 2},     // Tu
+	{start: 0x30e8, end: 0x30ed, value: 1},     // U
+	{start: 0x30ee, end: 0x30ee, value: 2},     // Tu
+	{start: 0x30ef, end: 0x30f4, value: 1},     // U
+	{start: 0x30f5, end: 0x30f6, value: 2},     // Tu
+	{start: 0x30f7, end: 0x30fb, value: 1},     // U
+	{start: 0x30fc, end: 0x30fc, value: 3},     // Tr
+	{start: 0x30fd, end: 0x3126, value: 1},     // U
+	{start: 0x3127, end: 0x3127, value: 2},     // Tu
+	{start: 0x3128, end: 0x31ef, value: 1},     // U
+	{start: 0x31f0, end: 0x31ff, value: 2},     // Tu
+	{start: 0x3200, end: 0x32fe, value: 1},     // U
+	{start: 0x32ff, end: 0x3357, value: 2},     // Tu
+	{start: 0x3358, end: 0x337a, value: 1},     // U
+	{start: 0x337b, end: 0x337f, value: 2},     // Tu
+	{start: 0x3380, end: 0xa4cf, value: 1},     // U
+	{start: 0xa960, end: 0xa97f, value: 1},     // U
+	{start: 0xac00, end: 0xd7ff, value: 1},     // U
+	{start: 0xe000, end: 0xfaff, value: 1},     // U
+	{start: 0xfe10, end: 0xfe1f, value: 1},     // U
+	{start: 0xfe30, end: 0xfe48, value: 1},     // U
+	{start: 0xfe50, end: 0xfe52, value: 2},     // Tu
+	{start: 0xfe53, end: 0xfe57, value: 1},     // U
+	{start: 0xfe59, end: 0xfe5e, value: 3},     // Tr
+	{start: 0xfe5f, end: 0xfe62, value: 1},     // U
+	{start: 0xfe67, end: 0xfe6f, value: 1},     // U
+	{start: 0xff01, end: 0xff01, value: 2},     // Tu
+	{start: 0xff02, end: 0xff07, value: 1},     // U
+	{start: 0xff08, end: 0xff09, value: 3},     // Tr
+	{start: 0xff0a, end: 0xff0b, value: 1},     // U
+	{start: 0xff0c, end: 0xff0c, value: 2},     // Tu
+	{start: 0xff0e, end: 0xff0e, value: 2},     // Tu
+	{start: 0xff0f, end: 0xff19, value: 1},     // U
+	{start: 0xff1a, end: 0xff1b, value: 3},     // Tr
+	{start: 0xff1f, end: 0xff1f, value: 2},     // Tu
+	{start: 0xff20, end: 0xff3a, value: 1},     // U
+	{start: 0xff3b, end: 0xff3b, value: 3},     // Tr
+	{start: 0xff3c, end: 0xff3c, value: 1},     // U
+	{start: 0xff3d, end: 0xff3d, value: 3},     // Tr
+	{start: 0xff3e, end: 0xff3e, value: 1},     // U
+	{start: 0xff3f, end: 0xff3f, value: 3},     // Tr
+	{start: 0xff40, end: 0xff5a, value: 1},     // U
+	{start: 0xff5b, end: 0xff60, value: 3},     // Tr
+	{start: 0xffe0, end: 0xffe2, value: 1},     // U
+	{start: 0xffe3, end: 0xffe3, value: 3},     // Tr
+	{start: 0xffe4, end: 0xffe7, value: 1},     // U
+	{start: 0xfff0, end: 0xfff8, value: 1},     // U
+	{start: 0xfffc, end: 0xfffd, value: 1},     // U
+	{start: 0x10980, end: 0x1099f, value: 1},   // U
+	{start: 0x11580, end: 0x115ff, value: 1},   // U
+	{start: 0x11a00, end: 0x11aaf, value: 1},   // U
+	{start: 0x13000, end: 0x1343f, value: 1},   // U
+	{start: 0x14400, end: 0x1467f, value: 1},   // U
+	{start: 0x16fe0, end: 0x18d8f, value: 1},   // U
+	{start: 0x1b000, end: 0x1b2ff, value: 1},   // U
+	{start: 0x1d000, end: 0x1d1ff, value: 1},   // U
+	{start: 0x1d2e0, end: 0x1d37f, value: 1},   // U
+	{start: 0x1d800, end: 0x1daaf, value: 1},   // U
+	{start: 0x1f000, end: 0x1f1ff, value: 1},   // U
+	{start: 0x1f200, end: 0x1f201, value: 2},   // Tu
+	{start: 0x1f202, end: 0x1f7ff, value: 1},   // U
+	{start: 0x1f900, end: 0x1faff, value: 1},   // U
+	{start: 0x20000, end: 0x2fffd, value: 1},   // U
+	{start: 0x30000, end: 0x3fffd, value: 1},   // U
+	{start: 0xf0000, end: 0xffffd, value: 1},   // U
+	{start: 0x100000, end: 0x10fffd, value: 1}, // U
+}
@@ -254,6 +254,22 @@ var Emoji_Modifier_Base = &unicode.RangeTable{
 	},
 }
 
+var Emoji_Component = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0023, Hi: 0x002a, Stride: 7},
+		{Lo: 0x0030, Hi: 0x0039, Stride: 1},
+		{Lo: 0x200d, Hi: 0x20e3, Stride: 214},
+		{Lo: 0xfe0f, Hi: 0xfe0f, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f1e6, Hi: 0x1f1ff, Stride: 1},
+		{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1},
+		{Lo: 0x1f9b0, Hi: 0x1f9b3, Stride: 1},
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+	},
+	LatinOffset: 2,
+}
+
 var Extended_Pictographic = &unicode.RangeTable{
 	R16: []unicode.Range16{
 		{Lo: 0x00a9, Hi: 0x00ae, Stride: 5},
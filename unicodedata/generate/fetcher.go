@@ -25,9 +25,14 @@ const (
 	urlLineBreak     = "https://unicode.org/Public/" + version + "/ucd/LineBreak.txt"
 	urlSentenceBreak = "https://unicode.org/Public/" + version + "/ucd/auxiliary/SentenceBreakProperty.txt"
 	urlDerivedCore   = "https://unicode.org/Public/" + version + "/ucd/DerivedCoreProperties.txt"
+	// only used by the tests of the segmenter package
+	urlLineBreakTest = "https://unicode.org/Public/" + version + "/ucd/auxiliary/LineBreakTest.txt"
 )
 
-func fetchData(url string) {
+func fetchData(url string) { fetchDataTo(url, ".") }
+
+// fetchDataTo saves the file in the `dir` directory
+func fetchDataTo(url, dir string) {
 	fmt.Println("Downloading", url, "...")
 	resp, err := http.Get(url)
 	check(err)
@@ -36,7 +41,7 @@ func fetchData(url string) {
 	data, err := io.ReadAll(resp.Body)
 	check(err)
 
-	filename := path.Base(url)
+	filename := path.Join(dir, path.Base(url))
 	err = os.WriteFile(filename, data, os.ModePerm)
 	check(err)
 }
@@ -35,6 +35,7 @@ func main() {
 		fetchData(urlLineBreak)
 		fetchData(urlSentenceBreak)
 		fetchData(urlDerivedCore)
+		fetchDataTo(urlLineBreakTest, "../../segmenter/testdata")
 	}
 
 	// parse
@@ -59,7 +60,7 @@ func main() {
 	mirrors, err := parseMirroring(b)
 	check(err)
 
-	dms, compEx := parseXML("ucd.nounihan.grouped.zip")
+	xmlProps := parseXML("ucd.nounihan.grouped.zip")
 
 	b, err = os.ReadFile("ArabicShaping.txt")
 	check(err)
@@ -124,7 +125,13 @@ func main() {
 	derivedCore, err := parseAnnexTables(b)
 	check(err)
 
+	b, err = os.ReadFile("../../harfbuzz/langs/language-subtag-registry.txt")
+	check(err)
+	subtags, err := parseSubtagRegistry(b)
+	check(err)
+
 	// generate
+	process("../version.go", generateVersion)
 	process("../combining_classes.go", func(w io.Writer) {
 		generateCombiningClasses(combiningClasses, w)
 	})
@@ -138,7 +145,10 @@ func main() {
 		generateMirroring(mirrors, w)
 	})
 	process("../decomposition.go", func(w io.Writer) {
-		generateDecomposition(dms, compEx, w)
+		generateDecomposition(xmlProps.dms, xmlProps.compat, xmlProps.compEx, w)
+	})
+	process("../brackets.go", func(w io.Writer) {
+		generateBidiBrackets(xmlProps.brackets, w)
 	})
 	process("../arabic.go", func(w io.Writer) {
 		generateArabicShaping(joiningTypes, w)
@@ -156,8 +166,11 @@ func main() {
 	process("../linebreak.go", func(w io.Writer) {
 		generateLineBreak(lineBreak, w)
 	})
+	process("../eastasian.go", func(w io.Writer) {
+		generateEastAsian(xmlProps.eastAsianWidth, xmlProps.verticalOrientation, w)
+	})
 	process("../indic.go", func(w io.Writer) {
-		generateIndicCategories(indicS, w)
+		generateIndicCategories(indicS, indicP, w)
 	})
 	process("../sentenceBreak.go", func(w io.Writer) {
 		generateSTermProperty(sentenceBreaks, w)
@@ -165,6 +178,9 @@ func main() {
 	process("../../language/scripts_table.go", func(w io.Writer) {
 		generateScriptLookupTable(scriptsRanges, scriptNames, w)
 	})
+	process("../../language/subtags_table.go", func(w io.Writer) {
+		generateSubtagAliases(subtags, w)
+	})
 	fmt.Println("Done.")
 }
 
@@ -251,6 +251,10 @@ type group struct {
 	Dm        string `xml:"dm,attr"`
 	Dt        string `xml:"dt,attr"`
 	CompEx    string `xml:"Comp_Ex,attr"`
+	Bpt       string `xml:"bpt,attr"`
+	Bpb       string `xml:"bpb,attr"`
+	Ea        string `xml:"ea,attr"`
+	Vo        string `xml:"vo,attr"`
 	Chars     []char `xml:"char"`
 	Reserved  []char `xml:"reserved"`
 	NonChar   []char `xml:"noncharacter"`
@@ -264,9 +268,31 @@ type char struct {
 	Dm      string `xml:"dm,attr"`
 	Dt      string `xml:"dt,attr"`
 	CompEx  string `xml:"Comp_Ex,attr"`
+	Bpt     string `xml:"bpt,attr"`
+	Bpb     string `xml:"bpb,attr"`
+	Ea      string `xml:"ea,attr"`
+	Vo      string `xml:"vo,attr"`
 }
 
-func parseXML(filename string) (map[rune][]rune, map[rune]bool) {
+// bidiBracket is a paired bracket, as defined in BidiBrackets.txt
+type bidiBracket struct {
+	pair      rune
+	isOpening bool
+}
+
+// ucdProperties stores the properties read from the XML version of the UCD
+type ucdProperties struct {
+	dms      map[rune][]rune // canonical decompositions
+	compat   map[rune][]rune // compatibility decompositions
+	compEx   map[rune]bool   // composition exclusions
+	brackets map[rune]bidiBracket
+
+	// East_Asian_Width and Vertical_Orientation, as abbreviations,
+	// not including the runes with the default values N and R
+	eastAsianWidth, verticalOrientation map[rune]string
+}
+
+func parseXML(filename string) ucdProperties {
 	f, err := zip.OpenReader(filename)
 	check(err)
 	if len(f.File) != 1 {
@@ -288,9 +314,58 @@ func parseXML(filename string) (map[rune][]rune, map[rune]bool) {
 	}
 
 	dms := map[rune][]rune{}
+	compat := map[rune][]rune{}
 	compEx := map[rune]bool{}
+	brackets := map[rune]bidiBracket{}
+	eastAsianWidth := map[rune]string{}
+	verticalOrientation := map[rune]string{}
 	handleRunes := func(l []char, gr group) {
 		for _, ch := range l {
+			if ch.Ea == "" {
+				ch.Ea = gr.Ea
+			}
+			if ch.Ea == "" {
+				ch.Ea = "N"
+			}
+			if ch.Vo == "" {
+				ch.Vo = gr.Vo
+			}
+			if ch.Vo == "" {
+				ch.Vo = "R"
+			}
+			if ch.Ea != "N" || ch.Vo != "R" {
+				first, last := ch.Cp, ch.Cp
+				if ch.Cp == "" {
+					first, last = ch.FirstCp, ch.LastCp
+				}
+				firstRune, err := strconv.ParseInt(first, 16, 32)
+				check(err)
+				lastRune, err := strconv.ParseInt(last, 16, 32)
+				check(err)
+				for ru := rune(firstRune); ru <= rune(lastRune); ru++ {
+					if ch.Ea != "N" {
+						eastAsianWidth[ru] = ch.Ea
+					}
+					if ch.Vo != "R" {
+						verticalOrientation[ru] = ch.Vo
+					}
+				}
+			}
+
+			if ch.Bpt == "" {
+				ch.Bpt = gr.Bpt
+			}
+			if ch.Bpb == "" {
+				ch.Bpb = gr.Bpb
+			}
+			if (ch.Bpt == "o" || ch.Bpt == "c") && ch.Cp != "" {
+				ru, err := strconv.ParseInt(ch.Cp, 16, 32)
+				check(err)
+				pair, err := strconv.ParseInt(ch.Bpb, 16, 32)
+				check(err)
+				brackets[rune(ru)] = bidiBracket{pair: rune(pair), isOpening: ch.Bpt == "o"}
+			}
+
 			if ch.Dm == "" {
 				ch.Dm = gr.Dm
 			}
@@ -300,16 +375,20 @@ func parseXML(filename string) (map[rune][]rune, map[rune]bool) {
 			if ch.CompEx == "" {
 				ch.CompEx = gr.CompEx
 			}
-			if ch.Dt != "can" {
+			if ch.Dt == "" || ch.Dt == "none" {
 				continue
 			}
 
 			runes := parseDm(ch.Dm)
+			target := dms
+			if ch.Dt != "can" {
+				target = compat
+			}
 
 			if ch.Cp != "" {
 				ru, err := strconv.ParseInt(ch.Cp, 16, 32)
 				check(err)
-				dms[rune(ru)] = runes
+				target[rune(ru)] = runes
 				if ch.CompEx == "Y" {
 					compEx[rune(ru)] = true
 				}
@@ -319,7 +398,7 @@ func parseXML(filename string) (map[rune][]rune, map[rune]bool) {
 				lastRune, err := strconv.ParseInt(ch.LastCp, 16, 32)
 				check(err)
 				for ru := firstRune; ru <= lastRune; ru++ {
-					dms[rune(ru)] = runes
+					target[rune(ru)] = runes
 					if ch.CompEx == "Y" {
 						compEx[rune(ru)] = true
 					}
@@ -340,7 +419,14 @@ func parseXML(filename string) (map[rune][]rune, map[rune]bool) {
 		delete(dms, rune(i))
 	}
 
-	return dms, compEx
+	return ucdProperties{
+		dms:                 dms,
+		compat:              compat,
+		compEx:              compEx,
+		brackets:            brackets,
+		eastAsianWidth:      eastAsianWidth,
+		verticalOrientation: verticalOrientation,
+	}
 }
 
 // return the joining type and joining group
@@ -438,3 +524,62 @@ func parseScriptNames(b []byte) (map[string]uint32, error) {
 	}
 	return m, nil
 }
+
+// subtagRegistry stores the replacements of the deprecated subtags and tags
+// of the IANA Language Subtag Registry, in lower case
+type subtagRegistry struct {
+	// Type -> subtag -> Preferred-Value, for the language, script, region and variant types
+	aliases map[string]map[string]string
+	// extlang -> prefix
+	extlangs map[string]string
+	// grandfathered tag -> Preferred-Value, which may be empty
+	grandfathered map[string]string
+	// redundant tag -> Preferred-Value, only for the tags with a Preferred-Value
+	redundant map[string]string
+}
+
+func parseSubtagRegistry(b []byte) (subtagRegistry, error) {
+	out := subtagRegistry{
+		aliases:       map[string]map[string]string{},
+		extlangs:      map[string]string{},
+		grandfathered: map[string]string{},
+		redundant:     map[string]string{},
+	}
+	for _, record := range strings.Split(string(b), "\n%%\n")[1:] { // skip the File-Date
+		fields := map[string]string{}
+		for _, line := range strings.Split(record, "\n") {
+			if i := strings.Index(line, ": "); i != -1 && !strings.HasPrefix(line, " ") {
+				key := line[:i]
+				if _, has := fields[key]; !has { // only keep the first Description, Prefix, etc...
+					fields[key] = strings.ToLower(line[i+2:])
+				}
+			}
+		}
+
+		typ, preferred := fields["Type"], fields["Preferred-Value"]
+		switch typ {
+		case "language", "script", "region", "variant":
+			if preferred == "" {
+				continue
+			}
+			if out.aliases[typ] == nil {
+				out.aliases[typ] = map[string]string{}
+			}
+			out.aliases[typ][fields["Subtag"]] = preferred
+		case "extlang":
+			if preferred != fields["Subtag"] {
+				return out, fmt.Errorf("unexpected Preferred-Value for extlang %s", fields["Subtag"])
+			}
+			out.extlangs[fields["Subtag"]] = fields["Prefix"]
+		case "grandfathered":
+			out.grandfathered[fields["Tag"]] = preferred
+		case "redundant":
+			if preferred != "" {
+				out.redundant[fields["Tag"]] = preferred
+			}
+		default:
+			return out, fmt.Errorf("unexpected record type %s", typ)
+		}
+	}
+	return out, nil
+}
@@ -80,7 +80,7 @@ func generateCombiningClasses(classes map[uint8][]rune, w io.Writer) {
 
 func generateEmojis(runes map[string][]rune, w io.Writer) {
 	fmt.Fprint(w, header)
-	classes := [...]string{"Emoji", "Emoji_Presentation", "Emoji_Modifier", "Emoji_Modifier_Base", "Extended_Pictographic"}
+	classes := [...]string{"Emoji", "Emoji_Presentation", "Emoji_Modifier", "Emoji_Modifier_Base", "Emoji_Component", "Extended_Pictographic"}
 	for _, class := range classes {
 		table := rangetable.New(runes[class]...)
 		s := printTable(table, false)
@@ -103,7 +103,26 @@ func generateMirroring(runes map[uint16]uint16, w io.Writer) {
 	fmt.Fprintln(w, "}")
 }
 
-func generateDecomposition(dms map[rune][]rune, compExp map[rune]bool, w io.Writer) {
+func generateBidiBrackets(brackets map[rune]bidiBracket, w io.Writer) {
+	fmt.Fprint(w, header)
+	fmt.Fprintf(w, "var bidiBrackets = map[rune]bidiBracket{ // %d entries \n", len(brackets))
+	var sorted []rune
+	for r := range brackets {
+		sorted = append(sorted, r)
+	}
+	sortRunes(sorted)
+	for _, r := range sorted {
+		b := brackets[r]
+		kind := "BracketClose"
+		if b.isOpening {
+			kind = "BracketOpen"
+		}
+		fmt.Fprintf(w, "0x%04x: {0x%04x, %s},\n", r, b.pair, kind)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func generateDecomposition(dms, compat map[rune][]rune, compExp map[rune]bool, w io.Writer) {
 	var (
 		decompose1 [][2]rune         // length 1 mappings {from, to}
 		decompose2 [][3]rune         // length 2 mappings {from, to1, to2}
@@ -158,6 +177,24 @@ func generateDecomposition(dms map[rune][]rune, compExp map[rune]bool, w io.Writ
 		fmt.Fprintf(w, "{0x%04x,0x%04x}: 0x%04x,\n", vals[0], vals[1], vals[2])
 	}
 	fmt.Fprintln(w, "}")
+
+	var sorted []rune
+	for r := range compat {
+		sorted = append(sorted, r)
+	}
+	sortRunes(sorted)
+	fmt.Fprintf(w, "var decomposeCompat = map[rune][]rune{ // %d entries \n", len(sorted))
+	for _, r := range sorted {
+		fmt.Fprintf(w, "0x%04x: {", r)
+		for i, v := range compat[r] {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, "0x%04x", v)
+		}
+		fmt.Fprintln(w, "},")
+	}
+	fmt.Fprintln(w, "}")
 }
 
 func generateArabicShaping(joining map[rune]unicodedata.ArabicJoining, w io.Writer) {
@@ -359,15 +396,111 @@ func generateLineBreak(datas map[string][]rune, w io.Writer) {
 	`, dict)
 }
 
-func generateIndicCategories(datas map[string][]rune, w io.Writer) {
+func generateIndicCategories(syllabic, positional map[string][]rune, w io.Writer) {
 	fmt.Fprint(w, header)
 	for _, className := range []string{"Virama", "Vowel_Dependent"} {
-		table := rangetable.New(datas[className]...)
+		table := rangetable.New(syllabic[className]...)
 		s := printTable(table, false)
 		fmt.Fprintf(w, "var Indic%s = %s\n\n", className, s)
 	}
+
+	generateIndicProperty("IndicSyllabicCategory", "IndicSyllabic", "Other", syllabic, w)
+	generateIndicProperty("IndicPositionalCategory", "IndicPositional", "NA", positional, w)
+}
+
+// generateIndicProperty writes the constants of the enumerated property `typeName`,
+// and the ranges used to look it up. The first constant is the default value.
+func generateIndicProperty(typeName, prefix, defaultValue string, datas map[string][]rune, w io.Writer) {
+	values := []string{defaultValue}
+	for value := range datas {
+		if value != defaultValue {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values[1:])
+
+	fmt.Fprintf(w, "// values of %s (see its String method for the UCD names)\n", typeName)
+	fmt.Fprintln(w, "const (")
+	for i, value := range values {
+		if i == 0 {
+			fmt.Fprintf(w, "%s%s %s = iota\n", prefix, strings.ReplaceAll(value, "_", ""), typeName)
+		} else {
+			fmt.Fprintf(w, "%s%s\n", prefix, strings.ReplaceAll(value, "_", ""))
+		}
+	}
+	fmt.Fprintln(w, ")")
+
+	fmt.Fprintf(w, "\nvar %sNames = [...]string{\n", lowerFirst(typeName))
+	for _, value := range values {
+		fmt.Fprintf(w, "%q,\n", value)
+	}
+	fmt.Fprintln(w, "}")
+
+	byRune := map[rune]int{}
+	for i, value := range values[1:] {
+		for _, r := range datas[value] {
+			byRune[r] = i + 1
+		}
+	}
+	fmt.Fprintln(w)
+	generatePropertyRanges(lowerFirst(typeName)+"Ranges", byRune, values, w)
+}
+
+// generatePropertyRanges writes the ranges of runes with the same value (other than 0),
+// used by lookupRange. `names` are used to comment the values.
+func generatePropertyRanges(varName string, byRune map[rune]int, names []string, w io.Writer) {
+	var sorted []rune
+	for r, value := range byRune {
+		if value != 0 {
+			sorted = append(sorted, r)
+		}
+	}
+	sortRunes(sorted)
+
+	fmt.Fprintf(w, "var %s = [...]propertyRange{\n", varName)
+	for i := 0; i < len(sorted); {
+		start, value := sorted[i], byRune[sorted[i]]
+		end := start
+		for i++; i < len(sorted) && sorted[i] == end+1 && byRune[sorted[i]] == value; i++ {
+			end++
+		}
+		fmt.Fprintf(w, "{start: 0x%04x, end: 0x%04x, value: %d}, // %s\n", start, end, value, names[value])
+	}
+	fmt.Fprintln(w, "}")
 }
 
+func generateEastAsian(eastAsianWidth, verticalOrientation map[rune]string, w io.Writer) {
+	fmt.Fprint(w, header)
+
+	// in the order of the constants
+	widths := []string{"N", "A", "H", "W", "F", "Na"}
+	orientations := []string{"R", "U", "Tu", "Tr"}
+	index := func(values []string, value string) int {
+		for i, v := range values {
+			if v == value {
+				return i
+			}
+		}
+		log.Fatalf("unexpected property value %s", value)
+		return 0
+	}
+
+	byRune := map[rune]int{}
+	for r, value := range eastAsianWidth {
+		byRune[r] = index(widths, value)
+	}
+	generatePropertyRanges("eastAsianWidthRanges", byRune, widths, w)
+
+	byRune = map[rune]int{}
+	for r, value := range verticalOrientation {
+		byRune[r] = index(orientations, value)
+	}
+	fmt.Fprintln(w)
+	generatePropertyRanges("verticalOrientationRanges", byRune, orientations, w)
+}
+
+func lowerFirst(s string) string { return strings.ToLower(s[:1]) + s[1:] }
+
 func generateSTermProperty(datas map[string][]rune, w io.Writer) {
 	fmt.Fprint(w, header)
 
@@ -379,7 +512,7 @@ func generateSTermProperty(datas map[string][]rune, w io.Writer) {
 }
 
 func generateEmojisTest(sequences [][]rune, w io.Writer) {
-	fmt.Fprintln(w, `package harfbuzz
+	fmt.Fprintln(w, `package harfbuzz_test
 
 	// Code generated by unicodedata/generate/main.go DO NOT EDIT.
 	`)
@@ -486,3 +619,43 @@ func generateScriptLookupTable(scripts map[string][]runeRange, scriptNames map[s
 	}
 	fmt.Fprintln(w, "}")
 }
+
+func generateVersion(w io.Writer) {
+	fmt.Fprint(w, header)
+	fmt.Fprintf(w, `// Version is the version of the Unicode Character Database
+// used to generate the tables of this package.
+//
+// Note that LookupType relies on the standard package unicode,
+// whose version is given by unicode.Version.
+const Version = %q
+`, version)
+}
+
+func printStringMap(name, comment string, m map[string]string, w io.Writer) {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "// %s\nvar %s = map[string]string{ // %d entries\n", comment, name, len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(w, "%q: %q,\n", k, m[k])
+	}
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+}
+
+func generateSubtagAliases(registry subtagRegistry, w io.Writer) {
+	fmt.Fprintln(w, `package language
+
+	// Code generated by unicodedata/generate/main.go DO NOT EDIT.
+	`)
+
+	printStringMap("languageAliases", "deprecated language subtag -> preferred value", registry.aliases["language"], w)
+	printStringMap("scriptAliases", "deprecated script subtag -> preferred value", registry.aliases["script"], w)
+	printStringMap("regionAliases", "deprecated region subtag -> preferred value", registry.aliases["region"], w)
+	printStringMap("variantAliases", "deprecated variant subtag -> preferred value", registry.aliases["variant"], w)
+	printStringMap("extlangPrefixes", "extended language subtag -> prefix", registry.extlangs, w)
+	printStringMap("grandfatheredTags", "grandfathered tag -> preferred value (possibly empty)", registry.grandfathered, w)
+	printStringMap("redundantAliases", "redundant tag -> preferred value", registry.redundant, w)
+}
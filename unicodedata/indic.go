@@ -159,3 +159,1481 @@ var IndicVowel_Dependent = &unicode.RangeTable{
 		{Lo: 0x11ef3, Hi: 0x11ef6, Stride: 1},
 	},
 }
+
+// values of IndicSyllabicCategory (see its String method for the UCD names)
+const (
+	IndicSyllabicOther IndicSyllabicCategory = iota
+	IndicSyllabicAvagraha
+	IndicSyllabicBindu
+	IndicSyllabicBrahmiJoiningNumber
+	IndicSyllabicCantillationMark
+	IndicSyllabicConsonant
+	IndicSyllabicConsonantDead
+	IndicSyllabicConsonantFinal
+	IndicSyllabicConsonantHeadLetter
+	IndicSyllabicConsonantInitialPostfixed
+	IndicSyllabicConsonantKiller
+	IndicSyllabicConsonantMedial
+	IndicSyllabicConsonantPlaceholder
+	IndicSyllabicConsonantPrecedingRepha
+	IndicSyllabicConsonantPrefixed
+	IndicSyllabicConsonantSubjoined
+	IndicSyllabicConsonantSucceedingRepha
+	IndicSyllabicConsonantWithStacker
+	IndicSyllabicGeminationMark
+	IndicSyllabicInvisibleStacker
+	IndicSyllabicJoiner
+	IndicSyllabicModifyingLetter
+	IndicSyllabicNonJoiner
+	IndicSyllabicNukta
+	IndicSyllabicNumber
+	IndicSyllabicNumberJoiner
+	IndicSyllabicPureKiller
+	IndicSyllabicRegisterShifter
+	IndicSyllabicSyllableModifier
+	IndicSyllabicToneLetter
+	IndicSyllabicToneMark
+	IndicSyllabicVirama
+	IndicSyllabicVisarga
+	IndicSyllabicVowel
+	IndicSyllabicVowelDependent
+	IndicSyllabicVowelIndependent
+)
+
+var indicSyllabicCategoryNames = [...]string{
+	"Other",
+	"Avagraha",
+	"Bindu",
+	"Brahmi_Joining_Number",
+	"Cantillation_Mark",
+	"Consonant",
+	"Consonant_Dead",
+	"Consonant_Final",
+	"Consonant_Head_Letter",
+	"Consonant_Initial_Postfixed",
+	"Consonant_Killer",
+	"Consonant_Medial",
+	"Consonant_Placeholder",
+	"Consonant_Preceding_Repha",
+	"Consonant_Prefixed",
+	"Consonant_Subjoined",
+	"Consonant_Succeeding_Repha",
+	"Consonant_With_Stacker",
+	"Gemination_Mark",
+	"Invisible_Stacker",
+	"Joiner",
+	"Modifying_Letter",
+	"Non_Joiner",
+	"Nukta",
+	"Number",
+	"Number_Joiner",
+	"Pure_Killer",
+	"Register_Shifter",
+	"Syllable_Modifier",
+	"Tone_Letter",
+	"Tone_Mark",
+	"Virama",
+	"Visarga",
+	"Vowel",
+	"Vowel_Dependent",
+	"Vowel_Independent",
+}
+
+var indicSyllabicCategoryRanges = [...]propertyRange{
+	{start: 0x002d, end: 0x002d, value: 12},   // Consonant_Placeholder
+	{start: 0x0030, end: 0x0039, value: 24},   // Number
+	{start: 0x00a0, end: 0x00a0, value: 12},   // Consonant_Placeholder
+	{start: 0x00b2, end: 0x00b3, value: 28},   // Syllable_Modifier
+	{start: 0x00d7, end: 0x00d7, value: 12},   // Consonant_Placeholder
+	{start: 0x0900, end: 0x0902, value: 2},    // Bindu
+	{start: 0x0903, end: 0x0903, value: 32},   // Visarga
+	{start: 0x0904, end: 0x0914, value: 35},   // Vowel_Independent
+	{start: 0x0915, end: 0x0939, value: 5},    // Consonant
+	{start: 0x093a, end: 0x093b, value: 34},   // Vowel_Dependent
+	{start: 0x093c, end: 0x093c, value: 23},   // Nukta
+	{start: 0x093d, end: 0x093d, value: 1},    // Avagraha
+	{start: 0x093e, end: 0x094c, value: 34},   // Vowel_Dependent
+	{start: 0x094d, end: 0x094d, value: 31},   // Virama
+	{start: 0x094e, end: 0x094f, value: 34},   // Vowel_Dependent
+	{start: 0x0951, end: 0x0952, value: 4},    // Cantillation_Mark
+	{start: 0x0955, end: 0x0957, value: 34},   // Vowel_Dependent
+	{start: 0x0958, end: 0x095f, value: 5},    // Consonant
+	{start: 0x0960, end: 0x0961, value: 35},   // Vowel_Independent
+	{start: 0x0962, end: 0x0963, value: 34},   // Vowel_Dependent
+	{start: 0x0966, end: 0x096f, value: 24},   // Number
+	{start: 0x0972, end: 0x0977, value: 35},   // Vowel_Independent
+	{start: 0x0978, end: 0x097f, value: 5},    // Consonant
+	{start: 0x0980, end: 0x0980, value: 12},   // Consonant_Placeholder
+	{start: 0x0981, end: 0x0982, value: 2},    // Bindu
+	{start: 0x0983, end: 0x0983, value: 32},   // Visarga
+	{start: 0x0985, end: 0x098c, value: 35},   // Vowel_Independent
+	{start: 0x098f, end: 0x0990, value: 35},   // Vowel_Independent
+	{start: 0x0993, end: 0x0994, value: 35},   // Vowel_Independent
+	{start: 0x0995, end: 0x09a8, value: 5},    // Consonant
+	{start: 0x09aa, end: 0x09b0, value: 5},    // Consonant
+	{start: 0x09b2, end: 0x09b2, value: 5},    // Consonant
+	{start: 0x09b6, end: 0x09b9, value: 5},    // Consonant
+	{start: 0x09bc, end: 0x09bc, value: 23},   // Nukta
+	{start: 0x09bd, end: 0x09bd, value: 1},    // Avagraha
+	{start: 0x09be, end: 0x09c4, value: 34},   // Vowel_Dependent
+	{start: 0x09c7, end: 0x09c8, value: 34},   // Vowel_Dependent
+	{start: 0x09cb, end: 0x09cc, value: 34},   // Vowel_Dependent
+	{start: 0x09cd, end: 0x09cd, value: 31},   // Virama
+	{start: 0x09ce, end: 0x09ce, value: 6},    // Consonant_Dead
+	{start: 0x09d7, end: 0x09d7, value: 34},   // Vowel_Dependent
+	{start: 0x09dc, end: 0x09dd, value: 5},    // Consonant
+	{start: 0x09df, end: 0x09df, value: 5},    // Consonant
+	{start: 0x09e0, end: 0x09e1, value: 35},   // Vowel_Independent
+	{start: 0x09e2, end: 0x09e3, value: 34},   // Vowel_Dependent
+	{start: 0x09e6, end: 0x09ef, value: 24},   // Number
+	{start: 0x09f0, end: 0x09f1, value: 5},    // Consonant
+	{start: 0x09fc, end: 0x09fc, value: 2},    // Bindu
+	{start: 0x09fe, end: 0x09fe, value: 28},   // Syllable_Modifier
+	{start: 0x0a01, end: 0x0a02, value: 2},    // Bindu
+	{start: 0x0a03, end: 0x0a03, value: 32},   // Visarga
+	{start: 0x0a05, end: 0x0a0a, value: 35},   // Vowel_Independent
+	{start: 0x0a0f, end: 0x0a10, value: 35},   // Vowel_Independent
+	{start: 0x0a13, end: 0x0a14, value: 35},   // Vowel_Independent
+	{start: 0x0a15, end: 0x0a28, value: 5},    // Consonant
+	{start: 0x0a2a, end: 0x0a30, value: 5},    // Consonant
+	{start: 0x0a32, end: 0x0a33, value: 5},    // Consonant
+	{start: 0x0a35, end: 0x0a36, value: 5},    // Consonant
+	{start: 0x0a38, end: 0x0a39, value: 5},    // Consonant
+	{start: 0x0a3c, end: 0x0a3c, value: 23},   // Nukta
+	{start: 0x0a3e, end: 0x0a42, value: 34},   // Vowel_Dependent
+	{start: 0x0a47, end: 0x0a48, value: 34},   // Vowel_Dependent
+	{start: 0x0a4b, end: 0x0a4c, value: 34},   // Vowel_Dependent
+	{start: 0x0a4d, end: 0x0a4d, value: 31},   // Virama
+	{start: 0x0a51, end: 0x0a51, value: 4},    // Cantillation_Mark
+	{start: 0x0a59, end: 0x0a5c, value: 5},    // Consonant
+	{start: 0x0a5e, end: 0x0a5e, value: 5},    // Consonant
+	{start: 0x0a66, end: 0x0a6f, value: 24},   // Number
+	{start: 0x0a70, end: 0x0a70, value: 2},    // Bindu
+	{start: 0x0a71, end: 0x0a71, value: 18},   // Gemination_Mark
+	{start: 0x0a72, end: 0x0a73, value: 12},   // Consonant_Placeholder
+	{start: 0x0a75, end: 0x0a75, value: 11},   // Consonant_Medial
+	{start: 0x0a81, end: 0x0a82, value: 2},    // Bindu
+	{start: 0x0a83, end: 0x0a83, value: 32},   // Visarga
+	{start: 0x0a85, end: 0x0a8d, value: 35},   // Vowel_Independent
+	{start: 0x0a8f, end: 0x0a91, value: 35},   // Vowel_Independent
+	{start: 0x0a93, end: 0x0a94, value: 35},   // Vowel_Independent
+	{start: 0x0a95, end: 0x0aa8, value: 5},    // Consonant
+	{start: 0x0aaa, end: 0x0ab0, value: 5},    // Consonant
+	{start: 0x0ab2, end: 0x0ab3, value: 5},    // Consonant
+	{start: 0x0ab5, end: 0x0ab9, value: 5},    // Consonant
+	{start: 0x0abc, end: 0x0abc, value: 23},   // Nukta
+	{start: 0x0abd, end: 0x0abd, value: 1},    // Avagraha
+	{start: 0x0abe, end: 0x0ac5, value: 34},   // Vowel_Dependent
+	{start: 0x0ac7, end: 0x0ac9, value: 34},   // Vowel_Dependent
+	{start: 0x0acb, end: 0x0acc, value: 34},   // Vowel_Dependent
+	{start: 0x0acd, end: 0x0acd, value: 31},   // Virama
+	{start: 0x0ae0, end: 0x0ae1, value: 35},   // Vowel_Independent
+	{start: 0x0ae2, end: 0x0ae3, value: 34},   // Vowel_Dependent
+	{start: 0x0ae6, end: 0x0aef, value: 24},   // Number
+	{start: 0x0af9, end: 0x0af9, value: 5},    // Consonant
+	{start: 0x0afa, end: 0x0afc, value: 4},    // Cantillation_Mark
+	{start: 0x0afd, end: 0x0aff, value: 23},   // Nukta
+	{start: 0x0b01, end: 0x0b02, value: 2},    // Bindu
+	{start: 0x0b03, end: 0x0b03, value: 32},   // Visarga
+	{start: 0x0b05, end: 0x0b0c, value: 35},   // Vowel_Independent
+	{start: 0x0b0f, end: 0x0b10, value: 35},   // Vowel_Independent
+	{start: 0x0b13, end: 0x0b14, value: 35},   // Vowel_Independent
+	{start: 0x0b15, end: 0x0b28, value: 5},    // Consonant
+	{start: 0x0b2a, end: 0x0b30, value: 5},    // Consonant
+	{start: 0x0b32, end: 0x0b33, value: 5},    // Consonant
+	{start: 0x0b35, end: 0x0b39, value: 5},    // Consonant
+	{start: 0x0b3c, end: 0x0b3c, value: 23},   // Nukta
+	{start: 0x0b3d, end: 0x0b3d, value: 1},    // Avagraha
+	{start: 0x0b3e, end: 0x0b44, value: 34},   // Vowel_Dependent
+	{start: 0x0b47, end: 0x0b48, value: 34},   // Vowel_Dependent
+	{start: 0x0b4b, end: 0x0b4c, value: 34},   // Vowel_Dependent
+	{start: 0x0b4d, end: 0x0b4d, value: 31},   // Virama
+	{start: 0x0b55, end: 0x0b57, value: 34},   // Vowel_Dependent
+	{start: 0x0b5c, end: 0x0b5d, value: 5},    // Consonant
+	{start: 0x0b5f, end: 0x0b5f, value: 5},    // Consonant
+	{start: 0x0b60, end: 0x0b61, value: 35},   // Vowel_Independent
+	{start: 0x0b62, end: 0x0b63, value: 34},   // Vowel_Dependent
+	{start: 0x0b66, end: 0x0b6f, value: 24},   // Number
+	{start: 0x0b71, end: 0x0b71, value: 5},    // Consonant
+	{start: 0x0b82, end: 0x0b82, value: 2},    // Bindu
+	{start: 0x0b83, end: 0x0b83, value: 21},   // Modifying_Letter
+	{start: 0x0b85, end: 0x0b8a, value: 35},   // Vowel_Independent
+	{start: 0x0b8e, end: 0x0b90, value: 35},   // Vowel_Independent
+	{start: 0x0b92, end: 0x0b94, value: 35},   // Vowel_Independent
+	{start: 0x0b95, end: 0x0b95, value: 5},    // Consonant
+	{start: 0x0b99, end: 0x0b9a, value: 5},    // Consonant
+	{start: 0x0b9c, end: 0x0b9c, value: 5},    // Consonant
+	{start: 0x0b9e, end: 0x0b9f, value: 5},    // Consonant
+	{start: 0x0ba3, end: 0x0ba4, value: 5},    // Consonant
+	{start: 0x0ba8, end: 0x0baa, value: 5},    // Consonant
+	{start: 0x0bae, end: 0x0bb9, value: 5},    // Consonant
+	{start: 0x0bbe, end: 0x0bc2, value: 34},   // Vowel_Dependent
+	{start: 0x0bc6, end: 0x0bc8, value: 34},   // Vowel_Dependent
+	{start: 0x0bca, end: 0x0bcc, value: 34},   // Vowel_Dependent
+	{start: 0x0bcd, end: 0x0bcd, value: 31},   // Virama
+	{start: 0x0bd7, end: 0x0bd7, value: 34},   // Vowel_Dependent
+	{start: 0x0be6, end: 0x0bef, value: 24},   // Number
+	{start: 0x0c00, end: 0x0c02, value: 2},    // Bindu
+	{start: 0x0c03, end: 0x0c03, value: 32},   // Visarga
+	{start: 0x0c04, end: 0x0c04, value: 2},    // Bindu
+	{start: 0x0c05, end: 0x0c0c, value: 35},   // Vowel_Independent
+	{start: 0x0c0e, end: 0x0c10, value: 35},   // Vowel_Independent
+	{start: 0x0c12, end: 0x0c14, value: 35},   // Vowel_Independent
+	{start: 0x0c15, end: 0x0c28, value: 5},    // Consonant
+	{start: 0x0c2a, end: 0x0c39, value: 5},    // Consonant
+	{start: 0x0c3d, end: 0x0c3d, value: 1},    // Avagraha
+	{start: 0x0c3e, end: 0x0c44, value: 34},   // Vowel_Dependent
+	{start: 0x0c46, end: 0x0c48, value: 34},   // Vowel_Dependent
+	{start: 0x0c4a, end: 0x0c4c, value: 34},   // Vowel_Dependent
+	{start: 0x0c4d, end: 0x0c4d, value: 31},   // Virama
+	{start: 0x0c55, end: 0x0c56, value: 34},   // Vowel_Dependent
+	{start: 0x0c58, end: 0x0c5a, value: 5},    // Consonant
+	{start: 0x0c60, end: 0x0c61, value: 35},   // Vowel_Independent
+	{start: 0x0c62, end: 0x0c63, value: 34},   // Vowel_Dependent
+	{start: 0x0c66, end: 0x0c6f, value: 24},   // Number
+	{start: 0x0c80, end: 0x0c82, value: 2},    // Bindu
+	{start: 0x0c83, end: 0x0c83, value: 32},   // Visarga
+	{start: 0x0c85, end: 0x0c8c, value: 35},   // Vowel_Independent
+	{start: 0x0c8e, end: 0x0c90, value: 35},   // Vowel_Independent
+	{start: 0x0c92, end: 0x0c94, value: 35},   // Vowel_Independent
+	{start: 0x0c95, end: 0x0ca8, value: 5},    // Consonant
+	{start: 0x0caa, end: 0x0cb3, value: 5},    // Consonant
+	{start: 0x0cb5, end: 0x0cb9, value: 5},    // Consonant
+	{start: 0x0cbc, end: 0x0cbc, value: 23},   // Nukta
+	{start: 0x0cbd, end: 0x0cbd, value: 1},    // Avagraha
+	{start: 0x0cbe, end: 0x0cc4, value: 34},   // Vowel_Dependent
+	{start: 0x0cc6, end: 0x0cc8, value: 34},   // Vowel_Dependent
+	{start: 0x0cca, end: 0x0ccc, value: 34},   // Vowel_Dependent
+	{start: 0x0ccd, end: 0x0ccd, value: 31},   // Virama
+	{start: 0x0cd5, end: 0x0cd6, value: 34},   // Vowel_Dependent
+	{start: 0x0cde, end: 0x0cde, value: 5},    // Consonant
+	{start: 0x0ce0, end: 0x0ce1, value: 35},   // Vowel_Independent
+	{start: 0x0ce2, end: 0x0ce3, value: 34},   // Vowel_Dependent
+	{start: 0x0ce6, end: 0x0cef, value: 24},   // Number
+	{start: 0x0cf1, end: 0x0cf2, value: 17},   // Consonant_With_Stacker
+	{start: 0x0d00, end: 0x0d02, value: 2},    // Bindu
+	{start: 0x0d03, end: 0x0d03, value: 32},   // Visarga
+	{start: 0x0d04, end: 0x0d04, value: 2},    // Bindu
+	{start: 0x0d05, end: 0x0d0c, value: 35},   // Vowel_Independent
+	{start: 0x0d0e, end: 0x0d10, value: 35},   // Vowel_Independent
+	{start: 0x0d12, end: 0x0d14, value: 35},   // Vowel_Independent
+	{start: 0x0d15, end: 0x0d3a, value: 5},    // Consonant
+	{start: 0x0d3b, end: 0x0d3c, value: 26},   // Pure_Killer
+	{start: 0x0d3d, end: 0x0d3d, value: 1},    // Avagraha
+	{start: 0x0d3e, end: 0x0d44, value: 34},   // Vowel_Dependent
+	{start: 0x0d46, end: 0x0d48, value: 34},   // Vowel_Dependent
+	{start: 0x0d4a, end: 0x0d4c, value: 34},   // Vowel_Dependent
+	{start: 0x0d4d, end: 0x0d4d, value: 31},   // Virama
+	{start: 0x0d4e, end: 0x0d4e, value: 13},   // Consonant_Preceding_Repha
+	{start: 0x0d54, end: 0x0d56, value: 6},    // Consonant_Dead
+	{start: 0x0d57, end: 0x0d57, value: 34},   // Vowel_Dependent
+	{start: 0x0d5f, end: 0x0d61, value: 35},   // Vowel_Independent
+	{start: 0x0d62, end: 0x0d63, value: 34},   // Vowel_Dependent
+	{start: 0x0d66, end: 0x0d6f, value: 24},   // Number
+	{start: 0x0d7a, end: 0x0d7f, value: 6},    // Consonant_Dead
+	{start: 0x0d81, end: 0x0d82, value: 2},    // Bindu
+	{start: 0x0d83, end: 0x0d83, value: 32},   // Visarga
+	{start: 0x0d85, end: 0x0d96, value: 35},   // Vowel_Independent
+	{start: 0x0d9a, end: 0x0db1, value: 5},    // Consonant
+	{start: 0x0db3, end: 0x0dbb, value: 5},    // Consonant
+	{start: 0x0dbd, end: 0x0dbd, value: 5},    // Consonant
+	{start: 0x0dc0, end: 0x0dc6, value: 5},    // Consonant
+	{start: 0x0dca, end: 0x0dca, value: 31},   // Virama
+	{start: 0x0dcf, end: 0x0dd4, value: 34},   // Vowel_Dependent
+	{start: 0x0dd6, end: 0x0dd6, value: 34},   // Vowel_Dependent
+	{start: 0x0dd8, end: 0x0ddf, value: 34},   // Vowel_Dependent
+	{start: 0x0de6, end: 0x0def, value: 24},   // Number
+	{start: 0x0df2, end: 0x0df3, value: 34},   // Vowel_Dependent
+	{start: 0x0e01, end: 0x0e2e, value: 5},    // Consonant
+	{start: 0x0e30, end: 0x0e39, value: 34},   // Vowel_Dependent
+	{start: 0x0e3a, end: 0x0e3a, value: 26},   // Pure_Killer
+	{start: 0x0e40, end: 0x0e45, value: 34},   // Vowel_Dependent
+	{start: 0x0e47, end: 0x0e47, value: 34},   // Vowel_Dependent
+	{start: 0x0e48, end: 0x0e4b, value: 30},   // Tone_Mark
+	{start: 0x0e4c, end: 0x0e4c, value: 10},   // Consonant_Killer
+	{start: 0x0e4d, end: 0x0e4d, value: 2},    // Bindu
+	{start: 0x0e4e, end: 0x0e4e, value: 26},   // Pure_Killer
+	{start: 0x0e50, end: 0x0e59, value: 24},   // Number
+	{start: 0x0e81, end: 0x0e82, value: 5},    // Consonant
+	{start: 0x0e84, end: 0x0e84, value: 5},    // Consonant
+	{start: 0x0e86, end: 0x0e8a, value: 5},    // Consonant
+	{start: 0x0e8c, end: 0x0ea3, value: 5},    // Consonant
+	{start: 0x0ea5, end: 0x0ea5, value: 5},    // Consonant
+	{start: 0x0ea7, end: 0x0eae, value: 5},    // Consonant
+	{start: 0x0eb0, end: 0x0eb9, value: 34},   // Vowel_Dependent
+	{start: 0x0eba, end: 0x0eba, value: 26},   // Pure_Killer
+	{start: 0x0ebb, end: 0x0ebb, value: 34},   // Vowel_Dependent
+	{start: 0x0ebc, end: 0x0ebd, value: 11},   // Consonant_Medial
+	{start: 0x0ec0, end: 0x0ec4, value: 34},   // Vowel_Dependent
+	{start: 0x0ec8, end: 0x0ecb, value: 30},   // Tone_Mark
+	{start: 0x0ecd, end: 0x0ecd, value: 2},    // Bindu
+	{start: 0x0ed0, end: 0x0ed9, value: 24},   // Number
+	{start: 0x0edc, end: 0x0edf, value: 5},    // Consonant
+	{start: 0x0f20, end: 0x0f33, value: 24},   // Number
+	{start: 0x0f35, end: 0x0f35, value: 28},   // Syllable_Modifier
+	{start: 0x0f37, end: 0x0f37, value: 28},   // Syllable_Modifier
+	{start: 0x0f39, end: 0x0f39, value: 23},   // Nukta
+	{start: 0x0f40, end: 0x0f47, value: 5},    // Consonant
+	{start: 0x0f49, end: 0x0f6c, value: 5},    // Consonant
+	{start: 0x0f71, end: 0x0f7d, value: 34},   // Vowel_Dependent
+	{start: 0x0f7e, end: 0x0f7e, value: 2},    // Bindu
+	{start: 0x0f7f, end: 0x0f7f, value: 32},   // Visarga
+	{start: 0x0f80, end: 0x0f81, value: 34},   // Vowel_Dependent
+	{start: 0x0f82, end: 0x0f83, value: 2},    // Bindu
+	{start: 0x0f84, end: 0x0f84, value: 26},   // Pure_Killer
+	{start: 0x0f85, end: 0x0f85, value: 1},    // Avagraha
+	{start: 0x0f88, end: 0x0f8c, value: 8},    // Consonant_Head_Letter
+	{start: 0x0f8d, end: 0x0f97, value: 15},   // Consonant_Subjoined
+	{start: 0x0f99, end: 0x0fbc, value: 15},   // Consonant_Subjoined
+	{start: 0x0fc6, end: 0x0fc6, value: 28},   // Syllable_Modifier
+	{start: 0x1000, end: 0x1020, value: 5},    // Consonant
+	{start: 0x1021, end: 0x102a, value: 35},   // Vowel_Independent
+	{start: 0x102b, end: 0x1035, value: 34},   // Vowel_Dependent
+	{start: 0x1036, end: 0x1036, value: 2},    // Bindu
+	{start: 0x1037, end: 0x1037, value: 30},   // Tone_Mark
+	{start: 0x1038, end: 0x1038, value: 32},   // Visarga
+	{start: 0x1039, end: 0x1039, value: 19},   // Invisible_Stacker
+	{start: 0x103a, end: 0x103a, value: 26},   // Pure_Killer
+	{start: 0x103b, end: 0x103e, value: 11},   // Consonant_Medial
+	{start: 0x103f, end: 0x103f, value: 5},    // Consonant
+	{start: 0x1040, end: 0x1049, value: 24},   // Number
+	{start: 0x104b, end: 0x104b, value: 12},   // Consonant_Placeholder
+	{start: 0x104e, end: 0x104e, value: 12},   // Consonant_Placeholder
+	{start: 0x1050, end: 0x1051, value: 5},    // Consonant
+	{start: 0x1052, end: 0x1055, value: 35},   // Vowel_Independent
+	{start: 0x1056, end: 0x1059, value: 34},   // Vowel_Dependent
+	{start: 0x105a, end: 0x105d, value: 5},    // Consonant
+	{start: 0x105e, end: 0x1060, value: 11},   // Consonant_Medial
+	{start: 0x1061, end: 0x1061, value: 5},    // Consonant
+	{start: 0x1062, end: 0x1062, value: 34},   // Vowel_Dependent
+	{start: 0x1063, end: 0x1064, value: 30},   // Tone_Mark
+	{start: 0x1065, end: 0x1066, value: 5},    // Consonant
+	{start: 0x1067, end: 0x1068, value: 34},   // Vowel_Dependent
+	{start: 0x1069, end: 0x106d, value: 30},   // Tone_Mark
+	{start: 0x106e, end: 0x1070, value: 5},    // Consonant
+	{start: 0x1071, end: 0x1074, value: 34},   // Vowel_Dependent
+	{start: 0x1075, end: 0x1081, value: 5},    // Consonant
+	{start: 0x1082, end: 0x1082, value: 11},   // Consonant_Medial
+	{start: 0x1083, end: 0x1086, value: 34},   // Vowel_Dependent
+	{start: 0x1087, end: 0x108d, value: 30},   // Tone_Mark
+	{start: 0x108e, end: 0x108e, value: 5},    // Consonant
+	{start: 0x108f, end: 0x108f, value: 30},   // Tone_Mark
+	{start: 0x1090, end: 0x1099, value: 24},   // Number
+	{start: 0x109a, end: 0x109b, value: 30},   // Tone_Mark
+	{start: 0x109c, end: 0x109d, value: 34},   // Vowel_Dependent
+	{start: 0x1700, end: 0x1702, value: 35},   // Vowel_Independent
+	{start: 0x1703, end: 0x170c, value: 5},    // Consonant
+	{start: 0x170e, end: 0x1711, value: 5},    // Consonant
+	{start: 0x1712, end: 0x1713, value: 34},   // Vowel_Dependent
+	{start: 0x1714, end: 0x1714, value: 26},   // Pure_Killer
+	{start: 0x1720, end: 0x1722, value: 35},   // Vowel_Independent
+	{start: 0x1723, end: 0x1731, value: 5},    // Consonant
+	{start: 0x1732, end: 0x1733, value: 34},   // Vowel_Dependent
+	{start: 0x1734, end: 0x1734, value: 26},   // Pure_Killer
+	{start: 0x1740, end: 0x1742, value: 35},   // Vowel_Independent
+	{start: 0x1743, end: 0x1751, value: 5},    // Consonant
+	{start: 0x1752, end: 0x1753, value: 34},   // Vowel_Dependent
+	{start: 0x1760, end: 0x1762, value: 35},   // Vowel_Independent
+	{start: 0x1763, end: 0x176c, value: 5},    // Consonant
+	{start: 0x176e, end: 0x1770, value: 5},    // Consonant
+	{start: 0x1772, end: 0x1773, value: 34},   // Vowel_Dependent
+	{start: 0x1780, end: 0x17a2, value: 5},    // Consonant
+	{start: 0x17a3, end: 0x17b3, value: 35},   // Vowel_Independent
+	{start: 0x17b6, end: 0x17c5, value: 34},   // Vowel_Dependent
+	{start: 0x17c6, end: 0x17c6, value: 2},    // Bindu
+	{start: 0x17c7, end: 0x17c7, value: 32},   // Visarga
+	{start: 0x17c8, end: 0x17c8, value: 34},   // Vowel_Dependent
+	{start: 0x17c9, end: 0x17ca, value: 27},   // Register_Shifter
+	{start: 0x17cb, end: 0x17cb, value: 28},   // Syllable_Modifier
+	{start: 0x17cc, end: 0x17cc, value: 16},   // Consonant_Succeeding_Repha
+	{start: 0x17cd, end: 0x17cd, value: 10},   // Consonant_Killer
+	{start: 0x17ce, end: 0x17d0, value: 28},   // Syllable_Modifier
+	{start: 0x17d1, end: 0x17d1, value: 26},   // Pure_Killer
+	{start: 0x17d2, end: 0x17d2, value: 19},   // Invisible_Stacker
+	{start: 0x17d3, end: 0x17d3, value: 28},   // Syllable_Modifier
+	{start: 0x17dc, end: 0x17dc, value: 1},    // Avagraha
+	{start: 0x17dd, end: 0x17dd, value: 28},   // Syllable_Modifier
+	{start: 0x17e0, end: 0x17e9, value: 24},   // Number
+	{start: 0x1900, end: 0x1900, value: 12},   // Consonant_Placeholder
+	{start: 0x1901, end: 0x191e, value: 5},    // Consonant
+	{start: 0x1920, end: 0x1928, value: 34},   // Vowel_Dependent
+	{start: 0x1929, end: 0x192b, value: 15},   // Consonant_Subjoined
+	{start: 0x1930, end: 0x1931, value: 7},    // Consonant_Final
+	{start: 0x1932, end: 0x1932, value: 2},    // Bindu
+	{start: 0x1933, end: 0x1939, value: 7},    // Consonant_Final
+	{start: 0x193a, end: 0x193a, value: 34},   // Vowel_Dependent
+	{start: 0x193b, end: 0x193b, value: 28},   // Syllable_Modifier
+	{start: 0x1946, end: 0x194f, value: 24},   // Number
+	{start: 0x1950, end: 0x1962, value: 5},    // Consonant
+	{start: 0x1963, end: 0x196d, value: 33},   // Vowel
+	{start: 0x1970, end: 0x1974, value: 29},   // Tone_Letter
+	{start: 0x1980, end: 0x19ab, value: 5},    // Consonant
+	{start: 0x19b0, end: 0x19c0, value: 34},   // Vowel_Dependent
+	{start: 0x19c1, end: 0x19c7, value: 7},    // Consonant_Final
+	{start: 0x19c8, end: 0x19c9, value: 30},   // Tone_Mark
+	{start: 0x19d0, end: 0x19da, value: 24},   // Number
+	{start: 0x1a00, end: 0x1a16, value: 5},    // Consonant
+	{start: 0x1a17, end: 0x1a1b, value: 34},   // Vowel_Dependent
+	{start: 0x1a20, end: 0x1a4c, value: 5},    // Consonant
+	{start: 0x1a4d, end: 0x1a52, value: 35},   // Vowel_Independent
+	{start: 0x1a53, end: 0x1a54, value: 5},    // Consonant
+	{start: 0x1a55, end: 0x1a56, value: 11},   // Consonant_Medial
+	{start: 0x1a57, end: 0x1a57, value: 15},   // Consonant_Subjoined
+	{start: 0x1a58, end: 0x1a59, value: 7},    // Consonant_Final
+	{start: 0x1a5a, end: 0x1a5a, value: 9},    // Consonant_Initial_Postfixed
+	{start: 0x1a5b, end: 0x1a5e, value: 15},   // Consonant_Subjoined
+	{start: 0x1a60, end: 0x1a60, value: 19},   // Invisible_Stacker
+	{start: 0x1a61, end: 0x1a73, value: 34},   // Vowel_Dependent
+	{start: 0x1a74, end: 0x1a74, value: 2},    // Bindu
+	{start: 0x1a75, end: 0x1a79, value: 30},   // Tone_Mark
+	{start: 0x1a7a, end: 0x1a7a, value: 26},   // Pure_Killer
+	{start: 0x1a7b, end: 0x1a7c, value: 28},   // Syllable_Modifier
+	{start: 0x1a7f, end: 0x1a7f, value: 28},   // Syllable_Modifier
+	{start: 0x1a80, end: 0x1a89, value: 24},   // Number
+	{start: 0x1a90, end: 0x1a99, value: 24},   // Number
+	{start: 0x1b00, end: 0x1b02, value: 2},    // Bindu
+	{start: 0x1b03, end: 0x1b03, value: 16},   // Consonant_Succeeding_Repha
+	{start: 0x1b04, end: 0x1b04, value: 32},   // Visarga
+	{start: 0x1b05, end: 0x1b12, value: 35},   // Vowel_Independent
+	{start: 0x1b13, end: 0x1b33, value: 5},    // Consonant
+	{start: 0x1b34, end: 0x1b34, value: 23},   // Nukta
+	{start: 0x1b35, end: 0x1b43, value: 34},   // Vowel_Dependent
+	{start: 0x1b44, end: 0x1b44, value: 31},   // Virama
+	{start: 0x1b45, end: 0x1b4b, value: 5},    // Consonant
+	{start: 0x1b50, end: 0x1b59, value: 24},   // Number
+	{start: 0x1b80, end: 0x1b80, value: 2},    // Bindu
+	{start: 0x1b81, end: 0x1b81, value: 16},   // Consonant_Succeeding_Repha
+	{start: 0x1b82, end: 0x1b82, value: 32},   // Visarga
+	{start: 0x1b83, end: 0x1b89, value: 35},   // Vowel_Independent
+	{start: 0x1b8a, end: 0x1ba0, value: 5},    // Consonant
+	{start: 0x1ba1, end: 0x1ba3, value: 15},   // Consonant_Subjoined
+	{start: 0x1ba4, end: 0x1ba9, value: 34},   // Vowel_Dependent
+	{start: 0x1baa, end: 0x1baa, value: 26},   // Pure_Killer
+	{start: 0x1bab, end: 0x1bab, value: 19},   // Invisible_Stacker
+	{start: 0x1bac, end: 0x1bad, value: 15},   // Consonant_Subjoined
+	{start: 0x1bae, end: 0x1baf, value: 5},    // Consonant
+	{start: 0x1bb0, end: 0x1bb9, value: 24},   // Number
+	{start: 0x1bba, end: 0x1bba, value: 1},    // Avagraha
+	{start: 0x1bbb, end: 0x1bbd, value: 5},    // Consonant
+	{start: 0x1bbe, end: 0x1bbf, value: 7},    // Consonant_Final
+	{start: 0x1bc0, end: 0x1be3, value: 5},    // Consonant
+	{start: 0x1be4, end: 0x1be5, value: 35},   // Vowel_Independent
+	{start: 0x1be6, end: 0x1be6, value: 23},   // Nukta
+	{start: 0x1be7, end: 0x1bef, value: 34},   // Vowel_Dependent
+	{start: 0x1bf0, end: 0x1bf1, value: 7},    // Consonant_Final
+	{start: 0x1bf2, end: 0x1bf3, value: 26},   // Pure_Killer
+	{start: 0x1c00, end: 0x1c23, value: 5},    // Consonant
+	{start: 0x1c24, end: 0x1c25, value: 15},   // Consonant_Subjoined
+	{start: 0x1c26, end: 0x1c2c, value: 34},   // Vowel_Dependent
+	{start: 0x1c2d, end: 0x1c33, value: 7},    // Consonant_Final
+	{start: 0x1c34, end: 0x1c35, value: 2},    // Bindu
+	{start: 0x1c36, end: 0x1c36, value: 28},   // Syllable_Modifier
+	{start: 0x1c37, end: 0x1c37, value: 23},   // Nukta
+	{start: 0x1c40, end: 0x1c49, value: 24},   // Number
+	{start: 0x1c4d, end: 0x1c4f, value: 5},    // Consonant
+	{start: 0x1cd0, end: 0x1cd2, value: 4},    // Cantillation_Mark
+	{start: 0x1cd4, end: 0x1ce1, value: 4},    // Cantillation_Mark
+	{start: 0x1cf2, end: 0x1cf3, value: 6},    // Consonant_Dead
+	{start: 0x1cf4, end: 0x1cf4, value: 4},    // Cantillation_Mark
+	{start: 0x1cf5, end: 0x1cf6, value: 17},   // Consonant_With_Stacker
+	{start: 0x1cf7, end: 0x1cf9, value: 4},    // Cantillation_Mark
+	{start: 0x1cfa, end: 0x1cfa, value: 12},   // Consonant_Placeholder
+	{start: 0x1dfb, end: 0x1dfb, value: 28},   // Syllable_Modifier
+	{start: 0x200c, end: 0x200c, value: 22},   // Non_Joiner
+	{start: 0x200d, end: 0x200d, value: 20},   // Joiner
+	{start: 0x2010, end: 0x2014, value: 12},   // Consonant_Placeholder
+	{start: 0x2074, end: 0x2074, value: 28},   // Syllable_Modifier
+	{start: 0x2082, end: 0x2084, value: 28},   // Syllable_Modifier
+	{start: 0x20f0, end: 0x20f0, value: 4},    // Cantillation_Mark
+	{start: 0x25cc, end: 0x25cc, value: 12},   // Consonant_Placeholder
+	{start: 0xa800, end: 0xa801, value: 35},   // Vowel_Independent
+	{start: 0xa802, end: 0xa802, value: 34},   // Vowel_Dependent
+	{start: 0xa803, end: 0xa805, value: 35},   // Vowel_Independent
+	{start: 0xa806, end: 0xa806, value: 31},   // Virama
+	{start: 0xa807, end: 0xa80a, value: 5},    // Consonant
+	{start: 0xa80b, end: 0xa80b, value: 2},    // Bindu
+	{start: 0xa80c, end: 0xa822, value: 5},    // Consonant
+	{start: 0xa823, end: 0xa827, value: 34},   // Vowel_Dependent
+	{start: 0xa82c, end: 0xa82c, value: 26},   // Pure_Killer
+	{start: 0xa840, end: 0xa85d, value: 5},    // Consonant
+	{start: 0xa85e, end: 0xa861, value: 33},   // Vowel
+	{start: 0xa862, end: 0xa865, value: 5},    // Consonant
+	{start: 0xa866, end: 0xa866, value: 33},   // Vowel
+	{start: 0xa867, end: 0xa868, value: 15},   // Consonant_Subjoined
+	{start: 0xa869, end: 0xa870, value: 5},    // Consonant
+	{start: 0xa871, end: 0xa871, value: 15},   // Consonant_Subjoined
+	{start: 0xa872, end: 0xa872, value: 5},    // Consonant
+	{start: 0xa873, end: 0xa873, value: 2},    // Bindu
+	{start: 0xa880, end: 0xa880, value: 2},    // Bindu
+	{start: 0xa881, end: 0xa881, value: 32},   // Visarga
+	{start: 0xa882, end: 0xa891, value: 35},   // Vowel_Independent
+	{start: 0xa892, end: 0xa8b3, value: 5},    // Consonant
+	{start: 0xa8b4, end: 0xa8b4, value: 11},   // Consonant_Medial
+	{start: 0xa8b5, end: 0xa8c3, value: 34},   // Vowel_Dependent
+	{start: 0xa8c4, end: 0xa8c4, value: 31},   // Virama
+	{start: 0xa8c5, end: 0xa8c5, value: 2},    // Bindu
+	{start: 0xa8d0, end: 0xa8d9, value: 24},   // Number
+	{start: 0xa8e0, end: 0xa8f1, value: 4},    // Cantillation_Mark
+	{start: 0xa8f2, end: 0xa8f3, value: 2},    // Bindu
+	{start: 0xa8fe, end: 0xa8fe, value: 35},   // Vowel_Independent
+	{start: 0xa8ff, end: 0xa8ff, value: 34},   // Vowel_Dependent
+	{start: 0xa900, end: 0xa909, value: 24},   // Number
+	{start: 0xa90a, end: 0xa921, value: 5},    // Consonant
+	{start: 0xa922, end: 0xa92a, value: 33},   // Vowel
+	{start: 0xa92b, end: 0xa92d, value: 30},   // Tone_Mark
+	{start: 0xa930, end: 0xa946, value: 5},    // Consonant
+	{start: 0xa947, end: 0xa94e, value: 34},   // Vowel_Dependent
+	{start: 0xa94f, end: 0xa952, value: 7},    // Consonant_Final
+	{start: 0xa953, end: 0xa953, value: 26},   // Pure_Killer
+	{start: 0xa980, end: 0xa981, value: 2},    // Bindu
+	{start: 0xa982, end: 0xa982, value: 16},   // Consonant_Succeeding_Repha
+	{start: 0xa983, end: 0xa983, value: 32},   // Visarga
+	{start: 0xa984, end: 0xa988, value: 35},   // Vowel_Independent
+	{start: 0xa989, end: 0xa98b, value: 5},    // Consonant
+	{start: 0xa98c, end: 0xa98e, value: 35},   // Vowel_Independent
+	{start: 0xa98f, end: 0xa9b2, value: 5},    // Consonant
+	{start: 0xa9b3, end: 0xa9b3, value: 23},   // Nukta
+	{start: 0xa9b4, end: 0xa9bc, value: 34},   // Vowel_Dependent
+	{start: 0xa9bd, end: 0xa9bf, value: 11},   // Consonant_Medial
+	{start: 0xa9c0, end: 0xa9c0, value: 31},   // Virama
+	{start: 0xa9d0, end: 0xa9d9, value: 24},   // Number
+	{start: 0xa9e0, end: 0xa9e4, value: 5},    // Consonant
+	{start: 0xa9e5, end: 0xa9e5, value: 34},   // Vowel_Dependent
+	{start: 0xa9e7, end: 0xa9ef, value: 5},    // Consonant
+	{start: 0xa9f0, end: 0xa9f9, value: 24},   // Number
+	{start: 0xa9fa, end: 0xa9fe, value: 5},    // Consonant
+	{start: 0xaa00, end: 0xaa05, value: 35},   // Vowel_Independent
+	{start: 0xaa06, end: 0xaa28, value: 5},    // Consonant
+	{start: 0xaa29, end: 0xaa32, value: 34},   // Vowel_Dependent
+	{start: 0xaa33, end: 0xaa36, value: 11},   // Consonant_Medial
+	{start: 0xaa40, end: 0xaa4d, value: 7},    // Consonant_Final
+	{start: 0xaa50, end: 0xaa59, value: 24},   // Number
+	{start: 0xaa60, end: 0xaa6f, value: 5},    // Consonant
+	{start: 0xaa71, end: 0xaa73, value: 5},    // Consonant
+	{start: 0xaa74, end: 0xaa76, value: 12},   // Consonant_Placeholder
+	{start: 0xaa7a, end: 0xaa7a, value: 5},    // Consonant
+	{start: 0xaa7b, end: 0xaa7d, value: 30},   // Tone_Mark
+	{start: 0xaa7e, end: 0xaaaf, value: 5},    // Consonant
+	{start: 0xaab0, end: 0xaabe, value: 34},   // Vowel_Dependent
+	{start: 0xaabf, end: 0xaabf, value: 30},   // Tone_Mark
+	{start: 0xaac0, end: 0xaac0, value: 29},   // Tone_Letter
+	{start: 0xaac1, end: 0xaac1, value: 30},   // Tone_Mark
+	{start: 0xaac2, end: 0xaac2, value: 29},   // Tone_Letter
+	{start: 0xaae0, end: 0xaae1, value: 35},   // Vowel_Independent
+	{start: 0xaae2, end: 0xaaea, value: 5},    // Consonant
+	{start: 0xaaeb, end: 0xaaef, value: 34},   // Vowel_Dependent
+	{start: 0xaaf5, end: 0xaaf5, value: 32},   // Visarga
+	{start: 0xaaf6, end: 0xaaf6, value: 19},   // Invisible_Stacker
+	{start: 0xabc0, end: 0xabcd, value: 5},    // Consonant
+	{start: 0xabce, end: 0xabcf, value: 35},   // Vowel_Independent
+	{start: 0xabd0, end: 0xabd0, value: 5},    // Consonant
+	{start: 0xabd1, end: 0xabd1, value: 35},   // Vowel_Independent
+	{start: 0xabd2, end: 0xabda, value: 5},    // Consonant
+	{start: 0xabdb, end: 0xabe2, value: 7},    // Consonant_Final
+	{start: 0xabe3, end: 0xabea, value: 34},   // Vowel_Dependent
+	{start: 0xabec, end: 0xabec, value: 30},   // Tone_Mark
+	{start: 0xabed, end: 0xabed, value: 26},   // Pure_Killer
+	{start: 0xabf0, end: 0xabf9, value: 24},   // Number
+	{start: 0x10a00, end: 0x10a00, value: 5},  // Consonant
+	{start: 0x10a01, end: 0x10a03, value: 34}, // Vowel_Dependent
+	{start: 0x10a05, end: 0x10a06, value: 34}, // Vowel_Dependent
+	{start: 0x10a0c, end: 0x10a0d, value: 34}, // Vowel_Dependent
+	{start: 0x10a0e, end: 0x10a0e, value: 2},  // Bindu
+	{start: 0x10a0f, end: 0x10a0f, value: 32}, // Visarga
+	{start: 0x10a10, end: 0x10a13, value: 5},  // Consonant
+	{start: 0x10a15, end: 0x10a17, value: 5},  // Consonant
+	{start: 0x10a19, end: 0x10a35, value: 5},  // Consonant
+	{start: 0x10a38, end: 0x10a3a, value: 23}, // Nukta
+	{start: 0x10a3f, end: 0x10a3f, value: 19}, // Invisible_Stacker
+	{start: 0x10a40, end: 0x10a48, value: 24}, // Number
+	{start: 0x11000, end: 0x11001, value: 2},  // Bindu
+	{start: 0x11002, end: 0x11002, value: 32}, // Visarga
+	{start: 0x11003, end: 0x11004, value: 17}, // Consonant_With_Stacker
+	{start: 0x11005, end: 0x11012, value: 35}, // Vowel_Independent
+	{start: 0x11013, end: 0x11037, value: 5},  // Consonant
+	{start: 0x11038, end: 0x11045, value: 34}, // Vowel_Dependent
+	{start: 0x11046, end: 0x11046, value: 31}, // Virama
+	{start: 0x11052, end: 0x11065, value: 3},  // Brahmi_Joining_Number
+	{start: 0x11066, end: 0x1106f, value: 24}, // Number
+	{start: 0x1107f, end: 0x1107f, value: 25}, // Number_Joiner
+	{start: 0x11080, end: 0x11081, value: 2},  // Bindu
+	{start: 0x11082, end: 0x11082, value: 32}, // Visarga
+	{start: 0x11083, end: 0x1108c, value: 35}, // Vowel_Independent
+	{start: 0x1108d, end: 0x110af, value: 5},  // Consonant
+	{start: 0x110b0, end: 0x110b8, value: 34}, // Vowel_Dependent
+	{start: 0x110b9, end: 0x110b9, value: 31}, // Virama
+	{start: 0x110ba, end: 0x110ba, value: 23}, // Nukta
+	{start: 0x11100, end: 0x11101, value: 2},  // Bindu
+	{start: 0x11102, end: 0x11102, value: 32}, // Visarga
+	{start: 0x11103, end: 0x11106, value: 35}, // Vowel_Independent
+	{start: 0x11107, end: 0x11126, value: 5},  // Consonant
+	{start: 0x11127, end: 0x11132, value: 34}, // Vowel_Dependent
+	{start: 0x11133, end: 0x11133, value: 19}, // Invisible_Stacker
+	{start: 0x11134, end: 0x11134, value: 26}, // Pure_Killer
+	{start: 0x11136, end: 0x1113f, value: 24}, // Number
+	{start: 0x11144, end: 0x11144, value: 5},  // Consonant
+	{start: 0x11145, end: 0x11146, value: 34}, // Vowel_Dependent
+	{start: 0x11147, end: 0x11147, value: 5},  // Consonant
+	{start: 0x11150, end: 0x11154, value: 33}, // Vowel
+	{start: 0x11155, end: 0x11172, value: 5},  // Consonant
+	{start: 0x11173, end: 0x11173, value: 23}, // Nukta
+	{start: 0x11180, end: 0x11181, value: 2},  // Bindu
+	{start: 0x11182, end: 0x11182, value: 32}, // Visarga
+	{start: 0x11183, end: 0x11190, value: 35}, // Vowel_Independent
+	{start: 0x11191, end: 0x111b2, value: 5},  // Consonant
+	{start: 0x111b3, end: 0x111bf, value: 34}, // Vowel_Dependent
+	{start: 0x111c0, end: 0x111c0, value: 31}, // Virama
+	{start: 0x111c1, end: 0x111c1, value: 1},  // Avagraha
+	{start: 0x111c2, end: 0x111c3, value: 14}, // Consonant_Prefixed
+	{start: 0x111c9, end: 0x111c9, value: 28}, // Syllable_Modifier
+	{start: 0x111ca, end: 0x111ca, value: 23}, // Nukta
+	{start: 0x111cb, end: 0x111cc, value: 34}, // Vowel_Dependent
+	{start: 0x111ce, end: 0x111ce, value: 34}, // Vowel_Dependent
+	{start: 0x111cf, end: 0x111cf, value: 2},  // Bindu
+	{start: 0x111d0, end: 0x111d9, value: 24}, // Number
+	{start: 0x111e1, end: 0x111f4, value: 24}, // Number
+	{start: 0x11200, end: 0x11207, value: 35}, // Vowel_Independent
+	{start: 0x11208, end: 0x11211, value: 5},  // Consonant
+	{start: 0x11213, end: 0x1122b, value: 5},  // Consonant
+	{start: 0x1122c, end: 0x11233, value: 34}, // Vowel_Dependent
+	{start: 0x11234, end: 0x11234, value: 2},  // Bindu
+	{start: 0x11235, end: 0x11235, value: 31}, // Virama
+	{start: 0x11236, end: 0x11236, value: 23}, // Nukta
+	{start: 0x11237, end: 0x11237, value: 18}, // Gemination_Mark
+	{start: 0x1123e, end: 0x1123e, value: 4},  // Cantillation_Mark
+	{start: 0x11280, end: 0x11283, value: 35}, // Vowel_Independent
+	{start: 0x11284, end: 0x11286, value: 5},  // Consonant
+	{start: 0x11288, end: 0x11288, value: 5},  // Consonant
+	{start: 0x1128a, end: 0x1128d, value: 5},  // Consonant
+	{start: 0x1128f, end: 0x1129d, value: 5},  // Consonant
+	{start: 0x1129f, end: 0x112a8, value: 5},  // Consonant
+	{start: 0x112b0, end: 0x112b9, value: 35}, // Vowel_Independent
+	{start: 0x112ba, end: 0x112de, value: 5},  // Consonant
+	{start: 0x112df, end: 0x112df, value: 2},  // Bindu
+	{start: 0x112e0, end: 0x112e8, value: 34}, // Vowel_Dependent
+	{start: 0x112e9, end: 0x112e9, value: 23}, // Nukta
+	{start: 0x112ea, end: 0x112ea, value: 26}, // Pure_Killer
+	{start: 0x112f0, end: 0x112f9, value: 24}, // Number
+	{start: 0x11300, end: 0x11302, value: 2},  // Bindu
+	{start: 0x11303, end: 0x11303, value: 32}, // Visarga
+	{start: 0x11305, end: 0x1130c, value: 35}, // Vowel_Independent
+	{start: 0x1130f, end: 0x11310, value: 35}, // Vowel_Independent
+	{start: 0x11313, end: 0x11314, value: 35}, // Vowel_Independent
+	{start: 0x11315, end: 0x11328, value: 5},  // Consonant
+	{start: 0x1132a, end: 0x11330, value: 5},  // Consonant
+	{start: 0x11332, end: 0x11333, value: 5},  // Consonant
+	{start: 0x11335, end: 0x11339, value: 5},  // Consonant
+	{start: 0x1133b, end: 0x1133c, value: 23}, // Nukta
+	{start: 0x1133d, end: 0x1133d, value: 1},  // Avagraha
+	{start: 0x1133e, end: 0x11344, value: 34}, // Vowel_Dependent
+	{start: 0x11347, end: 0x11348, value: 34}, // Vowel_Dependent
+	{start: 0x1134b, end: 0x1134c, value: 34}, // Vowel_Dependent
+	{start: 0x1134d, end: 0x1134d, value: 31}, // Virama
+	{start: 0x11357, end: 0x11357, value: 34}, // Vowel_Dependent
+	{start: 0x1135e, end: 0x1135f, value: 2},  // Bindu
+	{start: 0x11360, end: 0x11361, value: 35}, // Vowel_Independent
+	{start: 0x11362, end: 0x11363, value: 34}, // Vowel_Dependent
+	{start: 0x11366, end: 0x1136c, value: 4},  // Cantillation_Mark
+	{start: 0x11370, end: 0x11374, value: 4},  // Cantillation_Mark
+	{start: 0x11400, end: 0x1140d, value: 35}, // Vowel_Independent
+	{start: 0x1140e, end: 0x11434, value: 5},  // Consonant
+	{start: 0x11435, end: 0x11441, value: 34}, // Vowel_Dependent
+	{start: 0x11442, end: 0x11442, value: 31}, // Virama
+	{start: 0x11443, end: 0x11444, value: 2},  // Bindu
+	{start: 0x11445, end: 0x11445, value: 32}, // Visarga
+	{start: 0x11446, end: 0x11446, value: 23}, // Nukta
+	{start: 0x11447, end: 0x11447, value: 1},  // Avagraha
+	{start: 0x11450, end: 0x11459, value: 24}, // Number
+	{start: 0x1145e, end: 0x1145e, value: 28}, // Syllable_Modifier
+	{start: 0x1145f, end: 0x1145f, value: 2},  // Bindu
+	{start: 0x11460, end: 0x11461, value: 17}, // Consonant_With_Stacker
+	{start: 0x11481, end: 0x1148e, value: 35}, // Vowel_Independent
+	{start: 0x1148f, end: 0x114af, value: 5},  // Consonant
+	{start: 0x114b0, end: 0x114be, value: 34}, // Vowel_Dependent
+	{start: 0x114bf, end: 0x114c0, value: 2},  // Bindu
+	{start: 0x114c1, end: 0x114c1, value: 32}, // Visarga
+	{start: 0x114c2, end: 0x114c2, value: 31}, // Virama
+	{start: 0x114c3, end: 0x114c3, value: 23}, // Nukta
+	{start: 0x114c4, end: 0x114c4, value: 1},  // Avagraha
+	{start: 0x114d0, end: 0x114d9, value: 24}, // Number
+	{start: 0x11580, end: 0x1158d, value: 35}, // Vowel_Independent
+	{start: 0x1158e, end: 0x115ae, value: 5},  // Consonant
+	{start: 0x115af, end: 0x115b5, value: 34}, // Vowel_Dependent
+	{start: 0x115b8, end: 0x115bb, value: 34}, // Vowel_Dependent
+	{start: 0x115bc, end: 0x115bd, value: 2},  // Bindu
+	{start: 0x115be, end: 0x115be, value: 32}, // Visarga
+	{start: 0x115bf, end: 0x115bf, value: 31}, // Virama
+	{start: 0x115c0, end: 0x115c0, value: 23}, // Nukta
+	{start: 0x115d8, end: 0x115db, value: 35}, // Vowel_Independent
+	{start: 0x115dc, end: 0x115dd, value: 34}, // Vowel_Dependent
+	{start: 0x11600, end: 0x1160d, value: 35}, // Vowel_Independent
+	{start: 0x1160e, end: 0x1162f, value: 5},  // Consonant
+	{start: 0x11630, end: 0x1163c, value: 34}, // Vowel_Dependent
+	{start: 0x1163d, end: 0x1163d, value: 2},  // Bindu
+	{start: 0x1163e, end: 0x1163e, value: 32}, // Visarga
+	{start: 0x1163f, end: 0x1163f, value: 31}, // Virama
+	{start: 0x11640, end: 0x11640, value: 34}, // Vowel_Dependent
+	{start: 0x11650, end: 0x11659, value: 24}, // Number
+	{start: 0x11680, end: 0x11689, value: 35}, // Vowel_Independent
+	{start: 0x1168a, end: 0x116aa, value: 5},  // Consonant
+	{start: 0x116ab, end: 0x116ab, value: 2},  // Bindu
+	{start: 0x116ac, end: 0x116ac, value: 32}, // Visarga
+	{start: 0x116ad, end: 0x116b5, value: 34}, // Vowel_Dependent
+	{start: 0x116b6, end: 0x116b6, value: 31}, // Virama
+	{start: 0x116b7, end: 0x116b7, value: 23}, // Nukta
+	{start: 0x116b8, end: 0x116b8, value: 5},  // Consonant
+	{start: 0x116c0, end: 0x116c9, value: 24}, // Number
+	{start: 0x11700, end: 0x1171a, value: 5},  // Consonant
+	{start: 0x1171d, end: 0x1171f, value: 11}, // Consonant_Medial
+	{start: 0x11720, end: 0x1172a, value: 34}, // Vowel_Dependent
+	{start: 0x1172b, end: 0x1172b, value: 26}, // Pure_Killer
+	{start: 0x11730, end: 0x1173b, value: 24}, // Number
+	{start: 0x11800, end: 0x11809, value: 35}, // Vowel_Independent
+	{start: 0x1180a, end: 0x1182b, value: 5},  // Consonant
+	{start: 0x1182c, end: 0x11836, value: 34}, // Vowel_Dependent
+	{start: 0x11837, end: 0x11837, value: 2},  // Bindu
+	{start: 0x11838, end: 0x11838, value: 32}, // Visarga
+	{start: 0x11839, end: 0x11839, value: 31}, // Virama
+	{start: 0x1183a, end: 0x1183a, value: 23}, // Nukta
+	{start: 0x11900, end: 0x11906, value: 35}, // Vowel_Independent
+	{start: 0x11909, end: 0x11909, value: 35}, // Vowel_Independent
+	{start: 0x1190c, end: 0x11913, value: 5},  // Consonant
+	{start: 0x11915, end: 0x11916, value: 5},  // Consonant
+	{start: 0x11918, end: 0x1192f, value: 5},  // Consonant
+	{start: 0x11930, end: 0x11935, value: 34}, // Vowel_Dependent
+	{start: 0x11937, end: 0x11938, value: 34}, // Vowel_Dependent
+	{start: 0x1193b, end: 0x1193c, value: 2},  // Bindu
+	{start: 0x1193d, end: 0x1193d, value: 26}, // Pure_Killer
+	{start: 0x1193e, end: 0x1193e, value: 19}, // Invisible_Stacker
+	{start: 0x1193f, end: 0x1193f, value: 14}, // Consonant_Prefixed
+	{start: 0x11940, end: 0x11940, value: 11}, // Consonant_Medial
+	{start: 0x11941, end: 0x11941, value: 13}, // Consonant_Preceding_Repha
+	{start: 0x11942, end: 0x11942, value: 11}, // Consonant_Medial
+	{start: 0x11943, end: 0x11943, value: 23}, // Nukta
+	{start: 0x11950, end: 0x11959, value: 24}, // Number
+	{start: 0x119a0, end: 0x119a7, value: 35}, // Vowel_Independent
+	{start: 0x119aa, end: 0x119ad, value: 35}, // Vowel_Independent
+	{start: 0x119ae, end: 0x119d0, value: 5},  // Consonant
+	{start: 0x119d1, end: 0x119d7, value: 34}, // Vowel_Dependent
+	{start: 0x119da, end: 0x119dd, value: 34}, // Vowel_Dependent
+	{start: 0x119de, end: 0x119de, value: 2},  // Bindu
+	{start: 0x119df, end: 0x119df, value: 32}, // Visarga
+	{start: 0x119e0, end: 0x119e0, value: 31}, // Virama
+	{start: 0x119e1, end: 0x119e1, value: 1},  // Avagraha
+	{start: 0x119e4, end: 0x119e4, value: 34}, // Vowel_Dependent
+	{start: 0x11a00, end: 0x11a00, value: 35}, // Vowel_Independent
+	{start: 0x11a01, end: 0x11a0a, value: 34}, // Vowel_Dependent
+	{start: 0x11a0b, end: 0x11a32, value: 5},  // Consonant
+	{start: 0x11a33, end: 0x11a33, value: 28}, // Syllable_Modifier
+	{start: 0x11a34, end: 0x11a34, value: 26}, // Pure_Killer
+	{start: 0x11a35, end: 0x11a38, value: 2},  // Bindu
+	{start: 0x11a39, end: 0x11a39, value: 32}, // Visarga
+	{start: 0x11a3a, end: 0x11a3a, value: 14}, // Consonant_Prefixed
+	{start: 0x11a3b, end: 0x11a3e, value: 11}, // Consonant_Medial
+	{start: 0x11a3f, end: 0x11a3f, value: 12}, // Consonant_Placeholder
+	{start: 0x11a45, end: 0x11a45, value: 12}, // Consonant_Placeholder
+	{start: 0x11a47, end: 0x11a47, value: 19}, // Invisible_Stacker
+	{start: 0x11a50, end: 0x11a50, value: 35}, // Vowel_Independent
+	{start: 0x11a51, end: 0x11a5b, value: 34}, // Vowel_Dependent
+	{start: 0x11a5c, end: 0x11a83, value: 5},  // Consonant
+	{start: 0x11a84, end: 0x11a89, value: 14}, // Consonant_Prefixed
+	{start: 0x11a8a, end: 0x11a95, value: 7},  // Consonant_Final
+	{start: 0x11a96, end: 0x11a96, value: 2},  // Bindu
+	{start: 0x11a97, end: 0x11a97, value: 32}, // Visarga
+	{start: 0x11a98, end: 0x11a98, value: 18}, // Gemination_Mark
+	{start: 0x11a99, end: 0x11a99, value: 19}, // Invisible_Stacker
+	{start: 0x11a9d, end: 0x11a9d, value: 1},  // Avagraha
+	{start: 0x11c00, end: 0x11c08, value: 35}, // Vowel_Independent
+	{start: 0x11c0a, end: 0x11c0d, value: 35}, // Vowel_Independent
+	{start: 0x11c0e, end: 0x11c2e, value: 5},  // Consonant
+	{start: 0x11c2f, end: 0x11c36, value: 34}, // Vowel_Dependent
+	{start: 0x11c38, end: 0x11c3b, value: 34}, // Vowel_Dependent
+	{start: 0x11c3c, end: 0x11c3d, value: 2},  // Bindu
+	{start: 0x11c3e, end: 0x11c3e, value: 32}, // Visarga
+	{start: 0x11c3f, end: 0x11c3f, value: 31}, // Virama
+	{start: 0x11c40, end: 0x11c40, value: 1},  // Avagraha
+	{start: 0x11c50, end: 0x11c6c, value: 24}, // Number
+	{start: 0x11c72, end: 0x11c8f, value: 5},  // Consonant
+	{start: 0x11c92, end: 0x11ca7, value: 15}, // Consonant_Subjoined
+	{start: 0x11ca9, end: 0x11caf, value: 15}, // Consonant_Subjoined
+	{start: 0x11cb0, end: 0x11cb4, value: 34}, // Vowel_Dependent
+	{start: 0x11cb5, end: 0x11cb6, value: 2},  // Bindu
+	{start: 0x11d00, end: 0x11d06, value: 35}, // Vowel_Independent
+	{start: 0x11d08, end: 0x11d09, value: 35}, // Vowel_Independent
+	{start: 0x11d0b, end: 0x11d0b, value: 35}, // Vowel_Independent
+	{start: 0x11d0c, end: 0x11d30, value: 5},  // Consonant
+	{start: 0x11d31, end: 0x11d36, value: 34}, // Vowel_Dependent
+	{start: 0x11d3a, end: 0x11d3a, value: 34}, // Vowel_Dependent
+	{start: 0x11d3c, end: 0x11d3d, value: 34}, // Vowel_Dependent
+	{start: 0x11d3f, end: 0x11d3f, value: 34}, // Vowel_Dependent
+	{start: 0x11d40, end: 0x11d40, value: 2},  // Bindu
+	{start: 0x11d41, end: 0x11d41, value: 32}, // Visarga
+	{start: 0x11d42, end: 0x11d42, value: 23}, // Nukta
+	{start: 0x11d43, end: 0x11d43, value: 34}, // Vowel_Dependent
+	{start: 0x11d44, end: 0x11d44, value: 26}, // Pure_Killer
+	{start: 0x11d45, end: 0x11d45, value: 19}, // Invisible_Stacker
+	{start: 0x11d46, end: 0x11d46, value: 13}, // Consonant_Preceding_Repha
+	{start: 0x11d47, end: 0x11d47, value: 11}, // Consonant_Medial
+	{start: 0x11d50, end: 0x11d59, value: 24}, // Number
+	{start: 0x11d60, end: 0x11d65, value: 35}, // Vowel_Independent
+	{start: 0x11d67, end: 0x11d68, value: 35}, // Vowel_Independent
+	{start: 0x11d6a, end: 0x11d6b, value: 35}, // Vowel_Independent
+	{start: 0x11d6c, end: 0x11d89, value: 5},  // Consonant
+	{start: 0x11d8a, end: 0x11d8e, value: 34}, // Vowel_Dependent
+	{start: 0x11d90, end: 0x11d91, value: 34}, // Vowel_Dependent
+	{start: 0x11d93, end: 0x11d94, value: 34}, // Vowel_Dependent
+	{start: 0x11d95, end: 0x11d95, value: 2},  // Bindu
+	{start: 0x11d96, end: 0x11d96, value: 32}, // Visarga
+	{start: 0x11d97, end: 0x11d97, value: 19}, // Invisible_Stacker
+	{start: 0x11da0, end: 0x11da9, value: 24}, // Number
+	{start: 0x11ee0, end: 0x11ef1, value: 5},  // Consonant
+	{start: 0x11ef2, end: 0x11ef2, value: 12}, // Consonant_Placeholder
+	{start: 0x11ef3, end: 0x11ef6, value: 34}, // Vowel_Dependent
+}
+
+// values of IndicPositionalCategory (see its String method for the UCD names)
+const (
+	IndicPositionalNA IndicPositionalCategory = iota
+	IndicPositionalBottom
+	IndicPositionalBottomAndLeft
+	IndicPositionalBottomAndRight
+	IndicPositionalLeft
+	IndicPositionalLeftAndRight
+	IndicPositionalOverstruck
+	IndicPositionalRight
+	IndicPositionalTop
+	IndicPositionalTopAndBottom
+	IndicPositionalTopAndBottomAndLeft
+	IndicPositionalTopAndBottomAndRight
+	IndicPositionalTopAndLeft
+	IndicPositionalTopAndLeftAndRight
+	IndicPositionalTopAndRight
+	IndicPositionalVisualOrderLeft
+)
+
+var indicPositionalCategoryNames = [...]string{
+	"NA",
+	"Bottom",
+	"Bottom_And_Left",
+	"Bottom_And_Right",
+	"Left",
+	"Left_And_Right",
+	"Overstruck",
+	"Right",
+	"Top",
+	"Top_And_Bottom",
+	"Top_And_Bottom_And_Left",
+	"Top_And_Bottom_And_Right",
+	"Top_And_Left",
+	"Top_And_Left_And_Right",
+	"Top_And_Right",
+	"Visual_Order_Left",
+}
+
+var indicPositionalCategoryRanges = [...]propertyRange{
+	{start: 0x0900, end: 0x0902, value: 8},    // Top
+	{start: 0x0903, end: 0x0903, value: 7},    // Right
+	{start: 0x093a, end: 0x093a, value: 8},    // Top
+	{start: 0x093b, end: 0x093b, value: 7},    // Right
+	{start: 0x093c, end: 0x093c, value: 1},    // Bottom
+	{start: 0x093e, end: 0x093e, value: 7},    // Right
+	{start: 0x093f, end: 0x093f, value: 4},    // Left
+	{start: 0x0940, end: 0x0940, value: 7},    // Right
+	{start: 0x0941, end: 0x0944, value: 1},    // Bottom
+	{start: 0x0945, end: 0x0948, value: 8},    // Top
+	{start: 0x0949, end: 0x094c, value: 7},    // Right
+	{start: 0x094d, end: 0x094d, value: 1},    // Bottom
+	{start: 0x094e, end: 0x094e, value: 4},    // Left
+	{start: 0x094f, end: 0x094f, value: 7},    // Right
+	{start: 0x0951, end: 0x0951, value: 8},    // Top
+	{start: 0x0952, end: 0x0952, value: 1},    // Bottom
+	{start: 0x0953, end: 0x0955, value: 8},    // Top
+	{start: 0x0956, end: 0x0957, value: 1},    // Bottom
+	{start: 0x0962, end: 0x0963, value: 1},    // Bottom
+	{start: 0x0981, end: 0x0981, value: 8},    // Top
+	{start: 0x0982, end: 0x0983, value: 7},    // Right
+	{start: 0x09bc, end: 0x09bc, value: 1},    // Bottom
+	{start: 0x09be, end: 0x09be, value: 7},    // Right
+	{start: 0x09bf, end: 0x09bf, value: 4},    // Left
+	{start: 0x09c0, end: 0x09c0, value: 7},    // Right
+	{start: 0x09c1, end: 0x09c4, value: 1},    // Bottom
+	{start: 0x09c7, end: 0x09c8, value: 4},    // Left
+	{start: 0x09cb, end: 0x09cc, value: 5},    // Left_And_Right
+	{start: 0x09cd, end: 0x09cd, value: 1},    // Bottom
+	{start: 0x09d7, end: 0x09d7, value: 7},    // Right
+	{start: 0x09e2, end: 0x09e3, value: 1},    // Bottom
+	{start: 0x09fe, end: 0x09fe, value: 8},    // Top
+	{start: 0x0a01, end: 0x0a02, value: 8},    // Top
+	{start: 0x0a03, end: 0x0a03, value: 7},    // Right
+	{start: 0x0a3c, end: 0x0a3c, value: 1},    // Bottom
+	{start: 0x0a3e, end: 0x0a3e, value: 7},    // Right
+	{start: 0x0a3f, end: 0x0a3f, value: 4},    // Left
+	{start: 0x0a40, end: 0x0a40, value: 7},    // Right
+	{start: 0x0a41, end: 0x0a42, value: 1},    // Bottom
+	{start: 0x0a47, end: 0x0a48, value: 8},    // Top
+	{start: 0x0a4b, end: 0x0a4c, value: 8},    // Top
+	{start: 0x0a4d, end: 0x0a4d, value: 1},    // Bottom
+	{start: 0x0a51, end: 0x0a51, value: 1},    // Bottom
+	{start: 0x0a70, end: 0x0a71, value: 8},    // Top
+	{start: 0x0a75, end: 0x0a75, value: 1},    // Bottom
+	{start: 0x0a81, end: 0x0a82, value: 8},    // Top
+	{start: 0x0a83, end: 0x0a83, value: 7},    // Right
+	{start: 0x0abc, end: 0x0abc, value: 1},    // Bottom
+	{start: 0x0abe, end: 0x0abe, value: 7},    // Right
+	{start: 0x0abf, end: 0x0abf, value: 4},    // Left
+	{start: 0x0ac0, end: 0x0ac0, value: 7},    // Right
+	{start: 0x0ac1, end: 0x0ac4, value: 1},    // Bottom
+	{start: 0x0ac5, end: 0x0ac5, value: 8},    // Top
+	{start: 0x0ac7, end: 0x0ac8, value: 8},    // Top
+	{start: 0x0ac9, end: 0x0ac9, value: 14},   // Top_And_Right
+	{start: 0x0acb, end: 0x0acc, value: 7},    // Right
+	{start: 0x0acd, end: 0x0acd, value: 1},    // Bottom
+	{start: 0x0ae2, end: 0x0ae3, value: 1},    // Bottom
+	{start: 0x0afa, end: 0x0aff, value: 8},    // Top
+	{start: 0x0b01, end: 0x0b01, value: 8},    // Top
+	{start: 0x0b02, end: 0x0b03, value: 7},    // Right
+	{start: 0x0b3c, end: 0x0b3c, value: 1},    // Bottom
+	{start: 0x0b3e, end: 0x0b3e, value: 7},    // Right
+	{start: 0x0b3f, end: 0x0b3f, value: 8},    // Top
+	{start: 0x0b40, end: 0x0b40, value: 7},    // Right
+	{start: 0x0b41, end: 0x0b44, value: 1},    // Bottom
+	{start: 0x0b47, end: 0x0b47, value: 4},    // Left
+	{start: 0x0b48, end: 0x0b48, value: 12},   // Top_And_Left
+	{start: 0x0b4b, end: 0x0b4b, value: 5},    // Left_And_Right
+	{start: 0x0b4c, end: 0x0b4c, value: 13},   // Top_And_Left_And_Right
+	{start: 0x0b4d, end: 0x0b4d, value: 1},    // Bottom
+	{start: 0x0b55, end: 0x0b56, value: 8},    // Top
+	{start: 0x0b57, end: 0x0b57, value: 14},   // Top_And_Right
+	{start: 0x0b62, end: 0x0b63, value: 1},    // Bottom
+	{start: 0x0b82, end: 0x0b82, value: 8},    // Top
+	{start: 0x0bbe, end: 0x0bbf, value: 7},    // Right
+	{start: 0x0bc0, end: 0x0bc0, value: 8},    // Top
+	{start: 0x0bc1, end: 0x0bc2, value: 7},    // Right
+	{start: 0x0bc6, end: 0x0bc8, value: 4},    // Left
+	{start: 0x0bca, end: 0x0bcc, value: 5},    // Left_And_Right
+	{start: 0x0bcd, end: 0x0bcd, value: 8},    // Top
+	{start: 0x0bd7, end: 0x0bd7, value: 7},    // Right
+	{start: 0x0c00, end: 0x0c00, value: 8},    // Top
+	{start: 0x0c01, end: 0x0c03, value: 7},    // Right
+	{start: 0x0c04, end: 0x0c04, value: 8},    // Top
+	{start: 0x0c3e, end: 0x0c40, value: 8},    // Top
+	{start: 0x0c41, end: 0x0c44, value: 7},    // Right
+	{start: 0x0c46, end: 0x0c47, value: 8},    // Top
+	{start: 0x0c48, end: 0x0c48, value: 9},    // Top_And_Bottom
+	{start: 0x0c4a, end: 0x0c4d, value: 8},    // Top
+	{start: 0x0c55, end: 0x0c55, value: 8},    // Top
+	{start: 0x0c56, end: 0x0c56, value: 1},    // Bottom
+	{start: 0x0c62, end: 0x0c63, value: 1},    // Bottom
+	{start: 0x0c81, end: 0x0c81, value: 8},    // Top
+	{start: 0x0c82, end: 0x0c83, value: 7},    // Right
+	{start: 0x0cbc, end: 0x0cbc, value: 1},    // Bottom
+	{start: 0x0cbe, end: 0x0cbe, value: 7},    // Right
+	{start: 0x0cbf, end: 0x0cbf, value: 8},    // Top
+	{start: 0x0cc0, end: 0x0cc0, value: 14},   // Top_And_Right
+	{start: 0x0cc1, end: 0x0cc4, value: 7},    // Right
+	{start: 0x0cc6, end: 0x0cc6, value: 8},    // Top
+	{start: 0x0cc7, end: 0x0cc8, value: 14},   // Top_And_Right
+	{start: 0x0cca, end: 0x0ccb, value: 14},   // Top_And_Right
+	{start: 0x0ccc, end: 0x0ccd, value: 8},    // Top
+	{start: 0x0cd5, end: 0x0cd6, value: 7},    // Right
+	{start: 0x0ce2, end: 0x0ce3, value: 1},    // Bottom
+	{start: 0x0d00, end: 0x0d01, value: 8},    // Top
+	{start: 0x0d02, end: 0x0d03, value: 7},    // Right
+	{start: 0x0d3b, end: 0x0d3c, value: 8},    // Top
+	{start: 0x0d3e, end: 0x0d42, value: 7},    // Right
+	{start: 0x0d43, end: 0x0d44, value: 1},    // Bottom
+	{start: 0x0d46, end: 0x0d48, value: 4},    // Left
+	{start: 0x0d4a, end: 0x0d4c, value: 5},    // Left_And_Right
+	{start: 0x0d4d, end: 0x0d4e, value: 8},    // Top
+	{start: 0x0d57, end: 0x0d57, value: 7},    // Right
+	{start: 0x0d62, end: 0x0d63, value: 1},    // Bottom
+	{start: 0x0d81, end: 0x0d81, value: 8},    // Top
+	{start: 0x0d82, end: 0x0d83, value: 7},    // Right
+	{start: 0x0dca, end: 0x0dca, value: 8},    // Top
+	{start: 0x0dcf, end: 0x0dd1, value: 7},    // Right
+	{start: 0x0dd2, end: 0x0dd3, value: 8},    // Top
+	{start: 0x0dd4, end: 0x0dd4, value: 1},    // Bottom
+	{start: 0x0dd6, end: 0x0dd6, value: 1},    // Bottom
+	{start: 0x0dd8, end: 0x0dd8, value: 7},    // Right
+	{start: 0x0dd9, end: 0x0dd9, value: 4},    // Left
+	{start: 0x0dda, end: 0x0dda, value: 12},   // Top_And_Left
+	{start: 0x0ddb, end: 0x0ddb, value: 4},    // Left
+	{start: 0x0ddc, end: 0x0ddc, value: 5},    // Left_And_Right
+	{start: 0x0ddd, end: 0x0ddd, value: 13},   // Top_And_Left_And_Right
+	{start: 0x0dde, end: 0x0dde, value: 5},    // Left_And_Right
+	{start: 0x0ddf, end: 0x0ddf, value: 7},    // Right
+	{start: 0x0df2, end: 0x0df3, value: 7},    // Right
+	{start: 0x0e30, end: 0x0e30, value: 7},    // Right
+	{start: 0x0e31, end: 0x0e31, value: 8},    // Top
+	{start: 0x0e32, end: 0x0e33, value: 7},    // Right
+	{start: 0x0e34, end: 0x0e37, value: 8},    // Top
+	{start: 0x0e38, end: 0x0e3a, value: 1},    // Bottom
+	{start: 0x0e40, end: 0x0e44, value: 15},   // Visual_Order_Left
+	{start: 0x0e45, end: 0x0e45, value: 7},    // Right
+	{start: 0x0e47, end: 0x0e4e, value: 8},    // Top
+	{start: 0x0eb0, end: 0x0eb0, value: 7},    // Right
+	{start: 0x0eb1, end: 0x0eb1, value: 8},    // Top
+	{start: 0x0eb2, end: 0x0eb3, value: 7},    // Right
+	{start: 0x0eb4, end: 0x0eb7, value: 8},    // Top
+	{start: 0x0eb8, end: 0x0eba, value: 1},    // Bottom
+	{start: 0x0ebb, end: 0x0ebb, value: 8},    // Top
+	{start: 0x0ebc, end: 0x0ebc, value: 1},    // Bottom
+	{start: 0x0ec0, end: 0x0ec4, value: 15},   // Visual_Order_Left
+	{start: 0x0ec8, end: 0x0ecd, value: 8},    // Top
+	{start: 0x0f18, end: 0x0f19, value: 1},    // Bottom
+	{start: 0x0f35, end: 0x0f35, value: 1},    // Bottom
+	{start: 0x0f37, end: 0x0f37, value: 1},    // Bottom
+	{start: 0x0f39, end: 0x0f39, value: 8},    // Top
+	{start: 0x0f3e, end: 0x0f3e, value: 7},    // Right
+	{start: 0x0f3f, end: 0x0f3f, value: 4},    // Left
+	{start: 0x0f71, end: 0x0f71, value: 1},    // Bottom
+	{start: 0x0f72, end: 0x0f72, value: 8},    // Top
+	{start: 0x0f73, end: 0x0f73, value: 9},    // Top_And_Bottom
+	{start: 0x0f74, end: 0x0f75, value: 1},    // Bottom
+	{start: 0x0f76, end: 0x0f79, value: 9},    // Top_And_Bottom
+	{start: 0x0f7a, end: 0x0f7e, value: 8},    // Top
+	{start: 0x0f7f, end: 0x0f7f, value: 7},    // Right
+	{start: 0x0f80, end: 0x0f80, value: 8},    // Top
+	{start: 0x0f81, end: 0x0f81, value: 9},    // Top_And_Bottom
+	{start: 0x0f82, end: 0x0f83, value: 8},    // Top
+	{start: 0x0f84, end: 0x0f84, value: 1},    // Bottom
+	{start: 0x0f86, end: 0x0f87, value: 8},    // Top
+	{start: 0x0f8d, end: 0x0f97, value: 1},    // Bottom
+	{start: 0x0f99, end: 0x0fbc, value: 1},    // Bottom
+	{start: 0x0fc6, end: 0x0fc6, value: 1},    // Bottom
+	{start: 0x102b, end: 0x102c, value: 7},    // Right
+	{start: 0x102d, end: 0x102e, value: 8},    // Top
+	{start: 0x102f, end: 0x1030, value: 1},    // Bottom
+	{start: 0x1031, end: 0x1031, value: 4},    // Left
+	{start: 0x1032, end: 0x1036, value: 8},    // Top
+	{start: 0x1037, end: 0x1037, value: 1},    // Bottom
+	{start: 0x1038, end: 0x1038, value: 7},    // Right
+	{start: 0x103a, end: 0x103a, value: 8},    // Top
+	{start: 0x103b, end: 0x103b, value: 7},    // Right
+	{start: 0x103c, end: 0x103c, value: 10},   // Top_And_Bottom_And_Left
+	{start: 0x103d, end: 0x103e, value: 1},    // Bottom
+	{start: 0x1056, end: 0x1057, value: 7},    // Right
+	{start: 0x1058, end: 0x1059, value: 1},    // Bottom
+	{start: 0x105e, end: 0x1060, value: 1},    // Bottom
+	{start: 0x1062, end: 0x1064, value: 7},    // Right
+	{start: 0x1067, end: 0x106d, value: 7},    // Right
+	{start: 0x1071, end: 0x1074, value: 8},    // Top
+	{start: 0x1082, end: 0x1082, value: 1},    // Bottom
+	{start: 0x1083, end: 0x1083, value: 7},    // Right
+	{start: 0x1084, end: 0x1084, value: 4},    // Left
+	{start: 0x1085, end: 0x1086, value: 8},    // Top
+	{start: 0x1087, end: 0x108c, value: 7},    // Right
+	{start: 0x108d, end: 0x108d, value: 1},    // Bottom
+	{start: 0x108f, end: 0x108f, value: 7},    // Right
+	{start: 0x109a, end: 0x109c, value: 7},    // Right
+	{start: 0x109d, end: 0x109d, value: 8},    // Top
+	{start: 0x1712, end: 0x1712, value: 8},    // Top
+	{start: 0x1713, end: 0x1714, value: 1},    // Bottom
+	{start: 0x1732, end: 0x1732, value: 8},    // Top
+	{start: 0x1733, end: 0x1734, value: 1},    // Bottom
+	{start: 0x1752, end: 0x1752, value: 8},    // Top
+	{start: 0x1753, end: 0x1753, value: 1},    // Bottom
+	{start: 0x1772, end: 0x1772, value: 8},    // Top
+	{start: 0x1773, end: 0x1773, value: 1},    // Bottom
+	{start: 0x17b6, end: 0x17b6, value: 7},    // Right
+	{start: 0x17b7, end: 0x17ba, value: 8},    // Top
+	{start: 0x17bb, end: 0x17bd, value: 1},    // Bottom
+	{start: 0x17be, end: 0x17be, value: 12},   // Top_And_Left
+	{start: 0x17bf, end: 0x17bf, value: 13},   // Top_And_Left_And_Right
+	{start: 0x17c0, end: 0x17c0, value: 5},    // Left_And_Right
+	{start: 0x17c1, end: 0x17c3, value: 4},    // Left
+	{start: 0x17c4, end: 0x17c5, value: 5},    // Left_And_Right
+	{start: 0x17c6, end: 0x17c6, value: 8},    // Top
+	{start: 0x17c7, end: 0x17c8, value: 7},    // Right
+	{start: 0x17c9, end: 0x17d1, value: 8},    // Top
+	{start: 0x17d3, end: 0x17d3, value: 8},    // Top
+	{start: 0x17dd, end: 0x17dd, value: 8},    // Top
+	{start: 0x1920, end: 0x1921, value: 8},    // Top
+	{start: 0x1922, end: 0x1922, value: 1},    // Bottom
+	{start: 0x1923, end: 0x1924, value: 7},    // Right
+	{start: 0x1925, end: 0x1926, value: 14},   // Top_And_Right
+	{start: 0x1927, end: 0x1928, value: 8},    // Top
+	{start: 0x1929, end: 0x192b, value: 7},    // Right
+	{start: 0x1930, end: 0x1931, value: 7},    // Right
+	{start: 0x1932, end: 0x1932, value: 1},    // Bottom
+	{start: 0x1933, end: 0x1938, value: 7},    // Right
+	{start: 0x1939, end: 0x1939, value: 1},    // Bottom
+	{start: 0x193a, end: 0x193a, value: 8},    // Top
+	{start: 0x193b, end: 0x193b, value: 1},    // Bottom
+	{start: 0x19b0, end: 0x19b4, value: 7},    // Right
+	{start: 0x19b5, end: 0x19b7, value: 15},   // Visual_Order_Left
+	{start: 0x19b8, end: 0x19b9, value: 7},    // Right
+	{start: 0x19ba, end: 0x19ba, value: 15},   // Visual_Order_Left
+	{start: 0x19bb, end: 0x19c0, value: 7},    // Right
+	{start: 0x19c8, end: 0x19c9, value: 7},    // Right
+	{start: 0x1a17, end: 0x1a17, value: 8},    // Top
+	{start: 0x1a18, end: 0x1a18, value: 1},    // Bottom
+	{start: 0x1a19, end: 0x1a19, value: 4},    // Left
+	{start: 0x1a1a, end: 0x1a1a, value: 7},    // Right
+	{start: 0x1a1b, end: 0x1a1b, value: 8},    // Top
+	{start: 0x1a55, end: 0x1a55, value: 4},    // Left
+	{start: 0x1a56, end: 0x1a56, value: 1},    // Bottom
+	{start: 0x1a57, end: 0x1a57, value: 7},    // Right
+	{start: 0x1a58, end: 0x1a5a, value: 8},    // Top
+	{start: 0x1a5b, end: 0x1a5e, value: 1},    // Bottom
+	{start: 0x1a61, end: 0x1a61, value: 7},    // Right
+	{start: 0x1a62, end: 0x1a62, value: 8},    // Top
+	{start: 0x1a63, end: 0x1a64, value: 7},    // Right
+	{start: 0x1a65, end: 0x1a68, value: 8},    // Top
+	{start: 0x1a69, end: 0x1a6a, value: 1},    // Bottom
+	{start: 0x1a6b, end: 0x1a6b, value: 8},    // Top
+	{start: 0x1a6c, end: 0x1a6c, value: 1},    // Bottom
+	{start: 0x1a6d, end: 0x1a6d, value: 7},    // Right
+	{start: 0x1a6e, end: 0x1a72, value: 4},    // Left
+	{start: 0x1a73, end: 0x1a7c, value: 8},    // Top
+	{start: 0x1a7f, end: 0x1a7f, value: 1},    // Bottom
+	{start: 0x1b00, end: 0x1b03, value: 8},    // Top
+	{start: 0x1b04, end: 0x1b04, value: 7},    // Right
+	{start: 0x1b34, end: 0x1b34, value: 8},    // Top
+	{start: 0x1b35, end: 0x1b35, value: 7},    // Right
+	{start: 0x1b36, end: 0x1b37, value: 8},    // Top
+	{start: 0x1b38, end: 0x1b3a, value: 1},    // Bottom
+	{start: 0x1b3b, end: 0x1b3b, value: 3},    // Bottom_And_Right
+	{start: 0x1b3c, end: 0x1b3c, value: 9},    // Top_And_Bottom
+	{start: 0x1b3d, end: 0x1b3d, value: 11},   // Top_And_Bottom_And_Right
+	{start: 0x1b3e, end: 0x1b3f, value: 4},    // Left
+	{start: 0x1b40, end: 0x1b41, value: 5},    // Left_And_Right
+	{start: 0x1b42, end: 0x1b42, value: 8},    // Top
+	{start: 0x1b43, end: 0x1b43, value: 14},   // Top_And_Right
+	{start: 0x1b44, end: 0x1b44, value: 7},    // Right
+	{start: 0x1b6b, end: 0x1b6b, value: 8},    // Top
+	{start: 0x1b6c, end: 0x1b6c, value: 1},    // Bottom
+	{start: 0x1b6d, end: 0x1b73, value: 8},    // Top
+	{start: 0x1b80, end: 0x1b81, value: 8},    // Top
+	{start: 0x1b82, end: 0x1b82, value: 7},    // Right
+	{start: 0x1ba1, end: 0x1ba1, value: 7},    // Right
+	{start: 0x1ba2, end: 0x1ba3, value: 1},    // Bottom
+	{start: 0x1ba4, end: 0x1ba4, value: 8},    // Top
+	{start: 0x1ba5, end: 0x1ba5, value: 1},    // Bottom
+	{start: 0x1ba6, end: 0x1ba6, value: 4},    // Left
+	{start: 0x1ba7, end: 0x1ba7, value: 7},    // Right
+	{start: 0x1ba8, end: 0x1ba9, value: 8},    // Top
+	{start: 0x1baa, end: 0x1baa, value: 7},    // Right
+	{start: 0x1bac, end: 0x1bad, value: 1},    // Bottom
+	{start: 0x1be6, end: 0x1be6, value: 8},    // Top
+	{start: 0x1be7, end: 0x1be7, value: 7},    // Right
+	{start: 0x1be8, end: 0x1be9, value: 8},    // Top
+	{start: 0x1bea, end: 0x1bec, value: 7},    // Right
+	{start: 0x1bed, end: 0x1bed, value: 8},    // Top
+	{start: 0x1bee, end: 0x1bee, value: 7},    // Right
+	{start: 0x1bef, end: 0x1bf1, value: 8},    // Top
+	{start: 0x1bf2, end: 0x1bf3, value: 7},    // Right
+	{start: 0x1c24, end: 0x1c26, value: 7},    // Right
+	{start: 0x1c27, end: 0x1c28, value: 4},    // Left
+	{start: 0x1c29, end: 0x1c29, value: 12},   // Top_And_Left
+	{start: 0x1c2a, end: 0x1c2b, value: 7},    // Right
+	{start: 0x1c2c, end: 0x1c2c, value: 1},    // Bottom
+	{start: 0x1c2d, end: 0x1c33, value: 8},    // Top
+	{start: 0x1c34, end: 0x1c35, value: 4},    // Left
+	{start: 0x1c36, end: 0x1c36, value: 8},    // Top
+	{start: 0x1c37, end: 0x1c37, value: 1},    // Bottom
+	{start: 0x1cd0, end: 0x1cd2, value: 8},    // Top
+	{start: 0x1cd4, end: 0x1cd4, value: 6},    // Overstruck
+	{start: 0x1cd5, end: 0x1cd9, value: 1},    // Bottom
+	{start: 0x1cda, end: 0x1cdb, value: 8},    // Top
+	{start: 0x1cdc, end: 0x1cdf, value: 1},    // Bottom
+	{start: 0x1ce0, end: 0x1ce0, value: 8},    // Top
+	{start: 0x1ce1, end: 0x1ce1, value: 7},    // Right
+	{start: 0x1ce2, end: 0x1ce8, value: 6},    // Overstruck
+	{start: 0x1ced, end: 0x1ced, value: 1},    // Bottom
+	{start: 0x1cf4, end: 0x1cf4, value: 8},    // Top
+	{start: 0x1cf7, end: 0x1cf7, value: 7},    // Right
+	{start: 0x1dfb, end: 0x1dfb, value: 8},    // Top
+	{start: 0x20f0, end: 0x20f0, value: 8},    // Top
+	{start: 0xa802, end: 0xa802, value: 8},    // Top
+	{start: 0xa806, end: 0xa806, value: 8},    // Top
+	{start: 0xa80b, end: 0xa80b, value: 8},    // Top
+	{start: 0xa823, end: 0xa824, value: 7},    // Right
+	{start: 0xa825, end: 0xa825, value: 1},    // Bottom
+	{start: 0xa826, end: 0xa826, value: 8},    // Top
+	{start: 0xa827, end: 0xa827, value: 7},    // Right
+	{start: 0xa82c, end: 0xa82c, value: 1},    // Bottom
+	{start: 0xa880, end: 0xa881, value: 7},    // Right
+	{start: 0xa8b4, end: 0xa8c3, value: 7},    // Right
+	{start: 0xa8c4, end: 0xa8c4, value: 1},    // Bottom
+	{start: 0xa8c5, end: 0xa8c5, value: 8},    // Top
+	{start: 0xa8e0, end: 0xa8f1, value: 8},    // Top
+	{start: 0xa8ff, end: 0xa8ff, value: 8},    // Top
+	{start: 0xa92b, end: 0xa92d, value: 1},    // Bottom
+	{start: 0xa947, end: 0xa949, value: 1},    // Bottom
+	{start: 0xa94a, end: 0xa94a, value: 8},    // Top
+	{start: 0xa94b, end: 0xa94e, value: 1},    // Bottom
+	{start: 0xa94f, end: 0xa951, value: 8},    // Top
+	{start: 0xa952, end: 0xa953, value: 7},    // Right
+	{start: 0xa980, end: 0xa982, value: 8},    // Top
+	{start: 0xa983, end: 0xa983, value: 7},    // Right
+	{start: 0xa9b3, end: 0xa9b3, value: 8},    // Top
+	{start: 0xa9b4, end: 0xa9b5, value: 7},    // Right
+	{start: 0xa9b6, end: 0xa9b7, value: 8},    // Top
+	{start: 0xa9b8, end: 0xa9b9, value: 1},    // Bottom
+	{start: 0xa9ba, end: 0xa9bb, value: 4},    // Left
+	{start: 0xa9bc, end: 0xa9bc, value: 8},    // Top
+	{start: 0xa9bd, end: 0xa9bd, value: 1},    // Bottom
+	{start: 0xa9be, end: 0xa9be, value: 3},    // Bottom_And_Right
+	{start: 0xa9bf, end: 0xa9bf, value: 2},    // Bottom_And_Left
+	{start: 0xa9c0, end: 0xa9c0, value: 3},    // Bottom_And_Right
+	{start: 0xa9e5, end: 0xa9e5, value: 8},    // Top
+	{start: 0xaa29, end: 0xaa2c, value: 8},    // Top
+	{start: 0xaa2d, end: 0xaa2d, value: 1},    // Bottom
+	{start: 0xaa2e, end: 0xaa2e, value: 8},    // Top
+	{start: 0xaa2f, end: 0xaa30, value: 4},    // Left
+	{start: 0xaa31, end: 0xaa31, value: 8},    // Top
+	{start: 0xaa32, end: 0xaa32, value: 1},    // Bottom
+	{start: 0xaa33, end: 0xaa33, value: 7},    // Right
+	{start: 0xaa34, end: 0xaa34, value: 4},    // Left
+	{start: 0xaa35, end: 0xaa36, value: 1},    // Bottom
+	{start: 0xaa43, end: 0xaa43, value: 8},    // Top
+	{start: 0xaa4c, end: 0xaa4c, value: 8},    // Top
+	{start: 0xaa4d, end: 0xaa4d, value: 7},    // Right
+	{start: 0xaa7b, end: 0xaa7b, value: 7},    // Right
+	{start: 0xaa7c, end: 0xaa7c, value: 8},    // Top
+	{start: 0xaa7d, end: 0xaa7d, value: 7},    // Right
+	{start: 0xaab0, end: 0xaab0, value: 8},    // Top
+	{start: 0xaab1, end: 0xaab1, value: 7},    // Right
+	{start: 0xaab2, end: 0xaab3, value: 8},    // Top
+	{start: 0xaab4, end: 0xaab4, value: 1},    // Bottom
+	{start: 0xaab5, end: 0xaab6, value: 15},   // Visual_Order_Left
+	{start: 0xaab7, end: 0xaab8, value: 8},    // Top
+	{start: 0xaab9, end: 0xaab9, value: 15},   // Visual_Order_Left
+	{start: 0xaaba, end: 0xaaba, value: 7},    // Right
+	{start: 0xaabb, end: 0xaabc, value: 15},   // Visual_Order_Left
+	{start: 0xaabd, end: 0xaabd, value: 7},    // Right
+	{start: 0xaabe, end: 0xaabf, value: 8},    // Top
+	{start: 0xaac1, end: 0xaac1, value: 8},    // Top
+	{start: 0xaaeb, end: 0xaaeb, value: 4},    // Left
+	{start: 0xaaec, end: 0xaaec, value: 1},    // Bottom
+	{start: 0xaaed, end: 0xaaed, value: 8},    // Top
+	{start: 0xaaee, end: 0xaaee, value: 4},    // Left
+	{start: 0xaaef, end: 0xaaef, value: 7},    // Right
+	{start: 0xaaf5, end: 0xaaf5, value: 7},    // Right
+	{start: 0xabe3, end: 0xabe4, value: 7},    // Right
+	{start: 0xabe5, end: 0xabe5, value: 8},    // Top
+	{start: 0xabe6, end: 0xabe7, value: 7},    // Right
+	{start: 0xabe8, end: 0xabe8, value: 1},    // Bottom
+	{start: 0xabe9, end: 0xabea, value: 7},    // Right
+	{start: 0xabec, end: 0xabec, value: 7},    // Right
+	{start: 0xabed, end: 0xabed, value: 1},    // Bottom
+	{start: 0x10a01, end: 0x10a01, value: 6},  // Overstruck
+	{start: 0x10a02, end: 0x10a03, value: 1},  // Bottom
+	{start: 0x10a05, end: 0x10a05, value: 8},  // Top
+	{start: 0x10a06, end: 0x10a06, value: 6},  // Overstruck
+	{start: 0x10a0c, end: 0x10a0e, value: 1},  // Bottom
+	{start: 0x10a0f, end: 0x10a0f, value: 8},  // Top
+	{start: 0x10a38, end: 0x10a38, value: 8},  // Top
+	{start: 0x10a39, end: 0x10a3a, value: 1},  // Bottom
+	{start: 0x11000, end: 0x11000, value: 7},  // Right
+	{start: 0x11001, end: 0x11001, value: 8},  // Top
+	{start: 0x11002, end: 0x11002, value: 7},  // Right
+	{start: 0x11038, end: 0x1103b, value: 8},  // Top
+	{start: 0x1103c, end: 0x11041, value: 1},  // Bottom
+	{start: 0x11042, end: 0x11046, value: 8},  // Top
+	{start: 0x11080, end: 0x11081, value: 8},  // Top
+	{start: 0x11082, end: 0x11082, value: 7},  // Right
+	{start: 0x110b0, end: 0x110b0, value: 7},  // Right
+	{start: 0x110b1, end: 0x110b1, value: 4},  // Left
+	{start: 0x110b2, end: 0x110b2, value: 7},  // Right
+	{start: 0x110b3, end: 0x110b4, value: 1},  // Bottom
+	{start: 0x110b5, end: 0x110b6, value: 8},  // Top
+	{start: 0x110b7, end: 0x110b8, value: 7},  // Right
+	{start: 0x110b9, end: 0x110ba, value: 1},  // Bottom
+	{start: 0x11100, end: 0x11102, value: 8},  // Top
+	{start: 0x11127, end: 0x11129, value: 8},  // Top
+	{start: 0x1112a, end: 0x1112b, value: 1},  // Bottom
+	{start: 0x1112c, end: 0x1112c, value: 4},  // Left
+	{start: 0x1112d, end: 0x1112d, value: 8},  // Top
+	{start: 0x1112e, end: 0x1112f, value: 9},  // Top_And_Bottom
+	{start: 0x11130, end: 0x11130, value: 8},  // Top
+	{start: 0x11131, end: 0x11132, value: 1},  // Bottom
+	{start: 0x11134, end: 0x11134, value: 8},  // Top
+	{start: 0x11145, end: 0x11146, value: 7},  // Right
+	{start: 0x11173, end: 0x11173, value: 1},  // Bottom
+	{start: 0x11180, end: 0x11181, value: 8},  // Top
+	{start: 0x11182, end: 0x11182, value: 7},  // Right
+	{start: 0x111b3, end: 0x111b3, value: 7},  // Right
+	{start: 0x111b4, end: 0x111b4, value: 4},  // Left
+	{start: 0x111b5, end: 0x111b5, value: 7},  // Right
+	{start: 0x111b6, end: 0x111bb, value: 1},  // Bottom
+	{start: 0x111bc, end: 0x111be, value: 8},  // Top
+	{start: 0x111bf, end: 0x111bf, value: 14}, // Top_And_Right
+	{start: 0x111c0, end: 0x111c0, value: 7},  // Right
+	{start: 0x111c2, end: 0x111c3, value: 8},  // Top
+	{start: 0x111c9, end: 0x111ca, value: 1},  // Bottom
+	{start: 0x111cb, end: 0x111cb, value: 8},  // Top
+	{start: 0x111cc, end: 0x111cc, value: 1},  // Bottom
+	{start: 0x111ce, end: 0x111ce, value: 4},  // Left
+	{start: 0x111cf, end: 0x111cf, value: 8},  // Top
+	{start: 0x1122c, end: 0x1122e, value: 7},  // Right
+	{start: 0x1122f, end: 0x1122f, value: 1},  // Bottom
+	{start: 0x11230, end: 0x11231, value: 8},  // Top
+	{start: 0x11232, end: 0x11233, value: 14}, // Top_And_Right
+	{start: 0x11234, end: 0x11234, value: 8},  // Top
+	{start: 0x11235, end: 0x11235, value: 7},  // Right
+	{start: 0x11236, end: 0x11237, value: 8},  // Top
+	{start: 0x1123e, end: 0x1123e, value: 8},  // Top
+	{start: 0x112df, end: 0x112df, value: 8},  // Top
+	{start: 0x112e0, end: 0x112e0, value: 7},  // Right
+	{start: 0x112e1, end: 0x112e1, value: 4},  // Left
+	{start: 0x112e2, end: 0x112e2, value: 7},  // Right
+	{start: 0x112e3, end: 0x112e4, value: 1},  // Bottom
+	{start: 0x112e5, end: 0x112e8, value: 8},  // Top
+	{start: 0x112e9, end: 0x112ea, value: 1},  // Bottom
+	{start: 0x11300, end: 0x11301, value: 8},  // Top
+	{start: 0x11302, end: 0x11303, value: 7},  // Right
+	{start: 0x1133b, end: 0x1133c, value: 1},  // Bottom
+	{start: 0x1133e, end: 0x1133f, value: 7},  // Right
+	{start: 0x11340, end: 0x11340, value: 8},  // Top
+	{start: 0x11341, end: 0x11344, value: 7},  // Right
+	{start: 0x11347, end: 0x11348, value: 4},  // Left
+	{start: 0x1134b, end: 0x1134c, value: 5},  // Left_And_Right
+	{start: 0x1134d, end: 0x1134d, value: 7},  // Right
+	{start: 0x11357, end: 0x11357, value: 7},  // Right
+	{start: 0x11362, end: 0x11363, value: 7},  // Right
+	{start: 0x11366, end: 0x1136c, value: 8},  // Top
+	{start: 0x11370, end: 0x11374, value: 8},  // Top
+	{start: 0x11435, end: 0x11435, value: 7},  // Right
+	{start: 0x11436, end: 0x11436, value: 4},  // Left
+	{start: 0x11437, end: 0x11437, value: 7},  // Right
+	{start: 0x11438, end: 0x1143d, value: 1},  // Bottom
+	{start: 0x1143e, end: 0x1143f, value: 8},  // Top
+	{start: 0x11440, end: 0x11441, value: 7},  // Right
+	{start: 0x11442, end: 0x11442, value: 1},  // Bottom
+	{start: 0x11443, end: 0x11444, value: 8},  // Top
+	{start: 0x11445, end: 0x11445, value: 7},  // Right
+	{start: 0x11446, end: 0x11446, value: 1},  // Bottom
+	{start: 0x1145e, end: 0x1145e, value: 8},  // Top
+	{start: 0x114b0, end: 0x114b0, value: 7},  // Right
+	{start: 0x114b1, end: 0x114b1, value: 4},  // Left
+	{start: 0x114b2, end: 0x114b2, value: 7},  // Right
+	{start: 0x114b3, end: 0x114b8, value: 1},  // Bottom
+	{start: 0x114b9, end: 0x114b9, value: 4},  // Left
+	{start: 0x114ba, end: 0x114ba, value: 8},  // Top
+	{start: 0x114bb, end: 0x114bb, value: 12}, // Top_And_Left
+	{start: 0x114bc, end: 0x114bc, value: 5},  // Left_And_Right
+	{start: 0x114bd, end: 0x114bd, value: 7},  // Right
+	{start: 0x114be, end: 0x114be, value: 5},  // Left_And_Right
+	{start: 0x114bf, end: 0x114c0, value: 8},  // Top
+	{start: 0x114c1, end: 0x114c1, value: 7},  // Right
+	{start: 0x114c2, end: 0x114c3, value: 1},  // Bottom
+	{start: 0x115af, end: 0x115af, value: 7},  // Right
+	{start: 0x115b0, end: 0x115b0, value: 4},  // Left
+	{start: 0x115b1, end: 0x115b1, value: 7},  // Right
+	{start: 0x115b2, end: 0x115b5, value: 1},  // Bottom
+	{start: 0x115b8, end: 0x115b8, value: 4},  // Left
+	{start: 0x115b9, end: 0x115b9, value: 12}, // Top_And_Left
+	{start: 0x115ba, end: 0x115ba, value: 5},  // Left_And_Right
+	{start: 0x115bb, end: 0x115bb, value: 13}, // Top_And_Left_And_Right
+	{start: 0x115bc, end: 0x115bd, value: 8},  // Top
+	{start: 0x115be, end: 0x115be, value: 7},  // Right
+	{start: 0x115bf, end: 0x115c0, value: 1},  // Bottom
+	{start: 0x115dc, end: 0x115dd, value: 1},  // Bottom
+	{start: 0x11630, end: 0x11632, value: 7},  // Right
+	{start: 0x11633, end: 0x11638, value: 1},  // Bottom
+	{start: 0x11639, end: 0x1163a, value: 8},  // Top
+	{start: 0x1163b, end: 0x1163c, value: 7},  // Right
+	{start: 0x1163d, end: 0x1163d, value: 8},  // Top
+	{start: 0x1163e, end: 0x1163e, value: 7},  // Right
+	{start: 0x1163f, end: 0x1163f, value: 1},  // Bottom
+	{start: 0x11640, end: 0x11640, value: 8},  // Top
+	{start: 0x116ab, end: 0x116ab, value: 8},  // Top
+	{start: 0x116ac, end: 0x116ac, value: 7},  // Right
+	{start: 0x116ad, end: 0x116ad, value: 8},  // Top
+	{start: 0x116ae, end: 0x116ae, value: 4},  // Left
+	{start: 0x116af, end: 0x116af, value: 7},  // Right
+	{start: 0x116b0, end: 0x116b1, value: 1},  // Bottom
+	{start: 0x116b2, end: 0x116b5, value: 8},  // Top
+	{start: 0x116b6, end: 0x116b6, value: 7},  // Right
+	{start: 0x116b7, end: 0x116b7, value: 1},  // Bottom
+	{start: 0x1171d, end: 0x1171d, value: 1},  // Bottom
+	{start: 0x1171e, end: 0x1171e, value: 10}, // Top_And_Bottom_And_Left
+	{start: 0x1171f, end: 0x1171f, value: 8},  // Top
+	{start: 0x11720, end: 0x11721, value: 7},  // Right
+	{start: 0x11722, end: 0x11723, value: 8},  // Top
+	{start: 0x11724, end: 0x11725, value: 1},  // Bottom
+	{start: 0x11726, end: 0x11726, value: 4},  // Left
+	{start: 0x11727, end: 0x11727, value: 8},  // Top
+	{start: 0x11728, end: 0x11728, value: 1},  // Bottom
+	{start: 0x11729, end: 0x1172b, value: 8},  // Top
+	{start: 0x1182c, end: 0x1182c, value: 7},  // Right
+	{start: 0x1182d, end: 0x1182d, value: 4},  // Left
+	{start: 0x1182e, end: 0x1182e, value: 7},  // Right
+	{start: 0x1182f, end: 0x11832, value: 1},  // Bottom
+	{start: 0x11833, end: 0x11837, value: 8},  // Top
+	{start: 0x11838, end: 0x11838, value: 7},  // Right
+	{start: 0x11839, end: 0x1183a, value: 1},  // Bottom
+	{start: 0x11930, end: 0x11934, value: 7},  // Right
+	{start: 0x11935, end: 0x11935, value: 4},  // Left
+	{start: 0x11937, end: 0x11937, value: 4},  // Left
+	{start: 0x11938, end: 0x11938, value: 5},  // Left_And_Right
+	{start: 0x1193b, end: 0x1193c, value: 8},  // Top
+	{start: 0x1193d, end: 0x1193d, value: 7},  // Right
+	{start: 0x1193f, end: 0x1193f, value: 8},  // Top
+	{start: 0x11940, end: 0x11940, value: 7},  // Right
+	{start: 0x11941, end: 0x11941, value: 8},  // Top
+	{start: 0x11942, end: 0x11942, value: 3},  // Bottom_And_Right
+	{start: 0x11943, end: 0x11943, value: 1},  // Bottom
+	{start: 0x119d1, end: 0x119d1, value: 7},  // Right
+	{start: 0x119d2, end: 0x119d2, value: 4},  // Left
+	{start: 0x119d3, end: 0x119d3, value: 7},  // Right
+	{start: 0x119d4, end: 0x119d7, value: 1},  // Bottom
+	{start: 0x119da, end: 0x119db, value: 8},  // Top
+	{start: 0x119dc, end: 0x119df, value: 7},  // Right
+	{start: 0x119e0, end: 0x119e0, value: 1},  // Bottom
+	{start: 0x119e4, end: 0x119e4, value: 4},  // Left
+	{start: 0x11a01, end: 0x11a01, value: 8},  // Top
+	{start: 0x11a02, end: 0x11a03, value: 1},  // Bottom
+	{start: 0x11a04, end: 0x11a09, value: 8},  // Top
+	{start: 0x11a0a, end: 0x11a0a, value: 1},  // Bottom
+	{start: 0x11a33, end: 0x11a34, value: 1},  // Bottom
+	{start: 0x11a35, end: 0x11a38, value: 8},  // Top
+	{start: 0x11a39, end: 0x11a39, value: 7},  // Right
+	{start: 0x11a3a, end: 0x11a3a, value: 8},  // Top
+	{start: 0x11a3b, end: 0x11a3e, value: 1},  // Bottom
+	{start: 0x11a51, end: 0x11a51, value: 8},  // Top
+	{start: 0x11a52, end: 0x11a53, value: 1},  // Bottom
+	{start: 0x11a54, end: 0x11a56, value: 8},  // Top
+	{start: 0x11a57, end: 0x11a58, value: 7},  // Right
+	{start: 0x11a59, end: 0x11a5b, value: 1},  // Bottom
+	{start: 0x11a84, end: 0x11a89, value: 8},  // Top
+	{start: 0x11a8a, end: 0x11a95, value: 1},  // Bottom
+	{start: 0x11a96, end: 0x11a96, value: 8},  // Top
+	{start: 0x11a97, end: 0x11a97, value: 7},  // Right
+	{start: 0x11a98, end: 0x11a98, value: 8},  // Top
+	{start: 0x11c2f, end: 0x11c2f, value: 7},  // Right
+	{start: 0x11c30, end: 0x11c31, value: 8},  // Top
+	{start: 0x11c32, end: 0x11c36, value: 1},  // Bottom
+	{start: 0x11c38, end: 0x11c3d, value: 8},  // Top
+	{start: 0x11c3e, end: 0x11c3e, value: 7},  // Right
+	{start: 0x11c3f, end: 0x11c3f, value: 1},  // Bottom
+	{start: 0x11c92, end: 0x11ca7, value: 1},  // Bottom
+	{start: 0x11ca9, end: 0x11ca9, value: 7},  // Right
+	{start: 0x11caa, end: 0x11cb0, value: 1},  // Bottom
+	{start: 0x11cb1, end: 0x11cb1, value: 4},  // Left
+	{start: 0x11cb2, end: 0x11cb2, value: 1},  // Bottom
+	{start: 0x11cb3, end: 0x11cb3, value: 8},  // Top
+	{start: 0x11cb4, end: 0x11cb4, value: 7},  // Right
+	{start: 0x11cb5, end: 0x11cb6, value: 8},  // Top
+	{start: 0x11d31, end: 0x11d35, value: 8},  // Top
+	{start: 0x11d36, end: 0x11d36, value: 1},  // Bottom
+	{start: 0x11d3a, end: 0x11d3a, value: 8},  // Top
+	{start: 0x11d3c, end: 0x11d3d, value: 8},  // Top
+	{start: 0x11d3f, end: 0x11d41, value: 8},  // Top
+	{start: 0x11d42, end: 0x11d42, value: 1},  // Bottom
+	{start: 0x11d43, end: 0x11d43, value: 8},  // Top
+	{start: 0x11d44, end: 0x11d44, value: 1},  // Bottom
+	{start: 0x11d46, end: 0x11d46, value: 7},  // Right
+	{start: 0x11d47, end: 0x11d47, value: 1},  // Bottom
+	{start: 0x11d8a, end: 0x11d8e, value: 7},  // Right
+	{start: 0x11d90, end: 0x11d91, value: 8},  // Top
+	{start: 0x11d93, end: 0x11d94, value: 7},  // Right
+	{start: 0x11d95, end: 0x11d95, value: 8},  // Top
+	{start: 0x11d96, end: 0x11d96, value: 7},  // Right
+	{start: 0x11ef3, end: 0x11ef3, value: 8},  // Top
+	{start: 0x11ef4, end: 0x11ef4, value: 1},  // Bottom
+	{start: 0x11ef5, end: 0x11ef5, value: 4},  // Left
+	{start: 0x11ef6, end: 0x11ef6, value: 7},  // Right
+}
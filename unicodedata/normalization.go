@@ -0,0 +1,110 @@
+package unicodedata
+
+import "sort"
+
+// reference : Unicode Standard Annex #15, Unicode Normalization Forms
+
+// NFD returns the Canonical Decomposition of `text`.
+func NFD(text []rune) []rune { return decompose(text, false) }
+
+// NFKD returns the Compatibility Decomposition of `text`.
+func NFKD(text []rune) []rune { return decompose(text, true) }
+
+// NFC returns the Canonical Decomposition of `text`,
+// followed by Canonical Composition.
+func NFC(text []rune) []rune { return recompose(decompose(text, false)) }
+
+// NFKC returns the Compatibility Decomposition of `text`,
+// followed by Canonical Composition.
+func NFKC(text []rune) []rune { return recompose(decompose(text, true)) }
+
+// DecomposeFull appends to `dst` the full decomposition of `r`, that is
+// the result of recursively applying the canonical mappings (including the
+// algorithmic decomposition of Hangul syllables), and the compatibility mappings if
+// `compat` is true. The returned runes are not in canonical order (see NFD).
+func DecomposeFull(dst []rune, r rune, compat bool) []rune {
+	if a, b, ok := Decompose(r); ok {
+		dst = DecomposeFull(dst, a, compat)
+		if b != 0 {
+			dst = DecomposeFull(dst, b, compat)
+		}
+		return dst
+	}
+	if mapping, ok := decomposeCompat[r]; ok && compat {
+		for _, m := range mapping {
+			dst = DecomposeFull(dst, m, compat)
+		}
+		return dst
+	}
+	return append(dst, r)
+}
+
+// decompose applies the full decomposition and
+// the Canonical Ordering Algorithm
+func decompose(text []rune, compat bool) []rune {
+	out := make([]rune, 0, len(text))
+	for _, r := range text {
+		out = DecomposeFull(out, r, compat)
+	}
+
+	// sort the sequences of non starters by combining class
+	classes := make([]uint8, len(out))
+	for i, r := range out {
+		classes[i] = LookupCombiningClass(r)
+	}
+	for start := 0; start < len(out); {
+		if classes[start] == 0 {
+			start++
+			continue
+		}
+		end := start + 1
+		for end < len(out) && classes[end] != 0 {
+			end++
+		}
+		if end-start > 1 {
+			sort.Stable(byClass{out[start:end], classes[start:end]})
+		}
+		start = end
+	}
+	return out
+}
+
+type byClass struct {
+	runes   []rune
+	classes []uint8
+}
+
+func (b byClass) Len() int           { return len(b.runes) }
+func (b byClass) Less(i, j int) bool { return b.classes[i] < b.classes[j] }
+func (b byClass) Swap(i, j int) {
+	b.runes[i], b.runes[j] = b.runes[j], b.runes[i]
+	b.classes[i], b.classes[j] = b.classes[j], b.classes[i]
+}
+
+// recompose applies the Canonical Composition Algorithm
+// to `text`, which must be in canonical order.
+// `text` is modified in place and returned.
+func recompose(text []rune) []rune {
+	var (
+		out       = text[:0]
+		starter   = -1  // index in out of the last starter
+		lastClass uint8 // of the last rune in out
+	)
+	for _, r := range text {
+		class := LookupCombiningClass(r)
+		// r is blocked from the starter if a rune between them has
+		// a class of zero or higher or equal than the one of r
+		if starter != -1 && (starter == len(out)-1 || lastClass != 0 && lastClass < class) {
+			if composed, ok := Compose(out[starter], r); ok {
+				out[starter] = composed
+				continue
+			}
+		}
+		if class == 0 {
+			starter = len(out)
+		}
+		lastClass = class
+		out = append(out, r)
+	}
+	return out
+}
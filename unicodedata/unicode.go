@@ -1,8 +1,15 @@
 // Package unicodedata provides additional lookup functions for unicode
 // properties, not covered by the standard package unicode.
+//
+// Most of the tables are generated from the Unicode Character Database,
+// whose version is given by Version, by running `go generate`
+// (see the generate directory).
 package unicodedata
 
+//go:generate go run -C generate .
+
 import (
+	"fmt"
 	"unicode"
 )
 
@@ -72,6 +79,48 @@ func LookupMirrorChar(ch rune) (rune, bool) {
 	return m, ok
 }
 
+// BracketType is the Bidi_Paired_Bracket_Type property,
+// used by the rule N0 of the Unicode Bidirectional Algorithm.
+type BracketType uint8
+
+const (
+	BracketNone  BracketType = iota // not a paired bracket
+	BracketOpen                     // opening bracket, like (
+	BracketClose                    // closing bracket, like )
+)
+
+type bidiBracket struct {
+	pair rune
+	kind BracketType
+}
+
+// LookupBidiBracket returns the paired bracket of `ch` and its type, as
+// defined in the file BidiBrackets.txt of the Unicode Character Database.
+// For characters which are not paired brackets, it returns `ch` and `BracketNone`.
+func LookupBidiBracket(ch rune) (rune, BracketType) {
+	if b, ok := bidiBrackets[ch]; ok {
+		return b.pair, b.kind
+	}
+	return ch, BracketNone
+}
+
+// BidiBracketsMatch returns true if `opening` and `closing` form a bracket
+// pair. As required by the rule BD16 of the Unicode Bidirectional Algorithm,
+// canonical equivalents are identified, so that U+2329 is matched by
+// U+232A as well as by U+3009.
+func BidiBracketsMatch(opening, closing rune) bool {
+	pair, kind := LookupBidiBracket(canonicalBracket(opening))
+	return kind == BracketOpen && pair == canonicalBracket(closing)
+}
+
+// canonicalBracket returns the singleton decomposition of `ch`, if any
+func canonicalBracket(ch rune) rune {
+	if m, ok := decompose1[ch]; ok {
+		return m
+	}
+	return ch
+}
+
 // Algorithmic hangul syllable [de]composition
 const (
 	HangulSBase  = 0xAC00
@@ -204,3 +253,181 @@ func Jamo(btype *unicode.RangeTable) JamoType {
 		return NO_JAMO
 	}
 }
+
+// IndicSyllabicCategory is the Indic_Syllabic_Category property, which
+// describes the role of a character in the syllables of the Brahmi-derived
+// scripts (see the constants IndicSyllabicXXX and the file IndicSyllabicCategory.txt
+// of the Unicode Character Database).
+type IndicSyllabicCategory uint8
+
+func (c IndicSyllabicCategory) String() string {
+	if int(c) < len(indicSyllabicCategoryNames) {
+		return indicSyllabicCategoryNames[c]
+	}
+	return fmt.Sprintf("<indic syllabic category unknown: %d>", c)
+}
+
+// IndicPositionalCategory is the Indic_Positional_Category property, which
+// describes the placement of the dependent vowels and marks relative to
+// their base (see the constants IndicPositionalXXX and the file IndicPositionalCategory.txt
+// of the Unicode Character Database).
+type IndicPositionalCategory uint8
+
+func (c IndicPositionalCategory) String() string {
+	if int(c) < len(indicPositionalCategoryNames) {
+		return indicPositionalCategoryNames[c]
+	}
+	return fmt.Sprintf("<indic positional category unknown: %d>", c)
+}
+
+// propertyRange stores the value of an enumerated
+// property for the runes from start to end (included)
+type propertyRange struct {
+	start, end rune
+	value      uint8
+}
+
+// lookupRange returns the value of the property for `r`, defaulting to 0
+func lookupRange(ranges []propertyRange, r rune) uint8 {
+	// binary search
+	for i, j := 0, len(ranges); i < j; {
+		h := i + (j-i)/2
+		entry := ranges[h]
+		if r < entry.start {
+			j = h
+		} else if entry.end < r {
+			i = h + 1
+		} else {
+			return entry.value
+		}
+	}
+	return 0
+}
+
+// LookupIndicSyllabicCategory returns the Indic_Syllabic_Category of `r`,
+// or IndicSyllabicOther if not found.
+func LookupIndicSyllabicCategory(r rune) IndicSyllabicCategory {
+	return IndicSyllabicCategory(lookupRange(indicSyllabicCategoryRanges[:], r))
+}
+
+// LookupIndicPositionalCategory returns the Indic_Positional_Category of `r`,
+// or IndicPositionalNA if not found.
+func LookupIndicPositionalCategory(r rune) IndicPositionalCategory {
+	return IndicPositionalCategory(lookupRange(indicPositionalCategoryRanges[:], r))
+}
+
+// EastAsianWidth is the East_Asian_Width property, defined in
+// Unicode Standard Annex #11, which may be used to estimate the width
+// of the characters in monospace (terminal-like) contexts.
+type EastAsianWidth uint8
+
+const (
+	EastAsianNeutral   EastAsianWidth = iota // N
+	EastAsianAmbiguous                       // A, wide or narrow depending on the context
+	EastAsianHalfwidth                       // H
+	EastAsianWide                            // W
+	EastAsianFullwidth                       // F
+	EastAsianNarrow                          // Na
+)
+
+// LookupEastAsianWidth returns the East_Asian_Width of `r`,
+// or EastAsianNeutral if not found.
+func LookupEastAsianWidth(r rune) EastAsianWidth {
+	return EastAsianWidth(lookupRange(eastAsianWidthRanges[:], r))
+}
+
+// VerticalOrientation is the Vertical_Orientation property, defined in
+// Unicode Standard Annex #50, which describes how a character is
+// displayed in vertical text.
+type VerticalOrientation uint8
+
+const (
+	// R : displayed sideways, rotated 90 degrees clockwise
+	VerticalRotated VerticalOrientation = iota
+	// U : displayed upright, with the same glyph as in horizontal text
+	VerticalUpright
+	// Tu : displayed upright, with a specific glyph (see the 'vert' feature),
+	// falling back to the horizontal glyph
+	VerticalTransformedUpright
+	// Tr : displayed upright, with a specific glyph (see the 'vert' feature),
+	// falling back to the rotated horizontal glyph
+	VerticalTransformedRotated
+)
+
+// LookupVerticalOrientation returns the Vertical_Orientation of `r`,
+// or VerticalRotated if not found.
+func LookupVerticalOrientation(r rune) VerticalOrientation {
+	return VerticalOrientation(lookupRange(verticalOrientationRanges[:], r))
+}
+
+// IsUprightInVertical returns true if `r` should be kept upright in
+// vertical text, and false if it should be rotated.
+// For the characters with orientation VerticalTransformedRotated, it returns true,
+// assuming the font provides a vertical alternate glyph : otherwise,
+// the character should be rotated.
+func IsUprightInVertical(r rune) bool {
+	return LookupVerticalOrientation(r) != VerticalRotated
+}
+
+// IsEmoji returns true for the characters with the Emoji property,
+// that is the characters which may be displayed as emoji.
+func IsEmoji(r rune) bool { return unicode.Is(Emoji, r) }
+
+// IsEmojiPresentation returns true for the characters with the Emoji_Presentation property,
+// that is the characters displayed as emoji by default.
+func IsEmojiPresentation(r rune) bool { return unicode.Is(Emoji_Presentation, r) }
+
+// IsEmojiModifier returns true for the characters with the Emoji_Modifier property,
+// that is the skin tone modifiers.
+func IsEmojiModifier(r rune) bool { return unicode.Is(Emoji_Modifier, r) }
+
+// IsEmojiModifierBase returns true for the characters with the Emoji_Modifier_Base property,
+// that is the characters which may be followed by a skin tone modifier.
+func IsEmojiModifierBase(r rune) bool { return unicode.Is(Emoji_Modifier_Base, r) }
+
+// IsEmojiComponent returns true for the characters with the Emoji_Component property,
+// which may appear in emoji sequences, like the regional indicators or ZWJ.
+func IsEmojiComponent(r rune) bool { return unicode.Is(Emoji_Component, r) }
+
+// IsExtendedPictographic returns true for the characters with the Extended_Pictographic property,
+// used by the text segmentation algorithms to keep the emoji sequences together.
+func IsExtendedPictographic(r rune) bool { return unicode.Is(Extended_Pictographic, r) }
+
+const (
+	TextPresentationSelector  = 0xFE0E // VS15
+	EmojiPresentationSelector = 0xFE0F // VS16
+)
+
+// Presentation is the display style of a character : as text,
+// usually monochrome, or as emoji, usually colorful.
+type Presentation uint8
+
+const (
+	PresentationText Presentation = iota
+	PresentationEmoji
+)
+
+// LookupPresentation returns the presentation of `r`, followed
+// by `next` (which should be 0 at the end of the text), as defined by
+// Unicode Technical Standard #51 :
+//   - characters without the Emoji property are displayed as text
+//   - the variation selectors VS15 and VS16 request the text or emoji presentation
+//   - an emoji modifier sequence (like a hand followed by a skin tone) is displayed as emoji
+//   - otherwise, the Emoji_Presentation property gives the default presentation
+func LookupPresentation(r, next rune) Presentation {
+	if !IsEmoji(r) {
+		return PresentationText
+	}
+	switch {
+	case next == TextPresentationSelector:
+		return PresentationText
+	case next == EmojiPresentationSelector:
+		return PresentationEmoji
+	case IsEmojiModifierBase(r) && IsEmojiModifier(next):
+		return PresentationEmoji
+	case IsEmojiPresentation(r):
+		return PresentationEmoji
+	default:
+		return PresentationText
+	}
+}
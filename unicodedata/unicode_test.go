@@ -77,3 +77,163 @@ func TestUnicodeNormalization(t *testing.T) {
 	assertDecompose(0xCE31, true, 0xCE20, 0x11B8)
 	assertDecompose(0xCE20, true, 0x110E, 0x1173)
 }
+
+func TestBidiBrackets(t *testing.T) {
+	if pair, kind := LookupBidiBracket('('); pair != ')' || kind != BracketOpen {
+		t.Errorf("unexpected bracket %c %d", pair, kind)
+	}
+	if pair, kind := LookupBidiBracket(0x232A); pair != 0x2329 || kind != BracketClose {
+		t.Errorf("unexpected bracket %c %d", pair, kind)
+	}
+	if pair, kind := LookupBidiBracket('<'); pair != '<' || kind != BracketNone {
+		t.Errorf("unexpected bracket %c %d", pair, kind)
+	}
+
+	for _, test := range []struct {
+		opening, closing rune
+		match            bool
+	}{
+		{'(', ')', true},
+		{'[', ')', false},
+		{')', '(', false},
+		{0x2329, 0x232A, true}, // angle brackets
+		{0x2329, 0x3009, true}, // canonical equivalent of U+232A
+		{0x3008, 0x232A, true},
+		{0x3008, 0x3009, true},
+		{0x2329, 0x300B, false},
+	} {
+		if got := BidiBracketsMatch(test.opening, test.closing); got != test.match {
+			t.Errorf("for %U and %U, expected %v", test.opening, test.closing, test.match)
+		}
+	}
+}
+
+func TestNormalizationForms(t *testing.T) {
+	for _, test := range []struct {
+		input                string
+		nfd, nfc, nfkd, nfkc string
+	}{
+		{"abc", "abc", "abc", "abc", "abc"},
+		{"\u00c5", "A\u030a", "\u00c5", "A\u030a", "\u00c5"},                                  // Å
+		{"\u212b", "A\u030a", "\u00c5", "A\u030a", "\u00c5"},                                  // Angstrom sign, singleton
+		{"\u1e0b\u0323", "d\u0323\u0307", "\u1e0d\u0307", "d\u0323\u0307", "\u1e0d\u0307"},    // canonical ordering
+		{"q\u0307\u0323", "q\u0323\u0307", "q\u0323\u0307", "q\u0323\u0307", "q\u0323\u0307"}, // no composition
+		{"\ufb01", "\ufb01", "\ufb01", "fi", "fi"},                                            // ligature
+		{"\u1e9b\u0323", "\u017f\u0323\u0307", "\u1e9b\u0323", "s\u0323\u0307", "\u1e69"},     // UAX #15, figure 6
+		{"\u0958", "\u0915\u093c", "\u0915\u093c", "\u0915\u093c", "\u0915\u093c"},            // composition exclusion
+		{"\ud55c", "\u1112\u1161\u11ab", "\ud55c", "\u1112\u1161\u11ab", "\ud55c"},            // Hangul LVT
+		{"\u1100\u1161", "\u1100\u1161", "\uac00", "\u1100\u1161", "\uac00"},                  // Hangul LV
+		{"a\u0301\u0301", "a\u0301\u0301", "\u00e1\u0301", "a\u0301\u0301", "\u00e1\u0301"},   // blocked
+		{"e\u0302\u0300", "e\u0302\u0300", "\u1ec1", "e\u0302\u0300", "\u1ec1"},
+		{"\u2460", "\u2460", "\u2460", "1", "1"},
+		{"", "", "", "", ""},
+	} {
+		input := []rune(test.input)
+		for i, form := range []struct {
+			f        func([]rune) []rune
+			expected string
+		}{{NFD, test.nfd}, {NFC, test.nfc}, {NFKD, test.nfkd}, {NFKC, test.nfkc}} {
+			if got := string(form.f(input)); got != form.expected {
+				t.Errorf("form %d of %+q: expected %+q, got %+q", i, test.input, form.expected, got)
+			}
+		}
+		if string(input) != test.input {
+			t.Errorf("input %+q modified", test.input)
+		}
+	}
+}
+
+func TestIndicCategories(t *testing.T) {
+	for _, test := range []struct {
+		r          rune
+		syllabic   IndicSyllabicCategory
+		positional IndicPositionalCategory
+	}{
+		{'a', IndicSyllabicOther, IndicPositionalNA},
+		{'0', IndicSyllabicNumber, IndicPositionalNA},
+		{0x0915, IndicSyllabicConsonant, IndicPositionalNA},         // क
+		{0x093F, IndicSyllabicVowelDependent, IndicPositionalLeft},  // ि
+		{0x0940, IndicSyllabicVowelDependent, IndicPositionalRight}, // ी
+		{0x094D, IndicSyllabicVirama, IndicPositionalBottom},
+		{0x0BCC, IndicSyllabicVowelDependent, IndicPositionalLeftAndRight},
+		{0x0D4E, IndicSyllabicConsonantPrecedingRepha, IndicPositionalTop},
+		{0x200D, IndicSyllabicJoiner, IndicPositionalNA},
+		{0x11F3E, IndicSyllabicOther, IndicPositionalNA}, // Kawi, not encoded in this version
+	} {
+		if got := LookupIndicSyllabicCategory(test.r); got != test.syllabic {
+			t.Errorf("for %U, expected %s, got %s", test.r, test.syllabic, got)
+		}
+		if got := LookupIndicPositionalCategory(test.r); got != test.positional {
+			t.Errorf("for %U, expected %s, got %s", test.r, test.positional, got)
+		}
+	}
+
+	if s := IndicSyllabicConsonantPrecedingRepha.String(); s != "Consonant_Preceding_Repha" {
+		t.Errorf("unexpected name %s", s)
+	}
+	if s := IndicPositionalTopAndBottomAndLeft.String(); s != "Top_And_Bottom_And_Left" {
+		t.Errorf("unexpected name %s", s)
+	}
+}
+
+func TestEastAsian(t *testing.T) {
+	for _, test := range []struct {
+		r           rune
+		width       EastAsianWidth
+		orientation VerticalOrientation
+	}{
+		{'a', EastAsianNarrow, VerticalRotated},
+		{0x00A7, EastAsianAmbiguous, VerticalUpright},            // §
+		{0x0915, EastAsianNeutral, VerticalRotated},              // क
+		{0x3001, EastAsianWide, VerticalTransformedUpright},      // 、
+		{0x3041, EastAsianWide, VerticalTransformedUpright},      // ぁ
+		{0x30FC, EastAsianWide, VerticalTransformedRotated},      // ー
+		{0x4E00, EastAsianWide, VerticalUpright},                 // 一
+		{0xFF08, EastAsianFullwidth, VerticalTransformedRotated}, // （
+		{0xFF71, EastAsianHalfwidth, VerticalRotated},            // ｱ
+		{0x20000, EastAsianWide, VerticalUpright},
+		{0x3FFFD, EastAsianWide, VerticalUpright}, // unassigned, in the CJK planes
+		{0xE0000, EastAsianNeutral, VerticalRotated},
+	} {
+		if got := LookupEastAsianWidth(test.r); got != test.width {
+			t.Errorf("for %U, expected width %d, got %d", test.r, test.width, got)
+		}
+		if got := LookupVerticalOrientation(test.r); got != test.orientation {
+			t.Errorf("for %U, expected orientation %d, got %d", test.r, test.orientation, got)
+		}
+		if IsUprightInVertical(test.r) != (test.orientation != VerticalRotated) {
+			t.Errorf("unexpected upright for %U", test.r)
+		}
+	}
+}
+
+func TestEmojiPresentation(t *testing.T) {
+	if !IsEmoji('#') || IsEmojiPresentation('#') || !IsEmojiComponent('#') {
+		t.Error("unexpected properties for #")
+	}
+	if !IsEmojiModifier(0x1F3FB) || !IsEmojiModifierBase(0x1F44B) || !IsExtendedPictographic(0x1F44B) {
+		t.Error("unexpected properties")
+	}
+
+	for _, test := range []struct {
+		r, next  rune
+		expected Presentation
+	}{
+		{'a', 0, PresentationText},
+		{'a', EmojiPresentationSelector, PresentationText}, // not an emoji
+		{'#', 0, PresentationText},
+		{'#', EmojiPresentationSelector, PresentationEmoji}, // keycap
+		{0x263A, 0, PresentationText},                       // ☺
+		{0x263A, EmojiPresentationSelector, PresentationEmoji},
+		{0x1F600, 0, PresentationEmoji}, // 😀
+		{0x1F600, TextPresentationSelector, PresentationText},
+		{0x270B, 0, PresentationEmoji}, // ✋
+		{0x261D, 0, PresentationText},  // ☝
+		{0x261D, 0x1F3FD, PresentationEmoji},
+		{0x1F1EB, 0x1F1F7, PresentationEmoji}, // regional indicators
+	} {
+		if got := LookupPresentation(test.r, test.next); got != test.expected {
+			t.Errorf("for %U %U, expected %d, got %d", test.r, test.next, test.expected, got)
+		}
+	}
+}